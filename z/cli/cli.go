@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// 退出码
+const (
+	ExitOK      = 0 // 成功
+	ExitFailure = 1 // 命令执行失败
+	ExitUsage   = 2 // 参数错误或未知命令
+	ExitAborted = 3 // 用户取消确认
+)
+
+const defaultStartTimeout = 30 * time.Second
+
+// ErrAborted 用户取消确认
+var ErrAborted = errors.New("aborted")
+
+// Command 维护命令
+// Run 为 fx 风格的函数，参数由依赖注入提供（可注入 *Context），可选返回 error
+type Command struct {
+	Name    string                 // 命令名，如 migrate
+	Usage   string                 // 简要说明
+	Confirm string                 // 非空时执行前需要确认，--yes 可跳过
+	Flags   func(fs *flag.FlagSet) // 注册命令参数
+	Run     interface{}            // 执行函数
+	Options []fx.Option            // 命令额外需要的 fx 选项
+}
+
+// Context 命令执行上下文
+type Context struct {
+	context.Context
+	Name   string
+	Flags  *flag.FlagSet
+	Args   []string
+	Yes    bool
+	In     io.Reader
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+// Printf 输出到标准输出
+func (c *Context) Printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(c.Out, format, args...)
+}
+
+// Confirm 交互确认，--yes 时直接通过
+func (c *Context) Confirm(prompt string) bool {
+	if c.Yes {
+		return true
+	}
+	_, _ = fmt.Fprintf(c.Out, "%s [y/N]: ", prompt)
+	line, _ := bufio.NewReader(c.In).ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Command{}
+)
+
+// Register 注册命令，同名命令后注册的覆盖先注册的
+func Register(cmds ...Command) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, cmd := range cmds {
+		name := strings.TrimSpace(cmd.Name)
+		if name == "" || cmd.Run == nil {
+			continue
+		}
+		cmd.Name = name
+		registry[name] = cmd
+	}
+}
+
+// Lookup 获取已注册命令
+func Lookup(name string) (Command, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	cmd, ok := registry[name]
+	return cmd, ok
+}
+
+// Commands 返回按名称排序的已注册命令
+func Commands() []Command {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]Command, 0, len(registry))
+	for _, cmd := range registry {
+		out = append(out, cmd)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Main 在启动 HTTP 服务前调用：命中已注册命令时执行并退出进程，否则直接返回
+// options 应只包含命令所需的 provider，不要包含会监听端口的 server 模块
+func Main(options []fx.Option) {
+	if handled, code := Dispatch(options, os.Args[1:]); handled {
+		os.Exit(code)
+	}
+}
+
+// Dispatch 执行 args 指定的命令，未命中命令时 handled 为 false
+func Dispatch(options []fx.Option, args []string) (handled bool, code int) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return false, ExitOK
+	}
+	name := args[0]
+	if name == "help" {
+		printHelp(os.Stdout)
+		return true, ExitOK
+	}
+	cmd, ok := Lookup(name)
+	if !ok {
+		return false, ExitOK
+	}
+	return true, Execute(options, cmd, args[1:], os.Stdin, os.Stdout, os.Stderr)
+}
+
+// Execute 解析参数、确认并在依赖注入容器中执行命令，返回退出码
+func Execute(options []fx.Option, cmd Command, args []string, in io.Reader, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	yes := fs.Bool("yes", false, "skip confirmation")
+	fs.BoolVar(yes, "y", false, "skip confirmation")
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(errOut, "usage: %s %s [flags]\n", filepath.Base(os.Args[0]), cmd.Name)
+		if cmd.Usage != "" {
+			_, _ = fmt.Fprintf(errOut, "  %s\n", cmd.Usage)
+		}
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cctx := &Context{
+		Context: ctx,
+		Name:    cmd.Name,
+		Flags:   fs,
+		Args:    fs.Args(),
+		Yes:     *yes,
+		In:      in,
+		Out:     out,
+		ErrOut:  errOut,
+	}
+
+	if cmd.Confirm != "" && !cctx.Confirm(cmd.Confirm) {
+		_, _ = fmt.Fprintln(errOut, "aborted")
+		return ExitAborted
+	}
+
+	if err := run(ctx, options, cmd, cctx); err != nil {
+		if errors.Is(err, ErrAborted) {
+			_, _ = fmt.Fprintln(errOut, "aborted")
+			return ExitAborted
+		}
+		_, _ = fmt.Fprintf(errOut, "%s: %v\n", cmd.Name, err)
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// run 构建并启动 fx 应用（执行 provider 的 OnStart），完成后调用命令函数
+func run(ctx context.Context, options []fx.Option, cmd Command, cctx *Context) error {
+	fn := reflect.ValueOf(cmd.Run)
+	if fn.Kind() != reflect.Func {
+		return fmt.Errorf("command run must be a function, got %T", cmd.Run)
+	}
+	fnType := fn.Type()
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	if fnType.NumOut() > 1 || (fnType.NumOut() == 1 && fnType.Out(0) != errType) {
+		return errors.New("command run may only return error")
+	}
+
+	// 以与 Run 相同的参数签名构造 invoke 函数，仅收集依赖，启动完成后再执行
+	var deps []reflect.Value
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		in[i] = fnType.In(i)
+	}
+	collect := reflect.MakeFunc(reflect.FuncOf(in, nil, fnType.IsVariadic()), func(args []reflect.Value) []reflect.Value {
+		deps = args
+		return nil
+	})
+
+	opts := make([]fx.Option, 0, len(options)+len(cmd.Options)+3)
+	opts = append(opts, options...)
+	opts = append(opts, cmd.Options...)
+	opts = append(opts, fx.Supply(cctx), fx.Invoke(collect.Interface()), fx.NopLogger)
+
+	app := fx.New(opts...)
+	if err := app.Err(); err != nil {
+		return err
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, defaultStartTimeout)
+	defer cancel()
+	if err := app.Start(startCtx); err != nil {
+		return err
+	}
+	defer func() {
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), defaultStartTimeout)
+		defer stopCancel()
+		_ = app.Stop(stopCtx)
+	}()
+
+	var results []reflect.Value
+	if fnType.IsVariadic() {
+		results = fn.CallSlice(deps)
+	} else {
+		results = fn.Call(deps)
+	}
+	if len(results) == 1 && !results[0].IsNil() {
+		return results[0].Interface().(error)
+	}
+	return nil
+}
+
+func printHelp(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "usage: %s <command> [flags]\n\ncommands:\n", filepath.Base(os.Args[0]))
+	for _, cmd := range Commands() {
+		_, _ = fmt.Fprintf(w, "  %-24s %s\n", cmd.Name, cmd.Usage)
+	}
+}
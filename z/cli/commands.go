@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/job_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/mem_cache_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/redis_provider"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// Seeder 数据填充函数
+type Seeder func(ctx context.Context, tx *gorm.DB) error
+
+// MigrateCommand 执行 AutoMigrate
+func MigrateCommand(models ...interface{}) Command {
+	return Command{
+		Name:  "migrate",
+		Usage: "auto migrate database tables",
+		Run: func(c *Context, db *db_provider.DB) error {
+			if len(models) == 0 {
+				return errors.New("no models registered")
+			}
+			if err := db.WithContext(c).AutoMigrate(models...); err != nil {
+				return err
+			}
+			c.Printf("migrated %d models\n", len(models))
+			return nil
+		},
+	}
+}
+
+// SeedCommand 依次执行数据填充，整体在一个事务中完成
+func SeedCommand(seeders ...Seeder) Command {
+	return Command{
+		Name:    "seed",
+		Usage:   "seed database",
+		Confirm: "seed database?",
+		Run: func(c *Context, db *db_provider.DB) error {
+			return db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+				for i, seeder := range seeders {
+					if seeder == nil {
+						continue
+					}
+					if err := seeder(c, tx); err != nil {
+						return fmt.Errorf("seeder #%d: %w", i+1, err)
+					}
+				}
+				c.Printf("seeded %d seeders\n", len(seeders))
+				return nil
+			})
+		},
+	}
+}
+
+type clearCacheIn struct {
+	fx.In
+
+	Ctx   *Context
+	Mem   *mem_cache_provider.MemCache `optional:"true"`
+	Redis *redis_provider.Redis        `optional:"true"`
+}
+
+// ClearCacheCommand 清空内存缓存并按前缀删除 redis 键
+func ClearCacheCommand() Command {
+	var pattern *string
+	return Command{
+		Name:    "clear-cache",
+		Usage:   "flush memory cache and delete redis keys matching --pattern",
+		Confirm: "clear cache?",
+		Flags: func(fs *flag.FlagSet) {
+			pattern = fs.String("pattern", "", "redis key pattern to delete, e.g. cache:*")
+		},
+		Run: func(in clearCacheIn) error {
+			c := in.Ctx
+			if in.Mem != nil {
+				n := in.Mem.ItemCount()
+				in.Mem.Flush()
+				c.Printf("memory cache flushed: %d items\n", n)
+			}
+			p := strings.TrimSpace(*pattern)
+			if in.Redis == nil || p == "" {
+				return nil
+			}
+			keys, err := in.Redis.ScanKeys(c, p, 500)
+			if err != nil {
+				return err
+			}
+			deleted, err := in.Redis.DeleteKeys(c, keys...)
+			if err != nil {
+				return err
+			}
+			c.Printf("redis keys deleted: %d\n", deleted)
+			return nil
+		},
+	}
+}
+
+// RotateKeysCommand 生成新的随机密钥，需自行写入配置（如 app.key）后重启生效
+func RotateKeysCommand() Command {
+	var size *int
+	return Command{
+		Name:    "rotate-keys",
+		Usage:   "generate a new random key for app.key / auth secrets",
+		Confirm: "rotating keys invalidates issued tokens, continue?",
+		Flags: func(fs *flag.FlagSet) {
+			size = fs.Int("bytes", 32, "key length in bytes")
+		},
+		Run: func(c *Context) error {
+			if *size < 16 {
+				return errors.New("key length must be at least 16 bytes")
+			}
+			buf := make([]byte, *size)
+			if _, err := rand.Read(buf); err != nil {
+				return err
+			}
+			c.Printf("%s\n", base64.RawURLEncoding.EncodeToString(buf))
+			return nil
+		},
+	}
+}
+
+// RequeueDeadLettersCommand 将重试耗尽的任务重新入队
+func RequeueDeadLettersCommand() Command {
+	var queue *string
+	return Command{
+		Name:    "requeue-dead-letters",
+		Usage:   "requeue archived jobs of --queue (default current queue)",
+		Confirm: "requeue dead letters?",
+		Flags: func(fs *flag.FlagSet) {
+			queue = fs.String("queue", "", "queue name")
+		},
+		Run: func(c *Context, client *job_provider.JobClient) error {
+			q := strings.TrimSpace(*queue)
+			if q == "" {
+				q = client.Queue()
+			}
+			n, err := client.RequeueDeadLetters(q)
+			if err != nil {
+				return err
+			}
+			c.Printf("requeued %d jobs from %s\n", n, q)
+			return nil
+		},
+	}
+}
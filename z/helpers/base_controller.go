@@ -16,6 +16,7 @@ import (
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	entranslations "github.com/go-playground/validator/v10/translations/en"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
 
 	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/auth_provider"
@@ -31,6 +32,7 @@ import (
 // Validator 数据验证器
 type Validator struct {
 	trans ut.Translator
+	langs map[string]ut.Translator
 }
 
 // Init 初始化验证器
@@ -53,11 +55,29 @@ func (v *Validator) Init() error {
 	if err := entranslations.RegisterDefaultTranslations(validate, trans); err != nil {
 		return err
 	}
+	zhTranslator, _ := uni.GetTranslator("zh")
+	if err := zhtranslations.RegisterDefaultTranslations(validate, zhTranslator); err != nil {
+		return err
+	}
 
 	v.trans = trans
+	v.langs = map[string]ut.Translator{"en": trans, "zh": zhTranslator}
 	return nil
 }
 
+// TContext 按请求语言（z.LocaleFrom）翻译验证错误消息，不支持的语言使用英文
+func (v *Validator) TContext(ctx context.Context, err error, req interface{}) string {
+	if v == nil {
+		return v.T(err, req)
+	}
+	if trans, ok := v.langs[z.LocaleFrom(ctx).Language()]; ok {
+		local := *v
+		local.trans = trans
+		return local.T(err, req)
+	}
+	return v.T(err, req)
+}
+
 // T 翻译验证错误消息
 func (v *Validator) T(err error, req interface{}) string {
 	if io.EOF == err {
@@ -133,7 +153,7 @@ var BaseControllerModule = fx.Options(
 	fx.Provide(NewBaseController),
 )
 
-// Handler 返回请求处理
+// Handler 返回请求处理，不需要单独的 span 时可直接以 z.H 作为路由处理函数
 func (b *BaseController) Handler(c *gin.Context, spanName string, handler func(ctx context.Context) (interface{}, error)) {
 	ctx := c.Request.Context()
 	span := trace.SpanFromContext(ctx)
@@ -142,7 +162,13 @@ func (b *BaseController) Handler(c *gin.Context, spanName string, handler func(c
 		defer span.End()
 	}
 
+	if c.IsAborted() {
+		return
+	}
 	result, err := handler(ctx)
+	if c.IsAborted() {
+		return
+	}
 	if err != nil {
 		z.Failure(c, err)
 		return
@@ -186,6 +212,9 @@ func (b *BaseController) StreamHandler(c *gin.Context, spanName string, handler
 		defer span.End()
 	}
 
+	if c.IsAborted() {
+		return
+	}
 	s := z.NewStreamSender(c)
 	defer func() { s.Done() }()
 
@@ -195,8 +224,51 @@ func (b *BaseController) StreamHandler(c *gin.Context, spanName string, handler
 	}
 }
 
-// GetQuery 从 gin.Context 中获取查询参数
+// BindJSON 按 http.bind.* 限制绑定并校验 JSON 请求体，失败时输出错误响应并返回 false
+//
+//	var req CreatePostRequest
+//	if !b.BindJSON(c, &req) {
+//		return
+//	}
+func (b *BaseController) BindJSON(c *gin.Context, req interface{}) bool {
+	err := z.BindJSON(c, req)
+	if err == nil {
+		return true
+	}
+	var bindErr *z.BindError
+	if errors.As(err, &bindErr) {
+		z.Failure(c, bindErr.Message, bindErr.Status())
+		return false
+	}
+	z.Failure(c, b.TContext(c.Request.Context(), err, req), z.StatusBadRequest)
+	return false
+}
+
+// GetQuery 从 gin.Context 中获取查询参数，并按 db.query.* 限制校验
+// 校验失败时输出 StatusBadRequest 响应并中止请求，返回空查询；Handler / StreamHandler 不再执行已中止的请求
 func (b *BaseController) GetQuery(c *gin.Context) db_provider.Query {
+	query, _ := b.BindQuery(c)
+	return query
+}
+
+// BindQuery 获取并校验查询参数，失败时输出错误响应并返回 false
+//
+//	query, ok := b.BindQuery(c, db_provider.QueryLimits{AllowedFields: []string{"name", "status"}})
+//	if !ok {
+//		return
+//	}
+func (b *BaseController) BindQuery(c *gin.Context, limits ...db_provider.QueryLimits) (db_provider.Query, bool) {
+	query := b.parseQuery(c)
+	if err := query.Validate(limits...); err != nil {
+		z.Failure(c, err, z.StatusBadRequest)
+		c.Abort()
+		return db_provider.Query{}, false
+	}
+	return query, true
+}
+
+// parseQuery 从 gin.Context 中解析查询参数
+func (b *BaseController) parseQuery(c *gin.Context) db_provider.Query {
 	// 标准方案（优先）：如果上游（middleware）已解析并写入 context，则直接使用
 	if value, exists := c.Get("query"); exists {
 		if q, ok := value.(db_provider.Query); ok {
@@ -242,7 +314,8 @@ func (b *BaseController) getQueryFromURL(c *gin.Context) db_provider.Query {
 				continue
 			}
 			var normalizedValue interface{} = value
-			if strings.EqualFold(operator, "in") {
+			switch strings.ToLower(operator) {
+			case "in", "not_in", "between", "not_between":
 				normalizedValue = strings.Split(value, ",")
 			}
 			conditions = append(conditions, []interface{}{field, normalizedValue, operator})
@@ -263,6 +336,15 @@ func (b *BaseController) getQueryFromURL(c *gin.Context) db_provider.Query {
 		}
 	}
 
+	// 解析 include：多个关联用 | 分隔，嵌套关联用 . 连接，: 后为该关联查询的字段
+	if includeStrs, ok := queryParams["include"]; ok && len(includeStrs) > 0 {
+		for _, part := range strings.Split(includeStrs[0], "|") {
+			if part = strings.TrimSpace(part); part != "" {
+				query.Include = append(query.Include, part)
+			}
+		}
+	}
+
 	// 解析 limit
 	if limitStrs, ok := queryParams["limit"]; ok && len(limitStrs) > 0 {
 		limitStr := limitStrs[0]
@@ -490,3 +572,14 @@ func (b *BaseController) T(err error, req interface{}) string {
 	}
 	return b.Validator.T(err, req)
 }
+
+// TContext 按请求语言翻译校验错误，ctx 通常为 c.Request.Context()
+func (b *BaseController) TContext(ctx context.Context, err error, req interface{}) string {
+	if b == nil || b.Validator == nil {
+		if err == nil {
+			return ""
+		}
+		return err.Error()
+	}
+	return b.Validator.TContext(ctx, err, req)
+}
@@ -0,0 +1,167 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+)
+
+// RelationRequest 关联管理请求体，ids 与 items 可同时使用
+//
+//	{"ids": [1, 2]}
+//	{"items": [{"id": 1, "pivot": {"expires_at": "2025-12-31"}}]}
+type RelationRequest struct {
+	IDs   []RelationID          `json:"ids"`
+	Items []RelationRequestItem `json:"items"`
+}
+
+// RelationRequestItem 带中间表附加字段的关联项
+type RelationRequestItem struct {
+	ID    RelationID             `json:"id"`
+	Pivot map[string]interface{} `json:"pivot"`
+}
+
+// RelationID 关联主键，接受 JSON 整数或字符串；整数按 int64 解析，避免大主键经 float64 丢失精度
+type RelationID struct {
+	value interface{}
+}
+
+// Value 返回主键值：int64 或 string，未设置时为 nil
+func (id RelationID) Value() interface{} {
+	return id.value
+}
+
+func (id *RelationID) UnmarshalJSON(b []byte) error {
+	raw := strings.TrimSpace(string(b))
+	if raw == "null" {
+		id.value = nil
+		return nil
+	}
+	if strings.HasPrefix(raw, `"`) {
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return err
+		}
+		id.value = strings.TrimSpace(s)
+		return nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("relation id must be an integer or string: %s", raw)
+	}
+	id.value = n
+	return nil
+}
+
+// RelationController 多对多关联管理接口，调用 CrudService 的 Attach / Detach / Sync
+//
+//	roles := &helpers.RelationController[User]{Base: base, Service: users, Relation: "Roles", Pivot: []string{"expires_at"}}
+//	ra.Handle(g, http.MethodPost, "/users/:id/roles/attach", helpers.ActionAttach, roles.Attach)
+//	ra.Handle(g, http.MethodPost, "/users/:id/roles/detach", helpers.ActionDetach, roles.Detach)
+//	ra.Handle(g, http.MethodPut, "/users/:id/roles", helpers.ActionSync, roles.Sync)
+type RelationController[T db_provider.IModel] struct {
+	Base     *BaseController
+	Service  *CrudService[T]
+	Relation string   // 关联名（模型字段名），如 Roles
+	Pivot    []string // 允许客户端写入的中间表附加字段，请求包含其它字段时返回 400
+	Param    string   // 路由中主键参数名，默认 id
+}
+
+// Attach 添加关联，已存在的关联仅更新中间表附加字段
+func (r *RelationController[T]) Attach(c *gin.Context) {
+	id, items, ok := r.bind(c, false)
+	if !ok {
+		return
+	}
+	r.Base.Handler(c, "relation.attach", func(ctx context.Context) (interface{}, error) {
+		if err := r.exists(ctx, id); err != nil {
+			return nil, err
+		}
+		return true, r.Service.Attach(ctx, id, r.Relation, items...)
+	})
+}
+
+// Detach 移除列表中的关联；ids 不能为空，移除全部关联请使用 Sync 传入空列表
+func (r *RelationController[T]) Detach(c *gin.Context) {
+	id, items, ok := r.bind(c, true)
+	if !ok {
+		return
+	}
+	ids := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	r.Base.Handler(c, "relation.detach", func(ctx context.Context) (interface{}, error) {
+		if err := r.exists(ctx, id); err != nil {
+			return nil, err
+		}
+		return true, r.Service.Detach(ctx, id, r.Relation, ids...)
+	})
+}
+
+// Sync 将关联同步为请求中的列表，空列表移除全部关联
+func (r *RelationController[T]) Sync(c *gin.Context) {
+	id, items, ok := r.bind(c, false)
+	if !ok {
+		return
+	}
+	r.Base.Handler(c, "relation.sync", func(ctx context.Context) (interface{}, error) {
+		if err := r.exists(ctx, id); err != nil {
+			return nil, err
+		}
+		return true, r.Service.Sync(ctx, id, r.Relation, items...)
+	})
+}
+
+// exists 校验主记录存在，避免为不存在的记录写入中间表
+func (r *RelationController[T]) exists(ctx context.Context, id interface{}) error {
+	_, err := r.Service.FindModel(ctx, id)
+	return err
+}
+
+// bind 读取主键参数与请求体，校验中间表字段；失败时输出 400 响应并返回 false
+func (r *RelationController[T]) bind(c *gin.Context, requireIDs bool) (interface{}, []RelationItem, bool) {
+	param := r.Param
+	if param == "" {
+		param = "id"
+	}
+	id := strings.TrimSpace(c.Param(param))
+	if id == "" {
+		z.Failure(c, fmt.Sprintf("%s is required", param), z.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	var req RelationRequest
+	if !r.Base.BindJSON(c, &req) {
+		return nil, nil, false
+	}
+	items := make([]RelationItem, 0, len(req.IDs)+len(req.Items))
+	for _, v := range req.IDs {
+		items = append(items, RelationItem{ID: v.Value()})
+	}
+	for _, item := range req.Items {
+		for field := range item.Pivot {
+			if !z.InStringSlice(r.Pivot, field) {
+				z.Failure(c, fmt.Sprintf("pivot field %s is not allowed", field), z.StatusBadRequest)
+				return nil, nil, false
+			}
+		}
+		items = append(items, RelationItem{ID: item.ID.Value(), Pivot: item.Pivot})
+	}
+	for _, item := range items {
+		if item.ID == nil || item.ID == "" {
+			z.Failure(c, "relation id is required", z.StatusBadRequest)
+			return nil, nil, false
+		}
+	}
+	if requireIDs && len(items) == 0 {
+		z.Failure(c, "ids is required", z.StatusBadRequest)
+		return nil, nil, false
+	}
+	return id, items, true
+}
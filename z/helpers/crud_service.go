@@ -0,0 +1,273 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+	"gorm.io/gorm"
+)
+
+// Transformer 响应转换器，将模型转换为对外响应结构
+type Transformer[T any] func(ctx context.Context, model T) (interface{}, error)
+
+// ComputedField 计算字段，根据模型派生额外字段（如 full_name、存储地址等）
+type ComputedField[T any] func(ctx context.Context, model T) (interface{}, error)
+
+// CrudService 通用 CRUD 服务
+// Get/Page/Find/Create/Update 的返回值统一经过 Transform 处理：
+// 设置了 Transformer 时使用 Transformer 结果；否则存在计算字段时将模型转换为 map 并追加计算字段；都未设置时原样返回模型
+type CrudService[T db_provider.IModel] struct {
+	DB          *db_provider.DB
+	Transformer Transformer[T]
+	Computed    map[string]ComputedField[T]
+	Unique      []string      // 唯一列，批量写入前校验
+	BatchHooks  BatchHookMode // 批量操作的钩子触发方式
+	Validator   *Validator    // 翻译校验错误消息，为空时使用英文原始消息
+
+	ValidateCreate func(ctx context.Context, values T) error                 // 创建前校验钩子
+	ValidateUpdate func(ctx context.Context, id interface{}, values T) error // 更新前校验钩子
+	SkipValidation bool                                                      // 跳过 binding 标签校验及校验钩子
+	Convert        *z.ConvertOptions                                         // ToModel 的严格转换选项，为空时与 z.ToStruct 相同
+
+	computedSeq []string
+	tx          *gorm.DB // 绑定的事务，非空时所有构建器使用该事务
+}
+
+// NewCrudService 创建 CRUD 服务
+func NewCrudService[T db_provider.IModel](db *db_provider.DB) *CrudService[T] {
+	return &CrudService[T]{DB: db}
+}
+
+// WithTransformer 设置响应转换器
+func (s *CrudService[T]) WithTransformer(fn Transformer[T]) *CrudService[T] {
+	s.Transformer = fn
+	return s
+}
+
+// AddComputed 添加计算字段，按添加顺序计算
+func (s *CrudService[T]) AddComputed(name string, fn ComputedField[T]) *CrudService[T] {
+	if s.Computed == nil {
+		s.Computed = map[string]ComputedField[T]{}
+	}
+	if _, ok := s.Computed[name]; !ok {
+		s.computedSeq = append(s.computedSeq, name)
+	}
+	s.Computed[name] = fn
+	return s
+}
+
+// WithTx 返回绑定事务的服务副本，其查询、创建、更新、删除均在该事务内执行，原服务不受影响
+//
+//	err := db.DB.Transaction(func(tx *gorm.DB) error {
+//		if _, err := users.WithTx(tx).Create(ctx, user); err != nil {
+//			return err
+//		}
+//		_, err := orders.WithTx(tx).Update(ctx, orderID, order)
+//		return err
+//	})
+func (s *CrudService[T]) WithTx(tx *gorm.DB) *CrudService[T] {
+	bound := *s
+	bound.tx = tx
+	return &bound
+}
+
+// Tx 返回绑定的事务，未绑定时返回 nil
+func (s *CrudService[T]) Tx() *gorm.DB {
+	return s.tx
+}
+
+// Transaction 开启事务并传入绑定该事务的服务副本，fn 返回错误或 panic 时回滚
+// 已绑定事务时开启嵌套事务（SAVEPOINT）；其他服务可通过 WithTx(ts.Tx()) 加入同一事务
+//
+//	err := users.Transaction(ctx, func(ts *helpers.CrudService[User]) error {
+//		if _, err := ts.Create(ctx, user); err != nil {
+//			return err
+//		}
+//		_, err := orders.WithTx(ts.Tx()).Create(ctx, order)
+//		return err
+//	})
+func (s *CrudService[T]) Transaction(ctx context.Context, fn func(ts *CrudService[T]) error) error {
+	db := s.db()
+	if db == nil {
+		return errors.New("db is nil")
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(s.WithTx(tx))
+	})
+}
+
+// db 返回当前使用的连接：绑定的事务或全局 DB
+func (s *CrudService[T]) db() *gorm.DB {
+	if s.tx != nil {
+		return s.tx
+	}
+	if s.DB != nil {
+		return s.DB.DB
+	}
+	return nil
+}
+
+// Transform 转换单个模型
+func (s *CrudService[T]) Transform(ctx context.Context, model T) (interface{}, error) {
+	if s.Transformer != nil {
+		return s.Transformer(ctx, model)
+	}
+	if len(s.Computed) == 0 {
+		return model, nil
+	}
+
+	data := map[string]interface{}{}
+	if err := z.ToInterface(model, &data); err != nil {
+		return nil, err
+	}
+	for _, name := range s.computedNames() {
+		value, err := s.Computed[name](ctx, model)
+		if err != nil {
+			return nil, err
+		}
+		data[name] = value
+	}
+	return data, nil
+}
+
+// TransformList 转换模型列表
+func (s *CrudService[T]) TransformList(ctx context.Context, models []T) (interface{}, error) {
+	if s.Transformer == nil && len(s.Computed) == 0 {
+		return models, nil
+	}
+	out := make([]interface{}, 0, len(models))
+	for _, model := range models {
+		item, err := s.Transform(ctx, model)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// computedNames 返回计算字段名称，直接赋值 Computed 时按 map 遍历顺序补齐
+func (s *CrudService[T]) computedNames() []string {
+	if len(s.computedSeq) == len(s.Computed) {
+		return s.computedSeq
+	}
+	names := make([]string, 0, len(s.Computed))
+	seen := map[string]bool{}
+	for _, name := range s.computedSeq {
+		if _, ok := s.Computed[name]; ok {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	for name := range s.Computed {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Query 返回查询构建器
+func (s *CrudService[T]) Query(ctx context.Context, query db_provider.Query) *db_provider.QueryBuilder[T] {
+	return &db_provider.QueryBuilder[T]{DB: s.DB, Query: query, Context: ctx, TX: s.tx}
+}
+
+// Get 查询多条记录
+func (s *CrudService[T]) Get(ctx context.Context, query db_provider.Query) (interface{}, error) {
+	var models []T
+	if err := s.Query(ctx, query).Get(&models); err != nil {
+		return nil, err
+	}
+	return s.TransformList(ctx, models)
+}
+
+// Page 分页查询
+func (s *CrudService[T]) Page(ctx context.Context, query db_provider.Query) (*db_provider.Pager, error) {
+	var models []T
+	pager := &db_provider.Pager{}
+	if err := s.Query(ctx, query).Page(pager, &models); err != nil {
+		return nil, err
+	}
+	data, err := s.TransformList(ctx, models)
+	if err != nil {
+		return nil, err
+	}
+	pager.Data = data
+	return pager, nil
+}
+
+// Find 根据主键查询
+func (s *CrudService[T]) Find(ctx context.Context, id interface{}, query ...db_provider.Query) (interface{}, error) {
+	model, err := s.FindModel(ctx, id, query...)
+	if err != nil {
+		return nil, err
+	}
+	return s.Transform(ctx, model)
+}
+
+// FindModel 根据主键查询原始模型
+func (s *CrudService[T]) FindModel(ctx context.Context, id interface{}, query ...db_provider.Query) (T, error) {
+	var model T
+	q := db_provider.Query{}
+	if len(query) > 0 {
+		q = query[0]
+	}
+	if err := s.Query(ctx, q).Find(id, &model); err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// Create 创建记录，写入前按 binding 标签校验并调用 ValidateCreate 钩子，校验失败返回 *ValidationError
+func (s *CrudService[T]) Create(ctx context.Context, values T) (interface{}, error) {
+	if err := s.validateCreate(ctx, values); err != nil {
+		return nil, err
+	}
+	builder := &db_provider.CreateBuilder[T]{DB: s.DB, Context: ctx, TX: s.tx}
+	model, err := builder.Create(values)
+	if err != nil {
+		return nil, err
+	}
+	return s.Transform(ctx, model)
+}
+
+// Update 根据主键更新记录，返回更新后的记录；写入前校验非零值字段的 binding 标签并调用 ValidateUpdate 钩子
+func (s *CrudService[T]) Update(ctx context.Context, id interface{}, values T) (interface{}, error) {
+	if err := s.validateUpdate(ctx, id, values); err != nil {
+		return nil, err
+	}
+	builder := &db_provider.UpdateBuilder[T]{DB: s.DB, Context: ctx, TX: s.tx}
+	ok, err := builder.UpdateByID(id, values)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("update failed")
+	}
+	return s.Find(ctx, id)
+}
+
+// Delete 根据主键删除记录，模型含 gorm.DeletedAt 字段（如内嵌 db_provider.SoftDelete）时为软删除
+func (s *CrudService[T]) Delete(ctx context.Context, id interface{}) (bool, error) {
+	builder := &db_provider.DeleteBuilder[T]{DB: s.DB, Context: ctx, TX: s.tx}
+	return builder.DeleteByID(id)
+}
+
+// SoftDeletes 模型是否支持软删除，支持时 Query 默认排除已删除记录，可通过 Query.WithTrashed / OnlyTrashed 调整
+func (s *CrudService[T]) SoftDeletes() bool {
+	return db_provider.SoftDeleteColumn[T](s.db()) != ""
+}
+
+// Restore 根据主键恢复已软删除的记录，记录不存在或未被删除时返回 false
+func (s *CrudService[T]) Restore(ctx context.Context, id interface{}) (bool, error) {
+	builder := &db_provider.DeleteBuilder[T]{DB: s.DB, Context: ctx, TX: s.tx}
+	return builder.RestoreByID(id)
+}
+
+// ForceDelete 根据主键物理删除记录，包括已软删除的记录
+func (s *CrudService[T]) ForceDelete(ctx context.Context, id interface{}) (bool, error) {
+	builder := &db_provider.DeleteBuilder[T]{DB: s.DB, Context: ctx, TX: s.tx}
+	return builder.ForceDeleteByID(id)
+}
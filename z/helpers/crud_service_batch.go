@@ -0,0 +1,245 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+	"gorm.io/gorm"
+)
+
+// BatchHookMode 批量操作时模型钩子的触发方式
+type BatchHookMode int
+
+const (
+	BatchHookPerItem  BatchHookMode = iota // 每条记录触发 gorm 模型钩子（BeforeCreate、AfterDelete 等），默认
+	BatchHookPerBatch                      // 跳过逐条钩子，模型实现 IBatchHook 时整批触发一次；Uuid / Ulid 主键不会自动生成，需预先赋值
+)
+
+// 批量操作类型，传给 IBatchHook
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// IBatchHook 整批钩子，BatchHookPerBatch 模式下在同一事务内调用，返回错误时回滚整批
+type IBatchHook interface {
+	BeforeBatch(tx *gorm.DB, op string, count int) error
+	AfterBatch(tx *gorm.DB, op string, count int) error
+}
+
+// WithUnique 设置唯一列，BatchCreate / BatchUpdate 写入前校验批内重复及与已有记录的冲突
+// 每列仅执行一次 IN 查询；并发写入仍需依赖数据库唯一索引兜底
+func (s *CrudService[T]) WithUnique(columns ...string) *CrudService[T] {
+	s.Unique = append(s.Unique, columns...)
+	return s
+}
+
+// WithBatchHooks 设置批量操作的钩子触发方式
+func (s *CrudService[T]) WithBatchHooks(mode BatchHookMode) *CrudService[T] {
+	s.BatchHooks = mode
+	return s
+}
+
+// BatchCreate 在一个事务内批量创建记录，任一记录失败时整批回滚
+func (s *CrudService[T]) BatchCreate(ctx context.Context, values []T) (interface{}, error) {
+	if len(values) == 0 {
+		return s.TransformList(ctx, []T{})
+	}
+	for i := range values {
+		if err := s.validateCreate(ctx, values[i]); err != nil {
+			return nil, prefixFieldErrors(err, i)
+		}
+	}
+	var created []T
+	err := s.batch(ctx, BatchOpCreate, len(values), func(tx *gorm.DB) error {
+		if err := s.checkUnique(tx, values, nil); err != nil {
+			return err
+		}
+		builder := &db_provider.CreateBuilder[T]{TX: tx, Context: ctx}
+		var err error
+		created, err = builder.BatchCreate(values)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.TransformList(ctx, created)
+}
+
+// BatchUpdate 在一个事务内按主键批量更新记录（仅单主键），任一记录不存在或失败时整批回滚，返回更新条数
+// 按主键顺序执行，降低并发批量更新时的死锁概率
+func (s *CrudService[T]) BatchUpdate(ctx context.Context, values map[interface{}]T) (int64, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+	ids := make([]interface{}, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return fmt.Sprint(ids[i]) < fmt.Sprint(ids[j]) })
+	items := make([]T, 0, len(ids))
+	for i, id := range ids {
+		items = append(items, values[id])
+		if err := s.validateUpdate(ctx, id, items[i]); err != nil {
+			return 0, prefixFieldErrors(err, id)
+		}
+	}
+
+	var updated int64
+	err := s.batch(ctx, BatchOpUpdate, len(ids), func(tx *gorm.DB) error {
+		if err := s.checkUnique(tx, items, ids); err != nil {
+			return err
+		}
+		builder := &db_provider.UpdateBuilder[T]{TX: tx, Context: ctx}
+		for i, id := range ids {
+			if _, err := builder.UpdateByID(id, items[i]); err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// BatchDelete 在一个事务内按主键批量删除记录（仅单主键，软删除规则同 Delete），返回删除条数
+// BatchHookPerItem 模式下逐条加载并删除以触发模型钩子；BatchHookPerBatch 模式下执行一条 DELETE ... IN
+func (s *CrudService[T]) BatchDelete(ctx context.Context, ids []interface{}) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	column, err := s.singlePrimaryKey()
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	err = s.batch(ctx, BatchOpDelete, len(ids), func(tx *gorm.DB) error {
+		if s.BatchHooks == BatchHookPerBatch {
+			var zero T
+			result := tx.Where(column+" IN ?", ids).Delete(&zero)
+			if result.Error != nil {
+				return db_provider.WrapDBError(result.Error)
+			}
+			deleted = result.RowsAffected
+			return nil
+		}
+		var rows []T
+		if err := tx.Where(column+" IN ?", ids).Find(&rows).Error; err != nil {
+			return db_provider.WrapDBError(err)
+		}
+		for i := range rows {
+			result := tx.Delete(&rows[i])
+			if result.Error != nil {
+				return db_provider.WrapDBError(result.Error)
+			}
+			deleted += result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// batch 开启事务执行批量操作（已绑定事务时为嵌套事务），按 BatchHooks 决定是否跳过逐条钩子并触发整批钩子
+func (s *CrudService[T]) batch(ctx context.Context, op string, count int, fn func(tx *gorm.DB) error) error {
+	db := s.db()
+	if db == nil {
+		return errors.New("db is nil")
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var hook IBatchHook
+		if s.BatchHooks == BatchHookPerBatch {
+			tx = tx.Session(&gorm.Session{SkipHooks: true})
+			var zero T
+			hook, _ = any(&zero).(IBatchHook)
+		}
+		if hook != nil {
+			if err := hook.BeforeBatch(tx, op, count); err != nil {
+				return err
+			}
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if hook != nil {
+			return hook.AfterBatch(tx, op, count)
+		}
+		return nil
+	})
+}
+
+// checkUnique 校验 Unique 列：批内不能重复，也不能与已有记录冲突（excludeIDs 为本批更新的主键）
+// 零值字段视为未设置，不参与校验
+func (s *CrudService[T]) checkUnique(tx *gorm.DB, items []T, excludeIDs []interface{}) error {
+	if len(s.Unique) == 0 {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(new(T)); err != nil {
+		return err
+	}
+	var pk string
+	if excludeIDs != nil {
+		var err error
+		if pk, err = s.singlePrimaryKey(); err != nil {
+			return err
+		}
+	}
+
+	for _, column := range s.Unique {
+		field := stmt.Schema.LookUpField(column)
+		if field == nil {
+			return fmt.Errorf("unique column not found: %s", column)
+		}
+		seen := map[string]bool{}
+		values := make([]interface{}, 0, len(items))
+		for i := range items {
+			value, zero := field.ValueOf(tx.Statement.Context, reflect.ValueOf(&items[i]).Elem())
+			if zero {
+				continue
+			}
+			key := fmt.Sprint(value)
+			if seen[key] {
+				return db_provider.DBError{Code: db_provider.ErrCodeDuplicate, Message: fmt.Sprintf("Duplicate value in batch: %v", value), Field: field.DBName}
+			}
+			seen[key] = true
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		var zero T
+		query := tx.Model(&zero).Where(field.DBName+" IN ?", values)
+		if pk != "" {
+			query = query.Where(pk+" NOT IN ?", excludeIDs)
+		}
+		var taken []string
+		if err := query.Limit(1).Pluck(field.DBName, &taken).Error; err != nil {
+			return db_provider.WrapDBError(err)
+		}
+		if len(taken) > 0 {
+			return db_provider.DBError{Code: db_provider.ErrCodeDuplicate, Message: fmt.Sprintf("Duplicate value: %v", taken[0]), Field: field.DBName}
+		}
+	}
+	return nil
+}
+
+func (s *CrudService[T]) singlePrimaryKey() (string, error) {
+	columns := db_provider.PrimaryKeyColumns[T](s.db())
+	if len(columns) != 1 {
+		return "", fmt.Errorf("batch: composite primary key is not supported")
+	}
+	return columns[0], nil
+}
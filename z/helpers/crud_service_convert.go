@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+)
+
+// WithStrictConvert 启用严格转换，ToModel 不再静默丢弃未知字段或忽略类型不符；未传 opts 时使用 z.StrictConvert
+func (s *CrudService[T]) WithStrictConvert(opts ...z.ConvertOptions) *CrudService[T] {
+	opt := z.StrictConvert
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	s.Convert = &opt
+	return s
+}
+
+// ToModel 将请求数据（map 或请求结构体）转换为模型
+// 设置了 Convert 时按严格模式转换，问题字段以 *ValidationError 返回（Field 为字段路径，如 items[0].price）
+//
+//	values, err := svc.ToModel(req)
+//	if err != nil {
+//		return nil, err
+//	}
+//	return svc.Create(ctx, values)
+func (s *CrudService[T]) ToModel(data interface{}) (T, error) {
+	var model T
+	if s.Convert == nil {
+		err := z.ToStruct(data, &model)
+		return model, err
+	}
+	if err := z.ToStructStrict(data, &model, *s.Convert); err != nil {
+		return model, convertValidationError(err)
+	}
+	return model, nil
+}
+
+// ConvertTransformer 将模型严格转换为响应结构 R 的转换器，未传 opts 时仅检查 R 的必填字段
+// 响应结构通常为模型的子集，因此默认不检查未知字段
+//
+//	svc.WithTransformer(helpers.ConvertTransformer[User, UserResponse]())
+func ConvertTransformer[T any, R any](opts ...z.ConvertOptions) Transformer[T] {
+	opt := z.ConvertOptions{RequireFields: true}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return func(ctx context.Context, model T) (interface{}, error) {
+		var out R
+		if err := z.ToStructStrict(model, &out, opt); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+}
+
+// convertValidationError 将 *z.ConvertError 转换为 *ValidationError，其他错误原样返回
+func convertValidationError(err error) error {
+	var convErr *z.ConvertError
+	if !errors.As(err, &convErr) {
+		return err
+	}
+	out := &ValidationError{Errors: make([]db_provider.DBError, 0, len(convErr.Issues))}
+	for _, issue := range convErr.Issues {
+		out.Errors = append(out.Errors, db_provider.DBError{
+			Code:    db_provider.ErrCodeInvalidData,
+			Message: issue.Path + " " + issue.Message,
+			Field:   issue.Path,
+		})
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// RelationItem 多对多关联项，Pivot 为中间表附加字段
+type RelationItem struct {
+	ID    interface{}
+	Pivot map[string]interface{}
+}
+
+// RelationIDs 将主键列表转换为关联项
+func RelationIDs(ids ...interface{}) []RelationItem {
+	items := make([]RelationItem, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, RelationItem{ID: id})
+	}
+	return items
+}
+
+// pivotRelation 中间表信息
+type pivotRelation struct {
+	table    string
+	ownerKey string
+	otherKey string
+}
+
+// pivot 解析多对多关联的中间表
+func (s *CrudService[T]) pivot(relation string) (*pivotRelation, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("db is nil")
+	}
+	stmt := &gorm.Statement{DB: s.DB.DB}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	rel, ok := stmt.Schema.Relationships.Relations[relation]
+	if !ok || rel.Type != schema.Many2Many || rel.JoinTable == nil {
+		return nil, fmt.Errorf("relation %s is not a many2many relation", relation)
+	}
+
+	p := &pivotRelation{table: rel.JoinTable.Table}
+	for _, ref := range rel.References {
+		if ref.ForeignKey == nil || ref.PrimaryKey == nil {
+			continue
+		}
+		if ref.OwnPrimaryKey {
+			p.ownerKey = ref.ForeignKey.DBName
+		} else {
+			p.otherKey = ref.ForeignKey.DBName
+		}
+	}
+	if p.ownerKey == "" || p.otherKey == "" {
+		return nil, fmt.Errorf("relation %s has unsupported join keys", relation)
+	}
+	return p, nil
+}
+
+// Attach 添加多对多关联，已存在的关联仅更新中间表附加字段
+func (s *CrudService[T]) Attach(ctx context.Context, id interface{}, relation string, items ...RelationItem) error {
+	p, err := s.pivot(relation)
+	if err != nil {
+		return err
+	}
+	return s.DB.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return attachPivot(tx, p, id, items)
+	})
+}
+
+// Detach 移除多对多关联，未传入 ids 时移除全部关联
+func (s *CrudService[T]) Detach(ctx context.Context, id interface{}, relation string, ids ...interface{}) error {
+	p, err := s.pivot(relation)
+	if err != nil {
+		return err
+	}
+	db := s.DB.DB.WithContext(ctx).Table(p.table).Where(p.ownerKey+" = ?", id)
+	if len(ids) > 0 {
+		db = db.Where(p.otherKey+" IN ?", ids)
+	}
+	return db.Delete(nil).Error
+}
+
+// Sync 同步多对多关联，移除不在列表中的关联并添加缺失的关联
+func (s *CrudService[T]) Sync(ctx context.Context, id interface{}, relation string, items ...RelationItem) error {
+	p, err := s.pivot(relation)
+	if err != nil {
+		return err
+	}
+	return s.DB.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		del := tx.Table(p.table).Where(p.ownerKey+" = ?", id)
+		if len(ids) > 0 {
+			del = del.Where(p.otherKey+" NOT IN ?", ids)
+		}
+		if err := del.Delete(nil).Error; err != nil {
+			return err
+		}
+		return attachPivot(tx, p, id, items)
+	})
+}
+
+// attachPivot 写入中间表
+func attachPivot(tx *gorm.DB, p *pivotRelation, id interface{}, items []RelationItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	var existing []string
+	if err := tx.Table(p.table).Where(p.ownerKey+" = ?", id).Where(p.otherKey+" IN ?", ids).Pluck(p.otherKey, &existing).Error; err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, v := range existing {
+		exists[v] = true
+	}
+
+	rows := make([]map[string]interface{}, 0, len(items))
+	hasPivot := false
+	for _, item := range items {
+		if exists[fmt.Sprint(item.ID)] {
+			if len(item.Pivot) > 0 {
+				if err := tx.Table(p.table).Where(p.ownerKey+" = ?", id).Where(p.otherKey+" = ?", item.ID).Updates(item.Pivot).Error; err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		row := map[string]interface{}{p.ownerKey: id, p.otherKey: item.ID}
+		for k, v := range item.Pivot {
+			row[k] = v
+			hasPivot = true
+		}
+		rows = append(rows, row)
+		exists[fmt.Sprint(item.ID)] = true
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if !hasPivot {
+		return tx.Table(p.table).Create(&rows).Error
+	}
+	// 附加字段可能各不相同，逐行写入
+	for _, row := range rows {
+		if err := tx.Table(p.table).Create(row).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+)
+
+// SlugExists 检查 column 列是否已存在该 slug，excludeID 非空时排除该主键的记录（更新自身时使用）
+func (s *CrudService[T]) SlugExists(ctx context.Context, column, slug string, excludeID interface{}) (bool, error) {
+	q := db_provider.Query{}
+	q.AddSearch(column, slug)
+	if excludeID != nil {
+		columns := db_provider.PrimaryKeyColumns[T](s.DB.DB)
+		if len(columns) != 1 {
+			return false, fmt.Errorf("slug exists: composite primary key is not supported")
+		}
+		q.AddSearch(columns[0], excludeID, "!=")
+	}
+	return s.Query(ctx, q).Exists()
+}
+
+// UniqueSlug 由 source 生成 slug（z.Slugify），与 column 列已有值冲突时追加 -2、-3 … 后缀
+//
+//	post.Slug, err = service.UniqueSlug(ctx, "slug", req.Title, nil, z.SlugOptions{MaxLength: 80})
+func (s *CrudService[T]) UniqueSlug(ctx context.Context, column, source string, excludeID interface{}, opts ...z.SlugOptions) (string, error) {
+	return z.UniqueSlug(z.Slugify(source, opts...), func(slug string) (bool, error) {
+		return s.SlugExists(ctx, column, slug, excludeID)
+	})
+}
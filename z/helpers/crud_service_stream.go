@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+)
+
+// streamFlushEvery 每写出多少条记录刷新一次
+const streamFlushEvery = 500
+
+// Each 逐行查询并转换记录，适用于大批量导出（忽略 page 参数，不支持 include 预加载）
+func (s *CrudService[T]) Each(ctx context.Context, query db_provider.Query, fn func(item interface{}) error) error {
+	return s.Query(ctx, query).Each(func(row *T) error {
+		item, err := s.Transform(ctx, *row)
+		if err != nil {
+			return err
+		}
+		return fn(item)
+	})
+}
+
+// Stream 按主键游标分批查询并转换记录，适用于报表生成、ETL 等全量遍历场景
+// limit 作为每批条数（默认 db_provider.DefaultBatchSize），忽略 orderby、page 参数，结果按主键升序
+func (s *CrudService[T]) Stream(ctx context.Context, query db_provider.Query, fn func(batch []interface{}) error) error {
+	return s.Query(ctx, query).Batches(query.Limit, func(rows []T) error {
+		batch := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			item, err := s.Transform(ctx, row)
+			if err != nil {
+				return err
+			}
+			batch = append(batch, item)
+		}
+		return fn(batch)
+	})
+}
+
+// StreamJSON 以流式 JSON（支持 gzip）输出查询结果，边扫描边编码，不在内存中保留完整列表
+// 输出开始后无法再修改状态码，中途出错时响应体 success 为 false 并附带 error 字段
+func (s *CrudService[T]) StreamJSON(c *gin.Context, query db_provider.Query) error {
+	stream := z.NewJSONStream(c)
+	err := s.Each(c.Request.Context(), query, func(item interface{}) error {
+		if err := stream.Write(item); err != nil {
+			return err
+		}
+		if stream.Count()%streamFlushEvery == 0 {
+			stream.Flush()
+		}
+		return nil
+	})
+	if cerr := stream.Close(err); err == nil {
+		err = cerr
+	}
+	return err
+}
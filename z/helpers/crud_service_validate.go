@@ -0,0 +1,151 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+)
+
+// ValidationError 字段级校验错误，每个字段一条 DBError（Code 为 INVALID_DATA，Field 为 json 字段名）
+type ValidationError struct {
+	Errors []db_provider.DBError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return db_provider.ErrCodeInvalidData
+	}
+	return e.Errors[0].Error()
+}
+
+// FieldError 创建单个字段的校验错误，供 ValidateCreate / ValidateUpdate 钩子返回
+func FieldError(field, message string) *ValidationError {
+	return &ValidationError{Errors: []db_provider.DBError{{Code: db_provider.ErrCodeInvalidData, Message: message, Field: field}}}
+}
+
+// WithValidateCreate 设置创建前的校验钩子，在 binding 标签校验通过后调用（Create、BatchCreate）
+func (s *CrudService[T]) WithValidateCreate(fn func(ctx context.Context, values T) error) *CrudService[T] {
+	s.ValidateCreate = fn
+	return s
+}
+
+// WithValidateUpdate 设置更新前的校验钩子，在 binding 标签校验通过后调用（Update、BatchUpdate）
+func (s *CrudService[T]) WithValidateUpdate(fn func(ctx context.Context, id interface{}, values T) error) *CrudService[T] {
+	s.ValidateUpdate = fn
+	return s
+}
+
+// validateCreate 按 binding 标签校验全部字段，再调用 ValidateCreate 钩子
+func (s *CrudService[T]) validateCreate(ctx context.Context, values T) error {
+	if s.SkipValidation {
+		return nil
+	}
+	if err := s.validateStruct(ctx, values, false); err != nil {
+		return err
+	}
+	if s.ValidateCreate != nil {
+		return s.ValidateCreate(ctx, values)
+	}
+	return nil
+}
+
+// validateUpdate 更新只写入非零值字段，因此仅校验非零值字段的 binding 标签，再调用 ValidateUpdate 钩子
+func (s *CrudService[T]) validateUpdate(ctx context.Context, id interface{}, values T) error {
+	if s.SkipValidation {
+		return nil
+	}
+	if err := s.validateStruct(ctx, values, true); err != nil {
+		return err
+	}
+	if s.ValidateUpdate != nil {
+		return s.ValidateUpdate(ctx, id, values)
+	}
+	return nil
+}
+
+func (s *CrudService[T]) validateStruct(ctx context.Context, values T, partial bool) error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok || validate == nil {
+		return nil
+	}
+	rv := reflect.Indirect(reflect.ValueOf(values))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var err error
+	if partial {
+		fields := nonZeroFields(rv, "")
+		if len(fields) == 0 {
+			return nil
+		}
+		err = validate.StructPartialCtx(ctx, values, fields...)
+	} else {
+		err = validate.StructCtx(ctx, values)
+	}
+	if err == nil {
+		return nil
+	}
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	out := &ValidationError{Errors: make([]db_provider.DBError, 0, len(errs))}
+	for _, fe := range errs {
+		out.Errors = append(out.Errors, db_provider.DBError{
+			Code:    db_provider.ErrCodeInvalidData,
+			Message: s.Validator.TContext(ctx, validator.ValidationErrors{fe}, values),
+			Field:   jsonFieldName(rv.Type(), fe.StructField()),
+		})
+	}
+	return out
+}
+
+// nonZeroFields 返回非零值字段的路径（内嵌结构体为 Type.Field），供 StructPartial 使用
+func nonZeroFields(rv reflect.Value, prefix string) []string {
+	var fields []string
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, nonZeroFields(rv.Field(i), prefix+f.Name+".")...)
+			continue
+		}
+		if !f.IsExported() || rv.Field(i).IsZero() {
+			continue
+		}
+		fields = append(fields, prefix+f.Name)
+	}
+	return fields
+}
+
+// jsonFieldName 返回字段的 json 名称，未设置时为小写字段名
+func jsonFieldName(t reflect.Type, name string) string {
+	if f, ok := t.FieldByName(name); ok {
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+			return tag
+		}
+	}
+	return strings.ToLower(name)
+}
+
+// prefixFieldErrors 为批量操作的校验错误字段追加序号（BatchCreate）或主键（BatchUpdate）前缀，如 [2].email
+func prefixFieldErrors(err error, key interface{}) error {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+	out := &ValidationError{Errors: make([]db_provider.DBError, len(verr.Errors))}
+	for i, e := range verr.Errors {
+		e.Field = fmt.Sprintf("[%v].%s", key, e.Field)
+		out.Errors[i] = e
+	}
+	return out
+}
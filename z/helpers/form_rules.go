@@ -0,0 +1,334 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/fx"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+)
+
+// FormRulesConfigKey 配置中表单校验规则的位置，键为表单名，值为规则列表
+const FormRulesConfigKey = "validation.forms"
+
+var (
+	ErrFormNotFound    = errors.New("form rules not found")
+	ErrFormRuleInvalid = errors.New("invalid form rule")
+)
+
+// FormRule 以数据形式定义的校验规则，Rule 为 validator 的校验标签名（required、email、min、oneof 等）
+// Params 为标签参数，如 min 的 "3"、oneof 的 "a b c"；Message 为自定义错误消息，为空时使用按请求语言翻译的默认消息
+// 同一字段的多条规则按顺序组合，Label 取该字段首个非空值
+type FormRule struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Params  string `json:"params,omitempty"`
+	Message string `json:"message,omitempty"`
+	Label   string `json:"label,omitempty"`
+}
+
+// FormValidator 由规则集编译的校验器，可并发使用
+type FormValidator struct {
+	Validator *Validator
+
+	fields []formField
+}
+
+type formField struct {
+	name     string
+	typ      reflect.Type
+	messages map[string]string
+}
+
+// crossFieldRules 依赖其他字段的校验标签，规则集按字段独立校验，不支持这类规则
+var crossFieldRules = []string{"required_if", "required_unless", "required_with", "required_without",
+	"excluded_if", "excluded_unless", "excluded_with", "excluded_without", "unique", "dive", "keys", "endkeys"}
+
+// NewFormValidator 编译规则集，规则名未注册、参数不合法或依赖其他字段时返回 ErrFormRuleInvalid
+// 未包含 required 规则的字段为可选字段，值缺失或为零值时跳过校验
+func NewFormValidator(rules []FormRule, v *Validator) (*FormValidator, error) {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok || validate == nil {
+		return nil, errors.New("binding validator engine is not *validator.Validate")
+	}
+
+	type group struct {
+		label    string
+		tags     []string
+		required bool
+		messages map[string]string
+	}
+	var order []string
+	groups := map[string]*group{}
+	for i, rule := range rules {
+		field := strings.TrimSpace(rule.Field)
+		name := strings.TrimSpace(rule.Rule)
+		if field == "" || name == "" {
+			return nil, fmt.Errorf("%w: rules[%d] requires field and rule", ErrFormRuleInvalid, i)
+		}
+		if err := checkFormRuleName(name); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrFormRuleInvalid, field, err)
+		}
+
+		g, ok := groups[field]
+		if !ok {
+			g = &group{messages: map[string]string{}}
+			groups[field] = g
+			order = append(order, field)
+		}
+		if g.label == "" {
+			g.label = rule.Label
+		}
+		if name == "required" {
+			g.required = true
+		}
+		tag := name
+		if rule.Params != "" {
+			tag += "=" + escapeFormRuleParams(rule.Params)
+		}
+		g.tags = append(g.tags, tag)
+		if rule.Message != "" {
+			g.messages[name] = rule.Message
+		}
+	}
+
+	fv := &FormValidator{Validator: v, fields: make([]formField, 0, len(order))}
+	for _, field := range order {
+		g := groups[field]
+		tags := strings.Join(g.tags, ",")
+		if !g.required {
+			tags = "omitempty," + tags
+		}
+		label := g.label
+		if label == "" {
+			label = field
+		}
+		typ := reflect.StructOf([]reflect.StructField{{
+			Name: "Value",
+			Type: reflect.TypeOf((*interface{})(nil)).Elem(),
+			Tag:  reflect.StructTag(fmt.Sprintf(`binding:%q label:%q`, tags, label)),
+		}})
+		// 以空值和字符串各试校验一次，未注册的规则名或不合法的参数（如 min=abc）在此处暴露
+		for _, sample := range []interface{}{nil, "x"} {
+			var errs validator.ValidationErrors
+			if _, err := runFormField(context.Background(), validate, typ, sample); err != nil && !errors.As(err, &errs) {
+				return nil, fmt.Errorf("%w: %s: %v", ErrFormRuleInvalid, field, err)
+			}
+		}
+		fv.fields = append(fv.fields, formField{name: field, typ: typ, messages: g.messages})
+	}
+	return fv, nil
+}
+
+// Validate 按规则集校验数据，字段名支持以 . 访问嵌套对象，如 address.city
+// 校验失败时返回 *ValidationError，每个字段一条错误
+func (f *FormValidator) Validate(ctx context.Context, data map[string]interface{}) error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok || validate == nil {
+		return nil
+	}
+
+	var out *ValidationError
+	for _, field := range f.fields {
+		value, _ := lookupFormValue(data, field.name)
+		req, err := runFormField(ctx, validate, field.typ, value)
+		if err == nil {
+			continue
+		}
+		if out == nil {
+			out = &ValidationError{}
+		}
+		out.Errors = append(out.Errors, db_provider.DBError{
+			Code:    db_provider.ErrCodeInvalidData,
+			Message: f.message(ctx, field, err, req),
+			Field:   field.name,
+		})
+	}
+	if out != nil {
+		return out
+	}
+	return nil
+}
+
+func (f *FormValidator) message(ctx context.Context, field formField, err error, req interface{}) string {
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) && len(errs) > 0 {
+		if msg, ok := field.messages[errs[0].Tag()]; ok {
+			return msg
+		}
+		return f.Validator.TContext(ctx, err, req)
+	}
+	return err.Error()
+}
+
+// runFormField 校验单个字段，validator 对不支持的值类型会 panic，此时作为校验失败返回
+func runFormField(ctx context.Context, validate *validator.Validate, typ reflect.Type, value interface{}) (req interface{}, err error) {
+	rv := reflect.New(typ)
+	if value != nil {
+		rv.Elem().Field(0).Set(reflect.ValueOf(value))
+	}
+	req = rv.Interface()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return req, validate.StructCtx(ctx, req)
+}
+
+func checkFormRuleName(name string) error {
+	if strings.ContainsAny(name, ",|=") {
+		return fmt.Errorf("rule %q must be a single tag name", name)
+	}
+	if strings.HasSuffix(name, "field") {
+		return fmt.Errorf("cross-field rule %q is not supported", name)
+	}
+	for _, r := range crossFieldRules {
+		if name == r {
+			return fmt.Errorf("rule %q is not supported", name)
+		}
+	}
+	return nil
+}
+
+// escapeFormRuleParams 转义参数中的标签分隔符，使 oneof、contains 等规则可以使用 , 和 |
+func escapeFormRuleParams(params string) string {
+	return strings.NewReplacer(",", "0x2C", "|", "0x7C").Replace(params)
+}
+
+func lookupFormValue(data map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = data
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// FormRuleLoader 按表单名加载规则集，表单不存在时返回 ErrFormNotFound
+type FormRuleLoader func(ctx context.Context, form string) ([]FormRule, error)
+
+// ConfigFormRuleLoader 从配置读取规则集，key 下以表单名为键、规则列表为值
+func ConfigFormRuleLoader(cfg *config_provider.Config, key string) FormRuleLoader {
+	return func(ctx context.Context, form string) ([]FormRule, error) {
+		forms := cfg.GetStringMap(key)
+		raw, ok := forms[form]
+		if !ok {
+			// 配置中的键不区分大小写
+			raw, ok = forms[strings.ToLower(form)]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrFormNotFound, form)
+		}
+		var rules []FormRule
+		if err := z.ToStruct(raw, &rules); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrFormRuleInvalid, form, err)
+		}
+		return rules, nil
+	}
+}
+
+// FormRules 按表单名加载并缓存编译后的规则集，用于表单结构由用户定义的接口
+type FormRules struct {
+	Validator *Validator
+
+	loader FormRuleLoader
+	mu     sync.RWMutex
+	cache  map[string]*FormValidator
+}
+
+// NewFormRules 创建规则集注册表，规则集在首次使用时加载并编译
+func NewFormRules(loader FormRuleLoader, v *Validator) *FormRules {
+	return &FormRules{Validator: v, loader: loader, cache: map[string]*FormValidator{}}
+}
+
+// Get 返回表单的校验器，未缓存时通过 loader 加载并编译
+func (r *FormRules) Get(ctx context.Context, form string) (*FormValidator, error) {
+	r.mu.RLock()
+	fv, ok := r.cache[form]
+	r.mu.RUnlock()
+	if ok {
+		return fv, nil
+	}
+
+	if r.loader == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFormNotFound, form)
+	}
+	rules, err := r.loader(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	if fv, err = NewFormValidator(rules, r.Validator); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cache[form] = fv
+	r.mu.Unlock()
+	return fv, nil
+}
+
+// Validate 按表单的规则集校验数据
+func (r *FormRules) Validate(ctx context.Context, form string, data map[string]interface{}) error {
+	fv, err := r.Get(ctx, form)
+	if err != nil {
+		return err
+	}
+	return fv.Validate(ctx, data)
+}
+
+// Invalidate 清除已编译的规则集，规则变更后调用；不传表单名时清除全部
+func (r *FormRules) Invalidate(forms ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(forms) == 0 {
+		r.cache = map[string]*FormValidator{}
+		return
+	}
+	for _, form := range forms {
+		delete(r.cache, form)
+	}
+}
+
+type FormRulesIn struct {
+	fx.In
+
+	Config    *config_provider.Config `optional:"true"`
+	Validator *Validator              `optional:"true"`
+	Loader    FormRuleLoader          `optional:"true"`
+}
+
+// NewFormRulesProvider 未提供 FormRuleLoader 时从配置的 validation.forms 读取规则，配置变更后重新编译
+func NewFormRulesProvider(in FormRulesIn) *FormRules {
+	loader := in.Loader
+	if loader == nil && in.Config != nil {
+		loader = ConfigFormRuleLoader(in.Config, FormRulesConfigKey)
+	}
+	rules := NewFormRules(loader, in.Validator)
+	if in.Loader == nil && in.Config != nil {
+		in.Config.OnChange(func(e config_provider.ChangeEvent) {
+			if e.Has(FormRulesConfigKey) {
+				rules.Invalidate()
+			}
+		})
+	}
+	return rules
+}
+
+var FormRulesModule = fx.Options(
+	fx.Provide(NewFormRulesProvider),
+)
@@ -0,0 +1,519 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 导入变更类型
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+)
+
+// 导入进度阶段
+const (
+	ImportPhaseCommit = "commit"
+	ImportPhaseDone   = "done"
+	ImportPhaseFailed = "failed"
+)
+
+// ImportProgressEvent 导入进度事件名称（SSE / WebSocket）
+const ImportProgressEvent = "import.progress"
+
+var (
+	ErrImportNotFound    = errors.New("import: staged import not found or expired")
+	ErrImportInvalidRows = errors.New("import: staged import has invalid rows")
+	ErrImportTooManyRows = errors.New("import: too many rows")
+)
+
+// ImportRowError 行级错误，Field 为列名，解析整行失败时为空
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportRow 解析后的一行
+type ImportRow[R any] struct {
+	Row    int
+	Data   R
+	Errors []ImportRowError
+}
+
+// ImportFieldChange 字段变更前后的值
+type ImportFieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ImportChange 预览中一行将产生的变更，Fields 仅在 update 时给出
+type ImportChange struct {
+	Row    int                          `json:"row"`
+	Action string                       `json:"action"`
+	Key    interface{}                  `json:"key,omitempty"`
+	Fields map[string]ImportFieldChange `json:"fields,omitempty"`
+}
+
+// ImportPreview 暂存结果，提交前展示给用户确认
+type ImportPreview struct {
+	ID        string           `json:"id"`
+	Total     int              `json:"total"`
+	Valid     int              `json:"valid"`
+	Invalid   int              `json:"invalid"`
+	Creates   int              `json:"creates"`
+	Updates   int              `json:"updates"`
+	Unchanged int              `json:"unchanged"`
+	Errors    []ImportRowError `json:"errors"`
+	Changes   []ImportChange   `json:"changes"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+// ImportProgress 提交进度
+type ImportProgress struct {
+	ID    string `json:"id"`
+	Phase string `json:"phase"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+	Error string `json:"error,omitempty"`
+}
+
+// ImportResult 提交结果
+type ImportResult struct {
+	ID        string `json:"id"`
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+	Skipped   int    `json:"skipped"` // 校验未通过而跳过的行
+}
+
+// importStage 暂存的导入，models 与 preview.Changes 一一对应
+type importStage[T any] struct {
+	preview *ImportPreview
+	models  []T
+	columns map[int][]string // Changes 下标 -> 需更新的列
+}
+
+// Importer 通用导入流程：解析文件 -> 按请求结构校验（收集行级错误）-> 转换为模型并与已有记录对比后暂存到内存 -> 预览 -> 分批在事务中提交
+// R 为请求结构（binding 标签校验，import / json / label 标签对应列名），T 为模型
+//
+//	im := helpers.NewImporter[UserImportRequest, User](db, func(ctx context.Context, r UserImportRequest) (User, error) {
+//		return User{Name: r.Name, Phone: r.Phone}, nil
+//	})
+//	im.KeyColumn = "phone"
+//	preview, err := im.StageUpload(c, "file")          // 上传并预览
+//	result, err := im.Commit(ctx, preview.ID, progress) // 用户确认后提交
+type Importer[R any, T db_provider.IModel] struct {
+	DB        *db_provider.DB
+	Validator *Validator                                  // 翻译校验错误，为空时使用英文原始消息
+	Convert   func(ctx context.Context, row R) (T, error) // 请求结构 -> 模型，R 与 T 相同时可为空
+	KeyColumn string                                      // 匹配已有记录的列，命中时更新，为空时全部新增
+	Columns   []string                                    // 对比和更新的列，为空时为除主键、键列和自动时间外的全部列
+	BatchSize int                                         // 每批提交条数，默认 db_provider.DefaultBatchSize
+	MaxRows   int                                         // 单次导入最大行数，0 表示不限制
+	TTL       time.Duration                               // 暂存有效期，默认 30 分钟
+	Strict    bool                                        // 存在校验错误时拒绝提交
+
+	mu     sync.Mutex
+	stages map[string]*importStage[T]
+	expiry map[string]time.Time
+}
+
+// NewImporter 创建导入器
+func NewImporter[R any, T db_provider.IModel](db *db_provider.DB, convert func(ctx context.Context, row R) (T, error)) *Importer[R, T] {
+	return &Importer[R, T]{DB: db, Convert: convert}
+}
+
+// Validate 按请求结构的 binding 标签校验各行，错误追加到行的 Errors 中，每个字段一条
+func (im *Importer[R, T]) Validate(ctx context.Context, rows []ImportRow[R]) {
+	if binding.Validator == nil {
+		return
+	}
+	cols := columnsOf(reflect.TypeOf((*R)(nil)).Elem())
+	for i := range rows {
+		row := &rows[i]
+		if len(row.Errors) > 0 {
+			continue
+		}
+		err := binding.Validator.ValidateStruct(&row.Data)
+		if err == nil {
+			continue
+		}
+		var errs validator.ValidationErrors
+		if !errors.As(err, &errs) {
+			row.Errors = append(row.Errors, ImportRowError{Row: row.Row, Message: err.Error()})
+			continue
+		}
+		for _, fe := range errs {
+			row.Errors = append(row.Errors, ImportRowError{
+				Row:     row.Row,
+				Field:   cols.labels[fe.StructField()],
+				Message: im.Validator.TContext(ctx, validator.ValidationErrors{fe}, row.Data),
+			})
+		}
+	}
+}
+
+// Stage 校验、转换并与已有记录对比，暂存后返回预览；校验未通过的行计入 Errors，不参与提交
+func (im *Importer[R, T]) Stage(ctx context.Context, rows []ImportRow[R]) (*ImportPreview, error) {
+	if im.DB == nil {
+		return nil, errors.New("import: db is nil")
+	}
+	if im.MaxRows > 0 && len(rows) > im.MaxRows {
+		return nil, ErrImportTooManyRows
+	}
+	im.Validate(ctx, rows)
+
+	preview := &ImportPreview{ID: uuid.NewString(), Total: len(rows), Errors: []ImportRowError{}, Changes: []ImportChange{}}
+	stage := &importStage[T]{preview: preview, columns: map[int][]string{}}
+	var valid []ImportRow[R]
+	var models []T
+	for _, row := range rows {
+		if len(row.Errors) == 0 {
+			model, err := im.convert(ctx, row.Data)
+			if err == nil {
+				valid = append(valid, row)
+				models = append(models, model)
+				continue
+			}
+			row.Errors = append(row.Errors, ImportRowError{Row: row.Row, Message: err.Error()})
+		}
+		preview.Errors = append(preview.Errors, row.Errors...)
+		preview.Invalid++
+	}
+
+	if err := im.diff(ctx, stage, valid, models); err != nil {
+		return nil, err
+	}
+	preview.Valid = preview.Total - preview.Invalid
+	for _, change := range preview.Changes {
+		switch change.Action {
+		case ImportActionCreate:
+			preview.Creates++
+		case ImportActionUpdate:
+			preview.Updates++
+		default:
+			preview.Unchanged++
+		}
+	}
+
+	ttl := im.TTL
+	if ttl <= 0 {
+		ttl = 30 * time.Minute
+	}
+	preview.ExpiresAt = time.Now().Add(ttl)
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	im.sweepLocked()
+	if im.stages == nil {
+		im.stages = map[string]*importStage[T]{}
+		im.expiry = map[string]time.Time{}
+	}
+	im.stages[preview.ID] = stage
+	im.expiry[preview.ID] = preview.ExpiresAt
+	return preview, nil
+}
+
+func (im *Importer[R, T]) convert(ctx context.Context, row R) (T, error) {
+	if im.Convert != nil {
+		return im.Convert(ctx, row)
+	}
+	if model, ok := any(row).(T); ok {
+		return model, nil
+	}
+	var zero T
+	return zero, errors.New("import: Convert is required")
+}
+
+// diff 按 KeyColumn 查询已有记录，确定每行是新增、更新还是无变化；文件内键重复的行记为错误
+func (im *Importer[R, T]) diff(ctx context.Context, stage *importStage[T], rows []ImportRow[R], models []T) error {
+	preview := stage.preview
+	if len(rows) == 0 {
+		return nil
+	}
+	sch, err := im.schema()
+	if err != nil {
+		return err
+	}
+
+	var keyField *schema.Field
+	existing := map[string]reflect.Value{}
+	if im.KeyColumn != "" {
+		if keyField = sch.LookUpField(im.KeyColumn); keyField == nil {
+			return fmt.Errorf("import: unknown key column %q", im.KeyColumn)
+		}
+		keys := make([]interface{}, 0, len(models))
+		for i := range models {
+			if v, zero := keyField.ValueOf(ctx, reflect.ValueOf(&models[i]).Elem()); !zero {
+				keys = append(keys, v)
+			}
+		}
+		for start := 0; start < len(keys); start += db_provider.DefaultBatchSize {
+			end := min(start+db_provider.DefaultBatchSize, len(keys))
+			var found []T
+			err := im.DB.WithContext(ctx).Model(new(T)).Where(im.DB.F(keyField.DBName)+" IN ?", keys[start:end]).Find(&found).Error
+			if err != nil {
+				return db_provider.WrapDBError(err)
+			}
+			for i := range found {
+				rv := reflect.ValueOf(&found[i]).Elem()
+				v, _ := keyField.ValueOf(ctx, rv)
+				existing[fmt.Sprint(v)] = rv
+			}
+		}
+	}
+	fields, err := im.compareFields(sch, keyField)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]int{}
+	for i, row := range rows {
+		rv := reflect.ValueOf(&models[i]).Elem()
+		change := ImportChange{Row: row.Row, Action: ImportActionCreate}
+		if keyField != nil {
+			if v, zero := keyField.ValueOf(ctx, rv); !zero {
+				change.Key = v
+				key := fmt.Sprint(v)
+				if first, dup := seen[key]; dup {
+					preview.Errors = append(preview.Errors, ImportRowError{Row: row.Row, Field: im.KeyColumn, Message: fmt.Sprintf("duplicate key %v, first seen in row %d", v, first)})
+					preview.Invalid++
+					continue
+				}
+				seen[key] = row.Row
+				if old, ok := existing[key]; ok {
+					change.Action = ImportActionUnchanged
+					var columns []string
+					for _, f := range fields {
+						oldV, _ := f.ValueOf(ctx, old)
+						newV, _ := f.ValueOf(ctx, rv)
+						if importValueEqual(oldV, newV) {
+							continue
+						}
+						if change.Fields == nil {
+							change.Fields = map[string]ImportFieldChange{}
+						}
+						change.Fields[f.DBName] = ImportFieldChange{Old: oldV, New: newV}
+						columns = append(columns, f.DBName)
+					}
+					if len(columns) > 0 {
+						change.Action = ImportActionUpdate
+						stage.columns[len(preview.Changes)] = columns
+					}
+				}
+			}
+		}
+		preview.Changes = append(preview.Changes, change)
+		stage.models = append(stage.models, models[i])
+	}
+	return nil
+}
+
+func (im *Importer[R, T]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: im.DB.DB}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, fmt.Errorf("import: parse model: %w", err)
+	}
+	return stmt.Schema, nil
+}
+
+// compareFields 返回参与对比和更新的字段
+func (im *Importer[R, T]) compareFields(sch *schema.Schema, keyField *schema.Field) ([]*schema.Field, error) {
+	if len(im.Columns) > 0 {
+		fields := make([]*schema.Field, 0, len(im.Columns))
+		for _, name := range im.Columns {
+			f := sch.LookUpField(name)
+			if f == nil || f.DBName == "" {
+				return nil, fmt.Errorf("import: unknown column %q", name)
+			}
+			fields = append(fields, f)
+		}
+		return fields, nil
+	}
+	deletedAt := reflect.TypeOf(gorm.DeletedAt{})
+	var fields []*schema.Field
+	for _, f := range sch.Fields {
+		if f.DBName == "" || f.PrimaryKey || !f.Updatable || f == keyField ||
+			f.AutoCreateTime > 0 || f.AutoUpdateTime > 0 || f.FieldType == deletedAt {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func importValueEqual(a, b interface{}) bool {
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Equal(tb)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Preview 返回暂存的预览
+func (im *Importer[R, T]) Preview(id string) (*ImportPreview, error) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	im.sweepLocked()
+	stage, ok := im.stages[id]
+	if !ok {
+		return nil, ErrImportNotFound
+	}
+	return stage.preview, nil
+}
+
+// Discard 丢弃暂存的导入
+func (im *Importer[R, T]) Discard(id string) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	delete(im.stages, id)
+	delete(im.expiry, id)
+}
+
+// sweepLocked 清理过期的暂存，调用方需持有 mu
+func (im *Importer[R, T]) sweepLocked() {
+	now := time.Now()
+	for id, at := range im.expiry {
+		if now.After(at) {
+			delete(im.stages, id)
+			delete(im.expiry, id)
+		}
+	}
+}
+
+// Commit 在一个事务中分批提交暂存的变更，每批完成后回调 progress；任一批失败时整体回滚，暂存保留可重试
+// 同一暂存同时只能有一次提交，成功后暂存被移除
+func (im *Importer[R, T]) Commit(ctx context.Context, id string, progress func(ImportProgress)) (*ImportResult, error) {
+	im.mu.Lock()
+	im.sweepLocked()
+	stage, ok := im.stages[id]
+	if ok {
+		delete(im.stages, id)
+	}
+	im.mu.Unlock()
+	if !ok {
+		return nil, ErrImportNotFound
+	}
+	preview := stage.preview
+	if im.Strict && preview.Invalid > 0 {
+		im.restore(id, stage)
+		return nil, ErrImportInvalidRows
+	}
+
+	report := func(p ImportProgress) {
+		if progress != nil {
+			p.ID, p.Total = id, len(preview.Changes)
+			progress(p)
+		}
+	}
+	result := &ImportResult{ID: id, Skipped: preview.Invalid}
+	batchSize := im.BatchSize
+	if batchSize <= 0 {
+		batchSize = db_provider.DefaultBatchSize
+	}
+
+	err := im.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		autoUpdate, err := im.autoUpdateColumns()
+		if err != nil {
+			return err
+		}
+		report(ImportProgress{Phase: ImportPhaseCommit})
+		for start := 0; start < len(preview.Changes); start += batchSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			end := min(start+batchSize, len(preview.Changes))
+			var creates []T
+			for i := start; i < end; i++ {
+				change := preview.Changes[i]
+				switch change.Action {
+				case ImportActionCreate:
+					creates = append(creates, stage.models[i])
+				case ImportActionUpdate:
+					columns := append(append([]string{}, stage.columns[i]...), autoUpdate...)
+					err := tx.Model(new(T)).Where(im.DB.F(im.KeyColumn)+" = ?", change.Key).Select(columns).Updates(&stage.models[i]).Error
+					if err != nil {
+						return fmt.Errorf("row %d: %w", change.Row, db_provider.WrapDBError(err))
+					}
+					result.Updated++
+				default:
+					result.Unchanged++
+				}
+			}
+			if len(creates) > 0 {
+				if err := tx.Create(&creates).Error; err != nil {
+					return fmt.Errorf("rows %d-%d: %w", preview.Changes[start].Row, preview.Changes[end-1].Row, db_provider.WrapDBError(err))
+				}
+				result.Created += len(creates)
+			}
+			report(ImportProgress{Phase: ImportPhaseCommit, Done: end})
+		}
+		return nil
+	})
+	if err != nil {
+		im.restore(id, stage)
+		report(ImportProgress{Phase: ImportPhaseFailed, Error: err.Error()})
+		return nil, err
+	}
+	im.Discard(id)
+	report(ImportProgress{Phase: ImportPhaseDone, Done: len(preview.Changes)})
+	return result, nil
+}
+
+// autoUpdateColumns 返回自动更新时间列，Select 指定列时 gorm 不会自动写入
+func (im *Importer[R, T]) autoUpdateColumns() ([]string, error) {
+	sch, err := im.schema()
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	for _, f := range sch.Fields {
+		if f.DBName != "" && f.AutoUpdateTime > 0 {
+			columns = append(columns, f.DBName)
+		}
+	}
+	return columns, nil
+}
+
+// restore 提交失败后放回暂存，已过期的不再放回
+func (im *Importer[R, T]) restore(id string, stage *importStage[T]) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if at, ok := im.expiry[id]; ok && time.Now().Before(at) {
+		im.stages[id] = stage
+	}
+}
+
+// SSEImportProgress 将进度以 import.progress 事件写入 SSE 流，可直接作为 Commit 的 progress 参数
+//
+//	b.StreamHandler(c, "import.commit", func(ctx context.Context, s *z.StreamSender) error {
+//		_, err := im.Commit(ctx, c.Param("id"), helpers.SSEImportProgress(s))
+//		return err
+//	})
+//
+// 通过 WebSocket 推送时：
+//
+//	progress := func(p helpers.ImportProgress) {
+//		env := websocket_server.NewEnvelope("ws." + helpers.ImportProgressEvent)
+//		env.Data = p
+//		ws.Push(websocket_server.PushTarget{Guard: guard, UserID: userID}, env)
+//	}
+func SSEImportProgress(s *z.StreamSender) func(ImportProgress) {
+	return func(p ImportProgress) {
+		_ = s.SendJSON(ImportProgressEvent, p)
+	}
+}
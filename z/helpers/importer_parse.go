@@ -0,0 +1,306 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+)
+
+// 导入文件格式
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+// importTimeLayouts 解析时间列时依次尝试的格式
+var importTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02", "2006/01/02 15:04:05", "2006/01/02"}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// importColumns 请求结构的列名 -> 字段索引，列名不区分大小写
+type importColumns struct {
+	index  map[string][]int
+	labels map[string]string // 字段名 -> 错误提示中使用的列名
+}
+
+// importColumnCache 请求结构类型 -> *importColumns
+var importColumnCache sync.Map
+
+// columnsOf 解析请求结构的列名：依次接受 import、json、label 标签和字段名，import:"-" 表示不参与导入
+func columnsOf(t reflect.Type) *importColumns {
+	if cached, ok := importColumnCache.Load(t); ok {
+		return cached.(*importColumns)
+	}
+	cols := &importColumns{index: map[string][]int{}, labels: map[string]string{}}
+	var walk func(t reflect.Type, prefix []int)
+	walk = func(t reflect.Type, prefix []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			idx := append(append([]int{}, prefix...), i)
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				walk(f.Type, idx)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			tag := f.Tag.Get("import")
+			if tag == "-" {
+				continue
+			}
+			jsonName := strings.Split(f.Tag.Get("json"), ",")[0]
+			label := f.Tag.Get("label")
+			for _, name := range []string{tag, jsonName, label, f.Name} {
+				name = strings.ToLower(strings.TrimSpace(name))
+				if name == "" || name == "-" {
+					continue
+				}
+				if _, ok := cols.index[name]; !ok {
+					cols.index[name] = idx
+				}
+			}
+			cols.labels[f.Name] = firstImportName(tag, label, jsonName, f.Name)
+		}
+	}
+	walk(t, nil)
+	importColumnCache.Store(t, cols)
+	return cols
+}
+
+func firstImportName(values ...string) string {
+	for _, v := range values {
+		if v != "" && v != "-" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ParseImport 将 CSV（首行为表头）或 JSON 数组解析为请求结构，单元格无法转换的行记录行级错误并继续
+// 行号从 1 开始，不含表头，空行跳过但计入行号；maxRows > 0 时超出返回 ErrImportTooManyRows
+func ParseImport[R any](r io.Reader, format string, maxRows int) ([]ImportRow[R], error) {
+	switch strings.ToLower(format) {
+	case ImportFormatCSV:
+		return parseImportCSV[R](r, maxRows)
+	case ImportFormatJSON:
+		return parseImportJSON[R](r, maxRows)
+	}
+	return nil, fmt.Errorf("import: unsupported format %q", format)
+}
+
+func parseImportCSV[R any](r io.Reader, maxRows int) ([]ImportRow[R], error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Excel 导出的 CSV 带有 UTF-8 BOM
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("import: read header: %w", err)
+	}
+	t := reflect.TypeOf((*R)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("import: row type %s is not a struct", t)
+	}
+	cols := columnsOf(t)
+	fields := make([][]int, len(header))
+	for i, name := range header {
+		fields[i] = cols.index[strings.ToLower(strings.TrimSpace(name))]
+	}
+
+	var rows []ImportRow[R]
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("import: read row %d: %w", n, err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if maxRows > 0 && len(rows) >= maxRows {
+			return nil, ErrImportTooManyRows
+		}
+		row := ImportRow[R]{Row: n}
+		rv := reflect.ValueOf(&row.Data).Elem()
+		for i, cell := range record {
+			if i >= len(fields) || fields[i] == nil {
+				continue
+			}
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			field := rv.FieldByIndex(fields[i])
+			if err := setImportValue(field, cell); err != nil {
+				name := t.FieldByIndex(fields[i]).Name
+				row.Errors = append(row.Errors, ImportRowError{Row: n, Field: cols.labels[name], Message: err.Error()})
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseImportJSON[R any](r io.Reader, maxRows int) ([]ImportRow[R], error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("import: decode json: %w", err)
+	}
+	if maxRows > 0 && len(raw) > maxRows {
+		return nil, ErrImportTooManyRows
+	}
+	rows := make([]ImportRow[R], len(raw))
+	for i, item := range raw {
+		rows[i].Row = i + 1
+		if err := json.Unmarshal(item, &rows[i].Data); err != nil {
+			rows[i].Errors = append(rows[i].Errors, ImportRowError{Row: i + 1, Message: err.Error()})
+		}
+	}
+	return rows, nil
+}
+
+// setImportValue 将单元格文本写入字段，支持基础类型、time.Time、指针、逗号分隔的切片和 encoding.TextUnmarshaler
+func setImportValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setImportValue(v.Elem(), s)
+	}
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		for _, layout := range importTimeLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid time %q", s)
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := strings.Split(s, ",")
+		out := reflect.MakeSlice(v.Type(), 0, len(parts))
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			item := reflect.New(v.Type().Elem()).Elem()
+			if err := setImportValue(item, part); err != nil {
+				return err
+			}
+			out = reflect.Append(out, item)
+		}
+		v.Set(out)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// importFormatOf 按文件扩展名判断格式
+func importFormatOf(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return ImportFormatCSV, nil
+	case ".json":
+		return ImportFormatJSON, nil
+	}
+	return "", fmt.Errorf("import: unsupported file type %q", filepath.Ext(filename))
+}
+
+// StageUpload 读取上传文件（表单字段 field，按扩展名识别 csv / json），解析、校验并暂存
+func (im *Importer[R, T]) StageUpload(c *gin.Context, field string) (*ImportPreview, error) {
+	fh, err := c.FormFile(field)
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+	format, err := importFormatOf(fh.Filename)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return im.StageReader(c.Request.Context(), f, format)
+}
+
+// StageReader 解析、校验并暂存
+func (im *Importer[R, T]) StageReader(ctx context.Context, r io.Reader, format string) (*ImportPreview, error) {
+	rows, err := ParseImport[R](r, format, im.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("import: no rows")
+	}
+	return im.Stage(ctx, rows)
+}
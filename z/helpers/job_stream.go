@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/job_provider"
+)
+
+// JobAccepted 长耗时操作受理响应，前端通过 StatusURL 订阅进度
+type JobAccepted struct {
+	JobID     string                 `json:"job_id"`
+	Status    job_provider.JobStatus `json:"status"`
+	StatusURL string                 `json:"status_url"`
+}
+
+// 任务进度 SSE 事件名：执行中为 progress，结束时为 completed 或 failed，数据均为 JobProgress
+const (
+	JobStreamEventProgress = "progress"
+)
+
+// jobStreamKeepAlive SSE 保活间隔
+const jobStreamKeepAlive = 15 * time.Second
+
+// StartJob 开启进度跟踪并投递任务，返回 JobAccepted（Code 为 StatusAccepted）
+// statusURL 中的 :id 替换为任务 ID，对应的路由使用 StreamJob 输出进度
+//
+//	func (ctl *ExportController) Create(c *gin.Context) {
+//		ctl.StartJob(c, ctl.Jobs, "order.export", req, "/exports/:id/events")
+//	}
+func (b *BaseController) StartJob(c *gin.Context, client *job_provider.JobClient, name string, payload any, statusURL string, opt ...*job_provider.AddJobOptions) {
+	options := job_provider.AddJobOptions{}
+	if len(opt) > 0 && opt[0] != nil {
+		options = *opt[0]
+	}
+	options.TrackProgress = true
+
+	info, err := client.AddJob(c.Request.Context(), name, payload, &options)
+	if err != nil {
+		z.Failure(c, err)
+		return
+	}
+	if info == nil {
+		// 相同 TaskID 的任务已存在
+		z.Failure(c, "job already exists", z.StatusConflict)
+		return
+	}
+	z.Success(c, JobAccepted{
+		JobID:     info.ID,
+		Status:    job_provider.JobStatusPending,
+		StatusURL: strings.ReplaceAll(statusURL, ":id", info.ID),
+	}, z.StatusAccepted)
+}
+
+// StreamJob 以 SSE 推送任务进度直到任务结束：先发送当前快照，之后每次更新发送一次，
+// 事件 ID 为进度序号，客户端携带 Last-Event-ID 重连时不重复发送已收到的进度
+// 任务不存在或未开启进度跟踪时返回 StatusNotFound；调用方应先校验当前用户能否访问该任务
+func (b *BaseController) StreamJob(c *gin.Context, client *job_provider.JobClient, id string) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	updates, err := client.WatchProgress(ctx, id)
+	if err != nil {
+		if errors.Is(err, job_provider.ErrProgressNotFound) {
+			z.Failure(c, "job not found", z.StatusNotFound)
+			return
+		}
+		z.Failure(c, err)
+		return
+	}
+
+	b.StreamHandler(c, "job.stream", func(ctx context.Context, s *z.StreamSender) error {
+		s.OnDisconnect(cancel)
+		s.KeepAlive(jobStreamKeepAlive)
+		lastSeen, _ := strconv.ParseInt(s.LastEventID(), 10, 64)
+		for p := range updates {
+			if p.Seq <= lastSeen && !p.Finished() {
+				continue
+			}
+			data, err := json.Marshal(p)
+			if err != nil {
+				return err
+			}
+			event := JobStreamEventProgress
+			if p.Finished() {
+				event = string(p.Status)
+			}
+			if err := s.Send(z.StreamEvent{ID: strconv.FormatInt(p.Seq, 10), Event: event, Data: string(data)}); err != nil {
+				return nil
+			}
+		}
+		return nil
+	})
+}
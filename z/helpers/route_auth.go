@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/auth_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/permission_provider"
+)
+
+// CRUD 动作名称
+const (
+	ActionGet    = "get"
+	ActionPage   = "page"
+	ActionFind   = "find"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	ActionAttach = "attach" // 多对多关联，见 RelationController
+	ActionDetach = "detach"
+	ActionSync   = "sync"
+)
+
+// ActionAuth 单个动作的认证要求，Guard 为空表示公开访问
+type ActionAuth struct {
+	Guard       string // 认证 guard，多个以逗号分隔
+	Permissions string // 权限要求，格式同 permission_provider.PermissionMiddleware，为空时仅校验登录
+}
+
+// RouteAuth 按动作声明的路由认证要求，未声明的动作公开访问
+//
+//	ra := helpers.WithAuth("admin", map[string]string{
+//		helpers.ActionCreate: "article:manage",
+//		helpers.ActionUpdate: "article:manage",
+//		helpers.ActionDelete: "article:manage",
+//	}).Bind(auth, perm)
+//	g.GET("/articles", ra.Handlers(helpers.ActionPage, ctl.Page)...)
+//	ra.Handle(g, http.MethodPost, "/articles", helpers.ActionCreate, ctl.Create) // 同时记录认证要求，供 z.Routes 导出
+type RouteAuth struct {
+	auth    *auth_provider.Auth
+	perm    *permission_provider.Provider
+	actions map[string]ActionAuth
+}
+
+// WithAuth 声明 permissions 中列出的动作需要通过 guard 认证并具有对应权限
+func WithAuth(guard string, permissions map[string]string) *RouteAuth {
+	r := &RouteAuth{actions: map[string]ActionAuth{}}
+	for action, perms := range permissions {
+		r.actions[action] = ActionAuth{Guard: guard, Permissions: perms}
+	}
+	return r
+}
+
+// Bind 设置认证与权限实例，未设置权限实例时仅校验登录
+func (r *RouteAuth) Bind(auth *auth_provider.Auth, perm *permission_provider.Provider) *RouteAuth {
+	r.auth = auth
+	r.perm = perm
+	return r
+}
+
+// Require 声明动作的认证要求
+func (r *RouteAuth) Require(action string, req ActionAuth) *RouteAuth {
+	r.actions[action] = req
+	return r
+}
+
+// Public 声明动作公开访问
+func (r *RouteAuth) Public(actions ...string) *RouteAuth {
+	for _, action := range actions {
+		delete(r.actions, action)
+	}
+	return r
+}
+
+// Get 返回动作的认证要求
+func (r *RouteAuth) Get(action string) (ActionAuth, bool) {
+	req, ok := r.actions[action]
+	return req, ok && req.Guard != ""
+}
+
+// Handlers 返回动作的处理链：认证 → 权限 → handlers
+// 声明了认证要求但未 Bind 对应实例时拒绝访问，避免配置遗漏导致接口公开
+func (r *RouteAuth) Handlers(action string, handlers ...gin.HandlerFunc) []gin.HandlerFunc {
+	req, ok := r.Get(action)
+	if !ok {
+		return handlers
+	}
+	chain := make([]gin.HandlerFunc, 0, len(handlers)+2)
+	if r.auth == nil || (req.Permissions != "" && r.perm == nil) {
+		chain = append(chain, func(c *gin.Context) {
+			c.AbortWithStatusJSON(403, gin.H{
+				"success": false,
+				"message": "route auth not configured",
+				"code":    403,
+			})
+		})
+		return append(chain, handlers...)
+	}
+	chain = append(chain, auth_provider.RequireGuard(r.auth, req.Guard))
+	if req.Permissions != "" {
+		chain = append(chain, r.perm.PermissionMiddleware(req.Permissions))
+	}
+	return append(chain, handlers...)
+}
+
+// Handle 注册路由并通过 z.SetRouteAuth 记录其认证要求，z.Routes / z.PermissionMap 导出时可见
+func (r *RouteAuth) Handle(g gin.IRoutes, method, relativePath, action string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	fullPath := relativePath
+	if group, ok := g.(interface{ BasePath() string }); ok {
+		fullPath = joinRoutePath(group.BasePath(), relativePath)
+	}
+	meta := z.RouteAuthMeta{Action: action, Public: true}
+	if req, ok := r.Get(action); ok {
+		meta.Public = false
+		meta.Guards = splitList(req.Guard, ",")
+		meta.Permissions = expandPermissions(req.Permissions)
+	}
+	z.SetRouteAuth(method, fullPath, meta)
+	return g.Handle(method, relativePath, r.Handlers(action, handlers...)...)
+}
+
+// joinRoutePath 与 gin 分组的路径拼接规则一致，保留末尾斜杠
+func joinRoutePath(base, relativePath string) string {
+	if relativePath == "" {
+		return base
+	}
+	joined := path.Join(base, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
+
+// expandPermissions 将 "article:create,update;user:list" 展开为 article:create、article:update、user:list
+func expandPermissions(permissions string) []string {
+	var out []string
+	for _, group := range splitList(permissions, ";") {
+		resource, actions, ok := strings.Cut(group, ":")
+		resource = strings.TrimSpace(resource)
+		if !ok || resource == "" {
+			continue
+		}
+		for _, action := range splitList(actions, ",") {
+			out = append(out, resource+":"+action)
+		}
+	}
+	return out
+}
+
+func splitList(s, sep string) []string {
+	var out []string
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package z
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+)
+
+// JSONStream 流式输出列表响应，结构与 Response 一致：{"message":[...],"success":true,"code":200}
+// 客户端支持 gzip 时自动压缩；逐条编码写出，不在内存中构建完整列表
+type JSONStream struct {
+	w       io.Writer
+	gz      *gzip.Writer
+	flusher http.Flusher
+	count   int
+	closed  bool
+}
+
+// NewJSONStream 写入响应头并开始输出列表
+func NewJSONStream(c *gin.Context) *JSONStream {
+	header := c.Writer.Header()
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Del("Content-Length")
+	header.Add("Vary", "Accept-Encoding")
+
+	s := &JSONStream{w: c.Writer}
+	if strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+		header.Set("Content-Encoding", "gzip")
+		s.gz = gzip.NewWriter(c.Writer)
+		s.w = s.gz
+	}
+	if f, ok := c.Writer.(http.Flusher); ok {
+		s.flusher = f
+	}
+	c.Writer.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(s.w, `{"message":[`)
+	return s
+}
+
+// Write 编码并写出一条记录
+func (s *JSONStream) Write(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if s.count > 0 {
+		if _, err := io.WriteString(s.w, ","); err != nil {
+			return err
+		}
+	}
+	if _, err := s.w.Write(b); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+// Flush 将已写出的数据刷新到客户端
+func (s *JSONStream) Flush() {
+	if s.gz != nil {
+		_ = s.gz.Flush()
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+// Count 返回已写出的记录数
+func (s *JSONStream) Count() int {
+	return s.count
+}
+
+// Close 结束列表输出；err 不为空时响应体中 success 为 false 并附带 error 字段
+func (s *JSONStream) Close(err error) error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	tail := `],"success":true,"code":200}`
+	if err != nil {
+		msg, _ := json.Marshal(err.Error())
+		tail = `],"success":false,"code":` + strconv.Itoa(int(StatusInternalError)) + `,"error":` + string(msg) + `}`
+	}
+	_, werr := io.WriteString(s.w, tail)
+	if s.gz != nil {
+		if cerr := s.gz.Close(); werr == nil {
+			werr = cerr
+		}
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return werr
+}
@@ -0,0 +1,485 @@
+package auth_provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/ulule/limiter/v3"
+	limitermemory "github.com/ulule/limiter/v3/drivers/store/memory"
+	limiterredis "github.com/ulule/limiter/v3/drivers/store/redis"
+	"go.uber.org/fx"
+)
+
+// API Key 存储类型
+const (
+	APIKeyStoreConfig = "config" // guard 配置中的 keys 列表，只读
+	APIKeyStoreCache  = "cache"  // guard cache 配置的存储后端（默认）
+)
+
+// defaultAPIKeyHeader 读取 API Key 的默认请求头
+const defaultAPIKeyHeader = "X-API-Key"
+
+// apiKeyTouchInterval 最近使用时间的最小更新间隔
+const apiKeyTouchInterval = time.Minute
+
+// API Key 相关错误
+var (
+	ErrAPIKeyNotFound    = &AuthError{Code: "API_KEY_NOT_FOUND", Message: "api key not found"}
+	ErrAPIKeyRevoked     = &AuthError{Code: "API_KEY_REVOKED", Message: "api key revoked"}
+	ErrAPIKeyRateLimited = &AuthError{Code: "API_KEY_RATE_LIMITED", Message: "api key rate limit exceeded"}
+	ErrAPIKeyReadOnly    = errors.New("api key store is read-only")
+)
+
+// APIKey API Key 记录，仅保存密钥的 SHA-256 哈希，明文只在创建时返回一次
+type APIKey struct {
+	ID         string                 `json:"id"`
+	Guard      string                 `json:"guard"`
+	Name       string                 `json:"name"`
+	Owner      string                 `json:"owner"` // 所属用户，认证后作为 UserID，为空时使用 ID
+	Hint       string                 `json:"hint"`  // 密钥末 4 位，便于识别
+	Hash       string                 `json:"hash,omitempty"`
+	Scopes     []string               `json:"scopes"`
+	RateLimit  string                 `json:"rate_limit,omitempty"` // 单个密钥的速率，如 100-M，为空表示不限制
+	Data       map[string]interface{} `json:"data,omitempty"`
+	CreatedAt  int64                  `json:"created_at"`
+	ExpiresAt  int64                  `json:"expires_at"` // 0 表示不过期
+	LastUsedAt int64                  `json:"last_used_at"`
+	RevokedAt  int64                  `json:"revoked_at"`
+}
+
+// view 返回不含哈希的副本
+func (k *APIKey) view() *APIKey {
+	out := *k
+	out.Hash = ""
+	out.Scopes = append([]string(nil), k.Scopes...)
+	return &out
+}
+
+// APIKeyOptions CreateKey 参数
+type APIKeyOptions struct {
+	Name      string
+	Owner     string
+	Scopes    []string
+	RateLimit string        // 如 100-M
+	TTL       time.Duration // <= 0 表示不过期
+	Data      map[string]interface{}
+}
+
+// APIKeyStore API Key 存储后端
+type APIKeyStore interface {
+	// FindByHash 按密钥哈希查找，不存在时返回 nil, nil
+	FindByHash(ctx context.Context, guard, hash string) (*APIKey, error)
+	// Save 新增或更新
+	Save(ctx context.Context, key *APIKey) error
+	// List 返回 guard 下的全部密钥
+	List(ctx context.Context, guard string) ([]*APIKey, error)
+}
+
+// APIKeyToucher 可选接口：仅更新最近使用时间，不重写整条记录，避免多实例下覆盖其它实例的吊销
+// 未实现时不记录最近使用时间
+type APIKeyToucher interface {
+	Touch(ctx context.Context, guard, id string, at int64) error
+}
+
+// NamedAPIKeyStore 具名的 API Key 存储后端
+type NamedAPIKeyStore struct {
+	Name  string
+	Store APIKeyStore
+}
+
+// APIKeyStoreOut 注册 API Key 存储后端的 fx 出参
+type APIKeyStoreOut struct {
+	fx.Out
+
+	Store NamedAPIKeyStore `group:"auth_apikey_stores"`
+}
+
+// RegisterAPIKeyStore 注册 API Key 存储后端，guard 配置 key_store: <name> 时使用
+//
+//	fx.Provide(func(db *db_provider.DB) auth_provider.APIKeyStoreOut {
+//		return auth_provider.RegisterAPIKeyStore("database", db_provider.NewDBAPIKeyStore(db))
+//	})
+func RegisterAPIKeyStore(name string, store APIKeyStore) APIKeyStoreOut {
+	return APIKeyStoreOut{Store: NamedAPIKeyStore{Name: name, Store: store}}
+}
+
+// APIKeyFunc 以回调查找密钥的只读存储，适用于由外部系统管理密钥的场景
+type APIKeyFunc func(ctx context.Context, guard, hash string) (*APIKey, error)
+
+func (f APIKeyFunc) FindByHash(ctx context.Context, guard, hash string) (*APIKey, error) {
+	return f(ctx, guard, hash)
+}
+
+func (f APIKeyFunc) Save(context.Context, *APIKey) error { return ErrAPIKeyReadOnly }
+
+func (f APIKeyFunc) List(context.Context, string) ([]*APIKey, error) { return nil, ErrAPIKeyReadOnly }
+
+// HashAPIKey 返回密钥的 SHA-256 哈希（十六进制），用于配置 key_hash 或自定义存储
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// configAPIKeyStore guard 配置中的静态密钥
+type configAPIKeyStore struct {
+	keys map[string]*APIKey // 哈希 -> 密钥
+}
+
+func (s *configAPIKeyStore) FindByHash(_ context.Context, _ string, hash string) (*APIKey, error) {
+	if k, ok := s.keys[hash]; ok {
+		out := *k
+		return &out, nil
+	}
+	return nil, nil
+}
+
+func (s *configAPIKeyStore) Save(context.Context, *APIKey) error { return ErrAPIKeyReadOnly }
+
+func (s *configAPIKeyStore) List(context.Context, string) ([]*APIKey, error) {
+	out := make([]*APIKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		out = append(out, k.view())
+	}
+	return out, nil
+}
+
+// loadConfigAPIKeys 读取 auth.guards.<guard>.keys.<id>：key 或 key_hash、owner、scopes、rate_limit
+func loadConfigAPIKeys(a *Auth, guardName string) (*configAPIKeyStore, error) {
+	prefix := "auth.guards." + guardName + ".keys"
+	store := &configAPIKeyStore{keys: map[string]*APIKey{}}
+	for id := range a.cfg.GetStringMap(prefix) {
+		p := prefix + "." + id
+		hash := strings.ToLower(strings.TrimSpace(a.cfg.GetString(p + ".key_hash")))
+		if key := a.cfg.GetString(p + ".key"); key != "" {
+			hash = HashAPIKey(key)
+		}
+		if len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("%s: key or key_hash (sha256 hex) is required", p)
+		}
+		k := &APIKey{
+			ID:        id,
+			Guard:     guardName,
+			Name:      a.cfg.GetString(p + ".name"),
+			Owner:     a.cfg.GetString(p + ".owner"),
+			Hint:      hash[len(hash)-4:],
+			Hash:      hash,
+			Scopes:    a.cfg.GetStringSlice(p + ".scopes"),
+			RateLimit: strings.TrimSpace(a.cfg.GetString(p + ".rate_limit")),
+		}
+		if k.RateLimit != "" {
+			if _, err := limiter.NewRateFromFormatted(k.RateLimit); err != nil {
+				return nil, fmt.Errorf("%s.rate_limit: %w", p, err)
+			}
+		}
+		store.keys[hash] = k
+	}
+	return store, nil
+}
+
+// cacheAPIKeyStore 将密钥保存在 guard 的 cache 存储中
+type cacheAPIKeyStore struct {
+	a *Auth
+}
+
+func (s *cacheAPIKeyStore) recordKey(guard, id string) string {
+	return fmt.Sprintf("auth_apikey_%s_%s", guard, id)
+}
+
+func (s *cacheAPIKeyStore) hashKey(guard, hash string) string {
+	return fmt.Sprintf("auth_apikey_hash_%s_%s", guard, hash)
+}
+
+func (s *cacheAPIKeyStore) indexKey(guard string) string {
+	return fmt.Sprintf("auth_apikey_index_%s", guard)
+}
+
+// usedKey 最近使用时间单独存放，更新时不重写记录
+func (s *cacheAPIKeyStore) usedKey(guard, id string) string {
+	return fmt.Sprintf("auth_apikey_used_%s_%s", guard, id)
+}
+
+// Touch 实现 APIKeyToucher
+func (s *cacheAPIKeyStore) Touch(_ context.Context, guard, id string, at int64) error {
+	return s.a.setCache(guard, s.usedKey(guard, id), at, 0)
+}
+
+// loadUsed 合并单独存放的最近使用时间
+func (s *cacheAPIKeyStore) loadUsed(k *APIKey) {
+	var at int64
+	if exists, err := s.a.getCache(k.Guard, s.usedKey(k.Guard, k.ID), &at); err == nil && exists && at > k.LastUsedAt {
+		k.LastUsedAt = at
+	}
+}
+
+func (s *cacheAPIKeyStore) FindByHash(_ context.Context, guard, hash string) (*APIKey, error) {
+	var id string
+	exists, err := s.a.getCache(guard, s.hashKey(guard, hash), &id)
+	if err != nil || !exists {
+		return nil, err
+	}
+	var k APIKey
+	exists, err = s.a.getCache(guard, s.recordKey(guard, id), &k)
+	if err != nil || !exists {
+		return nil, err
+	}
+	s.loadUsed(&k)
+	return &k, nil
+}
+
+func (s *cacheAPIKeyStore) Save(_ context.Context, k *APIKey) error {
+	if err := s.a.setCache(k.Guard, s.recordKey(k.Guard, k.ID), k, 0); err != nil {
+		return err
+	}
+	// 吊销后保留记录与哈希索引，认证时返回 API_KEY_REVOKED
+	if err := s.a.setCache(k.Guard, s.hashKey(k.Guard, k.Hash), k.ID, 0); err != nil {
+		return err
+	}
+
+	s.a.apiKeyMu.Lock()
+	defer s.a.apiKeyMu.Unlock()
+	var ids []string
+	if _, err := s.a.getCache(k.Guard, s.indexKey(k.Guard), &ids); err != nil {
+		return err
+	}
+	if z.InStringSlice(ids, k.ID) {
+		return nil
+	}
+	return s.a.setCache(k.Guard, s.indexKey(k.Guard), append(ids, k.ID), 0)
+}
+
+func (s *cacheAPIKeyStore) List(_ context.Context, guard string) ([]*APIKey, error) {
+	var ids []string
+	if _, err := s.a.getCache(guard, s.indexKey(guard), &ids); err != nil {
+		return nil, err
+	}
+	out := make([]*APIKey, 0, len(ids))
+	for _, id := range ids {
+		var k APIKey
+		exists, err := s.a.getCache(guard, s.recordKey(guard, id), &k)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			s.loadUsed(&k)
+			out = append(out, &k)
+		}
+	}
+	return out, nil
+}
+
+// apiKeyStore 返回 guard 使用的 API Key 存储
+func (a *Auth) apiKeyStore(guardName string) (*GuardConfig, APIKeyStore, error) {
+	guardCfg, ok := a.guards[guardName]
+	if !ok {
+		return nil, nil, ErrGuardNotFound
+	}
+	if guardCfg.Type != AuthTypeAPIKey {
+		return nil, nil, ErrAuthTypeUnsupported
+	}
+	switch guardCfg.KeyStore {
+	case "", APIKeyStoreCache:
+		return guardCfg, &cacheAPIKeyStore{a: a}, nil
+	case APIKeyStoreConfig:
+		return guardCfg, a.configKeys[guardName], nil
+	}
+	if s, ok := a.apiKeyStores[guardCfg.KeyStore]; ok {
+		return guardCfg, s, nil
+	}
+	return nil, nil, fmt.Errorf("api key store '%s' not registered", guardCfg.KeyStore)
+}
+
+// CreateKey 为 apikey 类型的 guard 创建密钥，明文仅返回一次
+func (a *Auth) CreateKey(guardName string, opts APIKeyOptions) (*APIKey, string, error) {
+	_, store, err := a.apiKeyStore(guardName)
+	if err != nil {
+		return nil, "", err
+	}
+	opts.RateLimit = strings.TrimSpace(opts.RateLimit)
+	if opts.RateLimit != "" {
+		if _, err := limiter.NewRateFromFormatted(opts.RateLimit); err != nil {
+			return nil, "", fmt.Errorf("invalid rate limit: %w", err)
+		}
+	}
+
+	secret, err := a.generateSessionToken()
+	if err != nil {
+		return nil, "", err
+	}
+	key := "ak_" + secret
+	now := a.now()
+	k := &APIKey{
+		ID:        uuid.NewString(),
+		Guard:     guardName,
+		Name:      opts.Name,
+		Owner:     opts.Owner,
+		Hint:      key[len(key)-4:],
+		Hash:      HashAPIKey(key),
+		Scopes:    append([]string(nil), opts.Scopes...),
+		RateLimit: opts.RateLimit,
+		Data:      opts.Data,
+		CreatedAt: now.Unix(),
+	}
+	if opts.TTL > 0 {
+		k.ExpiresAt = now.Add(opts.TTL).Unix()
+	}
+	if err := store.Save(context.Background(), k); err != nil {
+		return nil, "", fmt.Errorf("failed to save api key: %w", err)
+	}
+	return k.view(), key, nil
+}
+
+// RevokeKey 吊销密钥，记录保留在 ListKeys 中
+func (a *Auth) RevokeKey(guardName, id string) error {
+	_, store, err := a.apiKeyStore(guardName)
+	if err != nil {
+		return err
+	}
+	keys, err := store.List(context.Background(), guardName)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.ID != id {
+			continue
+		}
+		if k.RevokedAt > 0 {
+			return nil
+		}
+		k.RevokedAt = a.now().Unix()
+		return store.Save(context.Background(), k)
+	}
+	return ErrAPIKeyNotFound
+}
+
+// ListKeys 返回 guard 下的全部密钥（不含哈希），按创建时间排序
+func (a *Auth) ListKeys(guardName string) ([]*APIKey, error) {
+	_, store, err := a.apiKeyStore(guardName)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := store.List(context.Background(), guardName)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*APIKey, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, k.view())
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt < out[j].CreatedAt })
+	return out, nil
+}
+
+// authenticateAPIKey 校验密钥状态、有效期与速率，按间隔更新最近使用时间
+func (a *Auth) authenticateAPIKey(guardName, key string) (*AuthContext, error) {
+	_, store, err := a.apiKeyStore(guardName)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	k, err := store.FindByHash(ctx, guardName, HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return nil, ErrTokenInvalid
+	}
+	now := a.now()
+	if k.RevokedAt > 0 {
+		return nil, ErrAPIKeyRevoked
+	}
+	if k.ExpiresAt > 0 && k.ExpiresAt <= now.Unix() {
+		return nil, ErrTokenExpired
+	}
+	if k.RateLimit != "" {
+		reached, err := a.apiKeyRateReached(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		if reached {
+			return nil, ErrAPIKeyRateLimited
+		}
+	}
+
+	if now.Unix()-k.LastUsedAt >= int64(apiKeyTouchInterval/time.Second) {
+		a.touchAPIKey(store, guardName, k.ID, now.Unix())
+	}
+
+	userID := k.Owner
+	if userID == "" {
+		userID = k.ID
+	}
+	return &AuthContext{
+		GuardName: guardName,
+		UserID:    userID,
+		Token:     key,
+		Data:      apiKeyData(k),
+	}, nil
+}
+
+// touchAPIKey 单独更新最近使用时间，存储未实现 APIKeyToucher 时跳过；失败不影响鉴权
+func (a *Auth) touchAPIKey(store APIKeyStore, guardName, id string, now int64) {
+	toucher, ok := store.(APIKeyToucher)
+	if !ok {
+		return
+	}
+	if err := toucher.Touch(context.Background(), guardName, id, now); err != nil && a.log != nil {
+		a.log.Warnw("failed to update api key last used", "guard", guardName, "id", id, "error", err.Error())
+	}
+}
+
+// apiKeyData API Key 认证后写入 AuthContext.Data 的数据
+func apiKeyData(k *APIKey) map[string]interface{} {
+	data := make(map[string]interface{}, len(k.Data)+4)
+	for key, v := range k.Data {
+		data[key] = v
+	}
+	data["token_type"] = "api_key"
+	data["api_key_id"] = k.ID
+	data["api_key_name"] = k.Name
+	data["scopes"] = append([]string(nil), k.Scopes...)
+	return data
+}
+
+// apiKeyRateReached 按密钥计数，启用 redis 时多实例共享计数
+func (a *Auth) apiKeyRateReached(ctx context.Context, k *APIKey) (bool, error) {
+	rate, err := limiter.NewRateFromFormatted(k.RateLimit)
+	if err != nil {
+		return false, err
+	}
+	store, err := a.apiKeyLimiterStore()
+	if err != nil {
+		return false, err
+	}
+	res, err := limiter.New(store, rate).Get(ctx, k.Guard+":"+k.ID)
+	if err != nil {
+		return false, err
+	}
+	return res.Reached, nil
+}
+
+func (a *Auth) apiKeyLimiterStore() (limiter.Store, error) {
+	a.apiKeyMu.Lock()
+	defer a.apiKeyMu.Unlock()
+	if a.apiKeyLimiter != nil {
+		return a.apiKeyLimiter, nil
+	}
+	opts := limiter.StoreOptions{Prefix: "auth_apikey_rate"}
+	if a.redis != nil {
+		store, err := limiterredis.NewStoreWithOptions(a.redis.UniversalClient(), opts)
+		if err != nil {
+			return nil, err
+		}
+		a.apiKeyLimiter = store
+	} else {
+		a.apiKeyLimiter = limitermemory.NewStoreWithOptions(opts)
+	}
+	return a.apiKeyLimiter, nil
+}
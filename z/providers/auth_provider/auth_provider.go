@@ -8,16 +8,24 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/mem_cache_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/redis_provider"
+	"github.com/ulule/limiter/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
 
+const tracerName = "github.com/icreateapp-com/go-zLib/auth"
+
 const (
 	defaultSessionDuration      = 24 * time.Hour
 	defaultSessionTouchInterval = 5 * time.Minute
@@ -29,9 +37,23 @@ type Auth struct {
 	log      *logger_provider.Logger
 	redis    *redis_provider.Redis
 	memCache *mem_cache_provider.MemCache
+	clock    z.Clock
+	stores   map[string]SessionStore
+	sealer   *sessionSealer
+
+	apiKeyStores map[string]APIKeyStore
+
+	guards     map[string]*GuardConfig
+	jwtKeys    map[string]*jwtKey
+	configKeys map[string]*configAPIKeyStore
+	oidc       map[string]*oidcProvider
+	sorted     []sortedGuard
+
+	verifyMu sync.Mutex // 存储后端不支持原子读删时的一次性令牌消费
+	saMu     sync.Mutex // 服务账号记录的读改写
 
-	guards map[string]*GuardConfig
-	sorted []sortedGuard
+	apiKeyMu      sync.Mutex // API Key 索引的读改写及限流存储的延迟创建
+	apiKeyLimiter limiter.Store
 }
 
 // In Auth 的 fx 入参
@@ -42,6 +64,9 @@ type In struct {
 	Log      *logger_provider.Logger
 	Redis    *redis_provider.Redis        `optional:"true"`
 	MemCache *mem_cache_provider.MemCache `optional:"true"`
+	Clock    z.Clock                      `optional:"true"`
+	Stores   []NamedSessionStore          `group:"auth_session_stores"`
+	KeyStore []NamedAPIKeyStore           `group:"auth_apikey_stores"`
 }
 
 type sortedGuard struct {
@@ -51,7 +76,26 @@ type sortedGuard struct {
 
 // NewAuthProvider 创建 Auth provider
 func NewAuthProvider(lc fx.Lifecycle, in In) (*Auth, error) {
-	a := &Auth{cfg: in.Cfg, log: in.Log, redis: in.Redis, memCache: in.MemCache}
+	a := &Auth{cfg: in.Cfg, log: in.Log, redis: in.Redis, memCache: in.MemCache, clock: in.Clock, stores: map[string]SessionStore{}}
+	for _, s := range in.Stores {
+		if s.Name == "" || s.Store == nil || s.Name == CacheTypeMemory || s.Name == CacheTypeRedis {
+			return nil, fmt.Errorf("invalid session store registration: %q", s.Name)
+		}
+		if _, ok := a.stores[s.Name]; ok {
+			return nil, fmt.Errorf("duplicate session store: %s", s.Name)
+		}
+		a.stores[s.Name] = s.Store
+	}
+	a.apiKeyStores = map[string]APIKeyStore{}
+	for _, s := range in.KeyStore {
+		if s.Name == "" || s.Store == nil || s.Name == APIKeyStoreConfig || s.Name == APIKeyStoreCache {
+			return nil, fmt.Errorf("invalid api key store registration: %q", s.Name)
+		}
+		if _, ok := a.apiKeyStores[s.Name]; ok {
+			return nil, fmt.Errorf("duplicate api key store: %s", s.Name)
+		}
+		a.apiKeyStores[s.Name] = s.Store
+	}
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -74,8 +118,17 @@ func (a *Auth) Init(cfg *config_provider.Config) error {
 	}
 
 	a.guards = make(map[string]*GuardConfig)
+	a.jwtKeys = make(map[string]*jwtKey)
+	a.configKeys = make(map[string]*configAPIKeyStore)
+	a.oidc = make(map[string]*oidcProvider)
 	a.sorted = nil
 
+	sealer, err := loadSessionSealer(cfg)
+	if err != nil {
+		return err
+	}
+	a.sealer = sealer
+
 	guardMap := cfg.GetStringMap("auth.guards")
 	var guardList []string
 	if len(guardMap) > 0 {
@@ -106,9 +159,81 @@ func (a *Auth) Init(cfg *config_provider.Config) error {
 			Prefix:               cfg.GetString("auth.guards." + g + ".prefix"),
 			Cache:                cfg.GetString("auth.guards." + g + ".cache"),
 			Duration:             cfg.GetInt("auth.guards." + g + ".duration"),
+			IdleTimeout:          cfg.GetInt("auth.guards." + g + ".idle_timeout"),
+			MaxLifetime:          cfg.GetInt("auth.guards." + g + ".max_lifetime"),
 			TouchInterval:        cfg.GetInt("auth.guards." + g + ".touch_interval"),
 			SingleSessionEnabled: cfg.GetBool("auth.guards." + g + ".single_session_enabled"),
+			MaxDevices:           cfg.GetInt("auth.guards." + g + ".max_devices"),
+			DeviceLimitStrategy:  cfg.GetString("auth.guards."+g+".device_limit_strategy", DeviceLimitEvictOldest),
 			Anonymity:            cfg.GetStringSlice("auth.guards." + g + ".anonymity"),
+			Secret:               cfg.GetString("auth.guards." + g + ".secret"),
+			PrivateKey:           cfg.GetString("auth.guards." + g + ".private_key"),
+			PublicKey:            cfg.GetString("auth.guards." + g + ".public_key"),
+			KeyID:                cfg.GetString("auth.guards." + g + ".key_id"),
+			Issuer:               cfg.GetString("auth.guards." + g + ".issuer"),
+			Audience:             cfg.GetStringSlice("auth.guards." + g + ".audience"),
+			Leeway:               cfg.GetInt("auth.guards." + g + ".leeway"),
+			Algorithms:           cfg.GetStringSlice("auth.guards." + g + ".algorithms"),
+			MaxAge:               cfg.GetInt("auth.guards." + g + ".max_age"),
+			KeyHeader:            cfg.GetString("auth.guards." + g + ".key_header"),
+			KeyStore:             cfg.GetString("auth.guards." + g + ".key_store"),
+			DiscoveryURL:         cfg.GetString("auth.guards." + g + ".discovery_url"),
+			JWKSURL:              cfg.GetString("auth.guards." + g + ".jwks_url"),
+			JWKSRefresh:          cfg.GetInt("auth.guards." + g + ".jwks_refresh"),
+			UserClaim:            cfg.GetString("auth.guards." + g + ".user_claim"),
+			RolesClaim:           cfg.GetString("auth.guards." + g + ".roles_claim"),
+			AllowAnyAudience:     cfg.GetBool("auth.guards." + g + ".allow_any_audience"),
+			Roles:                cfg.GetStringMapStringSlice("auth.guards." + g + ".roles"),
+			DefaultRoles:         cfg.GetStringSlice("auth.guards." + g + ".default_roles"),
+			TrustBaggage:         cfg.GetBool("auth.guards." + g + ".trust_baggage"),
+		}
+		if gc.Type == AuthTypeJWT {
+			key, err := loadJWTKey(g, gc)
+			if err != nil {
+				return err
+			}
+			if key != nil {
+				a.jwtKeys[g] = key
+			}
+		}
+		if gc.Type == AuthTypeOIDC {
+			p, err := newOIDCProvider(g, gc)
+			if err != nil {
+				return err
+			}
+			a.oidc[g] = p
+		}
+		if gc.Type == AuthTypeAPIKey {
+			if gc.KeyHeader == "" {
+				gc.KeyHeader = defaultAPIKeyHeader
+			}
+			switch gc.KeyStore {
+			case "", APIKeyStoreCache:
+			case APIKeyStoreConfig:
+				keys, err := loadConfigAPIKeys(a, g)
+				if err != nil {
+					return err
+				}
+				a.configKeys[g] = keys
+			default:
+				if _, ok := a.apiKeyStores[gc.KeyStore]; !ok {
+					return fmt.Errorf("auth.guards.%s.key_store: api key store '%s' not registered", g, gc.KeyStore)
+				}
+			}
+		}
+		if _, ok := a.stores[gc.Cache]; !ok && gc.Cache != "" && gc.Cache != CacheTypeMemory && gc.Cache != CacheTypeRedis {
+			return fmt.Errorf("auth.guards.%s.cache: session store '%s' not registered", g, gc.Cache)
+		}
+		if gc.DeviceLimitStrategy == "" {
+			gc.DeviceLimitStrategy = DeviceLimitEvictOldest
+		}
+		if gc.DeviceLimitStrategy != DeviceLimitEvictOldest && gc.DeviceLimitStrategy != DeviceLimitReject {
+			return fmt.Errorf("auth.guards.%s.device_limit_strategy: unsupported strategy '%s'", g, gc.DeviceLimitStrategy)
+		}
+		for _, role := range gc.DefaultRoles {
+			if _, ok := gc.Roles[role]; !ok {
+				return fmt.Errorf("auth.guards.%s.default_roles: role '%s' is not defined", g, role)
+			}
 		}
 		a.guards[g] = gc
 		if gc.Prefix != "" {
@@ -132,60 +257,6 @@ func (a *Auth) extractToken(token string) string {
 	return token
 }
 
-func (a *Auth) isRedisCache(guardName string) bool {
-	guard, ok := a.guards[guardName]
-	if !ok {
-		return a.redis != nil
-	}
-	if guard.Cache == "" || guard.Cache == CacheTypeRedis {
-		return a.redis != nil
-	}
-	return false
-}
-
-func (a *Auth) setCache(guardName, key string, value interface{}, expiration time.Duration) error {
-	if a.isRedisCache(guardName) {
-		if a.redis == nil {
-			return fmt.Errorf("redis not enabled")
-		}
-		return a.redis.Set(key, value, expiration)
-	}
-	if a.memCache == nil {
-		return fmt.Errorf("mem cache not enabled")
-	}
-	a.memCache.Set(key, value, expiration)
-	return nil
-}
-
-func (a *Auth) getCache(guardName, key string) (interface{}, bool) {
-	if a.isRedisCache(guardName) {
-		if a.redis == nil {
-			return nil, false
-		}
-		var result interface{}
-		err := a.redis.Get(key, &result)
-		return result, err == nil
-	}
-	if a.memCache == nil {
-		return nil, false
-	}
-	return a.memCache.Get(key)
-}
-
-func (a *Auth) deleteCache(guardName, key string) error {
-	if a.isRedisCache(guardName) {
-		if a.redis == nil {
-			return fmt.Errorf("redis not enabled")
-		}
-		return a.redis.Delete(key)
-	}
-	if a.memCache == nil {
-		return fmt.Errorf("mem cache not enabled")
-	}
-	a.memCache.Delete(key)
-	return nil
-}
-
 func (a *Auth) getSessionCacheKey(guardName, tokenHash string) string {
 	return fmt.Sprintf("auth_session_%s_%s", guardName, tokenHash)
 }
@@ -208,14 +279,40 @@ func (a *Auth) generateSessionToken() (string, error) {
 	return hex.EncodeToString(secret), nil
 }
 
+// now 当前时间，未注入 Clock 时使用系统时钟
+func (a *Auth) now() time.Time {
+	return z.ClockOr(a.clock).Now()
+}
+
 func (a *Auth) getGuardDuration(guardName string) time.Duration {
 	guard, ok := a.guards[guardName]
-	if !ok || guard == nil || guard.Duration <= 0 {
+	if !ok || guard == nil {
+		return defaultSessionDuration
+	}
+	if guard.IdleTimeout > 0 {
+		return time.Duration(guard.IdleTimeout) * time.Second
+	}
+	if guard.Duration <= 0 {
 		return defaultSessionDuration
 	}
 	return time.Duration(guard.Duration) * time.Second
 }
 
+// sessionTTL 返回从 now 起的会话有效期，不超过绝对过期时间，已过绝对过期时间时返回 0
+func sessionTTL(session *SessionData, idle time.Duration, now time.Time) time.Duration {
+	if session.AbsoluteExpiresAt <= 0 {
+		return idle
+	}
+	remaining := time.Unix(session.AbsoluteExpiresAt, 0).Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+	if remaining < idle {
+		return remaining
+	}
+	return idle
+}
+
 func (a *Auth) getGuardTouchInterval(guardName string) time.Duration {
 	guard, ok := a.guards[guardName]
 	if !ok || guard == nil || guard.TouchInterval <= 0 {
@@ -230,33 +327,15 @@ func (a *Auth) GetGuardTouchInterval(guardName string) time.Duration {
 }
 
 func (a *Auth) getSession(guardName, tokenHash string) (*SessionData, bool, error) {
-	key := a.getSessionCacheKey(guardName, tokenHash)
-	if a.isRedisCache(guardName) {
-		if a.redis == nil {
-			return nil, false, fmt.Errorf("redis not enabled")
-		}
-		var session SessionData
-		if err := a.redis.Get(key, &session); err != nil {
-			return nil, false, nil
-		}
-		return &session, true, nil
-	}
-	if a.memCache == nil {
-		return nil, false, fmt.Errorf("mem cache not enabled")
+	var session SessionData
+	exists, err := a.getCache(guardName, a.getSessionCacheKey(guardName, tokenHash), &session)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid session data: %w", err)
 	}
-	value, exists := a.memCache.Get(key)
 	if !exists {
 		return nil, false, nil
 	}
-	switch session := value.(type) {
-	case *SessionData:
-		return session, true, nil
-	case SessionData:
-		copy := session
-		return &copy, true, nil
-	default:
-		return nil, false, fmt.Errorf("invalid session data")
-	}
+	return &session, true, nil
 }
 
 func (a *Auth) setSession(guardName string, session *SessionData, expiration time.Duration) error {
@@ -271,38 +350,11 @@ func (a *Auth) deleteSession(guardName, tokenHash string) error {
 }
 
 func (a *Auth) getUserSessionHashes(guardName, userID string) ([]string, error) {
-	key := a.getUserSessionsKey(guardName, userID)
-	if a.isRedisCache(guardName) {
-		if a.redis == nil {
-			return nil, fmt.Errorf("redis not enabled")
-		}
-		var hashes []string
-		if err := a.redis.Get(key, &hashes); err != nil {
-			return []string{}, nil
-		}
-		return hashes, nil
-	}
-	if a.memCache == nil {
-		return nil, fmt.Errorf("mem cache not enabled")
-	}
-	value, exists := a.memCache.Get(key)
-	if !exists {
-		return []string{}, nil
-	}
-	switch hashes := value.(type) {
-	case []string:
-		return hashes, nil
-	case []interface{}:
-		result := make([]string, 0, len(hashes))
-		for _, item := range hashes {
-			if s, ok := item.(string); ok {
-				result = append(result, s)
-			}
-		}
-		return result, nil
-	default:
-		return []string{}, nil
+	hashes := []string{}
+	if _, err := a.getCache(guardName, a.getUserSessionsKey(guardName, userID), &hashes); err != nil {
+		return nil, err
 	}
+	return hashes, nil
 }
 
 func (a *Auth) setUserSessionHashes(guardName, userID string, hashes []string) error {
@@ -315,22 +367,7 @@ func (a *Auth) setUserSessionHashes(guardName, userID string, hashes []string) e
 
 // touchUserSessionIndex 仅刷新用户会话索引的 TTL，避免续期时重复读写索引内容。
 func (a *Auth) touchUserSessionIndex(guardName, userID string, duration time.Duration) error {
-	key := a.getUserSessionsKey(guardName, userID)
-	if a.isRedisCache(guardName) {
-		if a.redis == nil {
-			return fmt.Errorf("redis not enabled")
-		}
-		return a.redis.Expire(key, duration)
-	}
-	if a.memCache == nil {
-		return fmt.Errorf("mem cache not enabled")
-	}
-	value, exists := a.memCache.Get(key)
-	if !exists {
-		return nil
-	}
-	a.memCache.Set(key, value, duration)
-	return nil
+	return a.expireCache(guardName, a.getUserSessionsKey(guardName, userID), duration)
 }
 
 func (a *Auth) addUserSessionHash(guardName, userID, tokenHash string) error {
@@ -398,6 +435,11 @@ func (a *Auth) AuthenticateRequest(requestPath string, tokenFromHeader string, t
 
 // AuthenticateByGuard 按指定 guard 鉴权
 func (a *Auth) AuthenticateByGuard(guardName string, tokenFromHeader string, tokenFromQuery string) (bool, string, *AuthContext, error) {
+	return a.AuthenticateByGuardContext(context.Background(), guardName, tokenFromHeader, tokenFromQuery)
+}
+
+// AuthenticateByGuardContext 按指定 guard 鉴权，ctx 用于 oidc guard 加载签发方密钥，请求取消时随之中止
+func (a *Auth) AuthenticateByGuardContext(ctx context.Context, guardName string, tokenFromHeader string, tokenFromQuery string) (bool, string, *AuthContext, error) {
 	guardCfg, ok := a.guards[guardName]
 	if !ok {
 		return true, guardName, nil, ErrGuardNotFound
@@ -418,6 +460,12 @@ func (a *Auth) AuthenticateByGuard(guardName string, tokenFromHeader string, tok
 		authCtx, err = a.authenticateFixedToken(guardName, token, guardCfg)
 	case AuthTypeSession:
 		authCtx, err = a.authenticateSession(guardName, token)
+	case AuthTypeJWT:
+		authCtx, err = a.authenticateJWT(guardName, token)
+	case AuthTypeAPIKey:
+		authCtx, err = a.authenticateAPIKey(guardName, token)
+	case AuthTypeOIDC:
+		authCtx, err = a.authenticateOIDC(ctx, guardName, token)
 	default:
 		err = ErrAuthTypeUnsupported
 	}
@@ -431,6 +479,9 @@ func (a *Auth) AuthenticateByGuard(guardName string, tokenFromHeader string, tok
 		}
 		authCtx.Data = authCtx.Session.Data
 	}
+	if authCtx != nil {
+		authCtx.Roles = resolveRoles(guardCfg, authCtx)
+	}
 
 	return true, guardName, authCtx, nil
 }
@@ -446,18 +497,19 @@ func (a *Auth) matchGuard(path string) (string, *GuardConfig) {
 }
 
 func (a *Auth) authenticateFixedToken(guardName, token string, guardConfig *GuardConfig) (*AuthContext, error) {
-	if token != guardConfig.Token {
-		return nil, ErrTokenInvalid
+	if guardConfig.Token == "" || token != guardConfig.Token {
+		// 非配置中的固定令牌时按服务账号令牌校验
+		return a.authenticateServiceAccount(guardName, token)
 	}
 
 	tokenHash := a.getTokenHash(token)
 	cacheKey := fmt.Sprintf("token_%s_%s", guardName, tokenHash)
-	sessionData, exists := a.getCache(guardName, cacheKey)
-	if !exists {
+	var sessionData map[string]interface{}
+	if exists, _ := a.getCache(guardName, cacheKey, &sessionData); !exists {
 		sessionData = map[string]interface{}{
 			"user_id":    tokenHash,
 			"guard_name": guardName,
-			"login_time": time.Now().Unix(),
+			"login_time": a.now().Unix(),
 			"token_type": "fixed",
 		}
 		_ = a.setCache(guardName, cacheKey, sessionData, 24*365*time.Hour)
@@ -486,6 +538,12 @@ func (a *Auth) authenticateSession(guardName, token string) (*AuthContext, error
 	if session.GuardName != "" && session.GuardName != guardName {
 		return nil, ErrTokenInvalid
 	}
+	// 已超过最长存活时间的会话即使仍在活跃续期也视为过期
+	if session.AbsoluteExpiresAt > 0 && !a.now().Before(time.Unix(session.AbsoluteExpiresAt, 0)) {
+		_ = a.deleteSession(guardName, tokenHash)
+		_ = a.removeUserSessionHash(guardName, session.UserID, tokenHash)
+		return nil, ErrSessionExpired
+	}
 
 	return &AuthContext{
 		GuardName: guardName,
@@ -501,22 +559,27 @@ func (a *Auth) touchSessionIfNeeded(guardName string, session *SessionData) erro
 		return ErrSessionInvalid
 	}
 
-	duration := a.getGuardDuration(guardName)
 	touchInterval := a.getGuardTouchInterval(guardName)
-	now := time.Now()
+	now := a.now()
 	lastSeenAt := time.Unix(session.LastSeenAt, 0)
 
 	if session.LastSeenAt > 0 && now.Sub(lastSeenAt) < touchInterval {
 		return nil
 	}
 
+	idle := a.getGuardDuration(guardName)
+	duration := sessionTTL(session, idle, now)
+	if duration <= 0 {
+		return ErrSessionExpired
+	}
 	session.LastSeenAt = now.Unix()
 	session.ExpiresAt = now.Add(duration).Unix()
 	if err := a.setSession(guardName, session, duration); err != nil {
 		return err
 	}
 
-	if err := a.touchUserSessionIndex(guardName, session.UserID, duration); err != nil {
+	// 索引由用户所有会话共享，按完整空闲时间续期
+	if err := a.touchUserSessionIndex(guardName, session.UserID, idle); err != nil {
 		return err
 	}
 
@@ -562,6 +625,11 @@ func (a *Auth) TouchSession(guardName, token string) (*SessionData, error) {
 
 // Login 用户登录，生成 session token 并存储到缓存
 func (a *Auth) Login(guard string, userID string, duration time.Duration, data ...interface{}) (string, error) {
+	return a.LoginWithRoles(guard, userID, nil, duration, data...)
+}
+
+// LoginWithRoles 用户登录并为会话绑定角色，角色需在 guard 的 roles 中定义
+func (a *Auth) LoginWithRoles(guard string, userID string, roles []string, duration time.Duration, data ...interface{}) (string, error) {
 	if strings.TrimSpace(guard) == "" {
 		return "", fmt.Errorf("guard name cannot be empty")
 	}
@@ -577,6 +645,12 @@ func (a *Auth) Login(guard string, userID string, duration time.Duration, data .
 		return "", fmt.Errorf("guard '%s' does not support session login", guard)
 	}
 
+	for _, role := range roles {
+		if _, ok := guardConfig.Roles[role]; !ok {
+			return "", fmt.Errorf("%w: '%s' in guard '%s'", ErrRoleNotFound, role, guard)
+		}
+	}
+
 	if duration <= 0 {
 		duration = a.getGuardDuration(guard)
 	}
@@ -592,19 +666,30 @@ func (a *Auth) Login(guard string, userID string, duration time.Duration, data .
 		return "", err
 	}
 
-	now := time.Now()
+	now := a.now()
 	session := &SessionData{
 		TokenHash:  a.getTokenHash(token),
 		UserID:     userID,
 		GuardName:  guard,
 		LoginTime:  now.Unix(),
 		LastSeenAt: now.Unix(),
-		ExpiresAt:  now.Add(duration).Unix(),
+		Roles:      roles,
+	}
+	if guardConfig.MaxLifetime > 0 {
+		session.AbsoluteExpiresAt = now.Add(time.Duration(guardConfig.MaxLifetime) * time.Second).Unix()
+		duration = sessionTTL(session, duration, now)
 	}
+	session.ExpiresAt = now.Add(duration).Unix()
 	if len(data) > 0 && data[0] != nil {
 		session.Data = data[0]
 	}
 
+	if !guardConfig.SingleSessionEnabled && guardConfig.MaxDevices > 0 {
+		if err := a.enforceDeviceLimit(guard, guardConfig, userID, sessionDevice(session)); err != nil {
+			return "", err
+		}
+	}
+
 	if err := a.setSession(guard, session, duration); err != nil {
 		return "", fmt.Errorf("failed to store session: %w", err)
 	}
@@ -678,7 +763,8 @@ func (a *Auth) Authenticate(c *gin.Context) (bool, string, error) {
 	if token == "" {
 		token = strings.TrimSpace(c.Query("token"))
 	}
-	if token == "" {
+	guardList := strings.Split(guards, ",")
+	if token == "" && !a.hasAPIKeyHeader(c, guardList) {
 		return false, "", ErrTokenMissing
 	}
 
@@ -686,8 +772,15 @@ func (a *Auth) Authenticate(c *gin.Context) (bool, string, error) {
 	if c.Request != nil && c.Request.URL != nil {
 		path = c.Request.URL.Path
 	}
+	// 仅在请求已有 span 时记录认证耗时
+	if c.Request != nil && trace.SpanContextFromContext(c.Request.Context()).IsValid() {
+		_, span := otel.Tracer(tracerName).Start(c.Request.Context(), "auth.authenticate",
+			trace.WithAttributes(attribute.String("auth.guards", guards)))
+		defer span.End()
+	}
 
-	guardList := strings.Split(guards, ",")
+	// 记录第一个 guard 的认证错误，便于区分令牌过期、会话失效等情况
+	var authErr error
 	for _, g := range guardList {
 		guardName := strings.TrimSpace(g)
 		if guardName == "" {
@@ -703,14 +796,26 @@ func (a *Auth) Authenticate(c *gin.Context) (bool, string, error) {
 			}
 		}
 
-		_, _, authCtx, err := a.AuthenticateByGuard(guardName, token, "")
+		// apikey guard 优先读取配置的请求头
+		guardToken := token
+		if guardCfg.Type == AuthTypeAPIKey {
+			if key := strings.TrimSpace(c.GetHeader(guardCfg.KeyHeader)); key != "" {
+				guardToken = key
+			}
+		}
+		_, _, authCtx, err := a.AuthenticateByGuardContext(c.Request.Context(), guardName, guardToken, "")
 		if err != nil {
+			if authErr == nil {
+				authErr = err
+			}
 			continue
 		}
 		if authCtx == nil {
 			continue
 		}
 
+		// 认证结果仅写入当前请求的 gin.Context，并发请求互不影响
+		c.Set(ContextKeyAuth, authCtx)
 		c.Set("auth.guard", guardName)
 		c.Set("auth.user_id", authCtx.UserID)
 		c.Set("auth.token", authCtx.Token)
@@ -720,13 +825,57 @@ func (a *Auth) Authenticate(c *gin.Context) (bool, string, error) {
 		if authCtx.Data != nil {
 			c.Set("auth.data", authCtx.Data)
 		}
+		c.Set("auth.roles", authCtx.Roles)
+		if c.Request != nil {
+			values := authBaggage(authCtx)
+			// 内部服务调用：沿用上游请求认证得到的身份
+			if guardCfg.TrustBaggage {
+				for key, value := range z.InboundIdentityBaggage(c.Request.Context()) {
+					values[key] = value
+				}
+			}
+			c.Request = c.Request.WithContext(z.WithBaggage(c.Request.Context(), values))
+		}
 
 		return true, guardName, nil
 	}
 
+	if authErr != nil {
+		return false, "", convertToFriendlyError(authErr)
+	}
 	return false, "", ErrPermissionDenied
 }
 
+// hasAPIKeyHeader 请求是否携带了任一 apikey guard 的密钥请求头
+func (a *Auth) hasAPIKeyHeader(c *gin.Context, guardList []string) bool {
+	for _, g := range guardList {
+		guardCfg, ok := a.guards[strings.TrimSpace(g)]
+		if ok && guardCfg.Type == AuthTypeAPIKey && strings.TrimSpace(c.GetHeader(guardCfg.KeyHeader)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// authBaggage 构建写入 OTel baggage 的身份信息，覆盖客户端传入的同名键避免伪造
+// tenant_id 取自认证自定义数据（map 中的 tenant_id 字段）
+func authBaggage(authCtx *AuthContext) map[string]string {
+	values := map[string]string{
+		z.BaggageGuard:    authCtx.GuardName,
+		z.BaggageUserID:   authCtx.UserID,
+		z.BaggageTenantID: "",
+	}
+	switch data := authCtx.Data.(type) {
+	case map[string]interface{}:
+		if v, ok := data[z.BaggageTenantID]; ok && v != nil {
+			values[z.BaggageTenantID] = fmt.Sprint(v)
+		}
+	case map[string]string:
+		values[z.BaggageTenantID] = data[z.BaggageTenantID]
+	}
+	return values
+}
+
 // GetUserID 从 gin 上下文中获取当前登录用户的ID
 func (a *Auth) GetUserID(c *gin.Context) (string, error) {
 	if c == nil {
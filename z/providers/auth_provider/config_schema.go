@@ -0,0 +1,25 @@
+package auth_provider
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// authConfig auth.yml 配置结构
+type authConfig struct {
+	Guards      map[string]interface{} `required:"true" desc:"认证守卫，按名称配置 type、token、secret、issuer 等，参见 GuardConfig"`
+	GuardsNames []string               `desc:"守卫名称列表（兼容旧配置），guards 为空时使用"`
+	GuardsList  []string               `desc:"守卫名称列表（兼容旧配置），guards 与 guards_names 为空时使用"`
+	JWKS        struct {
+		Path   string `default:"/.well-known/jwks.json" desc:"JWKS 公开路径"`
+		MaxAge int    `default:"300" desc:"JWKS 响应缓存时长（秒）"`
+	} `config:"jwks" desc:"JWKS 公钥端点"`
+	Verification struct {
+		Secret string `desc:"验证令牌签名密钥，为空时使用 app.key"`
+	} `desc:"验证令牌（邮箱验证、密码重置等）"`
+	SessionSealing struct {
+		Mode            string                 `default:"none" desc:"会话数据保护方式：none / sign（HMAC 签名）/ encrypt（AES-GCM 加密）"`
+		ActiveKey       string                 `desc:"写入使用的密钥 ID，为空时取 keys 中排序最后的一个"`
+		Keys            map[string]interface{} `desc:"密钥 ID: 密钥，轮换时保留旧密钥，为空时使用 app.key"`
+		AcceptPlaintext bool                   `default:"false" desc:"接受未保护的旧值，从明文迁移时开启"`
+	} `desc:"会话数据加密与签名"`
+}
+
+var _ = config_provider.RegisterSchema("auth", "认证", authConfig{})
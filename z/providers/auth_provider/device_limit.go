@@ -0,0 +1,59 @@
+package auth_provider
+
+import (
+	"fmt"
+	"sort"
+)
+
+// enforceDeviceLimit 在新登录写入前检查用户的设备数
+// 会话数据中 device / device_id 相同的视为同一设备，新登录替换该设备的旧会话；未携带设备标识的会话各算一台设备
+// 超出 max_devices 时按 device_limit_strategy 踢掉最早登录的会话，或返回 ErrDeviceLimitExceeded
+func (a *Auth) enforceDeviceLimit(guard string, guardCfg *GuardConfig, userID, device string) error {
+	hashes, err := a.getUserSessionHashes(guard, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load user sessions: %w", err)
+	}
+
+	var sessions, replaced []*SessionData
+	for _, hash := range hashes {
+		session, exists, err := a.getSession(guard, hash)
+		if err != nil {
+			return err
+		}
+		// 已过期的会话只清理索引
+		if !exists || session == nil {
+			continue
+		}
+		if device != "" && sessionDevice(session) == device {
+			replaced = append(replaced, session)
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+
+	excess := len(sessions) + 1 - guardCfg.MaxDevices
+	if excess > 0 && guardCfg.DeviceLimitStrategy == DeviceLimitReject {
+		return ErrDeviceLimitExceeded
+	}
+
+	evicted := replaced
+	if excess > 0 {
+		sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].LoginTime < sessions[j].LoginTime })
+		evicted = append(evicted, sessions[:excess]...)
+		sessions = sessions[excess:]
+	}
+	for _, session := range evicted {
+		if err := a.deleteSession(guard, session.TokenHash); err != nil {
+			return fmt.Errorf("failed to evict session: %w", err)
+		}
+	}
+	if len(evicted) == 0 && len(sessions) == len(hashes) {
+		return nil
+	}
+
+	remaining := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		remaining = append(remaining, session.TokenHash)
+	}
+	return a.setUserSessionHashes(guard, userID, remaining)
+}
@@ -0,0 +1,132 @@
+package auth_provider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"go.uber.org/fx"
+)
+
+// JWK JSON Web Key 公钥（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet JWKS 文档
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 返回所有使用非对称算法的 jwt guard 的公钥，供其它服务校验本服务签发的令牌
+func (a *Auth) JWKS() JWKSet {
+	names := make([]string, 0, len(a.jwtKeys))
+	for name := range a.jwtKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set := JWKSet{Keys: []JWK{}}
+	seen := map[string]bool{}
+	for _, name := range names {
+		key := a.jwtKeys[name]
+		if seen[key.kid] {
+			continue
+		}
+		jwk, err := publicJWK(key.public)
+		if err != nil {
+			continue
+		}
+		seen[key.kid] = true
+		jwk.Use = "sig"
+		jwk.Kid = key.kid
+		jwk.Alg = key.alg
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// publicJWK 将公钥转换为 JWK，EC 坐标按曲线长度补齐
+func publicJWK(public crypto.PublicKey) (JWK, error) {
+	enc := base64.RawURLEncoding
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{Kty: "RSA", N: enc.EncodeToString(pub.N.Bytes()), E: enc.EncodeToString(big.NewInt(int64(pub.E)).Bytes())}, nil
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Crv: pub.Curve.Params().Name,
+			X:   enc.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+			Y:   enc.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+		}, nil
+	}
+	return JWK{}, errors.New("unsupported public key type")
+}
+
+// jwkThumbprint 计算 RFC 7638 JWK 指纹，作为默认 kid
+func jwkThumbprint(public crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return "", err
+	}
+	// 必需成员按字典序排列，json.Marshal 对 map 的键排序
+	members := map[string]string{"kty": jwk.Kty}
+	if jwk.Kty == "RSA" {
+		members["e"], members["n"] = jwk.E, jwk.N
+	} else {
+		members["crv"], members["x"], members["y"] = jwk.Crv, jwk.X, jwk.Y
+	}
+	b, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// JWKSMiddleware 通过 auth.jwks.path（默认 /.well-known/jwks.json）公开 JWKS
+func JWKSMiddleware(ap *Auth, cfg *config_provider.Config) gin.HandlerFunc {
+	path := cfg.GetString("auth.jwks.path")
+	if path == "" {
+		path = "/.well-known/jwks.json"
+	}
+	maxAge := cfg.GetInt("auth.jwks.max_age")
+	if maxAge <= 0 {
+		maxAge = 300
+	}
+	return func(c *gin.Context) {
+		if ap == nil || c.Request.URL.Path != path || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		c.Header("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+		c.AbortWithStatusJSON(http.StatusOK, ap.JWKS())
+	}
+}
+
+// JWKSMiddlewareModule fx 模块
+var JWKSMiddlewareModule = fx.Provide(
+	fx.Annotate(
+		JWKSMiddleware,
+		fx.ResultTags(`group:"http_middlewares"`),
+	),
+)
@@ -0,0 +1,334 @@
+package auth_provider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// jwtMethods 支持的签名算法
+var jwtMethods = map[string]jwt.SigningMethod{
+	"HS256": jwt.SigningMethodHS256,
+	"HS384": jwt.SigningMethodHS384,
+	"HS512": jwt.SigningMethodHS512,
+	"RS256": jwt.SigningMethodRS256,
+	"RS384": jwt.SigningMethodRS384,
+	"RS512": jwt.SigningMethodRS512,
+	"PS256": jwt.SigningMethodPS256,
+	"PS384": jwt.SigningMethodPS384,
+	"PS512": jwt.SigningMethodPS512,
+	"ES256": jwt.SigningMethodES256,
+	"ES384": jwt.SigningMethodES384,
+	"ES512": jwt.SigningMethodES512,
+}
+
+// jwtECCurves ES 算法对应的曲线
+var jwtECCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// jwtKey guard 的非对称密钥，private 为空时只能校验不能签发
+type jwtKey struct {
+	private crypto.Signer
+	public  crypto.PublicKey
+	kid     string
+	alg     string // 首个非对称算法，发布到 JWKS
+}
+
+// JWTClaims guard 签发的 JWT 载荷
+type JWTClaims struct {
+	Guard string                 `json:"guard"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// jwtAlgorithms 返回 guard 允许的签名算法，默认 HS256
+func jwtAlgorithms(guardCfg *GuardConfig) []string {
+	algs := make([]string, 0, len(guardCfg.Algorithms))
+	for _, alg := range guardCfg.Algorithms {
+		alg = strings.ToUpper(strings.TrimSpace(alg))
+		if _, ok := jwtMethods[alg]; ok {
+			algs = append(algs, alg)
+		}
+	}
+	if len(algs) == 0 {
+		algs = []string{"HS256"}
+	}
+	return algs
+}
+
+// IssueJWT 为 jwt 类型的 guard 签发令牌，有效期取 guard 的 duration（默认 24 小时），jti 用于 RevokeToken 吊销
+func (a *Auth) IssueJWT(guardName string, userID string, data map[string]interface{}) (string, error) {
+	guardCfg, ok := a.guards[guardName]
+	if !ok {
+		return "", ErrGuardNotFound
+	}
+	if guardCfg.Type != AuthTypeJWT {
+		return "", ErrAuthTypeUnsupported
+	}
+	if strings.TrimSpace(userID) == "" {
+		return "", fmt.Errorf("user id is empty")
+	}
+
+	now := a.now()
+	claims := JWTClaims{
+		Guard: guardName,
+		Data:  data,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			Issuer:    guardCfg.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.getGuardDuration(guardName))),
+		},
+	}
+	if len(guardCfg.Audience) > 0 {
+		claims.Audience = jwt.ClaimStrings(guardCfg.Audience)
+	}
+
+	alg := jwtAlgorithms(guardCfg)[0]
+	token := jwt.NewWithClaims(jwtMethods[alg], claims)
+	if isHMACAlg(alg) {
+		return token.SignedString([]byte(guardCfg.Secret))
+	}
+	key := a.jwtKeys[guardName]
+	if key == nil || key.private == nil {
+		return "", fmt.Errorf("guard '%s' has no private key for %s", guardName, alg)
+	}
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// ParseJWT 校验令牌：签名算法白名单、签名、exp/nbf/iat（含时钟偏差）、iss、aud、最大年龄及 guard
+func (a *Auth) ParseJWT(guardName string, token string) (*JWTClaims, error) {
+	guardCfg, ok := a.guards[guardName]
+	if !ok {
+		return nil, ErrGuardNotFound
+	}
+	if guardCfg.Type != AuthTypeJWT {
+		return nil, ErrAuthTypeUnsupported
+	}
+
+	leeway := time.Duration(guardCfg.Leeway) * time.Second
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(jwtAlgorithms(guardCfg)),
+		jwt.WithLeeway(leeway),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(a.now),
+	}
+	if guardCfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(guardCfg.Issuer))
+	}
+
+	claims := &JWTClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		// 按令牌算法选择密钥，HMAC 与非对称密钥互不混用，避免算法混淆攻击
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+			if guardCfg.Secret == "" {
+				return nil, ErrTokenInvalid
+			}
+			return []byte(guardCfg.Secret), nil
+		}
+		key := a.jwtKeys[guardName]
+		if key == nil {
+			return nil, ErrTokenInvalid
+		}
+		if kid, ok := t.Header["kid"].(string); ok && kid != key.kid {
+			return nil, ErrTokenInvalid
+		}
+		return key.public, nil
+	}, opts...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrTokenInvalid
+	}
+
+	// jwt.WithAudience 仅支持单个受众，这里允许匹配配置中的任意一个
+	if len(guardCfg.Audience) > 0 && !jwtAudienceMatch(claims.Audience, guardCfg.Audience) {
+		return nil, ErrTokenInvalid
+	}
+
+	if guardCfg.MaxAge > 0 {
+		if claims.IssuedAt == nil {
+			return nil, ErrTokenInvalid
+		}
+		maxAge := time.Duration(guardCfg.MaxAge) * time.Second
+		if a.now().Sub(claims.IssuedAt.Time) > maxAge+leeway {
+			return nil, ErrTokenExpired
+		}
+	}
+
+	if claims.Guard != "" && claims.Guard != guardName {
+		return nil, ErrTokenInvalid
+	}
+	if strings.TrimSpace(claims.Subject) == "" {
+		return nil, ErrTokenInvalid
+	}
+
+	return claims, nil
+}
+
+func isHMACAlg(alg string) bool {
+	return strings.HasPrefix(alg, "HS")
+}
+
+// loadJWTKey 加载 guard 的非对称密钥并校验与所选算法匹配，未使用非对称算法时返回 nil
+func loadJWTKey(guardName string, guardCfg *GuardConfig) (*jwtKey, error) {
+	var asymmetric []string
+	hmac := false
+	for _, alg := range jwtAlgorithms(guardCfg) {
+		if isHMACAlg(alg) {
+			hmac = true
+		} else {
+			asymmetric = append(asymmetric, alg)
+		}
+	}
+	if hmac && strings.TrimSpace(guardCfg.Secret) == "" {
+		return nil, fmt.Errorf("auth.guards.%s.secret is required for jwt guard", guardName)
+	}
+	if len(asymmetric) == 0 {
+		return nil, nil
+	}
+
+	key := &jwtKey{kid: strings.TrimSpace(guardCfg.KeyID), alg: asymmetric[0]}
+	if guardCfg.PrivateKey != "" {
+		pem, err := readPEM(guardCfg.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("auth.guards.%s.private_key: %w", guardName, err)
+		}
+		if key.private, err = parsePrivateKey(pem); err != nil {
+			return nil, fmt.Errorf("auth.guards.%s.private_key: %w", guardName, err)
+		}
+		key.public = key.private.Public()
+	}
+	if guardCfg.PublicKey != "" {
+		pem, err := readPEM(guardCfg.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("auth.guards.%s.public_key: %w", guardName, err)
+		}
+		public, err := parsePublicKey(pem)
+		if err != nil {
+			return nil, fmt.Errorf("auth.guards.%s.public_key: %w", guardName, err)
+		}
+		if key.public != nil && !publicKeyEqual(key.public, public) {
+			return nil, fmt.Errorf("auth.guards.%s: public_key does not match private_key", guardName)
+		}
+		key.public = public
+	}
+	if key.public == nil {
+		return nil, fmt.Errorf("auth.guards.%s.private_key or public_key is required for %s", guardName, asymmetric[0])
+	}
+
+	for _, alg := range asymmetric {
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			if strings.HasPrefix(alg, "ES") {
+				return nil, fmt.Errorf("auth.guards.%s: %s requires an ECDSA key", guardName, alg)
+			}
+		case *ecdsa.PublicKey:
+			curve, ok := jwtECCurves[alg]
+			if !ok {
+				return nil, fmt.Errorf("auth.guards.%s: %s requires an RSA key", guardName, alg)
+			}
+			if curve != pub.Curve {
+				return nil, fmt.Errorf("auth.guards.%s: %s requires an ECDSA %s key", guardName, alg, curve.Params().Name)
+			}
+		}
+	}
+	if key.kid == "" {
+		kid, err := jwkThumbprint(key.public)
+		if err != nil {
+			return nil, fmt.Errorf("auth.guards.%s: %w", guardName, err)
+		}
+		key.kid = kid
+	}
+	return key, nil
+}
+
+// readPEM 配置值以 -----BEGIN 开头时视为 PEM 内容，否则视为文件路径
+func readPEM(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+func parsePrivateKey(pem []byte) (crypto.Signer, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pem); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPrivateKeyFromPEM(pem); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported private key, expected RSA or ECDSA PEM")
+}
+
+func parsePublicKey(pem []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pem); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(pem); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported public key, expected RSA or ECDSA PEM")
+}
+
+func publicKeyEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
+}
+
+func jwtAudienceMatch(tokenAud jwt.ClaimStrings, allowed []string) bool {
+	for _, aud := range tokenAud {
+		for _, want := range allowed {
+			if aud == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *Auth) authenticateJWT(guardName, token string) (*AuthContext, error) {
+	claims, err := a.ParseJWT(guardName, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkServiceAccountJWT(guardName, claims); err != nil {
+		return nil, err
+	}
+	revoked, err := a.isRevoked(guardName, token, claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	var data interface{}
+	if claims.Data != nil {
+		data = claims.Data
+	}
+	return &AuthContext{
+		GuardName: guardName,
+		UserID:    claims.Subject,
+		Token:     token,
+		Data:      data,
+	}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
 	"go.uber.org/fx"
 )
 
@@ -31,7 +32,23 @@ func (wg *WrappedGroup) Guard(guards string) *gin.RouterGroup {
 	return wg.group
 }
 
-// AuthMiddleware HTTP认证中间件
+// ContextKeyAuth gin.Context 中保存 *AuthContext 的键
+const ContextKeyAuth = "auth.context"
+
+// GetAuthContext 返回当前请求的认证结果，未认证时返回 false
+func GetAuthContext(c *gin.Context) (*AuthContext, bool) {
+	if c == nil {
+		return nil, false
+	}
+	v, ok := c.Get(ContextKeyAuth)
+	if !ok {
+		return nil, false
+	}
+	authCtx, ok := v.(*AuthContext)
+	return authCtx, ok && authCtx != nil
+}
+
+// AuthMiddleware HTTP认证中间件，认证结果通过 GetAuthContext 读取，失败时按错误类型返回对应的业务状态码
 func AuthMiddleware(ap *Auth) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if ap == nil {
@@ -64,26 +81,7 @@ func AuthMiddleware(ap *Auth) gin.HandlerFunc {
 		success, _, err := ap.Authenticate(c)
 
 		if !success {
-			applyAuthFailureCORSHeaders(c)
-
-			// 处理友好的错误消息
-			var errorMsg string
-
-			if authErr, ok := err.(*AuthError); ok {
-				errorMsg = authErr.Message
-			} else {
-				// 如果不是AuthError，转换为友好错误
-				friendlyErr := ConvertToFriendlyError(err)
-				errorMsg = friendlyErr.Message
-			}
-
-			// 返回结构化的错误响应
-			c.JSON(401, gin.H{
-				"success": false,
-				"message": errorMsg,
-				"code":    401,
-			})
-			c.Abort()
+			abortUnauthorized(c, err)
 			return
 		}
 
@@ -92,6 +90,50 @@ func AuthMiddleware(ap *Auth) gin.HandlerFunc {
 	}
 }
 
+// RequireGuard 路由级认证中间件，按指定 guard（多个以逗号分隔）认证当前请求
+// 不依赖路径前缀匹配，适用于同一路径下部分动作公开、部分动作需要登录的路由
+func RequireGuard(ap *Auth, guards string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ap == nil || c.Request.Method == "OPTIONS" {
+			c.Next()
+			return
+		}
+		// 全局中间件已按相同 guard 认证时不重复认证
+		if authed := c.GetString("auth.guard"); authed != "" && guardListContains(guards, authed) {
+			c.Next()
+			return
+		}
+		c.Set("guard", guards)
+		if success, _, err := ap.Authenticate(c); !success {
+			abortUnauthorized(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+func guardListContains(guards, guard string) bool {
+	for _, g := range strings.Split(guards, ",") {
+		if strings.TrimSpace(g) == guard {
+			return true
+		}
+	}
+	return false
+}
+
+// abortUnauthorized 返回 401 并终止请求，业务状态码取自 AuthError.Status
+func abortUnauthorized(c *gin.Context, err error) {
+	applyAuthFailureCORSHeaders(c)
+
+	// 非 AuthError 转换为友好错误，避免泄露内部错误信息
+	authErr := ConvertToFriendlyError(err)
+	if authErr == nil {
+		authErr = ErrTokenMissing
+	}
+	z.Failure(c, authErr.Message, authErr.Status(), http.StatusUnauthorized)
+	c.Abort()
+}
+
 func applyAuthFailureCORSHeaders(c *gin.Context) {
 	if c == nil {
 		return
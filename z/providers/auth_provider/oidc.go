@@ -0,0 +1,376 @@
+package auth_provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDC 默认值
+const (
+	defaultOIDCJWKSRefresh  = 300 // JWKS 缓存时间（秒）
+	defaultOIDCHTTPTimeout  = 5 * time.Second
+	oidcMinRefreshInterval  = 30 * time.Second // 遇到未知 kid 时强制刷新的最小间隔
+	oidcDiscoveryPathSuffix = "/.well-known/openid-configuration"
+)
+
+// oidcCurves JWK crv 对应的曲线
+var oidcCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// oidcProvider 外部签发方的元数据与 JWKS 缓存，首次认证时加载
+type oidcProvider struct {
+	guard  string
+	cfg    *GuardConfig
+	client *http.Client
+
+	mu          sync.RWMutex
+	issuer      string
+	jwksURL     string
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	lastErr     error
+}
+
+// oidcDiscovery OpenID Provider 元数据中用到的字段
+type oidcDiscovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+func newOIDCProvider(guardName string, guardCfg *GuardConfig) (*oidcProvider, error) {
+	// 未配置 discovery_url 时 iss 只能来自配置，缺少 issuer 会接受任意签发方使用同一 JWKS 签发的令牌
+	if guardCfg.DiscoveryURL == "" && guardCfg.Issuer == "" {
+		return nil, fmt.Errorf("auth.guards.%s: issuer is required for oidc guard when discovery_url is not set", guardName)
+	}
+	// 同一签发方通常为多个客户端签发令牌，不校验 aud 时其它应用的令牌也能通过
+	if len(guardCfg.Audience) == 0 && !guardCfg.AllowAnyAudience {
+		return nil, fmt.Errorf("auth.guards.%s: audience is required for oidc guard, set allow_any_audience to accept any audience", guardName)
+	}
+	for _, alg := range guardCfg.Algorithms {
+		if isHMACAlg(strings.ToUpper(strings.TrimSpace(alg))) {
+			return nil, fmt.Errorf("auth.guards.%s.algorithms: %s is not supported for oidc guard", guardName, alg)
+		}
+	}
+	return &oidcProvider{
+		guard:   guardName,
+		cfg:     guardCfg,
+		client:  &http.Client{Timeout: defaultOIDCHTTPTimeout},
+		issuer:  guardCfg.Issuer,
+		jwksURL: guardCfg.JWKSURL,
+	}, nil
+}
+
+// oidcAlgorithms 外部签发方的令牌只接受非对称算法，默认 RS256
+func oidcAlgorithms(guardCfg *GuardConfig) []string {
+	var algs []string
+	for _, alg := range jwtAlgorithms(guardCfg) {
+		if !isHMACAlg(alg) {
+			algs = append(algs, alg)
+		}
+	}
+	if len(algs) == 0 {
+		return []string{"RS256"}
+	}
+	return algs
+}
+
+func (p *oidcProvider) refreshInterval() time.Duration {
+	if p.cfg.JWKSRefresh > 0 {
+		return time.Duration(p.cfg.JWKSRefresh) * time.Second
+	}
+	return defaultOIDCJWKSRefresh * time.Second
+}
+
+// discover 读取 discovery 文档，配置中的 issuer / jwks_url 优先
+func (p *oidcProvider) discover(ctx context.Context) error {
+	if p.jwksURL != "" && p.issuer != "" {
+		return nil
+	}
+	url := p.cfg.DiscoveryURL
+	if url == "" {
+		url = strings.TrimRight(p.cfg.Issuer, "/") + oidcDiscoveryPathSuffix
+	}
+	var doc oidcDiscovery
+	if err := p.getJSON(ctx, url, &doc); err != nil {
+		return fmt.Errorf("oidc discovery: %w", err)
+	}
+	if p.issuer == "" {
+		p.issuer = doc.Issuer
+	}
+	if p.jwksURL == "" {
+		p.jwksURL = doc.JWKSURI
+	}
+	if p.jwksURL == "" {
+		return errors.New("oidc discovery: jwks_uri is empty")
+	}
+	return nil
+}
+
+// refresh 重新拉取 JWKS，调用方持有写锁
+func (p *oidcProvider) refresh(ctx context.Context) error {
+	p.lastAttempt = time.Now()
+	if err := p.discover(ctx); err != nil {
+		return err
+	}
+	var set JWKSet
+	if err := p.getJSON(ctx, p.jwksURL, &set); err != nil {
+		return fmt.Errorf("oidc jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := parseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return errors.New("oidc jwks: no usable signing keys")
+	}
+	p.keys = keys
+	p.fetchedAt = time.Now()
+	return nil
+}
+
+// key 按 kid 返回公钥：缓存过期或出现未知 kid（签发方轮换密钥）时刷新，刷新间隔不小于 oidcMinRefreshInterval，
+// 避免伪造 kid 的请求打满签发方；刷新失败时继续使用已缓存的密钥
+func (p *oidcProvider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.RLock()
+	pub, ok := p.lookup(kid)
+	stale := time.Since(p.fetchedAt) >= p.refreshInterval()
+	p.mu.RUnlock()
+	if ok && !stale {
+		return pub, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pub, ok = p.lookup(kid)
+	if ok && time.Since(p.fetchedAt) < p.refreshInterval() {
+		return pub, nil
+	}
+	if p.lastAttempt.IsZero() || time.Since(p.lastAttempt) >= oidcMinRefreshInterval {
+		p.lastErr = p.refresh(ctx)
+		if p.lastErr == nil {
+			pub, ok = p.lookup(kid)
+		}
+	}
+	if ok {
+		return pub, nil
+	}
+	if len(p.keys) == 0 && p.lastErr != nil {
+		return nil, p.lastErr
+	}
+	return nil, ErrTokenInvalid
+}
+
+// lookup 未携带 kid 且只有一把密钥时使用该密钥
+func (p *oidcProvider) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, pub := range p.keys {
+			return pub, true
+		}
+	}
+	pub, ok := p.keys[kid]
+	return pub, ok
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// parseJWK 解析 RSA / EC 公钥
+func parseJWK(jwk JWK) (crypto.PublicKey, error) {
+	dec := base64.RawURLEncoding
+	switch jwk.Kty {
+	case "RSA":
+		n, err := dec.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := dec.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid rsa jwk")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		curve, ok := oidcCurves[jwk.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := dec.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := dec.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("invalid ec jwk")
+		}
+		return pub, nil
+	}
+	return nil, fmt.Errorf("unsupported kty %s", jwk.Kty)
+}
+
+// authenticateOIDC 校验外部签发方的访问令牌：签名（JWKS）、算法白名单、exp/nbf/iat、iss、aud 及最大年龄
+func (a *Auth) authenticateOIDC(ctx context.Context, guardName, token string) (*AuthContext, error) {
+	guardCfg := a.guards[guardName]
+	p := a.oidc[guardName]
+	if guardCfg == nil || p == nil {
+		return nil, ErrGuardNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*defaultOIDCHTTPTimeout)
+	defer cancel()
+
+	leeway := time.Duration(guardCfg.Leeway) * time.Second
+	claims := jwt.MapClaims{}
+	var keyErr error
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		pub, err := p.key(ctx, kid)
+		if err != nil {
+			keyErr = err
+			return nil, err
+		}
+		return pub, nil
+	},
+		jwt.WithValidMethods(oidcAlgorithms(guardCfg)),
+		jwt.WithLeeway(leeway),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(a.now),
+	)
+	if err != nil {
+		// 签发方不可用属于服务端错误，不转换为令牌无效
+		if keyErr != nil && !errors.Is(keyErr, ErrTokenInvalid) {
+			if a.log != nil {
+				a.log.Warnw("failed to load oidc keys", "guard", guardName, "error", keyErr.Error())
+			}
+			return nil, fmt.Errorf("failed to load oidc keys: %w", keyErr)
+		}
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrTokenInvalid
+	}
+
+	p.mu.RLock()
+	issuer := p.issuer
+	p.mu.RUnlock()
+	if iss, _ := claims.GetIssuer(); issuer != "" && iss != issuer {
+		return nil, ErrTokenInvalid
+	}
+	if len(guardCfg.Audience) > 0 {
+		aud, _ := claims.GetAudience()
+		if !jwtAudienceMatch(aud, guardCfg.Audience) {
+			return nil, ErrTokenInvalid
+		}
+	}
+	if guardCfg.MaxAge > 0 {
+		iat, _ := claims.GetIssuedAt()
+		if iat == nil || a.now().Sub(iat.Time) > time.Duration(guardCfg.MaxAge)*time.Second+leeway {
+			return nil, ErrTokenExpired
+		}
+	}
+
+	userClaim := guardCfg.UserClaim
+	if userClaim == "" {
+		userClaim = "sub"
+	}
+	userID, _ := claimValue(claims, userClaim).(string)
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrTokenInvalid
+	}
+
+	data := map[string]interface{}(claims)
+	data["token_type"] = "oidc"
+	data["scopes"] = oidcScopes(claims)
+	rolesClaim := guardCfg.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = "roles"
+	}
+	if roles := dataStrings(map[string]interface{}{"roles": claimValue(claims, rolesClaim)}, "roles"); len(roles) > 0 {
+		data["roles"] = roles
+	}
+
+	return &AuthContext{
+		GuardName: guardName,
+		UserID:    userID,
+		Token:     token,
+		Data:      data,
+	}, nil
+}
+
+// claimValue 按点号路径读取声明，如 realm_access.roles（Keycloak）
+func claimValue(claims map[string]interface{}, path string) interface{} {
+	var cur interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+// oidcScopes 兼容空格分隔的 scope 与数组形式的 scp
+func oidcScopes(claims jwt.MapClaims) []string {
+	if s, ok := claims["scope"].(string); ok {
+		return strings.Fields(s)
+	}
+	switch scp := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []interface{}:
+		out := make([]string, 0, len(scp))
+		for _, v := range scp {
+			if s, ok := v.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return []string{}
+}
@@ -0,0 +1,167 @@
+package auth_provider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+)
+
+// resolveRoles 确定认证结果的角色：会话取登录时绑定的角色，JWT 等取自定义数据中的 roles 字段，均为空时使用 guard 的 default_roles
+func resolveRoles(guardCfg *GuardConfig, authCtx *AuthContext) []string {
+	var roles []string
+	if authCtx.Session != nil {
+		roles = authCtx.Session.Roles
+	}
+	if len(roles) == 0 {
+		roles = dataStrings(authCtx.Data, "roles")
+	}
+	if len(roles) == 0 {
+		roles = guardCfg.DefaultRoles
+	}
+	return append([]string(nil), roles...)
+}
+
+// dataStrings 读取自定义数据中的字符串列表字段，兼容 JSON 反序列化后的 []interface{}
+func dataStrings(data interface{}, key string) []string {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	switch values := m[key].(type) {
+	case []string:
+		return values
+	case []interface{}:
+		list := make([]string, 0, len(values))
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// permissionMatch 判断授予的权限是否覆盖所需权限，支持 * 与 post.* 前缀通配
+func permissionMatch(granted, required string) bool {
+	if granted == "*" || granted == required {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(granted, ".*"); ok {
+		return strings.HasPrefix(required, prefix+".")
+	}
+	return false
+}
+
+// RolePermissions 返回 guard 中角色对应的权限列表
+func (a *Auth) RolePermissions(guardName, role string) ([]string, error) {
+	guardCfg, ok := a.guards[guardName]
+	if !ok {
+		return nil, ErrGuardNotFound
+	}
+	perms, ok := guardCfg.Roles[role]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s' in guard '%s'", ErrRoleNotFound, role, guardName)
+	}
+	return append([]string(nil), perms...), nil
+}
+
+// GetRoles 从 gin 上下文中获取当前登录用户的角色
+func (a *Auth) GetRoles(c *gin.Context) []string {
+	if c == nil {
+		return nil
+	}
+	roles, _ := c.Get("auth.roles")
+	value, _ := roles.([]string)
+	return value
+}
+
+// HasRole 判断当前用户是否具备任一指定角色
+func (a *Auth) HasRole(c *gin.Context, roles ...string) bool {
+	for _, role := range a.GetRoles(c) {
+		if z.InStringSlice(roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission 判断当前用户是否具备指定权限
+// 权限来自当前 guard 中用户角色的权限列表，服务账号的 scopes 同样视为权限
+func (a *Auth) HasPermission(c *gin.Context, permission string) bool {
+	if c == nil {
+		return false
+	}
+	if guardCfg, ok := a.guards[c.GetString("auth.guard")]; ok {
+		for _, role := range a.GetRoles(c) {
+			for _, granted := range guardCfg.Roles[role] {
+				if permissionMatch(granted, permission) {
+					return true
+				}
+			}
+		}
+	}
+	if raw, ok := c.Get("auth.data"); ok {
+		if data, ok := raw.(map[string]interface{}); ok && data["token_type"] == "service_account" {
+			for _, scope := range dataStrings(data, "scopes") {
+				if permissionMatch(scope, permission) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// RequirePermission 路由级授权中间件，需具备全部指定权限，未登录返回 401，权限不足返回 StatusPermissionDenied
+// 需在 AuthMiddleware 或 RequireGuard 之后使用
+//
+//	api.POST("/posts", auth_provider.RequirePermission(auth, "post.create"), handler)
+func RequirePermission(ap *Auth, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ap == nil || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		if c.GetString("auth.guard") == "" {
+			abortUnauthorized(c, ErrTokenMissing)
+			return
+		}
+		for _, permission := range permissions {
+			if !ap.HasPermission(c, permission) {
+				abortForbidden(c)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireRole 路由级授权中间件，需具备任一指定角色
+func RequireRole(ap *Auth, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ap == nil || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		if c.GetString("auth.guard") == "" {
+			abortUnauthorized(c, ErrTokenMissing)
+			return
+		}
+		if !ap.HasRole(c, roles...) {
+			abortForbidden(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortForbidden 返回 403 并终止请求
+func abortForbidden(c *gin.Context) {
+	applyAuthFailureCORSHeaders(c)
+	z.Failure(c, ErrPermissionDenied.Message, ErrPermissionDenied.Status(), http.StatusForbidden)
+	c.Abort()
+}
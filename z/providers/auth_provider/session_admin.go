@@ -0,0 +1,237 @@
+package auth_provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sessionScanCount 扫描 Redis 会话键时每批数量
+const sessionScanCount = 500
+
+// SessionFilter 会话筛选条件，零值字段不参与筛选
+type SessionFilter struct {
+	UserID      string `json:"user_id" form:"user_id"`
+	Device      string `json:"device" form:"device"`             // 匹配会话数据中的 device / device_id
+	LoginAfter  int64  `json:"login_after" form:"login_after"`   // 登录时间下限（Unix 秒，含）
+	LoginBefore int64  `json:"login_before" form:"login_before"` // 登录时间上限（Unix 秒，不含）
+}
+
+// SessionPage 会话分页结果
+type SessionPage struct {
+	Total    int            `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Data     []*SessionData `json:"data"`
+}
+
+// SessionStats guard 会话统计
+type SessionStats struct {
+	Guard    string `json:"guard"`
+	Sessions int    `json:"sessions"` // 活跃会话数
+	Users    int    `json:"users"`    // 在线用户数
+	Devices  int    `json:"devices"`  // 不同设备数，未记录设备的会话不计入
+}
+
+// ListSessions 扫描会话存储，按登录时间倒序分页返回 guard 下的活跃会话
+func (a *Auth) ListSessions(guard string, filter SessionFilter, page, pageSize int) (*SessionPage, error) {
+	sessions, err := a.scanSessions(guard, filter)
+	if err != nil {
+		return nil, err
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].LoginTime != sessions[j].LoginTime {
+			return sessions[i].LoginTime > sessions[j].LoginTime
+		}
+		return sessions[i].TokenHash < sessions[j].TokenHash
+	})
+
+	result := &SessionPage{Total: len(sessions), Page: page, PageSize: pageSize, Data: []*SessionData{}}
+	start := (page - 1) * pageSize
+	if start < len(sessions) {
+		end := start + pageSize
+		if end > len(sessions) {
+			end = len(sessions)
+		}
+		result.Data = sessions[start:end]
+	}
+	return result, nil
+}
+
+// RevokeSessions 批量注销符合条件的会话，返回注销数量；筛选条件为空时注销 guard 下全部会话
+func (a *Auth) RevokeSessions(guard string, filter SessionFilter) (int, error) {
+	sessions, err := a.scanSessions(guard, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if err := a.deleteSession(guard, session.TokenHash); err != nil {
+			return revoked, fmt.Errorf("failed to clear session: %w", err)
+		}
+		if err := a.removeUserSessionHash(guard, session.UserID, session.TokenHash); err != nil {
+			return revoked, fmt.Errorf("failed to clear session index: %w", err)
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// GetSessionStats 返回 guard 的会话统计
+func (a *Auth) GetSessionStats(guard string) (*SessionStats, error) {
+	sessions, err := a.scanSessions(guard, SessionFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	users := map[string]struct{}{}
+	devices := map[string]struct{}{}
+	for _, session := range sessions {
+		users[session.UserID] = struct{}{}
+		if device := sessionDevice(session); device != "" {
+			devices[session.UserID+"\x00"+device] = struct{}{}
+		}
+	}
+	return &SessionStats{Guard: guard, Sessions: len(sessions), Users: len(users), Devices: len(devices)}, nil
+}
+
+// GetAllSessionStats 返回所有会话型 guard 的会话统计
+func (a *Auth) GetAllSessionStats() ([]*SessionStats, error) {
+	names := make([]string, 0, len(a.guards))
+	for name, guard := range a.guards {
+		if guard != nil && guard.Type == AuthTypeSession {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := make([]*SessionStats, 0, len(names))
+	for _, name := range names {
+		stats, err := a.GetSessionStats(name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, stats)
+	}
+	return out, nil
+}
+
+// scanSessions 扫描 guard 的全部会话并按条件筛选
+func (a *Auth) scanSessions(guard string, filter SessionFilter) ([]*SessionData, error) {
+	guardConfig, ok := a.guards[guard]
+	if !ok {
+		return nil, ErrGuardNotFound
+	}
+	if guardConfig.Type != AuthTypeSession {
+		return nil, fmt.Errorf("guard '%s' does not support session login", guard)
+	}
+
+	// 指定用户时直接读取用户会话索引，无需扫描
+	var hashes []string
+	var err error
+	if filter.UserID != "" {
+		hashes, err = a.getUserSessionHashes(guard, filter.UserID)
+	} else {
+		hashes, err = a.scanSessionHashes(guard)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*SessionData, 0, len(hashes))
+	for _, hash := range hashes {
+		session, exists, err := a.getSession(guard, hash)
+		if err != nil {
+			return nil, err
+		}
+		if !exists || session == nil || !filter.match(session) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// scanSessionHashes 扫描会话存储中 guard 的会话哈希
+func (a *Auth) scanSessionHashes(guard string) ([]string, error) {
+	prefix := a.getSessionCacheKey(guard, "")
+
+	store, err := a.store(guard)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := store.Scan(context.Background(), prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(keys))
+	for _, key := range keys {
+		hash := strings.TrimPrefix(key, prefix)
+		// guard 名称存在前缀关系时（如 user 与 user_admin）会扫描到其它 guard 的键，按哈希格式排除
+		if !isTokenHash(hash) {
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+func (f SessionFilter) match(session *SessionData) bool {
+	if f.UserID != "" && session.UserID != f.UserID {
+		return false
+	}
+	if f.LoginAfter > 0 && session.LoginTime < f.LoginAfter {
+		return false
+	}
+	if f.LoginBefore > 0 && session.LoginTime >= f.LoginBefore {
+		return false
+	}
+	if f.Device != "" && sessionDevice(session) != f.Device {
+		return false
+	}
+	return true
+}
+
+// sessionDevice 读取会话数据中的设备标识
+func sessionDevice(session *SessionData) string {
+	if session == nil || session.Data == nil {
+		return ""
+	}
+	data, ok := session.Data.(map[string]interface{})
+	if !ok {
+		b, err := json.Marshal(session.Data)
+		if err != nil || json.Unmarshal(b, &data) != nil {
+			return ""
+		}
+	}
+	for _, key := range []string{"device", "device_id"} {
+		if value, ok := data[key]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// isTokenHash 判断是否为 getTokenHash 生成的哈希
+func isTokenHash(value string) bool {
+	if len(value) != 32 {
+		return false
+	}
+	for _, c := range value {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package auth_provider
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// 会话数据保护方式
+const (
+	SealModeNone    = "none"    // 明文 JSON
+	SealModeSign    = "sign"    // HMAC-SHA256 签名，内容仍可读，防止篡改和伪造
+	SealModeEncrypt = "encrypt" // AES-256-GCM 加密，同时防止泄露和篡改
+)
+
+// sealPrefix 受保护值的前缀，明文 JSON 不会以该前缀开头
+var sealPrefix = []byte("zs1:")
+
+// sealKey 由配置密钥派生的加密与签名密钥
+type sealKey struct {
+	id   string
+	aead cipher.AEAD
+	mac  []byte
+}
+
+// sessionSealer 在写入存储前加密或签名，读取时解密或校验，密钥与缓存键绑定，值不能被移到其他键下使用
+type sessionSealer struct {
+	mode      string
+	active    *sealKey
+	keys      map[string]*sealKey
+	plaintext bool
+}
+
+// loadSessionSealer 读取 auth.session_sealing 配置
+//
+//	auth:
+//	  session_sealing:
+//	    mode: encrypt             # none（默认）/ sign / encrypt
+//	    active_key: k2            # 用于写入的密钥，为空时取 keys 中排序最后的一个
+//	    keys:                     # 任意长度的密钥，轮换时保留旧密钥直至旧会话全部过期
+//	      k1: "old-secret"
+//	      k2: "new-secret"
+//	    accept_plaintext: true    # 接受未保护的旧值，从明文迁移时开启
+//
+// keys 为空时使用 app.key
+func loadSessionSealer(cfg *config_provider.Config) (*sessionSealer, error) {
+	s := &sessionSealer{
+		mode:      strings.ToLower(strings.TrimSpace(cfg.GetString("auth.session_sealing.mode", SealModeNone))),
+		keys:      map[string]*sealKey{},
+		plaintext: cfg.GetBool("auth.session_sealing.accept_plaintext", false),
+	}
+	switch s.mode {
+	case "", SealModeNone:
+		return nil, nil
+	case SealModeSign, SealModeEncrypt:
+	default:
+		return nil, fmt.Errorf("invalid auth.session_sealing.mode: %s", s.mode)
+	}
+
+	secrets := map[string]string{}
+	for id, v := range cfg.GetStringMap("auth.session_sealing.keys") {
+		secrets[id] = z.ToString(v)
+	}
+	if len(secrets) == 0 {
+		if appKey := cfg.GetString("app.key"); appKey != "" {
+			secrets = map[string]string{"app": appKey}
+		}
+	}
+	if len(secrets) == 0 {
+		return nil, fmt.Errorf("missing auth.session_sealing.keys")
+	}
+	ids := make([]string, 0, len(secrets))
+	for id, secret := range secrets {
+		if id == "" || strings.Contains(id, ":") || secret == "" {
+			return nil, fmt.Errorf("invalid auth.session_sealing.keys.%s", id)
+		}
+		key, err := newSealKey(id, secret)
+		if err != nil {
+			return nil, err
+		}
+		s.keys[id] = key
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	active := strings.ToLower(strings.TrimSpace(cfg.GetString("auth.session_sealing.active_key")))
+	if active == "" {
+		active = ids[len(ids)-1]
+	}
+	if s.active = s.keys[active]; s.active == nil {
+		return nil, fmt.Errorf("auth.session_sealing.active_key: key '%s' is not defined", active)
+	}
+	return s, nil
+}
+
+func newSealKey(id, secret string) (*sealKey, error) {
+	encKey := sha256.Sum256([]byte("session-encrypt|" + secret))
+	macKey := sha256.Sum256([]byte("session-sign|" + secret))
+	block, err := aes.NewCipher(encKey[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealKey{id: id, aead: aead, mac: macKey[:]}, nil
+}
+
+// seal 保护 JSON 值，格式为 zs1:<模式>:<密钥ID>:<数据>
+//
+//	sign:    zs1:s:<kid>:<base64 签名>:<JSON>
+//	encrypt: zs1:e:<kid>:<base64 nonce+密文>
+func (s *sessionSealer) seal(cacheKey string, plain []byte) ([]byte, error) {
+	if s == nil {
+		return plain, nil
+	}
+	k := s.active
+	var buf bytes.Buffer
+	buf.Write(sealPrefix)
+	if s.mode == SealModeSign {
+		buf.WriteString("s:" + k.id + ":")
+		buf.WriteString(base64.RawURLEncoding.EncodeToString(k.sign(cacheKey, plain)))
+		buf.WriteByte(':')
+		buf.Write(plain)
+		return buf.Bytes(), nil
+	}
+
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := k.aead.Seal(nonce, nonce, plain, []byte(cacheKey))
+	buf.WriteString("e:" + k.id + ":")
+	buf.WriteString(base64.RawURLEncoding.EncodeToString(sealed))
+	return buf.Bytes(), nil
+}
+
+// open 校验并还原 JSON 值，未配置保护时原样返回；值被篡改、密钥已移除或不接受明文时返回 ErrSessionInvalid
+func (s *sessionSealer) open(cacheKey string, value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, sealPrefix) {
+		if s == nil || s.plaintext {
+			return value, nil
+		}
+		return nil, ErrSessionInvalid
+	}
+	if s == nil {
+		return nil, ErrSessionInvalid
+	}
+
+	parts := bytes.SplitN(value[len(sealPrefix):], []byte(":"), 4)
+	if len(parts) < 3 {
+		return nil, ErrSessionInvalid
+	}
+	k := s.keys[string(parts[1])]
+	if k == nil {
+		return nil, ErrSessionInvalid
+	}
+	switch string(parts[0]) {
+	case "s":
+		if len(parts) != 4 {
+			return nil, ErrSessionInvalid
+		}
+		sig, err := base64.RawURLEncoding.DecodeString(string(parts[2]))
+		if err != nil || !hmac.Equal(sig, k.sign(cacheKey, parts[3])) {
+			return nil, ErrSessionInvalid
+		}
+		return parts[3], nil
+	case "e":
+		if len(parts) != 3 {
+			return nil, ErrSessionInvalid
+		}
+		sealed, err := base64.RawURLEncoding.DecodeString(string(parts[2]))
+		if err != nil || len(sealed) < k.aead.NonceSize() {
+			return nil, ErrSessionInvalid
+		}
+		nonce, ciphertext := sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():]
+		plain, err := k.aead.Open(nil, nonce, ciphertext, []byte(cacheKey))
+		if err != nil {
+			return nil, ErrSessionInvalid
+		}
+		return plain, nil
+	}
+	return nil, ErrSessionInvalid
+}
+
+func (k *sealKey) sign(cacheKey string, plain []byte) []byte {
+	mac := hmac.New(sha256.New, k.mac)
+	mac.Write([]byte(cacheKey))
+	mac.Write([]byte{0})
+	mac.Write(plain)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,265 @@
+package auth_provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/mem_cache_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/redis_provider"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// SessionStore 会话、服务账号、验证令牌等鉴权数据的存储后端，值为 JSON 字节
+// 按 guard 的 cache 配置选择：memory、redis 或通过 RegisterSessionStore 注册的自定义后端
+type SessionStore interface {
+	// Get 读取键，不存在时返回 false 且 err 为 nil
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set 写入键，ttl <= 0 表示不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	// Scan 返回指定前缀的全部键
+	Scan(ctx context.Context, prefix string) ([]string, error)
+}
+
+// SessionStoreExpirer 可选接口，仅刷新键的过期时间；未实现时以读取后重写代替
+type SessionStoreExpirer interface {
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// SessionStoreGetDeleter 可选接口，原子地读取并删除键，用于一次性验证令牌；未实现时仅在进程内保证原子性
+type SessionStoreGetDeleter interface {
+	GetDel(ctx context.Context, key string) ([]byte, bool, error)
+}
+
+// NamedSessionStore 具名的存储后端
+type NamedSessionStore struct {
+	Name  string
+	Store SessionStore
+}
+
+// SessionStoreOut 注册存储后端的 fx 出参
+type SessionStoreOut struct {
+	fx.Out
+
+	Store NamedSessionStore `group:"auth_session_stores"`
+}
+
+// RegisterSessionStore 注册自定义存储后端，guard 配置 cache: <name> 时使用
+//
+//	fx.Provide(func(db *db_provider.DB) auth_provider.SessionStoreOut {
+//		return auth_provider.RegisterSessionStore("database", NewDBSessionStore(db))
+//	})
+func RegisterSessionStore(name string, store SessionStore) SessionStoreOut {
+	return SessionStoreOut{Store: NamedSessionStore{Name: name, Store: store}}
+}
+
+// store 返回 guard 使用的存储后端，未配置或配置为 redis 时优先使用 redis，未启用 redis 时回退到内存
+func (a *Auth) store(guardName string) (SessionStore, error) {
+	name := ""
+	if guard, ok := a.guards[guardName]; ok {
+		name = guard.Cache
+	}
+	switch name {
+	case "", CacheTypeRedis:
+		if a.redis != nil {
+			return &redisSessionStore{redis: a.redis}, nil
+		}
+		fallthrough
+	case CacheTypeMemory:
+		if a.memCache == nil {
+			return nil, fmt.Errorf("mem cache not enabled")
+		}
+		return &memorySessionStore{cache: a.memCache, mu: &a.verifyMu}, nil
+	}
+	if s, ok := a.stores[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("session store '%s' not registered", name)
+}
+
+// getCache 读取 guard 存储中的键并反序列化到 dest
+func (a *Auth) getCache(guardName, key string, dest interface{}) (bool, error) {
+	s, err := a.store(guardName)
+	if err != nil {
+		return false, err
+	}
+	b, exists, err := s.Get(context.Background(), key)
+	if err != nil || !exists {
+		return false, err
+	}
+	if b, err = a.sealer.open(key, b); err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(b, dest)
+}
+
+// setCache 序列化后写入 guard 存储，expiration <= 0 表示不过期
+func (a *Auth) setCache(guardName, key string, value interface{}, expiration time.Duration) error {
+	s, err := a.store(guardName)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if b, err = a.sealer.seal(key, b); err != nil {
+		return err
+	}
+	return s.Set(context.Background(), key, b, expiration)
+}
+
+func (a *Auth) deleteCache(guardName, key string) error {
+	s, err := a.store(guardName)
+	if err != nil {
+		return err
+	}
+	return s.Delete(context.Background(), key)
+}
+
+// expireCache 刷新键的过期时间，键不存在时忽略
+func (a *Auth) expireCache(guardName, key string, expiration time.Duration) error {
+	s, err := a.store(guardName)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if e, ok := s.(SessionStoreExpirer); ok {
+		return e.Expire(ctx, key, expiration)
+	}
+	b, exists, err := s.Get(ctx, key)
+	if err != nil || !exists {
+		return err
+	}
+	return s.Set(ctx, key, b, expiration)
+}
+
+// takeCache 读取键并还原受保护的值，consume 为 true 时同时删除
+func (a *Auth) takeCache(guardName, key string, consume bool) ([]byte, bool, error) {
+	b, exists, err := a.takeRawCache(guardName, key, consume)
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+	if b, err = a.sealer.open(key, b); err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+// takeRawCache 读取存储中的原始值，consume 为 true 时同时删除
+func (a *Auth) takeRawCache(guardName, key string, consume bool) ([]byte, bool, error) {
+	s, err := a.store(guardName)
+	if err != nil {
+		return nil, false, err
+	}
+	ctx := context.Background()
+	if !consume {
+		return s.Get(ctx, key)
+	}
+	if gd, ok := s.(SessionStoreGetDeleter); ok {
+		return gd.GetDel(ctx, key)
+	}
+	a.verifyMu.Lock()
+	defer a.verifyMu.Unlock()
+	b, exists, err := s.Get(ctx, key)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+	return b, true, s.Delete(ctx, key)
+}
+
+// memorySessionStore 基于 MemCache 的存储，仅适用于单实例
+type memorySessionStore struct {
+	cache *mem_cache_provider.MemCache
+	mu    *sync.Mutex
+}
+
+func (s *memorySessionStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, exists := s.cache.Get(key)
+	if !exists {
+		return nil, false, nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("invalid cache data for %s", key)
+	}
+	return b, true, nil
+}
+
+func (s *memorySessionStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = -1
+	}
+	s.cache.Set(key, value, ttl)
+	return nil
+}
+
+func (s *memorySessionStore) Delete(_ context.Context, key string) error {
+	s.cache.Delete(key)
+	return nil
+}
+
+func (s *memorySessionStore) Scan(_ context.Context, prefix string) ([]string, error) {
+	return s.cache.Keys(prefix), nil
+}
+
+func (s *memorySessionStore) GetDel(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, exists, err := s.Get(ctx, key)
+	if exists {
+		s.cache.Delete(key)
+	}
+	return b, exists, err
+}
+
+// redisSessionStore 基于 Redis 的存储，多实例共享
+type redisSessionStore struct {
+	redis *redis_provider.Redis
+}
+
+func (s *redisSessionStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := s.redis.UniversalClient().Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+func (s *redisSessionStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return s.redis.UniversalClient().Set(ctx, key, value, ttl).Err()
+}
+
+func (s *redisSessionStore) Delete(ctx context.Context, key string) error {
+	return s.redis.DeleteCtx(ctx, key)
+}
+
+func (s *redisSessionStore) Scan(ctx context.Context, prefix string) ([]string, error) {
+	return s.redis.ScanKeys(ctx, prefix+"*", sessionScanCount)
+}
+
+func (s *redisSessionStore) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return s.redis.ExpireCtx(ctx, key, ttl)
+}
+
+func (s *redisSessionStore) GetDel(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := s.redis.UniversalClient().GetDel(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
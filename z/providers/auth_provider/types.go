@@ -1,11 +1,18 @@
 package auth_provider
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z"
+)
 
 // 认证类型常量
 const (
 	AuthTypeSession = "session" // 服务端会话认证类型
 	AuthTypeToken   = "token"   // 固定Token认证类型
+	AuthTypeJWT     = "jwt"     // 无状态 JWT 认证类型
+	AuthTypeAPIKey  = "apikey"  // API Key 认证类型
+	AuthTypeOIDC    = "oidc"    // 外部 OAuth2/OIDC 签发方的访问令牌
 )
 
 // 缓存类型常量
@@ -14,16 +21,57 @@ const (
 	CacheTypeMemory = "memory" // 内存缓存
 )
 
+// 设备数超限策略常量
+const (
+	DeviceLimitEvictOldest = "evict_oldest" // 踢掉最早登录的设备（默认）
+	DeviceLimitReject      = "reject"       // 拒绝新登录
+)
+
 // GuardConfig guard配置结构
 type GuardConfig struct {
-	Type                 string   `json:"type"`                   // session | token
+	Type                 string   `json:"type"`                   // session | token | jwt | apikey | oidc
 	Token                string   `json:"token"`                  // 固定令牌
 	Prefix               string   `json:"prefix"`                 // 路由前缀
 	Anonymity            []string `json:"anonymity"`              // 匿名路由列表
-	Cache                string   `json:"cache"`                  // memory | redis
+	Cache                string   `json:"cache"`                  // memory | redis | 自定义存储后端名称
 	Duration             int      `json:"duration"`               // 会话空闲超时时间（秒）
+	IdleTimeout          int      `json:"idle_timeout"`           // 会话空闲超时时间（秒），优先于 duration，每次认证按续期间隔滑动延长
+	MaxLifetime          int      `json:"max_lifetime"`           // 会话最长存活时间（秒），从登录起计算，滑动续期不会超过，0 表示不限制
 	TouchInterval        int      `json:"touch_interval"`         // 最小续期间隔（秒）
 	SingleSessionEnabled bool     `json:"single_session_enabled"` // 单会话登录开关（默认 false）
+	MaxDevices           int      `json:"max_devices"`            // 同一用户最多同时登录的设备数，0 表示不限制
+	DeviceLimitStrategy  string   `json:"device_limit_strategy"`  // 超出设备数时的策略：evict_oldest | reject
+
+	// JWT 配置（type 为 jwt 时生效）
+	Secret     string   `json:"secret"`      // HMAC 签名密钥
+	PrivateKey string   `json:"private_key"` // RSA/ECDSA 私钥，PEM 内容或文件路径
+	PublicKey  string   `json:"public_key"`  // RSA/ECDSA 公钥，PEM 内容或文件路径，仅校验时配置即可，未配置时由私钥导出
+	KeyID      string   `json:"key_id"`      // JWT 头部及 JWKS 中的 kid，默认为公钥的 RFC 7638 指纹
+	Issuer     string   `json:"issuer"`      // 签发者，非空时校验 iss
+	Audience   []string `json:"audience"`    // 受众，非空时 aud 需包含其中之一
+	Leeway     int      `json:"leeway"`      // 时钟偏差容忍（秒）
+	Algorithms []string `json:"algorithms"`  // 允许的签名算法，第一个用于签发，默认 HS256
+	MaxAge     int      `json:"max_age"`     // 令牌最大年龄（秒），按 iat 计算，0 表示不限制
+
+	// API Key 配置（type 为 apikey 时生效）
+	KeyHeader string `json:"key_header"` // 读取密钥的请求头，默认 X-API-Key，未携带时回退 Authorization
+	KeyStore  string `json:"key_store"`  // cache（默认）| config | 自定义存储后端名称
+
+	// OIDC 配置（type 为 oidc 时生效，同时使用 issuer、audience、leeway、algorithms、max_age）
+	DiscoveryURL string `json:"discovery_url"` // discovery 文档地址，默认 issuer + /.well-known/openid-configuration
+	JWKSURL      string `json:"jwks_url"`      // JWKS 地址，未配置时取自 discovery
+	JWKSRefresh  int    `json:"jwks_refresh"`  // JWKS 缓存时间（秒），默认 300
+	UserClaim    string `json:"user_claim"`    // 作为 UserID 的声明，默认 sub
+	RolesClaim   string `json:"roles_claim"`   // 角色声明，支持点号路径如 realm_access.roles，默认 roles
+	// AllowAnyAudience 不校验 aud；oidc guard 未配置 audience 时必须显式开启
+	AllowAnyAudience bool `json:"allow_any_audience"`
+
+	// TrustBaggage 认证成功后沿用调用方传递的 tenant_id、user_id、guard baggage，仅用于内部服务间调用的 guard
+	TrustBaggage bool `json:"trust_baggage"`
+
+	// 权限配置
+	Roles        map[string][]string `json:"roles"`         // 角色 -> 权限列表，权限支持 * 与 post.* 通配
+	DefaultRoles []string            `json:"default_roles"` // 认证结果未携带角色时赋予的角色
 }
 
 // AuthContext 认证上下文结构
@@ -32,18 +80,22 @@ type AuthContext struct {
 	UserID    string       `json:"user_id"`    // 用户ID
 	Token     string       `json:"token"`      // 当前会话令牌
 	Session   *SessionData `json:"session"`    // 当前会话数据
+	Roles     []string     `json:"roles"`      // 当前用户角色
 	Data      interface{}  `json:"data"`       // 自定义数据
 }
 
 // SessionData 服务端会话数据
 type SessionData struct {
-	TokenHash  string      `json:"token_hash"`
-	UserID     string      `json:"user_id"`
-	GuardName  string      `json:"guard_name"`
-	LoginTime  int64       `json:"login_time"`
-	LastSeenAt int64       `json:"last_seen_at"`
-	ExpiresAt  int64       `json:"expires_at"`
-	Data       interface{} `json:"data,omitempty"`
+	TokenHash  string `json:"token_hash"`
+	UserID     string `json:"user_id"`
+	GuardName  string `json:"guard_name"`
+	LoginTime  int64  `json:"login_time"`
+	LastSeenAt int64  `json:"last_seen_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	// 绝对过期时间，guard 配置 max_lifetime 时设置
+	AbsoluteExpiresAt int64       `json:"absolute_expires_at,omitempty"`
+	Roles             []string    `json:"roles,omitempty"`
+	Data              interface{} `json:"data,omitempty"`
 }
 
 // AuthError 认证错误类型
@@ -56,16 +108,38 @@ func (e *AuthError) Error() string {
 	return e.Message
 }
 
+// Status 返回对应的业务状态码，供 z.Failure 输出
+func (e *AuthError) Status() z.Status {
+	switch e.Code {
+	case "TOKEN_MISSING":
+		return z.StatusLoginRequired
+	case "TOKEN_EXPIRED", "VERIFICATION_TOKEN_EXPIRED":
+		return z.StatusAuthTokenExpired
+	case "SESSION_EXPIRED", "SESSION_NOT_FOUND":
+		return z.StatusSessionExpired
+	case "PERMISSION_DENIED", "ROLE_NOT_FOUND":
+		return z.StatusPermissionDenied
+	case "SERVICE_ACCOUNT_DISABLED":
+		return z.StatusAccountDisabled
+	case "DEVICE_LIMIT_EXCEEDED", "API_KEY_RATE_LIMITED":
+		return z.StatusTooManyRequests
+	}
+	return z.StatusAuthTokenInvalid
+}
+
 // 预定义的认证错误
 var (
 	ErrTokenMissing        = &AuthError{Code: "TOKEN_MISSING", Message: "token required"}
 	ErrTokenInvalid        = &AuthError{Code: "TOKEN_INVALID", Message: "invalid token"}
 	ErrSessionExpired      = &AuthError{Code: "SESSION_EXPIRED", Message: "session expired"}
+	ErrTokenExpired        = &AuthError{Code: "TOKEN_EXPIRED", Message: "token expired"}
 	ErrSessionNotFound     = &AuthError{Code: "SESSION_NOT_FOUND", Message: "session expired"}
 	ErrSessionInvalid      = &AuthError{Code: "SESSION_INVALID", Message: "invalid session"}
 	ErrGuardNotFound       = &AuthError{Code: "GUARD_NOT_FOUND", Message: "guard not found"}
 	ErrAuthTypeUnsupported = &AuthError{Code: "AUTH_TYPE_UNSUPPORTED", Message: "unsupported auth type"}
 	ErrPermissionDenied    = &AuthError{Code: "PERMISSION_DENIED", Message: "access denied"}
+	ErrRoleNotFound        = &AuthError{Code: "ROLE_NOT_FOUND", Message: "role not found"}
+	ErrDeviceLimitExceeded = &AuthError{Code: "DEVICE_LIMIT_EXCEEDED", Message: "too many devices signed in"}
 )
 
 // convertToFriendlyError 将技术性错误转换为用户友好的错误
@@ -0,0 +1,199 @@
+package auth_provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// 验证令牌用途常量
+const (
+	TokenPurposeEmailVerification = "email_verification" // 邮箱验证
+	TokenPurposePasswordReset     = "password_reset"     // 密码重置
+	TokenPurposeMagicLogin        = "magic_login"        // 免密登录链接
+)
+
+const defaultVerificationTokenTTL = 15 * time.Minute
+
+// 验证令牌相关错误
+var (
+	ErrVerificationTokenInvalid = &AuthError{Code: "VERIFICATION_TOKEN_INVALID", Message: "invalid verification token"}
+	ErrVerificationTokenExpired = &AuthError{Code: "VERIFICATION_TOKEN_EXPIRED", Message: "verification token expired"}
+)
+
+// VerificationToken 一次性验证令牌数据
+type VerificationToken struct {
+	Purpose   string      `json:"purpose"`
+	Subject   string      `json:"subject"` // 用户ID或邮箱等
+	GuardName string      `json:"guard_name"`
+	IssuedAt  int64       `json:"issued_at"`
+	ExpiresAt int64       `json:"expires_at"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// getVerificationSecret 读取令牌签名密钥
+func (a *Auth) getVerificationSecret() (string, error) {
+	secret := a.cfg.GetString("auth.verification.secret")
+	if secret == "" {
+		secret = a.cfg.GetString("app.key")
+	}
+	if secret == "" {
+		return "", fmt.Errorf("missing auth.verification.secret")
+	}
+	return secret, nil
+}
+
+func (a *Auth) getVerificationCacheKey(guardName, purpose, nonce string) string {
+	return fmt.Sprintf("auth_verify_%s_%s_%s", guardName, purpose, a.getTokenHash(nonce))
+}
+
+// signVerificationNonce 对 nonce 进行签名，签名与用途绑定，防止跨用途复用
+func (a *Auth) signVerificationNonce(secret, guardName, purpose, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(guardName + "|" + purpose + "|" + nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IssueVerificationToken 签发一次性验证令牌（邮箱验证、密码重置、免密登录等）
+// ttl 小于等于 0 时默认 15 分钟
+func (a *Auth) IssueVerificationToken(guard, purpose, subject string, ttl time.Duration, data ...interface{}) (string, error) {
+	if strings.TrimSpace(guard) == "" {
+		return "", fmt.Errorf("guard name cannot be empty")
+	}
+	if strings.TrimSpace(purpose) == "" {
+		return "", fmt.Errorf("purpose cannot be empty")
+	}
+	if strings.TrimSpace(subject) == "" {
+		return "", fmt.Errorf("subject cannot be empty")
+	}
+	if _, ok := a.guards[guard]; !ok {
+		return "", ErrGuardNotFound
+	}
+	if ttl <= 0 {
+		ttl = defaultVerificationTokenTTL
+	}
+
+	secret, err := a.getVerificationSecret()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := a.generateSessionToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := a.now()
+	vt := &VerificationToken{
+		Purpose:   purpose,
+		Subject:   subject,
+		GuardName: guard,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+	if len(data) > 0 && data[0] != nil {
+		vt.Data = data[0]
+	}
+
+	if err := a.setCache(guard, a.getVerificationCacheKey(guard, purpose, nonce), vt, ttl); err != nil {
+		return "", fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	return nonce + "." + a.signVerificationNonce(secret, guard, purpose, nonce), nil
+}
+
+// VerifyVerificationToken 校验并消费一次性验证令牌，校验成功后令牌立即失效
+func (a *Auth) VerifyVerificationToken(guard, purpose, token string) (*VerificationToken, error) {
+	return a.loadVerificationToken(guard, purpose, token, true)
+}
+
+// PeekVerificationToken 校验验证令牌但不消费，用于展示重置密码页面等场景
+func (a *Auth) PeekVerificationToken(guard, purpose, token string) (*VerificationToken, error) {
+	return a.loadVerificationToken(guard, purpose, token, false)
+}
+
+// RevokeVerificationToken 撤销验证令牌
+func (a *Auth) RevokeVerificationToken(guard, purpose, token string) error {
+	nonce, err := a.parseVerificationToken(guard, purpose, token)
+	if err != nil {
+		return err
+	}
+	return a.deleteCache(guard, a.getVerificationCacheKey(guard, purpose, nonce))
+}
+
+// LoginWithMagicLink 消费免密登录令牌并创建会话
+func (a *Auth) LoginWithMagicLink(guard, token string, duration time.Duration) (string, *VerificationToken, error) {
+	vt, err := a.VerifyVerificationToken(guard, TokenPurposeMagicLogin, token)
+	if err != nil {
+		return "", nil, err
+	}
+	sessionToken, err := a.Login(guard, vt.Subject, duration, vt.Data)
+	if err != nil {
+		return "", nil, err
+	}
+	return sessionToken, vt, nil
+}
+
+// VerificationLink 将令牌拼接到链接中，供邮件模板使用
+func VerificationLink(baseURL, token string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// parseVerificationToken 校验签名并返回 nonce
+func (a *Auth) parseVerificationToken(guard, purpose, token string) (string, error) {
+	token = strings.TrimSpace(token)
+	nonce, signature, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" || signature == "" {
+		return "", ErrVerificationTokenInvalid
+	}
+	secret, err := a.getVerificationSecret()
+	if err != nil {
+		return "", err
+	}
+	expected := a.signVerificationNonce(secret, guard, purpose, nonce)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return "", ErrVerificationTokenInvalid
+	}
+	return nonce, nil
+}
+
+func (a *Auth) loadVerificationToken(guard, purpose, token string, consume bool) (*VerificationToken, error) {
+	nonce, err := a.parseVerificationToken(guard, purpose, token)
+	if err != nil {
+		return nil, err
+	}
+	key := a.getVerificationCacheKey(guard, purpose, nonce)
+
+	raw, exists, err := a.takeCache(guard, key, consume)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrVerificationTokenExpired
+	}
+	var vt VerificationToken
+	if err := json.Unmarshal(raw, &vt); err != nil {
+		return nil, ErrVerificationTokenInvalid
+	}
+
+	if vt.Purpose != purpose || vt.GuardName != guard {
+		return nil, ErrVerificationTokenInvalid
+	}
+	if vt.ExpiresAt > 0 && a.now().Unix() > vt.ExpiresAt {
+		return nil, ErrVerificationTokenExpired
+	}
+
+	return &vt, nil
+}
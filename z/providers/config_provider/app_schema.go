@@ -0,0 +1,12 @@
+package config_provider
+
+// appConfig app.yml 配置结构，业务配置项可通过 RegisterSchema("app", ...) 合并
+type appConfig struct {
+	Name    string `required:"true" desc:"应用名称，用于日志、链路追踪及健康检查"`
+	Env     string `desc:"运行环境，如 production、staging"`
+	Version string `desc:"版本号，错误上报的默认 release"`
+	Debug   bool   `default:"true" desc:"调试模式"`
+	Key     string `desc:"应用密钥，未配置 auth.verification.secret 时用于签发验证令牌"`
+}
+
+var _ = RegisterSchema("app", "应用", appConfig{})
@@ -6,21 +6,62 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/spf13/viper"
 	"go.uber.org/fx"
 )
 
 // Config 配置管理
 type Config struct {
-	path    string
-	configs map[string]*viper.Viper
-	isDir   bool
+	mu        sync.RWMutex
+	path      string
+	configs   map[string]*viper.Viper
+	isDir     bool
+	listeners []func(ChangeEvent)
+
+	// reloadMu 串行化 Reload：修改 remote / overrides、重新加载及失败回滚在同一临界区内完成
+	reloadMu sync.Mutex
+
+	// 远程配置：remote 为最近一次同步（或快照恢复）的配置，覆盖本地同名配置项
+	remote     map[string]map[string]interface{}
+	remoteSync *remoteSync
+
+	// 环境变量模式：不读取配置文件，所有配置项从环境变量解析
+	envOnly   bool
+	envPrefix string
+	defaults  map[string]interface{}
+
+	// 运行时覆盖：优先级最高，到期后自动移除；overridePath 非空时持久化到文件
+	overrides     map[string]Override
+	overrideMu    sync.Mutex // 覆盖项的读改写，持有至 Reload 完成，先于 reloadMu 获取
+	overridePath  string
+	overrideTimer *time.Timer
 }
 
 type Options struct {
 	Path string
+
+	// EnvOnly 仅从环境变量读取配置，不需要配置文件；Path 为空时同样启用
+	EnvOnly bool
+	// EnvPrefix 环境变量前缀，如 MYAPP 时 db.host 对应 MYAPP_DB_HOST
+	EnvPrefix string
+
+	// Remote 远程配置源（配置中心），为空时仅使用本地配置文件
+	Remote RemoteSource
+	// SnapshotPath 本地快照路径，默认为配置目录下的 .config.snapshot
+	SnapshotPath string
+	// SnapshotKey 快照加密密钥，为空时不写入也不读取快照
+	SnapshotKey []byte
+	// FetchTimeout 启动时拉取远程配置的超时时间，默认 5 秒
+	FetchTimeout time.Duration
+	// RetryInterval 降级后重新同步的间隔，默认 30 秒
+	RetryInterval time.Duration
+
+	// OverridePath 运行时覆盖项的持久化文件，为空时覆盖项仅保存在内存中，重启后失效
+	OverridePath string
 }
 
 func ConfigOptions(path string) Options {
@@ -30,14 +71,43 @@ func ConfigOptions(path string) Options {
 // NewConfigProvider 创建配置管理实例
 func NewConfigProvider(opts Options) (*Config, error) {
 	c := &Config{
-		path:    opts.Path,
-		configs: map[string]*viper.Viper{},
+		path:      opts.Path,
+		configs:   map[string]*viper.Viper{},
+		envOnly:   opts.EnvOnly || opts.Path == "",
+		envPrefix: opts.EnvPrefix,
+	}
+	if opts.OverridePath != "" {
+		overrides, err := loadOverrides(opts.OverridePath)
+		if err != nil {
+			return nil, err
+		}
+		c.overrides = overrides
+		c.overridePath = opts.OverridePath
 	}
 
-	return c.init()
+	if _, err := c.init(); err != nil {
+		return nil, err
+	}
+	if opts.Remote != nil {
+		if err := c.bootstrapRemote(opts); err != nil {
+			return nil, err
+		}
+	}
+	c.scheduleOverrideExpiry()
+	return c, nil
 }
 
 func (c *Config) init() (*Config, error) {
+	if c.envOnly {
+		c.isDir = true
+		if err := c.applyRemote(); err != nil {
+			return nil, err
+		}
+		c.applyOverrides()
+		c.applyDefaults()
+		return c, nil
+	}
+
 	info, err := os.Stat(c.path)
 	if err != nil {
 		return nil, err
@@ -59,12 +129,26 @@ func (c *Config) init() (*Config, error) {
 		}
 	}
 
+	if err := c.applyRemote(); err != nil {
+		return nil, err
+	}
+	c.applyOverrides()
+	c.applyDefaults()
+
 	return c, nil
 }
 
 // ConfigModule 配置管理模块
 var ConfigModule = fx.Options(
 	fx.Provide(NewConfigProvider),
+	fx.Invoke(registerRemoteSync),
+	// 降级模式仍可使用快照提供服务，默认作为可选依赖
+	fx.Provide(
+		fx.Annotate(
+			func(c *Config) z.ReadyCheck { return z.ReadyCheck{Name: "config", Checker: c, Optional: true} },
+			fx.ResultTags(`group:"ready_checks"`),
+		),
+	),
 )
 
 // LoadDir 加载指定目录下的所有配置文件
@@ -120,13 +204,6 @@ func (c *Config) LoadFile(dir string, filename string, namespace string) error {
 		registerName = name
 	}
 
-	if c.configs == nil {
-		c.configs = make(map[string]*viper.Viper)
-	}
-	if _, exists := c.configs[registerName]; exists {
-		return errors.New("duplicate namespace config: " + registerName)
-	}
-
 	cfg := viper.New()
 	cfg.SetConfigFile(filepath.Join(dir, filename))
 
@@ -134,6 +211,14 @@ func (c *Config) LoadFile(dir string, filename string, namespace string) error {
 		return errors.New("error on parsing configuration file: " + err.Error())
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.configs == nil {
+		c.configs = make(map[string]*viper.Viper)
+	}
+	if _, exists := c.configs[registerName]; exists {
+		return errors.New("duplicate namespace config: " + registerName)
+	}
 	c.configs[registerName] = cfg
 
 	return nil
@@ -149,6 +234,13 @@ func (c *Config) parseName(name string) (v *viper.Viper, valueName string, err e
 	ns := names[0]
 	key := strings.Join(names[1:], ".")
 
+	if c.envOnly {
+		return c.namespace(ns), key, nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if c.isDir {
 		vv := c.configs[ns]
 		if vv == nil {
@@ -174,6 +266,15 @@ func (c *Config) parseName(name string) (v *viper.Viper, valueName string, err e
 	return only, name, nil
 }
 
+// IsSet 判断配置项是否已设置（配置文件、配置中心、环境变量、默认值或覆盖项），用于区分未配置与显式配置为零值
+func (c *Config) IsSet(name string) bool {
+	vv, vn, err := c.parseName(name)
+	if err != nil {
+		return false
+	}
+	return vv.IsSet(vn)
+}
+
 // String 获取字符串类型的配置项
 func (c *Config) String(name string) (value string, err error) {
 	vv, vn, err := c.parseName(name)
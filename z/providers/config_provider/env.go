@@ -0,0 +1,103 @@
+package config_provider
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvOptions 仅使用环境变量的配置选项，适用于不携带配置文件的容器和 Serverless 部署
+// 配置项 namespace.key 对应环境变量 {PREFIX_}NAMESPACE_KEY：大写，点号替换为下划线，如 db.host -> DB_HOST
+func EnvOptions(prefix string) Options {
+	return Options{EnvOnly: true, EnvPrefix: prefix}
+}
+
+// EnvOnly 是否为环境变量模式
+func (c *Config) EnvOnly() bool {
+	return c.envOnly
+}
+
+// EnvName 返回配置项对应的环境变量名
+func (c *Config) EnvName(name string) string {
+	name = strings.ToUpper(strings.ReplaceAll(name, ".", "_"))
+	if c.envPrefix == "" {
+		return name
+	}
+	return strings.ToUpper(c.envPrefix) + "_" + name
+}
+
+// SetDefault 设置配置项默认值，优先级低于配置文件、配置中心和环境变量，Reload 后仍然生效
+// 环境变量模式下无法从环境变量推导的配置项（如 GetStringMap 读取的嵌套配置）需通过默认值提供
+func (c *Config) SetDefault(name string, value interface{}) error {
+	if len(strings.Split(name, ".")) < 2 {
+		return errors.New("invalid configuration name")
+	}
+
+	c.mu.Lock()
+	// 写时复制，Reload 构建的新实例与当前实例共享默认值
+	defaults := make(map[string]interface{}, len(c.defaults)+1)
+	for k, v := range c.defaults {
+		defaults[k] = v
+	}
+	defaults[name] = value
+	c.defaults = defaults
+	c.applyDefault(name, value)
+	c.mu.Unlock()
+	return nil
+}
+
+// applyDefaults 加载配置后重新应用默认值
+func (c *Config) applyDefaults() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, value := range c.defaults {
+		c.applyDefault(name, value)
+	}
+}
+
+// applyDefault 将默认值写入对应命名空间，目录模式下命名空间不存在时创建；调用方需持有写锁
+func (c *Config) applyDefault(name string, value interface{}) {
+	names := strings.Split(name, ".")
+	ns, key := names[0], strings.Join(names[1:], ".")
+
+	if c.isDir {
+		vv := c.configs[ns]
+		if vv == nil {
+			vv = c.newNamespace(ns)
+			c.configs[ns] = vv
+		}
+		vv.SetDefault(key, value)
+		return
+	}
+	for _, vv := range c.configs {
+		if ns == "app" {
+			vv.SetDefault(key, value)
+		} else {
+			vv.SetDefault(name, value)
+		}
+		return
+	}
+}
+
+// namespace 返回命名空间配置，环境变量模式下不存在时返回仅读取环境变量的临时实例
+func (c *Config) namespace(ns string) *viper.Viper {
+	c.mu.RLock()
+	vv := c.configs[ns]
+	c.mu.RUnlock()
+	if vv != nil {
+		return vv
+	}
+	return c.newNamespace(ns)
+}
+
+// newNamespace 创建命名空间配置，环境变量模式下按 {PREFIX_}NAMESPACE_KEY 读取环境变量
+func (c *Config) newNamespace(ns string) *viper.Viper {
+	vv := viper.New()
+	if c.envOnly {
+		vv.SetEnvPrefix(c.EnvName(ns))
+		vv.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+		vv.AutomaticEnv()
+	}
+	return vv
+}
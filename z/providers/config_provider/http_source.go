@@ -0,0 +1,53 @@
+package config_provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/icreateapp-com/go-zLib/z"
+)
+
+// HttpSource 通过 HTTP 拉取配置的远程配置源
+// 响应体为 namespace -> 配置项的 JSON 对象；配置中心以 {"data": {...}} 包装时同样支持
+//
+//	creds := z.NewOAuth2ClientCredentials(z.OAuth2Options{TokenURL: "...", ClientID: "...", ClientSecret: "..."})
+//	cfg, err := config_provider.NewConfigProvider(config_provider.Options{
+//		Path:   "./config",
+//		Remote: &config_provider.HttpSource{URL: "https://config.internal/v1/apps/order", Credentials: creds},
+//	})
+type HttpSource struct {
+	URL         string            // 配置地址
+	Headers     map[string]string // 附加请求头
+	Credentials z.Credentials     // 凭证，响应 401 时自动刷新并重试一次
+	Client      *http.Client      // 自定义 client，未设置时使用凭证提供的 client
+}
+
+// Fetch 拉取远程配置
+func (s *HttpSource) Fetch(ctx context.Context) (map[string]map[string]interface{}, error) {
+	resp, err := z.AuthorizedRequest(s.Credentials, z.RequestOptions{
+		URL:         s.URL,
+		Method:      http.MethodGet,
+		Headers:     s.Headers,
+		ContentType: z.RequestContentTypeRaw,
+		Data:        "",
+		Client:      s.Client,
+		Context:     ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapped struct {
+		Data map[string]map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &wrapped); err == nil && wrapped.Data != nil {
+		return wrapped.Data, nil
+	}
+	var settings map[string]map[string]interface{}
+	if err := json.Unmarshal(resp.Body, &settings); err != nil {
+		return nil, fmt.Errorf("decode remote config: %w", err)
+	}
+	return settings, nil
+}
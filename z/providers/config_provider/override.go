@@ -0,0 +1,266 @@
+package config_provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// overrideFileMode 覆盖项文件权限，仅当前用户可读写
+const overrideFileMode = 0o600
+
+// Override 运行时覆盖的配置项，优先级高于配置文件、配置中心和环境变量，到期后自动恢复原值
+type Override struct {
+	Key       string      `json:"key"`
+	Value     interface{} `json:"value"`
+	CreatedAt time.Time   `json:"created_at"`
+	ExpiresAt time.Time   `json:"expires_at"` // 零值表示不过期
+}
+
+// expired 判断覆盖项在 now 时是否已过期
+func (o Override) expired(now time.Time) bool {
+	return !o.ExpiresAt.IsZero() && !now.Before(o.ExpiresAt)
+}
+
+// SetOverride 运行时覆盖配置项，ttl 大于 0 时到期自动恢复，变更会通知 OnChange 监听者
+// 用于排查线上问题时临时调整日志级别、调试开关等，无需重启；配置了 Options.OverridePath 时重启后仍然生效
+//
+//	cfg.SetOverride("logger.level", "debug", 30*time.Minute)
+func (c *Config) SetOverride(name string, value interface{}, ttl time.Duration) (ChangeEvent, error) {
+	if len(strings.Split(name, ".")) < 2 {
+		return ChangeEvent{}, errors.New("invalid configuration name")
+	}
+	if err := checkOverride(name, value); err != nil {
+		return ChangeEvent{}, err
+	}
+	now := time.Now()
+	item := Override{Key: name, Value: value, CreatedAt: now}
+	if ttl > 0 {
+		item.ExpiresAt = now.Add(ttl)
+	}
+	return c.updateOverrides(func(overrides map[string]Override) bool {
+		overrides[name] = item
+		return true
+	})
+}
+
+// ClearOverride 移除配置项的覆盖，恢复为配置文件或配置中心的值
+func (c *Config) ClearOverride(name string) (ChangeEvent, error) {
+	return c.updateOverrides(func(overrides map[string]Override) bool {
+		if _, ok := overrides[name]; !ok {
+			return false
+		}
+		delete(overrides, name)
+		return true
+	})
+}
+
+// ClearOverrides 移除全部覆盖项
+func (c *Config) ClearOverrides() (ChangeEvent, error) {
+	return c.updateOverrides(func(overrides map[string]Override) bool {
+		for k := range overrides {
+			delete(overrides, k)
+		}
+		return true
+	})
+}
+
+// Overrides 返回当前生效的覆盖项，按键名排序
+func (c *Config) Overrides() []Override {
+	now := time.Now()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	list := make([]Override, 0, len(c.overrides))
+	for _, item := range c.overrides {
+		if !item.expired(now) {
+			list = append(list, item)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// updateOverrides 在 overrideMu 内复制覆盖项交给 fn 修改，fn 返回 true 时替换并重新加载，失败时回滚；
+// 成功后写入覆盖项文件并安排下一次过期。OnChange 监听者中不可修改覆盖项
+func (c *Config) updateOverrides(fn func(overrides map[string]Override) bool) (ChangeEvent, error) {
+	c.overrideMu.Lock()
+	defer c.overrideMu.Unlock()
+
+	c.mu.RLock()
+	previous := c.overrides
+	overrides := make(map[string]Override, len(previous)+1)
+	for k, v := range previous {
+		overrides[k] = v
+	}
+	c.mu.RUnlock()
+	if !fn(overrides) {
+		c.scheduleOverrideExpiry()
+		return ChangeEvent{}, nil
+	}
+
+	c.reloadMu.Lock()
+	c.mu.Lock()
+	c.overrides = overrides
+	c.mu.Unlock()
+
+	event, err := c.reload()
+	if err != nil {
+		c.mu.Lock()
+		c.overrides = previous
+		c.mu.Unlock()
+	}
+	c.reloadMu.Unlock()
+	if err != nil {
+		return event, err
+	}
+	c.scheduleOverrideExpiry()
+	if c.overridePath != "" {
+		if err := writeOverrides(c.overridePath, c.Overrides()); err != nil {
+			return event, err
+		}
+	}
+	return event, nil
+}
+
+// expireOverrides 移除已过期的覆盖项
+func (c *Config) expireOverrides() {
+	now := time.Now()
+	_, _ = c.updateOverrides(func(overrides map[string]Override) bool {
+		changed := false
+		for k, v := range overrides {
+			if v.expired(now) {
+				delete(overrides, k)
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// scheduleOverrideExpiry 按最早的过期时间重设定时器
+func (c *Config) scheduleOverrideExpiry() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.overrideTimer != nil {
+		c.overrideTimer.Stop()
+		c.overrideTimer = nil
+	}
+	var next time.Time
+	for _, item := range c.overrides {
+		if item.ExpiresAt.IsZero() {
+			continue
+		}
+		if next.IsZero() || item.ExpiresAt.Before(next) {
+			next = item.ExpiresAt
+		}
+	}
+	if next.IsZero() {
+		return
+	}
+	c.overrideTimer = time.AfterFunc(time.Until(next), c.expireOverrides)
+}
+
+// checkOverride 按已注册的配置结构校验覆盖值的类型，未定义的配置项不校验
+func checkOverride(name string, value interface{}) error {
+	names := strings.Split(name, ".")
+	schemaMu.RLock()
+	s, ok := schemas[names[0]]
+	schemaMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	f, ok := s.Field(strings.Join(names[1:], "."))
+	if !ok {
+		return nil
+	}
+	if err := checkSchemaType(f.Type, value); err != nil {
+		return fmt.Errorf("invalid override %s: %w", name, err)
+	}
+	return nil
+}
+
+// applyOverrides 将未过期的覆盖项写入对应命名空间，优先级最高
+func (c *Config) applyOverrides() {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, item := range c.overrides {
+		if item.expired(now) {
+			continue
+		}
+		names := strings.Split(name, ".")
+		ns, key := names[0], strings.Join(names[1:], ".")
+
+		if c.isDir {
+			vv := c.configs[ns]
+			if vv == nil {
+				vv = c.newNamespace(ns)
+				c.configs[ns] = vv
+			}
+			vv.Set(key, item.Value)
+			continue
+		}
+		for _, vv := range c.configs {
+			if ns == "app" {
+				vv.Set(key, item.Value)
+			} else {
+				vv.Set(name, item.Value)
+			}
+			break
+		}
+	}
+}
+
+// loadOverrides 启动时读取覆盖项文件，忽略已过期的项；文件不存在时返回空
+func loadOverrides(path string) (map[string]Override, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Override{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Override
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, errors.New("invalid config override file: " + err.Error())
+	}
+	now := time.Now()
+	overrides := make(map[string]Override, len(list))
+	for _, item := range list {
+		if item.Key != "" && !item.expired(now) {
+			overrides[item.Key] = item
+		}
+	}
+	return overrides, nil
+}
+
+// writeOverrides 写入覆盖项文件，先写临时文件再重命名
+func writeOverrides(path string, list []Override) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	return os.Chmod(path, overrideFileMode)
+}
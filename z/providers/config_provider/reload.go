@@ -0,0 +1,180 @@
+package config_provider
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EventConfigChanged 配置变更事件名称
+const EventConfigChanged = "config.changed"
+
+// Change 单个配置项的变更，敏感配置项的值已脱敏
+type Change struct {
+	Key string      `json:"key"`
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ChangeEvent 配置变更事件载荷
+type ChangeEvent struct {
+	Keys    []string `json:"keys"`
+	Changes []Change `json:"changes"`
+}
+
+// Has 判断指定配置项或其子项是否发生变更，如 Has("logger") 匹配 logger.level
+// 单文件模式下 app. 前缀与读取配置时一致，可省略
+func (e ChangeEvent) Has(prefix string) bool {
+	for _, key := range e.Keys {
+		if matchKeyPrefix(key, prefix) || matchKeyPrefix("app."+key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchKeyPrefix(key, prefix string) bool {
+	return key == prefix || strings.HasPrefix(key, prefix+".")
+}
+
+// OnChange 注册配置变更监听，Reload 检测到变更后同步回调
+func (c *Config) OnChange(fn func(ChangeEvent)) {
+	if fn == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// Reload 重新读取配置文件，返回变更内容并通知监听者；读取失败时保留原配置
+// 与远程配置同步、覆盖项变更串行执行，OnChange 监听者中不可调用 Reload
+func (c *Config) Reload() (ChangeEvent, error) {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	return c.reload()
+}
+
+// reload 基于当前的远程配置与覆盖项重新加载，调用方需持有 reloadMu，
+// 保证快照、加载与替换之间 remote / overrides 不被并发修改，较早的快照不会覆盖较新的结果
+func (c *Config) reload() (ChangeEvent, error) {
+	c.mu.RLock()
+	remote := c.remote
+	defaults := c.defaults
+	overrides := c.overrides
+	c.mu.RUnlock()
+
+	fresh := &Config{
+		path:      c.path,
+		configs:   map[string]*viper.Viper{},
+		remote:    remote,
+		envOnly:   c.envOnly,
+		envPrefix: c.envPrefix,
+		defaults:  defaults,
+		overrides: overrides,
+	}
+	if _, err := fresh.init(); err != nil {
+		return ChangeEvent{}, err
+	}
+
+	before := c.flatSettings()
+	after := fresh.flatSettings()
+
+	c.mu.Lock()
+	c.configs = fresh.configs
+	c.isDir = fresh.isDir
+	listeners := append([]func(ChangeEvent){}, c.listeners...)
+	c.mu.Unlock()
+
+	event := DiffSettings(before, after)
+	if len(event.Keys) == 0 {
+		return event, nil
+	}
+	for _, fn := range listeners {
+		fn(event)
+	}
+	return event, nil
+}
+
+// DiffSettings 比较两份扁平化配置，键名按字典序排列
+func DiffSettings(before, after map[string]interface{}) ChangeEvent {
+	keys := make([]string, 0)
+	for key, oldValue := range before {
+		if newValue, ok := after[key]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	event := ChangeEvent{Keys: keys, Changes: make([]Change, 0, len(keys))}
+	for _, key := range keys {
+		change := Change{Key: key, Old: before[key], New: after[key]}
+		if IsSensitiveKey(key) {
+			if change.Old != nil {
+				change.Old = RedactedValue
+			}
+			if change.New != nil {
+				change.New = RedactedValue
+			}
+		}
+		event.Changes = append(event.Changes, change)
+	}
+	return event
+}
+
+// flatSettings 展开为与读取配置时相同的键名：目录模式为 namespace.key，单文件模式为 key
+func (c *Config) flatSettings() map[string]interface{} {
+	c.mu.RLock()
+	isDir := c.isDir
+	c.mu.RUnlock()
+
+	settings := c.AllSettings()
+	if isDir {
+		return flattenSettings(settings)
+	}
+	out := map[string]interface{}{}
+	for _, value := range settings {
+		if m, ok := value.(map[string]interface{}); ok {
+			for k, v := range flattenSettings(m) {
+				out[k] = v
+			}
+		}
+	}
+	return out
+}
+
+// flattenSettings 将嵌套配置展开为 key.sub 形式
+func flattenSettings(settings map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if len(v) == 0 {
+				out[prefix] = v
+				return
+			}
+			for k, item := range v {
+				walk(prefix+"."+k, item)
+			}
+		case map[interface{}]interface{}:
+			for k, item := range v {
+				walk(prefix+"."+fmt.Sprint(k), item)
+			}
+		default:
+			out[prefix] = v
+		}
+	}
+	for key, value := range settings {
+		walk(key, value)
+	}
+	return out
+}
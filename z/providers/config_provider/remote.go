@@ -0,0 +1,357 @@
+package config_provider
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/scheduler"
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+)
+
+// RemoteSource 远程配置源，返回 namespace -> 配置项，覆盖本地同名配置
+// 单文件模式下 app 命名空间合并到根，其它命名空间作为同名一级配置项合并
+type RemoteSource interface {
+	Fetch(ctx context.Context) (map[string]map[string]interface{}, error)
+}
+
+// RemoteSourceFunc 函数形式的远程配置源
+type RemoteSourceFunc func(ctx context.Context) (map[string]map[string]interface{}, error)
+
+func (f RemoteSourceFunc) Fetch(ctx context.Context) (map[string]map[string]interface{}, error) {
+	return f(ctx)
+}
+
+var (
+	// ErrSnapshotInvalid 快照被篡改、密钥错误或格式不正确
+	ErrSnapshotInvalid = errors.New("invalid config snapshot")
+	// ErrRemoteNotConfigured 未配置远程配置源
+	ErrRemoteNotConfigured = errors.New("remote config source not configured")
+	// ErrRemoteDegraded 远程配置不可用，正在使用本地快照运行
+	ErrRemoteDegraded = errors.New("remote config degraded, running on snapshot")
+)
+
+const (
+	snapshotMagic        = "ZCS1"
+	defaultSnapshotName  = ".config.snapshot"
+	defaultFetchTimeout  = 5 * time.Second
+	defaultRetryInterval = 30 * time.Second
+	snapshotFileMode     = 0o600
+)
+
+// snapshot 本地快照内容
+type snapshot struct {
+	SavedAt  time.Time                         `json:"saved_at"`
+	Settings map[string]map[string]interface{} `json:"settings"`
+}
+
+// remoteSync 远程配置同步状态
+type remoteSync struct {
+	source        RemoteSource
+	snapshotPath  string
+	snapshotKey   []byte
+	fetchTimeout  time.Duration
+	retryInterval time.Duration
+
+	mu       sync.RWMutex
+	degraded bool
+	lastSync time.Time
+	retry    *scheduler.Scheduler
+}
+
+// bootstrapRemote 启动时同步远程配置；配置中心不可达时回退到本地快照并进入降级模式
+func (c *Config) bootstrapRemote(opts Options) error {
+	rs := &remoteSync{
+		source:        opts.Remote,
+		snapshotPath:  opts.SnapshotPath,
+		snapshotKey:   opts.SnapshotKey,
+		fetchTimeout:  opts.FetchTimeout,
+		retryInterval: opts.RetryInterval,
+		retry:         scheduler.New(),
+	}
+	if rs.snapshotPath == "" {
+		dir := c.path
+		if !c.isDir {
+			dir = filepath.Dir(c.path)
+		}
+		rs.snapshotPath = filepath.Join(dir, defaultSnapshotName)
+	}
+	if rs.fetchTimeout <= 0 {
+		rs.fetchTimeout = defaultFetchTimeout
+	}
+	if rs.retryInterval <= 0 {
+		rs.retryInterval = defaultRetryInterval
+	}
+	c.remoteSync = rs
+
+	err := c.Sync(context.Background())
+	if err == nil {
+		return nil
+	}
+	if len(rs.snapshotKey) == 0 {
+		return fmt.Errorf("fetch remote config: %w", err)
+	}
+
+	snap, snapErr := readSnapshot(rs.snapshotPath, rs.snapshotKey)
+	if snapErr != nil {
+		return fmt.Errorf("fetch remote config: %v; load snapshot: %w", err, snapErr)
+	}
+	if _, applyErr := c.setRemote(snap.Settings); applyErr != nil {
+		return fmt.Errorf("apply config snapshot: %w", applyErr)
+	}
+
+	rs.mu.Lock()
+	rs.degraded = true
+	rs.lastSync = snap.SavedAt
+	rs.mu.Unlock()
+
+	fmt.Fprintf(os.Stderr, "config warning: remote config unavailable (%v), using snapshot saved at %s\n",
+		err, snap.SavedAt.Format(time.RFC3339))
+	return nil
+}
+
+// Sync 拉取远程配置并应用，成功后更新本地快照并退出降级模式
+func (c *Config) Sync(ctx context.Context) error {
+	rs := c.remoteSync
+	if rs == nil {
+		return ErrRemoteNotConfigured
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, rs.fetchTimeout)
+	defer cancel()
+	settings, err := rs.source.Fetch(fetchCtx)
+	if err != nil {
+		return err
+	}
+	if _, err := c.setRemote(settings); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rs.mu.Lock()
+	rs.degraded = false
+	rs.lastSync = now
+	rs.mu.Unlock()
+
+	if len(rs.snapshotKey) > 0 {
+		if err := writeSnapshot(rs.snapshotPath, rs.snapshotKey, snapshot{SavedAt: now, Settings: settings}); err != nil {
+			fmt.Fprintf(os.Stderr, "config warning: save snapshot failed: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// Degraded 是否处于降级模式（使用本地快照运行）
+func (c *Config) Degraded() bool {
+	rs := c.remoteSync
+	if rs == nil {
+		return false
+	}
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return rs.degraded
+}
+
+// Ready 配置中心就绪检查，降级模式下返回 ErrRemoteDegraded；未配置远程配置源时始终就绪
+func (c *Config) Ready() error {
+	if c.Degraded() {
+		return ErrRemoteDegraded
+	}
+	return nil
+}
+
+// LastSync 最近一次同步远程配置的时间，降级模式下为快照保存时间
+func (c *Config) LastSync() time.Time {
+	rs := c.remoteSync
+	if rs == nil {
+		return time.Time{}
+	}
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return rs.lastSync
+}
+
+// setRemote 替换远程配置并重新加载，变更会通知 OnChange 监听者
+func (c *Config) setRemote(settings map[string]map[string]interface{}) (ChangeEvent, error) {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	c.mu.Lock()
+	previous := c.remote
+	c.remote = settings
+	c.mu.Unlock()
+
+	event, err := c.reload()
+	if err != nil {
+		c.mu.Lock()
+		c.remote = previous
+		c.mu.Unlock()
+	}
+	return event, err
+}
+
+// applyRemote 将远程配置合并到已加载的本地配置
+func (c *Config) applyRemote() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for ns, settings := range c.remote {
+		if c.isDir {
+			vv := c.configs[ns]
+			if vv == nil {
+				vv = c.newNamespace(ns)
+				c.configs[ns] = vv
+			}
+			if err := vv.MergeConfigMap(settings); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var only *viper.Viper
+		for _, vv := range c.configs {
+			only = vv
+			break
+		}
+		if only == nil {
+			return errors.New("invalid config state")
+		}
+		value := settings
+		if ns != "app" {
+			value = map[string]interface{}{ns: settings}
+		}
+		if err := only.MergeConfigMap(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remoteRetryTask 降级模式下的重试任务名
+const remoteRetryTask = "config.remote_retry"
+
+// retrySync 降级模式下重试同步，恢复后移除重试任务
+func (c *Config) retrySync(ctx context.Context) error {
+	if c.Degraded() {
+		if err := c.Sync(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "config: remote config recovered, left degraded mode")
+	}
+	c.remoteSync.retry.Remove(remoteRetryTask)
+	return nil
+}
+
+// registerRemoteSync 应用启动后在降级模式下按间隔重试同步
+func registerRemoteSync(lc fx.Lifecycle, c *Config) {
+	rs := c.remoteSync
+	if rs == nil {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if !c.Degraded() {
+				return nil
+			}
+			if err := rs.retry.Every(remoteRetryTask, rs.retryInterval, c.retrySync); err != nil {
+				return err
+			}
+			rs.retry.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return rs.retry.Stop(ctx)
+		},
+	})
+}
+
+// snapshotCipher 由密钥派生 AES-256-GCM，GCM 同时提供加密和完整性校验
+func snapshotCipher(key []byte) (cipher.AEAD, error) {
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeSnapshot 加密写入快照，先写临时文件再重命名，避免中途失败留下损坏文件
+func writeSnapshot(path string, key []byte, snap snapshot) error {
+	plain, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	aead, err := snapshotCipher(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	data := make([]byte, 0, len(snapshotMagic)+len(nonce)+len(plain)+aead.Overhead())
+	data = append(data, snapshotMagic...)
+	data = append(data, nonce...)
+	data = aead.Seal(data, nonce, plain, []byte(snapshotMagic))
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	return os.Chmod(path, snapshotFileMode)
+}
+
+// readSnapshot 读取并校验快照
+func readSnapshot(path string, key []byte) (*snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := snapshotCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	head := len(snapshotMagic) + aead.NonceSize()
+	if len(data) < head+aead.Overhead() || string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, ErrSnapshotInvalid
+	}
+	plain, err := aead.Open(nil, data[len(snapshotMagic):head], data[head:], []byte(snapshotMagic))
+	if err != nil {
+		return nil, ErrSnapshotInvalid
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(plain, &snap); err != nil {
+		return nil, ErrSnapshotInvalid
+	}
+	return &snap, nil
+}
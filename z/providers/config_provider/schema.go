@@ -0,0 +1,550 @@
+package config_provider
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// 配置项类型
+const (
+	SchemaString      = "string"
+	SchemaInt         = "int"
+	SchemaFloat       = "float"
+	SchemaBool        = "bool"
+	SchemaDuration    = "duration"
+	SchemaStringSlice = "[]string"
+	SchemaList        = "list"
+	SchemaMap         = "map"
+)
+
+// SchemaField 配置项定义
+type SchemaField struct {
+	Key         string `json:"key"` // 命名空间内的配置项名，如 mysql.host
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Schema 命名空间的配置结构，由结构体及其标签生成：
+//
+//	type redisConfig struct {
+//		Host     string `config:"host" default:"127.0.0.1" desc:"主机地址"`
+//		Port     int    `default:"6379" desc:"端口"`
+//		Password string `desc:"密码"`
+//		Sentinel struct {
+//			Addrs []string `desc:"哨兵地址"`
+//		} `desc:"哨兵模式"`
+//	}
+//
+// config 为配置项名（默认为字段名的 snake_case，- 表示忽略），default 为默认值（[]string 以逗号分隔），
+// required:"true" 表示必填，desc 为说明；嵌套结构体为子配置段，map 及非字符串切片为自由结构（如按名称配置的 guards、规则列表）
+type Schema struct {
+	Namespace   string        `json:"namespace"`
+	Description string        `json:"description,omitempty"`
+	Fields      []SchemaField `json:"fields"`
+
+	sections map[string]string // 子配置段说明
+}
+
+var (
+	schemaMu sync.RWMutex
+	schemas  = map[string]Schema{}
+)
+
+// RegisterSchema 注册命名空间的配置结构，通常在提供者包初始化时调用；同一命名空间多次注册时合并配置项
+// v 必须为结构体，否则 panic
+func RegisterSchema(namespace, description string, v interface{}) Schema {
+	s, err := NewSchema(namespace, description, v)
+	if err != nil {
+		panic(err)
+	}
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	if old, ok := schemas[namespace]; ok {
+		s = old.merge(s)
+	}
+	schemas[namespace] = s
+	return s
+}
+
+// Schemas 返回已注册的配置结构，app 在前，其余按命名空间排序
+func Schemas() []Schema {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+	list := make([]Schema, 0, len(schemas))
+	for _, s := range schemas {
+		list = append(list, s)
+	}
+	sortSchemas(list)
+	return list
+}
+
+// NewSchema 由结构体生成配置结构
+func NewSchema(namespace, description string, v interface{}) (Schema, error) {
+	namespace = strings.TrimSpace(namespace)
+	if namespace == "" || strings.Contains(namespace, ".") {
+		return Schema{}, fmt.Errorf("config schema: invalid namespace: %q", namespace)
+	}
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return Schema{}, fmt.Errorf("config schema %s: expected struct, got %T", namespace, v)
+	}
+	s := Schema{Namespace: namespace, Description: description, sections: map[string]string{}}
+	if err := s.collect(t, ""); err != nil {
+		return Schema{}, fmt.Errorf("config schema %s: %w", namespace, err)
+	}
+	return s, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (s *Schema) collect(t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Tag.Get("config")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(f.Name)
+		}
+		key := prefix + name
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			if desc := f.Tag.Get("desc"); desc != "" {
+				s.sections[key] = desc
+			}
+			if err := s.collect(ft, key+"."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		typ, err := schemaType(ft)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		field := SchemaField{
+			Key:         key,
+			Type:        typ,
+			Default:     f.Tag.Get("default"),
+			Required:    f.Tag.Get("required") == "true",
+			Description: f.Tag.Get("desc"),
+		}
+		if field.Default != "" {
+			if _, err := parseSchemaValue(field.Type, field.Default); err != nil {
+				return fmt.Errorf("%s: invalid default %q: %w", key, field.Default, err)
+			}
+		}
+		s.Fields = append(s.Fields, field)
+	}
+	return nil
+}
+
+func schemaType(t reflect.Type) (string, error) {
+	if t == durationType {
+		return SchemaDuration, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return SchemaString, nil
+	case reflect.Bool:
+		return SchemaBool, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return SchemaInt, nil
+	case reflect.Float32, reflect.Float64:
+		return SchemaFloat, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return SchemaStringSlice, nil
+		}
+		return SchemaList, nil
+	case reflect.Map:
+		return SchemaMap, nil
+	}
+	return "", fmt.Errorf("unsupported type: %s", t)
+}
+
+// merge 合并另一次注册的配置项，已存在的配置项保持不变
+func (s Schema) merge(other Schema) Schema {
+	known := make(map[string]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		known[f.Key] = true
+	}
+	fields := append([]SchemaField{}, s.Fields...)
+	for _, f := range other.Fields {
+		if !known[f.Key] {
+			fields = append(fields, f)
+		}
+	}
+	sections := make(map[string]string, len(s.sections)+len(other.sections))
+	for k, v := range other.sections {
+		sections[k] = v
+	}
+	for k, v := range s.sections {
+		sections[k] = v
+	}
+	if s.Description == "" {
+		s.Description = other.Description
+	}
+	s.Fields = fields
+	s.sections = sections
+	return s
+}
+
+// Field 按配置项名查找，自由结构配置项的子项返回该配置项
+func (s Schema) Field(key string) (SchemaField, bool) {
+	for _, f := range s.Fields {
+		free := f.Type == SchemaMap || f.Type == SchemaList
+		if f.Key == key || (free && strings.HasPrefix(key, f.Key+".")) {
+			return f, true
+		}
+	}
+	return SchemaField{}, false
+}
+
+// WriteExample 生成带注释的单文件配置示例（如 config.example.yml）：app 配置项位于顶层，其余命名空间为同名配置段
+// 目录模式下每个命名空间为独立文件，可使用 WriteNamespaceExample 分别生成
+// 未传 schemas 时使用已注册的配置结构
+func WriteExample(w io.Writer, list ...Schema) error {
+	if len(list) == 0 {
+		list = Schemas()
+	}
+	list = append([]Schema{}, list...)
+	sortSchemas(list)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# 配置示例，由 config_provider.WriteExample 生成")
+	fmt.Fprintln(bw, "# 注释格式：说明 (类型[, 必填])，值为默认值")
+	for _, s := range list {
+		fmt.Fprintln(bw)
+		indent := 0
+		if s.Namespace != "app" {
+			writeComment(bw, 0, s.Namespace+": "+s.Description)
+			fmt.Fprintf(bw, "%s:\n", s.Namespace)
+			indent = 1
+		} else {
+			writeComment(bw, 0, "app: "+s.Description)
+		}
+		writeSchemaFields(bw, s, indent)
+	}
+	return bw.Flush()
+}
+
+// WriteNamespaceExample 生成单个命名空间的配置文件示例（如 redis.yml）
+func WriteNamespaceExample(w io.Writer, s Schema) error {
+	bw := bufio.NewWriter(w)
+	writeComment(bw, 0, s.Namespace+".yml: "+s.Description)
+	writeSchemaFields(bw, s, 0)
+	return bw.Flush()
+}
+
+func writeSchemaFields(w io.Writer, s Schema, indent int) {
+	var parents []string
+	for _, f := range s.Fields {
+		parts := strings.Split(f.Key, ".")
+		path := parts[:len(parts)-1]
+		common := 0
+		for common < len(parents) && common < len(path) && parents[common] == path[common] {
+			common++
+		}
+		for i := common; i < len(path); i++ {
+			section := strings.Join(path[:i+1], ".")
+			writeComment(w, indent+i, s.sections[section])
+			fmt.Fprintf(w, "%s%s:\n", strings.Repeat("  ", indent+i), path[i])
+		}
+		parents = path
+
+		depth := indent + len(path)
+		comment := "(" + f.Type
+		if f.Required {
+			comment += ", 必填"
+		}
+		comment += ")"
+		if f.Description != "" {
+			comment = f.Description + " " + comment
+		}
+		writeComment(w, depth, comment)
+		fmt.Fprintf(w, "%s%s: %s\n", strings.Repeat("  ", depth), parts[len(parts)-1], exampleValue(f))
+	}
+}
+
+func writeComment(w io.Writer, indent int, comment string) {
+	comment = strings.TrimRight(strings.TrimSpace(comment), ":")
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		fmt.Fprintf(w, "%s# %s\n", strings.Repeat("  ", indent), strings.TrimSpace(line))
+	}
+}
+
+// exampleValue 默认值的 YAML 表示，无默认值时为类型零值
+func exampleValue(f SchemaField) string {
+	value, err := parseSchemaValue(f.Type, f.Default)
+	if err != nil || (f.Default == "" && f.Type == SchemaString) {
+		return `""`
+	}
+	if f.Type == SchemaDuration {
+		if f.Default == "" {
+			return "0s"
+		}
+		return f.Default
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return `""`
+	}
+	if node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode {
+		node.Style = yaml.FlowStyle
+	}
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return `""`
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// parseSchemaValue 将标签中的默认值解析为对应类型
+func parseSchemaValue(typ, raw string) (interface{}, error) {
+	switch typ {
+	case SchemaString:
+		return raw, nil
+	case SchemaInt:
+		if raw == "" {
+			return 0, nil
+		}
+		return strconv.ParseInt(raw, 10, 64)
+	case SchemaFloat:
+		if raw == "" {
+			return 0.0, nil
+		}
+		return strconv.ParseFloat(raw, 64)
+	case SchemaBool:
+		if raw == "" {
+			return false, nil
+		}
+		return strconv.ParseBool(raw)
+	case SchemaDuration:
+		if raw == "" {
+			return time.Duration(0), nil
+		}
+		return time.ParseDuration(raw)
+	case SchemaStringSlice:
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	case SchemaList:
+		return []interface{}{}, nil
+	case SchemaMap:
+		return map[string]interface{}{}, nil
+	}
+	return nil, fmt.Errorf("unknown type: %s", typ)
+}
+
+// SchemaIssue 配置校验问题
+type SchemaIssue struct {
+	Key     string `json:"key"` // 完整配置项名，如 redis.port
+	Message string `json:"message"`
+}
+
+// SchemaError 配置校验失败，包含全部问题
+type SchemaError struct {
+	Issues []SchemaIssue `json:"issues"`
+}
+
+func (e *SchemaError) Error() string {
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		parts[i] = issue.Key + ": " + issue.Message
+	}
+	return fmt.Sprintf("config: %d schema issue(s): %s", len(e.Issues), strings.Join(parts, "; "))
+}
+
+// ValidateFile 加载配置文件或目录并按配置结构校验，未传 schemas 时使用已注册的配置结构
+func ValidateFile(path string, list ...Schema) error {
+	c, err := NewConfigProvider(ConfigOptions(path))
+	if err != nil {
+		return err
+	}
+	return c.ValidateSchema(list...)
+}
+
+// ValidateSchema 按配置结构校验已加载的配置：必填项缺失、类型不符及未定义的配置项（常见于拼写错误或过期配置）
+// 仅校验有配置结构的命名空间，未配置任何配置项的命名空间（未使用的提供者）不检查必填项；
+// 环境变量模式下不检查未定义的配置项。返回 *SchemaError
+func (c *Config) ValidateSchema(list ...Schema) error {
+	if len(list) == 0 {
+		list = Schemas()
+	}
+	var issues []SchemaIssue
+	for _, s := range list {
+		var nsIssues []SchemaIssue
+		present := s.Namespace == "app"
+		for _, f := range s.Fields {
+			name := s.Namespace + "." + f.Key
+			vv, key, err := c.parseName(name)
+			var value interface{}
+			set := false
+			if err == nil {
+				set = vv.IsSet(key)
+				value = vv.Get(key)
+			}
+			if set {
+				present = true
+			}
+			if !set || value == nil || value == "" {
+				if f.Required {
+					nsIssues = append(nsIssues, SchemaIssue{Key: name, Message: "is required"})
+				}
+				continue
+			}
+			if err := checkSchemaType(f.Type, value); err != nil {
+				nsIssues = append(nsIssues, SchemaIssue{Key: name, Message: err.Error()})
+			}
+		}
+		if present {
+			issues = append(issues, nsIssues...)
+		}
+	}
+	if !c.envOnly {
+		issues = append(issues, c.unknownKeys(list)...)
+	}
+	if len(issues) > 0 {
+		return &SchemaError{Issues: issues}
+	}
+	return nil
+}
+
+func checkSchemaType(typ string, value interface{}) error {
+	var err error
+	switch typ {
+	case SchemaString:
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			err = fmt.Errorf("not a scalar")
+		}
+	case SchemaInt:
+		_, err = cast.ToInt64E(value)
+	case SchemaFloat:
+		_, err = cast.ToFloat64E(value)
+	case SchemaBool:
+		_, err = cast.ToBoolE(value)
+	case SchemaDuration:
+		_, err = cast.ToDurationE(value)
+	case SchemaStringSlice:
+		_, err = cast.ToStringSliceE(value)
+	case SchemaList:
+		_, err = cast.ToSliceE(value)
+	case SchemaMap:
+		_, err = cast.ToStringMapE(value)
+	}
+	if err != nil {
+		return fmt.Errorf("expected %s, got %v", typ, value)
+	}
+	return nil
+}
+
+// unknownKeys 查找配置文件中未定义的配置项
+func (c *Config) unknownKeys(list []Schema) []SchemaIssue {
+	byNS := make(map[string]Schema, len(list))
+	for _, s := range list {
+		byNS[s.Namespace] = s
+	}
+
+	keys := map[string][]string{}
+	c.mu.RLock()
+	if c.isDir {
+		for ns, vv := range c.configs {
+			if _, ok := byNS[ns]; ok {
+				keys[ns] = vv.AllKeys()
+			}
+		}
+	} else {
+		var only *viper.Viper
+		for _, vv := range c.configs {
+			only = vv
+		}
+		if only != nil {
+			for _, key := range only.AllKeys() {
+				first, rest, nested := strings.Cut(key, ".")
+				if _, ok := byNS[first]; ok && nested && first != "app" {
+					keys[first] = append(keys[first], rest)
+				} else if !nested {
+					// 顶层的配置段属于未注册配置结构的命名空间，不视为 app 配置项
+					keys["app"] = append(keys["app"], key)
+				}
+			}
+		}
+	}
+	c.mu.RUnlock()
+
+	var issues []SchemaIssue
+	for ns, nsKeys := range keys {
+		s, ok := byNS[ns]
+		if !ok {
+			continue
+		}
+		sort.Strings(nsKeys)
+		for _, key := range nsKeys {
+			if _, ok := s.Field(key); !ok {
+				issues = append(issues, SchemaIssue{Key: ns + "." + key, Message: "is not defined"})
+			}
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
+
+func sortSchemas(list []Schema) {
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Namespace == "app") != (list[j].Namespace == "app") {
+			return list[i].Namespace == "app"
+		}
+		return list[i].Namespace < list[j].Namespace
+	})
+}
+
+// snakeCase 字段名转 snake_case，如 MaxOpenConns -> max_open_conns、TTL -> ttl
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
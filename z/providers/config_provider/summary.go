@@ -0,0 +1,146 @@
+package config_provider
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RedactedValue 脱敏后的占位值
+const RedactedValue = "******"
+
+// sensitiveKeys 需要脱敏的配置项关键字
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "private_key", "access_key", "api_key", "credential", "dsn"}
+
+var startedAt = time.Now()
+
+// Summary 配置摘要，用于启动信息输出和运维工具查询
+type Summary struct {
+	Name       string                 `json:"name"`
+	Debug      bool                   `json:"debug"`
+	Path       string                 `json:"path"`
+	Namespaces []string               `json:"namespaces"`
+	Addresses  map[string]string      `json:"addresses"`
+	Runtime    map[string]interface{} `json:"runtime"`
+	Settings   map[string]interface{} `json:"settings"`
+}
+
+// Namespaces 返回已加载的配置命名空间
+func (c *Config) Namespaces() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.configs))
+	for name := range c.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AllSettings 返回所有生效的配置项，按命名空间分组
+func (c *Config) AllSettings() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	settings := make(map[string]interface{}, len(c.configs))
+	for name, vv := range c.configs {
+		settings[name] = vv.AllSettings()
+	}
+	return settings
+}
+
+// RedactedSettings 返回脱敏后的配置项
+func (c *Config) RedactedSettings() map[string]interface{} {
+	return Redact(c.AllSettings())
+}
+
+// Summary 生成配置摘要，敏感配置项已脱敏
+func (c *Config) Summary() Summary {
+	hostname, _ := os.Hostname()
+
+	addresses := map[string]string{}
+	if port := c.GetInt("http.port"); port > 0 {
+		addresses["http"] = fmt.Sprintf("%s:%d", c.GetString("http.host"), port)
+	}
+	if path := c.GetString("websocket.path"); path != "" {
+		addresses["websocket"] = path
+	}
+
+	return Summary{
+		Name:       c.GetString("app.name"),
+		Debug:      c.GetBool("app.debug", true),
+		Path:       c.path,
+		Namespaces: c.Namespaces(),
+		Addresses:  addresses,
+		Runtime: map[string]interface{}{
+			"go_version": runtime.Version(),
+			"pid":        os.Getpid(),
+			"hostname":   hostname,
+			"started_at": startedAt.Format(time.RFC3339),
+		},
+		Settings: c.RedactedSettings(),
+	}
+}
+
+// Redact 递归脱敏配置项，键名包含敏感关键字的值将被替换
+func Redact(settings map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		if IsSensitiveKey(key) {
+			if value == nil || value == "" {
+				result[key] = value
+			} else {
+				result[key] = RedactedValue
+			}
+			continue
+		}
+		result[key] = redactValue(value)
+	}
+	return result
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return Redact(v)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = item
+		}
+		return Redact(m)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = redactValue(item)
+		}
+		return items
+	}
+	return value
+}
+
+// IsSensitiveKey 判断配置项是否为敏感信息，key 可为单个键名或以点号分隔的完整路径
+// 任一层级敏感时整个子树均视为敏感，如 auth.session_sealing.keys.current
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	for _, segment := range strings.Split(key, ".") {
+		if isKeySegment(segment) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKeySegment 判断键名是否表示密钥：key、keys、*_key、*_keys 或包含 _key_，如 app.key、http.id_mask_key
+func isKeySegment(segment string) bool {
+	return segment == "key" || segment == "keys" ||
+		strings.HasSuffix(segment, "_key") || strings.HasSuffix(segment, "_keys") ||
+		strings.Contains(segment, "_key_")
+}
@@ -0,0 +1,142 @@
+package db_provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/icreateapp-com/go-zLib/z/providers/auth_provider"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// APIKeyRecord API Key 表
+type APIKeyRecord struct {
+	ID         string `gorm:"primaryKey;size:64" json:"id"`
+	Guard      string `gorm:"size:64;index" json:"guard"`
+	Name       string `gorm:"size:128" json:"name"`
+	Owner      string `gorm:"size:64;index" json:"owner"`
+	Hint       string `gorm:"size:8" json:"hint"`
+	Hash       string `gorm:"size:64;uniqueIndex" json:"-"`
+	Scopes     string `gorm:"type:text" json:"scopes"`
+	RateLimit  string `gorm:"size:32" json:"rate_limit"`
+	Data       string `gorm:"type:text" json:"data"`
+	CreatedAt  int64  `json:"created_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+	RevokedAt  int64  `json:"revoked_at"`
+}
+
+func (APIKeyRecord) TableName() string { return "api_keys" }
+
+// dbAPIKeyStore 基于 api_keys 表的 auth_provider.APIKeyStore
+type dbAPIKeyStore struct {
+	db *DB
+}
+
+// NewDBAPIKeyStore 创建数据库 API Key 存储，migrate 为 true 时自动建表
+//
+//	fx.Provide(func(db *db_provider.DB) (auth_provider.APIKeyStoreOut, error) {
+//		store, err := db_provider.NewDBAPIKeyStore(db, true)
+//		return auth_provider.RegisterAPIKeyStore("database", store), err
+//	})
+func NewDBAPIKeyStore(db *DB, migrate bool) (auth_provider.APIKeyStore, error) {
+	if migrate {
+		if err := db.AutoMigrate(&APIKeyRecord{}); err != nil {
+			return nil, WrapDBError(err)
+		}
+	}
+	return &dbAPIKeyStore{db: db}, nil
+}
+
+func (s *dbAPIKeyStore) FindByHash(ctx context.Context, guard, hash string) (*auth_provider.APIKey, error) {
+	var row APIKeyRecord
+	err := s.db.WithContext(ctx).Where("guard = ? AND hash = ?", guard, hash).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, WrapDBError(err)
+	}
+	return row.toAPIKey()
+}
+
+func (s *dbAPIKeyStore) Save(ctx context.Context, key *auth_provider.APIKey) error {
+	row := APIKeyRecord{
+		ID:         key.ID,
+		Guard:      key.Guard,
+		Name:       key.Name,
+		Owner:      key.Owner,
+		Hint:       key.Hint,
+		Hash:       key.Hash,
+		Scopes:     strings.Join(key.Scopes, ","),
+		RateLimit:  key.RateLimit,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+	if len(key.Data) > 0 {
+		b, err := json.Marshal(key.Data)
+		if err != nil {
+			return err
+		}
+		row.Data = string(b)
+	}
+	// 哈希创建后不再变化，更新时保留原值；last_used_at 仅由 Touch 更新
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "owner", "scopes", "rate_limit", "data", "expires_at", "revoked_at"}),
+	}).Create(&row).Error
+	return WrapDBError(err)
+}
+
+// Touch 实现 auth_provider.APIKeyToucher，只更新 last_used_at 列
+func (s *dbAPIKeyStore) Touch(ctx context.Context, guard, id string, at int64) error {
+	err := s.db.WithContext(ctx).Model(&APIKeyRecord{}).
+		Where("guard = ? AND id = ? AND last_used_at < ?", guard, id, at).
+		Update("last_used_at", at).Error
+	return WrapDBError(err)
+}
+
+func (s *dbAPIKeyStore) List(ctx context.Context, guard string) ([]*auth_provider.APIKey, error) {
+	var rows []APIKeyRecord
+	if err := s.db.WithContext(ctx).Where("guard = ?", guard).Order("created_at").Find(&rows).Error; err != nil {
+		return nil, WrapDBError(err)
+	}
+	out := make([]*auth_provider.APIKey, 0, len(rows))
+	for i := range rows {
+		k, err := rows[i].toAPIKey()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	return out, nil
+}
+
+func (r *APIKeyRecord) toAPIKey() (*auth_provider.APIKey, error) {
+	k := &auth_provider.APIKey{
+		ID:         r.ID,
+		Guard:      r.Guard,
+		Name:       r.Name,
+		Owner:      r.Owner,
+		Hint:       r.Hint,
+		Hash:       r.Hash,
+		RateLimit:  r.RateLimit,
+		CreatedAt:  r.CreatedAt,
+		ExpiresAt:  r.ExpiresAt,
+		LastUsedAt: r.LastUsedAt,
+		RevokedAt:  r.RevokedAt,
+	}
+	if r.Scopes != "" {
+		k.Scopes = strings.Split(r.Scopes, ",")
+	}
+	if r.Data != "" {
+		if err := json.Unmarshal([]byte(r.Data), &k.Data); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
@@ -0,0 +1,50 @@
+package db_provider
+
+import (
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// dbConfig db.yml 配置结构
+type dbConfig struct {
+	Driver      string   `default:"mysql" desc:"数据库驱动，目前支持 mysql"`
+	Middlewares []string `desc:"启用的 GORM 中间件，如 otel、caches、model_events"`
+	SlowQueryMs int      `config:"slow_query_ms" default:"1000" desc:"慢查询阈值（毫秒），超过时记录 warn 日志并写入 span 事件，小于 0 时不记录，支持热加载"`
+	SQLLog      bool     `config:"sql_log" default:"false" desc:"输出全部 SQL（app.debug 下默认输出），支持热加载"`
+	MySQL       struct {
+		Host            string        `default:"127.0.0.1" desc:"主机地址"`
+		Port            int           `default:"3306" desc:"端口"`
+		Username        string        `required:"true" desc:"用户名"`
+		Password        string        `desc:"密码"`
+		DBName          string        `config:"dbname" required:"true" desc:"数据库名"`
+		Charset         string        `default:"utf8mb4" desc:"字符集"`
+		MaxOpenConns    int           `default:"50" desc:"最大打开连接数"`
+		MaxIdleConns    int           `default:"10" desc:"最大空闲连接数，不超过 max_open_conns"`
+		ConnMaxLifetime time.Duration `default:"5m" desc:"连接最长存活时间"`
+		ConnMaxIdleTime time.Duration `default:"2m" desc:"连接最长空闲时间"`
+	} `config:"mysql" desc:"MySQL 连接"`
+	Caches struct {
+		Easer bool `default:"true" desc:"合并并发的相同查询"`
+	} `desc:"查询缓存中间件"`
+	Page struct {
+		Count         string        `default:"exact" desc:"总数统计策略：exact / approx / none"`
+		CountCacheTTL time.Duration `config:"count_cache_ttl" desc:"总数缓存时长，0 不缓存"`
+		MaxPage       int           `desc:"最大页码，0 不限制"`
+	} `desc:"分页"`
+	Query struct {
+		MaxGroups        int      `default:"10" desc:"条件组最大数量"`
+		MaxConditions    int      `default:"20" desc:"每组最大条件数"`
+		MaxValues        int      `default:"500" desc:"in / not_in 最大值个数"`
+		MaxOrderBy       int      `config:"max_orderby" default:"5" desc:"最大排序字段数"`
+		MaxGroupBy       int      `config:"max_groupby" default:"5" desc:"最大分组字段数"`
+		MaxRequired      int      `default:"10" desc:"最大 required 字段数"`
+		MaxIncludes      int      `default:"5" desc:"最大 include 关联数"`
+		MaxIncludeDepth  int      `default:"3" desc:"include 关联最大嵌套层数"`
+		MaxLimit         int      `default:"100" desc:"limit 最大值"`
+		MaxPage          int      `desc:"最大页码，默认同 page.max_page"`
+		AllowedOperators []string `desc:"允许的操作符，为空时允许全部合法操作符"`
+	} `desc:"客户端查询参数限制"`
+}
+
+var _ = config_provider.RegisterSchema("db", "数据库", dbConfig{})
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/db_provider/db_middlewares"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
@@ -19,6 +20,10 @@ import (
 type DB struct {
 	*gorm.DB
 	log *logger_provider.Logger
+
+	PageOptions PageOptions // 分页默认选项（db.page.*）
+
+	queries *SlowQueryPlugin
 }
 
 type MiddlewaresIn struct {
@@ -45,16 +50,20 @@ func NewDBProvider(lc fx.Lifecycle, in MiddlewaresIn, cfg *config_provider.Confi
 		return nil, fmt.Errorf("unknown db type: %s", driver)
 	}
 
-	debugLevel := logger.Error
-	if cfg.GetBool("app.debug", true) {
-		debugLevel = logger.Info
+	// app.debug 或 db.sql_log 开启时输出全部 SQL，两者均支持运行时调整
+	sqlLogLevel := func() logger.LogLevel {
+		if cfg.GetBool("app.debug", true) || cfg.GetBool("db.sql_log", false) {
+			return logger.Info
+		}
+		return logger.Error
 	}
+	debugLevel := sqlLogLevel()
 
 	std := zap.NewStdLog(log.Base())
 	gormLogger := NewFilteredGormLogger(logger.New(
 		std,
 		logger.Config{
-			SlowThreshold:             5 * time.Second,
+			SlowThreshold:             0, // 慢查询由 SlowQueryPlugin 按 db.slow_query_ms 记录
 			LogLevel:                  debugLevel,
 			IgnoreRecordNotFoundError: true,
 			ParameterizedQueries:      true,
@@ -68,7 +77,43 @@ func NewDBProvider(lc fx.Lifecycle, in MiddlewaresIn, cfg *config_provider.Confi
 		return nil, err
 	}
 
-	db := &DB{DB: gdb, log: log}
+	slowQueryThreshold := func() time.Duration {
+		return time.Duration(cfg.GetInt("db.slow_query_ms", int(DefaultSlowQueryThreshold/time.Millisecond))) * time.Millisecond
+	}
+	queries := NewSlowQueryPlugin(SlowQueryOptions{
+		Threshold: slowQueryThreshold(),
+		Log:       log,
+	})
+	if err := gdb.Use(queries); err != nil {
+		return nil, err
+	}
+
+	// 配置重载或运行时覆盖后调整 SQL 日志级别与慢查询阈值
+	cfg.OnChange(func(event config_provider.ChangeEvent) {
+		if event.Has("app.debug") || event.Has("db.sql_log") {
+			if l, ok := gormLogger.(*FilteredGormLogger); ok {
+				l.SetLevel(sqlLogLevel())
+			}
+		}
+		if event.Has("db.slow_query_ms") {
+			queries.SetThreshold(slowQueryThreshold())
+		}
+	})
+
+	db := &DB{DB: gdb, log: log, PageOptions: pageOptionsFromConfig(cfg), queries: queries}
+	SetQueryLimits(QueryLimits{
+		MaxGroups:        cfg.GetInt("db.query.max_groups", 0),
+		MaxConditions:    cfg.GetInt("db.query.max_conditions", 0),
+		MaxValues:        cfg.GetInt("db.query.max_values", 0),
+		MaxOrderBy:       cfg.GetInt("db.query.max_orderby", 0),
+		MaxGroupBy:       cfg.GetInt("db.query.max_groupby", 0),
+		MaxRequired:      cfg.GetInt("db.query.max_required", 0),
+		MaxIncludes:      cfg.GetInt("db.query.max_includes", 0),
+		MaxIncludeDepth:  cfg.GetInt("db.query.max_include_depth", 0),
+		MaxLimit:         cfg.GetInt("db.query.max_limit", 0),
+		MaxPage:          cfg.GetInt("db.query.max_page", db.PageOptions.MaxPage),
+		AllowedOperators: cfg.GetStringSlice("db.query.allowed_operators", nil),
+	})
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -160,18 +205,41 @@ var DBProviderModule = fx.Options(
 	db_middlewares.RegistryModule,
 	db_middlewares.OtelGormModule,
 	db_middlewares.CachesModule,
+	db_middlewares.ModelEventsModule,
 	fx.Provide(NewDBProvider),
+	fx.Provide(
+		fx.Annotate(
+			func(db *DB) z.ReadyCheck { return z.ReadyCheck{Name: "db", Checker: db} },
+			fx.ResultTags(`group:"ready_checks"`),
+		),
+	),
 )
 
+// readyTimeout 就绪检查的 Ping 超时
+const readyTimeout = 2 * time.Second
+
+// Ready 检查数据库连接是否可用
+func (db *DB) Ready() error {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// QueryStats 返回进程内累计的查询统计，未通过 NewDBProvider 创建时为零值
+func (db *DB) QueryStats() QueryStats {
+	return db.queries.Stats()
+}
+
 // Transaction 事务装饰器 - 自动管理事务生命周期
 func (db *DB) Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
 	return db.DB.Transaction(fc, opts...)
 }
 
-// F 字段转义
+// F 按方言转义字段名：MySQL / SQLite 使用反引号，PostgreSQL 使用双引号，table.column 分别转义
 func (db *DB) F(field string) string {
-	if db.Dialector.Name() == "mysql" {
-		return "`" + field + "`"
-	}
-	return field
+	return db.Statement.Quote(field)
 }
@@ -0,0 +1,206 @@
+package db_provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 聚合函数
+const (
+	AggCount = "count"
+	AggSum   = "sum"
+	AggAvg   = "avg"
+	AggMin   = "min"
+	AggMax   = "max"
+)
+
+// Aggregate 聚合列，如 Aggregate{Func: AggSum, Field: "amount", As: "total"} 生成 SUM(amount) AS total
+type Aggregate struct {
+	Func     string // count / sum / avg / min / max
+	Field    string // 字段名，count 可为 *
+	As       string // 结果列名，为空时为 func_field（如 sum_amount、count_all）
+	Distinct bool   // 对去重后的值聚合，如 COUNT(DISTINCT user_id)
+}
+
+// CountOf 计数，field 为空或 * 时统计行数
+func CountOf(field, as string) Aggregate {
+	return Aggregate{Func: AggCount, Field: field, As: as}
+}
+
+// SumOf 求和
+func SumOf(field, as string) Aggregate {
+	return Aggregate{Func: AggSum, Field: field, As: as}
+}
+
+// AvgOf 平均值
+func AvgOf(field, as string) Aggregate {
+	return Aggregate{Func: AggAvg, Field: field, As: as}
+}
+
+// MinOf 最小值
+func MinOf(field, as string) Aggregate {
+	return Aggregate{Func: AggMin, Field: field, As: as}
+}
+
+// MaxOf 最大值
+func MaxOf(field, as string) Aggregate {
+	return Aggregate{Func: AggMax, Field: field, As: as}
+}
+
+// Expr 校验并生成 SQL 片段
+func (a Aggregate) Expr() (string, error) {
+	fn := strings.ToLower(strings.TrimSpace(a.Func))
+	switch fn {
+	case AggCount, AggSum, AggAvg, AggMin, AggMax:
+	default:
+		return "", errors.New("invalid aggregate function: " + a.Func)
+	}
+	field := strings.TrimSpace(a.Field)
+	if field == "" || field == "*" {
+		if fn != AggCount || a.Distinct {
+			return "", fmt.Errorf("aggregate %s requires a field", fn)
+		}
+		field = "*"
+	} else if !isValidFieldName(field) {
+		return "", errors.New("invalid field name: " + a.Field)
+	}
+	alias := a.As
+	if alias == "" {
+		name := strings.ReplaceAll(field, ".", "_")
+		if name == "*" {
+			name = "all"
+		}
+		alias = fn + "_" + name
+	}
+	if !isValidFieldName(alias) || strings.Contains(alias, ".") {
+		return "", errors.New("invalid aggregate alias: " + alias)
+	}
+	if a.Distinct {
+		field = "DISTINCT " + field
+	}
+	return fmt.Sprintf("%s(%s) AS %s", strings.ToUpper(fn), field, alias), nil
+}
+
+// ParseGroupBy 解析分组字段及分组过滤条件
+func ParseGroupBy(db *gorm.DB, groupBy []string, having []ConditionGroup) (*gorm.DB, error) {
+	if len(groupBy) == 0 {
+		if len(having) > 0 {
+			return nil, errors.New("having requires group by")
+		}
+		return db, nil
+	}
+	for _, field := range groupBy {
+		if !isValidFieldName(field) {
+			return nil, errors.New("invalid group by field name: " + field)
+		}
+	}
+	db = db.Group(strings.Join(groupBy, ", "))
+
+	havingClause, values, err := buildConditionClause(having, nil)
+	if err != nil {
+		return nil, err
+	}
+	if havingClause != "" {
+		db = db.Having(havingClause, values...)
+	}
+	return db, nil
+}
+
+// Aggregate 按 Query.GroupBy 分组计算聚合列，结果扫描到 dest（结构体切片或 []map[string]interface{}）
+// 结果列为分组字段及各聚合别名；应用 Search、Having、OrderBy（可使用别名）和 Limit，忽略 Page
+//
+//	var rows []struct {
+//		Status string
+//		Total  float64
+//		Orders int64
+//	}
+//	q := db_provider.Query{}
+//	q.AddGroupBy("status").AddHaving("total", 100, ">").AddOrderByDesc("total")
+//	err := builder.Aggregate(&rows, db_provider.SumOf("amount", "total"), db_provider.CountOf("*", "orders"))
+func (q *QueryBuilder[T]) Aggregate(dest interface{}, aggregates ...Aggregate) error {
+	if len(aggregates) == 0 {
+		return WrapDBError(errors.New("aggregate requires at least one aggregate column"))
+	}
+	columns := make([]string, 0, len(q.Query.GroupBy)+len(aggregates))
+	columns = append(columns, q.Query.GroupBy...)
+	for _, a := range aggregates {
+		expr, err := a.Expr()
+		if err != nil {
+			return WrapDBError(err)
+		}
+		columns = append(columns, expr)
+	}
+
+	db := q.getDBWithModel()
+	if db == nil {
+		return WrapDBError(errors.New("database not initialized"))
+	}
+	query := Query{
+		Search:   q.Query.Search,
+		Required: q.Query.Required,
+		Trashed:  q.Query.Trashed,
+	}
+	parsedDB, err := ParseQuery(query, db)
+	if err != nil {
+		return WrapDBError(err)
+	}
+	if parsedDB, err = ParseGroupBy(parsedDB, q.Query.GroupBy, q.Query.Having); err != nil {
+		return WrapDBError(err)
+	}
+	if parsedDB, err = ParseOrderBy(parsedDB, q.Query.OrderBy); err != nil {
+		return WrapDBError(err)
+	}
+	if parsedDB, err = ParseLimit(parsedDB, q.Query.Limit); err != nil {
+		return WrapDBError(err)
+	}
+
+	if err := parsedDB.Select(strings.Join(columns, ", ")).Scan(dest).Error; err != nil {
+		return WrapDBError(err)
+	}
+	return nil
+}
+
+// Min 计算字段最小值，无记录时返回 0
+func (q *QueryBuilder[T]) Min(field string) (float64, error) {
+	return q.aggregateValue(AggMin, field)
+}
+
+// Max 计算字段最大值，无记录时返回 0
+func (q *QueryBuilder[T]) Max(field string) (float64, error) {
+	return q.aggregateValue(AggMax, field)
+}
+
+// aggregateValue 按搜索条件计算单个聚合值
+func (q *QueryBuilder[T]) aggregateValue(fn, field string) (float64, error) {
+	// 验证字段名安全性
+	if !isValidFieldName(field) {
+		return 0, errors.New("invalid field name: " + field)
+	}
+
+	db := q.getDBWithModel()
+	if db == nil {
+		return 0, WrapDBError(errors.New("database not initialized"))
+	}
+
+	// 只解析搜索条件
+	query := Query{
+		Search:   q.Query.Search,
+		Required: q.Query.Required,
+		Trashed:  q.Query.Trashed,
+	}
+
+	parsedDB, err := ParseQuery(query, db)
+	if err != nil {
+		return 0, WrapDBError(err)
+	}
+
+	var value float64
+	if err := parsedDB.Select(fmt.Sprintf("COALESCE(%s(%s), 0) as %s", strings.ToUpper(fn), field, fn)).Row().Scan(&value); err != nil {
+		return 0, WrapDBError(err)
+	}
+
+	return value, nil
+}
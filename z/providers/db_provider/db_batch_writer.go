@@ -0,0 +1,30 @@
+package db_provider
+
+import (
+	"context"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"go.uber.org/fx"
+)
+
+// BatchInsert 返回 z.BatchWriter 的写入函数，每批记录通过 CreateBuilder.CreateMany 插入
+// batchSize 为每条 INSERT 语句的记录数，<= 0 时使用 DefaultCreateBatchSize；可重试的错误（见 z.IsRetryable）按 BatchWriterOptions.Retries 重试
+//
+//	w := z.NewBatchWriter(db_provider.BatchInsert[AuditLog](db, 0), z.BatchWriterOptions{Name: "audit_log"})
+func BatchInsert[T IModel](db *DB, batchSize int) z.BatchFlushFunc[T] {
+	return func(ctx context.Context, items []T) error {
+		builder := &CreateBuilder[T]{DB: db, Context: ctx}
+		_, err := builder.CreateMany(items, batchSize)
+		return err
+	}
+}
+
+// RegisterBatchWriter 应用停止时关闭写入器并写入缓冲中的剩余记录
+// 写入器依赖 *DB 时该钩子晚于数据库注册，因此在数据库连接关闭前执行
+func RegisterBatchWriter[T any](lc fx.Lifecycle, w *z.BatchWriter[T]) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return w.Close(ctx)
+		},
+	})
+}
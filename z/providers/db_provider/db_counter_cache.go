@@ -0,0 +1,346 @@
+package db_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CounterCache 父表上的计数列，记录子表中关联的记录数（软删除的记录不计入）
+type CounterCache struct {
+	ForeignKey string // 子表中关联父表的列，如 post_id
+	Column     string // 父表的计数列，默认为 子表名_count，如 comments_count
+	ParentKey  string // 父表中被关联的列，默认 id
+
+	parent string
+}
+
+// CounterDrift 计数列与实际记录数不一致的父记录
+type CounterDrift struct {
+	Table     string `json:"table"`
+	Column    string `json:"column"`
+	ParentKey string `json:"parent_key"`
+	Stored    int64  `json:"stored"`
+	Actual    int64  `json:"actual"`
+}
+
+var (
+	counterCacheMu sync.RWMutex
+	counterCaches  = map[reflect.Type][]CounterCache{}
+)
+
+// RegisterCounterCache 声明父表 P 的计数列跟踪子表 C 的记录数，由 CreateBuilder / DeleteBuilder（及基于它们的 CrudService）在同一事务中维护
+// 修改子记录的关联列不会调整计数，需调用 RebuildCounterCache
+//
+//	db_provider.RegisterCounterCache[Comment, Post](db_provider.CounterCache{ForeignKey: "post_id", Column: "comments_count"})
+func RegisterCounterCache[C IModel, P IModel](cache CounterCache) error {
+	var child C
+	var parent P
+	cache.parent = parent.TableName()
+	if cache.Column == "" {
+		cache.Column = child.TableName() + "_count"
+	}
+	if cache.ParentKey == "" {
+		cache.ParentKey = "id"
+	}
+	for _, column := range []string{cache.ForeignKey, cache.Column, cache.ParentKey} {
+		if column == "" || !isValidFieldName(column) {
+			return fmt.Errorf("counter cache %s.%s: invalid column %q", cache.parent, cache.Column, column)
+		}
+	}
+
+	typ := reflect.TypeOf(child)
+	counterCacheMu.Lock()
+	defer counterCacheMu.Unlock()
+	for _, existing := range counterCaches[typ] {
+		if existing.parent == cache.parent && existing.Column == cache.Column {
+			return fmt.Errorf("counter cache %s.%s already registered", cache.parent, cache.Column)
+		}
+	}
+	counterCaches[typ] = append(counterCaches[typ], cache)
+	return nil
+}
+
+// CounterCaches 返回子表模型 C 上声明的计数列
+func CounterCaches[C IModel]() []CounterCache {
+	var child C
+	counterCacheMu.RLock()
+	defer counterCacheMu.RUnlock()
+	return append([]CounterCache(nil), counterCaches[reflect.TypeOf(child)]...)
+}
+
+// counterCacheTx 模型有计数列时在事务中执行 fn（已绑定事务时为嵌套事务），否则直接执行
+func counterCacheTx[T IModel](db *gorm.DB, fn func(tx *gorm.DB, caches []CounterCache) error) error {
+	caches := CounterCaches[T]()
+	if len(caches) == 0 {
+		return fn(db, nil)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return fn(tx, caches)
+	})
+}
+
+// incrementCounters 按写入的记录增加父表计数
+func incrementCounters(tx *gorm.DB, caches []CounterCache, values interface{}) error {
+	if len(caches) == 0 {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(values); err != nil {
+		return err
+	}
+	for _, cache := range caches {
+		field := stmt.Schema.LookUpField(cache.ForeignKey)
+		if field == nil {
+			return fmt.Errorf("counter cache %s.%s: foreign key %s not found in %s", cache.parent, cache.Column, cache.ForeignKey, stmt.Schema.Table)
+		}
+		deltas := map[interface{}]int64{}
+		eachRecord(reflect.ValueOf(values), func(rv reflect.Value) {
+			if key, zero := field.ValueOf(tx.Statement.Context, rv); !zero {
+				if key = derefKey(key); key != nil {
+					deltas[key]++
+				}
+			}
+		})
+		if err := adjustCounters(tx, cache, deltas, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockCounterKeys 锁定即将删除 / 恢复的记录并统计每个父记录的条数；trashed 为 true 时统计已软删除的记录
+// 先于写入执行，并发删除同一批记录时后者等待前者提交，不会重复扣减
+func lockCounterKeys(tx *gorm.DB, caches []CounterCache, trashed bool) ([]map[interface{}]int64, error) {
+	if len(caches) == 0 {
+		return nil, nil
+	}
+	query := tx
+	if column := softDeleteColumnOf(tx, tx.Statement.Model); column != "" {
+		cond := "? IS NULL"
+		if trashed {
+			cond = "? IS NOT NULL"
+		}
+		query = query.Where(clause.Expr{SQL: cond, Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: column}}})
+	} else if trashed {
+		return make([]map[interface{}]int64, len(caches)), nil
+	}
+	if tx.Dialector.Name() != "sqlite" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	columns := make([]interface{}, 0, len(caches))
+	for _, cache := range caches {
+		columns = append(columns, clause.Column{Table: clause.CurrentTable, Name: cache.ForeignKey})
+	}
+	var rows []map[string]interface{}
+	if err := query.Select(placeholders(len(columns)), columns...).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	deltas := make([]map[interface{}]int64, len(caches))
+	for i, cache := range caches {
+		deltas[i] = map[interface{}]int64{}
+		for _, row := range rows {
+			if key := derefKey(row[cache.ForeignKey]); key != nil {
+				deltas[i][key]++
+			}
+		}
+	}
+	return deltas, nil
+}
+
+// applyCounterKeys 按 lockCounterKeys 的统计调整计数，sign 为 -1 表示删除，1 表示恢复
+func applyCounterKeys(tx *gorm.DB, caches []CounterCache, deltas []map[interface{}]int64, sign int64) error {
+	for i, cache := range caches {
+		if err := adjustCounters(tx, cache, deltas[i], sign); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// adjustCounters 以原子的 col = col + n 更新计数，相同增量的父记录合并为一条语句，按主键排序以固定加锁顺序
+func adjustCounters(tx *gorm.DB, cache CounterCache, deltas map[interface{}]int64, sign int64) error {
+	byDelta := map[int64][]interface{}{}
+	for key, n := range deltas {
+		if n != 0 {
+			byDelta[n*sign] = append(byDelta[n*sign], key)
+		}
+	}
+	db := tx.Session(&gorm.Session{NewDB: true})
+	for delta, keys := range byDelta {
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		err := db.Table(cache.parent).
+			Where(clause.IN{Column: clause.Column{Name: cache.ParentKey}, Values: keys}).
+			UpdateColumn(cache.Column, gorm.Expr("? + ?", clause.Column{Name: cache.Column}, delta)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recountCounters 按写入的记录重新统计其父记录的计数，用于 Upsert 等无法确定新增条数的写入
+func recountCounters(tx *gorm.DB, caches []CounterCache, values interface{}) error {
+	if len(caches) == 0 {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(values); err != nil {
+		return err
+	}
+	for _, cache := range caches {
+		field := stmt.Schema.LookUpField(cache.ForeignKey)
+		if field == nil {
+			return fmt.Errorf("counter cache %s.%s: foreign key %s not found in %s", cache.parent, cache.Column, cache.ForeignKey, stmt.Schema.Table)
+		}
+		seen := map[interface{}]bool{}
+		var keys []interface{}
+		eachRecord(reflect.ValueOf(values), func(rv reflect.Value) {
+			if key, zero := field.ValueOf(tx.Statement.Context, rv); !zero {
+				if key = derefKey(key); key != nil && !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+		})
+		if len(keys) == 0 {
+			continue
+		}
+		if _, err := rebuildCounter(tx, stmt.Schema.Table, softDeleteColumnOf(tx, values), cache, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildCounterCache 按子表 C 的实际记录数重算其声明的全部计数列，parentKeys 非空时仅重算这些父记录，返回被修正的父记录数
+//
+//	fixed, err := db_provider.RebuildCounterCache[Comment](ctx, db)
+func RebuildCounterCache[C IModel](ctx context.Context, db *DB, parentKeys ...interface{}) (int64, error) {
+	if db == nil {
+		return 0, WrapDBError(errors.New("db is nil"))
+	}
+	var child C
+	var fixed int64
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, cache := range CounterCaches[C]() {
+			n, err := rebuildCounter(tx, child.TableName(), softDeleteColumnOf(tx, &child), cache, parentKeys)
+			if err != nil {
+				return err
+			}
+			fixed += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, WrapDBError(err)
+	}
+	return fixed, nil
+}
+
+func rebuildCounter(tx *gorm.DB, childTable, softDelete string, cache CounterCache, parentKeys []interface{}) (int64, error) {
+	actual := actualCountExpr(childTable, softDelete, cache)
+	query := tx.Session(&gorm.Session{NewDB: true}).Table(cache.parent).
+		Where(clause.Expr{SQL: "? <> " + actual.SQL, Vars: append([]interface{}{clause.Column{Name: cache.Column}}, actual.Vars...)})
+	if len(parentKeys) > 0 {
+		query = query.Where(clause.IN{Column: clause.Column{Name: cache.ParentKey}, Values: parentKeys})
+	}
+	result := query.UpdateColumn(cache.Column, actual)
+	return result.RowsAffected, result.Error
+}
+
+// CounterCacheDrift 检查子表 C 声明的计数列，返回计数与实际记录数不一致的父记录，limit > 0 时每个计数列最多返回 limit 条
+func CounterCacheDrift[C IModel](ctx context.Context, db *DB, limit int) ([]CounterDrift, error) {
+	if db == nil {
+		return nil, WrapDBError(errors.New("db is nil"))
+	}
+	var child C
+	var drifts []CounterDrift
+	for _, cache := range CounterCaches[C]() {
+		tx := db.WithContext(ctx)
+		actual := actualCountExpr(child.TableName(), softDeleteColumnOf(tx, &child), cache)
+		inner := tx.Table(cache.parent).Select("? AS parent_key, ? AS stored, "+actual.SQL+" AS actual",
+			append([]interface{}{clause.Column{Name: cache.ParentKey}, clause.Column{Name: cache.Column}}, actual.Vars...)...)
+		query := tx.Table("(?) AS counter_drift", inner).Where("stored <> actual").Order("parent_key")
+		if limit > 0 {
+			query = query.Limit(limit)
+		}
+		var rows []struct {
+			ParentKey string
+			Stored    int64
+			Actual    int64
+		}
+		if err := query.Scan(&rows).Error; err != nil {
+			return nil, WrapDBError(err)
+		}
+		for _, row := range rows {
+			drifts = append(drifts, CounterDrift{Table: cache.parent, Column: cache.Column, ParentKey: row.ParentKey, Stored: row.Stored, Actual: row.Actual})
+		}
+	}
+	return drifts, nil
+}
+
+// actualCountExpr 统计子表中关联当前父记录的未删除记录数的关联子查询
+func actualCountExpr(childTable, softDelete string, cache CounterCache) clause.Expr {
+	sql := "SELECT COUNT(*) FROM ? WHERE ? = ?"
+	vars := []interface{}{
+		clause.Table{Name: childTable},
+		clause.Column{Table: childTable, Name: cache.ForeignKey},
+		clause.Column{Table: cache.parent, Name: cache.ParentKey},
+	}
+	if softDelete != "" {
+		sql += " AND ? IS NULL"
+		vars = append(vars, clause.Column{Table: childTable, Name: softDelete})
+	}
+	return clause.Expr{SQL: "(" + sql + ")", Vars: vars}
+}
+
+// eachRecord 遍历单条记录或记录切片
+func eachRecord(rv reflect.Value, fn func(reflect.Value)) {
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
+
+// derefKey 取出指针字段的值，MySQL 驱动返回的 []byte 转为 string，使相同的关联值合并
+func derefKey(key interface{}) interface{} {
+	if b, ok := key.([]byte); ok {
+		return string(b)
+	}
+	rv := reflect.ValueOf(key)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+func placeholders(n int) string {
+	s := ""
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			s += ", "
+		}
+		s += "?"
+	}
+	return s
+}
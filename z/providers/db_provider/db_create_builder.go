@@ -5,8 +5,21 @@ import (
 	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// DefaultCreateBatchSize CreateMany / Upsert 未指定批大小时每条 INSERT 语句的记录数
+const DefaultCreateBatchSize = 500
+
+// OnConflict 写入冲突时的处理方式
+// MySQL 生成 ON DUPLICATE KEY UPDATE，按表上的主键 / 唯一索引判定冲突，忽略 Columns；
+// PostgreSQL、SQLite 生成 ON CONFLICT (Columns)，Columns 为空时按主键判定
+type OnConflict struct {
+	Columns   []string // 冲突判定列
+	Update    []string // 冲突时更新的列，为空时更新除主键和创建时间外的全部列
+	DoNothing bool     // 冲突时忽略该记录
+}
+
 // rawCreateCondition 原生条件
 type rawCreateCondition struct {
 	query string        // SQL 查询条件
@@ -17,6 +30,7 @@ type CreateBuilder[T IModel] struct {
 	DB            *DB                  // 数据库连接（DI 注入）
 	TX            *gorm.DB             // 事务支持
 	Context       context.Context      // 上下文
+	Filter        ExistenceFilter      // 存在性过滤器（可选），创建成功后写入主键
 	rawConditions []rawCreateCondition // 原生条件
 }
 
@@ -27,6 +41,13 @@ func (q *CreateBuilder[T]) WithContext(ctx context.Context) *CreateBuilder[T] {
 	return newBuilder
 }
 
+// WithFilter 设置存在性过滤器
+func (q *CreateBuilder[T]) WithFilter(filter ExistenceFilter) *CreateBuilder[T] {
+	newBuilder := q.clone()
+	newBuilder.Filter = filter
+	return newBuilder
+}
+
 // Where 添加 WHERE 条件
 func (q *CreateBuilder[T]) Where(query string, args ...interface{}) *CreateBuilder[T] {
 	newBuilder := q.clone()
@@ -43,6 +64,7 @@ func (q *CreateBuilder[T]) clone() *CreateBuilder[T] {
 		DB:      q.DB,
 		TX:      q.TX,
 		Context: q.Context,
+		Filter:  q.Filter,
 	}
 
 	// 深拷贝 rawConditions
@@ -85,9 +107,17 @@ func (q *CreateBuilder[T]) Create(values T, customFunc ...func(*gorm.DB) *gorm.D
 
 	// 创建一个副本用于数据库操作，确保原始数据不被修改
 	result := values
-	if err := db.Create(&result).Error; err != nil {
+	var tx *gorm.DB
+	err := counterCacheTx[T](db, func(db *gorm.DB, caches []CounterCache) error {
+		if tx = db.Create(&result); tx.Error != nil {
+			return tx.Error
+		}
+		return incrementCounters(db, caches, &result)
+	})
+	if err != nil {
 		return zero, WrapDBError(err)
 	}
+	addToFilter(q.Context, q.Filter, tx, &result)
 
 	// 返回包含自动生成字段（如 ID）的结果
 	return result, nil
@@ -131,10 +161,119 @@ func (q *CreateBuilder[T]) BatchCreate(values []T, customFunc ...func(*gorm.DB)
 	result := make([]T, len(values))
 	copy(result, values)
 
-	if err := db.Create(&result).Error; err != nil {
+	var tx *gorm.DB
+	err := counterCacheTx[T](db, func(db *gorm.DB, caches []CounterCache) error {
+		if tx = db.Create(&result); tx.Error != nil {
+			return tx.Error
+		}
+		return incrementCounters(db, caches, &result)
+	})
+	if err != nil {
 		return nil, WrapDBError(err)
 	}
+	addToFilter(q.Context, q.Filter, tx, &result)
 
 	// 返回包含自动生成字段（如 ID）的结果
 	return result, nil
 }
+
+// CreateMany 按批大小分批插入记录，batchSize <= 0 时使用 DefaultCreateBatchSize
+// 多个批次在同一事务中执行（未开启 SkipDefaultTransaction 时），任一批失败时整体回滚
+func (q *CreateBuilder[T]) CreateMany(values []T, batchSize int, customFunc ...func(*gorm.DB) *gorm.DB) ([]T, error) {
+	return q.createMany(values, batchSize, false, customFunc)
+}
+
+// createMany 分批插入，upsert 为 true 时冲突的记录可能未新增，按写入记录的父记录重算计数列
+func (q *CreateBuilder[T]) createMany(values []T, batchSize int, upsert bool, customFunc []func(*gorm.DB) *gorm.DB) ([]T, error) {
+	if len(values) == 0 {
+		return []T{}, nil
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultCreateBatchSize
+	}
+
+	db, err := q.prepare(customFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建副本用于数据库操作，确保原始数据不被修改
+	result := make([]T, len(values))
+	copy(result, values)
+
+	var tx *gorm.DB
+	err = counterCacheTx[T](db, func(db *gorm.DB, caches []CounterCache) error {
+		if tx = db.CreateInBatches(&result, batchSize); tx.Error != nil {
+			return tx.Error
+		}
+		if upsert {
+			return recountCounters(db, caches, &result)
+		}
+		return incrementCounters(db, caches, &result)
+	})
+	if err != nil {
+		return nil, WrapDBError(err)
+	}
+	// 多批次在事务中执行时返回的语句未解析模型，需补充解析以写入过滤器
+	if q.Filter != nil && tx.Statement.Schema == nil {
+		_ = tx.Statement.Parse(&result)
+	}
+	addToFilter(q.Context, q.Filter, tx, &result)
+
+	return result, nil
+}
+
+// Upsert 分批插入记录，冲突时按 conflict 更新或忽略，batchSize <= 0 时使用 DefaultCreateBatchSize
+// MySQL 下冲突更新的记录不会回填自增主键
+func (q *CreateBuilder[T]) Upsert(values []T, batchSize int, conflict OnConflict, customFunc ...func(*gorm.DB) *gorm.DB) ([]T, error) {
+	onConflict := clause.OnConflict{DoNothing: conflict.DoNothing}
+	for _, column := range conflict.Columns {
+		if !isValidFieldName(column) {
+			return nil, WrapDBError(errors.New("invalid conflict column: " + column))
+		}
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	if !conflict.DoNothing {
+		if len(conflict.Update) == 0 {
+			onConflict.UpdateAll = true
+		} else {
+			for _, column := range conflict.Update {
+				if !isValidFieldName(column) {
+					return nil, WrapDBError(errors.New("invalid update column: " + column))
+				}
+			}
+			onConflict.DoUpdates = clause.AssignmentColumns(conflict.Update)
+		}
+	}
+
+	return q.createMany(values, batchSize, true, append([]func(*gorm.DB) *gorm.DB{func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(onConflict)
+	}}, customFunc...))
+}
+
+// prepare 获取应用了上下文、原生条件和自定义函数的数据库连接
+func (q *CreateBuilder[T]) prepare(customFunc []func(*gorm.DB) *gorm.DB) (*gorm.DB, error) {
+	var zero T
+	var db *gorm.DB
+	if q.TX != nil {
+		db = q.TX.Model(&zero)
+	} else {
+		if q.DB == nil {
+			return nil, WrapDBError(errors.New("db is nil"))
+		}
+		db = q.DB.Model(&zero)
+	}
+
+	if q.Context != nil {
+		db = db.WithContext(q.Context)
+	}
+	for _, condition := range q.rawConditions {
+		db = db.Where(condition.query, condition.args...)
+	}
+	for _, fn := range customFunc {
+		if fn != nil {
+			db = fn(db)
+		}
+	}
+	return db, nil
+}
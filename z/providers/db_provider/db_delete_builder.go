@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // rawCondition 原生条件
@@ -56,17 +57,90 @@ func (q *DeleteBuilder[T]) clone() *DeleteBuilder[T] {
 	return newBuilder
 }
 
+// Delete 删除记录，模型含 gorm.DeletedAt 字段（如内嵌 SoftDelete）时为软删除，仅设置 deleted_at
 func (q *DeleteBuilder[T]) Delete(query ...Query) (bool, error) {
+	db, err := q.prepare(false, query...)
+	if err != nil {
+		return false, err
+	}
+	if err := q.delete(db); err != nil {
+		return false, WrapDBError(err)
+	}
+	return true, nil
+}
+
+// ForceDelete 物理删除记录，包括已软删除的记录
+func (q *DeleteBuilder[T]) ForceDelete(query ...Query) (bool, error) {
+	db, err := q.prepare(true, query...)
+	if err != nil {
+		return false, err
+	}
+	if err := q.delete(db); err != nil {
+		return false, WrapDBError(err)
+	}
+	return true, nil
+}
+
+// Restore 恢复已软删除的记录，返回是否有记录被恢复；模型不支持软删除时返回 ErrSoftDeleteUnsupported
+func (q *DeleteBuilder[T]) Restore(query ...Query) (bool, error) {
+	column := SoftDeleteColumn[T](builderDB(q.DB, q.TX))
+	if column == "" {
+		return false, ErrSoftDeleteUnsupported
+	}
+	db, err := q.prepare(true, query...)
+	if err != nil {
+		return false, err
+	}
+	var restored int64
+	err = counterCacheTx[T](db, func(db *gorm.DB, caches []CounterCache) error {
+		deltas, err := lockCounterKeys(db, caches, true)
+		if err != nil {
+			return err
+		}
+		result := db.Where(clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: column}}}).
+			Update(column, nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		restored = result.RowsAffected
+		return applyCounterKeys(db, caches, deltas, 1)
+	})
+	if err != nil {
+		return false, WrapDBError(err)
+	}
+	return restored > 0, nil
+}
+
+// delete 执行删除，模型有计数列时先统计并锁定待删除的记录，删除后在同一事务中扣减父表计数
+func (q *DeleteBuilder[T]) delete(db *gorm.DB) error {
+	var zero T
+	return counterCacheTx[T](db, func(db *gorm.DB, caches []CounterCache) error {
+		deltas, err := lockCounterKeys(db, caches, false)
+		if err != nil {
+			return err
+		}
+		if err := db.Delete(&zero).Error; err != nil {
+			return err
+		}
+		return applyCounterKeys(db, caches, deltas, -1)
+	})
+}
+
+// prepare 构建带条件的连接，unscoped 为 true 时不排除已软删除的记录
+func (q *DeleteBuilder[T]) prepare(unscoped bool, query ...Query) (*gorm.DB, error) {
 	var zero T
 	var db *gorm.DB
 	if q.TX != nil {
 		db = q.TX.Model(&zero)
 	} else {
 		if q.DB == nil {
-			return false, WrapDBError(errors.New("db is nil"))
+			return nil, WrapDBError(errors.New("db is nil"))
 		}
 		db = q.DB.Model(&zero)
 	}
+	if unscoped {
+		db = db.Unscoped()
+	}
 
 	// 应用上下文
 	if q.Context != nil {
@@ -79,44 +153,60 @@ func (q *DeleteBuilder[T]) Delete(query ...Query) (bool, error) {
 	}
 
 	// 先应用初始化时的 Query 参数
+	var err error
 	if len(q.Query.Search) > 0 || len(q.Query.Required) > 0 {
-		var err error
-		db, err = ParseSearch(db, q.Query.Search, q.Query.Required)
-		if err != nil {
-			return false, WrapDBError(err)
+		if db, err = ParseSearch(db, q.Query.Search, q.Query.Required); err != nil {
+			return nil, WrapDBError(err)
 		}
 	}
 
 	// 如果提供了查询参数，则应用查询条件
 	if len(query) > 0 {
-		db, err := ParseSearch(db, query[0].Search, query[0].Required)
-		if err != nil {
-			return false, WrapDBError(err)
+		if db, err = ParseSearch(db, query[0].Search, query[0].Required); err != nil {
+			return nil, WrapDBError(err)
 		}
+	}
+	return db, nil
+}
 
-		if err := db.Delete(&zero).Error; err != nil {
-			return false, WrapDBError(err)
-		}
-	} else {
-		// 没有额外查询条件时直接删除
-		if err := db.Delete(&zero).Error; err != nil {
-			return false, WrapDBError(err)
-		}
+func (q *DeleteBuilder[T]) DeleteByID(id interface{}, additionalQuery ...Query) (bool, error) {
+	query, err := q.primaryKeyQuery(id, additionalQuery...)
+	if err != nil {
+		return false, err
 	}
+	return q.Delete(query)
+}
 
-	return true, nil
+// ForceDeleteByID 根据主键物理删除记录
+func (q *DeleteBuilder[T]) ForceDeleteByID(id interface{}, additionalQuery ...Query) (bool, error) {
+	query, err := q.primaryKeyQuery(id, additionalQuery...)
+	if err != nil {
+		return false, err
+	}
+	return q.ForceDelete(query)
 }
 
-func (q *DeleteBuilder[T]) DeleteByID(id interface{}, additionalQuery ...Query) (bool, error) {
-	if id == nil || id == "" {
-		return false, errors.New("id cannot be empty")
+// RestoreByID 根据主键恢复已软删除的记录
+func (q *DeleteBuilder[T]) RestoreByID(id interface{}, additionalQuery ...Query) (bool, error) {
+	query, err := q.primaryKeyQuery(id, additionalQuery...)
+	if err != nil {
+		return false, err
 	}
+	return q.Restore(query)
+}
 
-	// 构建基础的ID查询条件
+// primaryKeyQuery 构建主键条件并合并额外查询条件
+func (q *DeleteBuilder[T]) primaryKeyQuery(id interface{}, additionalQuery ...Query) (Query, error) {
+	conditions, err := primaryKeyConditions(PrimaryKeyColumns[T](builderDB(q.DB, q.TX)), id)
+	if err != nil {
+		return Query{}, err
+	}
+
+	// 构建基础的主键查询条件
 	query := Query{
 		Search: []ConditionGroup{
 			{
-				Conditions: [][]interface{}{{"id", id}},
+				Conditions: conditions,
 				Operator:   "AND",
 			},
 		},
@@ -135,6 +225,5 @@ func (q *DeleteBuilder[T]) DeleteByID(id interface{}, additionalQuery ...Query)
 		}
 	}
 
-	// 直接调用 Delete 方法，传入构建的查询条件
-	return q.Delete(query)
+	return query, nil
 }
@@ -1,12 +1,15 @@
 package db_provider
 
 import (
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -24,6 +27,17 @@ func (e DBError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// RetryClass 死锁、锁等待超时、序列化冲突和连接中断可重试，约束类错误不可重试，供 z.IsRetryable 判断
+func (e DBError) RetryClass() z.RetryClass {
+	switch e.Code {
+	case ErrCodeDeadlock, ErrCodeLockTimeout, ErrCodeSerialization, ErrCodeConnection:
+		return z.RetryRetryable
+	case ErrCodeDatabaseError:
+		return z.RetryUnknown
+	}
+	return z.RetryPermanent
+}
+
 // 错误代码常量
 const (
 	ErrCodeNotFound         = "RECORD_NOT_FOUND"
@@ -32,8 +46,34 @@ const (
 	ErrCodeInvalidData      = "INVALID_DATA"
 	ErrCodeDatabaseError    = "DATABASE_ERROR"
 	ErrCodeConstraintFailed = "CONSTRAINT_FAILED"
+	ErrCodeDeadlock         = "DEADLOCK"
+	ErrCodeLockTimeout      = "LOCK_WAIT_TIMEOUT"
+	ErrCodeSerialization    = "SERIALIZATION_FAILURE"
+	ErrCodeConnection       = "CONNECTION_ERROR"
+	ErrCodeVersionConflict  = "VERSION_CONFLICT"
 )
 
+// Status 返回业务状态码，便于 Failure(c, err, err.Status()) 输出
+func (e DBError) Status() z.Status {
+	switch e.Code {
+	case ErrCodeNotFound:
+		return z.StatusResourceNotFound
+	case ErrCodeDuplicate:
+		return z.StatusDuplicateEntry
+	case ErrCodeInvalidData:
+		return z.StatusDataValidation
+	case ErrCodeForeignKey, ErrCodeConstraintFailed:
+		return z.StatusDataConflict
+	case ErrCodeVersionConflict:
+		return z.StatusVersionConflict
+	case ErrCodeDeadlock, ErrCodeLockTimeout, ErrCodeSerialization:
+		return z.StatusResourceLocked
+	case ErrCodeConnection:
+		return z.StatusServiceUnavailable
+	}
+	return z.StatusDBError
+}
+
 // WrapDBError 包装数据库错误为用户友好的错误消息
 func WrapDBError(err error) error {
 	if err == nil {
@@ -48,11 +88,42 @@ func WrapDBError(err error) error {
 		}
 	}
 
+	// 已包装的错误直接返回，避免重复包装丢失错误代码
+	var dbErr DBError
+	if errors.As(err, &dbErr) {
+		return dbErr
+	}
+
+	// 连接中断
+	var pgConnErr *pgconn.ConnectError
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.As(err, &pgConnErr) {
+		return DBError{
+			Code:    ErrCodeConnection,
+			Message: "Database connection lost",
+		}
+	}
+
 	// 处理 MySQL 错误
 	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
 		return handleMySQLError(mysqlErr)
 	}
 
+	// 处理 PostgreSQL 错误（pgx）
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return handlePostgresError(pgErr)
+	}
+
+	// 开启 gorm TranslateError 后由方言转换的错误
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return DBError{Code: ErrCodeDuplicate, Message: "Duplicate entry detected"}
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return DBError{Code: ErrCodeForeignKey, Message: "Foreign key constraint violation"}
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return DBError{Code: ErrCodeConstraintFailed, Message: "Check constraint violation"}
+	}
+
 	// 处理字符串形式的 MySQL 错误（有时 GORM 会将错误转换为字符串）
 	errStr := err.Error()
 	if strings.Contains(errStr, "Error 1452") {
@@ -67,6 +138,18 @@ func WrapDBError(err error) error {
 	if strings.Contains(errStr, "Error 1406") {
 		return handleDataTooLongError(errStr)
 	}
+	if strings.Contains(errStr, "Error 1213") || strings.Contains(errStr, "SQLSTATE 40P01") {
+		return DBError{Code: ErrCodeDeadlock, Message: "Deadlock detected, please retry"}
+	}
+	if strings.Contains(errStr, "Error 1205") || strings.Contains(errStr, "SQLSTATE 55P03") {
+		return DBError{Code: ErrCodeLockTimeout, Message: "Lock wait timeout exceeded, please retry"}
+	}
+	if strings.Contains(errStr, "SQLSTATE 40001") {
+		return DBError{Code: ErrCodeSerialization, Message: "Transaction serialization failure, please retry"}
+	}
+	if dbErr, ok := handleSQLiteError(errStr); ok {
+		return dbErr
+	}
 
 	// 默认返回通用数据库错误
 	return DBError{
@@ -86,6 +169,16 @@ func handleMySQLError(mysqlErr *mysql.MySQLError) error {
 		return handleNullConstraintError(mysqlErr.Message)
 	case 1406: // Data too long for column
 		return handleDataTooLongError(mysqlErr.Message)
+	case 1213: // Deadlock found when trying to get lock
+		return DBError{
+			Code:    ErrCodeDeadlock,
+			Message: "Deadlock detected, please retry",
+		}
+	case 1205: // Lock wait timeout exceeded
+		return DBError{
+			Code:    ErrCodeLockTimeout,
+			Message: "Lock wait timeout exceeded, please retry",
+		}
 	case 1451: // Cannot delete or update a parent row
 		return DBError{
 			Code:    ErrCodeConstraintFailed,
@@ -99,6 +192,80 @@ func handleMySQLError(mysqlErr *mysql.MySQLError) error {
 	}
 }
 
+// handlePostgresError 处理 PostgreSQL 错误，按 SQLSTATE 映射
+func handlePostgresError(pgErr *pgconn.PgError) error {
+	switch pgErr.Code {
+	case "23505": // unique_violation
+		if m := pgKeyDetail.FindStringSubmatch(pgErr.Detail); len(m) > 2 {
+			if strings.HasSuffix(pgErr.ConstraintName, "_pkey") {
+				return DBError{Code: ErrCodeDuplicate, Message: "Record with this ID already exists", Field: m[1]}
+			}
+			return DBError{Code: ErrCodeDuplicate, Message: fmt.Sprintf("Value '%s' already exists", m[2]), Field: m[1]}
+		}
+		return DBError{Code: ErrCodeDuplicate, Message: "Duplicate entry detected"}
+	case "23503": // foreign_key_violation
+		// 删除或更新被引用的父记录
+		if strings.Contains(pgErr.Detail, "is still referenced") {
+			return DBError{
+				Code:    ErrCodeConstraintFailed,
+				Message: "Cannot delete record because it is referenced by other records",
+			}
+		}
+		if m := pgKeyDetail.FindStringSubmatch(pgErr.Detail); len(m) > 1 {
+			return DBError{Code: ErrCodeForeignKey, Message: fmt.Sprintf("Referenced %s does not exist", m[1]), Field: m[1]}
+		}
+		return DBError{Code: ErrCodeForeignKey, Message: "Foreign key constraint violation"}
+	case "23502": // not_null_violation
+		if pgErr.ColumnName != "" {
+			return DBError{Code: ErrCodeInvalidData, Message: fmt.Sprintf("Field %s is required", pgErr.ColumnName), Field: pgErr.ColumnName}
+		}
+		return DBError{Code: ErrCodeInvalidData, Message: "Required field is missing"}
+	case "22001": // string_data_right_truncation
+		return DBError{Code: ErrCodeInvalidData, Message: "Data exceeds maximum length", Field: pgErr.ColumnName}
+	case "23514": // check_violation
+		return DBError{Code: ErrCodeConstraintFailed, Message: "Check constraint violation"}
+	case "40P01": // deadlock_detected
+		return DBError{Code: ErrCodeDeadlock, Message: "Deadlock detected, please retry"}
+	case "55P03": // lock_not_available
+		return DBError{Code: ErrCodeLockTimeout, Message: "Lock wait timeout exceeded, please retry"}
+	case "40001": // serialization_failure
+		return DBError{Code: ErrCodeSerialization, Message: "Transaction serialization failure, please retry"}
+	}
+	if strings.HasPrefix(pgErr.Code, "08") { // connection_exception
+		return DBError{Code: ErrCodeConnection, Message: "Database connection lost"}
+	}
+	return DBError{Code: ErrCodeDatabaseError, Message: "Database operation failed"}
+}
+
+// pgKeyDetail 匹配 PostgreSQL 约束错误详情，如 Key (email)=(a@b.com) already exists.
+var pgKeyDetail = regexp.MustCompile(`Key \(([^)]+)\)=\((.*)\)`)
+
+// sqliteConstraint 匹配 SQLite 约束错误，如 UNIQUE constraint failed: users.email
+var sqliteConstraint = regexp.MustCompile(`(UNIQUE|NOT NULL) constraint failed: ([^\s,]+)`)
+
+// handleSQLiteError 处理 SQLite 错误，各驱动的错误类型不同，按错误消息识别
+func handleSQLiteError(errMsg string) (DBError, bool) {
+	if m := sqliteConstraint.FindStringSubmatch(errMsg); len(m) > 2 {
+		field := m[2]
+		if i := strings.LastIndex(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		if m[1] == "UNIQUE" {
+			return DBError{Code: ErrCodeDuplicate, Message: "Duplicate entry detected", Field: field}, true
+		}
+		return DBError{Code: ErrCodeInvalidData, Message: fmt.Sprintf("Field %s is required", field), Field: field}, true
+	}
+	switch {
+	case strings.Contains(errMsg, "FOREIGN KEY constraint failed"):
+		return DBError{Code: ErrCodeForeignKey, Message: "Foreign key constraint violation"}, true
+	case strings.Contains(errMsg, "CHECK constraint failed"):
+		return DBError{Code: ErrCodeConstraintFailed, Message: "Check constraint violation"}, true
+	case strings.Contains(errMsg, "database is locked"), strings.Contains(errMsg, "database table is locked"):
+		return DBError{Code: ErrCodeLockTimeout, Message: "Lock wait timeout exceeded, please retry"}, true
+	}
+	return DBError{}, false
+}
+
 // handleForeignKeyError 处理外键约束错误
 func handleForeignKeyError(errMsg string) error {
 	// 正则表达式匹配外键字段名
@@ -0,0 +1,122 @@
+package db_provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"gorm.io/gorm"
+)
+
+// ExistenceFilter 存在性过滤器，用于防止缓存穿透
+// MightContain 返回 false 时记录一定不存在，可直接跳过数据库查询
+// redis_provider.BloomFilter 和 MemoryExistenceFilter 均实现该接口
+type ExistenceFilter interface {
+	Add(ctx context.Context, key string) error
+	MightContain(ctx context.Context, key string) (bool, error)
+}
+
+// MemoryExistenceFilter 基于内存布隆过滤器的存在性过滤器，适用于单实例
+type MemoryExistenceFilter struct {
+	filter *z.BloomFilter
+}
+
+// NewMemoryExistenceFilter 创建内存存在性过滤器
+func NewMemoryExistenceFilter(expected uint, fpRate float64) *MemoryExistenceFilter {
+	return &MemoryExistenceFilter{filter: z.NewBloomFilter(expected, fpRate)}
+}
+
+// Add 添加主键
+func (f *MemoryExistenceFilter) Add(ctx context.Context, key string) error {
+	f.filter.Add(key)
+	return nil
+}
+
+// MightContain 判断主键是否可能存在
+func (f *MemoryExistenceFilter) MightContain(ctx context.Context, key string) (bool, error) {
+	return f.filter.MightContain(key), nil
+}
+
+// WarmExistenceFilter 将表中已有主键加载到过滤器，启用过滤器前必须预热，否则已有记录会被误判为不存在
+func WarmExistenceFilter[T IModel](ctx context.Context, db *DB, filter ExistenceFilter, batchSize int) error {
+	if db == nil || filter == nil {
+		return fmt.Errorf("db or filter is nil")
+	}
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// 与 addToFilter 一致，按模型 schema 解析主键列
+	var model T
+	stmt := &gorm.Statement{DB: db.DB}
+	if err := stmt.Parse(&model); err != nil {
+		return err
+	}
+	if len(stmt.Schema.PrimaryFields) > 1 {
+		return fmt.Errorf("existence filter does not support composite primary keys")
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return fmt.Errorf("existence filter requires a primary key")
+	}
+	pk := field.DBName
+
+	return db.WithContext(ctx).Model(&model).Select(pk).FindInBatches(&[]map[string]interface{}{}, batchSize, func(tx *gorm.DB, batch int) error {
+		rows, ok := tx.Statement.Dest.(*[]map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for _, row := range *rows {
+			if err := filter.Add(ctx, z.ToString(row[pk])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// mightExist 通过过滤器判断主键是否可能存在，过滤器异常时放行到数据库
+func mightExist(ctx context.Context, filter ExistenceFilter, id interface{}) bool {
+	if filter == nil {
+		return true
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ok, err := filter.MightContain(ctx, z.ToString(id))
+	if err != nil {
+		return true
+	}
+	return ok
+}
+
+// addToFilter 将新创建记录的主键写入过滤器
+func addToFilter(ctx context.Context, filter ExistenceFilter, db *gorm.DB, value interface{}) {
+	if filter == nil || db == nil || db.Statement == nil || db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	addOne := func(v reflect.Value) {
+		if id, zero := field.ValueOf(ctx, reflect.Indirect(v)); !zero {
+			_ = filter.Add(ctx, z.ToString(id))
+		}
+	}
+	if rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			addOne(rv.Index(i))
+		}
+		return
+	}
+	addOne(rv)
+}
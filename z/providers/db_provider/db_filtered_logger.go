@@ -3,6 +3,7 @@ package db_provider
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/gorm/logger"
@@ -13,32 +14,45 @@ import (
 // 仅压制 context.Canceled，保留真实慢查询与数据库错误日志，
 // 避免把前端主动取消请求、连接切换等正常行为误判为数据库异常。
 type FilteredGormLogger struct {
-	inner logger.Interface
+	base  logger.Interface
+	inner atomic.Pointer[gormLoggerBox]
+}
+
+// gormLoggerBox 包装 logger.Interface 以便原子替换
+type gormLoggerBox struct {
+	logger.Interface
 }
 
 // NewFilteredGormLogger 创建带 context canceled 过滤能力的 GORM Logger。
 func NewFilteredGormLogger(inner logger.Interface) logger.Interface {
-	return &FilteredGormLogger{inner: inner}
+	l := &FilteredGormLogger{base: inner}
+	l.inner.Store(&gormLoggerBox{inner})
+	return l
 }
 
 // LogMode 保持原始日志级别配置行为。
 func (l *FilteredGormLogger) LogMode(level logger.LogLevel) logger.Interface {
-	return &FilteredGormLogger{inner: l.inner.LogMode(level)}
+	return NewFilteredGormLogger(l.base.LogMode(level))
+}
+
+// SetLevel 运行时调整日志级别，对已创建的 gorm.DB 及其会话立即生效。
+func (l *FilteredGormLogger) SetLevel(level logger.LogLevel) {
+	l.inner.Store(&gormLoggerBox{l.base.LogMode(level)})
 }
 
 // Info 透传普通信息日志。
 func (l *FilteredGormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
-	l.inner.Info(ctx, msg, data...)
+	l.inner.Load().Info(ctx, msg, data...)
 }
 
 // Warn 透传警告日志。
 func (l *FilteredGormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
-	l.inner.Warn(ctx, msg, data...)
+	l.inner.Load().Warn(ctx, msg, data...)
 }
 
 // Error 透传错误日志。
 func (l *FilteredGormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
-	l.inner.Error(ctx, msg, data...)
+	l.inner.Load().Error(ctx, msg, data...)
 }
 
 // Trace 过滤因请求取消产生的 SQL 噪音，其他日志保持原样。
@@ -51,5 +65,5 @@ func (l *FilteredGormLogger) Trace(
 	if errors.Is(err, context.Canceled) {
 		return
 	}
-	l.inner.Trace(ctx, begin, fc, err)
+	l.inner.Load().Trace(ctx, begin, fc, err)
 }
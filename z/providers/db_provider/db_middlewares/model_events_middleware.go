@@ -0,0 +1,256 @@
+package db_middlewares
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/event_bus_provider"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 模型生命周期事件名称
+const (
+	EventModelCreated = "model.created"
+	EventModelUpdated = "model.updated"
+	EventModelDeleted = "model.deleted"
+)
+
+// ModelEvent 模型生命周期事件载荷
+type ModelEvent struct {
+	Table  string      `json:"table"`
+	Action string      `json:"action"` // created / updated / deleted
+	ID     string      `json:"id"`
+	Fields []string    `json:"fields,omitempty"` // 写入的列名，删除事件为空
+	Data   interface{} `json:"data,omitempty"`   // 写入的记录，删除事件为空；仅供进程内监听器使用，推送给客户端前需去除
+}
+
+// ModelEventsMiddleware 在创建、更新、删除成功后发布模型事件
+// 事务内的事件在提交后发布，回滚（含回滚到保存点）时丢弃；不在事务内的写入执行成功后立即发布
+// 仅能识别带主键值的记录，按条件批量更新/删除不会发布事件
+type ModelEventsMiddleware struct {
+	Bus *event_bus_provider.EventBus
+}
+
+func (m ModelEventsMiddleware) Apply(db *gorm.DB) error {
+	if m.Bus == nil {
+		return nil
+	}
+	// 包装连接池，使事务提交时能发布事务内收集的事件
+	if _, ok := db.ConnPool.(*modelEventsPool); !ok {
+		db.ConnPool = &modelEventsPool{ConnPool: db.ConnPool}
+		db.Statement.ConnPool = db.ConnPool
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("z:model_events_create", m.emit(EventModelCreated, "created")); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("z:model_events_update", m.emit(EventModelUpdated, "updated")); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("z:model_events_delete", m.emit(EventModelDeleted, "deleted"))
+}
+
+// pendingModelEvent 等待事务提交后发布的事件
+type pendingModelEvent struct {
+	ctx   context.Context
+	bus   *event_bus_provider.EventBus
+	name  string
+	event ModelEvent
+}
+
+func (e pendingModelEvent) publish() {
+	e.bus.EmitAsync(e.ctx, e.name, e.event)
+}
+
+// modelEventsPool 包装 gorm 连接池，开启的事务为 modelEventsTx
+type modelEventsPool struct {
+	gorm.ConnPool
+}
+
+func (p *modelEventsPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var (
+		tx  gorm.ConnPool
+		err error
+	)
+	switch beginner := p.ConnPool.(type) {
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &modelEventsTx{ConnPool: tx, savepoints: map[string]int{}}, nil
+}
+
+// GetDBConn 实现 gorm.GetDBConnector，使 gorm.DB.DB() 可取得底层连接池
+func (p *modelEventsPool) GetDBConn() (*sql.DB, error) {
+	return unwrapSQLDB(p.ConnPool)
+}
+
+// modelEventsTx 收集事务内的事件，提交成功后发布
+type modelEventsTx struct {
+	gorm.ConnPool
+
+	mu         sync.Mutex
+	events     []pendingModelEvent
+	savepoints map[string]int // 保存点名称 -> 创建保存点时已收集的事件数
+}
+
+func (t *modelEventsTx) add(e pendingModelEvent) {
+	t.mu.Lock()
+	t.events = append(t.events, e)
+	t.mu.Unlock()
+}
+
+// ExecContext 跟踪嵌套事务的保存点，回滚到保存点时丢弃其后收集的事件
+func (t *modelEventsTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := t.ConnPool.ExecContext(ctx, query, args...)
+	if err != nil {
+		return result, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if name, ok := strings.CutPrefix(query, "ROLLBACK TO SAVEPOINT "); ok {
+		if n, ok := t.savepoints[strings.TrimSpace(name)]; ok && n <= len(t.events) {
+			t.events = t.events[:n]
+		}
+	} else if name, ok := strings.CutPrefix(query, "SAVEPOINT "); ok {
+		t.savepoints[strings.TrimSpace(name)] = len(t.events)
+	}
+	return result, nil
+}
+
+func (t *modelEventsTx) Commit() error {
+	committer, ok := t.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		return gorm.ErrInvalidTransaction
+	}
+	if err := committer.Commit(); err != nil {
+		t.reset()
+		return err
+	}
+	t.mu.Lock()
+	events := t.events
+	t.events = nil
+	t.mu.Unlock()
+	for _, e := range events {
+		e.publish()
+	}
+	return nil
+}
+
+func (t *modelEventsTx) Rollback() error {
+	t.reset()
+	committer, ok := t.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		return gorm.ErrInvalidTransaction
+	}
+	return committer.Rollback()
+}
+
+func (t *modelEventsTx) reset() {
+	t.mu.Lock()
+	t.events = nil
+	t.mu.Unlock()
+}
+
+// GetDBConn 实现 gorm.GetDBConnector，使事务内的 gorm.DB.DB() 可取得底层连接池
+func (t *modelEventsTx) GetDBConn() (*sql.DB, error) {
+	return unwrapSQLDB(t.ConnPool)
+}
+
+func unwrapSQLDB(pool gorm.ConnPool) (*sql.DB, error) {
+	switch p := pool.(type) {
+	case *sql.DB:
+		return p, nil
+	case *sql.Tx:
+		return (&gorm.DB{Config: &gorm.Config{ConnPool: p}}).DB()
+	case gorm.GetDBConnector:
+		return p.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// writtenFields 返回语句写入的列名
+func writtenFields(stmt *gorm.Statement) []string {
+	var fields []string
+	if c, ok := stmt.Clauses["SET"]; ok {
+		if set, ok := c.Expression.(clause.Set); ok {
+			for _, a := range set {
+				fields = append(fields, a.Column.Name)
+			}
+		}
+	} else if c, ok := stmt.Clauses["VALUES"]; ok {
+		if values, ok := c.Expression.(clause.Values); ok {
+			for _, col := range values.Columns {
+				fields = append(fields, col.Name)
+			}
+		}
+	}
+	return fields
+}
+
+func (m ModelEventsMiddleware) emit(eventName, action string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		stmt := tx.Statement
+		if tx.Error != nil || tx.RowsAffected == 0 || stmt == nil || stmt.Schema == nil {
+			return
+		}
+		pk := stmt.Schema.PrioritizedPrimaryField
+		if pk == nil {
+			return
+		}
+		var fields []string
+		if action != "deleted" {
+			fields = writtenFields(stmt)
+		}
+		etx, inTx := stmt.ConnPool.(*modelEventsTx)
+
+		publish := func(rv reflect.Value) {
+			for rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					return
+				}
+				rv = rv.Elem()
+			}
+			if rv.Kind() != reflect.Struct {
+				return
+			}
+			id, zero := pk.ValueOf(stmt.Context, rv)
+			if zero {
+				return
+			}
+			event := ModelEvent{Table: stmt.Table, Action: action, ID: fmt.Sprint(id), Fields: fields}
+			if action != "deleted" {
+				// 提交后异步投递，复制一份记录避免调用方后续修改影响事件内容
+				data := reflect.New(rv.Type())
+				data.Elem().Set(rv)
+				event.Data = data.Interface()
+			}
+			pending := pendingModelEvent{ctx: stmt.Context, bus: m.Bus, name: eventName, event: event}
+			if inTx {
+				etx.add(pending)
+			} else {
+				pending.publish()
+			}
+		}
+
+		rv := stmt.ReflectValue
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				publish(rv.Index(i))
+			}
+		default:
+			publish(rv)
+		}
+	}
+}
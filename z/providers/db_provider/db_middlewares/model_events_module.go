@@ -0,0 +1,35 @@
+package db_middlewares
+
+import (
+	"github.com/icreateapp-com/go-zLib/z/providers/event_bus_provider"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+type ModelEventsIn struct {
+	fx.In
+	Bus *event_bus_provider.EventBus `optional:"true"`
+}
+
+type ModelEventsNamedOut struct {
+	fx.Out
+	Item NamedMiddleware `group:"db_named_middlewares"`
+}
+
+func NewModelEventsNamed(in ModelEventsIn) ModelEventsNamedOut {
+	return ModelEventsNamedOut{
+		Item: NamedMiddleware{
+			Name: "model_events",
+			New: func() Middleware {
+				m := ModelEventsMiddleware{Bus: in.Bus}
+				return func(db *gorm.DB) error { return m.Apply(db) }
+			},
+		},
+	}
+}
+
+var ModelEventsModule = fx.Options(
+	fx.Provide(
+		NewModelEventsNamed,
+	),
+)
@@ -0,0 +1,200 @@
+package db_provider
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"gorm.io/gorm"
+)
+
+// CountStrategy 分页总数统计策略
+type CountStrategy string
+
+const (
+	CountExact  CountStrategy = "exact"  // COUNT(*) 精确统计（默认）
+	CountApprox CountStrategy = "approx" // EXPLAIN 估算行数，失败时回退精确统计
+	CountNone   CountStrategy = "none"   // 不统计总数，通过多查一条判断是否有下一页
+)
+
+// ErrPageTooDeep 页码超过允许的最大深度
+var ErrPageTooDeep = DBError{Code: ErrCodeInvalidData, Message: "Page exceeds max depth", Field: "page"}
+
+// PageOptions 分页选项
+type PageOptions struct {
+	Count         CountStrategy // 总数统计策略
+	CountCacheTTL time.Duration // 总数缓存时长，<= 0 不缓存
+	MaxPage       int           // 最大页码，<= 0 不限制
+}
+
+// pageOptionsFromConfig 读取 db.page.* 配置
+func pageOptionsFromConfig(cfg *config_provider.Config) PageOptions {
+	return PageOptions{
+		Count:         CountStrategy(strings.ToLower(strings.TrimSpace(cfg.GetString("db.page.count", string(CountExact))))),
+		CountCacheTTL: cfg.GetDuration("db.page.count_cache_ttl", 0),
+		MaxPage:       cfg.GetInt("db.page.max_page", 0),
+	}
+}
+
+// pageOptions 返回生效的分页选项：构建器选项优先，其次为数据库全局配置
+func (q *QueryBuilder[T]) pageOptions() PageOptions {
+	if q.PageOptions != nil {
+		return *q.PageOptions
+	}
+	if q.DB != nil {
+		return q.DB.PageOptions
+	}
+	return PageOptions{}
+}
+
+// WithPageOptions 设置分页选项
+func (q *QueryBuilder[T]) WithPageOptions(opt PageOptions) *QueryBuilder[T] {
+	newBuilder := q.clone()
+	newBuilder.PageOptions = &opt
+	return newBuilder
+}
+
+// countCacheEntry 总数缓存条目
+type countCacheEntry struct {
+	total     int64
+	expiresAt time.Time
+}
+
+var (
+	countCacheMu sync.Mutex
+	countCache   = map[string]countCacheEntry{}
+)
+
+const countCacheMaxEntries = 1024
+
+func countCacheGet(key string) (int64, bool) {
+	countCacheMu.Lock()
+	defer countCacheMu.Unlock()
+	entry, ok := countCache[key]
+	if !ok {
+		return 0, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(countCache, key)
+		return 0, false
+	}
+	return entry.total, true
+}
+
+func countCacheSet(key string, total int64, ttl time.Duration) {
+	countCacheMu.Lock()
+	defer countCacheMu.Unlock()
+	now := time.Now()
+	if len(countCache) >= countCacheMaxEntries {
+		for k, entry := range countCache {
+			if now.After(entry.expiresAt) {
+				delete(countCache, k)
+			}
+		}
+		if len(countCache) >= countCacheMaxEntries {
+			countCache = map[string]countCacheEntry{}
+		}
+	}
+	countCache[key] = countCacheEntry{total: total, expiresAt: now.Add(ttl)}
+}
+
+// countCacheKey 以完整 SQL（含参数）作为缓存键
+func countCacheKey(db *gorm.DB, strategy CountStrategy) string {
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var total int64
+		return tx.Count(&total)
+	})
+	sum := sha1.Sum([]byte(string(strategy) + ":" + sql))
+	return hex.EncodeToString(sum[:])
+}
+
+// countTotal 按策略统计总数，返回总数及是否为估算值
+func countTotal(db *gorm.DB, opt PageOptions) (int64, bool, error) {
+	strategy := opt.Count
+	if strategy == "" {
+		strategy = CountExact
+	}
+
+	key := ""
+	if opt.CountCacheTTL > 0 {
+		key = countCacheKey(db, strategy)
+		if total, ok := countCacheGet(key); ok {
+			return total, strategy == CountApprox, nil
+		}
+	}
+
+	var total int64
+	approximate := false
+	if strategy == CountApprox {
+		if estimate, err := explainRows(db); err == nil {
+			total = estimate
+			approximate = true
+		}
+	}
+	if !approximate {
+		if err := db.Count(&total).Error; err != nil {
+			return 0, false, err
+		}
+	}
+
+	if key != "" {
+		countCacheSet(key, total, opt.CountCacheTTL)
+	}
+	return total, approximate, nil
+}
+
+// explainRows 通过 EXPLAIN 获取优化器估算的扫描行数（MySQL）
+func explainRows(db *gorm.DB) (int64, error) {
+	if db.Dialector.Name() != "mysql" {
+		return 0, errors.New("approximate count not supported")
+	}
+	stmt := db.Session(&gorm.Session{DryRun: true}).Select("*").Find(&[]map[string]interface{}{}).Statement
+	if stmt.SQL.Len() == 0 {
+		return 0, errors.New("empty statement")
+	}
+
+	var rows []map[string]interface{}
+	if err := db.Session(&gorm.Session{NewDB: true}).Raw("EXPLAIN "+stmt.SQL.String(), stmt.Vars...).Scan(&rows).Error; err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, errors.New("empty explain result")
+	}
+	value, ok := rows[0]["rows"]
+	if !ok || value == nil {
+		return 0, errors.New("explain result has no rows column")
+	}
+	switch v := value.(type) {
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	default:
+		return strconv.ParseInt(fmt.Sprint(v), 10, 64)
+	}
+}
+
+// trimPageData 去掉多查的一条记录，返回是否存在下一页
+func trimPageData(data interface{}, limit int) bool {
+	rv := reflect.ValueOf(data)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice || rv.Len() <= limit {
+		return false
+	}
+	if rv.CanSet() {
+		rv.Set(rv.Slice(0, limit))
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package db_provider
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// includeEntry 单个关联路径的预加载设置，columns 为空表示查询全部字段
+type includeEntry struct {
+	path     string
+	parent   string
+	rel      *schema.Relationship
+	columns  []string
+	explicit bool
+}
+
+// splitInclude 拆分 include 项，格式为 relation[.nested][:col1,col2]
+func splitInclude(include string) (relation string, columns []string) {
+	relation, cols, _ := strings.Cut(strings.TrimSpace(include), ":")
+	relation = strings.TrimSpace(relation)
+	for _, col := range strings.Split(cols, ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			columns = append(columns, col)
+		}
+	}
+	return relation, columns
+}
+
+// ParseInclude 解析关联预加载，支持嵌套关联（orders.items）与按关联选择字段（orders:id,total）
+// 关联名不区分大小写，也可使用蛇形命名；选择字段时自动补充主键与关联所需的外键
+func ParseInclude(db *gorm.DB, include []string) (*gorm.DB, error) {
+	if len(include) == 0 {
+		return db, nil
+	}
+	if db.Statement.Model == nil {
+		return nil, invalidQuery("include", "include requires a model")
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(db.Statement.Model); err != nil {
+		return nil, err
+	}
+
+	entries := map[string]*includeEntry{}
+	var order []string
+	for i, item := range include {
+		relation, columns := splitInclude(item)
+		if relation == "" {
+			return nil, invalidQuery("include", "empty include at %d", i)
+		}
+		current := stmt.Schema
+		path := ""
+		segments := strings.Split(relation, ".")
+		for j, segment := range segments {
+			rel := lookupRelation(db, current, segment)
+			if rel == nil {
+				return nil, invalidQuery("include", "unknown relation: %s", relation)
+			}
+			parent := path
+			if path == "" {
+				path = rel.Name
+			} else {
+				path += "." + rel.Name
+			}
+			entry, ok := entries[path]
+			if !ok {
+				entry = &includeEntry{path: path, parent: parent, rel: rel}
+				entries[path] = entry
+				order = append(order, path)
+			}
+			current = rel.FieldSchema
+
+			if j < len(segments)-1 {
+				continue
+			}
+			// 同一关联出现多次时合并字段，任一项未指定字段则查询全部字段
+			if len(columns) == 0 {
+				entry.columns = nil
+			} else if !entry.explicit || len(entry.columns) > 0 {
+				for _, col := range columns {
+					field := lookupColumn(current, col)
+					if field == nil {
+						return nil, invalidQuery("include", "unknown column %s of relation %s", col, relation)
+					}
+					entry.columns = appendColumn(entry.columns, field.DBName)
+				}
+			}
+			entry.explicit = true
+		}
+	}
+
+	// 补充主键与关联键，否则 gorm 无法将预加载结果回填到父记录
+	for _, path := range order {
+		entry := entries[path]
+		if len(entry.columns) == 0 {
+			continue
+		}
+		for _, name := range entry.rel.FieldSchema.PrimaryFieldDBNames {
+			entry.columns = appendColumn(entry.columns, name)
+		}
+		entry.columns = appendRelationKeys(entry.columns, entry.rel, entry.rel.FieldSchema)
+	}
+	for _, path := range order {
+		entry := entries[path]
+		if parent, ok := entries[entry.parent]; ok && len(parent.columns) > 0 {
+			parent.columns = appendRelationKeys(parent.columns, entry.rel, entry.rel.Schema)
+		}
+	}
+
+	for _, path := range order {
+		entry := entries[path]
+		if !entry.explicit {
+			continue
+		}
+		if len(entry.columns) == 0 {
+			db = db.Preload(path)
+			continue
+		}
+		columns := entry.columns
+		db = db.Preload(path, func(tx *gorm.DB) *gorm.DB {
+			return tx.Select(columns)
+		})
+	}
+	return db, nil
+}
+
+// lookupRelation 按字段名（不区分大小写）或蛇形命名查找关联
+func lookupRelation(db *gorm.DB, s *schema.Schema, name string) *schema.Relationship {
+	if s == nil {
+		return nil
+	}
+	for relName, rel := range s.Relationships.Relations {
+		if strings.EqualFold(relName, name) || db.NamingStrategy.ColumnName("", relName) == name {
+			return rel
+		}
+	}
+	return nil
+}
+
+// lookupColumn 按列名或字段名查找可查询的字段
+func lookupColumn(s *schema.Schema, name string) *schema.Field {
+	if !isValidFieldName(name) || strings.Contains(name, ".") {
+		return nil
+	}
+	if field, ok := s.FieldsByDBName[name]; ok {
+		return field
+	}
+	if field, ok := s.FieldsByName[name]; ok && field.DBName != "" && field.Readable {
+		return field
+	}
+	return nil
+}
+
+// appendRelationKeys 追加关联在 owner 表上的主键 / 外键列
+func appendRelationKeys(columns []string, rel *schema.Relationship, owner *schema.Schema) []string {
+	for _, ref := range rel.References {
+		if ref.ForeignKey != nil && ref.ForeignKey.Schema == owner {
+			columns = appendColumn(columns, ref.ForeignKey.DBName)
+		}
+		if ref.PrimaryKey != nil && ref.PrimaryKey.Schema == owner {
+			columns = appendColumn(columns, ref.PrimaryKey.DBName)
+		}
+	}
+	return columns
+}
+
+func appendColumn(columns []string, column string) []string {
+	for _, c := range columns {
+		if c == column {
+			return columns
+		}
+	}
+	return append(columns, column)
+}
@@ -83,6 +83,20 @@ func ParseSearch(db *gorm.DB, search []ConditionGroup, required []string) (*gorm
 		}
 	}
 
+	whereClause, values, err := buildConditionClause(search, allowEmptyStringFields)
+	if err != nil {
+		return nil, err
+	}
+	if whereClause != "" {
+		db = db.Where(whereClause, values...)
+	}
+
+	return db, nil
+}
+
+// buildConditionClause 将条件组构建为 SQL 片段及参数，供 WHERE 与 HAVING 使用
+// 组内按组操作符连接，组间以 AND 连接；值为 nil 或空字符串的条件被跳过（allowEmptyStringFields 中的字段除外）
+func buildConditionClause(search []ConditionGroup, allowEmptyStringFields map[string]bool) (string, []interface{}, error) {
 	var conditions []string
 	var values []interface{}
 
@@ -96,17 +110,17 @@ func ParseSearch(db *gorm.DB, search []ConditionGroup, required []string) (*gorm
 
 		for _, condition := range group.Conditions {
 			if len(condition) < 2 {
-				return nil, errors.New("invalid condition: each condition must have at least 2 elements")
+				return "", nil, errors.New("invalid condition: each condition must have at least 2 elements")
 			}
 
 			// 安全的类型断言
 			field, ok := condition[0].(string)
 			if !ok {
-				return nil, errors.New("invalid condition: field must be string")
+				return "", nil, errors.New("invalid condition: field must be string")
 			}
 
 			if !isValidFieldName(field) {
-				return nil, errors.New("invalid field name: " + field)
+				return "", nil, errors.New("invalid field name: " + field)
 			}
 
 			value := condition[1]
@@ -133,7 +147,7 @@ func ParseSearch(db *gorm.DB, search []ConditionGroup, required []string) (*gorm
 
 			// 验证操作符
 			if !isValidOperator(operator) {
-				return nil, fmt.Errorf("invalid operator: '%s' is not a valid operator", operator)
+				return "", nil, fmt.Errorf("invalid operator: '%s' is not a valid operator", operator)
 			}
 
 			// 处理特殊的 like 操作符
@@ -178,13 +192,8 @@ func ParseSearch(db *gorm.DB, search []ConditionGroup, required []string) (*gorm
 		conditions = append(conditions, fmt.Sprintf("(%s)", groupClause))
 	}
 
-	if len(conditions) > 0 {
-		// 组间条件用 AND 连接
-		whereClause := strings.Join(conditions, " AND ")
-		db = db.Where(whereClause, values...)
-	}
-
-	return db, nil
+	// 组间条件用 AND 连接
+	return strings.Join(conditions, " AND "), values, nil
 }
 
 // isValidOperator 验证操作符是否有效
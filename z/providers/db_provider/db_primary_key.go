@@ -0,0 +1,115 @@
+package db_provider
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// IPrimaryKey 自定义主键列名，未实现时按 gorm primaryKey 标签识别，默认为 id
+type IPrimaryKey interface {
+	PrimaryKey() string
+}
+
+// ICompositePrimaryKey 复合主键列名，按顺序与 Find 等方法传入的主键值对应
+type ICompositePrimaryKey interface {
+	PrimaryKeys() []string
+}
+
+// primaryKeyCache 模型类型 -> 主键列名
+var primaryKeyCache sync.Map
+
+// PrimaryKeyColumns 返回模型的主键列名
+// 优先级：PrimaryKeys() > PrimaryKey() > gorm primaryKey 标签 > id
+func PrimaryKeyColumns[T any](db *gorm.DB) []string {
+	var zero T
+	typ := reflect.TypeOf(&zero)
+	if cached, ok := primaryKeyCache.Load(typ); ok {
+		return cached.([]string)
+	}
+
+	var columns []string
+	switch m := any(&zero).(type) {
+	case ICompositePrimaryKey:
+		columns = m.PrimaryKeys()
+	case IPrimaryKey:
+		columns = []string{m.PrimaryKey()}
+	}
+	if len(columns) == 0 {
+		if m, ok := any(zero).(ICompositePrimaryKey); ok {
+			columns = m.PrimaryKeys()
+		} else if m, ok := any(zero).(IPrimaryKey); ok {
+			columns = []string{m.PrimaryKey()}
+		}
+	}
+	if len(columns) == 0 && db != nil {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(&zero); err == nil && stmt.Schema != nil {
+			columns = append(columns, stmt.Schema.PrimaryFieldDBNames...)
+		}
+	}
+	if len(columns) == 0 {
+		// 未能解析模型结构时不缓存，避免后续带连接的调用也拿到默认值
+		if db == nil {
+			return []string{"id"}
+		}
+		columns = []string{"id"}
+	}
+
+	primaryKeyCache.Store(typ, columns)
+	return columns
+}
+
+// builderDB 返回构建器使用的连接，用于解析模型结构
+func builderDB(db *DB, tx *gorm.DB) *gorm.DB {
+	if tx != nil {
+		return tx
+	}
+	if db != nil {
+		return db.DB
+	}
+	return nil
+}
+
+// primaryKeyConditions 构建主键查询条件
+// 单主键直接传值；复合主键传 map[列名]值，或按列顺序传切片
+func primaryKeyConditions(columns []string, id interface{}) ([][]interface{}, error) {
+	if id == nil || id == "" {
+		return nil, errors.New("id cannot be empty")
+	}
+	if len(columns) == 1 {
+		return [][]interface{}{{columns[0], id}}, nil
+	}
+
+	values := make([]interface{}, len(columns))
+	switch v := id.(type) {
+	case map[string]interface{}:
+		for i, col := range columns {
+			value, ok := v[col]
+			if !ok || value == nil {
+				return nil, fmt.Errorf("missing primary key value: %s", col)
+			}
+			values[i] = value
+		}
+	default:
+		rv := reflect.ValueOf(id)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, fmt.Errorf("composite primary key requires %d values", len(columns))
+		}
+		if rv.Len() != len(columns) {
+			return nil, fmt.Errorf("composite primary key requires %d values, got %d", len(columns), rv.Len())
+		}
+		for i := range columns {
+			values[i] = rv.Index(i).Interface()
+		}
+	}
+
+	conditions := make([][]interface{}, 0, len(columns))
+	for i, col := range columns {
+		conditions = append(conditions, []interface{}{col, values[i]})
+	}
+	return conditions, nil
+}
@@ -0,0 +1,134 @@
+package db_provider
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultBatchSize Batches 默认每批条数
+const DefaultBatchSize = 500
+
+// Batches 按主键游标（keyset）分批查询并回调，每批最多 size 条，size <= 0 时使用 DefaultBatchSize
+// 结果按主键升序返回，忽略 orderby、page、limit 参数；单主键时以开始时的最大主键为上界，遍历期间新增的记录不会出现
+// 每批为独立查询，不持有长事务或游标，回调返回错误时停止并返回该错误
+func (q *QueryBuilder[T]) Batches(size int, fn func(rows []T) error) error {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	db := q.getDBWithModel()
+	if db == nil {
+		return WrapDBError(errors.New("database not initialized"))
+	}
+	columns := PrimaryKeyColumns[T](db)
+	fields, err := keysetFields[T](db, columns)
+	if err != nil {
+		return WrapDBError(err)
+	}
+
+	search := Query{Search: q.Query.Search, Required: q.Query.Required, Trashed: q.Query.Trashed}
+	var upper interface{}
+	if len(columns) == 1 {
+		if upper, err = q.keysetUpperBound(search, columns[0]); err != nil {
+			return err
+		}
+		if upper == nil {
+			return nil
+		}
+	}
+
+	var last []interface{}
+	for {
+		parsedDB, err := ParseQuery(search, q.getDBWithModel())
+		if err != nil {
+			return WrapDBError(err)
+		}
+		if upper != nil {
+			parsedDB = parsedDB.Where(clause.Lte{Column: clause.Column{Name: columns[0]}, Value: upper})
+		}
+		if last != nil {
+			parsedDB = parsedDB.Where(keysetAfter(columns, last))
+		}
+		for _, col := range columns {
+			parsedDB = parsedDB.Order(clause.OrderByColumn{Column: clause.Column{Name: col}})
+		}
+
+		var rows []T
+		if err := parsedDB.Limit(size).Find(&rows).Error; err != nil {
+			return WrapDBError(err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		if len(rows) < size {
+			return nil
+		}
+
+		ctx := parsedDB.Statement.Context
+		row := reflect.Indirect(reflect.ValueOf(&rows[len(rows)-1]))
+		last = make([]interface{}, len(fields))
+		for i, field := range fields {
+			last[i], _ = field.ValueOf(ctx, row)
+		}
+	}
+}
+
+// keysetUpperBound 查询开始时满足条件的最大主键，无记录时返回 nil
+func (q *QueryBuilder[T]) keysetUpperBound(search Query, column string) (interface{}, error) {
+	parsedDB, err := ParseQuery(search, q.getDBWithModel())
+	if err != nil {
+		return nil, WrapDBError(err)
+	}
+	var upper interface{}
+	row := parsedDB.Select(fmt.Sprintf("MAX(%s)", parsedDB.Statement.Quote(column))).Row()
+	if err := row.Scan(&upper); err != nil {
+		return nil, WrapDBError(err)
+	}
+	if b, ok := upper.([]byte); ok {
+		upper = string(b)
+	}
+	return upper, nil
+}
+
+// keysetFields 解析主键列对应的模型字段，用于读取每批最后一条记录的主键值
+func keysetFields[T any](db *gorm.DB, columns []string) ([]*schema.Field, error) {
+	var zero T
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&zero); err != nil {
+		return nil, err
+	}
+	fields := make([]*schema.Field, 0, len(columns))
+	for _, col := range columns {
+		field := stmt.Schema.LookUpField(col)
+		if field == nil {
+			return nil, fmt.Errorf("primary key field not found: %s", col)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// keysetAfter 构建大于上一批最后主键的条件，复合主键展开为 (a > ?) OR (a = ? AND b > ?)
+func keysetAfter(columns []string, last []interface{}) clause.Expression {
+	if len(columns) == 1 {
+		return clause.Gt{Column: clause.Column{Name: columns[0]}, Value: last[0]}
+	}
+	ors := make([]clause.Expression, 0, len(columns))
+	for i := range columns {
+		ands := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, clause.Eq{Column: clause.Column{Name: columns[j]}, Value: last[j]})
+		}
+		ands = append(ands, clause.Gt{Column: clause.Column{Name: columns[i]}, Value: last[i]})
+		ors = append(ors, clause.And(ands...))
+	}
+	return clause.Or(ors...)
+}
@@ -3,7 +3,6 @@ package db_provider
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"gorm.io/gorm"
 )
@@ -27,7 +26,10 @@ type QueryBuilder[T any] struct {
 	Query         Query           // 查询参数
 	Model         interface{}     // 显式设置查询模型
 	Context       context.Context // 上下文
+	Filter        ExistenceFilter // 存在性过滤器（可选），用于 Find 防止缓存穿透
+	PageOptions   *PageOptions    // 分页选项（可选），为空时使用数据库全局配置
 	rawConditions []rawCondition  // 原生条件
+	scopes        []Scope         // 查询作用域
 }
 
 // SetModel 设置查询模型
@@ -43,6 +45,13 @@ func (q *QueryBuilder[T]) WithContext(ctx context.Context) *QueryBuilder[T] {
 	return newBuilder
 }
 
+// WithFilter 设置存在性过滤器，Find 查询一定不存在的主键时直接返回记录不存在
+func (q *QueryBuilder[T]) WithFilter(filter ExistenceFilter) *QueryBuilder[T] {
+	newBuilder := q.clone()
+	newBuilder.Filter = filter
+	return newBuilder
+}
+
 // Where 添加 WHERE 条件
 func (q *QueryBuilder[T]) Where(query string, args ...interface{}) *QueryBuilder[T] {
 	newBuilder := q.clone()
@@ -53,6 +62,27 @@ func (q *QueryBuilder[T]) Where(query string, args ...interface{}) *QueryBuilder
 	return newBuilder
 }
 
+// Scopes 添加查询作用域，如 Scopes.Active()、Scopes.Search(...)
+func (q *QueryBuilder[T]) Scopes(scopes ...Scope) *QueryBuilder[T] {
+	newBuilder := q.clone()
+	newBuilder.scopes = append(newBuilder.scopes, scopes...)
+	return newBuilder
+}
+
+// WithTrashed 查询结果包含已软删除的记录
+func (q *QueryBuilder[T]) WithTrashed() *QueryBuilder[T] {
+	newBuilder := q.clone()
+	newBuilder.Query.Trashed = TrashedWith
+	return newBuilder
+}
+
+// OnlyTrashed 仅查询已软删除的记录
+func (q *QueryBuilder[T]) OnlyTrashed() *QueryBuilder[T] {
+	newBuilder := q.clone()
+	newBuilder.Query.Trashed = TrashedOnly
+	return newBuilder
+}
+
 // clone 克隆 QueryBuilder 实例
 func (q *QueryBuilder[T]) clone() *QueryBuilder[T] {
 	newBuilder := &QueryBuilder[T]{
@@ -61,6 +91,11 @@ func (q *QueryBuilder[T]) clone() *QueryBuilder[T] {
 		Query:   q.Query,
 		Model:   q.Model,
 		Context: q.Context,
+		Filter:  q.Filter,
+	}
+	if q.PageOptions != nil {
+		opt := *q.PageOptions
+		newBuilder.PageOptions = &opt
 	}
 
 	// 深拷贝 rawConditions
@@ -68,16 +103,22 @@ func (q *QueryBuilder[T]) clone() *QueryBuilder[T] {
 		newBuilder.rawConditions = make([]rawCondition, len(q.rawConditions))
 		copy(newBuilder.rawConditions, q.rawConditions)
 	}
+	if len(q.scopes) > 0 {
+		newBuilder.scopes = append([]Scope(nil), q.scopes...)
+	}
 
 	return newBuilder
 }
 
 // Pager 分页信息
+// 统计策略为 none 时 Total 与 LastPage 为 -1，通过 HasNext 判断是否有下一页
 type Pager struct {
-	CurrentPage int `json:"current_page"` // 当前页码
-	Total       int `json:"total"`        // 总记录数
-	LastPage    int `json:"last_page"`    // 最后一页
-	Data        any `json:"data"`         // 分页数据
+	CurrentPage int  `json:"current_page"`          // 当前页码
+	Total       int  `json:"total"`                 // 总记录数
+	LastPage    int  `json:"last_page"`             // 最后一页
+	HasNext     bool `json:"has_next"`              // 是否有下一页
+	Approximate bool `json:"approximate,omitempty"` // 总数是否为估算值
+	Data        any  `json:"data"`                  // 分页数据
 }
 
 // getDB 获取数据库连接（支持事务）
@@ -115,6 +156,11 @@ func (q *QueryBuilder[T]) getDBWithModel() *gorm.DB {
 		db = db.Where(condition.query, condition.args...)
 	}
 
+	// 应用查询作用域
+	if len(q.scopes) > 0 {
+		db = db.Scopes(q.scopes...)
+	}
+
 	return db
 }
 
@@ -141,6 +187,40 @@ func (q *QueryBuilder[T]) Get(dest interface{}) error {
 	return nil
 }
 
+// Each 逐行扫描查询结果并回调，不在内存中构建完整结果集（忽略 page 参数，不支持 Preload）
+// 回调返回错误时停止扫描并返回该错误
+func (q *QueryBuilder[T]) Each(fn func(row *T) error) error {
+	query := q.Query
+	query.Page = 0
+
+	db := q.getDBWithModel()
+	if db == nil {
+		return WrapDBError(errors.New("database not initialized"))
+	}
+
+	parsedDB, err := ParseQuery(query, db)
+	if err != nil {
+		return WrapDBError(err)
+	}
+
+	rows, err := parsedDB.Rows()
+	if err != nil {
+		return WrapDBError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := parsedDB.ScanRows(rows, &row); err != nil {
+			return WrapDBError(err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return WrapDBError(rows.Err())
+}
+
 // Page 查询多条记录（带分页）
 func (q *QueryBuilder[T]) Page(pager *Pager, dest ...interface{}) error {
 	query := q.Query
@@ -153,33 +233,46 @@ func (q *QueryBuilder[T]) Page(pager *Pager, dest ...interface{}) error {
 		query.Limit = DefaultPageSize
 	}
 
-	// 先获取总数（使用独立的数据库连接，不包含 Preload 和分页）
-	countBuilder := &QueryBuilder[T]{
-		DB:            q.DB,
-		Query:         Query{Search: query.Search, Required: query.Required},
-		Model:         q.Model,
-		Context:       q.Context,
-		rawConditions: q.rawConditions,
-	}
-	countDB := countBuilder.getDBWithModel()
-	if countDB == nil {
-		return WrapDBError(errors.New("database not initialized"))
+	opt := q.pageOptions()
+	if opt.MaxPage > 0 && query.Page > opt.MaxPage {
+		return ErrPageTooDeep
 	}
+	limit := query.Limit
 
-	countParsedDB, err := ParseQuery(Query{
-		Search:   query.Search,
-		Required: query.Required,
-	}, countDB)
-	if err != nil {
-		return WrapDBError(err)
-	}
+	// 先获取总数（使用独立的查询，不包含 Preload 和分页；事务内与分页数据使用同一事务）
+	total := int64(-1)
+	approximate := false
+	if opt.Count != CountNone {
+		countBuilder := &QueryBuilder[T]{
+			DB:            q.DB,
+			TX:            q.TX,
+			Query:         Query{Search: query.Search, Required: query.Required, Trashed: query.Trashed},
+			Model:         q.Model,
+			Context:       q.Context,
+			rawConditions: q.rawConditions,
+			scopes:        q.scopes,
+		}
+		countDB := countBuilder.getDBWithModel()
+		if countDB == nil {
+			return WrapDBError(errors.New("database not initialized"))
+		}
 
-	var total int64
-	if err := countParsedDB.Count(&total).Error; err != nil {
-		return WrapDBError(err)
+		countParsedDB, err := ParseQuery(Query{
+			Search:   query.Search,
+			Required: query.Required,
+			Trashed:  query.Trashed,
+		}, countDB)
+		if err != nil {
+			return WrapDBError(err)
+		}
+
+		total, approximate, err = countTotal(countParsedDB, opt)
+		if err != nil {
+			return WrapDBError(err)
+		}
 	}
 
-	// 再获取分页数据
+	// 再获取分页数据；不统计总数时多查一条用于判断是否有下一页
 	modelDB := q.getDBWithModel()
 	if modelDB == nil {
 		return WrapDBError(errors.New("database not initialized"))
@@ -188,38 +281,48 @@ func (q *QueryBuilder[T]) Page(pager *Pager, dest ...interface{}) error {
 	if err != nil {
 		return WrapDBError(err)
 	}
+	if opt.Count == CountNone {
+		dataDB = dataDB.Limit(limit + 1)
+	}
 
 	// 支持自定义数据
+	var data interface{}
 	if len(dest) > 0 {
-		data := dest[0]
+		data = dest[0]
 		if err := dataDB.Find(data).Error; err != nil {
 			return WrapDBError(err)
 		}
-		pager.Data = data
 	} else {
-		var data []T
-		if err := dataDB.Find(&data).Error; err != nil {
+		var rows []T
+		if err := dataDB.Find(&rows).Error; err != nil {
 			return WrapDBError(err)
 		}
-		pager.Data = data
+		data = &rows
 	}
 
 	// 计算分页信息
-	limit := query.Limit
-	if limit <= 0 {
-		limit = DefaultPageSize
+	pager.CurrentPage = query.Page
+	pager.Approximate = approximate
+	if opt.Count == CountNone {
+		pager.HasNext = trimPageData(data, limit)
+		pager.Total = -1
+		pager.LastPage = -1
+	} else {
+		lastPage := int((total + int64(limit) - 1) / int64(limit))
+		if lastPage == 0 {
+			lastPage = 1
+		}
+		pager.Total = int(total)
+		pager.LastPage = lastPage
+		pager.HasNext = query.Page < lastPage
 	}
 
-	lastPage := int((total + int64(limit) - 1) / int64(limit))
-	if lastPage == 0 {
-		lastPage = 1
+	if len(dest) > 0 {
+		pager.Data = data
+	} else {
+		pager.Data = *(data.(*[]T))
 	}
 
-	// 设置分页信息
-	pager.CurrentPage = query.Page
-	pager.Total = int(total)
-	pager.LastPage = lastPage
-
 	return nil
 }
 
@@ -251,17 +354,23 @@ func (q *QueryBuilder[T]) Find(id interface{}, dest interface{}) error {
 		copy(newQuery.Search, q.Query.Search)
 	}
 
-	if id == nil || id == "" {
-		return errors.New("id cannot be empty")
+	columns := PrimaryKeyColumns[T](q.getDB())
+	conditions, err := primaryKeyConditions(columns, id)
+	if err != nil {
+		return err
 	}
 
-	// 将 ID 条件添加到查询中
+	// 过滤器判定一定不存在时直接返回，避免穿透到数据库（过滤器仅记录单主键）
+	if len(columns) == 1 && !mightExist(q.Context, q.Filter, id) {
+		return WrapDBError(gorm.ErrRecordNotFound)
+	}
+
+	// 将主键条件添加到查询中
 	if newQuery.Search == nil {
 		newQuery.Search = []ConditionGroup{}
 	}
-	// 添加 ID 查询条件
 	idCondition := ConditionGroup{
-		Conditions: [][]interface{}{{"id", id, "="}},
+		Conditions: conditions,
 	}
 	newQuery.Search = append(newQuery.Search, idCondition)
 
@@ -287,6 +396,7 @@ func (q *QueryBuilder[T]) Count() (int64, error) {
 	countQuery := Query{
 		Search:   query.Search,
 		Required: query.Required,
+		Trashed:  query.Trashed,
 	}
 
 	parsedDB, err := ParseQuery(countQuery, db)
@@ -304,62 +414,12 @@ func (q *QueryBuilder[T]) Count() (int64, error) {
 
 // Sum 计算字段总和
 func (q *QueryBuilder[T]) Sum(field string) (float64, error) {
-	query := q.Query
-
-	// 验证字段名安全性
-	if !isValidFieldName(field) {
-		return 0, errors.New("invalid field name: " + field)
-	}
-
-	db := q.getDBWithModel()
-
-	// 只解析搜索条件
-	sumQuery := Query{
-		Search:   query.Search,
-		Required: query.Required,
-	}
-
-	parsedDB, err := ParseQuery(sumQuery, db)
-	if err != nil {
-		return 0, WrapDBError(err)
-	}
-
-	var sum float64
-	if err := parsedDB.Select(fmt.Sprintf("COALESCE(SUM(%s), 0) as sum", field)).Row().Scan(&sum); err != nil {
-		return 0, WrapDBError(err)
-	}
-
-	return sum, nil
+	return q.aggregateValue(AggSum, field)
 }
 
 // Avg 计算字段平均值
 func (q *QueryBuilder[T]) Avg(field string) (float64, error) {
-	query := q.Query
-
-	// 验证字段名安全性
-	if !isValidFieldName(field) {
-		return 0, errors.New("invalid field name: " + field)
-	}
-
-	db := q.getDBWithModel()
-
-	// 只解析搜索条件
-	avgQuery := Query{
-		Search:   query.Search,
-		Required: query.Required,
-	}
-
-	parsedDB, err := ParseQuery(avgQuery, db)
-	if err != nil {
-		return 0, WrapDBError(err)
-	}
-
-	var avg float64
-	if err := parsedDB.Select(fmt.Sprintf("COALESCE(AVG(%s), 0) as avg", field)).Row().Scan(&avg); err != nil {
-		return 0, WrapDBError(err)
-	}
-
-	return avg, nil
+	return q.aggregateValue(AggAvg, field)
 }
 
 // Exists 检查记录是否存在
@@ -374,15 +434,21 @@ func (q *QueryBuilder[T]) Exists() (bool, error) {
 
 // ExistsById 通过主键检查记录是否存在
 func (q *QueryBuilder[T]) ExistsById(id interface{}) (bool, error) {
-	if id == nil {
-		return false, errors.New("id cannot be empty")
+	columns := PrimaryKeyColumns[T](q.getDB())
+	conditions, err := primaryKeyConditions(columns, id)
+	if err != nil {
+		return false, err
+	}
+	if len(columns) == 1 && !mightExist(q.Context, q.Filter, id) {
+		return false, nil
 	}
 	query := Query{
 		Search: []ConditionGroup{
 			{
-				Conditions: [][]interface{}{{"id", id}},
+				Conditions: conditions,
 			},
 		},
+		Trashed: q.Query.Trashed,
 	}
 	newBuilder := &QueryBuilder[T]{
 		DB:            q.DB,
@@ -0,0 +1,89 @@
+package db_provider
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ToSQL 返回 Get 将执行的 SQL（? 占位符）与参数，不访问数据库
+// 已注册的查询回调（如租户、软删除条件）同样生效，结果与实际执行一致
+func (q *QueryBuilder[T]) ToSQL() (string, []interface{}, error) {
+	query := q.Query
+	query.Page = 0
+	return q.dryRun(query, 0)
+}
+
+// ToPageSQL 返回 Page 获取分页数据将执行的 SQL 与参数，不访问数据库
+func (q *QueryBuilder[T]) ToPageSQL() (string, []interface{}, error) {
+	query := q.Query
+	if query.Page <= 0 {
+		query.Page = DefaultPage
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+
+	opt := q.pageOptions()
+	if opt.MaxPage > 0 && query.Page > opt.MaxPage {
+		return "", nil, ErrPageTooDeep
+	}
+	extra := 0
+	if opt.Count == CountNone {
+		extra = query.Limit + 1
+	}
+	return q.dryRun(query, extra)
+}
+
+// ToCountSQL 返回 Count 及 Page 统计总数将执行的 SQL 与参数，不访问数据库
+func (q *QueryBuilder[T]) ToCountSQL() (string, []interface{}, error) {
+	db := q.getDBWithModel()
+	if db == nil {
+		return "", nil, WrapDBError(errors.New("database not initialized"))
+	}
+	parsedDB, err := ParseQuery(Query{Search: q.Query.Search, Required: q.Query.Required, Trashed: q.Query.Trashed}, db)
+	if err != nil {
+		return "", nil, WrapDBError(err)
+	}
+	var count int64
+	result := parsedDB.Session(&gorm.Session{DryRun: true}).Count(&count)
+	if result.Error != nil {
+		return "", nil, WrapDBError(result.Error)
+	}
+	return result.Statement.SQL.String(), result.Statement.Vars, nil
+}
+
+// ToRawSQL 返回 Get 将执行的、参数已代入的 SQL，仅用于日志和排查，不可直接拼接执行
+func (q *QueryBuilder[T]) ToRawSQL() (string, error) {
+	sql, vars, err := q.ToSQL()
+	if err != nil {
+		return "", err
+	}
+	db := q.getDB()
+	if db == nil {
+		return "", WrapDBError(errors.New("database not initialized"))
+	}
+	return db.Dialector.Explain(sql, vars...), nil
+}
+
+// dryRun 按 query 构建查询并以 DryRun 模式生成 SQL；limit > 0 时覆盖返回条数
+func (q *QueryBuilder[T]) dryRun(query Query, limit int) (string, []interface{}, error) {
+	db := q.getDBWithModel()
+	if db == nil {
+		return "", nil, WrapDBError(errors.New("database not initialized"))
+	}
+	parsedDB, err := ParseQuery(query, db)
+	if err != nil {
+		return "", nil, WrapDBError(err)
+	}
+	if limit > 0 {
+		parsedDB = parsedDB.Limit(limit)
+	}
+
+	var rows []T
+	result := parsedDB.Session(&gorm.Session{DryRun: true}).Find(&rows)
+	if result.Error != nil {
+		return "", nil, WrapDBError(result.Error)
+	}
+	return result.Statement.SQL.String(), result.Statement.Vars, nil
+}
@@ -0,0 +1,205 @@
+package db_provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// readOnlyStatements 只读模式下允许的语句类型
+var readOnlyStatements = []string{"select", "with", "show", "explain", "describe", "desc"}
+
+// RawQuery 原生 SQL 查询，支持 :name 命名参数
+// 查询通过 gorm 执行，链路追踪（otelgorm）与慢查询日志自动生效
+type RawQuery struct {
+	db       *DB
+	tx       *gorm.DB
+	ctx      context.Context
+	query    string
+	params   map[string]interface{}
+	readOnly bool
+}
+
+// Raw 创建原生 SQL 查询，参数使用 :name 形式，例如：
+//
+//	db.Raw(ctx, "SELECT * FROM users WHERE status = :status AND id IN :ids", map[string]any{"status": 1, "ids": ids}).Scan(&users)
+//
+// 如需使用 gorm 原始的 Raw 方法，请调用 db.DB.Raw
+func (db *DB) Raw(ctx context.Context, query string, params map[string]interface{}) *RawQuery {
+	return &RawQuery{db: db, ctx: ctx, query: query, params: params}
+}
+
+// WithTx 在指定事务中执行
+func (r *RawQuery) WithTx(tx *gorm.DB) *RawQuery {
+	r.tx = tx
+	return r
+}
+
+// ReadOnly 启用只读模式，仅允许查询语句，并在只读事务中执行
+func (r *RawQuery) ReadOnly() *RawQuery {
+	r.readOnly = true
+	return r
+}
+
+// SQL 返回转换后的 SQL（? 占位符）与参数
+func (r *RawQuery) SQL() (string, []interface{}, error) {
+	return BindNamedParams(r.query, r.params)
+}
+
+// Scan 执行查询并将结果映射到 dest，支持结构体、结构体切片、map[string]interface{} 和 []map[string]interface{}
+func (r *RawQuery) Scan(dest interface{}) error {
+	query, args, err := r.prepare()
+	if err != nil {
+		return WrapDBError(err)
+	}
+
+	run := func(db *gorm.DB) error {
+		return db.Raw(query, args...).Scan(dest).Error
+	}
+
+	if r.readOnly && r.tx == nil {
+		err = r.session().Transaction(run, &sql.TxOptions{ReadOnly: true})
+	} else {
+		err = run(r.session())
+	}
+	return WrapDBError(err)
+}
+
+// Exec 执行非查询语句，返回影响行数
+func (r *RawQuery) Exec() (int64, error) {
+	if r.readOnly {
+		return 0, WrapDBError(errors.New("exec is not allowed in read-only mode"))
+	}
+	query, args, err := r.prepare()
+	if err != nil {
+		return 0, WrapDBError(err)
+	}
+	result := r.session().Exec(query, args...)
+	if result.Error != nil {
+		return 0, WrapDBError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *RawQuery) session() *gorm.DB {
+	db := r.tx
+	if db == nil {
+		db = r.db.DB
+	}
+	if r.ctx != nil {
+		db = db.WithContext(r.ctx)
+	}
+	return db
+}
+
+func (r *RawQuery) prepare() (string, []interface{}, error) {
+	if r.tx == nil && (r.db == nil || r.db.DB == nil) {
+		return "", nil, errors.New("database not initialized")
+	}
+	if r.readOnly {
+		if err := checkReadOnlySQL(r.query); err != nil {
+			return "", nil, err
+		}
+	}
+	return BindNamedParams(r.query, r.params)
+}
+
+// checkReadOnlySQL 校验 SQL 是否为单条只读语句
+func checkReadOnlySQL(query string) error {
+	trimmed := strings.TrimSpace(query)
+	trimmed = strings.TrimRight(trimmed, "; \t\r\n")
+	if strings.Contains(stripQuoted(trimmed), ";") {
+		return errors.New("multiple statements are not allowed in read-only mode")
+	}
+	fields := strings.Fields(trimmed)
+	if len(fields) == 0 {
+		return errors.New("empty sql")
+	}
+	first := strings.ToLower(fields[0])
+	for _, stmt := range readOnlyStatements {
+		if first == stmt {
+			return nil
+		}
+	}
+	return fmt.Errorf("statement %q is not allowed in read-only mode", fields[0])
+}
+
+// stripQuoted 移除引号内的内容，便于语法检查
+func stripQuoted(query string) string {
+	var b strings.Builder
+	var quote rune
+	for _, ch := range query {
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		if ch == '\'' || ch == '"' || ch == '`' {
+			quote = ch
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+// BindNamedParams 将 :name 命名参数转换为 ? 占位符，引号内与 :: 类型转换不做处理
+// 切片参数由 gorm 自动展开，可用于 IN 查询
+func BindNamedParams(query string, params map[string]interface{}) (string, []interface{}, error) {
+	var b strings.Builder
+	args := make([]interface{}, 0, len(params))
+	runes := []rune(query)
+	var quote rune
+
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		if quote != 0 {
+			b.WriteRune(ch)
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		if ch == '\'' || ch == '"' || ch == '`' {
+			quote = ch
+			b.WriteRune(ch)
+			continue
+		}
+		if ch == ':' && i+1 < len(runes) && runes[i+1] == ':' {
+			b.WriteString("::")
+			i++
+			continue
+		}
+		if ch == ':' && i+1 < len(runes) && isNamedParamRune(runes[i+1]) {
+			j := i + 1
+			for j < len(runes) && isNamedParamRune(runes[j]) {
+				j++
+			}
+			name := string(runes[i+1 : j])
+			value, ok := params[name]
+			if !ok {
+				return "", nil, fmt.Errorf("missing named parameter: %s", name)
+			}
+			args = append(args, value)
+			b.WriteRune('?')
+			i = j - 1
+			continue
+		}
+		b.WriteRune(ch)
+	}
+
+	if quote != 0 {
+		return "", nil, errors.New("unterminated quoted string in sql")
+	}
+
+	return b.String(), args, nil
+}
+
+func isNamedParamRune(ch rune) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
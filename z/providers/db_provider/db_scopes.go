@@ -0,0 +1,99 @@
+package db_provider
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Scope 查询作用域，可通过 QueryBuilder.Scopes 组合，也可直接用于 gorm 的 db.Scopes
+type Scope = func(db *gorm.DB) *gorm.DB
+
+// IActiveScope 自定义 Scopes.Active 的条件，未实现时按 status = 1 过滤
+type IActiveScope interface {
+	ActiveScope(db *gorm.DB) *gorm.DB
+}
+
+type _scopes struct{}
+
+// Scopes 常用查询作用域
+//
+//	q := crud.Query(ctx, db_provider.Query{}).Scopes(
+//		db_provider.Scopes.Active(),
+//		db_provider.Scopes.Search([]string{"name", "mobile"}, keyword),
+//		db_provider.Scopes.Latest(20),
+//	)
+var Scopes = _scopes{}
+
+// Between 字段在 [from, to] 区间内，from 或 to 为 nil 时只限制另一端
+func (_scopes) Between(field string, from, to interface{}) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		if !isValidFieldName(field) {
+			_ = db.AddError(fmt.Errorf("invalid field name in scope: %s", field))
+			return db
+		}
+		if from != nil {
+			db = db.Where(fmt.Sprintf("%s >= ?", field), from)
+		}
+		if to != nil {
+			db = db.Where(fmt.Sprintf("%s <= ?", field), to)
+		}
+		return db
+	}
+}
+
+// Latest 按 created_at 倒序取最近 n 条，n <= 0 时不限制条数
+func (_scopes) Latest(n int) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Order("created_at DESC")
+		if n > 0 {
+			db = db.Limit(n)
+		}
+		return db
+	}
+}
+
+// Active 仅查询启用的记录，模型实现 IActiveScope 时使用模型定义的条件
+// 软删除的记录已由 gorm 自动排除
+func (_scopes) Active() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		if m, ok := db.Statement.Model.(IActiveScope); ok {
+			return m.ActiveScope(db)
+		}
+		return db.Where("status = ?", 1)
+	}
+}
+
+// Search 多字段模糊搜索，任一字段包含 keyword 即匹配（OR LIKE），keyword 为空时不过滤
+// keyword 中的 % 和 _ 按普通字符匹配
+func (_scopes) Search(fields []string, keyword string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" || len(fields) == 0 {
+			return db
+		}
+		pattern := "%" + escapeLike(keyword) + "%"
+		// SQLite 没有默认转义符，需显式指定
+		like := "%s LIKE ?"
+		if db.Dialector.Name() == "sqlite" {
+			like = `%s LIKE ? ESCAPE '\'`
+		}
+		conditions := make([]string, 0, len(fields))
+		values := make([]interface{}, 0, len(fields))
+		for _, field := range fields {
+			if !isValidFieldName(field) {
+				_ = db.AddError(fmt.Errorf("invalid field name in scope: %s", field))
+				return db
+			}
+			conditions = append(conditions, fmt.Sprintf(like, field))
+			values = append(values, pattern)
+		}
+		return db.Where(strings.Join(conditions, " OR "), values...)
+	}
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
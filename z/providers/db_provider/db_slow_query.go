@@ -0,0 +1,218 @@
+package db_provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// DefaultSlowQueryThreshold 未配置 db.slow_query_ms 时的慢查询阈值
+const DefaultSlowQueryThreshold = time.Second
+
+// maxSlowQuerySQL 慢查询日志中 SQL 的最大长度
+const maxSlowQuerySQL = 2000
+
+const slowQueryStartKey = "z:slow_query:start"
+
+// QueryStats 进程内累计的查询统计，可供 metrics 接口输出
+type QueryStats struct {
+	Queries     int64   `json:"queries"`      // 执行的语句数
+	Errors      int64   `json:"errors"`       // 失败的语句数，不含记录不存在和请求取消
+	Slow        int64   `json:"slow"`         // 超过阈值的语句数
+	TotalMs     float64 `json:"total_ms"`     // 累计耗时
+	MaxMs       float64 `json:"max_ms"`       // 最长耗时
+	ThresholdMs int64   `json:"threshold_ms"` // 慢查询阈值
+}
+
+// SlowQueryOptions 慢查询插件选项
+type SlowQueryOptions struct {
+	Threshold time.Duration // 慢查询阈值，0 使用 DefaultSlowQueryThreshold，小于 0 时只统计不记录慢查询
+	Log       *logger_provider.Logger
+	Clock     z.Clock
+}
+
+// SlowQueryPlugin GORM 插件：统计每条语句的耗时，超过阈值时记录 warn 日志并写入当前 span 的事件
+// 同时上报 OTel 指标 db.queries、db.query.errors、db.slow_queries、db.query.duration（未配置 MeterProvider 时为空实现）
+type SlowQueryPlugin struct {
+	opts      SlowQueryOptions
+	threshold atomic.Int64 // 纳秒，可通过 SetThreshold 运行时调整
+
+	queries atomic.Int64
+	errors  atomic.Int64
+	slow    atomic.Int64
+	total   atomic.Int64 // 纳秒
+	max     atomic.Int64 // 纳秒
+}
+
+// NewSlowQueryPlugin 创建慢查询插件，通过 gdb.Use 注册
+func NewSlowQueryPlugin(opts SlowQueryOptions) *SlowQueryPlugin {
+	if opts.Threshold == 0 {
+		opts.Threshold = DefaultSlowQueryThreshold
+	}
+	opts.Clock = z.ClockOr(opts.Clock)
+	p := &SlowQueryPlugin{opts: opts}
+	p.threshold.Store(int64(opts.Threshold))
+	return p
+}
+
+// SetThreshold 运行时调整慢查询阈值，取值含义同 SlowQueryOptions.Threshold
+func (p *SlowQueryPlugin) SetThreshold(threshold time.Duration) {
+	if threshold == 0 {
+		threshold = DefaultSlowQueryThreshold
+	}
+	p.threshold.Store(int64(threshold))
+}
+
+func (p *SlowQueryPlugin) Name() string { return "z:slow_query" }
+
+// callbackRegistrar gorm 回调链上 Before / After 返回的注册器
+type callbackRegistrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+// Initialize 在各类语句执行前后注册回调
+func (p *SlowQueryPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	processors := map[string][2]callbackRegistrar{
+		"create": {cb.Create().Before("*"), cb.Create().After("*")},
+		"query":  {cb.Query().Before("*"), cb.Query().After("*")},
+		"update": {cb.Update().Before("*"), cb.Update().After("*")},
+		"delete": {cb.Delete().Before("*"), cb.Delete().After("*")},
+		"row":    {cb.Row().Before("*"), cb.Row().After("*")},
+		"raw":    {cb.Raw().Before("*"), cb.Raw().After("*")},
+	}
+	for operation, proc := range processors {
+		if err := proc[0].Register("z:slow_query:before_"+operation, p.before); err != nil {
+			return err
+		}
+		if err := proc[1].Register("z:slow_query:after_"+operation, p.after(operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stats 返回累计的查询统计
+func (p *SlowQueryPlugin) Stats() QueryStats {
+	if p == nil {
+		return QueryStats{}
+	}
+	return QueryStats{
+		Queries:     p.queries.Load(),
+		Errors:      p.errors.Load(),
+		Slow:        p.slow.Load(),
+		TotalMs:     float64(p.total.Load()) / float64(time.Millisecond),
+		MaxMs:       float64(p.max.Load()) / float64(time.Millisecond),
+		ThresholdMs: time.Duration(p.threshold.Load()).Milliseconds(),
+	}
+}
+
+func (p *SlowQueryPlugin) before(db *gorm.DB) {
+	db.InstanceSet(slowQueryStartKey, p.opts.Clock.Now())
+}
+
+func (p *SlowQueryPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(slowQueryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		elapsed := p.opts.Clock.Now().Sub(start)
+		stmt := db.Statement
+		ctx := stmt.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		p.queries.Add(1)
+		p.total.Add(int64(elapsed))
+		for {
+			current := p.max.Load()
+			if int64(elapsed) <= current || p.max.CompareAndSwap(current, int64(elapsed)) {
+				break
+			}
+		}
+		failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) && !errors.Is(db.Error, context.Canceled)
+		if failed {
+			p.errors.Add(1)
+		}
+
+		m := getQueryMetrics()
+		attrs := metric.WithAttributes(attribute.String("operation", operation), attribute.String("table", stmt.Table))
+		m.queries.Add(ctx, 1, attrs)
+		m.duration.Record(ctx, float64(elapsed)/float64(time.Millisecond), attrs)
+		if failed {
+			m.errors.Add(ctx, 1, attrs)
+		}
+
+		threshold := time.Duration(p.threshold.Load())
+		if threshold < 0 || elapsed < threshold {
+			return
+		}
+		p.slow.Add(1)
+		m.slow.Add(ctx, 1, attrs)
+
+		sql := stmt.SQL.String()
+		if len(sql) > maxSlowQuerySQL {
+			sql = sql[:maxSlowQuerySQL] + "..."
+		}
+		span := trace.SpanFromContext(ctx)
+		span.AddEvent("db.slow_query", trace.WithAttributes(
+			attribute.String("db.operation", operation),
+			attribute.String("db.sql.table", stmt.Table),
+			attribute.String("db.statement", sql),
+			attribute.Int64("db.duration_ms", elapsed.Milliseconds()),
+			attribute.Int64("db.rows_affected", db.RowsAffected),
+		))
+		if p.opts.Log != nil {
+			// 只记录参数化的 SQL，不输出参数值
+			p.opts.Log.Warnw("db slow query",
+				"operation", operation,
+				"table", stmt.Table,
+				"sql", sql,
+				"duration_ms", elapsed.Milliseconds(),
+				"threshold_ms", threshold.Milliseconds(),
+				"rows", db.RowsAffected,
+				"trace_id", span.SpanContext().TraceID().String(),
+			)
+		}
+	}
+}
+
+// queryMetrics OTel 指标，未配置 MeterProvider 时为空实现
+type queryMetrics struct {
+	queries  metric.Int64Counter
+	errors   metric.Int64Counter
+	slow     metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+var (
+	queryMetricsOnce sync.Once
+	queryMetricsInst queryMetrics
+)
+
+func getQueryMetrics() queryMetrics {
+	queryMetricsOnce.Do(func() {
+		meter := otel.Meter("github.com/icreateapp-com/go-zLib/db")
+		queryMetricsInst.queries, _ = meter.Int64Counter("db.queries", metric.WithDescription("执行的 SQL 语句数"))
+		queryMetricsInst.errors, _ = meter.Int64Counter("db.query.errors", metric.WithDescription("失败的 SQL 语句数"))
+		queryMetricsInst.slow, _ = meter.Int64Counter("db.slow_queries", metric.WithDescription("超过慢查询阈值的 SQL 语句数"))
+		queryMetricsInst.duration, _ = meter.Float64Histogram("db.query.duration", metric.WithUnit("ms"), metric.WithDescription("SQL 语句耗时"))
+	})
+	return queryMetricsInst
+}
@@ -0,0 +1,71 @@
+package db_provider
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 软删除记录的查询范围（Query.Trashed）
+const (
+	TrashedWith = "with" // 包含已软删除的记录
+	TrashedOnly = "only" // 仅查询已软删除的记录
+)
+
+// ErrSoftDeleteUnsupported 模型没有 gorm.DeletedAt 字段
+var ErrSoftDeleteUnsupported = DBError{Code: ErrCodeInvalidData, Message: "Model does not support soft delete"}
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// softDeleteCache 模型类型 -> 软删除列名（空串表示不支持）
+var softDeleteCache sync.Map
+
+// SoftDeleteColumn 返回模型的软删除列名（类型为 gorm.DeletedAt 的字段，如内嵌 SoftDelete），不支持软删除时返回空串
+func SoftDeleteColumn[T any](db *gorm.DB) string {
+	var zero T
+	return softDeleteColumnOf(db, &zero)
+}
+
+func softDeleteColumnOf(db *gorm.DB, model interface{}) string {
+	if db == nil || model == nil {
+		return ""
+	}
+	typ := reflect.TypeOf(model)
+	if cached, ok := softDeleteCache.Load(typ); ok {
+		return cached.(string)
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil || stmt.Schema == nil {
+		return ""
+	}
+	column := ""
+	for _, field := range stmt.Schema.Fields {
+		if field.FieldType == deletedAtType && field.DBName != "" {
+			column = field.DBName
+			break
+		}
+	}
+	softDeleteCache.Store(typ, column)
+	return column
+}
+
+// ParseTrashed 应用软删除查询范围，默认（空串）由 gorm 自动排除已软删除的记录
+func ParseTrashed(db *gorm.DB, trashed string) (*gorm.DB, error) {
+	switch trashed {
+	case "":
+		return db, nil
+	case TrashedWith:
+		return db.Unscoped(), nil
+	case TrashedOnly:
+		column := softDeleteColumnOf(db, db.Statement.Model)
+		if column == "" {
+			return nil, ErrSoftDeleteUnsupported
+		}
+		return db.Unscoped().Where(clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: column}}}), nil
+	default:
+		return nil, fmt.Errorf("invalid trashed option: %s", trashed)
+	}
+}
@@ -0,0 +1,291 @@
+package db_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTreeCycle 移动节点会形成环
+var ErrTreeCycle = errors.New("tree node cannot be moved under itself or its descendants")
+
+// TreeBuilder 树形数据（邻接表 parent_id）构建器
+// 默认使用递归 CTE（MySQL 8+、PostgreSQL、SQLite 3.8.3+），DisableCTE 为 true 时逐层查询
+type TreeBuilder[T IModel] struct {
+	DB           *DB             // 数据库连接（DI 注入）
+	TX           *gorm.DB        // 事务支持
+	Context      context.Context // 上下文
+	IDColumn     string          // 主键字段，默认 id
+	ParentColumn string          // 父级字段，默认 parent_id
+	DisableCTE   bool            // 禁用递归 CTE
+}
+
+// WithContext 设置上下文
+func (b *TreeBuilder[T]) WithContext(ctx context.Context) *TreeBuilder[T] {
+	nb := *b
+	nb.Context = ctx
+	return &nb
+}
+
+func (b *TreeBuilder[T]) getDB() (*gorm.DB, error) {
+	var db *gorm.DB
+	if b.TX != nil {
+		db = b.TX
+	} else if b.DB != nil {
+		db = b.DB.DB
+	} else {
+		return nil, errors.New("database not initialized")
+	}
+	if b.Context != nil {
+		db = db.WithContext(b.Context)
+	}
+	return db, nil
+}
+
+func (b *TreeBuilder[T]) columns() (string, string, error) {
+	id, parent := b.IDColumn, b.ParentColumn
+	if id == "" {
+		id = "id"
+	}
+	if parent == "" {
+		parent = "parent_id"
+	}
+	if !isValidFieldName(id) || !isValidFieldName(parent) {
+		return "", "", errors.New("invalid tree column name")
+	}
+	return id, parent, nil
+}
+
+func (b *TreeBuilder[T]) table() string {
+	var zero T
+	return zero.TableName()
+}
+
+// Descendants 查询所有后代节点（不包含自身）
+func (b *TreeBuilder[T]) Descendants(id interface{}, dest interface{}) error {
+	db, err := b.getDB()
+	if err != nil {
+		return WrapDBError(err)
+	}
+	idCol, parentCol, err := b.columns()
+	if err != nil {
+		return WrapDBError(err)
+	}
+	table := b.table()
+
+	if b.DisableCTE {
+		ids, err := b.DescendantIDs(id)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		var zero T
+		return WrapDBError(db.Model(&zero).Where(idCol+" IN ?", ids).Find(dest).Error)
+	}
+
+	// 与逐层查询一致：从直接子节点开始，跳过已软删除的节点及其子树
+	var zero T
+	alive, childAlive := "", ""
+	if column := softDeleteColumnOf(db, &zero); column != "" {
+		alive = " AND " + column + " IS NULL"
+		childAlive = " WHERE c." + column + " IS NULL"
+	}
+	query := fmt.Sprintf(
+		"WITH RECURSIVE tree AS (SELECT * FROM %[1]s WHERE %[3]s = ?%[4]s UNION ALL SELECT c.* FROM %[1]s c INNER JOIN tree t ON c.%[3]s = t.%[2]s%[5]s) SELECT * FROM tree",
+		table, idCol, parentCol, alive, childAlive,
+	)
+	return WrapDBError(db.Raw(query, id).Scan(dest).Error)
+}
+
+// Ancestors 查询所有祖先节点（不包含自身），从直接父级到根节点
+func (b *TreeBuilder[T]) Ancestors(id interface{}, dest interface{}) error {
+	db, err := b.getDB()
+	if err != nil {
+		return WrapDBError(err)
+	}
+	idCol, parentCol, err := b.columns()
+	if err != nil {
+		return WrapDBError(err)
+	}
+	table := b.table()
+
+	if b.DisableCTE {
+		ids, err := b.AncestorIDs(id)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		var zero T
+		return WrapDBError(db.Model(&zero).Where(idCol+" IN ?", ids).Find(dest).Error)
+	}
+
+	// 与逐级查询一致：遇到已软删除的节点即停止
+	var zero T
+	alive, parentAlive := "", ""
+	if column := softDeleteColumnOf(db, &zero); column != "" {
+		alive = " AND c." + column + " IS NULL AND p." + column + " IS NULL"
+		parentAlive = " WHERE p." + column + " IS NULL"
+	}
+	query := fmt.Sprintf(
+		"WITH RECURSIVE tree AS (SELECT p.*, 1 AS tree_depth FROM %[1]s p INNER JOIN %[1]s c ON c.%[3]s = p.%[2]s WHERE c.%[2]s = ?%[4]s UNION ALL SELECT p.*, t.tree_depth + 1 FROM %[1]s p INNER JOIN tree t ON t.%[3]s = p.%[2]s%[5]s) SELECT * FROM tree ORDER BY tree_depth",
+		table, idCol, parentCol, alive, parentAlive,
+	)
+	return WrapDBError(db.Raw(query, id).Scan(dest).Error)
+}
+
+// DescendantIDs 逐层查询所有后代节点主键
+func (b *TreeBuilder[T]) DescendantIDs(id interface{}) ([]interface{}, error) {
+	db, err := b.getDB()
+	if err != nil {
+		return nil, WrapDBError(err)
+	}
+	idCol, parentCol, err := b.columns()
+	if err != nil {
+		return nil, WrapDBError(err)
+	}
+
+	var zero T
+	result := make([]interface{}, 0)
+	seen := map[string]bool{fmt.Sprint(id): true}
+	level := []interface{}{id}
+	for len(level) > 0 {
+		var children []interface{}
+		if err := db.Model(&zero).Where(parentCol+" IN ?", level).Pluck(idCol, &children).Error; err != nil {
+			return nil, WrapDBError(err)
+		}
+		next := make([]interface{}, 0, len(children))
+		for _, child := range children {
+			key := fmt.Sprint(normalizePluckValue(child))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			next = append(next, normalizePluckValue(child))
+		}
+		result = append(result, next...)
+		level = next
+	}
+	return result, nil
+}
+
+// AncestorIDs 逐级查询所有祖先节点主键，从直接父级到根节点
+func (b *TreeBuilder[T]) AncestorIDs(id interface{}) ([]interface{}, error) {
+	db, err := b.getDB()
+	if err != nil {
+		return nil, WrapDBError(err)
+	}
+	return b.ancestorIDs(db, id, false)
+}
+
+// ancestorIDs 逐级查询祖先节点主键，lock 为 true 时对经过的节点加行锁（SQLite 不支持，跳过）
+func (b *TreeBuilder[T]) ancestorIDs(db *gorm.DB, id interface{}, lock bool) ([]interface{}, error) {
+	idCol, parentCol, err := b.columns()
+	if err != nil {
+		return nil, WrapDBError(err)
+	}
+
+	var zero T
+	result := make([]interface{}, 0)
+	seen := map[string]bool{fmt.Sprint(id): true}
+	current := id
+	for {
+		var parents []interface{}
+		if err := lockTreeRows(db, lock).Model(&zero).Where(idCol+" = ?", current).Limit(1).Pluck(parentCol, &parents).Error; err != nil {
+			return nil, WrapDBError(err)
+		}
+		if len(parents) == 0 || parents[0] == nil {
+			return result, nil
+		}
+		parent := normalizePluckValue(parents[0])
+		key := fmt.Sprint(parent)
+		if seen[key] || key == "" || key == "0" {
+			return result, nil
+		}
+		seen[key] = true
+		result = append(result, parent)
+		current = parent
+	}
+}
+
+// ValidateParent 校验将节点挂载到 parentID 下不会形成环
+func (b *TreeBuilder[T]) ValidateParent(id interface{}, parentID interface{}) error {
+	db, err := b.getDB()
+	if err != nil {
+		return WrapDBError(err)
+	}
+	return b.validateParent(db, id, parentID, false)
+}
+
+func (b *TreeBuilder[T]) validateParent(db *gorm.DB, id interface{}, parentID interface{}, lock bool) error {
+	if parentID == nil || fmt.Sprint(parentID) == "" || fmt.Sprint(parentID) == "0" {
+		return nil
+	}
+	if fmt.Sprint(id) == fmt.Sprint(parentID) {
+		return ErrTreeCycle
+	}
+	// lock 时新父节点及其祖先链均加锁，链上节点被并发移动时等待其提交
+	ancestors, err := b.ancestorIDs(db, parentID, lock)
+	if err != nil {
+		return err
+	}
+	for _, ancestor := range ancestors {
+		if fmt.Sprint(ancestor) == fmt.Sprint(id) {
+			return ErrTreeCycle
+		}
+	}
+	return nil
+}
+
+// Move 将节点（连同子树）移动到新的父节点下，parentID 为 nil 时移动为根节点
+// 在事务中锁定节点及新父节点的祖先链后校验并更新，避免并发移动形成环
+func (b *TreeBuilder[T]) Move(id interface{}, parentID interface{}) error {
+	db, err := b.getDB()
+	if err != nil {
+		return WrapDBError(err)
+	}
+	idCol, parentCol, err := b.columns()
+	if err != nil {
+		return WrapDBError(err)
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var zero T
+		var ids []interface{}
+		if err := lockTreeRows(tx, true).Model(&zero).Where(idCol+" = ?", id).Pluck(idCol, &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := b.validateParent(tx, id, parentID, true); err != nil {
+			return err
+		}
+		return tx.Model(&zero).Where(idCol+" = ?", id).Update(parentCol, parentID).Error
+	})
+	if errors.Is(err, ErrTreeCycle) {
+		return err
+	}
+	return WrapDBError(err)
+}
+
+// lockTreeRows 为查询加 FOR UPDATE 行锁，SQLite 不支持行锁时跳过
+func lockTreeRows(db *gorm.DB, lock bool) *gorm.DB {
+	if !lock || db.Dialector.Name() == "sqlite" {
+		return db
+	}
+	return db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+// normalizePluckValue 将驱动返回的 []byte 转换为字符串
+func normalizePluckValue(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
@@ -3,8 +3,10 @@ package db_provider
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // rawUpdateCondition 原生条件
@@ -107,8 +109,9 @@ func (q *UpdateBuilder[T]) Update(query Query, values T, customFunc ...func(*gor
 }
 
 func (q *UpdateBuilder[T]) UpdateByID(id interface{}, values T, customFunc ...func(*gorm.DB) *gorm.DB) (bool, error) {
-	if id == nil || id == "" {
-		return false, errors.New("id cannot be empty")
+	conditions, err := primaryKeyConditions(PrimaryKeyColumns[T](builderDB(q.DB, q.TX)), id)
+	if err != nil {
+		return false, err
 	}
 
 	queryBuilder := QueryBuilder[T]{
@@ -121,6 +124,11 @@ func (q *UpdateBuilder[T]) UpdateByID(id interface{}, values T, customFunc ...fu
 		return false, WrapDBError(errors.New("row not found"))
 	}
 
+	// 乐观锁：模型含版本号字段时按 values 中的版本号更新
+	if field := versionFieldOf(builderDB(q.DB, q.TX), new(T)); field != nil {
+		return q.updateVersioned(field, conditions, values, customFunc...)
+	}
+
 	// 如果提供了自定义函数，使用直接更新方式
 	if len(customFunc) > 0 && customFunc[0] != nil {
 		var zero T
@@ -147,8 +155,11 @@ func (q *UpdateBuilder[T]) UpdateByID(id interface{}, values T, customFunc ...fu
 		// 应用自定义函数（如 Select、Omit 等）
 		db = customFunc[0](db)
 
-		// 添加 ID 条件并执行更新
-		if err := db.Where("id = ?", id).Updates(&values).Error; err != nil {
+		// 添加主键条件并执行更新
+		for _, cond := range conditions {
+			db = db.Where(fmt.Sprintf("%s = ?", cond[0]), cond[1])
+		}
+		if err := db.Updates(&values).Error; err != nil {
 			return false, WrapDBError(err)
 		}
 
@@ -159,7 +170,7 @@ func (q *UpdateBuilder[T]) UpdateByID(id interface{}, values T, customFunc ...fu
 	query := Query{
 		Search: []ConditionGroup{
 			{
-				Conditions: [][]interface{}{{"id", id}},
+				Conditions: conditions,
 			},
 		},
 	}
@@ -176,6 +187,61 @@ func (q *UpdateBuilder[T]) UpdateByID(id interface{}, values T, customFunc ...fu
 	return newBuilder.Update(query, values)
 }
 
+// updateVersioned 按主键和版本号更新记录并将版本号加一，版本号不匹配时返回 ErrVersionConflict
+func (q *UpdateBuilder[T]) updateVersioned(field *schema.Field, conditions [][]interface{}, values T, customFunc ...func(*gorm.DB) *gorm.DB) (bool, error) {
+	var zero T
+	var db *gorm.DB
+	if q.TX != nil {
+		db = q.TX.Model(&zero)
+	} else {
+		if q.DB == nil {
+			return false, WrapDBError(errors.New("db is nil"))
+		}
+		db = q.DB.Model(&zero)
+	}
+
+	// 应用上下文
+	if q.Context != nil {
+		db = db.WithContext(q.Context)
+	}
+
+	// 应用原生条件
+	for _, condition := range q.rawConditions {
+		db = db.Where(condition.query, condition.args...)
+	}
+
+	// 应用初始化时的 Query 参数
+	if len(q.Query.Search) > 0 || len(q.Query.Required) > 0 {
+		var err error
+		db, err = ParseSearch(db, q.Query.Search, q.Query.Required)
+		if err != nil {
+			return false, WrapDBError(err)
+		}
+	}
+
+	// 应用自定义函数（如 Select、Omit 等）
+	if len(customFunc) > 0 && customFunc[0] != nil {
+		db = customFunc[0](db)
+	}
+
+	for _, cond := range conditions {
+		db = db.Where(fmt.Sprintf("%s = ?", cond[0]), cond[1])
+	}
+	db, err := applyVersion(q.Context, db, field, &values)
+	if err != nil {
+		return false, WrapDBError(err)
+	}
+
+	result := db.Updates(&values)
+	if result.Error != nil {
+		return false, WrapDBError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, ErrVersionConflict
+	}
+	return true, nil
+}
+
 // UpdateBatch 批量更新多条记录，每条记录有不同的值
 func (q *UpdateBuilder[T]) UpdateBatch(values []T) (int64, error) {
 	if len(values) == 0 {
@@ -0,0 +1,88 @@
+package db_provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrVersionConflict 乐观锁冲突：记录在读取后已被其他请求修改
+var ErrVersionConflict = DBError{Code: ErrCodeVersionConflict, Message: "Record has been modified by another request"}
+
+// versionFieldCache 模型类型 -> 版本号字段（无版本号时为 nil）
+var versionFieldCache sync.Map
+
+// VersionColumn 返回模型的乐观锁版本号列名，不支持时返回空串
+// 版本号字段为整数类型，带 gorm:"version" 标签或列名为 version
+func VersionColumn[T any](db *gorm.DB) string {
+	var zero T
+	if field := versionFieldOf(db, &zero); field != nil {
+		return field.DBName
+	}
+	return ""
+}
+
+func versionFieldOf(db *gorm.DB, model interface{}) *schema.Field {
+	if db == nil || model == nil {
+		return nil
+	}
+	typ := reflect.TypeOf(model)
+	if cached, ok := versionFieldCache.Load(typ); ok {
+		return cached.(*schema.Field)
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil || stmt.Schema == nil {
+		return nil
+	}
+	var found *schema.Field
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || !isIntegerKind(field.IndirectFieldType.Kind()) {
+			continue
+		}
+		if _, ok := field.TagSettings["VERSION"]; ok {
+			found = field
+			break
+		}
+		if field.DBName == "version" && found == nil {
+			found = field
+		}
+	}
+	versionFieldCache.Store(typ, found)
+	return found
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// applyVersion 以 values 中的版本号作为更新条件，并将写入的版本号加一
+func applyVersion(ctx context.Context, db *gorm.DB, field *schema.Field, values interface{}) (*gorm.DB, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rv := reflect.ValueOf(values).Elem()
+	current, _ := field.ValueOf(ctx, rv)
+	version, ok := z.ToInt(current)
+	if !ok {
+		return nil, DBError{Code: ErrCodeInvalidData, Message: "invalid version", Field: field.DBName}
+	}
+	if err := field.Set(ctx, rv, version+1); err != nil {
+		return nil, err
+	}
+	db = db.Where(fmt.Sprintf("%s = ?", field.DBName), version)
+	// 自定义函数限定了更新列时补充版本号列
+	if len(db.Statement.Selects) > 0 {
+		db = db.Select(append(append([]string(nil), db.Statement.Selects...), field.DBName))
+	}
+	return db, nil
+}
@@ -0,0 +1,207 @@
+package db_provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/icreateapp-com/go-zLib/z"
+	"gorm.io/gorm"
+)
+
+// PrivacyColumn 匿名化时对一列的处理：Value 非空时写入该值，否则 Mask 非空时按脱敏方式处理原值，都为空时置为 NULL
+type PrivacyColumn struct {
+	Column string      `json:"column"`
+	Mask   string      `json:"mask"`  // phone / email / id_card / name / all 或 z.RegisterMask 注册的名称
+	Value  interface{} `json:"value"` // 固定值，如 "已注销用户"
+}
+
+// PrivacyModelOptions 模型的数据主体请求配置
+type PrivacyModelOptions struct {
+	Name       string          // 数据类名称，默认为表名
+	UserColumn string          // 关联用户的列，默认 user_id
+	Columns    []PrivacyColumn // 匿名化的列
+	Omit       []string        // 导出时排除的列，如密码哈希
+}
+
+// privacyModel 基于模型的 z.PrivacySubject 实现
+type privacyModel[T IModel] struct {
+	db   *DB
+	opts PrivacyModelOptions
+}
+
+// RegisterPrivacyModel 将模型注册到 z.Privacy：导出该用户的全部记录，匿名化时在事务中逐行改写配置的列
+//
+//	db_provider.RegisterPrivacyModel[User](db, db_provider.PrivacyModelOptions{
+//		UserColumn: "id",
+//		Columns: []db_provider.PrivacyColumn{
+//			{Column: "name", Value: "已注销用户"},
+//			{Column: "phone", Mask: "phone"},
+//			{Column: "email"},
+//		},
+//		Omit: []string{"password"},
+//	})
+func RegisterPrivacyModel[T IModel](db *DB, opts PrivacyModelOptions) error {
+	var model T
+	if opts.Name == "" {
+		opts.Name = model.TableName()
+	}
+	if opts.UserColumn == "" {
+		opts.UserColumn = "user_id"
+	}
+	if !isValidFieldName(opts.UserColumn) {
+		return fmt.Errorf("privacy model %s: invalid user column %q", opts.Name, opts.UserColumn)
+	}
+	for _, col := range opts.Columns {
+		if !isValidFieldName(col.Column) {
+			return fmt.Errorf("privacy model %s: invalid column %q", opts.Name, col.Column)
+		}
+		if col.Value == nil && col.Mask != "" {
+			if _, ok := z.LookupMask(col.Mask); !ok {
+				return fmt.Errorf("privacy model %s: unknown mask %q for column %s", opts.Name, col.Mask, col.Column)
+			}
+		}
+	}
+	z.Privacy.Register(opts.Name, &privacyModel[T]{db: db, opts: opts})
+	return nil
+}
+
+func (m *privacyModel[T]) rows(tx *gorm.DB, userID string) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	// 软删除的记录同样属于用户数据
+	err := tx.Unscoped().Model(new(T)).Where(m.db.F(m.opts.UserColumn)+" = ?", userID).Find(&rows).Error
+	if err != nil {
+		return nil, WrapDBError(err)
+	}
+	return rows, nil
+}
+
+// Export 返回用户的全部记录，排除 Omit 中的列
+func (m *privacyModel[T]) Export(ctx context.Context, userID string) (interface{}, error) {
+	rows, err := m.rows(m.db.WithContext(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for _, col := range m.opts.Omit {
+			delete(row, col)
+		}
+		for k, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[k] = string(b)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// Erase 在事务中逐行改写配置的列，返回改写的记录数
+func (m *privacyModel[T]) Erase(ctx context.Context, userID string) (int64, error) {
+	if len(m.opts.Columns) == 0 {
+		return 0, nil
+	}
+	var affected int64
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		rows, err := m.rows(tx, userID)
+		if err != nil {
+			return err
+		}
+		pk := PrimaryKeyColumns[T](tx)
+		for _, row := range rows {
+			query := tx.Unscoped().Model(new(T))
+			for _, col := range pk {
+				value, ok := row[col]
+				if !ok {
+					return fmt.Errorf("privacy model %s: primary key %s not loaded", m.opts.Name, col)
+				}
+				query = query.Where(m.db.F(col)+" = ?", value)
+			}
+			updates := make(map[string]interface{}, len(m.opts.Columns))
+			for _, col := range m.opts.Columns {
+				updates[col.Column] = anonymize(col, row[col.Column])
+			}
+			if err := query.UpdateColumns(updates).Error; err != nil {
+				return WrapDBError(err)
+			}
+			affected++
+		}
+		return nil
+	})
+	return affected, err
+}
+
+// anonymize 计算列的匿名化值
+func anonymize(col PrivacyColumn, value interface{}) interface{} {
+	if col.Value != nil {
+		return col.Value
+	}
+	if col.Mask == "" || value == nil {
+		return nil
+	}
+	fn, ok := z.LookupMask(col.Mask)
+	if !ok {
+		return nil
+	}
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case []byte:
+		return fn(string(v))
+	}
+	return fn(fmt.Sprint(value))
+}
+
+// PrivacyAudit 数据主体请求审计记录表
+type PrivacyAudit struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    string    `gorm:"size:64;index" json:"user_id"`
+	Action    string    `gorm:"size:16" json:"action"`
+	Operator  string    `gorm:"size:64" json:"operator"`
+	Subjects  string    `gorm:"type:text" json:"subjects"`
+	Affected  string    `gorm:"type:text" json:"affected"`
+	Error     string    `gorm:"type:text" json:"error"`
+	CreatedAt time.Time `gorm:"type:datetime" json:"created_at"`
+}
+
+func (PrivacyAudit) TableName() string { return "privacy_audits" }
+
+// dbPrivacyAuditor 将审计记录写入 privacy_audits
+type dbPrivacyAuditor struct {
+	db *DB
+}
+
+// NewDBPrivacyAuditor 创建数据库审计存储，migrate 为 true 时自动建表
+//
+//	auditor, err := db_provider.NewDBPrivacyAuditor(db, true)
+//	z.Privacy.SetAuditor(auditor)
+func NewDBPrivacyAuditor(db *DB, migrate bool) (z.PrivacyAuditor, error) {
+	if migrate {
+		if err := db.AutoMigrate(&PrivacyAudit{}); err != nil {
+			return nil, WrapDBError(err)
+		}
+	}
+	return &dbPrivacyAuditor{db: db}, nil
+}
+
+func (a *dbPrivacyAuditor) Record(ctx context.Context, record z.PrivacyAuditRecord) error {
+	affected := ""
+	if len(record.Affected) > 0 {
+		b, err := json.Marshal(record.Affected)
+		if err != nil {
+			return err
+		}
+		affected = string(b)
+	}
+	row := &PrivacyAudit{
+		UserID:    record.UserID,
+		Action:    record.Action,
+		Operator:  record.Operator,
+		Subjects:  strings.Join(record.Subjects, ","),
+		Affected:  affected,
+		Error:     record.Error,
+		CreatedAt: record.CreatedAt,
+	}
+	return WrapDBError(a.db.WithContext(ctx).Create(row).Error)
+}
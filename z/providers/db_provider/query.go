@@ -1,5 +1,7 @@
 package db_provider
 
+import "strings"
+
 // Query 查询参数
 type Query struct {
 	Search   []ConditionGroup `json:"search"`
@@ -7,6 +9,10 @@ type Query struct {
 	Limit    int              `json:"limit"`
 	Page     int              `json:"page"`
 	Required []string         `json:"required"`
+	Include  []string         `json:"include"` // 关联预加载，格式为 relation[.nested][:col1,col2]，如 orders:id,total、orders.items
+	GroupBy  []string         `json:"groupby"` // 分组字段，仅 QueryBuilder.Aggregate 使用
+	Having   []ConditionGroup `json:"having"`  // 分组过滤条件，格式同 Search，字段可为聚合别名，仅 QueryBuilder.Aggregate 使用
+	Trashed  string           `json:"-"`       // 软删除查询范围：空（排除已删除）、with、only；仅服务端设置，不从请求参数解析
 }
 
 // ConditionGroup 条件组
@@ -116,30 +122,68 @@ func (q *Query) AddRequired(fields ...string) *Query {
 	return q
 }
 
+// AddInclude 添加关联预加载，relation 可为嵌套路径（如 orders.items），columns 为该关联查询的字段，为空时查询全部字段
+func (q *Query) AddInclude(relation string, columns ...string) *Query {
+	if len(columns) > 0 {
+		relation += ":" + strings.Join(columns, ",")
+	}
+	q.Include = append(q.Include, relation)
+	return q
+}
+
+// AddGroupBy 添加分组字段
+func (q *Query) AddGroupBy(fields ...string) *Query {
+	q.GroupBy = append(q.GroupBy, fields...)
+	return q
+}
+
+// AddHaving 添加分组过滤条件，field 可为聚合别名，操作符同 AddSearch
+func (q *Query) AddHaving(field string, value interface{}, operator ...string) *Query {
+	op := "="
+	if len(operator) > 0 && operator[0] != "" {
+		op = operator[0]
+	}
+	q.Having = append(q.Having, ConditionGroup{
+		Conditions: [][]interface{}{{field, value, op}},
+		Operator:   "AND",
+	})
+	return q
+}
+
+// WithTrashed 查询结果包含已软删除的记录
+func (q *Query) WithTrashed() *Query {
+	q.Trashed = TrashedWith
+	return q
+}
+
+// OnlyTrashed 仅查询已软删除的记录
+func (q *Query) OnlyTrashed() *Query {
+	q.Trashed = TrashedOnly
+	return q
+}
+
 // Clone 克隆 Query 实例
 func (q *Query) Clone() Query {
 	clone := Query{
 		Limit:    q.Limit,
 		Page:     q.Page,
 		Required: make([]string, len(q.Required)),
+		Trashed:  q.Trashed,
 	}
 
 	// 深拷贝 Required
 	copy(clone.Required, q.Required)
 
-	// 深拷贝 Search
-	if len(q.Search) > 0 {
-		clone.Search = make([]ConditionGroup, len(q.Search))
-		for i, group := range q.Search {
-			clone.Search[i] = ConditionGroup{
-				Conditions: make([][]interface{}, len(group.Conditions)),
-				Operator:   group.Operator,
-			}
-			for j, condition := range group.Conditions {
-				clone.Search[i].Conditions[j] = make([]interface{}, len(condition))
-				copy(clone.Search[i].Conditions[j], condition)
-			}
-		}
+	// 深拷贝 Search / Having
+	clone.Search = cloneConditionGroups(q.Search)
+	clone.Having = cloneConditionGroups(q.Having)
+
+	// 深拷贝 Include / GroupBy
+	if len(q.Include) > 0 {
+		clone.Include = append([]string(nil), q.Include...)
+	}
+	if len(q.GroupBy) > 0 {
+		clone.GroupBy = append([]string(nil), q.GroupBy...)
 	}
 
 	// 深拷贝 OrderBy
@@ -153,3 +197,21 @@ func (q *Query) Clone() Query {
 
 	return clone
 }
+
+func cloneConditionGroups(groups []ConditionGroup) []ConditionGroup {
+	if len(groups) == 0 {
+		return nil
+	}
+	clone := make([]ConditionGroup, len(groups))
+	for i, group := range groups {
+		clone[i] = ConditionGroup{
+			Conditions: make([][]interface{}, len(group.Conditions)),
+			Operator:   group.Operator,
+		}
+		for j, condition := range group.Conditions {
+			clone[i].Conditions[j] = make([]interface{}, len(condition))
+			copy(clone[i].Conditions[j], condition)
+		}
+	}
+	return clone
+}
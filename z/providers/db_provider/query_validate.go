@@ -0,0 +1,322 @@
+package db_provider
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryLimits 客户端查询参数限制，字段 <= 0 时使用默认值
+type QueryLimits struct {
+	MaxGroups        int      // 条件组最大数量，默认 10
+	MaxConditions    int      // 每组最大条件数，默认 20
+	MaxValues        int      // in / not_in 最大值个数，默认 500
+	MaxOrderBy       int      // 最大排序字段数，默认 5
+	MaxGroupBy       int      // 最大分组字段数，默认 5
+	MaxRequired      int      // 最大 required 字段数，默认 10
+	MaxIncludes      int      // 最大 include 关联数，默认 5
+	MaxIncludeDepth  int      // include 关联最大嵌套层数，默认 3
+	MaxLimit         int      // limit 最大值，默认 100（与 ParseLimit 的截断一致）
+	MaxPage          int      // 最大页码，0 表示不限制
+	AllowedOperators []string // 允许的操作符（如 =、like、in，下划线与空格等价），为空时允许全部合法操作符
+	AllowedFields    []string // 允许查询 / 排序的字段，为空时不限制（仍校验字段名格式）
+	AllowedIncludes  []string // 允许预加载的关联路径（如 orders、orders.items），为空时不限制
+}
+
+// 默认查询限制
+const (
+	defaultQueryMaxGroups       = 10
+	defaultQueryMaxConditions   = 20
+	defaultQueryMaxValues       = 500
+	defaultQueryMaxOrderBy      = 5
+	defaultQueryMaxGroupBy      = 5
+	defaultQueryMaxRequired     = 10
+	defaultQueryMaxIncludes     = 5
+	defaultQueryMaxIncludeDepth = 3
+	defaultQueryMaxLimit        = 100
+)
+
+var (
+	queryLimitsMu sync.RWMutex
+	queryLimits   QueryLimits
+)
+
+// SetQueryLimits 设置 Query.Validate 的全局限制，由 db 提供者按 db.query.* 配置调用
+func SetQueryLimits(limits QueryLimits) {
+	queryLimitsMu.Lock()
+	defer queryLimitsMu.Unlock()
+	queryLimits = limits
+}
+
+// GetQueryLimits 返回补全默认值后的全局限制
+func GetQueryLimits() QueryLimits {
+	queryLimitsMu.RLock()
+	limits := queryLimits
+	queryLimitsMu.RUnlock()
+	return limits.normalize()
+}
+
+func (l QueryLimits) normalize() QueryLimits {
+	if l.MaxGroups <= 0 {
+		l.MaxGroups = defaultQueryMaxGroups
+	}
+	if l.MaxConditions <= 0 {
+		l.MaxConditions = defaultQueryMaxConditions
+	}
+	if l.MaxValues <= 0 {
+		l.MaxValues = defaultQueryMaxValues
+	}
+	if l.MaxOrderBy <= 0 {
+		l.MaxOrderBy = defaultQueryMaxOrderBy
+	}
+	if l.MaxGroupBy <= 0 {
+		l.MaxGroupBy = defaultQueryMaxGroupBy
+	}
+	if l.MaxRequired <= 0 {
+		l.MaxRequired = defaultQueryMaxRequired
+	}
+	if l.MaxIncludes <= 0 {
+		l.MaxIncludes = defaultQueryMaxIncludes
+	}
+	if l.MaxIncludeDepth <= 0 {
+		l.MaxIncludeDepth = defaultQueryMaxIncludeDepth
+	}
+	if l.MaxLimit <= 0 {
+		l.MaxLimit = defaultQueryMaxLimit
+	}
+	return l
+}
+
+// invalidQuery 查询参数校验错误，Field 为出错位置，如 search[0].conditions[1]
+func invalidQuery(field, format string, args ...interface{}) error {
+	return DBError{Code: ErrCodeInvalidData, Message: fmt.Sprintf(format, args...), Field: field}
+}
+
+// Validate 校验来自客户端的查询参数，在进入构建器前拒绝格式错误或超出限制的查询，未传 limits 时使用全局限制
+// 返回 DBError（Code 为 INVALID_DATA，Field 为出错位置）
+func (q Query) Validate(limits ...QueryLimits) error {
+	l := GetQueryLimits()
+	if len(limits) > 0 {
+		l = limits[0].normalize()
+	}
+	allowedOps := map[string]bool{}
+	for _, op := range l.AllowedOperators {
+		allowedOps[normalizeOperator(op)] = true
+	}
+	allowedFields := map[string]bool{}
+	for _, f := range l.AllowedFields {
+		allowedFields[f] = true
+	}
+	checkField := func(path, field string) error {
+		if !isValidFieldName(field) {
+			return invalidQuery(path, "invalid field name: %s", field)
+		}
+		if len(allowedFields) > 0 && !allowedFields[field] {
+			return invalidQuery(path, "field is not allowed: %s", field)
+		}
+		return nil
+	}
+
+	if len(q.Search) > l.MaxGroups {
+		return invalidQuery("search", "too many condition groups: %d > %d", len(q.Search), l.MaxGroups)
+	}
+	if err := validateConditionGroups("search", q.Search, l, allowedOps, checkField); err != nil {
+		return err
+	}
+
+	if len(q.GroupBy) > l.MaxGroupBy {
+		return invalidQuery("groupby", "too many group fields: %d > %d", len(q.GroupBy), l.MaxGroupBy)
+	}
+	for i, field := range q.GroupBy {
+		if err := checkField(fmt.Sprintf("groupby[%d]", i), field); err != nil {
+			return err
+		}
+	}
+	if len(q.Having) > 0 && len(q.GroupBy) == 0 {
+		return invalidQuery("having", "having requires groupby")
+	}
+	if len(q.Having) > l.MaxGroups {
+		return invalidQuery("having", "too many condition groups: %d > %d", len(q.Having), l.MaxGroups)
+	}
+	// having 字段多为聚合别名，只校验格式
+	checkAlias := func(path, field string) error {
+		if !isValidFieldName(field) {
+			return invalidQuery(path, "invalid field name: %s", field)
+		}
+		return nil
+	}
+	if err := validateConditionGroups("having", q.Having, l, allowedOps, checkAlias); err != nil {
+		return err
+	}
+
+	if len(q.OrderBy) > l.MaxOrderBy {
+		return invalidQuery("orderby", "too many order fields: %d > %d", len(q.OrderBy), l.MaxOrderBy)
+	}
+	for i, order := range q.OrderBy {
+		path := fmt.Sprintf("orderby[%d]", i)
+		if len(order) < 1 || len(order) > 2 {
+			return invalidQuery(path, "order must be [field] or [field, direction]")
+		}
+		if err := checkField(path, order[0]); err != nil {
+			return err
+		}
+		if len(order) == 2 {
+			if d := strings.ToLower(order[1]); d != "asc" && d != "desc" {
+				return invalidQuery(path, "invalid order direction: %s", order[1])
+			}
+		}
+	}
+
+	if len(q.Required) > l.MaxRequired {
+		return invalidQuery("required", "too many required fields: %d > %d", len(q.Required), l.MaxRequired)
+	}
+	for i, field := range q.Required {
+		if err := checkField(fmt.Sprintf("required[%d]", i), field); err != nil {
+			return err
+		}
+	}
+
+	if len(q.Include) > l.MaxIncludes {
+		return invalidQuery("include", "too many includes: %d > %d", len(q.Include), l.MaxIncludes)
+	}
+	allowedIncludes := map[string]bool{}
+	for _, rel := range l.AllowedIncludes {
+		allowedIncludes[strings.ToLower(rel)] = true
+	}
+	for i, item := range q.Include {
+		path := fmt.Sprintf("include[%d]", i)
+		relation, columns := splitInclude(item)
+		segments := strings.Split(relation, ".")
+		for _, segment := range segments {
+			if segment == "" || !isValidFieldName(segment) {
+				return invalidQuery(path, "invalid relation: %s", relation)
+			}
+		}
+		if len(segments) > l.MaxIncludeDepth {
+			return invalidQuery(path, "include too deep: %d > %d", len(segments), l.MaxIncludeDepth)
+		}
+		if len(allowedIncludes) > 0 && !allowedIncludes[strings.ToLower(relation)] {
+			return invalidQuery(path, "relation is not allowed: %s", relation)
+		}
+		for _, col := range columns {
+			if !isValidFieldName(col) || strings.Contains(col, ".") {
+				return invalidQuery(path, "invalid field name: %s", col)
+			}
+		}
+	}
+
+	if q.Limit < 0 || q.Limit > l.MaxLimit {
+		return invalidQuery("limit", "limit must be between 0 and %d", l.MaxLimit)
+	}
+	if q.Page < 0 {
+		return invalidQuery("page", "page must not be negative")
+	}
+	if l.MaxPage > 0 && q.Page > l.MaxPage {
+		return invalidQuery("page", "page exceeds %d", l.MaxPage)
+	}
+	return nil
+}
+
+// validateConditionGroups 校验条件组的操作符、条件数量及各条件的字段、操作符和值
+func validateConditionGroups(name string, groups []ConditionGroup, l QueryLimits, allowedOps map[string]bool, checkField func(path, field string) error) error {
+	for i, group := range groups {
+		path := fmt.Sprintf("%s[%d]", name, i)
+		switch strings.ToUpper(strings.TrimSpace(group.Operator)) {
+		case "", "AND", "OR":
+		default:
+			return invalidQuery(path+".operator", "invalid group operator: %s", group.Operator)
+		}
+		if len(group.Conditions) > l.MaxConditions {
+			return invalidQuery(path+".conditions", "too many conditions: %d > %d", len(group.Conditions), l.MaxConditions)
+		}
+		for j, condition := range group.Conditions {
+			cpath := fmt.Sprintf("%s.conditions[%d]", path, j)
+			if len(condition) < 2 || len(condition) > 3 {
+				return invalidQuery(cpath, "condition must be [field, value] or [field, value, operator]")
+			}
+			field, ok := condition[0].(string)
+			if !ok {
+				return invalidQuery(cpath, "field must be string")
+			}
+			if err := checkField(cpath, field); err != nil {
+				return err
+			}
+			op := "="
+			if len(condition) == 3 {
+				s, ok := condition[2].(string)
+				if !ok {
+					return invalidQuery(cpath, "operator must be string")
+				}
+				op = s
+			}
+			op = normalizeOperator(op)
+			if !isValidOperator(op) {
+				return invalidQuery(cpath, "invalid operator: %s", op)
+			}
+			if len(allowedOps) > 0 && !allowedOps[op] {
+				return invalidQuery(cpath, "operator is not allowed: %s", op)
+			}
+			if err := validateConditionValue(cpath, op, condition[1], l.MaxValues); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateConditionValue 校验条件值的形状：in / not in 为数组，between 为两个元素的数组，其余为标量
+func validateConditionValue(path, op string, value interface{}, maxValues int) error {
+	rv := reflect.ValueOf(value)
+	isList := value != nil && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8
+	switch op {
+	case "is null", "is not null":
+		return nil
+	case "in", "not in":
+		if !isList {
+			return invalidQuery(path, "value of %s must be an array", op)
+		}
+		if rv.Len() == 0 {
+			return invalidQuery(path, "value of %s must not be empty", op)
+		}
+		if rv.Len() > maxValues {
+			return invalidQuery(path, "too many values: %d > %d", rv.Len(), maxValues)
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if !isScalar(rv.Index(i).Interface()) {
+				return invalidQuery(path, "values of %s must be scalars", op)
+			}
+		}
+		return nil
+	case "between", "not between":
+		if !isList || rv.Len() != 2 {
+			return invalidQuery(path, "value of %s must be an array of 2 elements", op)
+		}
+		if !isScalar(rv.Index(0).Interface()) || !isScalar(rv.Index(1).Interface()) {
+			return invalidQuery(path, "values of %s must be scalars", op)
+		}
+		return nil
+	default:
+		if value != nil && !isScalar(value) {
+			return invalidQuery(path, "value of %s must be a scalar", op)
+		}
+		return nil
+	}
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case nil, time.Time, driver.Valuer:
+		return true
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
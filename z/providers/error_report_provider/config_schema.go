@@ -0,0 +1,25 @@
+package error_report_provider
+
+import (
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// errorReportConfig error_report.yml 配置结构
+type errorReportConfig struct {
+	Enabled       bool          `default:"false" desc:"启用错误上报"`
+	SentryDSN     string        `config:"sentry_dsn" desc:"Sentry DSN"`
+	Webhook       string        `desc:"Webhook 地址，与 sentry_dsn 可同时配置"`
+	Async         string        `desc:"设为 job 时经任务队列投递，需启用 job 提供者"`
+	Release       string        `desc:"版本号，默认 app.version"`
+	Environment   string        `desc:"运行环境，默认 app.env"`
+	SampleRate    float64       `default:"1" desc:"采样率 0-1"`
+	BatchSize     int           `default:"20" desc:"批量上报条数"`
+	FlushInterval time.Duration `default:"5s" desc:"批量上报间隔"`
+	QueueSize     int           `default:"1000" desc:"待上报队列长度，队列满时丢弃"`
+	ScrubHeaders  []string      `desc:"需脱敏的请求头，追加到默认列表"`
+	ScrubPatterns []string      `desc:"需脱敏的正则，追加到默认列表"`
+}
+
+var _ = config_provider.RegisterSchema("error_report", "错误上报", errorReportConfig{})
@@ -0,0 +1,189 @@
+package error_report_provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/job_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"go.uber.org/fx"
+)
+
+// DeliverJobName 通过任务队列投递报告时使用的任务名
+const DeliverJobName = "error_report.deliver"
+
+// ErrorReport 错误上报服务
+type ErrorReport struct {
+	reporter *z.BatchReporter
+	sink     z.ReportSink
+}
+
+type In struct {
+	fx.In
+
+	LC  fx.Lifecycle
+	Cfg *config_provider.Config
+	Log *logger_provider.Logger
+	Job *job_provider.JobClient `optional:"true"`
+}
+
+// NewErrorReportProvider 创建错误上报服务（fx Provider），启用后设置为全局 z.ErrorReporter
+//
+//	error_report:
+//	  enabled: true
+//	  sentry_dsn: https://key@sentry.example.com/1
+//	  webhook: https://collector.internal/errors  # 与 sentry_dsn 可同时配置
+//	  sample_rate: 0.5            # 普通错误采样率，panic 始终上报
+//	  release: v1.2.3             # 默认 app.version
+//	  environment: production     # 默认 app.env
+//	  scrub_headers: [Authorization, Cookie]
+//	  scrub_patterns: ['\d{11}']  # 消息、堆栈、URL 中替换为 [Filtered] 的正则
+//	  batch_size: 20
+//	  flush_interval: 5s
+//	  queue_size: 1000
+//	  async: job                  # 通过 job_provider 投递，失败由任务队列重试
+func NewErrorReportProvider(in In) (*ErrorReport, error) {
+	p := &ErrorReport{}
+	if !in.Cfg.GetBool("error_report.enabled", false) {
+		in.Log.Infow("provider[error_report] disabled")
+		return p, nil
+	}
+
+	sink, err := newSink(in.Cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.sink = sink
+
+	scrubber, err := newScrubber(in.Cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	async := strings.ToLower(strings.TrimSpace(in.Cfg.GetString("error_report.async")))
+	if async == "job" {
+		if in.Job == nil {
+			return nil, errors.New("error_report.async is job but job provider is not enabled")
+		}
+		sink = &jobSink{client: in.Job}
+	}
+
+	p.reporter = z.NewBatchReporter(sink, z.BatchReporterOptions{
+		Release:       in.Cfg.GetString("error_report.release", in.Cfg.GetString("app.version")),
+		Environment:   in.Cfg.GetString("error_report.environment", in.Cfg.GetString("app.env")),
+		SampleRate:    in.Cfg.GetFloat64("error_report.sample_rate", 1),
+		Scrubber:      scrubber,
+		BatchSize:     in.Cfg.GetInt("error_report.batch_size", 20),
+		FlushInterval: in.Cfg.GetDuration("error_report.flush_interval", 5*time.Second),
+		QueueSize:     in.Cfg.GetInt("error_report.queue_size", 1000),
+		OnError: func(err error, reports []z.ErrorReport) {
+			in.Log.Warnw("error report delivery failed", "reports", len(reports), "error", err)
+		},
+	})
+
+	in.LC.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			z.SetErrorReporter(p.reporter)
+			in.Log.Infow("provider[error_report] enabled", "async", async)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			z.SetErrorReporter(nil)
+			p.reporter.Close(ctx)
+			if dropped := p.reporter.Dropped(); dropped > 0 {
+				in.Log.Warnw("error reports dropped", "count", dropped)
+			}
+			in.Log.Infow("provider[error_report] stopped")
+			return nil
+		},
+	})
+	return p, nil
+}
+
+// Enabled 是否启用上报
+func (p *ErrorReport) Enabled() bool {
+	return p != nil && p.reporter != nil
+}
+
+// Flush 立即投递队列中的报告
+func (p *ErrorReport) Flush(ctx context.Context) {
+	if p.Enabled() {
+		p.reporter.Flush(ctx)
+	}
+}
+
+func newSink(cfg *config_provider.Config) (z.ReportSink, error) {
+	var sinks z.MultiSink
+	if dsn := strings.TrimSpace(cfg.GetString("error_report.sentry_dsn")); dsn != "" {
+		sentry, err := z.NewSentrySink(dsn, nil)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sentry)
+	}
+	if webhook := strings.TrimSpace(cfg.GetString("error_report.webhook")); webhook != "" {
+		sinks = append(sinks, &z.WebhookSink{URL: webhook})
+	}
+	switch len(sinks) {
+	case 0:
+		return nil, errors.New("error_report enabled but neither error_report.sentry_dsn nor error_report.webhook is set")
+	case 1:
+		return sinks[0], nil
+	}
+	return sinks, nil
+}
+
+func newScrubber(cfg *config_provider.Config) (z.ReportScrubber, error) {
+	scrubber := z.ReportScrubber{Headers: cfg.GetStringSlice("error_report.scrub_headers")}
+	patterns := cfg.GetStringSlice("error_report.scrub_patterns")
+	if len(patterns) == 0 {
+		return scrubber, nil
+	}
+	scrubber.Patterns = append([]*regexp.Regexp(nil), z.DefaultScrubPatterns...)
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return scrubber, fmt.Errorf("invalid error_report.scrub_patterns %q: %w", pattern, err)
+		}
+		scrubber.Patterns = append(scrubber.Patterns, re)
+	}
+	return scrubber, nil
+}
+
+// jobSink 将报告作为任务入队，由 worker 投递
+type jobSink struct {
+	client *job_provider.JobClient
+}
+
+func (s *jobSink) Send(ctx context.Context, reports []z.ErrorReport) error {
+	_, err := s.client.AddJob(ctx, DeliverJobName, reports, nil)
+	return err
+}
+
+// deliverHandler worker 端投递报告，失败时由任务队列按退避重试
+func deliverHandler(p *ErrorReport) job_provider.HandlerOut {
+	return job_provider.Register(DeliverJobName, func(ctx context.Context, job *job_provider.Job) error {
+		if p.sink == nil {
+			return z.Permanent(errors.New("error report sink not configured"))
+		}
+		var reports []z.ErrorReport
+		if err := json.Unmarshal(job.Payload, &reports); err != nil {
+			return z.Permanent(err)
+		}
+		return p.sink.Send(ctx, reports)
+	})
+}
+
+// ErrorReportProviderModule 错误上报模块
+var ErrorReportProviderModule = fx.Options(
+	fx.Provide(NewErrorReportProvider),
+	fx.Provide(deliverHandler),
+	fx.Invoke(func(_ *ErrorReport) {}),
+)
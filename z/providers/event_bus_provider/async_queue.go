@@ -0,0 +1,266 @@
+package event_bus_provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// OverflowPolicy 异步队列已满时的处理策略
+type OverflowPolicy string
+
+const (
+	OverflowDropNewest OverflowPolicy = "drop_newest" // 丢弃新事件（默认）
+	OverflowDropOldest OverflowPolicy = "drop_oldest" // 丢弃队列中最早的事件
+	OverflowBlock      OverflowPolicy = "block"       // 阻塞发布方直到入队或 ctx 结束
+)
+
+// AsyncOptions EmitAsync 的队列配置，每个监听器独立一个有界队列和固定数量的工作协程
+type AsyncOptions struct {
+	QueueSize     int            // 每个监听器的队列长度，默认 1024
+	Workers       int            // 每个监听器的工作协程数，默认 4；为 1 时同一监听器按发布顺序处理
+	Overflow      OverflowPolicy // 队列满时的策略，默认 drop_newest
+	SlowThreshold time.Duration  // 单次处理超过该耗时记录慢消费日志，0 表示不记录
+}
+
+func (o AsyncOptions) normalize() AsyncOptions {
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1024
+	}
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	switch o.Overflow {
+	case OverflowDropNewest, OverflowDropOldest, OverflowBlock:
+	default:
+		o.Overflow = OverflowDropNewest
+	}
+	return o
+}
+
+// ListenerStats 单个监听器的异步队列统计
+type ListenerStats struct {
+	Event      string        `json:"event"`
+	ListenerID uint64        `json:"listener_id"`
+	QueueDepth int           `json:"queue_depth"`
+	QueueSize  int           `json:"queue_size"`
+	Processed  uint64        `json:"processed"`
+	Dropped    uint64        `json:"dropped"`
+	Panics     uint64        `json:"panics"`
+	AvgLatency time.Duration `json:"avg_latency"`
+	MaxLatency time.Duration `json:"max_latency"`
+}
+
+// busMetrics OTel 指标，未配置 MeterProvider 时为空实现
+type busMetrics struct {
+	depth    metric.Int64UpDownCounter
+	dropped  metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+var (
+	metricsOnce sync.Once
+	metrics     busMetrics
+)
+
+func getBusMetrics() busMetrics {
+	metricsOnce.Do(func() {
+		meter := otel.Meter("github.com/icreateapp-com/go-zLib/event_bus")
+		metrics.depth, _ = meter.Int64UpDownCounter("event_bus.queue.depth", metric.WithDescription("异步队列中待处理的事件数"))
+		metrics.dropped, _ = meter.Int64Counter("event_bus.dropped", metric.WithDescription("因队列已满被丢弃的事件数"))
+		metrics.duration, _ = meter.Float64Histogram("event_bus.handler.duration", metric.WithUnit("ms"), metric.WithDescription("监听器处理耗时"))
+	})
+	return metrics
+}
+
+// asyncTask 队列中的待处理事件
+type asyncTask[T any] struct {
+	ctx   context.Context
+	event Event[T]
+}
+
+// listenerQueue 监听器的有界队列，工作协程在首次异步发布时启动，监听器移除时停止
+type listenerQueue[T any] struct {
+	eventName string
+	listener  *listenerWrapper[T]
+	opts      AsyncOptions
+	log       *logger_provider.Logger
+	metrics   busMetrics
+	attrs     metric.MeasurementOption
+
+	tasks    chan asyncTask[T]
+	done     chan struct{}
+	stopOnce sync.Once
+	inflight atomic.Int64
+
+	processed    atomic.Uint64
+	dropped      atomic.Uint64
+	panics       atomic.Uint64
+	latencyTotal atomic.Int64
+	latencyMax   atomic.Int64
+}
+
+func newListenerQueue[T any](eventName string, listener *listenerWrapper[T], opts AsyncOptions, log *logger_provider.Logger) *listenerQueue[T] {
+	q := &listenerQueue[T]{
+		eventName: eventName,
+		listener:  listener,
+		opts:      opts,
+		log:       log,
+		metrics:   getBusMetrics(),
+		attrs:     metric.WithAttributes(attribute.String("event", eventName)),
+		tasks:     make(chan asyncTask[T], opts.QueueSize),
+		done:      make(chan struct{}),
+	}
+	for i := 0; i < opts.Workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// stop 停止工作协程，队列中未处理的事件被丢弃
+func (q *listenerQueue[T]) stop() {
+	q.stopOnce.Do(func() {
+		close(q.done)
+		// 丢弃未处理的事件，保持队列深度指标准确
+		for {
+			select {
+			case <-q.tasks:
+				q.metrics.depth.Add(context.Background(), -1, q.attrs)
+			default:
+				return
+			}
+		}
+	})
+}
+
+// enqueue 按溢出策略入队，返回是否成功
+func (q *listenerQueue[T]) enqueue(task asyncTask[T]) bool {
+	select {
+	case <-q.done:
+		return false
+	default:
+	}
+
+	select {
+	case q.tasks <- task:
+		q.metrics.depth.Add(context.Background(), 1, q.attrs)
+		return true
+	default:
+	}
+
+	switch q.opts.Overflow {
+	case OverflowBlock:
+		ctx := task.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		select {
+		case q.tasks <- task:
+			q.metrics.depth.Add(context.Background(), 1, q.attrs)
+			return true
+		case <-ctx.Done():
+		case <-q.done:
+			return false
+		}
+	case OverflowDropOldest:
+		select {
+		case <-q.tasks:
+			q.metrics.depth.Add(context.Background(), -1, q.attrs)
+			q.drop()
+		default:
+		}
+		select {
+		case q.tasks <- task:
+			q.metrics.depth.Add(context.Background(), 1, q.attrs)
+			return true
+		default:
+		}
+	}
+	q.drop()
+	return false
+}
+
+func (q *listenerQueue[T]) drop() {
+	n := q.dropped.Add(1)
+	q.metrics.dropped.Add(context.Background(), 1, q.attrs)
+	// 持续溢出时按间隔记录，避免日志放大
+	if q.log != nil && (n == 1 || n%1000 == 0) {
+		q.log.Warnw("event listener queue overflow", "event", q.eventName, "listener_id", q.listener.id, "policy", string(q.opts.Overflow), "dropped", n)
+	}
+}
+
+func (q *listenerQueue[T]) work() {
+	for {
+		select {
+		case <-q.done:
+			return
+		case task := <-q.tasks:
+			q.metrics.depth.Add(context.Background(), -1, q.attrs)
+			q.inflight.Add(1)
+			q.handle(task)
+			q.inflight.Add(-1)
+		}
+	}
+}
+
+func (q *listenerQueue[T]) handle(task asyncTask[T]) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			q.panics.Add(1)
+			if q.log != nil {
+				q.log.Errorw("panic in event listener", "event", q.eventName, "panic", fmt.Sprint(r))
+			}
+		}
+		q.observe(time.Since(start))
+	}()
+	q.listener.listener(task.ctx, task.event)
+}
+
+// observe 记录处理耗时，超过阈值时记录慢消费日志
+func (q *listenerQueue[T]) observe(elapsed time.Duration) {
+	q.processed.Add(1)
+	q.latencyTotal.Add(int64(elapsed))
+	for {
+		max := q.latencyMax.Load()
+		if int64(elapsed) <= max || q.latencyMax.CompareAndSwap(max, int64(elapsed)) {
+			break
+		}
+	}
+	q.metrics.duration.Record(context.Background(), float64(elapsed)/float64(time.Millisecond), q.attrs)
+
+	if q.opts.SlowThreshold > 0 && elapsed > q.opts.SlowThreshold && q.log != nil {
+		q.log.Warnw("slow event listener", "event", q.eventName, "listener_id", q.listener.id, "elapsed", elapsed.String(), "queue_depth", len(q.tasks))
+	}
+}
+
+// pending 返回排队和处理中的事件数
+func (q *listenerQueue[T]) pending() int {
+	return len(q.tasks) + int(q.inflight.Load())
+}
+
+func (q *listenerQueue[T]) stats() ListenerStats {
+	processed := q.processed.Load()
+	var avg time.Duration
+	if processed > 0 {
+		avg = time.Duration(q.latencyTotal.Load() / int64(processed))
+	}
+	return ListenerStats{
+		Event:      q.eventName,
+		ListenerID: q.listener.id,
+		QueueDepth: len(q.tasks),
+		QueueSize:  cap(q.tasks),
+		Processed:  processed,
+		Dropped:    q.dropped.Load(),
+		Panics:     q.panics.Load(),
+		AvgLatency: avg,
+		MaxLatency: time.Duration(q.latencyMax.Load()),
+	}
+}
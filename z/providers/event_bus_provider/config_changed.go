@@ -0,0 +1,20 @@
+package event_bus_provider
+
+import (
+	"context"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"go.uber.org/fx"
+)
+
+// BridgeConfigChanges 将配置变更转发为 config.changed 事件，载荷为 config_provider.ChangeEvent
+func BridgeConfigChanges(cfg *config_provider.Config, bus *EventBus) {
+	cfg.OnChange(func(event config_provider.ChangeEvent) {
+		bus.Emit(context.Background(), config_provider.EventConfigChanged, event)
+	})
+}
+
+// ConfigChangedModule 启用配置变更事件转发
+var ConfigChangedModule = fx.Options(
+	fx.Invoke(BridgeConfigChanges),
+)
@@ -0,0 +1,15 @@
+package event_bus_provider
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// eventBusConfig event_bus.yml 配置结构
+type eventBusConfig struct {
+	Async struct {
+		QueueSize       int    `default:"1024" desc:"异步事件队列长度"`
+		Workers         int    `default:"4" desc:"异步处理协程数"`
+		Overflow        string `default:"drop_newest" desc:"队列满时的策略：drop_newest / drop_oldest / block"`
+		SlowThresholdMs int    `config:"slow_threshold_ms" desc:"慢处理告警阈值（毫秒），0 不告警"`
+	} `desc:"异步事件（EmitAsync）"`
+}
+
+var _ = config_provider.RegisterSchema("event_bus", "事件总线", eventBusConfig{})
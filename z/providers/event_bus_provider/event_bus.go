@@ -3,9 +3,12 @@ package event_bus_provider
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
 	"go.uber.org/fx"
 )
@@ -13,8 +16,13 @@ import (
 type EventBus = eventBusProvider[any]
 
 // NewEventBusProvider 创建 EventBusProvider 实例（fx Provider）。
-func NewEventBusProvider(lc fx.Lifecycle, log *logger_provider.Logger) *EventBus {
-	bus := NewEventBus[any]().WithLogger(log)
+func NewEventBusProvider(lc fx.Lifecycle, cfg *config_provider.Config, log *logger_provider.Logger) *EventBus {
+	bus := NewEventBus[any]().WithLogger(log).WithAsyncOptions(AsyncOptions{
+		QueueSize:     cfg.GetInt("event_bus.async.queue_size", 1024),
+		Workers:       cfg.GetInt("event_bus.async.workers", 4),
+		Overflow:      OverflowPolicy(cfg.GetString("event_bus.async.overflow", string(OverflowDropNewest))),
+		SlowThreshold: time.Duration(cfg.GetInt("event_bus.async.slow_threshold_ms", 0)) * time.Millisecond,
+	})
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -24,6 +32,8 @@ func NewEventBusProvider(lc fx.Lifecycle, log *logger_provider.Logger) *EventBus
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
+			// 等待异步队列处理完毕，超时后丢弃剩余事件
+			bus.Drain(ctx)
 			bus.Clear()
 			if log != nil {
 				log.Infow("provider[event_bus] stopped")
@@ -54,6 +64,24 @@ type Listener[T any] func(ctx context.Context, event Event[T])
 type listenerWrapper[T any] struct {
 	id       uint64      // 监听器唯一ID
 	listener Listener[T] // 泛型处理函数
+
+	queueOnce sync.Once                        // 首次异步发布时创建队列
+	queue     atomic.Pointer[listenerQueue[T]] // 异步队列
+}
+
+// asyncQueue 返回监听器的异步队列，首次调用时创建，需在持有 bus.lock 时调用
+func (w *listenerWrapper[T]) asyncQueue(bus *eventBusProvider[T], eventName string) *listenerQueue[T] {
+	w.queueOnce.Do(func() {
+		w.queue.Store(newListenerQueue(eventName, w, bus.async, bus.log))
+	})
+	return w.queue.Load()
+}
+
+// stopQueue 停止监听器的异步队列，需在持有 bus.lock 写锁时调用
+func (w *listenerWrapper[T]) stopQueue() {
+	if q := w.queue.Load(); q != nil {
+		q.stop()
+	}
 }
 
 // eventBusProvider 泛型事件总线提供者
@@ -62,6 +90,7 @@ type eventBusProvider[T any] struct {
 	lock      sync.RWMutex                     // 读写锁
 	nextID    uint64                           // 下一个监听器ID
 	log       *logger_provider.Logger          // 日志（可选）
+	async     AsyncOptions                     // 异步队列配置
 }
 
 // NewEventBus 创建一个新的泛型事件总线实例
@@ -69,6 +98,7 @@ func NewEventBus[T any]() *eventBusProvider[T] {
 	return &eventBusProvider[T]{
 		listeners: make(map[string][]*listenerWrapper[T]),
 		nextID:    1,
+		async:     AsyncOptions{}.normalize(),
 	}
 }
 
@@ -78,6 +108,14 @@ func (bus *eventBusProvider[T]) WithLogger(log *logger_provider.Logger) *eventBu
 	return bus
 }
 
+// WithAsyncOptions 设置异步队列配置，仅对之后首次异步发布的监听器生效
+func (bus *eventBusProvider[T]) WithAsyncOptions(opts AsyncOptions) *eventBusProvider[T] {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	bus.async = opts.normalize()
+	return bus
+}
+
 // On 注册监听器，返回监听器ID用于取消订阅
 func (bus *eventBusProvider[T]) On(eventName string, listener Listener[T]) uint64 {
 	// 验证事件名称
@@ -131,7 +169,8 @@ func (bus *eventBusProvider[T]) Emit(ctx context.Context, eventName string, payl
 	}
 }
 
-// EmitAsync 异步广播事件
+// EmitAsync 异步广播事件，事件进入各监听器的有界队列，由固定数量的工作协程处理
+// 队列已满时按 AsyncOptions.Overflow 处理，block 策略下最长阻塞到 ctx 结束
 func (bus *eventBusProvider[T]) EmitAsync(ctx context.Context, eventName string, payload T) {
 	// 验证事件名称
 	if eventName == "" {
@@ -139,24 +178,68 @@ func (bus *eventBusProvider[T]) EmitAsync(ctx context.Context, eventName string,
 	}
 
 	bus.lock.RLock()
-	defer bus.lock.RUnlock()
-
 	wrappers := bus.listeners[eventName]
+	queues := make([]*listenerQueue[T], 0, len(wrappers))
+	for _, wrapper := range wrappers {
+		queues = append(queues, wrapper.asyncQueue(bus, eventName))
+	}
+	bus.lock.RUnlock()
+
 	event := Event[T]{Name: eventName, Payload: payload, Context: ctx}
+	for _, q := range queues {
+		q.enqueue(asyncTask[T]{ctx: ctx, event: event})
+	}
+}
 
-	for _, wrapper := range wrappers {
-		go func(w *listenerWrapper[T], e Event[T]) {
-			// 添加 panic 恢复机制
-			defer func() {
-				if r := recover(); r != nil {
-					if bus.log != nil {
-						bus.log.Errorw("panic in event listener", "event", eventName, "panic", fmt.Sprint(r))
-					}
-				}
-			}()
-			w.listener(ctx, e)
-		}(wrapper, event)
+// Drain 等待所有异步队列处理完毕，ctx 结束时返回 false
+func (bus *eventBusProvider[T]) Drain(ctx context.Context) bool {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if bus.pending() == 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+func (bus *eventBusProvider[T]) pending() int {
+	bus.lock.RLock()
+	defer bus.lock.RUnlock()
+	n := 0
+	for _, wrappers := range bus.listeners {
+		for _, wrapper := range wrappers {
+			if q := wrapper.queue.Load(); q != nil {
+				n += q.pending()
+			}
+		}
 	}
+	return n
+}
+
+// Stats 返回已启用异步队列的监听器统计，按事件名和监听器 ID 排序
+func (bus *eventBusProvider[T]) Stats() []ListenerStats {
+	bus.lock.RLock()
+	defer bus.lock.RUnlock()
+	out := make([]ListenerStats, 0)
+	for _, wrappers := range bus.listeners {
+		for _, wrapper := range wrappers {
+			if q := wrapper.queue.Load(); q != nil {
+				out = append(out, q.stats())
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Event != out[j].Event {
+			return out[i].Event < out[j].Event
+		}
+		return out[i].ListenerID < out[j].ListenerID
+	})
+	return out
 }
 
 // Off 通过监听器ID取消订阅
@@ -177,6 +260,7 @@ func (bus *eventBusProvider[T]) Off(eventName string, listenerID uint64) bool {
 	// 查找并移除指定ID的监听器
 	for i, wrapper := range wrappers {
 		if wrapper.id == listenerID {
+			wrapper.stopQueue()
 			// 移除监听器
 			bus.listeners[eventName] = append(wrappers[:i], wrappers[i+1:]...)
 
@@ -219,6 +303,11 @@ func (bus *eventBusProvider[T]) GetAllEvents() []string {
 func (bus *eventBusProvider[T]) Clear() {
 	bus.lock.Lock()
 	defer bus.lock.Unlock()
+	for _, wrappers := range bus.listeners {
+		for _, wrapper := range wrappers {
+			wrapper.stopQueue()
+		}
+	}
 	bus.listeners = make(map[string][]*listenerWrapper[T])
 }
 
@@ -226,5 +315,8 @@ func (bus *eventBusProvider[T]) Clear() {
 func (bus *eventBusProvider[T]) ClearEvent(eventName string) {
 	bus.lock.Lock()
 	defer bus.lock.Unlock()
+	for _, wrapper := range bus.listeners[eventName] {
+		wrapper.stopQueue()
+	}
 	delete(bus.listeners, eventName)
 }
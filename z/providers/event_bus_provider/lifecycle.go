@@ -0,0 +1,148 @@
+package event_bus_provider
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/fx/fxevent"
+)
+
+// 应用生命周期事件名称
+const (
+	EventAppStarting         = "app.starting"
+	EventAppReady            = "app.ready"
+	EventAppStopping         = "app.stopping"
+	EventProviderInitialized = "provider.initialized"
+)
+
+// 组件初始化阶段
+const (
+	ProviderStageConstruct = "construct" // 构造函数执行
+	ProviderStageStart     = "start"     // OnStart 钩子执行
+)
+
+// AppEvent 应用生命周期事件载荷
+type AppEvent struct {
+	Phase    string        `json:"phase"`
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"duration"` // app.ready 为启动总耗时，其它阶段为 0
+}
+
+// ProviderEvent provider.initialized 事件载荷
+type ProviderEvent struct {
+	Name     string        `json:"name"`  // 构造函数名，如 redis_provider.NewRedisProvider
+	Stage    string        `json:"stage"` // construct / start
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// LifecycleRecorder 作为 fx 日志接收容器事件，转发为事件总线上的生命周期事件
+// 事件总线就绪前的构造事件会缓存，在 app.starting 之前补发，确保 Invoke 中注册的监听器都能收到
+type LifecycleRecorder struct {
+	mu      sync.Mutex
+	bus     *EventBus
+	started bool
+	pending []ProviderEvent
+	startAt time.Time
+}
+
+// NewLifecycleRecorder 创建生命周期事件记录器
+func NewLifecycleRecorder() *LifecycleRecorder {
+	return &LifecycleRecorder{}
+}
+
+// Attach 绑定事件总线，bus 为空时所有事件被忽略
+func (r *LifecycleRecorder) Attach(bus *EventBus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bus = bus
+}
+
+// LogEvent 实现 fxevent.Logger
+func (r *LifecycleRecorder) LogEvent(event fxevent.Event) {
+	switch e := event.(type) {
+	case *fxevent.Run:
+		// 忽略 fx 内部构造函数（如 fx.WithLogger 注册的函数）
+		if e.Kind != "provide" || strings.HasPrefix(shortFuncName(e.Name), "fx.") {
+			return
+		}
+		r.record(ProviderEvent{Name: shortFuncName(e.Name), Stage: ProviderStageConstruct, Duration: e.Runtime, Error: errString(e.Err)})
+	case *fxevent.OnStartExecuted:
+		r.record(ProviderEvent{Name: shortFuncName(e.CallerName), Stage: ProviderStageStart, Duration: e.Runtime, Error: errString(e.Err)})
+	}
+}
+
+// Starting 补发缓存的构造事件并发布 app.starting
+func (r *LifecycleRecorder) Starting(ctx context.Context) {
+	r.mu.Lock()
+	bus := r.bus
+	pending := r.pending
+	r.pending = nil
+	r.started = true
+	r.startAt = time.Now()
+	r.mu.Unlock()
+
+	if bus == nil {
+		return
+	}
+	for _, event := range pending {
+		bus.Emit(ctx, EventProviderInitialized, event)
+	}
+	bus.Emit(ctx, EventAppStarting, AppEvent{Phase: EventAppStarting, At: time.Now()})
+}
+
+// Ready 发布 app.ready，载荷包含启动耗时
+func (r *LifecycleRecorder) Ready(ctx context.Context) {
+	r.mu.Lock()
+	bus := r.bus
+	startAt := r.startAt
+	r.mu.Unlock()
+
+	if bus == nil {
+		return
+	}
+	now := time.Now()
+	bus.Emit(ctx, EventAppReady, AppEvent{Phase: EventAppReady, At: now, Duration: now.Sub(startAt)})
+}
+
+// Stopping 同步发布 app.stopping，监听器返回后才开始停止各组件
+func (r *LifecycleRecorder) Stopping(ctx context.Context) {
+	r.mu.Lock()
+	bus := r.bus
+	r.mu.Unlock()
+
+	if bus == nil {
+		return
+	}
+	bus.Emit(ctx, EventAppStopping, AppEvent{Phase: EventAppStopping, At: time.Now()})
+}
+
+func (r *LifecycleRecorder) record(event ProviderEvent) {
+	r.mu.Lock()
+	if !r.started || r.bus == nil {
+		r.pending = append(r.pending, event)
+		r.mu.Unlock()
+		return
+	}
+	bus := r.bus
+	r.mu.Unlock()
+
+	bus.Emit(context.Background(), EventProviderInitialized, event)
+}
+
+// shortFuncName 去掉包路径和参数括号，如 github.com/x/redis_provider.NewRedisProvider() -> redis_provider.NewRedisProvider
+func shortFuncName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, "()")
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,267 @@
+package job_provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// callbackTaskName 回调投递任务名，与业务任务共用队列
+const callbackTaskName = "zlib:job.callback"
+
+// 回调请求头
+const (
+	CallbackHeaderJobID     = "X-Job-Id"
+	CallbackHeaderTimestamp = "X-Job-Timestamp"
+	CallbackHeaderSignature = "X-Job-Signature" // sha256=hex(hmac_sha256(secret, timestamp + "." + body))，未配置密钥时不发送
+)
+
+// JobCallbackPayload 任务结束回调载荷，同时作为 CallbackEvent 事件的载荷
+type JobCallbackPayload struct {
+	JobID       string          `json:"job_id"`
+	Name        string          `json:"name"`
+	Status      JobStatus       `json:"status"` // completed / failed
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Attempts    int             `json:"attempts"`
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at"`
+	CompletedAt *time.Time      `json:"completed_at"`
+}
+
+// callbackTask 回调投递任务的内容
+type callbackTask struct {
+	URL     string             `json:"url"`
+	Payload JobCallbackPayload `json:"payload"`
+}
+
+// callbackConfig job.callback.* 配置
+type callbackConfig struct {
+	secret     string
+	maxRetries int
+	timeout    time.Duration
+	receivers  []callbackReceiver
+	policy     z.OutboundPolicy
+	client     *http.Client
+}
+
+// callbackReceiver 回调接收方，按主机名使用独立的签名密钥
+type callbackReceiver struct {
+	hosts  z.OutboundPolicy // 仅使用 AllowedHosts 匹配主机名
+	secret string
+}
+
+// callbackConfigFrom 读取回调配置；回调地址由提交任务的调用方提供，默认仅允许公网地址
+//
+//	job:
+//	  callback:
+//	    secret: xxx                          # 未匹配 receivers 时使用的签名密钥
+//	    allowed_hosts: [hooks.example.com]   # 允许的主机名，支持 *.example.com；与 receivers 的 hosts 合并，均为空时不限主机名
+//	    allow_private: false                 # 允许回环、内网及链路本地地址，仅用于本地开发
+//	    receivers:
+//	      partner_a:
+//	        hosts: [hooks.partner-a.com]
+//	        secret: yyy
+func callbackConfigFrom(cfg *config_provider.Config) callbackConfig {
+	timeoutSeconds := cfg.GetInt("job.callback.timeout", 10)
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 10
+	}
+	maxRetries := cfg.GetInt("job.callback.max_retries", 5)
+	if maxRetries <= 0 {
+		maxRetries = 5
+	}
+	cc := callbackConfig{
+		secret:     cfg.GetString("job.callback.secret"),
+		maxRetries: maxRetries,
+		timeout:    time.Duration(timeoutSeconds) * time.Second,
+		policy: z.OutboundPolicy{
+			AllowedHosts: cfg.GetStringSlice("job.callback.allowed_hosts"),
+			AllowPrivate: cfg.GetBool("job.callback.allow_private", false),
+		},
+	}
+	for name := range cfg.GetStringMap("job.callback.receivers") {
+		prefix := "job.callback.receivers." + name
+		hosts := cfg.GetStringSlice(prefix + ".hosts")
+		if len(hosts) == 0 {
+			continue
+		}
+		cc.receivers = append(cc.receivers, callbackReceiver{
+			hosts:  z.OutboundPolicy{AllowedHosts: hosts},
+			secret: cfg.GetString(prefix + ".secret"),
+		})
+		cc.policy.AllowedHosts = append(cc.policy.AllowedHosts, hosts...)
+	}
+	cc.client = cc.policy.NewHttpClient(0)
+	return cc
+}
+
+// secretFor 返回回调地址对应接收方的签名密钥，未匹配时使用 job.callback.secret
+func (c callbackConfig) secretFor(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return c.secret
+	}
+	for _, r := range c.receivers {
+		if r.hosts.HostAllowed(u.Hostname()) {
+			return r.secret
+		}
+	}
+	return c.secret
+}
+
+// SignCallback 计算回调签名，接收方按相同方式计算并与 X-Job-Signature 比较
+func SignCallback(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCallback 校验回调签名，maxAge > 0 时同时校验时间戳
+func VerifyCallback(secret, timestamp, signature string, body []byte, maxAge time.Duration) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	if maxAge > 0 {
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || time.Since(time.Unix(ts, 0)).Abs() > maxAge {
+			return false
+		}
+	}
+	return hmac.Equal([]byte(signature), []byte(SignCallback(secret, timestamp, body)))
+}
+
+// isFinalAttempt 判断本次执行结束后任务是否不再重试
+func isFinalAttempt(ctx context.Context, err error) bool {
+	if err == nil || errors.Is(err, asynq.SkipRetry) {
+		return true
+	}
+	retried, ok1 := asynq.GetRetryCount(ctx)
+	maxRetry, ok2 := asynq.GetMaxRetry(ctx)
+	return ok1 && ok2 && retried >= maxRetry
+}
+
+// notify 任务结束时发布回调事件并投递回调地址
+func (w *JobWorker) notify(ctx context.Context, job *Job, err error) {
+	if job.CallbackURL == "" && job.CallbackEvent == "" {
+		return
+	}
+	if !isFinalAttempt(ctx, err) {
+		return
+	}
+
+	payload := JobCallbackPayload{
+		JobID:       job.ID,
+		Name:        job.Name,
+		Status:      JobStatusCompleted,
+		Result:      job.Result,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+	}
+	if retried, ok := asynq.GetRetryCount(ctx); ok {
+		payload.Attempts = retried + 1
+	}
+	if err != nil {
+		payload.Status = JobStatusFailed
+		payload.Error = err.Error()
+	}
+
+	if job.CallbackEvent != "" && w.bus != nil {
+		w.bus.EmitAsync(context.Background(), job.CallbackEvent, payload)
+	}
+	if job.CallbackURL == "" {
+		return
+	}
+	if w.client == nil {
+		if w.log != nil {
+			w.log.Errorw("job callback skipped: client not initialized", "id", job.ID, "url", job.CallbackURL)
+		}
+		return
+	}
+
+	b, mErr := json.Marshal(callbackTask{URL: job.CallbackURL, Payload: payload})
+	if mErr != nil {
+		return
+	}
+	task := asynq.NewTask(callbackTaskName, b)
+	_, eErr := w.client.EnqueueContext(context.Background(), task,
+		asynq.Queue(w.queue),
+		asynq.MaxRetry(w.callback.maxRetries),
+		asynq.Timeout(w.callback.timeout+5*time.Second),
+	)
+	if eErr != nil && w.log != nil {
+		w.log.Errorw("job callback enqueue failed", "id", job.ID, "url", job.CallbackURL, "error", eErr)
+	}
+}
+
+// deliverCallback 投递回调，非 2xx 响应返回错误由队列按退避策略重试
+func (w *JobWorker) deliverCallback(ctx context.Context, task *asynq.Task) error {
+	var t callbackTask
+	if err := json.Unmarshal(task.Payload(), &t); err != nil {
+		return fmt.Errorf("%w: %v", asynq.SkipRetry, err)
+	}
+	body, err := json.Marshal(t.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", asynq.SkipRetry, err)
+	}
+
+	timestamp := strconv.FormatInt(w.clock.Now().Unix(), 10)
+	headers := map[string]string{
+		"Content-Type":          "application/json",
+		CallbackHeaderJobID:     t.Payload.JobID,
+		CallbackHeaderTimestamp: timestamp,
+	}
+	if secret := w.callback.secretFor(t.URL); secret != "" {
+		headers[CallbackHeaderSignature] = SignCallback(secret, timestamp, body)
+	}
+
+	// 配置可能在任务入队后变更，投递前重新校验；IP 在建立连接时由 client 校验
+	if err := w.callback.policy.CheckURL(t.URL); err != nil {
+		if w.log != nil {
+			w.log.Warnw("job callback rejected", "id", t.Payload.JobID, "url", t.URL, "error", err.Error())
+		}
+		return fmt.Errorf("%w: %w", asynq.SkipRetry, err)
+	}
+
+	resp, err := z.RequestWithResponse(z.RequestOptions{
+		Client:      w.callback.client,
+		URL:         t.URL,
+		Method:      "POST",
+		Headers:     headers,
+		ContentType: z.RequestContentTypeRaw,
+		Data:        body,
+		Timeout:     w.callback.timeout,
+		Context:     ctx,
+	})
+	if err == nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		err = &z.HttpStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if err != nil {
+		if w.log != nil {
+			w.log.Warnw("job callback failed", "id", t.Payload.JobID, "url", t.URL, "error", err.Error())
+		}
+		if z.IsPermanent(err) {
+			return fmt.Errorf("%w: %w", asynq.SkipRetry, err)
+		}
+		return err
+	}
+	if w.log != nil {
+		w.log.Infow("job callback delivered", "id", t.Payload.JobID, "url", t.URL, "status", resp.StatusCode)
+	}
+	return nil
+}
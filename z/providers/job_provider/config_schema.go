@@ -0,0 +1,42 @@
+package job_provider
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// jobConfig job.yml 配置结构
+type jobConfig struct {
+	Queue          string `default:"default" desc:"队列名"`
+	QueueNamespace string `desc:"队列命名空间，为空且 app.debug 为 true 时使用主机名，避免本地调试抢占共享队列的任务"`
+	Concurrency    int    `default:"10" desc:"worker 并发数"`
+	MaxRetries     int    `default:"3" desc:"默认最大重试次数"`
+	Timeout        int    `default:"3600" desc:"默认任务超时（秒）"`
+	Redis          struct {
+		Addr     string `desc:"地址，如 127.0.0.1:6379；host 非空时忽略"`
+		Host     string `desc:"主机地址"`
+		Port     int    `default:"6379" desc:"端口"`
+		Password string `desc:"密码"`
+		DB       int    `config:"db" desc:"数据库编号"`
+	} `desc:"独立的 Redis 连接，启用 redis 提供者时忽略"`
+	Callback struct {
+		Secret       string                 `desc:"回调签名密钥，未匹配 receivers 时使用"`
+		Timeout      int                    `default:"10" desc:"回调请求超时（秒）"`
+		MaxRetries   int                    `default:"5" desc:"回调失败最大重试次数"`
+		AllowedHosts []string               `desc:"允许的回调主机名，支持 *.example.com；与 receivers 的 hosts 合并，均为空时不限主机名"`
+		AllowPrivate bool                   `default:"false" desc:"允许回调回环、内网及链路本地地址，仅用于本地开发"`
+		Receivers    map[string]interface{} `desc:"回调接收方，按名称配置 hosts 与 secret，匹配的主机使用独立的签名密钥"`
+	} `desc:"任务完成回调"`
+	Progress struct {
+		TTL int `config:"ttl" default:"86400" desc:"进度记录保留时长（秒）"`
+	} `desc:"任务进度"`
+	Scheduler struct {
+		Election string `desc:"选主方式：redis / db / none，默认按可用的 redis、mysql 选择"`
+		Identity string `desc:"实例标识，默认为 主机名:进程号:随机串"`
+		Lease    int    `default:"15" desc:"领导权租约（秒）"`
+		Key      string `desc:"选主锁名，默认 zlib:job:scheduler:{队列名}"`
+	} `desc:"定时任务调度器"`
+	Compression struct {
+		Codec   string `default:"none" desc:"任务载荷压缩算法：none / gzip / zstd / snappy"`
+		MinSize int    `default:"1024" desc:"压缩阈值（字节）"`
+	} `desc:"任务载荷压缩"`
+}
+
+var _ = config_provider.RegisterSchema("job", "任务队列", jobConfig{})
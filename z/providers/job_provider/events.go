@@ -0,0 +1,95 @@
+package job_provider
+
+import (
+	"context"
+	"sync"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/event_bus_provider"
+)
+
+// EventStatusChanged 任务状态变更事件名（沿用旧版），载荷为 JobEvent
+const EventStatusChanged = "job.status.changed"
+
+// JobEventFilter 任务事件过滤条件，为空的字段不过滤
+type JobEventFilter struct {
+	Names    []string    // 任务名称
+	Statuses []JobStatus // 任务状态
+}
+
+// Match 判断事件是否满足过滤条件
+func (f JobEventFilter) Match(event JobEvent) bool {
+	if len(f.Names) > 0 && !z.InSlice(event.Name, f.Names) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !z.InSlice(event.Status, f.Statuses) {
+		return false
+	}
+	return true
+}
+
+// OnStatusChanged 订阅任务状态变更事件，返回取消订阅函数
+// ctx 结束时自动取消订阅；事件由 EmitAsync 发布，fn 在事件总线的工作协程中执行
+//
+//	unsubscribe := job_provider.OnStatusChanged(ctx, bus, func(e job_provider.JobEvent) {
+//		...
+//	}, job_provider.JobEventFilter{Names: []string{"order.export"}, Statuses: []job_provider.JobStatus{job_provider.JobStatusFailed}})
+//	defer unsubscribe()
+func OnStatusChanged(ctx context.Context, bus *event_bus_provider.EventBus, fn func(JobEvent), filters ...JobEventFilter) func() {
+	if bus == nil || fn == nil {
+		return func() {}
+	}
+	id := bus.On(EventStatusChanged, func(_ context.Context, e event_bus_provider.Event[any]) {
+		event, ok := jobEventFrom(e.Payload)
+		if !ok {
+			return
+		}
+		for _, f := range filters {
+			if !f.Match(event) {
+				return
+			}
+		}
+		fn(event)
+	})
+
+	done := make(chan struct{})
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			close(done)
+			bus.Off(EventStatusChanged, id)
+		})
+	}
+	if ctx != nil && ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				unsubscribe()
+			case <-done:
+			}
+		}()
+	}
+	return unsubscribe
+}
+
+// OnJob 订阅指定任务名称的状态变更事件
+func OnJob(ctx context.Context, bus *event_bus_provider.EventBus, name string, fn func(JobEvent)) func() {
+	return OnStatusChanged(ctx, bus, fn, JobEventFilter{Names: []string{name}})
+}
+
+// OnStatus 订阅指定状态的任务事件
+func OnStatus(ctx context.Context, bus *event_bus_provider.EventBus, fn func(JobEvent), statuses ...JobStatus) func() {
+	return OnStatusChanged(ctx, bus, fn, JobEventFilter{Statuses: statuses})
+}
+
+func jobEventFrom(payload any) (JobEvent, bool) {
+	switch v := payload.(type) {
+	case JobEvent:
+		return v, true
+	case *JobEvent:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return JobEvent{}, false
+}
@@ -3,17 +3,22 @@ package job_provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/event_bus_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/redis_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/supervisor_provider"
+	"github.com/icreateapp-com/go-zLib/z/scheduler"
 	"go.uber.org/fx"
 )
 
@@ -44,6 +49,23 @@ type Job struct {
 	MaxRetries  int             `json:"max_retries"`
 	Timeout     time.Duration   `json:"timeout"`
 	Error       string          `json:"error"`
+
+	CallbackURL   string          `json:"callback_url,omitempty"`   // 任务结束后 POST 结果的地址
+	CallbackEvent string          `json:"callback_event,omitempty"` // 任务结束后在 worker 事件总线上发布的事件名
+	Result        json.RawMessage `json:"result,omitempty"`         // 处理器通过 SetResult 设置，随回调发送
+	TrackProgress bool            `json:"track_progress,omitempty"` // 是否记录进度，见 JobClient.Progress / WatchProgress
+
+	progress func(percent float64, message string) // worker 注入，见 ReportProgress
+}
+
+// SetResult 设置任务结果，结果随完成回调发送
+func (j *Job) SetResult(result any) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	j.Result = b
+	return nil
 }
 
 // JobHandler 任务处理函数类型（保持旧版签名）
@@ -54,6 +76,7 @@ type JobHandler func(ctx context.Context, job *Job) error
 // JobEvent 任务事件结构体（与旧版一致）
 type JobEvent struct {
 	JobID  string    `json:"job_id"`
+	Name   string    `json:"name,omitempty"` // 任务名称
 	Status JobStatus `json:"status"`
 	Error  string    `json:"error,omitempty"`
 }
@@ -67,11 +90,16 @@ type JobHandlerRegister struct {
 // JobClient 用于在业务逻辑中 enqueue 任务（分布式场景：web 节点只需要 JobClient）
 type JobClient struct {
 	client     *asynq.Client
+	inspector  *asynq.Inspector
 	log        *logger_provider.Logger
 	bus        *event_bus_provider.EventBus
+	clock      z.Clock
+	progress   *progressStore
 	queue      string
 	maxRetries int
 	timeout    time.Duration
+	codec      *z.CompressionCodec // 任务载荷压缩（job.compression.*），nil 表示不压缩
+	callback   z.OutboundPolicy    // 回调地址限制（job.callback.allowed_hosts 等）
 }
 
 type AddJobOptions struct {
@@ -87,28 +115,49 @@ type AddJobOptions struct {
 	UniqueTTL *time.Duration
 	TaskID    *string
 	Retention *time.Duration
+
+	// CallbackURL 任务完成或重试耗尽失败后，worker 将带签名的结果 POST 到该地址（失败按 job.callback.max_retries 重试）
+	CallbackURL string
+	// CallbackEvent 任务结束后在 worker 的事件总线上发布该事件，载荷为 JobCallbackPayload
+	CallbackEvent string
+	// TrackProgress 记录任务进度（排队、执行中、处理器上报、结束结果），可跨进程读取或订阅
+	TrackProgress bool
 }
 
 // JobWorker 用于运行 worker 并执行任务（分布式场景：worker 节点只需要 JobWorker + 业务模块提供的 handlers）
 type JobWorker struct {
-	server *asynq.Server
-	mux    *asynq.ServeMux
-	log    *logger_provider.Logger
-	bus    *event_bus_provider.EventBus
+	server   *asynq.Server
+	mux      *asynq.ServeMux
+	client   *asynq.Client // 用于投递完成回调
+	log      *logger_provider.Logger
+	bus      *event_bus_provider.EventBus
+	clock    z.Clock
+	progress *progressStore
+	queue    string
+	callback callbackConfig
+	started  atomic.Bool
 }
 
+// jobWorkerHealthInterval 受监管时检查 Redis 连接的间隔
+const jobWorkerHealthInterval = 30 * time.Second
+
 type ClientIn struct {
 	fx.In
 	Cfg   *config_provider.Config
 	Log   *logger_provider.Logger
 	Redis *redis_provider.Redis        `optional:"true"`
 	Bus   *event_bus_provider.EventBus `optional:"true"`
+	Clock z.Clock                      `optional:"true"`
 }
 
 func NewJobClient(in ClientIn) (*JobClient, error) {
 	var client *asynq.Client
+	var inspector *asynq.Inspector
+	var progress *progressStore
 	if in.Redis != nil {
-		client = asynq.NewClientFromRedisClient(in.Redis.Client())
+		client = asynq.NewClientFromRedisClient(in.Redis.UniversalClient())
+		inspector = asynq.NewInspectorFromRedisClient(in.Redis.UniversalClient())
+		progress = newProgressStore(in.Cfg, in.Redis.UniversalClient())
 	} else {
 		// 兼容：允许 job.yml 单独配置 redis
 		redisHost := strings.TrimSpace(in.Cfg.GetString("job.redis.host"))
@@ -124,6 +173,8 @@ func NewJobClient(in ClientIn) (*JobClient, error) {
 		}
 		redisOpt := asynq.RedisClientOpt{Addr: redisAddr, Password: redisPassword, DB: redisDB}
 		client = asynq.NewClient(redisOpt)
+		inspector = asynq.NewInspector(redisOpt)
+		progress = newProgressStore(in.Cfg, progressRedisClient(redisOpt))
 	}
 
 	queue := resolveQueueName(in.Cfg)
@@ -134,7 +185,14 @@ func NewJobClient(in ClientIn) (*JobClient, error) {
 	}
 	timeout := time.Duration(timeoutSeconds) * time.Second
 
-	return &JobClient{client: client, log: in.Log, bus: in.Bus, queue: queue, maxRetries: maxRetries, timeout: timeout}, nil
+	// 载荷压缩：job.compression.codec（gzip / zstd / snappy，默认不压缩），超过 job.compression.min_size 字节时压缩
+	// worker 按载荷头部标记解压，未压缩的载荷原样处理
+	codec, err := z.NewCompressionCodec(in.Cfg.GetString("job.compression.codec", ""), in.Cfg.GetInt("job.compression.min_size", 0), "job")
+	if err != nil {
+		return nil, err
+	}
+
+	return &JobClient{client: client, inspector: inspector, log: in.Log, bus: in.Bus, clock: z.ClockOr(in.Clock), progress: progress, queue: queue, maxRetries: maxRetries, timeout: timeout, codec: codec, callback: callbackConfigFrom(in.Cfg).policy}, nil
 }
 
 type WorkerIn struct {
@@ -144,7 +202,11 @@ type WorkerIn struct {
 	Log      *logger_provider.Logger
 	Redis    *redis_provider.Redis        `optional:"true"`
 	Bus      *event_bus_provider.EventBus `optional:"true"`
+	Clock    z.Clock                      `optional:"true"`
 	Handlers []JobHandlerRegister         `group:"job_handlers"`
+
+	// Supervisor 启用 supervisor_provider 时由其运行 worker，Redis 不可用时告警并按退避重试
+	Supervisor *supervisor_provider.Supervisor `optional:"true"`
 }
 
 func NewJobWorker(in WorkerIn) (*JobWorker, error) {
@@ -156,6 +218,8 @@ func NewJobWorker(in WorkerIn) (*JobWorker, error) {
 
 	redisDesc := ""
 	var server *asynq.Server
+	var client *asynq.Client
+	var progress *progressStore
 	serverCfg := asynq.Config{
 		Concurrency: concurrency,
 		Queues: map[string]int{
@@ -163,11 +227,10 @@ func NewJobWorker(in WorkerIn) (*JobWorker, error) {
 		},
 	}
 	if in.Redis != nil {
-		redisOpt := in.Redis.Client().Options()
-		if redisOpt != nil {
-			redisDesc = redisOpt.Addr
-		}
-		server = asynq.NewServerFromRedisClient(in.Redis.Client(), serverCfg)
+		redisDesc = in.Redis.Addr()
+		server = asynq.NewServerFromRedisClient(in.Redis.UniversalClient(), serverCfg)
+		client = asynq.NewClientFromRedisClient(in.Redis.UniversalClient())
+		progress = newProgressStore(in.Cfg, in.Redis.UniversalClient())
 	} else {
 		// 兼容：允许 job.yml 单独配置 redis
 		redisHost := strings.TrimSpace(in.Cfg.GetString("job.redis.host"))
@@ -184,11 +247,14 @@ func NewJobWorker(in WorkerIn) (*JobWorker, error) {
 		redisDesc = redisAddr
 		redisOpt := asynq.RedisClientOpt{Addr: redisAddr, Password: redisPassword, DB: redisDB}
 		server = asynq.NewServer(redisOpt, serverCfg)
+		client = asynq.NewClient(redisOpt)
+		progress = newProgressStore(in.Cfg, progressRedisClient(redisOpt))
 	}
 
 	mux := asynq.NewServeMux()
 	registered := 0
-	w := &JobWorker{server: server, mux: mux, log: in.Log, bus: in.Bus}
+	w := &JobWorker{server: server, mux: mux, client: client, log: in.Log, bus: in.Bus, clock: z.ClockOr(in.Clock), progress: progress, queue: queue, callback: callbackConfigFrom(in.Cfg)}
+	mux.HandleFunc(callbackTaskName, w.deliverCallback)
 	for _, r := range in.Handlers {
 		name := strings.TrimSpace(r.Name)
 		if name == "" || r.Handler == nil {
@@ -198,23 +264,37 @@ func NewJobWorker(in WorkerIn) (*JobWorker, error) {
 		mux.HandleFunc(name, func(h JobHandler) func(context.Context, *asynq.Task) error {
 			return func(ctx context.Context, task *asynq.Task) error {
 				var job Job
-				if err := json.Unmarshal(task.Payload(), &job); err != nil {
+				payload, err := z.Decompress(task.Payload())
+				if err != nil {
+					return fmt.Errorf("%w: %v", asynq.SkipRetry, err)
+				}
+				if err := json.Unmarshal(payload, &job); err != nil {
 					return err
 				}
-				w.emitJobEvent(job.ID, JobStatusRunning, "")
-				now := time.Now()
+				w.emitJobEvent(job.ID, job.Name, JobStatusRunning, "")
+				w.reportProgress(&job, JobProgress{Status: JobStatusRunning})
+				job.progress = func(percent float64, message string) {
+					w.reportProgress(&job, JobProgress{Status: JobStatusRunning, Percent: percent, Message: message})
+				}
+				now := w.clock.Now()
 				job.StartedAt = &now
-				err := h(ctx, &job)
-				completedAt := time.Now()
+				err = h(ctx, &job)
+				if z.IsPermanent(err) && !errors.Is(err, asynq.SkipRetry) {
+					// 确定不可重试的错误（如参数错误、4xx 响应）不再占用重试次数
+					err = fmt.Errorf("%w: %w", asynq.SkipRetry, err)
+				}
+				completedAt := w.clock.Now()
 				job.CompletedAt = &completedAt
+				w.notify(ctx, &job, err)
+				w.reportProgress(&job, finalProgress(ctx, &job, err))
 				if err != nil {
-					w.emitJobEvent(job.ID, JobStatusFailed, err.Error())
+					w.emitJobEvent(job.ID, job.Name, JobStatusFailed, err.Error())
 					if in.Log != nil {
 						in.Log.Infow("job executed", "id", job.ID, "name", job.Name, "status", "failed", "error", err.Error())
 					}
 					return err
 				}
-				w.emitJobEvent(job.ID, JobStatusCompleted, "")
+				w.emitJobEvent(job.ID, job.Name, JobStatusCompleted, "")
 				if in.Log != nil {
 					in.Log.Infow("job executed", "id", job.ID, "name", job.Name, "status", "completed")
 				}
@@ -225,14 +305,12 @@ func NewJobWorker(in WorkerIn) (*JobWorker, error) {
 
 	in.LC.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			// server.Run 会阻塞，因此异步启动
-			go func() {
-				if err := w.server.Run(w.mux); err != nil {
-					if w.log != nil {
-						w.log.Errorw("asynq server stopped", "error", err)
-					}
-				}
-			}()
+			// 使用 Start 而非 Run：Run 会自行监听退出信号，与 fx 的生命周期重复
+			if in.Supervisor != nil {
+				in.Supervisor.GoFunc("job.worker", w.run)
+			} else if err := w.start(); err != nil {
+				return err
+			}
 			if w.log != nil {
 				w.log.Infow("provider[job_worker] enabled", "redis", redisDesc, "queue", queue, "concurrency", concurrency, "handlers", registered)
 			}
@@ -241,6 +319,9 @@ func NewJobWorker(in WorkerIn) (*JobWorker, error) {
 		OnStop: func(ctx context.Context) error {
 			w.server.Stop()
 			w.server.Shutdown()
+			if w.client != nil && in.Redis == nil {
+				_ = w.client.Close()
+			}
 			return nil
 		},
 	})
@@ -248,6 +329,37 @@ func NewJobWorker(in WorkerIn) (*JobWorker, error) {
 	return w, nil
 }
 
+// start 启动 asynq server，已启动时直接返回
+func (w *JobWorker) start() error {
+	if !w.started.CompareAndSwap(false, true) {
+		return nil
+	}
+	if err := w.server.Start(w.mux); err != nil {
+		w.started.Store(false)
+		return err
+	}
+	return nil
+}
+
+// run 受监管运行：启动 server 后定期检查 Redis 连接，不可用时返回错误，由监管器发出告警并在退避后重新检查
+func (w *JobWorker) run(ctx context.Context) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(jobWorkerHealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.server.Ping(); err != nil {
+				return fmt.Errorf("job worker redis unavailable: %w", err)
+			}
+		}
+	}
+}
+
 // AddJob 添加任务并入队（破坏性改造：使用 payload + options，更符合 asynq 习惯）
 func (c *JobClient) AddJob(ctx context.Context, name string, payload any, opt *AddJobOptions) (*asynq.TaskInfo, error) {
 	if opt == nil {
@@ -272,15 +384,33 @@ func (c *JobClient) AddJob(ctx context.Context, name string, payload any, opt *A
 		timeout = *opt.Timeout
 	}
 
+	callbackURL := strings.TrimSpace(opt.CallbackURL)
+	if callbackURL != "" {
+		if !z.IsUrl(callbackURL) {
+			return nil, fmt.Errorf("job: invalid callback url: %s", callbackURL)
+		}
+		if err := c.callback.CheckURL(callbackURL); err != nil {
+			return nil, fmt.Errorf("job: invalid callback url: %w", err)
+		}
+	}
+
+	// 任务 ID 即 asynq TaskID，未指定时生成 UUID
 	jobID := uuid.New().String()
+	if opt.TaskID != nil && strings.TrimSpace(*opt.TaskID) != "" {
+		jobID = strings.TrimSpace(*opt.TaskID)
+	}
 	job := &Job{
 		ID:         jobID,
 		Name:       name,
 		Status:     JobStatusPending,
-		CreatedAt:  time.Now(),
+		CreatedAt:  c.clock.Now(),
 		RetryCount: 0,
 		MaxRetries: maxRetry,
 		Timeout:    timeout,
+
+		CallbackURL:   callbackURL,
+		CallbackEvent: strings.TrimSpace(opt.CallbackEvent),
+		TrackProgress: opt.TrackProgress,
 	}
 
 	// payload 序列化
@@ -296,6 +426,9 @@ func (c *JobClient) AddJob(ctx context.Context, name string, payload any, opt *A
 	if err != nil {
 		return nil, err
 	}
+	if jobBytes, err = c.codec.Encode(jobBytes); err != nil {
+		return nil, err
+	}
 
 	task := asynq.NewTask(name, jobBytes)
 	opts := []asynq.Option{
@@ -312,13 +445,18 @@ func (c *JobClient) AddJob(ctx context.Context, name string, payload any, opt *A
 	if opt.UniqueTTL != nil && *opt.UniqueTTL > 0 {
 		opts = append(opts, asynq.Unique(*opt.UniqueTTL))
 	}
-	if opt.TaskID != nil && strings.TrimSpace(*opt.TaskID) != "" {
-		opts = append(opts, asynq.TaskID(strings.TrimSpace(*opt.TaskID)))
-	}
+	opts = append(opts, asynq.TaskID(jobID))
 	if opt.Retention != nil && *opt.Retention > 0 {
 		opts = append(opts, asynq.Retention(*opt.Retention))
 	}
 
+	// 入队前写入排队状态，避免 worker 先写入的执行进度被覆盖
+	if job.TrackProgress && c.progress != nil {
+		if err := c.progress.init(ctx, JobProgress{JobID: jobID, Name: name, Status: JobStatusPending, UpdatedAt: job.CreatedAt}); err != nil && c.log != nil {
+			c.log.Warnw("job progress save failed", "id", jobID, "name", name, "error", err)
+		}
+	}
+
 	info, err := c.client.EnqueueContext(ctx, task, opts...)
 	if err != nil {
 		// asynq 在相同 TaskID 已存在时会返回冲突错误；对业务层来说这是幂等场景，自动忽略并返回成功
@@ -331,7 +469,7 @@ func (c *JobClient) AddJob(ctx context.Context, name string, payload any, opt *A
 		return nil, err
 	}
 
-	c.emitJobEvent(jobID, JobStatusPending, "")
+	c.emitJobEvent(jobID, name, JobStatusPending, "")
 	if c.log != nil {
 		c.log.Infow("job enqueued", "id", jobID, "name", name, "queue", info.Queue, "next_process_at", info.NextProcessAt)
 	}
@@ -339,22 +477,40 @@ func (c *JobClient) AddJob(ctx context.Context, name string, payload any, opt *A
 	return info, nil
 }
 
-func (c *JobClient) emitJobEvent(jobID string, status JobStatus, errorMsg string) {
+// Queue 返回当前进程使用的队列名
+func (c *JobClient) Queue() string {
+	return c.queue
+}
+
+// RequeueDeadLetters 将已归档（重试耗尽）的任务重新入队，queue 为空时使用当前队列
+func (c *JobClient) RequeueDeadLetters(queue string) (int, error) {
+	if c.inspector == nil {
+		return 0, fmt.Errorf("job: inspector not initialized")
+	}
+	if strings.TrimSpace(queue) == "" {
+		queue = c.queue
+	}
+	n, err := c.inspector.RunAllArchivedTasks(queue)
+	if errors.Is(err, asynq.ErrQueueNotFound) {
+		return 0, nil
+	}
+	return n, err
+}
+
+func (c *JobClient) emitJobEvent(jobID, name string, status JobStatus, errorMsg string) {
 	if c == nil || c.bus == nil {
 		return
 	}
-	event := JobEvent{JobID: jobID, Status: status, Error: errorMsg}
-	// 复用旧事件名
-	c.bus.EmitAsync(context.Background(), "job.status.changed", event)
+	event := JobEvent{JobID: jobID, Name: name, Status: status, Error: errorMsg}
+	c.bus.EmitAsync(context.Background(), EventStatusChanged, event)
 }
 
-func (w *JobWorker) emitJobEvent(jobID string, status JobStatus, errorMsg string) {
+func (w *JobWorker) emitJobEvent(jobID, name string, status JobStatus, errorMsg string) {
 	if w == nil || w.bus == nil {
 		return
 	}
-	event := JobEvent{JobID: jobID, Status: status, Error: errorMsg}
-	// 复用旧事件名
-	w.bus.EmitAsync(context.Background(), "job.status.changed", event)
+	event := JobEvent{JobID: jobID, Name: name, Status: status, Error: errorMsg}
+	w.bus.EmitAsync(context.Background(), EventStatusChanged, event)
 }
 
 type HandlerOut struct {
@@ -419,4 +575,8 @@ var JobProviderModule = fx.Options(
 	fx.Invoke(func(_ *JobClient) {}),
 	fx.Provide(NewJobWorker),
 	fx.Invoke(func(_ *JobWorker) {}),
+	fx.Provide(NewJobScheduler),
+	// 供 scheduler_provider 使用同一选主结果，进程内定时任务只在 leader 上执行
+	fx.Provide(func(s *JobScheduler) scheduler.LeaderChecker { return s }),
+	fx.Invoke(func(_ *JobScheduler) {}),
 )
@@ -0,0 +1,178 @@
+package job_provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LeaderElector 调度器选主实现，同一时刻最多一个实例持有领导权
+type LeaderElector interface {
+	// Acquire 尝试成为 leader，已被其他实例持有时返回 false
+	Acquire(ctx context.Context) (bool, error)
+	// Renew 续期领导权，返回 false 表示领导权已丢失
+	Renew(ctx context.Context) (bool, error)
+	// Release 主动释放领导权（仅当仍由本实例持有时）
+	Release(ctx context.Context) error
+	// Leader 返回当前 leader 的身份标识，无 leader 时返回空串
+	Leader(ctx context.Context) (string, error)
+}
+
+// redisLeaderElector 基于 Redis 租约：SET NX PX 抢占，leader 宕机后租约过期由其他实例接管
+type redisLeaderElector struct {
+	client   redis.UniversalClient
+	key      string
+	identity string
+	lease    time.Duration
+}
+
+// leaderRenewScript 仅当租约仍属于本实例时续期
+var leaderRenewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// leaderReleaseScript 仅当租约仍属于本实例时删除
+var leaderReleaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// NewRedisLeaderElector 创建基于 Redis 租约的选主实现，lease 为租约时长
+func NewRedisLeaderElector(client redis.UniversalClient, key, identity string, lease time.Duration) LeaderElector {
+	return &redisLeaderElector{client: client, key: key, identity: identity, lease: lease}
+}
+
+func (e *redisLeaderElector) Acquire(ctx context.Context) (bool, error) {
+	return e.client.SetNX(ctx, e.key, e.identity, e.lease).Result()
+}
+
+func (e *redisLeaderElector) Renew(ctx context.Context) (bool, error) {
+	n, err := leaderRenewScript.Run(ctx, e.client, []string{e.key}, e.identity, e.lease.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (e *redisLeaderElector) Release(ctx context.Context) error {
+	return leaderReleaseScript.Run(ctx, e.client, []string{e.key}, e.identity).Err()
+}
+
+func (e *redisLeaderElector) Leader(ctx context.Context) (string, error) {
+	leader, err := e.client.Get(ctx, e.key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return leader, err
+}
+
+// dbLeaderElector 基于 MySQL GET_LOCK 咨询锁：锁绑定在一个专用连接上，
+// leader 进程退出或连接断开时数据库自动释放锁，其他实例即可接管
+type dbLeaderElector struct {
+	db       *sql.DB
+	key      string
+	identity string
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewDBLeaderElector 创建基于 MySQL 咨询锁的选主实现
+func NewDBLeaderElector(db *sql.DB, key, identity string) LeaderElector {
+	return &dbLeaderElector{db: db, key: key, identity: identity}
+}
+
+func (e *dbLeaderElector) Acquire(ctx context.Context) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		conn, err := e.db.Conn(ctx)
+		if err != nil {
+			return false, err
+		}
+		e.conn = conn
+	}
+	var got sql.NullInt64
+	if err := e.conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", e.key).Scan(&got); err != nil {
+		e.closeConn()
+		return false, err
+	}
+	if got.Int64 != 1 {
+		// 未抢到时关闭连接，避免长期占用连接池
+		e.closeConn()
+		return false, nil
+	}
+	return true, nil
+}
+
+func (e *dbLeaderElector) Renew(ctx context.Context) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return false, nil
+	}
+	// 锁仍由当前连接持有即视为续期成功，同时起到连接保活作用
+	var owned sql.NullInt64
+	err := e.conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?) = CONNECTION_ID()", e.key).Scan(&owned)
+	if err != nil {
+		e.closeConn()
+		return false, err
+	}
+	if owned.Int64 != 1 {
+		e.closeConn()
+		return false, nil
+	}
+	return true, nil
+}
+
+func (e *dbLeaderElector) Release(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	_, err := e.conn.ExecContext(ctx, "DO RELEASE_LOCK(?)", e.key)
+	e.closeConn()
+	return err
+}
+
+// Leader 咨询锁不记录持有者身份，本实例持有时返回自身标识，否则返回持有锁的数据库连接 ID
+func (e *dbLeaderElector) Leader(ctx context.Context) (string, error) {
+	var connID sql.NullInt64
+	if err := e.db.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?)", e.key).Scan(&connID); err != nil {
+		return "", err
+	}
+	if !connID.Valid {
+		return "", nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn != nil {
+		var self int64
+		if err := e.conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&self); err == nil && self == connID.Int64 {
+			return e.identity, nil
+		}
+	}
+	return fmt.Sprintf("mysql-connection:%d", connID.Int64), nil
+}
+
+// closeConn 关闭专用连接的物理连接：sql.Conn.Close 会把连接放回连接池，
+// 若连接仍持有 GET_LOCK（如续期查询超时），锁会随连接被其他请求复用而无法释放。
+// Raw 回调返回 driver.ErrBadConn 时 database/sql 丢弃该连接，数据库随之释放锁
+func (e *dbLeaderElector) closeConn() {
+	if e.conn != nil {
+		_ = e.conn.Raw(func(any) error { return driver.ErrBadConn })
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+}
@@ -0,0 +1,222 @@
+package job_provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/redis/go-redis/v9"
+)
+
+// EventProgress 任务进度事件名，在 worker 的事件总线上发布，载荷为 JobProgress
+const EventProgress = "job.progress"
+
+// progressKeyPrefix 进度快照键及发布频道前缀
+const progressKeyPrefix = "zlib:job:progress:"
+
+// ErrProgressNotFound 任务不存在、未开启进度跟踪或进度已过期
+var ErrProgressNotFound = errors.New("job: progress not found")
+
+// JobProgress 任务进度，worker 写入 Redis 快照并通过发布订阅推送，web 节点可跨进程读取
+type JobProgress struct {
+	JobID     string          `json:"job_id"`
+	Name      string          `json:"name"`
+	Status    JobStatus       `json:"status"`
+	Percent   float64         `json:"percent"`           // 0 ~ 100
+	Message   string          `json:"message,omitempty"` // 当前阶段说明
+	Result    json.RawMessage `json:"result,omitempty"`  // 任务完成时的结果（Job.SetResult）
+	Error     string          `json:"error,omitempty"`
+	Seq       int64           `json:"seq"` // 递增序号，可作为 SSE 事件 ID
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Finished 任务是否已结束（完成或重试耗尽失败）
+func (p JobProgress) Finished() bool {
+	return p.Status == JobStatusCompleted || p.Status == JobStatusFailed
+}
+
+// ReportProgress 在处理器中上报进度，仅 AddJobOptions.TrackProgress 开启时生效
+//
+//	job.ReportProgress(30, "parsing rows")
+func (j *Job) ReportProgress(percent float64, message string) {
+	if j == nil || j.progress == nil {
+		return
+	}
+	j.progress(percent, message)
+}
+
+// progressStore 进度快照存储（job.progress.ttl 秒，默认 86400）
+type progressStore struct {
+	client redis.UniversalClient
+	ttl    time.Duration
+}
+
+func newProgressStore(cfg *config_provider.Config, client redis.UniversalClient) *progressStore {
+	if client == nil {
+		return nil
+	}
+	ttlSeconds := cfg.GetInt("job.progress.ttl", 86400)
+	if ttlSeconds <= 0 {
+		ttlSeconds = 86400
+	}
+	return &progressStore{client: client, ttl: time.Duration(ttlSeconds) * time.Second}
+}
+
+// progressRedisClient 未共享 redis_provider 时，由 asynq 连接参数创建进度存储使用的客户端
+func progressRedisClient(opt asynq.RedisClientOpt) redis.UniversalClient {
+	client, _ := opt.MakeRedisClient().(redis.UniversalClient)
+	return client
+}
+
+// save 分配递增序号、写入快照并发布，返回分配的序号
+func (s *progressStore) save(ctx context.Context, p JobProgress) (int64, error) {
+	key := progressKeyPrefix + p.JobID
+	pipe := s.client.TxPipeline()
+	incr := pipe.Incr(ctx, key+":seq")
+	pipe.PExpire(ctx, key+":seq", s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	p.Seq = incr.Val()
+	b, err := json.Marshal(p)
+	if err != nil {
+		return 0, err
+	}
+	pipe = s.client.TxPipeline()
+	pipe.Set(ctx, key, b, s.ttl)
+	pipe.Publish(ctx, key, b)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return p.Seq, nil
+}
+
+// init 写入初始快照（序号 0），快照已存在时（如重复的 TaskID）不覆盖
+func (s *progressStore) init(ctx context.Context, p JobProgress) error {
+	p.Seq = 0
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return s.client.SetNX(ctx, progressKeyPrefix+p.JobID, b, s.ttl).Err()
+}
+
+func (s *progressStore) get(ctx context.Context, id string) (*JobProgress, error) {
+	data, err := s.client.Get(ctx, progressKeyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrProgressNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p JobProgress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Progress 读取任务进度快照
+func (c *JobClient) Progress(ctx context.Context, id string) (*JobProgress, error) {
+	if c.progress == nil {
+		return nil, ErrProgressNotFound
+	}
+	return c.progress.get(ctx, id)
+}
+
+// WatchProgress 订阅任务进度：先推送当前快照，之后推送每次更新，任务结束或 ctx 结束时关闭通道
+// 按 Seq 去重，订阅建立前后的更新不会重复或乱序
+func (c *JobClient) WatchProgress(ctx context.Context, id string) (<-chan JobProgress, error) {
+	if c.progress == nil {
+		return nil, ErrProgressNotFound
+	}
+	// 先订阅再读快照，避免两者之间的更新丢失
+	sub := c.progress.client.Subscribe(ctx, progressKeyPrefix+id)
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+	snapshot, err := c.progress.get(ctx, id)
+	if err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+
+	out := make(chan JobProgress, 16)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		last := snapshot.Seq
+		select {
+		case out <- *snapshot:
+		case <-ctx.Done():
+			return
+		}
+		if snapshot.Finished() {
+			return
+		}
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var p JobProgress
+				if err := json.Unmarshal([]byte(msg.Payload), &p); err != nil || p.Seq <= last {
+					continue
+				}
+				last = p.Seq
+				select {
+				case out <- p:
+				case <-ctx.Done():
+					return
+				}
+				if p.Finished() {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// reportProgress worker 写入进度并在本地事件总线发布 EventProgress
+func (w *JobWorker) reportProgress(job *Job, p JobProgress) {
+	if !job.TrackProgress {
+		return
+	}
+	p.JobID = job.ID
+	p.Name = job.Name
+	p.UpdatedAt = w.clock.Now()
+	if w.progress != nil {
+		seq, err := w.progress.save(context.Background(), p)
+		if err != nil {
+			if w.log != nil {
+				w.log.Warnw("job progress save failed", "id", job.ID, "name", job.Name, "error", err)
+			}
+		} else {
+			p.Seq = seq
+		}
+	}
+	if w.bus != nil {
+		w.bus.EmitAsync(context.Background(), EventProgress, p)
+	}
+}
+
+// finalProgress 根据执行结果生成结束进度，未耗尽重试的失败记为 retrying
+func finalProgress(ctx context.Context, job *Job, err error) JobProgress {
+	if err == nil {
+		return JobProgress{Status: JobStatusCompleted, Percent: 100, Result: job.Result}
+	}
+	status := JobStatusRetrying
+	if isFinalAttempt(ctx, err) {
+		status = JobStatusFailed
+	}
+	return JobProgress{Status: status, Error: err.Error()}
+}
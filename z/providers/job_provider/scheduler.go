@@ -0,0 +1,330 @@
+package job_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/redis_provider"
+	"github.com/icreateapp-com/go-zLib/z/scheduler"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/fx"
+)
+
+// 选主方式（job.scheduler.election）
+const (
+	ElectionRedis = "redis" // Redis 租约
+	ElectionDB    = "db"    // MySQL 咨询锁
+	ElectionNone  = "none"  // 不选主，单实例部署使用
+)
+
+// ScheduleRegister 由业务模块提供的定时任务（fx group），到点后由 leader 投递到任务队列
+type ScheduleRegister struct {
+	Spec    string // cron 表达式，支持可选的秒字段及 @every 1m、@daily 等描述符
+	Name    string // 任务名，对应 JobHandlerRegister.Name
+	Payload any
+	Options *AddJobOptions
+}
+
+type ScheduleOut struct {
+	fx.Out
+	Schedule ScheduleRegister `group:"job_schedules"`
+}
+
+// Schedule 注册定时任务
+//
+//	fx.Provide(func() job_provider.ScheduleOut {
+//		return job_provider.Schedule("0 3 * * *", "report.daily", nil)
+//	})
+func Schedule(spec, name string, payload any, opt ...*AddJobOptions) ScheduleOut {
+	r := ScheduleRegister{Spec: spec, Name: name, Payload: payload}
+	if len(opt) > 0 {
+		r.Options = opt[0]
+	}
+	return ScheduleOut{Schedule: r}
+}
+
+// JobScheduler 定时任务调度器：多副本部署时通过选主保证只有 leader 投递任务，
+// leader 宕机后租约过期（或数据库连接断开），其他实例自动接管
+type JobScheduler struct {
+	sched    *scheduler.Scheduler
+	client   *JobClient
+	elector  LeaderElector
+	log      *logger_provider.Logger
+	election string
+	identity string
+	interval time.Duration
+
+	leader atomic.Bool
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+type SchedulerIn struct {
+	fx.In
+	LC        fx.Lifecycle
+	Cfg       *config_provider.Config
+	Log       *logger_provider.Logger
+	Client    *JobClient
+	Redis     *redis_provider.Redis `optional:"true"`
+	DB        *db_provider.DB       `optional:"true"`
+	Elector   LeaderElector         `optional:"true"` // 自定义选主实现，优先于 job.scheduler.election
+	Schedules []ScheduleRegister    `group:"job_schedules"`
+}
+
+// schedulerMetrics 调度器指标
+type schedulerMetrics struct {
+	meter   metric.Meter
+	leader  metric.Int64ObservableGauge
+	changes metric.Int64Counter
+}
+
+var (
+	schedulerMetricsOnce sync.Once
+	schedulerMetricsInst schedulerMetrics
+)
+
+func getSchedulerMetrics() schedulerMetrics {
+	schedulerMetricsOnce.Do(func() {
+		m := &schedulerMetricsInst
+		m.meter = otel.Meter("github.com/icreateapp-com/go-zLib/job")
+		m.leader, _ = m.meter.Int64ObservableGauge("job.scheduler.leader", metric.WithDescription("本实例是否为调度器 leader（1 是，0 否），instance 属性为实例标识"))
+		m.changes, _ = m.meter.Int64Counter("job.scheduler.leader.changes", metric.WithDescription("本实例获得 / 失去领导权的次数"))
+	})
+	return schedulerMetricsInst
+}
+
+func NewJobScheduler(in SchedulerIn) (*JobScheduler, error) {
+	identity := strings.TrimSpace(in.Cfg.GetString("job.scheduler.identity"))
+	if identity == "" {
+		hostname, _ := os.Hostname()
+		identity = fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), uuid.New().String()[:8])
+	}
+	leaseSeconds := in.Cfg.GetInt("job.scheduler.lease", 15)
+	if leaseSeconds <= 0 {
+		leaseSeconds = 15
+	}
+	lease := time.Duration(leaseSeconds) * time.Second
+	key := strings.TrimSpace(in.Cfg.GetString("job.scheduler.key"))
+	if key == "" {
+		key = "zlib:job:scheduler:" + in.Client.Queue()
+	}
+
+	election := strings.ToLower(strings.TrimSpace(in.Cfg.GetString("job.scheduler.election")))
+	elector := in.Elector
+	if elector != nil {
+		election = "custom"
+	} else {
+		if election == "" {
+			switch {
+			case in.Redis != nil:
+				election = ElectionRedis
+			case in.DB != nil && in.DB.Dialector.Name() == "mysql":
+				election = ElectionDB
+			default:
+				election = ElectionNone
+			}
+		}
+		switch election {
+		case ElectionRedis:
+			if in.Redis == nil {
+				return nil, fmt.Errorf("job scheduler: election redis requires redis provider")
+			}
+			elector = NewRedisLeaderElector(in.Redis.UniversalClient(), key, identity, lease)
+		case ElectionDB:
+			if in.DB == nil || in.DB.Dialector.Name() != "mysql" {
+				return nil, fmt.Errorf("job scheduler: election db requires mysql db provider")
+			}
+			sqlDB, err := in.DB.DB.DB()
+			if err != nil {
+				return nil, err
+			}
+			elector = NewDBLeaderElector(sqlDB, key, identity)
+		case ElectionNone:
+		default:
+			return nil, fmt.Errorf("job scheduler: unsupported election %q", election)
+		}
+	}
+
+	s := &JobScheduler{
+		client:   in.Client,
+		elector:  elector,
+		log:      in.Log,
+		election: election,
+		identity: identity,
+		interval: lease / 3,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if elector == nil {
+		s.leader.Store(true)
+	}
+	s.sched = scheduler.New(scheduler.Options{Logger: in.Log, Leader: s})
+
+	registered := 0
+	for _, r := range in.Schedules {
+		name := strings.TrimSpace(r.Name)
+		if name == "" || strings.TrimSpace(r.Spec) == "" {
+			continue
+		}
+		r.Name = name
+		// 投递本身很快，允许重叠以免错过触发；同名任务的重复投递由 TaskID 去重
+		err := s.sched.Cron(name+"@"+r.Spec, r.Spec, func(context.Context) error {
+			s.fire(r)
+			return nil
+		}, scheduler.TaskOptions{Overlap: scheduler.OverlapAllow})
+		if errors.Is(err, scheduler.ErrTaskExists) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("job scheduler: invalid spec %q for %s: %w", r.Spec, name, err)
+		}
+		registered++
+	}
+
+	var registration metric.Registration
+	if metrics := getSchedulerMetrics(); metrics.leader != nil {
+		attrs := metric.WithAttributes(attribute.String("instance", identity), attribute.String("election", election))
+		registration, _ = metrics.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+			var v int64
+			if s.IsLeader() {
+				v = 1
+			}
+			o.ObserveInt64(metrics.leader, v, attrs)
+			return nil
+		}, metrics.leader)
+	}
+
+	in.LC.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if s.elector != nil {
+				s.campaign(ctx)
+				go s.loop()
+			} else {
+				close(s.done)
+			}
+			s.sched.Start()
+			if s.log != nil {
+				s.log.Infow("provider[job_scheduler] enabled", "election", election, "identity", identity, "lease", lease.String(), "schedules", registered)
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			_ = s.sched.Stop(ctx)
+			s.once.Do(func() { close(s.stop) })
+			select {
+			case <-s.done:
+			case <-ctx.Done():
+			}
+			if s.elector != nil && s.leader.Load() {
+				s.setLeader(false)
+				// 主动释放，其他实例无需等待租约过期即可接管
+				if err := s.elector.Release(ctx); err != nil && s.log != nil {
+					s.log.Warnw("job scheduler release leadership failed", "error", err)
+				}
+			}
+			if registration != nil {
+				_ = registration.Unregister()
+			}
+			return nil
+		},
+	})
+
+	return s, nil
+}
+
+// IsLeader 本实例当前是否为 leader
+func (s *JobScheduler) IsLeader() bool {
+	return s.leader.Load()
+}
+
+// Identity 本实例的身份标识（job.scheduler.identity，默认 主机名:pid:随机串）
+func (s *JobScheduler) Identity() string {
+	return s.identity
+}
+
+// Leader 返回当前 leader 的身份标识，未选主时返回本实例标识
+func (s *JobScheduler) Leader(ctx context.Context) (string, error) {
+	if s.elector == nil {
+		return s.identity, nil
+	}
+	return s.elector.Leader(ctx)
+}
+
+// Schedules 返回定时任务的触发状态与最近投递记录，非 leader 实例到点时记录为 skipped
+func (s *JobScheduler) Schedules() []scheduler.TaskInfo {
+	return s.sched.Tasks()
+}
+
+func (s *JobScheduler) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+			s.campaign(ctx)
+			cancel()
+		}
+	}
+}
+
+// campaign leader 续期，非 leader 尝试抢占；续期失败（含网络错误）立即停止投递，避免与新 leader 重复执行
+func (s *JobScheduler) campaign(ctx context.Context) {
+	// 先尝试续期：短暂网络故障后租约仍属于本实例时可直接恢复
+	ok, err := s.elector.Renew(ctx)
+	if err == nil && !ok && !s.leader.Load() {
+		ok, err = s.elector.Acquire(ctx)
+	}
+	if err != nil && s.log != nil {
+		s.log.Warnw("job scheduler election failed", "identity", s.identity, "error", err)
+	}
+	s.setLeader(err == nil && ok)
+}
+
+func (s *JobScheduler) setLeader(leader bool) {
+	if s.leader.Swap(leader) == leader {
+		return
+	}
+	state := "lost"
+	if leader {
+		state = "acquired"
+	}
+	getSchedulerMetrics().changes.Add(context.Background(), 1, metric.WithAttributes(attribute.String("instance", s.identity), attribute.String("state", state)))
+	if s.log != nil {
+		s.log.Infow("job scheduler leadership "+state, "identity", s.identity, "election", s.election)
+	}
+}
+
+// fire 到点投递任务；TaskID 由任务名与触发时间组成，leader 切换瞬间重复触发时由队列去重
+func (s *JobScheduler) fire(r ScheduleRegister) {
+	if !s.IsLeader() {
+		return
+	}
+	opt := AddJobOptions{}
+	if r.Options != nil {
+		opt = *r.Options
+	}
+	if opt.TaskID == nil {
+		taskID := fmt.Sprintf("schedule:%s:%d", r.Name, time.Now().Truncate(time.Second).Unix())
+		opt.TaskID = &taskID
+	}
+	if _, err := s.client.AddJob(context.Background(), r.Name, r.Payload, &opt); err != nil && s.log != nil {
+		s.log.Errorw("job scheduler enqueue failed", "name", r.Name, "spec", r.Spec, "error", err)
+	}
+}
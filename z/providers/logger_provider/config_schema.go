@@ -0,0 +1,13 @@
+package logger_provider
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// loggerConfig logger.yml 配置结构
+type loggerConfig struct {
+	Level     string `default:"info" desc:"日志级别：debug / info / warn / error，支持热加载"`
+	Dir       string `default:"./storage/log" desc:"日志目录"`
+	MaxAge    int    `default:"7" desc:"日志保留天数"`
+	PrintLine bool   `default:"false" desc:"输出调用位置"`
+}
+
+var _ = config_provider.RegisterSchema("logger", "日志", loggerConfig{})
@@ -17,8 +17,9 @@ import (
 
 // Logger 日志管理
 type Logger struct {
-	base *zap.Logger
-	log  *zap.SugaredLogger
+	base  *zap.Logger
+	log   *zap.SugaredLogger
+	level zap.AtomicLevel
 }
 
 // Base 返回底层 zap.Logger。
@@ -31,6 +32,11 @@ func (l *Logger) Sugar() *zap.SugaredLogger {
 	return l.log
 }
 
+// SetLevel 运行时调整日志级别
+func (l *Logger) SetLevel(level string) error {
+	return l.level.UnmarshalText([]byte(level))
+}
+
 // Debugw 输出 debug 级别结构化日志。
 func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
 	l.log.Debugw(msg, keysAndValues...)
@@ -85,10 +91,8 @@ func NewLoggerProvider(lc fx.Lifecycle, cfg *config_provider.Config) (*Logger, e
 		logDir = "./storage/log"
 	}
 
-	var lvl zapcore.Level
-	if err := lvl.Set(levelStr); err != nil {
-		lvl = zapcore.InfoLevel
-	}
+	lvl := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	_ = lvl.UnmarshalText([]byte(levelStr))
 
 	encCfg := zapcore.EncoderConfig{
 		TimeKey:        "ts",
@@ -135,7 +139,19 @@ func NewLoggerProvider(lc fx.Lifecycle, cfg *config_provider.Config) (*Logger, e
 	// 通过 AddCallerSkip(1) 跳过 logger_provider 的封装层，确保 caller 指向业务调用处
 	sugar := base.WithOptions(zap.AddCallerSkip(1)).Sugar()
 
-	l := &Logger{base: base, log: sugar}
+	l := &Logger{base: base, log: sugar, level: lvl}
+
+	// 配置重载后实时调整日志级别
+	cfg.OnChange(func(event config_provider.ChangeEvent) {
+		if !event.Has("logger.level") {
+			return
+		}
+		if err := l.SetLevel(cfg.GetString("logger.level", "info")); err != nil {
+			l.Warnw("invalid logger.level", "error", err)
+			return
+		}
+		l.Infow("logger level changed", "level", l.level.String())
+	})
 
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
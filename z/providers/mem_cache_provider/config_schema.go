@@ -0,0 +1,15 @@
+package mem_cache_provider
+
+import (
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// memCacheConfig mem_cache.yml 配置结构
+type memCacheConfig struct {
+	DefaultExpiration time.Duration `default:"1h" desc:"默认过期时间"`
+	CleanupInterval   time.Duration `default:"10m" desc:"过期清理间隔"`
+}
+
+var _ = config_provider.RegisterSchema("mem_cache", "内存缓存", memCacheConfig{})
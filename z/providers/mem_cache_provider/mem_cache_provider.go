@@ -1,26 +1,56 @@
 package mem_cache_provider
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 
 	"github.com/patrickmn/go-cache"
 	"go.uber.org/fx"
 )
 
+// ErrCacheMiss 缓存不存在或已过期，与 RedisCache 未命中时返回 redis.Nil 对应
+var ErrCacheMiss = errors.New("mem_cache: cache miss")
+
+// ErrCacheTypeMismatch 缓存值类型与目标类型不一致
+var ErrCacheTypeMismatch = errors.New("mem_cache: type mismatch")
+
 // MemCache 内存缓存
+// 过期时间按注入的 Clock 判断，过期条目仍由 go-cache 按系统时间定期清理
 type MemCache struct {
-	cache *cache.Cache
+	cache             *cache.Cache
+	clock             z.Clock
+	defaultExpiration time.Duration
+}
+
+// In MemCache 的 fx 入参
+type In struct {
+	fx.In
+
+	Cfg   *config_provider.Config
+	Clock z.Clock `optional:"true"`
+}
+
+// memItem 带过期时间的缓存条目，expiresAt 为 0 表示永不过期
+type memItem struct {
+	value     interface{}
+	expiresAt int64
 }
 
 // NewMemCacheProvider 创建内存缓存实例
-func NewMemCacheProvider(cfg *config_provider.Config) *MemCache {
-	defaultExpiration := cfg.GetDuration("mem_cache.default_expiration", 60*time.Minute)
-	cleanupInterval := cfg.GetDuration("mem_cache.cleanup_interval", 10*time.Minute)
+func NewMemCacheProvider(in In) *MemCache {
+	defaultExpiration := in.Cfg.GetDuration("mem_cache.default_expiration", 60*time.Minute)
+	cleanupInterval := in.Cfg.GetDuration("mem_cache.cleanup_interval", 10*time.Minute)
 
 	return &MemCache{
-		cache: cache.New(defaultExpiration, cleanupInterval),
+		cache:             cache.New(defaultExpiration, cleanupInterval),
+		clock:             z.ClockOr(in.Clock),
+		defaultExpiration: defaultExpiration,
 	}
 }
 
@@ -29,17 +59,128 @@ var MemCacheProviderModule = fx.Options(
 	fx.Provide(NewMemCacheProvider),
 )
 
-// Set 设置缓存
+// Set 设置缓存，d 为 0 时使用默认过期时间，为 -1 时永不过期
 func (p *MemCache) Set(k string, x interface{}, d time.Duration) {
-	p.cache.Set(k, x, d)
+	if d == cache.DefaultExpiration {
+		d = p.defaultExpiration
+	}
+	item := memItem{value: x}
+	if d > 0 {
+		item.expiresAt = p.clock.Now().Add(d).UnixNano()
+	}
+	p.cache.Set(k, item, d)
 }
 
 // Get 获取缓存
 func (p *MemCache) Get(k string) (interface{}, bool) {
-	return p.cache.Get(k)
+	v, ok := p.cache.Get(k)
+	if !ok {
+		return nil, false
+	}
+	item, ok := v.(memItem)
+	if !ok {
+		return v, true
+	}
+	if p.expired(item) {
+		return nil, false
+	}
+	return item.value, true
+}
+
+// GetWithExpiration 获取缓存及过期时间，永不过期时返回零值时间
+func (p *MemCache) GetWithExpiration(k string) (interface{}, time.Time, bool) {
+	v, exp, ok := p.cache.GetWithExpiration(k)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	item, ok := v.(memItem)
+	if !ok {
+		return v, exp, true
+	}
+	if p.expired(item) {
+		return nil, time.Time{}, false
+	}
+	if item.expiresAt == 0 {
+		return item.value, time.Time{}, true
+	}
+	return item.value, time.Unix(0, item.expiresAt), true
+}
+
+// Load 读取缓存到 dest（非空指针），未命中返回 ErrCacheMiss，用法与 RedisCache.Get 一致
+// 缓存值可以是 dest 指向的类型或其指针
+func (p *MemCache) Load(k string, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("mem_cache: dest must be a non-nil pointer, got %T", dest)
+	}
+	v, ok := p.Get(k)
+	if !ok {
+		return ErrCacheMiss
+	}
+	elem := rv.Elem()
+	val := reflect.ValueOf(v)
+	switch {
+	case !val.IsValid():
+		elem.SetZero()
+	case val.Type().AssignableTo(elem.Type()):
+		elem.Set(val)
+	case val.Kind() == reflect.Pointer && !val.IsNil() && val.Elem().Type().AssignableTo(elem.Type()):
+		elem.Set(val.Elem())
+	default:
+		return fmt.Errorf("%w: key %s holds %T, want %s", ErrCacheTypeMismatch, k, v, elem.Type())
+	}
+	return nil
+}
+
+// MemCacheGetAs 按类型读取缓存，未命中返回 ErrCacheMiss，类型不符返回 ErrCacheTypeMismatch
+//
+//	user, err := mem_cache_provider.MemCacheGetAs[*User](cache, "user:1")
+func MemCacheGetAs[T any](p *MemCache, k string) (T, error) {
+	var zero T
+	v, ok := p.Get(k)
+	if !ok {
+		return zero, ErrCacheMiss
+	}
+	if v == nil {
+		return zero, nil
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: key %s holds %T, want %T", ErrCacheTypeMismatch, k, v, zero)
+	}
+	return t, nil
+}
+
+func (p *MemCache) expired(item memItem) bool {
+	return item.expiresAt > 0 && p.clock.Now().UnixNano() > item.expiresAt
 }
 
 // Delete 删除缓存
 func (p *MemCache) Delete(k string) {
 	p.cache.Delete(k)
 }
+
+// Flush 清空全部缓存
+func (p *MemCache) Flush() {
+	p.cache.Flush()
+}
+
+// ItemCount 返回缓存条目数（可能包含尚未清理的过期条目）
+func (p *MemCache) ItemCount() int {
+	return p.cache.ItemCount()
+}
+
+// Keys 返回指定前缀的未过期键
+func (p *MemCache) Keys(prefix string) []string {
+	items := p.cache.Items()
+	keys := make([]string, 0, len(items))
+	for k, v := range items {
+		if item, ok := v.Object.(memItem); ok && p.expired(item) {
+			continue
+		}
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
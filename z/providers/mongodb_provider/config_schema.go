@@ -0,0 +1,21 @@
+package mongodb_provider
+
+import (
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// mongodbConfig mongodb.yml 配置结构，未配置时兼容读取旧的 mongodb_provider 命名空间
+type mongodbConfig struct {
+	Host           string        `required:"true" desc:"主机地址"`
+	Port           string        `desc:"端口"`
+	DBName         string        `config:"dbname" required:"true" desc:"数据库名"`
+	Username       string        `desc:"用户名"`
+	Password       string        `desc:"密码"`
+	AuthSource     string        `desc:"认证数据库"`
+	ConnectTimeout time.Duration `default:"10s" desc:"连接超时"`
+	Ping           bool          `default:"true" desc:"启动时检查连接"`
+}
+
+var _ = config_provider.RegisterSchema("mongodb", "MongoDB", mongodbConfig{})
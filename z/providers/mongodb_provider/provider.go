@@ -2,11 +2,13 @@ package mongodb_provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -129,4 +131,20 @@ func (p *MongoDB) Collection(name string) *Builder[any] {
 // MongoProviderModule 提供 MongoDB 的 fx 模块。
 var MongoProviderModule = fx.Options(
 	fx.Provide(NewMongoProvider),
+	fx.Provide(
+		fx.Annotate(
+			func(p *MongoDB) z.ReadyCheck { return z.ReadyCheck{Name: "mongodb", Checker: p} },
+			fx.ResultTags(`group:"ready_checks"`),
+		),
+	),
 )
+
+// Ready 检查 MongoDB 是否已连接且可用。
+func (p *MongoDB) Ready() error {
+	if p == nil || p.client == nil {
+		return errors.New("mongodb not connected")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return p.client.Ping(ctx, readpref.Primary())
+}
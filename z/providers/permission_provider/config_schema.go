@@ -0,0 +1,10 @@
+package permission_provider
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// permissionConfig permission.yml 配置结构
+type permissionConfig struct {
+	TTL int `config:"ttl" default:"86400" desc:"权限缓存时长（秒）"`
+}
+
+var _ = config_provider.RegisterSchema("permission", "权限", permissionConfig{})
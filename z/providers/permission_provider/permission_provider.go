@@ -10,6 +10,7 @@ import (
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
 	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/redis_provider"
@@ -49,6 +50,9 @@ type In struct {
 func NewPermissionProvider(lc fx.Lifecycle, in In) (*Provider, error) {
 	p := &Provider{cfg: in.Cfg, log: in.Log, redis: in.Redis}
 
+	// 响应脱敏规则中的 permission 按当前用户权限判断
+	z.SetMaskPermissionChecker(p.HasPermission)
+
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			return p.Init(in.Cfg)
@@ -287,6 +291,34 @@ func (p *Provider) PermissionMiddleware(permissions string) gin.HandlerFunc {
 	}
 }
 
+// HasPermission 判断当前请求用户是否具有任一指定权限，格式同 PermissionMiddleware
+// 缺少权限上下文或读取权限失败时返回 false
+func (p *Provider) HasPermission(c *gin.Context, permissions string) bool {
+	if p == nil || c == nil || c.Request == nil {
+		return false
+	}
+	if isPermissionBypassed(c) {
+		return true
+	}
+	whitelist := p.parsePermissions(permissions)
+	if len(whitelist) == 0 {
+		return false
+	}
+
+	tenantType := getPermissionContextString(c, permissionTenantTypeContextKey)
+	tenantID := getPermissionContextString(c, permissionTenantIDContextKey)
+	userID := getPermissionContextString(c, permissionUserIDContextKey)
+	if tenantType == "" || tenantID == "" || userID == "" {
+		return false
+	}
+
+	perms, err := p.GetUserPermissions(c.Request.Context(), tenantType, tenantID, userID)
+	if err != nil {
+		return false
+	}
+	return p.checkPermissions(perms, whitelist)
+}
+
 // Can 检查用户是否具有指定权限（PermissionMiddleware 别名）
 func (p *Provider) Can(Permission string) gin.HandlerFunc {
 	return p.PermissionMiddleware(Permission)
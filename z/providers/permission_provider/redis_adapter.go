@@ -22,16 +22,7 @@ func NewRedisAdapter(redis *redis_provider.Redis) *RedisAdapter {
 
 // scanKeys 使用 SCAN 遍历匹配的键，避免依赖生产环境常被禁用的 KEYS 命令。
 func (a *RedisAdapter) scanKeys(pattern string) ([]string, error) {
-	ctx := context.Background()
-	keys := make([]string, 0, 32)
-	iter := a.redis.Client().Scan(ctx, 0, pattern, 100).Iterator()
-	for iter.Next(ctx) {
-		keys = append(keys, iter.Val())
-	}
-	if err := iter.Err(); err != nil {
-		return nil, err
-	}
-	return keys, nil
+	return a.redis.ScanKeys(context.Background(), pattern, 100)
 }
 
 // LoadPolicy 从 Redis 加载策略
@@ -0,0 +1,17 @@
+package quota_provider
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// quotaConfig quota.yml 配置结构
+type quotaConfig struct {
+	Enabled     bool                   `default:"false" desc:"启用配额"`
+	Definitions map[string]interface{} `desc:"配额定义，按名称配置 limit 与 period（none / hour / day / week / month）"`
+	Plans       map[string]interface{} `desc:"套餐，按套餐名配置各配额的上限"`
+	Tenants     map[string]interface{} `desc:"租户使用的套餐，租户 ID: 套餐名"`
+	DefaultPlan string                 `desc:"未列出的租户使用的套餐"`
+	Redis       struct {
+		Prefix string `default:"quota" desc:"计数键前缀"`
+	} `desc:"计数存储"`
+}
+
+var _ = config_provider.RegisterSchema("quota", "配额", quotaConfig{})
@@ -0,0 +1,198 @@
+package quota_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/redis_provider"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// Quota 配额组件，启用后将 quota.definitions 注册到 z.Quota 并切换为 Redis 计数
+type Quota struct {
+	cfg   *config_provider.Config
+	log   *logger_provider.Logger
+	redis *redis_provider.Redis
+
+	enabled bool
+
+	mu    sync.RWMutex
+	plans *tenantPlans
+}
+
+// In 表示 Quota 的 fx 入参。
+type In struct {
+	fx.In
+
+	Cfg   *config_provider.Config
+	Log   *logger_provider.Logger
+	Redis *redis_provider.Redis `optional:"true"`
+}
+
+// NewQuotaProvider 创建 Quota 实例。
+func NewQuotaProvider(in In) (*Quota, error) {
+	p := &Quota{cfg: in.Cfg, log: in.Log, redis: in.Redis}
+
+	p.enabled = in.Cfg.GetBool("quota.enabled", false)
+	if !p.enabled {
+		if p.log != nil {
+			p.log.Infow("provider[quota] disabled")
+		}
+		return p, nil
+	}
+
+	if p.redis == nil {
+		return nil, errors.New("quota enabled but redis provider is nil")
+	}
+
+	defs, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSpace(in.Cfg.GetString("quota.redis.prefix", "quota"))
+	z.Quota.SetStore(NewRedisStore(p.redis.UniversalClient()), prefix)
+
+	// 配置中心下发变更后热更新配额定义与租户套餐
+	in.Cfg.OnChange(func(event config_provider.ChangeEvent) {
+		if !event.Has("quota") {
+			return
+		}
+		defs, err := p.load()
+		if err != nil {
+			if p.log != nil {
+				p.log.Warnw("quota reload failed, keeping previous limits", "error", err)
+			}
+			return
+		}
+		if p.log != nil {
+			p.log.Infow("quota limits reloaded", "quotas", len(defs))
+		}
+	})
+
+	if p.log != nil {
+		p.log.Infow("provider[quota] enabled", "quotas", len(defs), "prefix", prefix)
+	}
+
+	return p, nil
+}
+
+// Enabled 返回配额是否启用。
+func (p *Quota) Enabled() bool { return p.enabled }
+
+// load 读取配额定义与租户套餐并应用到 z.Quota，全部校验通过后才生效；配置中已删除的配额随之移除
+// 配置了套餐时接管 z.Quota 的上限解析，配额主体即租户 ID
+func (p *Quota) load() ([]z.QuotaDefinition, error) {
+	defs, err := loadDefinitions(p.cfg)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := loadTenantPlans(p.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.plans = plans
+	p.mu.Unlock()
+	z.Quota.Replace(defs...)
+	if len(plans.plans) > 0 {
+		z.Quota.SetLimitResolver(p.TenantLimit)
+	}
+	return defs, nil
+}
+
+// loadDefinitions 读取 quota.definitions.<name>.limit / period
+func loadDefinitions(cfg *config_provider.Config) ([]z.QuotaDefinition, error) {
+	items := cfg.GetStringMap("quota.definitions")
+	defs := make([]z.QuotaDefinition, 0, len(items))
+	for name, vv := range items {
+		m, ok := vv.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		period := z.QuotaPeriod(strings.ToLower(strings.TrimSpace(z.ToString(m["period"]))))
+		switch period {
+		case "":
+			period = z.QuotaPeriodNone
+		case z.QuotaPeriodNone, z.QuotaPeriodHour, z.QuotaPeriodDay, z.QuotaPeriodWeek, z.QuotaPeriodMonth:
+		default:
+			return nil, fmt.Errorf("invalid quota.definitions.%s.period: %s", name, period)
+		}
+		limit, ok := z.ToInt(m["limit"])
+		if m["limit"] != nil && !ok {
+			return nil, fmt.Errorf("invalid quota.definitions.%s.limit: %v", name, m["limit"])
+		}
+		defs = append(defs, z.QuotaDefinition{Name: name, Limit: int64(limit), Period: period})
+	}
+	return defs, nil
+}
+
+// consumeScript 原子地检查并累加计数，首次写入时设置过期时间
+var consumeScript = redis.NewScript(`
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+local n = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+if limit > 0 and used + n > limit then
+	return {used, 0}
+end
+used = redis.call('INCRBY', KEYS[1], n)
+if used < 0 then
+	redis.call('SET', KEYS[1], 0, 'KEEPTTL')
+	used = 0
+end
+if ttl > 0 and redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return {used, 1}
+`)
+
+// RedisStore 基于 Redis 的配额计数存储，多实例共享
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+// NewRedisStore 创建 Redis 配额计数存储
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Consume 实现 z.QuotaStore
+func (s *RedisStore) Consume(ctx context.Context, key string, n, limit int64, ttl time.Duration) (int64, bool, error) {
+	res, err := consumeScript.Run(ctx, s.client, []string{key}, n, limit, ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	if len(res) != 2 {
+		return 0, false, fmt.Errorf("unexpected quota script result: %v", res)
+	}
+	return res[0], res[1] == 1, nil
+}
+
+// Get 实现 z.QuotaStore
+func (s *RedisStore) Get(ctx context.Context, key string) (int64, error) {
+	used, err := s.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return used, err
+}
+
+// Reset 实现 z.QuotaStore
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+// QuotaProviderModule 提供 Quota 的 fx 模块。
+var QuotaProviderModule = fx.Options(
+	fx.Provide(NewQuotaProvider),
+)
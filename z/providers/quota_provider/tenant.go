@@ -0,0 +1,85 @@
+package quota_provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// tenantPlans 按租户套餐的配额上限快照
+type tenantPlans struct {
+	plans       map[string]map[string]int64 // 套餐 -> 配额名 -> 上限
+	tenants     map[string]string           // 租户 -> 套餐
+	defaultPlan string
+}
+
+// loadTenantPlans 读取 quota.plans / tenants / default_plan，套餐中未列出的配额使用 definitions 中的上限
+//
+//	quota:
+//	  plans:
+//	    free: { api_calls: 1000, storage_mb: 100 }
+//	    pro:  { api_calls: 100000, storage_mb: 10240 }
+//	  tenants:
+//	    t_1001: pro
+//	  default_plan: free
+//
+// 配置键会被转为小写，租户 ID 按不区分大小写匹配
+func loadTenantPlans(cfg *config_provider.Config) (*tenantPlans, error) {
+	t := &tenantPlans{plans: map[string]map[string]int64{}, tenants: map[string]string{}}
+	for plan, vv := range cfg.GetStringMap("quota.plans") {
+		m, ok := vv.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid quota.plans.%s: expected map of quota limits", plan)
+		}
+		limits := make(map[string]int64, len(m))
+		for name, v := range m {
+			limit, ok := z.ToInt(v)
+			if !ok {
+				return nil, fmt.Errorf("invalid quota.plans.%s.%s: %v", plan, name, v)
+			}
+			limits[name] = int64(limit)
+		}
+		t.plans[plan] = limits
+	}
+	for tenant, v := range cfg.GetStringMap("quota.tenants") {
+		plan := strings.ToLower(strings.TrimSpace(z.ToString(v)))
+		if _, ok := t.plans[plan]; !ok {
+			return nil, fmt.Errorf("invalid quota.tenants.%s: unknown plan %q", tenant, plan)
+		}
+		t.tenants[tenant] = plan
+	}
+	t.defaultPlan = strings.ToLower(strings.TrimSpace(cfg.GetString("quota.default_plan")))
+	if _, ok := t.plans[t.defaultPlan]; t.defaultPlan != "" && !ok {
+		return nil, fmt.Errorf("invalid quota.default_plan: unknown plan %q", t.defaultPlan)
+	}
+	return t, nil
+}
+
+// TenantPlan 返回租户所属套餐，未列出时为 default_plan
+func (p *Quota) TenantPlan(tenantID string) string {
+	p.mu.RLock()
+	t := p.plans
+	p.mu.RUnlock()
+	if t == nil {
+		return ""
+	}
+	if plan, ok := t.tenants[strings.ToLower(tenantID)]; ok {
+		return plan
+	}
+	return t.defaultPlan
+}
+
+// TenantLimit 实现 z.QuotaLimitResolver：按租户套餐返回配额上限，套餐未配置该配额时返回 false
+func (p *Quota) TenantLimit(_ context.Context, name, subject string) (int64, bool) {
+	plan := p.TenantPlan(subject)
+	if plan == "" {
+		return 0, false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	limit, ok := p.plans.plans[plan][strings.ToLower(name)]
+	return limit, ok
+}
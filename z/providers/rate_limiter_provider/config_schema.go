@@ -0,0 +1,20 @@
+package rate_limiter_provider
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// rateLimiterConfig rate_limiter.yml 配置结构
+type rateLimiterConfig struct {
+	Enabled        bool                   `default:"false" desc:"启用限流"`
+	DefaultRate    string                 `default:"60-M" desc:"默认速率，格式为 次数-周期（S / M / H / D），如 100-M"`
+	ClientIPHeader string                 `config:"client_ip_header" desc:"读取客户端 IP 的请求头，如 X-Real-IP"`
+	IPv6MaskBits   int                    `config:"ipv6_mask_bits" desc:"IPv6 地址按前缀聚合的位数，0 不聚合"`
+	Strategies     map[string]interface{} `desc:"限流策略，按名称配置 rate、key_by、methods、paths、prefix、message、headers"`
+	Plans          map[string]interface{} `desc:"套餐速率，套餐名: 速率"`
+	Tenants        map[string]interface{} `desc:"租户套餐或速率，租户 ID: 套餐名或速率"`
+	DefaultPlan    string                 `desc:"未列出的租户使用的套餐，为空时使用 default_rate"`
+	Redis          struct {
+		Prefix string `default:"limiter" desc:"计数键前缀"`
+	} `desc:"计数存储"`
+}
+
+var _ = config_provider.RegisterSchema("rate_limiter", "限流", rateLimiterConfig{})
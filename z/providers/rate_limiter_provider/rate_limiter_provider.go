@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync"
 
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
@@ -21,9 +22,11 @@ type RateLimiter struct {
 	log   *logger_provider.Logger
 	redis *redis_provider.Redis
 
+	mu          sync.RWMutex
 	defaultRate limiter.Rate
 	store       limiter.Store
 	limiter     *limiter.Limiter
+	tenants     *tenantLimits
 
 	prefix       string
 	clientIPHdr  string
@@ -67,31 +70,34 @@ func NewRateLimiterProvider(in In) (*RateLimiter, error) {
 	ipv6MaskBits := in.Cfg.GetInt("rate_limiter.ipv6_mask_bits", 0)
 	p.ipv6MaskBits = ipv6MaskBits
 
-	rateStr := strings.TrimSpace(in.Cfg.GetString("rate_limiter.default_rate", "60-M"))
-	rate, err := limiter.NewRateFromFormatted(rateStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid rate_limiter.default_rate: %w", err)
-	}
-	p.defaultRate = rate
-
-	store, err := limiterredis.NewStoreWithOptions(p.redis.Client(), limiter.StoreOptions{Prefix: p.prefix})
+	store, err := limiterredis.NewStoreWithOptions(p.redis.UniversalClient(), limiter.StoreOptions{Prefix: p.prefix})
 	if err != nil {
 		return nil, err
 	}
 	p.store = &failSafeStore{underlying: store, log: p.log}
 
-	var opts []limiter.Option
-	if p.clientIPHdr != "" {
-		opts = append(opts, limiter.WithClientIPHeader(p.clientIPHdr))
-	}
-	if p.ipv6MaskBits > 0 {
-		opts = append(opts, limiter.WithIPv6Mask(net.CIDRMask(p.ipv6MaskBits, 128)))
+	if err := p.load(); err != nil {
+		return nil, err
 	}
 
-	p.limiter = limiter.New(p.store, p.defaultRate, opts...)
+	// 配置中心下发变更后热更新默认速率与租户套餐
+	in.Cfg.OnChange(func(event config_provider.ChangeEvent) {
+		if !event.Has("rate_limiter") {
+			return
+		}
+		if err := p.load(); err != nil {
+			if p.log != nil {
+				p.log.Warnw("rate limiter reload failed, keeping previous limits", "error", err)
+			}
+			return
+		}
+		if p.log != nil {
+			p.log.Infow("rate limiter limits reloaded", "default_rate", p.DefaultRate().Formatted)
+		}
+	})
 
 	if p.log != nil {
-		p.log.Infow("provider[rate_limiter] enabled", "default_rate", rateStr, "prefix", p.prefix)
+		p.log.Infow("provider[rate_limiter] enabled", "default_rate", p.DefaultRate().Formatted, "prefix", p.prefix)
 	}
 
 	return p, nil
@@ -121,10 +127,49 @@ func (p *RateLimiter) Enabled() bool { return p.enabled }
 func (p *RateLimiter) Store() limiter.Store { return p.store }
 
 // Limiter 返回默认 limiter 实例。
-func (p *RateLimiter) Limiter() *limiter.Limiter { return p.limiter }
+func (p *RateLimiter) Limiter() *limiter.Limiter {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.limiter
+}
 
 // DefaultRate 返回默认限流速率。
-func (p *RateLimiter) DefaultRate() limiter.Rate { return p.defaultRate }
+func (p *RateLimiter) DefaultRate() limiter.Rate {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.defaultRate
+}
+
+// load 读取默认速率与租户套餐，全部校验通过后才替换当前配置
+func (p *RateLimiter) load() error {
+	rateStr := strings.TrimSpace(p.cfg.GetString("rate_limiter.default_rate"))
+	if rateStr == "" {
+		rateStr = "60-M"
+	}
+	rate, err := limiter.NewRateFromFormatted(rateStr)
+	if err != nil {
+		return fmt.Errorf("invalid rate_limiter.default_rate: %w", err)
+	}
+	tenants, err := loadTenantLimits(p.cfg)
+	if err != nil {
+		return err
+	}
+
+	var opts []limiter.Option
+	if p.clientIPHdr != "" {
+		opts = append(opts, limiter.WithClientIPHeader(p.clientIPHdr))
+	}
+	if p.ipv6MaskBits > 0 {
+		opts = append(opts, limiter.WithIPv6Mask(net.CIDRMask(p.ipv6MaskBits, 128)))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.defaultRate = rate
+	p.limiter = limiter.New(p.store, rate, opts...)
+	p.tenants = tenants
+	return nil
+}
 
 // GinConfig 返回给 Gin middleware 使用的策略列表。
 func (p *RateLimiter) GinConfig() *ProviderConfig {
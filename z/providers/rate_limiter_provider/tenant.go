@@ -0,0 +1,85 @@
+package rate_limiter_provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/ulule/limiter/v3"
+)
+
+// PlanCustom 租户直接指定速率而非引用套餐时的套餐名
+const PlanCustom = "custom"
+
+// tenantLimits 按租户套餐的限流配置快照
+type tenantLimits struct {
+	plans       map[string]limiter.Rate
+	tenants     map[string]string       // 租户 -> 套餐
+	custom      map[string]limiter.Rate // 租户 -> 单独指定的速率
+	defaultPlan string
+}
+
+// loadTenantLimits 读取 rate_limiter.plans / tenants / default_plan
+//
+//	rate_limiter:
+//	  plans:
+//	    free: 60-M
+//	    pro: 600-M
+//	  tenants:
+//	    t_1001: pro      # 引用套餐
+//	    t_1002: 5000-M   # 或直接指定速率
+//	  default_plan: free # 未列出的租户使用的套餐，为空时使用 default_rate
+//
+// 配置键会被转为小写，租户 ID 按不区分大小写匹配
+func loadTenantLimits(cfg *config_provider.Config) (*tenantLimits, error) {
+	t := &tenantLimits{plans: map[string]limiter.Rate{}, tenants: map[string]string{}, custom: map[string]limiter.Rate{}}
+	for name, v := range cfg.GetStringMap("rate_limiter.plans") {
+		rate, err := limiter.NewRateFromFormatted(strings.TrimSpace(z.ToString(v)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate_limiter.plans.%s: %w", name, err)
+		}
+		t.plans[name] = rate
+	}
+	for tenant, v := range cfg.GetStringMap("rate_limiter.tenants") {
+		value := strings.TrimSpace(z.ToString(v))
+		if _, ok := t.plans[strings.ToLower(value)]; ok {
+			t.tenants[tenant] = strings.ToLower(value)
+			continue
+		}
+		rate, err := limiter.NewRateFromFormatted(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate_limiter.tenants.%s: unknown plan or rate %q", tenant, value)
+		}
+		t.tenants[tenant] = PlanCustom
+		t.custom[tenant] = rate
+	}
+	t.defaultPlan = strings.ToLower(strings.TrimSpace(cfg.GetString("rate_limiter.default_plan")))
+	if _, ok := t.plans[t.defaultPlan]; t.defaultPlan != "" && !ok {
+		return nil, fmt.Errorf("invalid rate_limiter.default_plan: unknown plan %q", t.defaultPlan)
+	}
+	return t, nil
+}
+
+// TenantRate 返回租户的限流速率及套餐名，租户未列出且未配置 default_plan 时返回 false
+func (p *RateLimiter) TenantRate(tenantID string) (limiter.Rate, string, bool) {
+	if tenantID == "" {
+		return limiter.Rate{}, "", false
+	}
+	p.mu.RLock()
+	t := p.tenants
+	p.mu.RUnlock()
+	if t == nil {
+		return limiter.Rate{}, "", false
+	}
+	tenantID = strings.ToLower(tenantID)
+	if rate, ok := t.custom[tenantID]; ok {
+		return rate, PlanCustom, true
+	}
+	plan, ok := t.tenants[tenantID]
+	if !ok {
+		plan = t.defaultPlan
+	}
+	rate, ok := t.plans[plan]
+	return rate, plan, ok
+}
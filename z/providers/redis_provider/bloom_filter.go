@@ -0,0 +1,56 @@
+package redis_provider
+
+import (
+	"context"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/redis/go-redis/v9"
+)
+
+// BloomFilter 基于 Redis 位图的分布式布隆过滤器，多实例共享
+type BloomFilter struct {
+	redis *Redis
+	key   string
+	m     uint
+	k     uint
+}
+
+// NewBloomFilter 创建 Redis 布隆过滤器，key 为位图存储键
+func (r *Redis) NewBloomFilter(key string, expected uint, fpRate float64) *BloomFilter {
+	m, k := z.BloomParams(expected, fpRate)
+	return &BloomFilter{redis: r, key: key, m: m, k: k}
+}
+
+// Add 添加元素
+func (f *BloomFilter) Add(ctx context.Context, key string) error {
+	pipe := f.redis.client.Pipeline()
+	for _, loc := range z.BloomLocations(key, f.m, f.k) {
+		pipe.SetBit(ctx, f.key, int64(loc), 1)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// MightContain 判断元素是否可能存在，返回 false 时元素一定不存在
+func (f *BloomFilter) MightContain(ctx context.Context, key string) (bool, error) {
+	pipe := f.redis.client.Pipeline()
+	locations := z.BloomLocations(key, f.m, f.k)
+	cmds := make([]*redis.IntCmd, len(locations))
+	for i, loc := range locations {
+		cmds[i] = pipe.GetBit(ctx, f.key, int64(loc))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	for _, cmd := range cmds {
+		if cmd.Val() == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Reset 清空过滤器
+func (f *BloomFilter) Reset(ctx context.Context) error {
+	return f.redis.client.Del(ctx, f.key).Err()
+}
@@ -0,0 +1,122 @@
+package redis_provider
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheTagPrefix 标签集合键前缀
+const cacheTagPrefix = "cache:tag:"
+
+// tagAddScript 将 key 加入标签集合（KEYS[1]）并维护集合过期时间：
+// 集合过期时间不短于其中任意成员；成员无过期时间时集合也不过期
+var tagAddScript = redis.NewScript(`
+local tag = KEYS[1]
+local ttl = tonumber(ARGV[2])
+redis.call('SADD', tag, ARGV[1])
+if ttl <= 0 then
+	redis.call('PERSIST', tag)
+else
+	local current = redis.call('PTTL', tag)
+	if (current == -1 and redis.call('SCARD', tag) == 1) or (current >= 0 and current < ttl) then
+		redis.call('PEXPIRE', tag, ttl)
+	end
+end
+return 1
+`)
+
+// tagPopScript 取出标签集合（KEYS[1]）的全部成员并删除集合
+var tagPopScript = redis.NewScript(`
+local members = redis.call('SMEMBERS', KEYS[1])
+redis.call('DEL', KEYS[1])
+return members
+`)
+
+// TagKey 返回标签对应的集合键
+func TagKey(tag string) string {
+	return cacheTagPrefix + tag
+}
+
+// SetWithTags 设置 key 的值并关联标签，之后可通过 InvalidateTag 批量删除
+func (r *Redis) SetWithTags(key string, value interface{}, duration time.Duration, tags ...string) error {
+	return r.SetWithTagsCtx(context.Background(), key, value, duration, tags...)
+}
+
+// SetWithTagsCtx 设置 key 的值并关联标签
+func (r *Redis) SetWithTagsCtx(ctx context.Context, key string, value interface{}, duration time.Duration, tags ...string) error {
+	jsonValue, err := r.encode(value)
+	if err != nil {
+		return err
+	}
+
+	tagKeys := normalizeTagKeys(tags)
+	if err := r.client.Set(ctx, key, jsonValue, duration).Err(); err != nil {
+		return err
+	}
+	// 每个标签单独执行，兼容 cluster 模式下标签分布在不同 slot
+	for _, tagKey := range tagKeys {
+		if err := tagAddScript.Run(ctx, r.client, []string{tagKey}, key, duration.Milliseconds()).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TagKeys 获取标签下关联的 key
+func (r *Redis) TagKeys(tag string) ([]string, error) {
+	return r.TagKeysCtx(context.Background(), tag)
+}
+
+// TagKeysCtx 获取标签下关联的 key
+func (r *Redis) TagKeysCtx(ctx context.Context, tag string) ([]string, error) {
+	return r.client.SMembers(ctx, TagKey(tag)).Result()
+}
+
+// InvalidateTag 删除标签下关联的所有 key 及标签集合本身，返回删除的 key 数量
+func (r *Redis) InvalidateTag(tags ...string) (int64, error) {
+	return r.InvalidateTagCtx(context.Background(), tags...)
+}
+
+// InvalidateTagCtx 删除标签下关联的所有 key 及标签集合本身
+// 先原子地取出并删除标签集合，再删除 key：并发写入的 key 会重新加入新的集合，不会因为清理集合而失去关联
+func (r *Redis) InvalidateTagCtx(ctx context.Context, tags ...string) (int64, error) {
+	var deleted int64
+	for _, tagKey := range normalizeTagKeys(tags) {
+		keys, err := tagPopScript.Run(ctx, r.client, []string{tagKey}).StringSlice()
+		if err != nil {
+			return deleted, err
+		}
+		n, err := r.DeleteKeys(ctx, keys...)
+		deleted += n
+		if err != nil {
+			// 删除失败时将 key 放回集合，以便下次失效时重试
+			members := make([]interface{}, len(keys))
+			for i, k := range keys {
+				members[i] = k
+			}
+			_ = r.client.SAdd(ctx, tagKey, members...).Err()
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+func normalizeTagKeys(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, TagKey(tag))
+	}
+	return out
+}
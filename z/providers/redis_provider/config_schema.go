@@ -0,0 +1,31 @@
+package redis_provider
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// redisConfig redis.yml 配置结构
+type redisConfig struct {
+	Mode           string `default:"standalone" desc:"部署模式：standalone / sentinel / cluster"`
+	Host           string `default:"127.0.0.1" desc:"主机地址（standalone）"`
+	Port           int    `default:"6379" desc:"端口（standalone）"`
+	DB             int    `config:"db" desc:"数据库编号，cluster 模式不支持"`
+	Username       string `desc:"ACL 用户名"`
+	Password       string `desc:"密码"`
+	ReadFrom       string `default:"master" desc:"读请求路由：master / replica / latency / random，仅 sentinel 与 cluster 生效"`
+	Trace          bool   `default:"true" desc:"记录命令链路追踪"`
+	TraceStatement bool   `default:"false" desc:"追踪中记录完整命令（可能包含敏感数据）"`
+	Sentinel       struct {
+		MasterName string   `desc:"主节点名称"`
+		Addrs      []string `desc:"哨兵地址列表，如 10.0.0.1:26379"`
+		Username   string   `desc:"哨兵用户名"`
+		Password   string   `desc:"哨兵密码"`
+	} `desc:"哨兵模式"`
+	Cluster struct {
+		Addrs []string `desc:"集群节点地址列表"`
+	} `desc:"集群模式"`
+	Compression struct {
+		Codec   string `default:"none" desc:"压缩算法：none / gzip / zstd / snappy，作用于缓存值及 websocket 离线通知"`
+		MinSize int    `default:"1024" desc:"压缩阈值（字节）"`
+	} `desc:"值压缩"`
+}
+
+var _ = config_provider.RegisterSchema("redis", "Redis 连接", redisConfig{})
@@ -2,11 +2,14 @@ package redis_provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/goccy/go-json"
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
 	"github.com/redis/go-redis/v9"
@@ -14,27 +17,48 @@ import (
 )
 
 // Redis 封装 go-redis 客户端并提供常用便捷方法。
+// 支持 standalone、sentinel、cluster 三种模式，由 redis.mode 配置决定。
 type Redis struct {
-	client *redis.Client
+	client redis.UniversalClient
 	log    *logger_provider.Logger
+	mode   string
+	addr   string
+	codec  *z.CompressionCodec // Get / Set 值压缩，nil 表示不压缩
 }
 
+// Redis 部署模式
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
 // NewRedisProvider 创建 redis 实例
+//
+//	redis:
+//	  compression:
+//	    codec: zstd      # gzip / zstd / snappy，默认 none；Get / Set 的值及 websocket 离线通知超过阈值时压缩
+//	    min_size: 1024   # 压缩阈值（字节）
 func NewRedisProvider(lc fx.Lifecycle, cfg *config_provider.Config, log *logger_provider.Logger) (*Redis, error) {
-	host := cfg.GetString("redis.host")
-	port := cfg.GetInt("redis.port")
-	password := cfg.GetString("redis.password")
-	db := cfg.GetInt("redis.db")
-
-	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", host, port),
-		Password: password,
-		DB:       db,
-	})
+	client, mode, addr, err := newUniversalClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := z.NewCompressionCodec(cfg.GetString("redis.compression.codec"), cfg.GetInt("redis.compression.min_size", 0), "redis")
+	if err != nil {
+		return nil, err
+	}
+	// 默认开启，仅显式配置 trace: false 时关闭
+	if !cfg.IsSet("redis.trace") || cfg.GetBool("redis.trace") {
+		client.AddHook(newTracingHook(mode, addr, cfg.GetBool("redis.trace_statement", false)))
+	}
 
 	r := &Redis{
 		client: client,
 		log:    log,
+		mode:   mode,
+		addr:   addr,
+		codec:  codec,
 	}
 
 	lc.Append(fx.Hook{
@@ -43,7 +67,7 @@ func NewRedisProvider(lc fx.Lifecycle, cfg *config_provider.Config, log *logger_
 				log.Errorw("redis connect error", "error", err)
 				return err
 			}
-			log.Infow("provider[redis] enabled", "addr", fmt.Sprintf("%s:%d", host, port))
+			log.Infow("provider[redis] enabled", "mode", mode, "addr", addr)
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
@@ -71,27 +95,127 @@ func NewRedisProvider(lc fx.Lifecycle, cfg *config_provider.Config, log *logger_
 	return r, nil
 }
 
+// newUniversalClient 按 redis.mode 创建客户端
+// redis.read_from 控制读请求路由：master（默认）、replica、latency、random，
+// 仅 sentinel 与 cluster 模式生效；主从切换由 go-redis 自动处理
+func newUniversalClient(cfg *config_provider.Config) (redis.UniversalClient, string, string, error) {
+	mode := strings.ToLower(strings.TrimSpace(cfg.GetString("redis.mode", ModeStandalone)))
+	password := cfg.GetString("redis.password")
+	username := cfg.GetString("redis.username")
+	readFrom := strings.ToLower(strings.TrimSpace(cfg.GetString("redis.read_from", "master")))
+
+	switch mode {
+	case "", ModeStandalone:
+		host := cfg.GetString("redis.host")
+		port := cfg.GetInt("redis.port")
+		addr := fmt.Sprintf("%s:%d", host, port)
+		client := redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Username: username,
+			Password: password,
+			DB:       cfg.GetInt("redis.db"),
+		})
+		return client, ModeStandalone, addr, nil
+
+	case ModeSentinel:
+		masterName := strings.TrimSpace(cfg.GetString("redis.sentinel.master_name"))
+		addrs := cfg.GetStringSlice("redis.sentinel.addrs")
+		if masterName == "" || len(addrs) == 0 {
+			return nil, "", "", errors.New("redis sentinel requires redis.sentinel.master_name and redis.sentinel.addrs")
+		}
+		opt := &redis.FailoverOptions{
+			MasterName:       masterName,
+			SentinelAddrs:    addrs,
+			SentinelUsername: cfg.GetString("redis.sentinel.username"),
+			SentinelPassword: cfg.GetString("redis.sentinel.password"),
+			Username:         username,
+			Password:         password,
+			DB:               cfg.GetInt("redis.db"),
+		}
+		desc := masterName + "@" + strings.Join(addrs, ",")
+		switch readFrom {
+		case "replica":
+			opt.ReplicaOnly = true
+		case "latency":
+			opt.RouteByLatency = true
+		case "random":
+			opt.RouteRandomly = true
+		default:
+			return redis.NewFailoverClient(opt), ModeSentinel, desc, nil
+		}
+		return redis.NewFailoverClusterClient(opt), ModeSentinel, desc, nil
+
+	case ModeCluster:
+		addrs := cfg.GetStringSlice("redis.cluster.addrs")
+		if len(addrs) == 0 {
+			return nil, "", "", errors.New("redis cluster requires redis.cluster.addrs")
+		}
+		opt := &redis.ClusterOptions{
+			Addrs:    addrs,
+			Username: username,
+			Password: password,
+		}
+		switch readFrom {
+		case "replica":
+			opt.ReadOnly = true
+		case "latency":
+			opt.RouteByLatency = true
+		case "random":
+			opt.RouteRandomly = true
+		}
+		return redis.NewClusterClient(opt), ModeCluster, strings.Join(addrs, ","), nil
+	}
+
+	return nil, "", "", fmt.Errorf("unknown redis mode: %s", mode)
+}
+
 // RedisProviderModule redis 模块
 var RedisProviderModule = fx.Options(
 	fx.Provide(NewRedisProvider),
+	fx.Provide(
+		fx.Annotate(
+			func(r *Redis) z.ReadyCheck { return z.ReadyCheck{Name: "redis", Checker: r} },
+			fx.ResultTags(`group:"ready_checks"`),
+		),
+	),
 )
 
+// readyTimeout 就绪检查的 Ping 超时
+const readyTimeout = 2 * time.Second
+
+// Ready 检查 redis 连接是否可用
+func (r *Redis) Ready() error {
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+	return r.client.Ping(ctx).Err()
+}
+
 // Get 获取 key 的值
 func (r *Redis) Get(key string, dest interface{}) error {
-	ctx := context.Background()
-	res, err := r.client.Get(ctx, key).Result()
+	return r.GetCtx(context.Background(), key, dest)
+}
+
+// GetCtx 获取 key 的值，ctx 的截止时间与链路追踪传递到 Redis 命令
+func (r *Redis) GetCtx(ctx context.Context, key string, dest interface{}) error {
+	res, err := r.client.Get(ctx, key).Bytes()
 	if err != nil {
 		return err
 	}
+	if res, err = r.codec.Decode(res); err != nil {
+		return err
+	}
 
-	return json.Unmarshal([]byte(res), dest)
+	return json.Unmarshal(res, dest)
 }
 
 // Set 设置 key 的值
 func (r *Redis) Set(key string, value interface{}, duration time.Duration) error {
-	ctx := context.Background()
+	return r.SetCtx(context.Background(), key, value, duration)
+}
 
-	jsonValue, err := json.Marshal(value)
+// SetCtx 设置 key 的值
+func (r *Redis) SetCtx(ctx context.Context, key string, value interface{}, duration time.Duration) error {
+	jsonValue, err := r.encode(value)
 	if err != nil {
 		return err
 	}
@@ -99,43 +223,163 @@ func (r *Redis) Set(key string, value interface{}, duration time.Duration) error
 	return r.client.Set(ctx, key, jsonValue, duration).Err()
 }
 
+// encode 序列化为 JSON，开启压缩时超过阈值的值压缩
+func (r *Redis) encode(value interface{}) ([]byte, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return r.codec.Encode(b)
+}
+
+// Codec 返回值压缩编解码器，未开启压缩时为 nil（Decode 仍可读取压缩值）
+func (r *Redis) Codec() *z.CompressionCodec {
+	return r.codec
+}
+
 // Exists 判断 key 是否存在
 func (r *Redis) Exists(key string) bool {
-	ctx := context.Background()
+	exists, err := r.ExistsCtx(context.Background(), key)
+	return err == nil && exists
+}
 
-	exists, err := r.client.Exists(ctx, key).Result()
-	if 0 == exists || err != nil {
-		return false
+// ExistsCtx 判断 key 是否存在，区分不存在与查询失败
+func (r *Redis) ExistsCtx(ctx context.Context, key string) (bool, error) {
+	n, err := r.client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
 	}
-
-	return true
+	return n > 0, nil
 }
 
 // Delete 删除 key
 func (r *Redis) Delete(key string) error {
-	ctx := context.Background()
+	return r.DeleteCtx(context.Background(), key)
+}
+
+// DeleteCtx 删除 key
+func (r *Redis) DeleteCtx(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
 // Expire 设置 key 的过期时间
 func (r *Redis) Expire(key string, duration time.Duration) error {
-	ctx := context.Background()
+	return r.ExpireCtx(context.Background(), key, duration)
+}
+
+// ExpireCtx 设置 key 的过期时间
+func (r *Redis) ExpireCtx(ctx context.Context, key string, duration time.Duration) error {
 	return r.client.Expire(ctx, key, duration).Err()
 }
 
 // TTL 获取 key 剩余的时间
 func (r *Redis) TTL(key string) (time.Duration, error) {
-	ctx := context.Background()
+	return r.TTLCtx(context.Background(), key)
+}
+
+// TTLCtx 获取 key 剩余的时间
+func (r *Redis) TTLCtx(ctx context.Context, key string) (time.Duration, error) {
 	return r.client.TTL(ctx, key).Result()
 }
 
-// Keys 根据模式获取匹配的键列表
+// Keys 根据模式获取匹配的键列表，cluster 模式下汇总所有主节点
 func (r *Redis) Keys(pattern string) ([]string, error) {
-	ctx := context.Background()
-	return r.client.Keys(ctx, pattern).Result()
+	return r.KeysCtx(context.Background(), pattern)
 }
 
-// Client 获取 Redis 客户端实例
+// KeysCtx 根据模式获取匹配的键列表，cluster 模式下汇总所有主节点
+func (r *Redis) KeysCtx(ctx context.Context, pattern string) ([]string, error) {
+	cluster, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		return r.client.Keys(ctx, pattern).Result()
+	}
+
+	var mu sync.Mutex
+	keys := make([]string, 0)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		nodeKeys, err := node.Keys(ctx, pattern).Result()
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		keys = append(keys, nodeKeys...)
+		mu.Unlock()
+		return nil
+	})
+	return keys, err
+}
+
+// ScanKeys 使用 SCAN 遍历匹配的键，cluster 模式下遍历所有主节点
+func (r *Redis) ScanKeys(ctx context.Context, pattern string, count int64) ([]string, error) {
+	if count <= 0 {
+		count = 100
+	}
+	scan := func(ctx context.Context, client redis.Cmdable) ([]string, error) {
+		keys := make([]string, 0, 32)
+		iter := client.Scan(ctx, 0, pattern, count).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		return keys, iter.Err()
+	}
+
+	cluster, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		return scan(ctx, r.client)
+	}
+
+	var mu sync.Mutex
+	keys := make([]string, 0, 32)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		nodeKeys, err := scan(ctx, node)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		keys = append(keys, nodeKeys...)
+		mu.Unlock()
+		return nil
+	})
+	return keys, err
+}
+
+// DeleteKeys 批量删除 key，逐个发送 DEL 以兼容 cluster 跨 slot，返回删除数量
+func (r *Redis) DeleteKeys(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	cmds := make([]*redis.IntCmd, 0, len(keys))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			cmds = append(cmds, pipe.Del(ctx, key))
+		}
+		return nil
+	})
+	var deleted int64
+	for _, cmd := range cmds {
+		deleted += cmd.Val()
+	}
+	return deleted, err
+}
+
+// Client 获取 Redis 单节点客户端实例
+// standalone 模式及 read_from 为 master 的 sentinel 模式下可用，其他模式返回 nil，请使用 UniversalClient
 func (r *Redis) Client() *redis.Client {
+	client, _ := r.client.(*redis.Client)
+	return client
+}
+
+// UniversalClient 获取 Redis 客户端实例，适用于所有部署模式
+func (r *Redis) UniversalClient() redis.UniversalClient {
 	return r.client
 }
+
+// Mode 返回当前部署模式
+func (r *Redis) Mode() string {
+	return r.mode
+}
+
+// Addr 返回连接地址描述
+func (r *Redis) Addr() string {
+	return r.addr
+}
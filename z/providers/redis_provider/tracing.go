@@ -0,0 +1,94 @@
+package redis_provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/icreateapp-com/go-zLib/redis"
+
+// tracingHook 为每个 Redis 命令创建客户端 span，仅在 ctx 已有有效 span 时创建，避免产生孤立的根 span
+// 命令参数可能包含业务数据，默认只记录命令名，redis.trace_statement 开启后记录完整命令
+type tracingHook struct {
+	attrs     []attribute.KeyValue
+	statement bool
+}
+
+func newTracingHook(mode, addr string, statement bool) *tracingHook {
+	return &tracingHook{
+		attrs: []attribute.KeyValue{
+			attribute.String("db.system", "redis"),
+			attribute.String("db.redis.mode", mode),
+			attribute.String("server.address", addr),
+		},
+		statement: statement,
+	}
+}
+
+func (h *tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return next(ctx, cmd)
+		}
+		attrs := append(h.attrs, attribute.String("db.operation", cmd.FullName()))
+		if h.statement {
+			attrs = append(attrs, attribute.String("db.statement", cmd.String()))
+		}
+		ctx, span := otel.Tracer(tracerName).Start(ctx, "redis "+cmd.FullName(),
+			trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+		defer span.End()
+
+		err := next(ctx, cmd)
+		recordError(span, err)
+		return err
+	}
+}
+
+func (h *tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return next(ctx, cmds)
+		}
+		names := make([]string, 0, len(cmds))
+		for _, cmd := range cmds {
+			names = append(names, cmd.FullName())
+		}
+		attrs := append(h.attrs,
+			attribute.String("db.operation", "pipeline"),
+			attribute.Int("db.redis.num_cmd", len(cmds)),
+			attribute.String("db.redis.commands", strings.Join(names, " ")),
+		)
+		ctx, span := otel.Tracer(tracerName).Start(ctx, "redis pipeline",
+			trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+		defer span.End()
+
+		err := next(ctx, cmds)
+		recordError(span, err)
+		return err
+	}
+}
+
+// recordError 记录命令错误，key 不存在（redis.Nil）不视为错误
+func recordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		span.SetAttributes(attribute.Bool("db.redis.timeout", true))
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
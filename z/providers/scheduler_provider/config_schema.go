@@ -0,0 +1,12 @@
+package scheduler_provider
+
+import (
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// schedulerConfig scheduler.yml 配置结构
+type schedulerConfig struct {
+	HistorySize int `default:"20" desc:"每个定时任务保留的执行记录数"`
+}
+
+var _ = config_provider.RegisterSchema("scheduler", "进程内定时任务", schedulerConfig{})
@@ -0,0 +1,108 @@
+package scheduler_provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"github.com/icreateapp-com/go-zLib/z/scheduler"
+	"go.uber.org/fx"
+)
+
+// Task 由业务模块提供的定时任务（fx group），Spec 与 Trigger 二选一
+type Task struct {
+	Name    string
+	Spec    string            // cron 表达式
+	Trigger scheduler.Trigger // scheduler.Every / scheduler.At 等
+	Job     scheduler.Job
+	Options scheduler.TaskOptions
+}
+
+// In Scheduler 的 fx 入参
+type In struct {
+	fx.In
+
+	LC    fx.Lifecycle
+	Cfg   *config_provider.Config
+	Log   *logger_provider.Logger `optional:"true"`
+	Clock z.Clock                 `optional:"true"`
+	// Leader 多副本选主，启用 job_provider 时由其 JobScheduler 提供；为空时每个实例都执行
+	Leader scheduler.LeaderChecker `optional:"true"`
+	Tasks  []Task                  `group:"scheduler_tasks"`
+}
+
+// TaskOut 由业务模块提供定时任务（fx group）
+type TaskOut struct {
+	fx.Out
+	Task Task `group:"scheduler_tasks"`
+}
+
+// Cron 按 cron 表达式注册定时任务
+//
+//	fx.Provide(func(svc *ReportService) scheduler_provider.TaskOut {
+//		return scheduler_provider.Cron("report.archive", "0 3 * * *", svc.Archive)
+//	})
+func Cron(name, spec string, job scheduler.Job, opt ...scheduler.TaskOptions) TaskOut {
+	t := Task{Name: name, Spec: spec, Job: job}
+	if len(opt) > 0 {
+		t.Options = opt[0]
+	}
+	return TaskOut{Task: t}
+}
+
+// Register 按触发规则注册定时任务
+func Register(name string, trigger scheduler.Trigger, job scheduler.Job, opt ...scheduler.TaskOptions) TaskOut {
+	t := Task{Name: name, Trigger: trigger, Job: job}
+	if len(opt) > 0 {
+		t.Options = opt[0]
+	}
+	return TaskOut{Task: t}
+}
+
+// NewSchedulerProvider 创建调度器实例（fx Provider），应用启动后开始调度
+func NewSchedulerProvider(in In) (*scheduler.Scheduler, error) {
+	opt := scheduler.Options{
+		Clock:       in.Clock,
+		HistorySize: in.Cfg.GetInt("scheduler.history_size", scheduler.DefaultHistorySize),
+		Leader:      in.Leader,
+	}
+	if in.Log != nil {
+		opt.Logger = in.Log
+	}
+	s := scheduler.New(opt)
+
+	for _, task := range in.Tasks {
+		var err error
+		if strings.TrimSpace(task.Spec) != "" {
+			err = s.Cron(task.Name, task.Spec, task.Job, task.Options)
+		} else {
+			err = s.Schedule(task.Name, task.Trigger, task.Job, task.Options)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("scheduler task %s: %w", task.Name, err)
+		}
+	}
+
+	in.LC.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			n := s.Start()
+			if in.Log != nil {
+				in.Log.Infow("provider[scheduler] enabled", "tasks", n)
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return s.Stop(ctx)
+		},
+	})
+
+	return s, nil
+}
+
+// SchedulerProviderModule 提供 Scheduler 的 fx 模块
+var SchedulerProviderModule = fx.Options(
+	fx.Provide(NewSchedulerProvider),
+)
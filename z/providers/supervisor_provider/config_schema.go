@@ -0,0 +1,15 @@
+package supervisor_provider
+
+import (
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// supervisorConfig supervisor.yml 配置结构
+type supervisorConfig struct {
+	InitialBackoff time.Duration `default:"1s" desc:"首次重启等待"`
+	MaxBackoff     time.Duration `default:"1m" desc:"最大重启等待"`
+}
+
+var _ = config_provider.RegisterSchema("supervisor", "后台任务守护", supervisorConfig{})
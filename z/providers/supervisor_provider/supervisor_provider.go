@@ -0,0 +1,352 @@
+package supervisor_provider
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/event_bus_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"go.uber.org/fx"
+)
+
+// 事件名称
+const (
+	EventTaskFailed    = "supervisor.task_failed"    // 任务退出或 panic
+	EventTaskRestarted = "supervisor.task_restarted" // 任务已重启
+	EventTaskGaveUp    = "supervisor.task_gave_up"   // 超过最大重启次数，不再重启
+)
+
+// TaskFunc 长期运行的后台任务，应阻塞直到 ctx 取消
+type TaskFunc func(ctx context.Context) error
+
+// Task 受监管的后台任务
+type Task struct {
+	Name           string        // 任务名称
+	Run            TaskFunc      // 任务函数
+	InitialBackoff time.Duration // 首次重启等待，默认取配置 supervisor.initial_backoff
+	MaxBackoff     time.Duration // 最大重启等待，默认取配置 supervisor.max_backoff
+	MaxRestarts    int           // 最大重启次数，0 表示不限制
+}
+
+// TaskEvent 任务事件载荷
+type TaskEvent struct {
+	Name     string        `json:"name"`
+	Error    string        `json:"error,omitempty"`
+	Panic    bool          `json:"panic"`
+	Restarts int           `json:"restarts"`
+	Backoff  time.Duration `json:"backoff"`
+}
+
+// TaskStatus 任务运行状态
+type TaskStatus struct {
+	Name      string    `json:"name"`
+	Running   bool      `json:"running"`
+	Restarts  int       `json:"restarts"`
+	Panics    int       `json:"panics"`
+	LastError string    `json:"last_error,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	FailedAt  time.Time `json:"failed_at,omitempty"`
+}
+
+// Supervisor 后台任务监管器：任务退出或 panic 时按退避策略重启并发出告警事件
+type Supervisor struct {
+	log   *logger_provider.Logger
+	bus   *event_bus_provider.EventBus
+	clock z.Clock
+
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	pending []Task
+	status  map[string]*TaskStatus
+	wg      sync.WaitGroup
+}
+
+// In Supervisor 的 fx 入参
+type In struct {
+	fx.In
+
+	LC    fx.Lifecycle
+	Cfg   *config_provider.Config
+	Log   *logger_provider.Logger
+	Bus   *event_bus_provider.EventBus `optional:"true"`
+	Clock z.Clock                      `optional:"true"`
+	Tasks []Task                       `group:"supervisor_tasks"`
+}
+
+// TaskOut 由业务模块提供受监管任务（fx group）
+type TaskOut struct {
+	fx.Out
+	Task Task `group:"supervisor_tasks"`
+}
+
+// Register 注册受监管任务
+func Register(name string, run TaskFunc) TaskOut {
+	return TaskOut{Task: Task{Name: name, Run: run}}
+}
+
+// NewSupervisor 创建监管器（不依赖 fx 时使用）
+func NewSupervisor(log *logger_provider.Logger, bus *event_bus_provider.EventBus, initialBackoff, maxBackoff time.Duration) *Supervisor {
+	if initialBackoff <= 0 {
+		initialBackoff = time.Second
+	}
+	if maxBackoff < initialBackoff {
+		maxBackoff = initialBackoff
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Supervisor{
+		log:            log,
+		bus:            bus,
+		clock:          z.SystemClock,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		ctx:            ctx,
+		cancel:         cancel,
+		status:         map[string]*TaskStatus{},
+	}
+}
+
+// WithClock 设置重启退避使用的时钟，需在 Start 之前调用，c 为空时使用系统时钟
+func (s *Supervisor) WithClock(c z.Clock) *Supervisor {
+	s.clock = z.ClockOr(c)
+	return s
+}
+
+// NewSupervisorProvider 创建监管器实例（fx Provider）
+func NewSupervisorProvider(in In) *Supervisor {
+	initialBackoff := in.Cfg.GetDuration("supervisor.initial_backoff")
+	if initialBackoff <= 0 {
+		initialBackoff = time.Second
+	}
+	maxBackoff := in.Cfg.GetDuration("supervisor.max_backoff")
+	if maxBackoff <= 0 {
+		maxBackoff = time.Minute
+	}
+	s := NewSupervisor(in.Log, in.Bus, initialBackoff, maxBackoff).WithClock(in.Clock)
+	for _, task := range in.Tasks {
+		s.Go(task)
+	}
+
+	in.LC.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			n := s.Start()
+			if in.Log != nil {
+				in.Log.Infow("provider[supervisor] enabled", "tasks", n)
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return s.Stop(ctx)
+		},
+	})
+
+	return s
+}
+
+// SupervisorProviderModule 提供 Supervisor 的 fx 模块
+var SupervisorProviderModule = fx.Options(
+	fx.Provide(NewSupervisorProvider),
+)
+
+// Go 添加受监管任务，监管器启动前添加的任务在启动时运行
+func (s *Supervisor) Go(task Task) {
+	task.Name = strings.TrimSpace(task.Name)
+	if task.Name == "" || task.Run == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.status[task.Name]; exists {
+		if s.log != nil {
+			s.log.Warnw("supervisor task already registered", "task", task.Name)
+		}
+		return
+	}
+	s.status[task.Name] = &TaskStatus{Name: task.Name}
+
+	if !s.started {
+		s.pending = append(s.pending, task)
+		return
+	}
+	s.spawn(task)
+}
+
+// GoFunc 以默认退避策略添加受监管任务
+func (s *Supervisor) GoFunc(name string, run TaskFunc) {
+	s.Go(Task{Name: name, Run: run})
+}
+
+// Start 启动所有待运行任务，返回任务数量
+func (s *Supervisor) Start() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return len(s.status)
+	}
+	s.started = true
+	for _, task := range s.pending {
+		s.spawn(task)
+	}
+	s.pending = nil
+	return len(s.status)
+}
+
+// Stop 取消所有任务并等待退出
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status 返回所有任务的运行状态，可用于健康检查与指标上报
+func (s *Supervisor) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]TaskStatus, 0, len(s.status))
+	for _, st := range s.status {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// spawn 启动任务监管循环，调用方需持有 s.mu
+func (s *Supervisor) spawn(task Task) {
+	initial := task.InitialBackoff
+	if initial <= 0 {
+		initial = s.initialBackoff
+	}
+	maxBackoff := task.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = s.maxBackoff
+	}
+	if maxBackoff < initial {
+		maxBackoff = initial
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		backoff := initial
+		restarts := 0
+		for {
+			startedAt := s.clock.Now()
+			s.update(task.Name, func(st *TaskStatus) {
+				st.Running = true
+				st.StartedAt = startedAt
+			})
+
+			panicked, err := runTask(s.ctx, task.Run)
+
+			if s.ctx.Err() != nil {
+				s.update(task.Name, func(st *TaskStatus) { st.Running = false })
+				return
+			}
+
+			// 稳定运行超过最大退避时长后重置退避
+			if s.clock.Since(startedAt) >= maxBackoff {
+				backoff = initial
+			}
+
+			if err == nil {
+				err = fmt.Errorf("task exited unexpectedly")
+			}
+			s.update(task.Name, func(st *TaskStatus) {
+				st.Running = false
+				st.LastError = err.Error()
+				st.FailedAt = s.clock.Now()
+				if panicked {
+					st.Panics++
+				}
+			})
+
+			event := TaskEvent{Name: task.Name, Error: err.Error(), Panic: panicked, Restarts: restarts, Backoff: backoff}
+			if s.log != nil {
+				s.log.Errorw("supervisor task failed", "task", task.Name, "error", err.Error(), "panic", panicked, "restarts", restarts, "backoff", backoff.String())
+			}
+			s.emit(EventTaskFailed, event)
+
+			if task.MaxRestarts > 0 && restarts >= task.MaxRestarts {
+				if s.log != nil {
+					s.log.Errorw("supervisor task gave up", "task", task.Name, "restarts", restarts)
+				}
+				s.emit(EventTaskGaveUp, event)
+				return
+			}
+
+			timer := s.clock.NewTimer(backoff)
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C():
+			}
+
+			restarts++
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			s.update(task.Name, func(st *TaskStatus) { st.Restarts = restarts })
+			if s.log != nil {
+				s.log.Infow("supervisor task restarted", "task", task.Name, "restarts", restarts)
+			}
+			s.emit(EventTaskRestarted, TaskEvent{Name: task.Name, Restarts: restarts})
+		}
+	}()
+}
+
+// runTask 执行任务并将 panic 转换为错误
+func runTask(ctx context.Context, run TaskFunc) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			z.ReportPanic(ctx, r, stack, nil)
+			err = fmt.Errorf("panic: %v\n%s", r, stack)
+			panicked = true
+		}
+	}()
+	return false, run(ctx)
+}
+
+func (s *Supervisor) update(name string, fn func(st *TaskStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.status[name]; ok {
+		fn(st)
+	}
+}
+
+func (s *Supervisor) emit(event string, payload TaskEvent) {
+	if s.bus == nil {
+		return
+	}
+	s.bus.EmitAsync(context.Background(), event, payload)
+}
@@ -0,0 +1,15 @@
+package trace_provider
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// traceConfig trace.yml 配置结构
+type traceConfig struct {
+	Enable     bool    `default:"false" desc:"启用链路追踪，服务名取 app.name"`
+	SampleRate float64 `default:"1" desc:"根 span 采样比例 0~1，已有父 span 时沿用上游决定，支持热加载"`
+	OTLP       struct {
+		Endpoint string `desc:"OTLP gRPC 地址，如 127.0.0.1:4317"`
+		Insecure bool   `default:"true" desc:"不使用 TLS"`
+	} `config:"otlp" desc:"OTLP 导出"`
+}
+
+var _ = config_provider.RegisterSchema("trace", "链路追踪", traceConfig{})
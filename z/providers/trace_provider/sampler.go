@@ -0,0 +1,62 @@
+package trace_provider
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ratioSampler 按比例对根 span 采样，比例可运行时调整
+type ratioSampler struct {
+	bits atomic.Uint64
+}
+
+func newRatioSampler(rate float64) *ratioSampler {
+	s := &ratioSampler{}
+	s.set(rate)
+	return s
+}
+
+// set 设置采样比例，超出 [0, 1] 时取边界值
+func (s *ratioSampler) set(rate float64) {
+	if math.IsNaN(rate) || rate > 1 {
+		rate = 1
+	}
+	if rate < 0 {
+		rate = 0
+	}
+	s.bits.Store(math.Float64bits(rate))
+}
+
+func (s *ratioSampler) rate() float64 {
+	return math.Float64frombits(s.bits.Load())
+}
+
+func (s *ratioSampler) ShouldSample(p tracesdk.SamplingParameters) tracesdk.SamplingResult {
+	return tracesdk.TraceIDRatioBased(s.rate()).ShouldSample(p)
+}
+
+func (s *ratioSampler) Description() string {
+	return fmt.Sprintf("RatioSampler{%g}", s.rate())
+}
+
+// sampleRate 读取 trace.sample_rate，未配置时全部采样
+func sampleRate(cfg *config_provider.Config) float64 {
+	if !cfg.IsSet("trace.sample_rate") {
+		return 1
+	}
+	return cfg.GetFloat64("trace.sample_rate", 1)
+}
+
+// SampleRate 返回当前根 span 的采样比例
+func (t *Trace) SampleRate() float64 {
+	return t.sampler.rate()
+}
+
+// SetSampleRate 运行时调整根 span 的采样比例，已有父 span 的请求沿用上游的采样决定
+func (t *Trace) SetSampleRate(rate float64) {
+	t.sampler.set(rate)
+}
@@ -0,0 +1,130 @@
+package trace_provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 耗时分类
+const (
+	TimingAuth     = "auth"     // 认证，span 名称以 auth. 开头
+	TimingDB       = "db"       // 数据库，带 db.system 且不是 redis 的 span
+	TimingCache    = "cache"    // 缓存，db.system 为 redis 的 span
+	TimingUpstream = "upstream" // 上游 HTTP 调用，带 http 方法属性的客户端 span
+)
+
+// TimingPhase 一类依赖的耗时汇总，并发或嵌套的 span 会重复计入
+type TimingPhase struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Count    int           `json:"count"`
+}
+
+// Timings 一次请求内各类依赖的耗时
+type Timings struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases map[string]*TimingPhase
+}
+
+func (t *Timings) add(name string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.phases[name]
+	if !ok {
+		p = &TimingPhase{Name: name}
+		t.phases[name] = p
+	}
+	p.Duration += d
+	p.Count++
+}
+
+// Phases 返回已记录的分类耗时，按名称排序
+func (t *Timings) Phases() []TimingPhase {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]TimingPhase, 0, len(t.phases))
+	for _, p := range t.phases {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Elapsed 返回开始收集以来的耗时
+func (t *Timings) Elapsed() time.Duration {
+	return time.Since(t.start)
+}
+
+// timingProcessor 将已结束 span 的耗时按分类累加到所属 trace 的 Timings，只处理通过 TrackTimings 登记的 trace
+type timingProcessor struct {
+	active sync.Map // trace.TraceID -> *Timings
+}
+
+func (p *timingProcessor) OnStart(context.Context, tracesdk.ReadWriteSpan) {}
+
+func (p *timingProcessor) OnEnd(s tracesdk.ReadOnlySpan) {
+	v, ok := p.active.Load(s.SpanContext().TraceID())
+	if !ok {
+		return
+	}
+	if name := spanTimingCategory(s); name != "" {
+		v.(*Timings).add(name, s.EndTime().Sub(s.StartTime()))
+	}
+}
+
+func (p *timingProcessor) Shutdown(context.Context) error   { return nil }
+func (p *timingProcessor) ForceFlush(context.Context) error { return nil }
+
+// spanTimingCategory 按 span 名称、属性与类型确定耗时分类，无法归类时返回空
+func spanTimingCategory(s tracesdk.ReadOnlySpan) string {
+	if strings.HasPrefix(s.Name(), "auth.") {
+		return TimingAuth
+	}
+	var system string
+	var httpClient bool
+	for _, attr := range s.Attributes() {
+		switch attr.Key {
+		case "db.system":
+			system = attr.Value.AsString()
+		case "http.request.method", "http.method":
+			httpClient = s.SpanKind() == trace.SpanKindClient
+		}
+	}
+	switch {
+	case system == "redis":
+		return TimingCache
+	case system != "":
+		return TimingDB
+	case httpClient:
+		return TimingUpstream
+	}
+	return ""
+}
+
+// TrackTimings 开始收集 ctx 所在 trace 的依赖耗时，返回的 release 需在请求结束时调用
+// 仅在链路追踪启用且 ctx 带有采样中的 span 时生效，否则返回 nil
+func (t *Trace) TrackTimings(ctx context.Context) (*Timings, func()) {
+	if t == nil || t.TracerProvider == nil {
+		return nil, func() {}
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return nil, func() {}
+	}
+	t.timingOnce.Do(func() {
+		t.timing = &timingProcessor{}
+		t.TracerProvider.RegisterSpanProcessor(t.timing)
+	})
+
+	timings := &Timings{start: time.Now(), phases: map[string]*TimingPhase{}}
+	traceID := sc.TraceID()
+	t.timing.active.Store(traceID, timings)
+	return timings, func() { t.timing.active.Delete(traceID) }
+}
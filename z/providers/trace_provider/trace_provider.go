@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel/codes"
@@ -29,6 +30,10 @@ type Trace struct {
 	Tracer         trace.Tracer
 	serviceName    string
 	enabled        bool
+	sampler        *ratioSampler
+
+	timingOnce sync.Once
+	timing     *timingProcessor
 }
 
 // TraceProviderModule 链路追踪模块
@@ -38,7 +43,7 @@ var TraceProviderModule = fx.Options(
 
 // NewTraceProvider 创建链路追踪实例（fx Provider）
 func NewTraceProvider(lc fx.Lifecycle, cfg *config_provider.Config, log *logger_provider.Logger) (*Trace, error) {
-	tp := &Trace{}
+	tp := &Trace{sampler: newRatioSampler(sampleRate(cfg))}
 
 	tp.enabled = cfg.GetBool("trace.enable", false)
 	tp.serviceName = cfg.GetString("app.name", "")
@@ -49,6 +54,15 @@ func NewTraceProvider(lc fx.Lifecycle, cfg *config_provider.Config, log *logger_
 	endpoint := cfg.GetString("trace.otlp.endpoint", "")
 	insecure := cfg.GetBool("trace.otlp.insecure", true)
 
+	// 配置重载或运行时覆盖后调整采样比例
+	cfg.OnChange(func(event config_provider.ChangeEvent) {
+		if !event.Has("trace.sample_rate") {
+			return
+		}
+		tp.SetSampleRate(sampleRate(cfg))
+		log.Infow("trace sample rate changed", "sample_rate", tp.SampleRate())
+	})
+
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			if !tp.enabled {
@@ -73,6 +87,7 @@ func NewTraceProvider(lc fx.Lifecycle, cfg *config_provider.Config, log *logger_
 
 			sdkTP := tracesdk.NewTracerProvider(
 				tracesdk.WithBatcher(exporter),
+				tracesdk.WithSampler(tracesdk.ParentBased(tp.sampler)),
 				tracesdk.WithResource(resource.NewWithAttributes(
 					semconv.SchemaURL,
 					semconv.ServiceNameKey.String(tp.serviceName),
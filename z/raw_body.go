@@ -0,0 +1,20 @@
+package z
+
+import "github.com/gin-gonic/gin"
+
+// RawBodyKey gin.Context 中保存原始请求体的键
+const RawBodyKey = "z.raw_body"
+
+// RawBody 获取 RawBody 中间件缓存的原始请求体
+// 未启用中间件或请求体超过上限时返回 false
+func RawBody(c *gin.Context) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	v, ok := c.Get(RawBodyKey)
+	if !ok {
+		return nil, false
+	}
+	body, ok := v.([]byte)
+	return body, ok
+}
@@ -1,6 +1,7 @@
 package z
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -69,14 +70,29 @@ func response(responses []interface{}) (interface{}, int, int) {
 	return message, code, httpStatus
 }
 
-// Success 函数用于返回成功信息
+// Success 函数用于返回成功信息，输出前按 SetMaskRules 设置的规则脱敏
 func Success(c *gin.Context, responses ...interface{}) {
 	message, code, httpStatus := response(responses)
+	message, err := ApplyMask(c, message)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{Success: false, Message: "response masking failed", Code: int(StatusInternalError)})
+		return
+	}
 	c.JSON(httpStatus, Response{Success: true, Message: message, Code: code})
 }
 
 // Failure 函数用于返回失败信息
+// 经 Tracker 包装的错误会关联到当前请求的 span，便于通过 X-Trace-Id 定位堆栈，并在设置了 ErrorReporter 时上报
 func Failure(c *gin.Context, responses ...interface{}) {
+	if len(responses) > 0 && c.Request != nil {
+		if err, ok := responses[0].(error); ok {
+			var tracked *TrackedError
+			if errors.As(err, &tracked) {
+				attachSpan(c.Request.Context(), tracked)
+				ReportError(c.Request.Context(), err, c.Request)
+			}
+		}
+	}
 	message, code, httpStatus := response(responses)
 	c.JSON(httpStatus, Response{Success: false, Message: message, Code: code})
 }
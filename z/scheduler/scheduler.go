@@ -0,0 +1,552 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z"
+)
+
+var (
+	ErrTaskExists       = errors.New("scheduler: task already exists")
+	ErrTaskNotFound     = errors.New("scheduler: task not found")
+	ErrTriggerExpired   = errors.New("scheduler: trigger has no upcoming run")
+	ErrSchedulerStopped = errors.New("scheduler: stopped")
+)
+
+// DefaultHistorySize 每个任务默认保留的执行记录数
+const DefaultHistorySize = 20
+
+// Job 定时执行的任务函数，调度器停止或超过 TaskOptions.Timeout 时 ctx 被取消
+type Job func(ctx context.Context) error
+
+// Overlap 上一次执行尚未结束时再次到点的处理方式
+type Overlap string
+
+const (
+	OverlapSkip  Overlap = "skip"  // 跳过本次并记录为 skipped（默认）
+	OverlapAllow Overlap = "allow" // 并发执行
+	OverlapQueue Overlap = "queue" // 上一次结束后立即补执行，最多排队一次
+)
+
+// TaskOptions 任务选项
+type TaskOptions struct {
+	Location *time.Location // 计算 cron 触发时间的时区，默认 time.Local
+	Overlap  Overlap        // 重叠策略，默认 OverlapSkip
+	Timeout  time.Duration  // 单次执行超时，0 表示不限制
+	Paused   bool           // 注册后处于暂停状态，需调用 Resume 开始调度
+	// AllInstances 配置了 Options.Leader 时仍在每个实例执行，用于刷新本地缓存等实例级任务
+	AllInstances bool
+}
+
+// RunStatus 执行结果
+type RunStatus string
+
+const (
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+	RunSkipped   RunStatus = "skipped" // 因重叠策略跳过，或本实例不是 leader
+)
+
+// Run 单次执行记录
+type Run struct {
+	Scheduled time.Time     `json:"scheduled"`
+	Started   time.Time     `json:"started"`
+	Duration  time.Duration `json:"duration"`
+	Status    RunStatus     `json:"status"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// TaskInfo 任务状态
+type TaskInfo struct {
+	Name     string    `json:"name"`
+	Trigger  string    `json:"trigger"`
+	Location string    `json:"location"`
+	Overlap  Overlap   `json:"overlap"`
+	Paused   bool      `json:"paused"`
+	Running  int       `json:"running"`
+	Next     time.Time `json:"next,omitempty"` // 零值表示不再触发（一次性任务已执行）
+	Runs     int       `json:"runs"`
+	Failures int       `json:"failures"`
+	Skipped  int       `json:"skipped"`
+	LastRun  *Run      `json:"last_run,omitempty"`
+}
+
+// Logger 记录任务失败，*logger_provider.Logger 满足该接口
+type Logger interface {
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+// LeaderChecker 多副本部署时判断本实例是否为 leader，如 job_provider.JobScheduler
+type LeaderChecker interface {
+	IsLeader() bool
+}
+
+// Options 调度器选项
+type Options struct {
+	Clock       z.Clock // 时间来源，默认 z.SystemClock
+	HistorySize int     // 每个任务保留的执行记录数，默认 DefaultHistorySize
+	Logger      Logger  // 为空时不记录日志，失败仍会写入执行记录
+	// Leader 非空时到点的任务只在 leader 上执行，其他实例记录为 skipped；TaskOptions.AllInstances 的任务除外
+	Leader LeaderChecker
+}
+
+// Scheduler 进程内定时任务调度器，支持 cron 表达式、固定间隔和指定时间执行一次
+//
+//	s := scheduler.New()
+//	_ = s.Every("cache.warm", 5*time.Minute, warm)
+//	_ = s.Cron("report.archive", "0 3 * * *", archive, scheduler.TaskOptions{Location: shanghai})
+//	s.Start()
+//	defer s.Stop(context.Background())
+//
+// 多副本部署时每个实例都会执行，配置 Options.Leader 后只在 leader 上执行
+type Scheduler struct {
+	clock       z.Clock
+	historySize int
+	log         Logger
+	leader      LeaderChecker
+
+	mu      sync.Mutex
+	tasks   map[string]*task
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	stopped bool
+	loops   sync.WaitGroup
+	runs    sync.WaitGroup
+}
+
+// New 创建调度器，注册的任务在 Start 之后开始调度
+func New(opt ...Options) *Scheduler {
+	var o Options
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	if o.HistorySize <= 0 {
+		o.HistorySize = DefaultHistorySize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		clock:       z.ClockOr(o.Clock),
+		historySize: o.HistorySize,
+		log:         o.Logger,
+		leader:      o.Leader,
+		tasks:       map[string]*task{},
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Cron 按 cron 表达式注册任务
+func (s *Scheduler) Cron(name, spec string, job Job, opt ...TaskOptions) error {
+	trigger, err := Cron(spec)
+	if err != nil {
+		return err
+	}
+	return s.Schedule(name, trigger, job, opt...)
+}
+
+// Every 按固定间隔注册任务
+func (s *Scheduler) Every(name string, d time.Duration, job Job, opt ...TaskOptions) error {
+	if d <= 0 {
+		return fmt.Errorf("scheduler: invalid interval %s for %s", d, name)
+	}
+	return s.Schedule(name, Every(d), job, opt...)
+}
+
+// At 注册在指定时间执行一次的任务，时间已过时返回 ErrTriggerExpired
+func (s *Scheduler) At(name string, at time.Time, job Job, opt ...TaskOptions) error {
+	return s.Schedule(name, At(at), job, opt...)
+}
+
+// Schedule 按触发规则注册任务，任务名唯一
+func (s *Scheduler) Schedule(name string, trigger Trigger, job Job, opt ...TaskOptions) error {
+	name = strings.TrimSpace(name)
+	if name == "" || trigger == nil || job == nil {
+		return fmt.Errorf("scheduler: name, trigger and job are required")
+	}
+	var o TaskOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	switch o.Overlap {
+	case "":
+		o.Overlap = OverlapSkip
+	case OverlapSkip, OverlapAllow, OverlapQueue:
+	default:
+		return fmt.Errorf("scheduler: invalid overlap %q for %s", o.Overlap, name)
+	}
+	if o.Location == nil {
+		o.Location = time.Local
+	}
+
+	t := &task{
+		s:       s,
+		name:    name,
+		trigger: trigger,
+		job:     job,
+		opt:     o,
+		paused:  o.Paused,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+	t.next = trigger.Next(s.clock.Now().In(o.Location))
+	if t.next.IsZero() {
+		return fmt.Errorf("%w: %s (%s)", ErrTriggerExpired, name, trigger)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrSchedulerStopped
+	}
+	if _, ok := s.tasks[name]; ok {
+		return fmt.Errorf("%w: %s", ErrTaskExists, name)
+	}
+	s.tasks[name] = t
+	if s.started {
+		s.loops.Add(1)
+		go t.loop(s.ctx)
+	}
+	return nil
+}
+
+// Remove 移除任务，正在执行的不会被中断
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[name]
+	if ok {
+		delete(s.tasks, name)
+		close(t.stop)
+	}
+	return ok
+}
+
+// Pause 暂停调度，正在执行的不会被中断，暂停期间到点的触发不会补执行
+func (s *Scheduler) Pause(name string) error {
+	t, err := s.task(name)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.paused = true
+	t.mu.Unlock()
+	t.notify()
+	return nil
+}
+
+// Resume 恢复调度，从当前时间重新计算下一次触发；一次性任务在暂停期间到点时立即执行
+func (s *Scheduler) Resume(name string) error {
+	t, err := s.task(name)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	if t.paused {
+		t.paused = false
+		if next := t.trigger.Next(s.clock.Now().In(t.opt.Location)); !next.IsZero() {
+			t.next = next
+		}
+	}
+	t.mu.Unlock()
+	t.notify()
+	return nil
+}
+
+// RunNow 立即执行一次，同样遵循重叠策略，不影响原有的触发时间
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrSchedulerStopped
+	}
+	t, ok := s.tasks[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	t.fire(s.ctx, s.clock.Now())
+	return nil
+}
+
+// Task 返回任务状态
+func (s *Scheduler) Task(name string) (TaskInfo, bool) {
+	t, err := s.task(name)
+	if err != nil {
+		return TaskInfo{}, false
+	}
+	return t.info(), true
+}
+
+// Tasks 返回全部任务状态，按名称排序
+func (s *Scheduler) Tasks() []TaskInfo {
+	s.mu.Lock()
+	tasks := make([]*task, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		tasks = append(tasks, t)
+	}
+	s.mu.Unlock()
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].name < tasks[j].name })
+
+	infos := make([]TaskInfo, 0, len(tasks))
+	for _, t := range tasks {
+		infos = append(infos, t.info())
+	}
+	return infos
+}
+
+// History 返回任务最近的执行记录，按时间先后排列
+func (s *Scheduler) History(name string) ([]Run, error) {
+	t, err := s.task(name)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Run(nil), t.history...), nil
+}
+
+// Start 开始调度，返回任务数，重复调用无效
+func (s *Scheduler) Start() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return len(s.tasks)
+	}
+	s.started = true
+	for _, t := range s.tasks {
+		s.loops.Add(1)
+		go t.loop(s.ctx)
+	}
+	return len(s.tasks)
+}
+
+// Stop 停止调度并取消正在执行任务的 ctx，等待其退出直至 ctx 超时
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.cancel()
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) task(name string) (*task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	return t, nil
+}
+
+// task 已注册的任务
+type task struct {
+	s       *Scheduler
+	name    string
+	trigger Trigger
+	job     Job
+	opt     TaskOptions
+	wake    chan struct{}
+	stop    chan struct{}
+
+	mu       sync.Mutex
+	next     time.Time
+	paused   bool
+	running  int
+	queued   bool
+	queuedAt time.Time
+	runs     int
+	failures int
+	skipped  int
+	history  []Run
+}
+
+// notify 唤醒调度循环重新读取状态
+func (t *task) notify() {
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop 等待下一次触发；暂停时不设定时器，由 Resume 唤醒
+func (t *task) loop(ctx context.Context) {
+	defer t.s.loops.Done()
+	clock := t.s.clock
+	for {
+		t.mu.Lock()
+		next, paused := t.next, t.paused
+		t.mu.Unlock()
+		if next.IsZero() && !paused {
+			return
+		}
+
+		var timer z.ClockTimer
+		var fired <-chan time.Time
+		if !paused {
+			timer = clock.NewTimer(next.Sub(clock.Now()))
+			fired = timer.C()
+		}
+		select {
+		case <-ctx.Done():
+		case <-t.stop:
+		case <-t.wake:
+		case <-fired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.stop:
+			return
+		default:
+		}
+
+		t.mu.Lock()
+		// 等待期间被暂停或触发时间已变更
+		due := !t.paused && t.next.Equal(next) && !clock.Now().Before(next)
+		if due {
+			t.next = t.trigger.Next(clock.Now().In(t.opt.Location))
+		}
+		t.mu.Unlock()
+		if !due {
+			continue
+		}
+		if t.s.leader != nil && !t.opt.AllInstances && !t.s.leader.IsLeader() {
+			t.mu.Lock()
+			t.record(Run{Scheduled: next, Started: clock.Now(), Status: RunSkipped, Error: "not leader"})
+			t.mu.Unlock()
+			continue
+		}
+		t.fire(ctx, next)
+	}
+}
+
+// fire 按重叠策略执行一次
+func (t *task) fire(ctx context.Context, scheduled time.Time) {
+	t.mu.Lock()
+	if t.running > 0 {
+		switch t.opt.Overlap {
+		case OverlapSkip:
+			t.record(Run{Scheduled: scheduled, Started: t.s.clock.Now(), Status: RunSkipped})
+			t.mu.Unlock()
+			return
+		case OverlapQueue:
+			if !t.queued {
+				t.queued, t.queuedAt = true, scheduled
+			}
+			t.mu.Unlock()
+			return
+		}
+	}
+	t.running++
+	t.mu.Unlock()
+
+	t.s.runs.Add(1)
+	go t.run(ctx, scheduled)
+}
+
+func (t *task) run(ctx context.Context, scheduled time.Time) {
+	defer t.s.runs.Done()
+	for {
+		r := t.execute(ctx, scheduled)
+		t.mu.Lock()
+		t.record(r)
+		if t.queued && ctx.Err() == nil {
+			scheduled = t.queuedAt
+			t.queued = false
+			t.mu.Unlock()
+			continue
+		}
+		t.queued = false
+		t.running--
+		t.mu.Unlock()
+		return
+	}
+}
+
+func (t *task) execute(ctx context.Context, scheduled time.Time) (r Run) {
+	clock := t.s.clock
+	r = Run{Scheduled: scheduled, Started: clock.Now(), Status: RunSucceeded}
+	if t.opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.opt.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			r.Status, r.Error = RunFailed, fmt.Sprintf("panic: %v", p)
+			if t.s.log != nil {
+				t.s.log.Errorw("scheduler task panic", "task", t.name, "panic", p, "stack", string(debug.Stack()))
+			}
+		}
+		r.Duration = clock.Since(r.Started)
+	}()
+	if err := t.job(ctx); err != nil {
+		r.Status, r.Error = RunFailed, err.Error()
+		if t.s.log != nil {
+			t.s.log.Errorw("scheduler task failed", "task", t.name, "trigger", t.trigger.String(), "error", err)
+		}
+	}
+	return r
+}
+
+// record 写入执行记录，需在持有 t.mu 时调用
+func (t *task) record(r Run) {
+	switch r.Status {
+	case RunSkipped:
+		t.skipped++
+	case RunFailed:
+		t.runs++
+		t.failures++
+	default:
+		t.runs++
+	}
+	t.history = append(t.history, r)
+	if n := len(t.history) - t.s.historySize; n > 0 {
+		t.history = append(t.history[:0], t.history[n:]...)
+	}
+}
+
+func (t *task) info() TaskInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	info := TaskInfo{
+		Name:     t.name,
+		Trigger:  t.trigger.String(),
+		Location: t.opt.Location.String(),
+		Overlap:  t.opt.Overlap,
+		Paused:   t.paused,
+		Running:  t.running,
+		Next:     t.next,
+		Runs:     t.runs,
+		Failures: t.failures,
+		Skipped:  t.skipped,
+	}
+	if n := len(t.history); n > 0 {
+		last := t.history[n-1]
+		info.LastRun = &last
+	}
+	return info
+}
@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Trigger 触发规则，Next 返回 after 之后的下一次触发时间，返回零值表示不再触发
+type Trigger interface {
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// cronParser 支持可选的秒字段、CRON_TZ= 前缀及 @every 1m、@daily 等描述符
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+type cronTrigger struct {
+	spec     string
+	schedule cron.Schedule
+}
+
+// Cron 按 cron 表达式触发，触发时间按任务时区（TaskOptions.Location）计算
+//
+//	scheduler.Cron("0 3 * * *")          // 每天 03:00
+//	scheduler.Cron("*/10 * * * * *")     // 每 10 秒
+//	scheduler.Cron("@daily")
+func Cron(spec string) (Trigger, error) {
+	schedule, err := cronParser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: invalid cron spec %q: %w", spec, err)
+	}
+	return cronTrigger{spec: spec, schedule: schedule}, nil
+}
+
+func (t cronTrigger) Next(after time.Time) time.Time { return t.schedule.Next(after) }
+func (t cronTrigger) String() string                 { return "cron " + t.spec }
+
+type intervalTrigger struct {
+	every time.Duration
+}
+
+// Every 按固定间隔触发，间隔从上一次触发开始计算，d <= 0 时不会触发
+func Every(d time.Duration) Trigger {
+	return intervalTrigger{every: d}
+}
+
+func (t intervalTrigger) Next(after time.Time) time.Time {
+	if t.every <= 0 {
+		return time.Time{}
+	}
+	return after.Add(t.every)
+}
+
+func (t intervalTrigger) String() string { return "every " + t.every.String() }
+
+type onceTrigger struct {
+	at time.Time
+}
+
+// At 在指定时间执行一次
+func At(t time.Time) Trigger {
+	return onceTrigger{at: t}
+}
+
+func (t onceTrigger) Next(after time.Time) time.Time {
+	if after.Before(t.at) {
+		return t.at
+	}
+	return time.Time{}
+}
+
+func (t onceTrigger) String() string { return "at " + t.at.Format(time.RFC3339) }
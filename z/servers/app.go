@@ -8,7 +8,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/icreateapp-com/go-zLib/z/providers/event_bus_provider"
 	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
 )
 
 const (
@@ -23,6 +25,13 @@ type appRuntimeOptions struct {
 	singleInstanceLockPath string
 }
 
+// lifecycleBusIn 事件总线为可选依赖，未启用时不发布生命周期事件
+type lifecycleBusIn struct {
+	fx.In
+
+	Bus *event_bus_provider.EventBus `optional:"true"`
+}
+
 // WithSingleInstanceLock 启用单机单实例锁，避免同一台机器重复启动同一服务。
 func WithSingleInstanceLock(lockPath string) AppRuntimeOption {
 	return func(opts *appRuntimeOptions) {
@@ -48,7 +57,12 @@ func AppRun(options []fx.Option, runtimeOptions ...AppRuntimeOption) error {
 		}
 	}()
 
-	options = append(options, fx.NopLogger)
+	// 生命周期事件：app.starting / app.ready / app.stopping / provider.initialized
+	recorder := event_bus_provider.NewLifecycleRecorder()
+	options = append(options,
+		fx.WithLogger(func() fxevent.Logger { return recorder }),
+		fx.Invoke(func(in lifecycleBusIn) { recorder.Attach(in.Bus) }),
+	)
 
 	// 创建 fx.App
 	app := fx.New(options...)
@@ -56,9 +70,11 @@ func AppRun(options []fx.Option, runtimeOptions ...AppRuntimeOption) error {
 	// 启动应用
 	startCtx, startCancel := context.WithTimeout(context.Background(), defaultAppStartTimeout)
 	defer startCancel()
+	recorder.Starting(startCtx)
 	if err := app.Start(startCtx); err != nil {
 		return err
 	}
+	recorder.Ready(context.Background())
 
 	// 等待信号
 	sigs := make(chan os.Signal, 1)
@@ -68,6 +84,7 @@ func AppRun(options []fx.Option, runtimeOptions ...AppRuntimeOption) error {
 	// 停止应用
 	stopCtx, stopCancel := context.WithTimeout(context.Background(), defaultAppStopTimeout)
 	defer stopCancel()
+	recorder.Stopping(stopCtx)
 	stopErr := app.Stop(stopCtx)
 	if stopErr != nil {
 		if errors.Is(stopErr, context.DeadlineExceeded) || errors.Is(stopErr, context.Canceled) {
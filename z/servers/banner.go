@@ -0,0 +1,71 @@
+package servers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"go.uber.org/fx"
+)
+
+// PrintStartupBanner 启动时输出应用信息与生效配置摘要，敏感配置项已脱敏
+func PrintStartupBanner(lc fx.Lifecycle, cfg *config_provider.Config, log *logger_provider.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			summary := cfg.Summary()
+			fmt.Println(formatStartupBanner(summary))
+			log.Infow("startup config summary",
+				"name", summary.Name,
+				"debug", summary.Debug,
+				"path", summary.Path,
+				"namespaces", summary.Namespaces,
+				"addresses", summary.Addresses,
+				"runtime", summary.Runtime,
+				"settings", summary.Settings,
+			)
+			return nil
+		},
+	})
+}
+
+// formatStartupBanner 格式化启动横幅
+func formatStartupBanner(summary config_provider.Summary) string {
+	var b strings.Builder
+	line := strings.Repeat("=", 60)
+
+	b.WriteString(line + "\n")
+	b.WriteString(fmt.Sprintf(" %s\n", summary.Name))
+	b.WriteString(line + "\n")
+	b.WriteString(fmt.Sprintf(" %-12s %v\n", "debug", summary.Debug))
+	b.WriteString(fmt.Sprintf(" %-12s %s\n", "config", summary.Path))
+	b.WriteString(fmt.Sprintf(" %-12s %s\n", "namespaces", strings.Join(summary.Namespaces, ", ")))
+
+	keys := make([]string, 0, len(summary.Addresses))
+	for k := range summary.Addresses {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(fmt.Sprintf(" %-12s %s\n", k, summary.Addresses[k]))
+	}
+	for _, k := range []string{"go_version", "pid", "hostname", "started_at"} {
+		b.WriteString(fmt.Sprintf(" %-12s %v\n", k, summary.Runtime[k]))
+	}
+
+	if settings, err := json.MarshalIndent(summary.Settings, " ", "  "); err == nil {
+		b.WriteString(line + "\n")
+		b.WriteString(" " + string(settings) + "\n")
+	}
+	b.WriteString(line)
+
+	return b.String()
+}
+
+// StartupBannerModule 启动横幅模块
+var StartupBannerModule = fx.Options(
+	fx.Invoke(PrintStartupBanner),
+)
@@ -0,0 +1,65 @@
+package http_server
+
+import (
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// httpConfig http.yml 配置结构
+type httpConfig struct {
+	Host      string `desc:"监听地址，为空时监听全部网卡"`
+	Port      int    `required:"true" desc:"监听端口"`
+	StaticDir string `desc:"静态文件目录"`
+	IDMaskKey string `config:"id_mask_key" desc:"ID 混淆密钥，为空时不混淆"`
+	AppInfo   bool   `default:"false" desc:"开放 /.well-known/app-info 配置摘要（已脱敏）"`
+	Bind      struct {
+		MaxBodySize     int64 `default:"4194304" desc:"请求体最大字节数"`
+		MaxDepth        int   `default:"32" desc:"JSON 最大嵌套层数"`
+		MaxArrayLength  int   `default:"10000" desc:"单个数组最大元素数"`
+		MaxNumberLength int   `default:"64" desc:"单个数字最大字符数"`
+	} `desc:"JSON 请求体绑定限制"`
+	Masking struct {
+		Rules []interface{} `desc:"响应脱敏规则，参见 z.MaskRule"`
+	} `desc:"响应脱敏"`
+	Routes struct {
+		Check  bool   `default:"true" desc:"启动时检查路由冲突"`
+		Strict bool   `default:"false" desc:"存在路由冲突时启动失败"`
+		Expose bool   `default:"false" desc:"开放路由清单"`
+		Path   string `default:"/.well-known/routes" desc:"路由清单路径"`
+	} `desc:"路由"`
+	Readiness struct {
+		Wait     bool          `default:"false" desc:"等待依赖就绪后再监听"`
+		Timeout  time.Duration `default:"10s" desc:"等待超时"`
+		Interval time.Duration `default:"500ms" desc:"检查间隔"`
+		Required []string      `desc:"必需的就绪检查，为空时全部必需"`
+	} `desc:"就绪检查"`
+	Locale struct {
+		Default   string   `desc:"默认语言，如 zh-CN"`
+		Timezone  string   `desc:"默认时区，如 Asia/Shanghai"`
+		Currency  string   `desc:"默认货币，如 CNY"`
+		Supported []string `desc:"支持的语言，为空时不限制"`
+	} `desc:"区域设置"`
+	DebugTiming struct {
+		Enable  bool                   `default:"false" desc:"启用请求耗时明细（Server-Timing），支持热加载"`
+		Header  string                 `default:"X-Debug-Timing" desc:"触发明细输出的请求头"`
+		Guards  []string               `desc:"非调试模式下允许查看明细的认证守卫"`
+		Budgets map[string]interface{} `desc:"耗时预算，如 total: 300ms、db: 100ms，超出时标注"`
+	} `desc:"请求耗时调试"`
+	Overrides struct {
+		Expose bool          `default:"false" desc:"开放运行时配置覆盖接口，需配置 guard"`
+		Path   string        `default:"/.well-known/overrides" desc:"覆盖接口路径"`
+		Guard  string        `desc:"允许访问覆盖接口的认证守卫"`
+		TTL    time.Duration `config:"ttl" default:"30m" desc:"未指定有效期时的覆盖时长"`
+		MaxTTL time.Duration `config:"max_ttl" default:"24h" desc:"覆盖时长上限"`
+		Keys   []string      `desc:"允许覆盖的配置项，默认 logger.level、app.debug、db.sql_log、db.slow_query_ms、trace.sample_rate、http.debug_timing.enable"`
+	} `desc:"运行时配置覆盖"`
+	NodeAgent struct {
+		Enabled bool          `default:"false" desc:"启用节点代理，聚合同机进程的健康检查与指标"`
+		Timeout time.Duration `default:"2s" desc:"单个进程的请求超时"`
+		Label   string        `default:"instance" desc:"写入指标的进程标签名"`
+		Targets []interface{} `desc:"同机进程，如 { name: api-2, url: \"http://127.0.0.1:8082\", health_path: /readyz, metrics_path: /metrics, optional: false }"`
+	} `desc:"节点代理"`
+}
+
+var _ = config_provider.RegisterSchema("http", "HTTP 服务", httpConfig{})
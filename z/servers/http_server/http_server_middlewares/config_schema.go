@@ -0,0 +1,16 @@
+package http_server_middlewares
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// corsSchema cors.yml 配置结构
+type corsSchema struct {
+	Enabled          bool     `default:"false" desc:"启用跨域"`
+	AllowOrigins     []string `desc:"允许的来源，* 表示全部"`
+	AllowMethods     []string `desc:"允许的方法"`
+	AllowHeaders     []string `desc:"允许的请求头"`
+	ExposeHeaders    []string `desc:"允许读取的响应头"`
+	AllowCredentials bool     `default:"false" desc:"允许携带凭证"`
+	MaxAge           int      `desc:"预检结果缓存时长（秒）"`
+}
+
+var _ = config_provider.RegisterSchema("cors", "跨域", corsSchema{})
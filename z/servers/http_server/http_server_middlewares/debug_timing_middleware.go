@@ -0,0 +1,117 @@
+package http_server_middlewares
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/trace_provider"
+)
+
+// DebugTimingMiddleware 请求头 X-Debug-Timing: 1 时汇总本次请求的认证、数据库、缓存、上游调用耗时，通过 Server-Timing 响应头返回
+// 耗时取自链路追踪 span，需启用 trace；仅对 http.debug_timing.guards 中的 guard 输出，未配置时仅 app.debug 下输出
+// 需紧跟 TraceChainMiddleware 注册，才能覆盖后续中间件产生的 span；enable 与 app.debug 按请求读取，支持运行时覆盖
+//
+//	http:
+//	  debug_timing:
+//	    enable: true
+//	    guards: [admin]
+//	    budgets:         # 耗时预算，超出时在 Server-Timing 中标注
+//	      total: 300ms
+//	      db: 100ms
+func DebugTimingMiddleware(tp *trace_provider.Trace, cfg *config_provider.Config) gin.HandlerFunc {
+	header := cfg.GetString("http.debug_timing.header")
+	if header == "" {
+		header = "X-Debug-Timing"
+	}
+	guards := cfg.GetStringSlice("http.debug_timing.guards")
+	budgets := map[string]time.Duration{}
+	for name := range cfg.GetStringMap("http.debug_timing.budgets") {
+		if d := cfg.GetDuration("http.debug_timing.budgets."+name, 0); d > 0 {
+			budgets[name] = d
+		}
+	}
+
+	allowed := func(c *gin.Context) bool {
+		if len(guards) == 0 {
+			return cfg.GetBool("app.debug", true)
+		}
+		return z.InStringSlice(guards, c.GetString("auth.guard"))
+	}
+
+	return func(c *gin.Context) {
+		if tp == nil || c.GetHeader(header) != "1" || !cfg.GetBool("http.debug_timing.enable", false) {
+			c.Next()
+			return
+		}
+		timings, release := tp.TrackTimings(c.Request.Context())
+		if timings == nil {
+			c.Next()
+			return
+		}
+		defer release()
+
+		// 认证在处理器之前完成，首次写出响应时再判断权限并写入响应头
+		tw := &timingWriter{ResponseWriter: c.Writer}
+		tw.inject = func() {
+			if allowed(c) {
+				tw.Header().Set("Server-Timing", serverTiming(timings, budgets))
+			}
+		}
+		c.Writer = tw
+		c.Next()
+		tw.once.Do(tw.inject)
+	}
+}
+
+// serverTiming 按 Server-Timing 格式输出各分类耗时（毫秒），超出预算时在 desc 中标注
+func serverTiming(timings *trace_provider.Timings, budgets map[string]time.Duration) string {
+	metric := func(name string, d time.Duration, count int) string {
+		s := fmt.Sprintf("%s;dur=%.2f", name, float64(d.Microseconds())/1000)
+		var desc []string
+		if count > 0 {
+			desc = append(desc, fmt.Sprintf("%d spans", count))
+		}
+		if budget, ok := budgets[name]; ok && d > budget {
+			desc = append(desc, "over budget "+budget.String())
+		}
+		if len(desc) > 0 {
+			s += `;desc="` + strings.Join(desc, ", ") + `"`
+		}
+		return s
+	}
+
+	phases := timings.Phases()
+	parts := make([]string, 0, len(phases)+1)
+	for _, p := range phases {
+		parts = append(parts, metric(p.Name, p.Duration, p.Count))
+	}
+	parts = append(parts, metric("total", timings.Elapsed(), 0))
+	return strings.Join(parts, ", ")
+}
+
+// timingWriter 在响应头写出前调用 inject
+type timingWriter struct {
+	gin.ResponseWriter
+	once   sync.Once
+	inject func()
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.once.Do(w.inject)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	w.once.Do(w.inject)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.once.Do(w.inject)
+	return w.ResponseWriter.WriteString(s)
+}
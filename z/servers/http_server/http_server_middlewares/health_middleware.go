@@ -14,9 +14,11 @@ import (
 
 func RecoveryMiddleware(log *logger_provider.Logger) gin.HandlerFunc {
 	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered interface{}) {
+		stack := debug.Stack()
 		if log != nil {
-			log.Errorw("panic recovered", "recovered", recovered, "stack", string(debug.Stack()))
+			log.Errorw("panic recovered", "recovered", recovered, "stack", string(stack))
 		}
+		z.ReportPanic(c.Request.Context(), recovered, stack, c.Request)
 		z.Failure(c, "Internal Server Error", 500)
 		c.Abort()
 	})
@@ -43,6 +45,11 @@ func HealthMiddleware(cfg *config_provider.Config) gin.HandlerFunc {
 			})
 			c.Abort()
 		}
+		// 配置摘要仅在显式开启 http.app_info 时对外提供
+		if c.Request.URL.Path == "/.well-known/app-info" && cfg.GetBool("http.app_info") {
+			z.Success(c, cfg.Summary())
+			c.Abort()
+		}
 
 		c.Next()
 	}
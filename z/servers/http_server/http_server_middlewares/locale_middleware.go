@@ -0,0 +1,173 @@
+package http_server_middlewares
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"go.uber.org/fx"
+)
+
+// 区域设置请求头
+const (
+	HeaderLocale   = "X-Locale"
+	HeaderTimezone = "X-Timezone"
+	HeaderCurrency = "X-Currency"
+)
+
+// LocaleResolver 读取用户保存的区域设置（如用户资料中的语言、时区），优先级低于请求头、高于 Accept-Language
+// 返回的空字段继续按后续来源解析；需要登录信息时应在认证中间件之后执行
+type LocaleResolver func(c *gin.Context) (z.LocaleInfo, bool)
+
+type LocaleMiddlewareIn struct {
+	fx.In
+
+	Config   *config_provider.Config
+	Resolver LocaleResolver `optional:"true"`
+}
+
+type localeConfig struct {
+	supported []string
+}
+
+// localeConfigFrom 读取 http.locale 配置并设置全局默认区域
+//
+//	http:
+//	  locale:
+//	    default: zh-CN
+//	    timezone: Asia/Shanghai
+//	    currency: CNY
+//	    supported: [zh-CN, en]   # 为空时不限制
+func localeConfigFrom(cfg *config_provider.Config) localeConfig {
+	def := z.LocaleInfo{
+		Locale:   strings.TrimSpace(cfg.GetString("http.locale.default")),
+		Currency: strings.TrimSpace(cfg.GetString("http.locale.currency")),
+	}
+	if tz := strings.TrimSpace(cfg.GetString("http.locale.timezone")); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			def.Timezone = loc
+		}
+	}
+	z.SetDefaultLocale(def)
+	return localeConfig{supported: cfg.GetStringSlice("http.locale.supported")}
+}
+
+// LocaleMiddleware 解析请求的语言、时区、货币并写入 request context，下游通过 z.LocaleFrom(ctx) 读取
+// 优先级：X-Locale / X-Timezone / X-Currency 请求头 > LocaleResolver > Accept-Language > http.locale 默认值
+func LocaleMiddleware(in LocaleMiddlewareIn) gin.HandlerFunc {
+	conf := localeConfigFrom(in.Config)
+	return func(c *gin.Context) {
+		var info z.LocaleInfo
+		info.Locale = conf.match(c.GetHeader(HeaderLocale))
+		info.Timezone = parseTimezone(c.GetHeader(HeaderTimezone))
+		info.Currency = parseCurrency(c.GetHeader(HeaderCurrency))
+
+		if in.Resolver != nil && (info.Locale == "" || info.Timezone == nil || info.Currency == "") {
+			if user, ok := in.Resolver(c); ok {
+				if info.Locale == "" {
+					info.Locale = conf.match(user.Locale)
+				}
+				if info.Timezone == nil {
+					info.Timezone = user.Timezone
+				}
+				if info.Currency == "" {
+					info.Currency = parseCurrency(user.Currency)
+				}
+			}
+		}
+		if info.Locale == "" {
+			info.Locale = conf.acceptLanguage(c.GetHeader("Accept-Language"))
+		}
+
+		ctx := z.WithLocale(c.Request.Context(), info)
+		c.Request = c.Request.WithContext(ctx)
+		resolved := z.LocaleFrom(ctx)
+		c.Set("locale", resolved)
+		c.Header("Content-Language", resolved.Locale)
+
+		c.Next()
+	}
+}
+
+// match 返回支持列表中匹配的语言，未配置支持列表时原样返回
+func (conf localeConfig) match(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if locale == "" || len(conf.supported) == 0 {
+		return locale
+	}
+	lang := z.LocaleInfo{Locale: locale}.Language()
+	partial := ""
+	for _, s := range conf.supported {
+		if strings.EqualFold(strings.ReplaceAll(s, "_", "-"), strings.ReplaceAll(locale, "_", "-")) {
+			return s
+		}
+		if partial == "" && (z.LocaleInfo{Locale: s}).Language() == lang {
+			partial = s
+		}
+	}
+	return partial
+}
+
+// acceptLanguage 按 q 值从高到低返回第一个支持的语言
+func (conf localeConfig) acceptLanguage(header string) string {
+	best, bestQ := "", -1.0
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(f), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= bestQ || q <= 0 {
+			continue
+		}
+		if matched := conf.match(tag); matched != "" {
+			best, bestQ = matched, q
+		}
+	}
+	return best
+}
+
+func parseTimezone(name string) *time.Location {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+func parseCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return ""
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return ""
+		}
+	}
+	return code
+}
+
+var LocaleMiddlewareModule = fx.Options(
+	fx.Provide(
+		fx.Annotate(
+			LocaleMiddleware,
+			fx.ResultTags(`group:"http_middlewares"`),
+		),
+	),
+)
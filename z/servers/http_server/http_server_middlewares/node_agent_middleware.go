@@ -0,0 +1,96 @@
+package http_server_middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"go.uber.org/fx"
+)
+
+// 节点代理路径
+const (
+	NodeHealthPath  = "/.well-known/node/health"
+	NodeMetricsPath = "/.well-known/node/metrics"
+)
+
+// NodeAgentIn 节点代理依赖，Discovery 用于从注册中心等动态发现同机进程
+type NodeAgentIn struct {
+	fx.In
+
+	Config    *config_provider.Config
+	Log       *logger_provider.Logger
+	Readiness *z.Readiness    `optional:"true"`
+	Discovery z.NodeDiscovery `optional:"true"`
+}
+
+// NodeAgentMiddleware 节点代理：由一个进程聚合同机其他进程的健康检查与指标
+//
+//	http:
+//	  node_agent:
+//	    enabled: true
+//	    timeout: 2s          # 单个进程的请求超时
+//	    label: instance      # 写入指标的进程标签名
+//	    targets:
+//	      - { name: api-2, url: "http://127.0.0.1:8082" }
+//	      - { name: worker, url: "http://127.0.0.1:9100", health_path: /healthz, optional: true }
+//
+// /.well-known/node/health 返回本进程及各进程的状态，任一必需进程不可用时返回 503；
+// /.well-known/node/metrics 返回合并后的 Prometheus 文本格式指标
+func NodeAgentMiddleware(in NodeAgentIn) (gin.HandlerFunc, error) {
+	if !in.Config.GetBool("http.node_agent.enabled", false) {
+		return func(c *gin.Context) { c.Next() }, nil
+	}
+	var targets []z.NodeTarget
+	if raw, ok := in.Config.GetStringMap("http.node_agent")["targets"]; ok && raw != nil {
+		if err := z.ToStruct(raw, &targets); err != nil {
+			return nil, err
+		}
+	}
+	agent := z.NewNodeAgent(targets, z.NodeAgentOptions{
+		Timeout:   in.Config.GetDuration("http.node_agent.timeout", 0),
+		Label:     in.Config.GetString("http.node_agent.label"),
+		Discovery: in.Discovery,
+	})
+
+	return func(c *gin.Context) {
+		switch c.Request.URL.Path {
+		case NodeHealthPath:
+			health, err := agent.Health(c.Request.Context())
+			self, selfErr := in.Readiness.Check()
+			data := map[string]interface{}{
+				"status":    health.Status,
+				"timestamp": health.Timestamp,
+				"self":      self,
+				"nodes":     health.Nodes,
+			}
+			if err != nil || selfErr != nil {
+				data["status"] = "DOWN"
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, z.Response{Success: false, Message: data, Code: http.StatusServiceUnavailable})
+				return
+			}
+			z.Success(c, data)
+			c.Abort()
+		case NodeMetricsPath:
+			c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			c.Status(http.StatusOK)
+			if err := agent.Metrics(c.Request.Context(), c.Writer); err != nil {
+				in.Log.Warnw("node agent metrics failed", "error", err)
+			}
+			c.Abort()
+		default:
+			c.Next()
+		}
+	}, nil
+}
+
+var NodeAgentMiddlewareModule = fx.Options(
+	fx.Provide(
+		fx.Annotate(
+			NodeAgentMiddleware,
+			fx.ResultTags(`group:"http_middlewares"`),
+		),
+	),
+)
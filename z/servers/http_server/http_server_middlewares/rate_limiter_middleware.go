@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/rate_limiter_provider"
 	"github.com/ulule/limiter/v3"
 	limitergingw "github.com/ulule/limiter/v3/drivers/middleware/gin"
@@ -109,6 +110,8 @@ func rateLimiterRateFromStrategyOrDefault(st rate_limiter_provider.Strategy, def
 }
 
 // RateLimiterMiddleware 创建限流 Gin 中间件。
+// 未命中策略的请求若带有租户（baggage 中的 tenant_id）且配置了租户套餐，按套餐速率限流并通过 X-RateLimit-Plan 返回套餐名
+// 租户取自认证写入的 baggage，需在 AuthMiddlewareModule 之后注册
 func RateLimiterMiddleware(p *rate_limiter_provider.RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if p == nil || !p.Enabled() {
@@ -144,6 +147,27 @@ func RateLimiterMiddleware(p *rate_limiter_provider.RateLimiter) gin.HandlerFunc
 			return
 		}
 
+		// 租户套餐：按租户整体计数，取代默认速率
+		if tenantID := z.BaggageValue(c.Request.Context(), z.BaggageTenantID); tenantID != "" {
+			if rate, plan, ok := p.TenantRate(tenantID); ok {
+				c.Header("X-RateLimit-Plan", plan)
+				mw := limitergingw.NewMiddleware(
+					limiter.New(p.Store(), rate),
+					limitergingw.WithKeyGetter(func(c *gin.Context) string {
+						return "tenant:" + tenantID
+					}),
+					limitergingw.WithLimitReachedHandler(func(c *gin.Context) {
+						c.AbortWithStatusJSON(http.StatusTooManyRequests, map[string]interface{}{
+							"error":   "RATE_LIMITED",
+							"message": "TOO_MANY_REQUESTS",
+						})
+					}),
+				)
+				mw(c)
+				return
+			}
+		}
+
 		// default
 		mw := limitergingw.NewMiddleware(
 			p.Limiter(),
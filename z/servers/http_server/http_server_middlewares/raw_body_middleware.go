@@ -0,0 +1,53 @@
+package http_server_middlewares
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+)
+
+// defaultRawBodyLimit 默认缓存的请求体上限
+const defaultRawBodyLimit = 1 << 20
+
+type rawBodyReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// RawBody 缓存原始请求体，绑定后仍可通过 z.RawBody(c) 读取
+// 请求体超过 limit（<= 0 时为 1MB）时不缓存，但保持请求体完整可读
+func RawBody(limit int64) gin.HandlerFunc {
+	if limit <= 0 {
+		limit = defaultRawBodyLimit
+	}
+	return func(c *gin.Context) {
+		body := c.Request.Body
+		if body == nil || body == http.NoBody || c.Request.ContentLength > limit {
+			c.Next()
+			return
+		}
+
+		buf, err := io.ReadAll(io.LimitReader(body, limit+1))
+		if err != nil {
+			_ = body.Close()
+			z.Failure(c, "failed to read request body", z.StatusBadRequest)
+			c.Abort()
+			return
+		}
+
+		if int64(len(buf)) > limit {
+			// 超过上限：拼回已读取部分，剩余内容继续以流的方式读取
+			c.Request.Body = rawBodyReadCloser{Reader: io.MultiReader(bytes.NewReader(buf), body), Closer: body}
+			c.Next()
+			return
+		}
+
+		_ = body.Close()
+		c.Set(z.RawBodyKey, buf)
+		c.Request.Body = io.NopCloser(bytes.NewReader(buf))
+		c.Next()
+	}
+}
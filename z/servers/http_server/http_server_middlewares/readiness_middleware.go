@@ -0,0 +1,63 @@
+package http_server_middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"go.uber.org/fx"
+)
+
+// ReadinessIn provider 模块通过 group:"ready_checks" 注册的就绪检查
+type ReadinessIn struct {
+	fx.In
+
+	Config *config_provider.Config
+	Checks []z.ReadyCheck `group:"ready_checks"`
+}
+
+// NewReadiness 汇总已启用 provider 的就绪检查
+//
+//	http:
+//	  readiness:
+//	    required: [db, redis]  # 必需依赖，为空时除 config 外的已启用依赖均为必需
+//	    wait: true             # 启动时等待必需依赖就绪后再监听端口
+//	    timeout: 10s           # 等待超时，超时后启动失败
+//	    interval: 500ms        # 重试间隔
+func NewReadiness(in ReadinessIn) *z.Readiness {
+	return z.NewReadiness(in.Checks, in.Config.GetStringSlice("http.readiness.required"))
+}
+
+// ReadinessMiddleware 就绪探针：/.well-known/ready 与 /readyz，必需依赖未就绪时返回 503
+func ReadinessMiddleware(readiness *z.Readiness) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path != "/.well-known/ready" && c.Request.URL.Path != "/readyz" {
+			c.Next()
+			return
+		}
+		statuses, err := readiness.Check()
+		data := map[string]interface{}{
+			"status":    "UP",
+			"timestamp": time.Now().Unix(),
+			"checks":    statuses,
+		}
+		if err != nil {
+			data["status"] = "DOWN"
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, z.Response{Success: false, Message: data, Code: http.StatusServiceUnavailable})
+			return
+		}
+		z.Success(c, data)
+		c.Abort()
+	}
+}
+
+var ReadinessMiddlewareModule = fx.Options(
+	fx.Provide(
+		fx.Annotate(
+			ReadinessMiddleware,
+			fx.ResultTags(`group:"http_middlewares"`),
+		),
+	),
+)
@@ -0,0 +1,161 @@
+package http_server_middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+)
+
+// Timeout 为路由设置处理超时时间
+// 截止时间通过 c.Request.Context() 传递给下游 DB/HTTP 调用；超时后立即返回 504，
+// 处理协程的后续写入会被丢弃，中间件会等待处理协程结束后再返回，避免 gin.Context 被复用
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		origin := c.Writer
+		tw := &timeoutWriter{ResponseWriter: origin, header: make(http.Header), status: http.StatusOK}
+		c.Writer = tw
+
+		done := make(chan struct{})
+		panicChan := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicChan <- p
+				}
+				close(done)
+			}()
+			c.Next()
+		}()
+
+		select {
+		case <-done:
+			select {
+			case p := <-panicChan:
+				c.Writer = origin
+				panic(p)
+			default:
+			}
+			tw.flush()
+			c.Writer = origin
+		case <-ctx.Done():
+			tw.timeout()
+			<-done
+			c.Writer = origin
+			c.Abort()
+		}
+	}
+}
+
+// timeoutWriter 缓存处理器输出，超时后丢弃所有写入
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	size     int
+	written  bool
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut || w.written {
+		return
+	}
+	w.status = code
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = true
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	n, err := w.body.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.written {
+		return -1
+	}
+	return w.size
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
+}
+
+// Flush 缓存模式下不支持流式输出，忽略
+func (w *timeoutWriter) Flush() {}
+
+// flush 将缓存的响应写入原始 writer
+func (w *timeoutWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	dst := w.ResponseWriter.Header()
+	for k, vs := range w.header {
+		dst[k] = vs
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
+
+// timeout 标记超时并直接输出 504 响应
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+
+	body, _ := json.Marshal(z.Response{Success: false, Message: "Gateway Timeout", Code: int(z.StatusGatewayTimeout)})
+	dst := w.ResponseWriter.Header()
+	dst.Set("Content-Type", "application/json; charset=utf-8")
+	dst.Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	_, _ = w.ResponseWriter.Write(body)
+	w.ResponseWriter.Flush()
+}
@@ -5,9 +5,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/icreateapp-com/go-zLib/z"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/trace_provider"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
@@ -23,8 +23,10 @@ func TraceChainMiddleware(tp *trace_provider.Trace, log *logger_provider.Logger)
 			return
 		}
 
-		propagator := otel.GetTextMapPropagator()
+		propagator := z.TextMapPropagator()
 		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		// 身份 baggage 由认证中间件写入，客户端传入的值不可信
+		ctx = z.StripIdentityBaggage(ctx)
 
 		spanName := c.Request.Method + " " + c.FullPath()
 		ctx, span := tp.Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindServer))
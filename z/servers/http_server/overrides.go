@@ -0,0 +1,124 @@
+package http_server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/auth_provider"
+)
+
+// defaultOverrideKeys 未配置 http.overrides.keys 时允许运行时覆盖的配置项
+var defaultOverrideKeys = []string{
+	"logger.level",
+	"app.debug",
+	"db.sql_log",
+	"db.slow_query_ms",
+	"trace.sample_rate",
+	"http.debug_timing.enable",
+}
+
+// overrideRequest 设置覆盖项的请求体，ttl 为空时使用 http.overrides.ttl
+type overrideRequest struct {
+	Key   string      `json:"key" binding:"required"`
+	Value interface{} `json:"value"`
+	TTL   string      `json:"ttl"`
+}
+
+// registerOverrideRoutes 注册运行时配置覆盖接口，用于排查线上问题时临时调整日志级别、SQL 日志、调试输出及采样比例
+// 覆盖项到期后自动恢复，必须配置 guard 并启用 auth_provider
+//
+//	http:
+//	  overrides:
+//	    expose: true
+//	    path: /.well-known/overrides
+//	    guard: admin
+//	    ttl: 30m          # 未指定 ttl 时的有效期
+//	    max_ttl: 24h
+//	    keys: [logger.level, app.debug, db.sql_log, db.slow_query_ms, trace.sample_rate]
+//
+//	GET    {path}                                           当前覆盖项
+//	PUT    {path}  {"key":"logger.level","value":"debug","ttl":"15m"}
+//	DELETE {path}?key=logger.level                          移除覆盖项，不带 key 时移除全部
+func registerOverrideRoutes(in RoutesIn) error {
+	cfg := in.Cfg
+	guard := cfg.GetString("http.overrides.guard")
+	if guard == "" {
+		return errors.New("http.overrides.expose requires http.overrides.guard")
+	}
+	if in.Auth == nil {
+		return errors.New("http.overrides.expose requires auth provider")
+	}
+	path := cfg.GetString("http.overrides.path")
+	if path == "" {
+		path = "/.well-known/overrides"
+	}
+	keys := cfg.GetStringSlice("http.overrides.keys")
+	if len(keys) == 0 {
+		keys = defaultOverrideKeys
+	}
+	defaultTTL := cfg.GetDuration("http.overrides.ttl")
+	if defaultTTL <= 0 {
+		defaultTTL = 30 * time.Minute
+	}
+	maxTTL := cfg.GetDuration("http.overrides.max_ttl")
+	if maxTTL <= 0 {
+		maxTTL = 24 * time.Hour
+	}
+
+	list := func(c *gin.Context) {
+		z.Success(c, map[string]interface{}{
+			"overrides": cfg.Overrides(),
+			"keys":      keys,
+		})
+	}
+
+	g := in.Engine.Group(path, auth_provider.RequireGuard(in.Auth, guard))
+	g.GET("", list)
+	g.PUT("", func(c *gin.Context) {
+		var req overrideRequest
+		if err := z.BindJSON(c, &req); err != nil {
+			z.Failure(c, err, z.StatusBadRequest, http.StatusBadRequest)
+			return
+		}
+		if !z.InStringSlice(keys, req.Key) {
+			z.Failure(c, "config key not allowed: "+req.Key, z.StatusForbidden, http.StatusForbidden)
+			return
+		}
+		ttl := defaultTTL
+		if req.TTL != "" {
+			d, err := time.ParseDuration(req.TTL)
+			if err != nil || d <= 0 {
+				z.Failure(c, "invalid ttl: "+req.TTL, z.StatusBadRequest, http.StatusBadRequest)
+				return
+			}
+			ttl = d
+		}
+		if ttl > maxTTL {
+			ttl = maxTTL
+		}
+		if _, err := cfg.SetOverride(req.Key, req.Value, ttl); err != nil {
+			z.Failure(c, err, z.StatusUnprocessableEntity, http.StatusUnprocessableEntity)
+			return
+		}
+		in.Log.Warnw("config override set", "key", req.Key, "value", req.Value, "ttl", ttl.String(), "ip", c.ClientIP())
+		list(c)
+	})
+	g.DELETE("", func(c *gin.Context) {
+		var err error
+		if key := c.Query("key"); key != "" {
+			_, err = cfg.ClearOverride(key)
+		} else {
+			_, err = cfg.ClearOverrides()
+		}
+		if err != nil {
+			z.Failure(c, err, z.StatusInternalError, http.StatusInternalServerError)
+			return
+		}
+		in.Log.Warnw("config override cleared", "key", c.Query("key"), "ip", c.ClientIP())
+		list(c)
+	})
+	return nil
+}
@@ -7,9 +7,13 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/static"
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/auth_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/trace_provider"
@@ -36,6 +40,9 @@ type RoutesIn struct {
 
 	Engine *gin.Engine
 	Routes []RouteRegister `group:"routes"`
+	Cfg    *config_provider.Config
+	Log    *logger_provider.Logger
+	Auth   *auth_provider.Auth `optional:"true"`
 }
 
 type RouteRegister func(r *gin.Engine)
@@ -45,6 +52,38 @@ func NewHttpServer(in HttpMiddlewaresIn, tpIn TraceProviderIn, cfg *config_provi
 	if !cfg.GetBool("app.debug", true) {
 		gin.SetMode(gin.ReleaseMode)
 	}
+	cfg.OnChange(func(event config_provider.ChangeEvent) {
+		if !event.Has("app.debug") {
+			return
+		}
+		if cfg.GetBool("app.debug", true) {
+			gin.SetMode(gin.DebugMode)
+		} else {
+			gin.SetMode(gin.ReleaseMode)
+		}
+	})
+
+	// id masking for public APIs
+	if key := strings.TrimSpace(cfg.GetString("http.id_mask_key")); key != "" {
+		masker, err := z.NewIDMasker(key)
+		if err != nil {
+			return nil, err
+		}
+		z.SetIDMasker(masker)
+	}
+
+	// response masking rules
+	if err := loadMaskRules(cfg); err != nil {
+		return nil, err
+	}
+
+	// request body binding limits
+	z.SetBindLimits(z.BindLimits{
+		MaxBodySize:     cfg.GetInt64("http.bind.max_body_size"),
+		MaxDepth:        cfg.GetInt("http.bind.max_depth"),
+		MaxArrayLength:  cfg.GetInt("http.bind.max_array_length"),
+		MaxNumberLength: cfg.GetInt("http.bind.max_number_length"),
+	})
 
 	// instance engine
 	r := gin.New()
@@ -53,9 +92,13 @@ func NewHttpServer(in HttpMiddlewaresIn, tpIn TraceProviderIn, cfg *config_provi
 	r.Use(gin.Logger())
 	if tpIn.TraceProvider != nil {
 		r.Use(http_server_middlewares.TraceChainMiddleware(tpIn.TraceProvider, log))
+		r.Use(http_server_middlewares.DebugTimingMiddleware(tpIn.TraceProvider, cfg))
 		r.Use(http_server_middlewares.RecoveryMiddleware(log))
 	} else {
-		r.Use(gin.Recovery())
+		r.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+			z.ReportPanic(c.Request.Context(), recovered, debug.Stack(), c.Request)
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}))
 	}
 
 	// injected middlewares
@@ -85,13 +128,81 @@ func NewHttpServer(in HttpMiddlewaresIn, tpIn TraceProviderIn, cfg *config_provi
 	return r, nil
 }
 
-func RegisterRoutes(in RoutesIn) {
+// loadMaskRules 读取 http.masking.rules 响应脱敏规则
+func loadMaskRules(cfg *config_provider.Config) error {
+	raw, ok := cfg.GetStringMap("http.masking")["rules"]
+	if !ok || raw == nil {
+		return nil
+	}
+	var rules []z.MaskRule
+	if err := z.ToStruct(raw, &rules); err != nil {
+		return fmt.Errorf("invalid http.masking.rules: %w", err)
+	}
+	z.SetMaskRules(rules)
+	return nil
+}
+
+// reservedPaths 由内置中间件直接响应的路径，注册同名路由不会生效
+var reservedPaths = []string{
+	"/.well-known/alive",
+	"/.well-known/health",
+	"/.well-known/app-info",
+	"/.well-known/ready",
+	"/readyz",
+	"/.well-known/jwks.json",
+	"/.well-known/node/health",
+	"/.well-known/node/metrics",
+}
+
+// RegisterRoutes 注册各模块路由并检查冲突
+//
+//	http:
+//	  routes:
+//	    check: true                   # 启动时检查遮蔽、末尾斜杠及保留路径冲突并告警
+//	    strict: false                 # 存在冲突时启动失败
+//	    expose: false                 # 开启后通过 path 返回路由列表、认证要求及权限映射（?guard= 按 guard 过滤）
+//	    path: /.well-known/routes
+func RegisterRoutes(in RoutesIn) error {
 	for _, register := range in.Routes {
 		register(in.Engine)
 	}
+
+	if in.Cfg.GetBool("http.routes.expose", false) {
+		path := in.Cfg.GetString("http.routes.path")
+		if path == "" {
+			path = "/.well-known/routes"
+		}
+		in.Engine.GET(path, func(c *gin.Context) {
+			routes := z.Routes(in.Engine)
+			z.Success(c, map[string]interface{}{
+				"routes":      routes,
+				"conflicts":   z.CheckRoutes(routes, reservedPaths...),
+				"permissions": z.PermissionMap(routes, c.Query("guard")),
+			})
+		})
+	}
+
+	if in.Cfg.GetBool("http.overrides.expose", false) {
+		if err := registerOverrideRoutes(in); err != nil {
+			return err
+		}
+	}
+
+	// 默认开启，仅显式配置 check: false 时跳过
+	if in.Cfg.IsSet("http.routes.check") && !in.Cfg.GetBool("http.routes.check") {
+		return nil
+	}
+	conflicts := z.CheckRoutes(z.Routes(in.Engine), reservedPaths...)
+	for _, conflict := range conflicts {
+		in.Log.Warnw("http route conflict", "kind", conflict.Kind, "method", conflict.Method, "path", conflict.Path, "other", conflict.Other, "handler", conflict.Handler)
+	}
+	if len(conflicts) > 0 && in.Cfg.GetBool("http.routes.strict", false) {
+		return fmt.Errorf("%d http route conflicts found, first: %s", len(conflicts), conflicts[0].Message)
+	}
+	return nil
 }
 
-func RegisterHTTPServer(lc fx.Lifecycle, r *gin.Engine, cfg *config_provider.Config, log *logger_provider.Logger) {
+func RegisterHTTPServer(lc fx.Lifecycle, r *gin.Engine, readiness *z.Readiness, cfg *config_provider.Config, log *logger_provider.Logger) {
 	srv := &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", cfg.GetString("http.host"), cfg.GetInt("http.port")),
 		Handler: r,
@@ -99,6 +210,23 @@ func RegisterHTTPServer(lc fx.Lifecycle, r *gin.Engine, cfg *config_provider.Con
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
+			// 等待必需依赖就绪后再监听，避免请求先于 redis 等连接到达
+			if cfg.GetBool("http.readiness.wait", false) {
+				timeout := cfg.GetDuration("http.readiness.timeout")
+				if timeout <= 0 {
+					timeout = 10 * time.Second
+				}
+				interval := cfg.GetDuration("http.readiness.interval")
+				if interval <= 0 {
+					interval = 500 * time.Millisecond
+				}
+				waitCtx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				if err := readiness.Wait(waitCtx, interval); err != nil {
+					log.Errorw("http server dependencies not ready", "error", err)
+					return err
+				}
+			}
 			log.Infow("start http server", "addr", srv.Addr)
 			go srv.ListenAndServe()
 			return nil
@@ -117,6 +245,7 @@ func RegisterHTTPServer(lc fx.Lifecycle, r *gin.Engine, cfg *config_provider.Con
 
 var HttpServerModule = fx.Options(
 	fx.Provide(NewHttpServer),
+	fx.Provide(http_server_middlewares.NewReadiness),
 	fx.Invoke(RegisterHTTPServer),
 	fx.Invoke(RegisterRoutes),
 )
@@ -0,0 +1,14 @@
+package tcp_server
+
+import "github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+
+// tcpConfig tcp.yml 配置结构
+type tcpConfig struct {
+	Host            string `desc:"监听地址"`
+	Port            int    `desc:"监听端口，未配置时不启动 TCP 服务"`
+	MaxFrameSize    int    `default:"4194304" desc:"单帧最大字节数"`
+	IdleTimeoutSec  int    `config:"idle_timeout_sec" desc:"连接空闲超时（秒），0 不超时"`
+	WriteTimeoutSec int    `config:"write_timeout_sec" default:"10" desc:"写超时（秒）"`
+}
+
+var _ = config_provider.RegisterSchema("tcp", "TCP 服务", tcpConfig{})
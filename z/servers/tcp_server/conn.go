@@ -0,0 +1,125 @@
+package tcp_server
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options 连接选项
+type Options struct {
+	Codec        Codec         // 消息编解码，默认 JSON
+	MaxFrameSize int           // 单帧最大长度，默认 4MB
+	IdleTimeout  time.Duration // 读空闲超时，0 表示不限制
+	WriteTimeout time.Duration // 写超时，0 表示不限制
+}
+
+func (o Options) withDefaults() Options {
+	if o.Codec == nil {
+		o.Codec = JSONCodec{}
+	}
+	if o.MaxFrameSize <= 0 {
+		o.MaxFrameSize = DefaultMaxFrameSize
+	}
+	return o
+}
+
+// Conn 分帧连接，读操作需在单个 goroutine 中进行，写操作并发安全
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	opts   Options
+
+	writeMu sync.Mutex
+	values  sync.Map
+	closing atomic.Bool
+}
+
+// NewConn 包装 net.Conn
+func NewConn(conn net.Conn, opts Options) *Conn {
+	return &Conn{conn: conn, reader: bufio.NewReader(conn), opts: opts.withDefaults()}
+}
+
+// Dial 连接 TCP 服务
+func Dial(ctx context.Context, addr string, opts Options) (*Conn, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(conn, opts), nil
+}
+
+// ReadFrame 读取一帧原始消息
+func (c *Conn) ReadFrame() ([]byte, error) {
+	if c.closing.Load() {
+		return nil, net.ErrClosed
+	}
+	if c.opts.IdleTimeout > 0 {
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.opts.IdleTimeout)); err != nil {
+			return nil, err
+		}
+	}
+	return ReadFrame(c.reader, c.opts.MaxFrameSize)
+}
+
+// WriteFrame 写入一帧原始消息
+func (c *Conn) WriteFrame(payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.opts.WriteTimeout > 0 {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.opts.WriteTimeout)); err != nil {
+			return err
+		}
+	}
+	return WriteFrame(c.conn, payload, c.opts.MaxFrameSize)
+}
+
+// Receive 读取一帧并解码到 v
+func (c *Conn) Receive(v interface{}) error {
+	payload, err := c.ReadFrame()
+	if err != nil {
+		return err
+	}
+	return c.opts.Codec.Decode(payload, v)
+}
+
+// Send 编码 v 并写入一帧
+func (c *Conn) Send(v interface{}) error {
+	payload, err := c.opts.Codec.Encode(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteFrame(payload)
+}
+
+// Set 保存连接级数据，如设备 ID
+func (c *Conn) Set(key string, value interface{}) {
+	c.values.Store(key, value)
+}
+
+// Get 读取连接级数据
+func (c *Conn) Get(key string) (interface{}, bool) {
+	return c.values.Load(key)
+}
+
+// RemoteAddr 返回对端地址
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// NetConn 返回底层连接
+func (c *Conn) NetConn() net.Conn {
+	return c.conn
+}
+
+// Close 关闭连接
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,96 @@
+package tcp_server
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/goccy/go-json"
+)
+
+// DefaultMaxFrameSize 默认单帧最大长度（4MB）
+const DefaultMaxFrameSize = 4 << 20
+
+// frameHeaderSize 帧头长度，4 字节大端序表示消息体长度
+const frameHeaderSize = 4
+
+// ErrFrameTooLarge 帧长度超过限制
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// ReadFrame 读取一帧消息，maxSize <= 0 时使用 DefaultMaxFrameSize
+func ReadFrame(r io.Reader, maxSize int) ([]byte, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if uint64(size) > uint64(maxSize) {
+		return nil, ErrFrameTooLarge
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+// WriteFrame 写入一帧消息，帧头与消息体合并为一次写入
+func WriteFrame(w io.Writer, payload []byte, maxSize int) error {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	if len(payload) > maxSize {
+		return ErrFrameTooLarge
+	}
+	buf := make([]byte, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf, uint32(len(payload)))
+	copy(buf[frameHeaderSize:], payload)
+	_, err := w.Write(buf)
+	return err
+}
+
+// Codec 消息编解码
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(data []byte, v interface{}) error
+}
+
+// JSONCodec JSON 编解码
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Decode(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// RawCodec 原始字节编解码，仅支持 []byte 与 *[]byte
+type RawCodec struct{}
+
+func (RawCodec) Encode(v interface{}) ([]byte, error) {
+	switch b := v.(type) {
+	case []byte:
+		return b, nil
+	case *[]byte:
+		return *b, nil
+	default:
+		return nil, errors.New("raw codec: value must be []byte")
+	}
+}
+
+func (RawCodec) Decode(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return errors.New("raw codec: target must be *[]byte")
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
@@ -0,0 +1,265 @@
+package tcp_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"go.uber.org/fx"
+)
+
+// ErrServerClosed 服务已关闭
+var ErrServerClosed = errors.New("tcp server closed")
+
+// Handler 连接处理函数，返回后连接被关闭；服务关闭时 ctx 被取消，阻塞中的读操作会立即返回
+type Handler func(ctx context.Context, conn *Conn) error
+
+// Server 分帧 TCP 服务
+type Server struct {
+	addr    string
+	handler Handler
+	opts    Options
+	log     *logger_provider.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[*Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer 创建 TCP 服务
+func NewServer(addr string, handler Handler, opts Options, log *logger_provider.Logger) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		addr:    addr,
+		handler: handler,
+		opts:    opts.withDefaults(),
+		log:     log,
+		ctx:     ctx,
+		cancel:  cancel,
+		conns:   map[*Conn]struct{}{},
+	}
+}
+
+// Addr 返回监听地址，启动后为实际地址
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.addr
+}
+
+// Listen 监听地址，与 Serve 分开以便启动阶段即可发现端口占用
+func (s *Server) Listen() (net.Listener, error) {
+	return net.Listen("tcp", s.addr)
+}
+
+// ListenAndServe 监听并处理连接，直到服务关闭
+func (s *Server) ListenAndServe() error {
+	ln, err := s.Listen()
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve 在 ln 上接受连接，服务关闭后返回 ErrServerClosed
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = ln.Close()
+		return ErrServerClosed
+	}
+	s.listener = ln
+	s.mu.Unlock()
+
+	var backoff time.Duration
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				if backoff == 0 {
+					backoff = 5 * time.Millisecond
+				} else if backoff < time.Second {
+					backoff *= 2
+				}
+				time.Sleep(backoff)
+				continue
+			}
+			return err
+		}
+		backoff = 0
+
+		conn := NewConn(nc, s.opts)
+		if !s.track(conn) {
+			_ = nc.Close()
+			return ErrServerClosed
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Shutdown 优雅关闭：停止接受新连接并通知处理函数退出，ctx 到期后强制关闭剩余连接
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	ln := s.listener
+	conns := make([]*Conn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.mu.Unlock()
+
+	var err error
+	if ln != nil {
+		if closeErr := ln.Close(); closeErr != nil && !errors.Is(closeErr, net.ErrClosed) {
+			err = closeErr
+		}
+	}
+	s.cancel()
+	// 唤醒阻塞在读操作上的处理函数
+	for _, conn := range conns {
+		conn.closing.Store(true)
+		_ = conn.conn.SetReadDeadline(time.Now())
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		s.mu.Lock()
+		for conn := range s.conns {
+			_ = conn.Close()
+		}
+		s.mu.Unlock()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Server) track(conn *Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *Server) serveConn(conn *Conn) {
+	defer func() {
+		if r := recover(); r != nil && s.log != nil {
+			s.log.Errorw("tcp handler panic", "remote", conn.RemoteAddr().String(), "panic", fmt.Sprint(r))
+		}
+		_ = conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	err := s.handler(s.ctx, conn)
+	if err != nil && s.log != nil && !isConnClosedError(err) && s.ctx.Err() == nil {
+		s.log.Warnw("tcp handler error", "remote", conn.RemoteAddr().String(), "error", err)
+	}
+}
+
+// isConnClosedError 对端断开、连接关闭等正常结束的错误
+func isConnClosedError(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed)
+}
+
+type In struct {
+	fx.In
+
+	LC      fx.Lifecycle
+	Cfg     *config_provider.Config
+	Log     *logger_provider.Logger
+	Handler Handler `optional:"true"`
+	Codec   Codec   `optional:"true"`
+}
+
+// NewTCPServer 创建 TCP 服务（fx Provider），未配置 tcp.port 或未注入 Handler 时不启动，返回 nil
+func NewTCPServer(in In) (*Server, error) {
+	port := in.Cfg.GetInt("tcp.port")
+	if port <= 0 || in.Handler == nil {
+		return nil, nil
+	}
+
+	// 写超时为 0 时慢客户端会无限期阻塞写入，未配置或非正数时使用默认值
+	writeTimeout := in.Cfg.GetInt("tcp.write_timeout_sec", 10)
+	if writeTimeout <= 0 {
+		writeTimeout = 10
+	}
+
+	opts := Options{
+		Codec:        in.Codec,
+		MaxFrameSize: in.Cfg.GetInt("tcp.max_frame_size", DefaultMaxFrameSize),
+		IdleTimeout:  time.Duration(in.Cfg.GetInt("tcp.idle_timeout_sec")) * time.Second,
+		WriteTimeout: time.Duration(writeTimeout) * time.Second,
+	}
+	s := NewServer(fmt.Sprintf("%s:%d", in.Cfg.GetString("tcp.host"), port), in.Handler, opts, in.Log)
+
+	in.LC.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ln, err := s.Listen()
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := s.Serve(ln); err != nil && !errors.Is(err, ErrServerClosed) {
+					in.Log.Errorw("tcp server stopped unexpectedly", "addr", s.Addr(), "error", err)
+				}
+			}()
+			in.Log.Infow("start tcp server", "addr", s.Addr())
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			in.Log.Infow("stopping tcp server", "addr", s.Addr())
+			if err := s.Shutdown(ctx); err != nil {
+				in.Log.Errorw("tcp server stop failed", "addr", s.Addr(), "error", err)
+				return err
+			}
+			in.Log.Infow("tcp server stopped", "addr", s.Addr())
+			return nil
+		},
+	})
+
+	return s, nil
+}
+
+var TCPServerModule = fx.Options(
+	fx.Provide(NewTCPServer),
+	fx.Invoke(func(*Server) {}),
+)
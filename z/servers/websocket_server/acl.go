@@ -0,0 +1,147 @@
+package websocket_server
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/olahol/melody"
+)
+
+// EventSubscribeRejected 订阅被拒绝时推送给客户端的事件
+const EventSubscribeRejected = "ws.subscribe.rejected"
+
+// 订阅拒绝原因
+const (
+	RejectForbidden       = "forbidden"         // 无权订阅
+	RejectChannelFull     = "channel_full"      // 频道订阅数已满
+	RejectTooManyChannels = "too_many_channels" // 连接订阅频道数已达上限
+	RejectNotConnected    = "not_connected"     // 连接未注册
+)
+
+// WSChannelAuthorizer 订阅前校验连接（clientID 为连接 ID）能否加入频道，返回错误时拒绝
+// 连接的 guard、user_id 可通过 ChannelSessionMeta(ctx) 获取
+type WSChannelAuthorizer func(ctx context.Context, clientID, channel string) error
+
+// ChannelRule 频道访问规则，按配置顺序匹配，命中第一条生效
+type ChannelRule struct {
+	Channel        string   `json:"channel"`         // 频道名或 path.Match 模式，如 order:*
+	Guards         []string `json:"guards"`          // 允许订阅的 guard，为空时不限制
+	MaxSubscribers int      `json:"max_subscribers"` // 单个频道的订阅连接数上限，<= 0 不限制
+	Deny           bool     `json:"deny"`            // 禁止客户端订阅，仅服务端可通过 Hub.Subscribe 加入
+}
+
+// SubscribeRejected ws.subscribe.rejected 事件数据
+type SubscribeRejected struct {
+	Channel string `json:"channel"`
+	Reason  string `json:"reason"`
+}
+
+type channelMetaKey struct{}
+
+// ChannelSessionMeta 获取正在订阅的连接信息，仅在 WSChannelAuthorizer 中可用
+func ChannelSessionMeta(ctx context.Context) *SessionMeta {
+	meta, _ := ctx.Value(channelMetaKey{}).(*SessionMeta)
+	return meta
+}
+
+// channelACL 客户端订阅频道的访问控制
+type channelACL struct {
+	rules       []ChannelRule
+	defaultDeny bool
+	maxChannels int
+	authorize   WSChannelAuthorizer
+	legacy      WSSubscribeAuthorizer
+}
+
+// loadChannelACL 读取 websocket.acl 配置
+//
+//	websocket:
+//	  acl:
+//	    default: deny             # 未命中规则时的策略，allow（默认）/ deny
+//	    max_channels_per_conn: 50
+//	    rules:
+//	      - { channel: "admin:*", guards: [admin] }
+//	      - { channel: "order:*", guards: [user, admin], max_subscribers: 1000 }
+func loadChannelACL(cfg *config_provider.Config) (*channelACL, error) {
+	acl := &channelACL{
+		defaultDeny: strings.EqualFold(strings.TrimSpace(cfg.GetString("websocket.acl.default", "allow")), "deny"),
+		maxChannels: cfg.GetInt("websocket.acl.max_channels_per_conn", 0),
+	}
+	raw, ok := cfg.GetStringMap("websocket.acl")["rules"]
+	if !ok || raw == nil {
+		return acl, nil
+	}
+	if err := z.ToStruct(raw, &acl.rules); err != nil {
+		return nil, fmt.Errorf("invalid websocket.acl.rules: %w", err)
+	}
+	for _, rule := range acl.rules {
+		if _, err := path.Match(rule.Channel, ""); err != nil || rule.Channel == "" {
+			return nil, fmt.Errorf("invalid websocket.acl.rules channel: %q", rule.Channel)
+		}
+	}
+	return acl, nil
+}
+
+// match 返回频道命中的规则
+func (a *channelACL) match(channel string) *ChannelRule {
+	for i := range a.rules {
+		if ok, _ := path.Match(a.rules[i].Channel, channel); ok {
+			return &a.rules[i]
+		}
+	}
+	return nil
+}
+
+// check 校验连接能否订阅频道，返回拒绝原因和频道订阅数上限
+func (a *channelACL) check(ms *melody.Session, meta *SessionMeta, channel string) (string, int) {
+	maxSubscribers := 0
+	rule := a.match(channel)
+	switch {
+	case rule != nil:
+		if rule.Deny || (len(rule.Guards) > 0 && !z.InStringSlice(rule.Guards, meta.Guard)) {
+			return RejectForbidden, 0
+		}
+		maxSubscribers = rule.MaxSubscribers
+	case a.defaultDeny:
+		return RejectForbidden, 0
+	}
+
+	if a.legacy != nil && !a.legacy(ms, channel) {
+		return RejectForbidden, 0
+	}
+	if a.authorize != nil {
+		ctx := context.WithValue(context.Background(), channelMetaKey{}, meta)
+		if err := a.authorize(ctx, meta.ConnID, channel); err != nil {
+			return RejectForbidden, 0
+		}
+	}
+	return "", maxSubscribers
+}
+
+// subscribe 按访问控制订阅频道，返回被拒绝的频道
+func (a *channelACL) subscribe(hub *Hub, ms *melody.Session, channels []string) []SubscribeRejected {
+	meta := hub.GetMeta(ms)
+	if meta == nil {
+		return nil
+	}
+
+	var rejected []SubscribeRejected
+	for _, ch := range channels {
+		ch = strings.TrimSpace(ch)
+		if ch == "" {
+			continue
+		}
+		reason, maxSubscribers := a.check(ms, meta, ch)
+		if reason == "" {
+			reason = hub.TrySubscribe(ms, ch, maxSubscribers, a.maxChannels)
+		}
+		if reason != "" {
+			rejected = append(rejected, SubscribeRejected{Channel: ch, Reason: reason})
+		}
+	}
+	return rejected
+}
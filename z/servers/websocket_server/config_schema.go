@@ -0,0 +1,57 @@
+package websocket_server
+
+import (
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+)
+
+// websocketConfig websocket.yml 配置结构
+type websocketConfig struct {
+	Mode               string                 `default:"gin" desc:"运行模式，目前仅支持 gin（挂载到 HTTP 服务）"`
+	Path               string                 `default:"/ws" desc:"连接路径"`
+	MaxMessageSize     int64                  `default:"512" desc:"入站消息上限（字节），超出时丢弃该消息"`
+	HardMaxMessageSize int64                  `desc:"超出时断开连接（字节），默认为各上限最大值的 4 倍"`
+	SendBuffer         int                    `default:"256" desc:"每个连接的出站缓冲条数"`
+	Guards             map[string]interface{} `desc:"按认证守卫覆盖 max_message_size、compression"`
+	Compression        struct {
+		Enabled bool `default:"false" desc:"协商 permessage-deflate"`
+		Level   int  `default:"1" desc:"压缩级别 -2 ~ 9"`
+	} `desc:"消息压缩"`
+	Dedup struct {
+		Enabled bool `default:"false" desc:"丢弃客户端重试导致的重复消息"`
+		Size    int  `default:"128" desc:"每个连接记录的消息 ID 数"`
+		TTLSec  int  `config:"ttl_sec" default:"60" desc:"消息 ID 保留时长（秒）"`
+	} `desc:"消息去重"`
+	RateLimit struct {
+		Action     string `default:"warn" desc:"超出限制时的处理：drop / warn（丢弃并推送 ws.error）/ disconnect"`
+		Connection struct {
+			MessagesPerSec float64 `desc:"每秒消息数，0 不限制"`
+			BytesPerSec    float64 `desc:"每秒字节数，0 不限制"`
+		} `desc:"单个连接的入站速率"`
+		Channel struct {
+			MessagesPerSec float64 `desc:"每秒消息数，0 不限制"`
+			BytesPerSec    float64 `desc:"每秒字节数，0 不限制"`
+		} `desc:"单个连接向每个频道发送的速率，频道默认取 data.channel"`
+	} `desc:"入站消息限流"`
+	Heartbeat struct {
+		IntervalSec int    `default:"20" desc:"心跳检查间隔（秒）"`
+		TimeoutSec  int    `default:"60" desc:"心跳超时（秒），超时断开"`
+		EventName   string `default:"ws.heartbeat" desc:"心跳事件名"`
+	} `desc:"心跳"`
+	ACL struct {
+		Default            string        `default:"allow" desc:"未命中规则时的策略：allow / deny"`
+		MaxChannelsPerConn int           `desc:"每个连接最多订阅的频道数，0 不限制"`
+		Rules              []interface{} `desc:"频道规则，如 { channel: \"admin:*\", guards: [admin] }"`
+	} `config:"acl" desc:"频道订阅访问控制"`
+	Notifications struct {
+		TTL        time.Duration          `config:"ttl" default:"720h" desc:"通知保留时间"`
+		MaxPerUser int                    `default:"200" desc:"每个用户保留的通知数"`
+		Templates  map[string]interface{} `desc:"通知模板，按名称配置 title、body、action、required"`
+	} `desc:"离线通知"`
+	ModelEvents struct {
+		Rooms map[string]interface{} `desc:"推送的表及房间前缀，表名: 房间前缀"`
+	} `desc:"模型变更推送"`
+}
+
+var _ = config_provider.RegisterSchema("websocket", "WebSocket 服务", websocketConfig{})
@@ -0,0 +1,96 @@
+package websocket_server
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olahol/melody"
+)
+
+const dedupSessionKey = "ws_dedup"
+
+// WSDedupKeyFunc 自定义消息去重键，返回空字符串表示该消息不参与去重
+type WSDedupKeyFunc func(ms *melody.Session, raw []byte) string
+
+// DefaultDedupKey 默认以消息信封 ID 作为去重键
+func DefaultDedupKey(ms *melody.Session, raw []byte) string {
+	var env struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(env.ID)
+}
+
+// MessageDedup 单连接最近消息记录（环形缓冲 + TTL）
+type MessageDedup struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	ring []dedupEntry
+	pos  int
+	seen map[string]time.Time
+}
+
+type dedupEntry struct {
+	key string
+	at  time.Time
+}
+
+// NewMessageDedup 创建去重记录，size 为最多记录的消息数，ttl <= 0 表示仅按容量淘汰
+func NewMessageDedup(size int, ttl time.Duration) *MessageDedup {
+	if size <= 0 {
+		size = 128
+	}
+	return &MessageDedup{
+		ttl:  ttl,
+		ring: make([]dedupEntry, size),
+		seen: make(map[string]time.Time, size),
+	}
+}
+
+// Seen 判断 key 是否在窗口内出现过，未出现时记录并返回 false
+func (d *MessageDedup) Seen(key string) bool {
+	if key == "" {
+		return false
+	}
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if at, ok := d.seen[key]; ok {
+		if d.ttl <= 0 || now.Sub(at) < d.ttl {
+			return true
+		}
+	}
+
+	// 仅当记录仍指向被淘汰的槽位时才删除，避免误删过期后重新记录的同名 key
+	if old := d.ring[d.pos]; old.key != "" && d.seen[old.key].Equal(old.at) {
+		delete(d.seen, old.key)
+	}
+	d.ring[d.pos] = dedupEntry{key: key, at: now}
+	d.pos = (d.pos + 1) % len(d.ring)
+	d.seen[key] = now
+	return false
+}
+
+// isDuplicateMessage 检查连接上是否已处理过相同消息
+func isDuplicateMessage(ms *melody.Session, raw []byte, keyFunc WSDedupKeyFunc, size int, ttl time.Duration) bool {
+	key := keyFunc(ms, raw)
+	if key == "" {
+		return false
+	}
+
+	var dedup *MessageDedup
+	if v, ok := ms.Get(dedupSessionKey); ok {
+		dedup, _ = v.(*MessageDedup)
+	}
+	if dedup == nil {
+		dedup = NewMessageDedup(size, ttl)
+		ms.Set(dedupSessionKey, dedup)
+	}
+	return dedup.Seen(key)
+}
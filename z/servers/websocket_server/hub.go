@@ -138,6 +138,45 @@ func (h *Hub) Subscribe(s *melody.Session, channels []string) {
 	}
 }
 
+// TrySubscribe 在不超过限制时订阅单个频道，返回拒绝原因，成功或已订阅时返回空
+// maxSubscribers 为频道订阅连接数上限，maxChannels 为单连接订阅频道数上限，<= 0 不限制
+func (h *Hub) TrySubscribe(s *melody.Session, channel string, maxSubscribers, maxChannels int) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m := h.meta[s]
+	if m == nil {
+		return RejectNotConnected
+	}
+	if _, ok := m.Channels[channel]; ok {
+		return ""
+	}
+	if maxChannels > 0 && len(m.Channels) >= maxChannels {
+		return RejectTooManyChannels
+	}
+	if maxSubscribers > 0 && len(h.byChan[channel]) >= maxSubscribers {
+		return RejectChannelFull
+	}
+
+	m.Channels[channel] = struct{}{}
+	if _, ok := h.byChan[channel]; !ok {
+		h.byChan[channel] = map[string]struct{}{}
+	}
+	h.byChan[channel][m.ConnID] = struct{}{}
+	return ""
+}
+
+// MetaByConnID 按连接 ID 获取连接信息
+func (h *Hub) MetaByConnID(connID string) *SessionMeta {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	s, ok := h.byConnID[connID]
+	if !ok {
+		return nil
+	}
+	return h.meta[s]
+}
+
 func (h *Hub) Unsubscribe(s *melody.Session, channels []string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
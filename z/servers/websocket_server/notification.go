@@ -0,0 +1,330 @@
+package websocket_server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/redis_provider"
+	"github.com/olahol/melody"
+	"go.uber.org/fx"
+)
+
+// 站内通知事件
+const (
+	EventNotification     = "ws.notification"      // 服务端推送通知
+	EventNotificationSync = "ws.notification.sync" // 客户端拉取未读通知，服务端以同名事件返回列表
+	EventNotificationRead = "ws.notification.read" // 客户端上报已读，服务端以同名事件返回已标记的 ID
+)
+
+// ErrNotificationTemplate 通知模板不存在或载荷缺少必填字段
+var ErrNotificationTemplate = errors.New("notification template error")
+
+// NotificationTemplate 通知模板，Title、Body、Action 为 text/template，以载荷渲染，引用载荷中不存在的字段时渲染失败
+type NotificationTemplate struct {
+	Name     string   `json:"name"`
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Action   string   `json:"action"`   // 点击后的跳转地址或动作
+	Required []string `json:"required"` // 载荷必填字段
+}
+
+// Notification 站内通知
+type Notification struct {
+	ID          string                 `json:"id"`
+	Template    string                 `json:"template"`
+	Guard       string                 `json:"guard"`
+	UserID      string                 `json:"user_id"`
+	Title       string                 `json:"title"`
+	Body        string                 `json:"body"`
+	Action      string                 `json:"action,omitempty"`
+	Data        map[string]interface{} `json:"data,omitempty"`
+	CreatedAt   int64                  `json:"created_at"`
+	DeliveredAt int64                  `json:"delivered_at,omitempty"`
+	ReadAt      int64                  `json:"read_at,omitempty"`
+}
+
+// NotificationStore 通知存储，离线时暂存，并记录送达与已读状态
+type NotificationStore interface {
+	Save(ctx context.Context, n *Notification) error
+	// List 按创建时间倒序返回用户通知，unreadOnly 为 true 时只返回未读
+	List(ctx context.Context, guard, userID string, unreadOnly bool) ([]*Notification, error)
+	// MarkDelivered 标记已送达
+	MarkDelivered(ctx context.Context, guard, userID string, ids []string, at time.Time) error
+	// MarkRead 标记已读，返回本次由未读变为已读的 ID
+	MarkRead(ctx context.Context, guard, userID string, ids []string, at time.Time) ([]string, error)
+}
+
+// NotificationPusher 用户不在线时的推送通道（如 APNs、FCM、短信），由业务实现并注入
+type NotificationPusher interface {
+	Push(ctx context.Context, n *Notification) error
+}
+
+// NotificationReadRequest ws.notification.read 事件数据
+type NotificationReadRequest struct {
+	IDs []string `json:"ids"`
+}
+
+type compiledTemplate struct {
+	def                  NotificationTemplate
+	title, body, actionT *template.Template
+}
+
+// Notifier 站内通知：按模板渲染，推送到用户所有在线连接，均不在线时交给离线推送通道，客户端上线后通过 ws.notification.sync 拉取
+// 仅推送到当前实例的连接，多实例部署时需配合 InstanceRouter 将用户路由到所属实例
+type Notifier struct {
+	mu        sync.RWMutex
+	templates map[string]*compiledTemplate
+	store     NotificationStore
+	pusher    NotificationPusher
+	server    *Server
+	log       *logger_provider.Logger
+}
+
+// NotifierIn Notifier 的 fx 入参
+type NotifierIn struct {
+	fx.In
+
+	Cfg    *config_provider.Config
+	Log    *logger_provider.Logger
+	Redis  *redis_provider.Redis `optional:"true"`
+	Store  NotificationStore     `optional:"true"`
+	Pusher NotificationPusher    `optional:"true"`
+}
+
+// NewNotifier 创建通知服务，未注入 NotificationStore 时启用 redis 则存储在 redis，否则存储在内存
+//
+//	websocket:
+//	  notifications:
+//	    ttl: 720h          # 通知保留时间
+//	    max_per_user: 200  # 每个用户保留的通知数
+//	    templates:
+//	      order_shipped:
+//	        title: 订单已发货
+//	        body: 订单 {{.order_no}} 已发货
+//	        action: /orders/{{.order_id}}
+//	        required: [order_no, order_id]
+func NewNotifier(in NotifierIn) (*Notifier, error) {
+	ttl := in.Cfg.GetDuration("websocket.notifications.ttl")
+	if ttl <= 0 {
+		ttl = 30 * 24 * time.Hour
+	}
+	maxPerUser := in.Cfg.GetInt("websocket.notifications.max_per_user")
+	if maxPerUser <= 0 {
+		maxPerUser = 200
+	}
+
+	store := in.Store
+	if store == nil {
+		if in.Redis != nil {
+			store = NewRedisNotificationStore(in.Redis, ttl, maxPerUser)
+		} else {
+			store = NewMemoryNotificationStore(maxPerUser)
+		}
+	}
+
+	n := &Notifier{templates: map[string]*compiledTemplate{}, store: store, pusher: in.Pusher, log: in.Log}
+	for name := range in.Cfg.GetStringMap("websocket.notifications.templates") {
+		prefix := "websocket.notifications.templates." + name
+		if err := n.Register(NotificationTemplate{
+			Name:     name,
+			Title:    in.Cfg.GetString(prefix + ".title"),
+			Body:     in.Cfg.GetString(prefix + ".body"),
+			Action:   in.Cfg.GetString(prefix + ".action"),
+			Required: in.Cfg.GetStringSlice(prefix + ".required"),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// Register 注册或覆盖通知模板
+func (n *Notifier) Register(tpl NotificationTemplate) error {
+	name := strings.TrimSpace(tpl.Name)
+	if name == "" {
+		return fmt.Errorf("%w: empty template name", ErrNotificationTemplate)
+	}
+	c := &compiledTemplate{def: tpl}
+	var err error
+	for _, item := range []struct {
+		dst  **template.Template
+		text string
+		kind string
+	}{{&c.title, tpl.Title, "title"}, {&c.body, tpl.Body, "body"}, {&c.actionT, tpl.Action, "action"}} {
+		if *item.dst, err = template.New(name + "." + item.kind).Option("missingkey=error").Parse(item.text); err != nil {
+			return fmt.Errorf("%w: %s.%s: %v", ErrNotificationTemplate, name, item.kind, err)
+		}
+	}
+	n.mu.Lock()
+	n.templates[name] = c
+	n.mu.Unlock()
+	return nil
+}
+
+// Render 按模板渲染通知，不推送
+func (n *Notifier) Render(name, guard, userID string, data map[string]interface{}) (*Notification, error) {
+	n.mu.RLock()
+	c, ok := n.templates[name]
+	n.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: template %s not found", ErrNotificationTemplate, name)
+	}
+	for _, field := range c.def.Required {
+		if v, ok := data[field]; !ok || v == nil {
+			return nil, fmt.Errorf("%w: %s requires field %s", ErrNotificationTemplate, name, field)
+		}
+	}
+
+	out := &Notification{
+		ID:        uuid.NewString(),
+		Template:  name,
+		Guard:     guard,
+		UserID:    userID,
+		Data:      data,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	for _, item := range []struct {
+		dst *string
+		tpl *template.Template
+	}{{&out.Title, c.title}, {&out.Body, c.body}, {&out.Action, c.actionT}} {
+		var buf bytes.Buffer
+		if err := item.tpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotificationTemplate, err)
+		}
+		*item.dst = buf.String()
+	}
+	return out, nil
+}
+
+// Notify 渲染并发送通知：保存后推送到用户所有在线连接，无在线连接时交给 NotificationPusher
+func (n *Notifier) Notify(ctx context.Context, name, guard, userID string, data map[string]interface{}) (*Notification, error) {
+	notification, err := n.Render(name, guard, userID, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.store.Save(ctx, notification); err != nil {
+		return nil, err
+	}
+
+	delivered := 0
+	if n.server != nil {
+		env := NewEnvelope(EventNotification)
+		env.Data = notification
+		delivered = n.server.Push(PushTarget{Guard: guard, UserID: userID}, env)
+	}
+	if delivered > 0 {
+		now := time.Now()
+		notification.DeliveredAt = now.UnixMilli()
+		return notification, n.store.MarkDelivered(ctx, guard, userID, []string{notification.ID}, now)
+	}
+	if n.pusher != nil {
+		if err := n.pusher.Push(ctx, notification); err != nil {
+			return notification, fmt.Errorf("notification saved but offline push failed: %w", err)
+		}
+	}
+	return notification, nil
+}
+
+// Unread 返回用户未读通知
+func (n *Notifier) Unread(ctx context.Context, guard, userID string) ([]*Notification, error) {
+	return n.store.List(ctx, guard, userID, true)
+}
+
+// List 返回用户全部通知
+func (n *Notifier) List(ctx context.Context, guard, userID string) ([]*Notification, error) {
+	return n.store.List(ctx, guard, userID, false)
+}
+
+// MarkRead 标记已读，返回本次由未读变为已读的 ID
+func (n *Notifier) MarkRead(ctx context.Context, guard, userID string, ids ...string) ([]string, error) {
+	return n.store.MarkRead(ctx, guard, userID, ids, time.Now())
+}
+
+// handleMessage 处理客户端的 ws.notification.sync 与 ws.notification.read，其它消息放行
+func (n *Notifier) handleMessage(ms *melody.Session, raw []byte) bool {
+	var env Envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return true
+	}
+	if env.Event != EventNotificationSync && env.Event != EventNotificationRead {
+		return true
+	}
+	guard, _ := ms.Get("guard")
+	userID, _ := ms.Get("user_id")
+	g, _ := guard.(string)
+	u, _ := userID.(string)
+	if g == "" || u == "" {
+		return false
+	}
+
+	ctx := context.Background()
+	reply := NewEnvelope(env.Event)
+	switch env.Event {
+	case EventNotificationSync:
+		unread, err := n.store.List(ctx, g, u, true)
+		if err != nil {
+			n.logError("notification sync failed", err)
+			return false
+		}
+		ids := make([]string, 0, len(unread))
+		for _, item := range unread {
+			if item.DeliveredAt == 0 {
+				ids = append(ids, item.ID)
+			}
+		}
+		if len(ids) > 0 {
+			if err := n.store.MarkDelivered(ctx, g, u, ids, time.Now()); err != nil {
+				n.logError("notification mark delivered failed", err)
+			}
+		}
+		reply.Data = unread
+	case EventNotificationRead:
+		var req NotificationReadRequest
+		if err := DecodeData(env.Data, &req); err != nil || len(req.IDs) == 0 {
+			return false
+		}
+		ids, err := n.store.MarkRead(ctx, g, u, req.IDs, time.Now())
+		if err != nil {
+			n.logError("notification mark read failed", err)
+			return false
+		}
+		reply.Data = NotificationReadRequest{IDs: ids}
+	}
+	if n.server != nil {
+		_ = n.server.Send(ms, reply)
+	}
+	return false
+}
+
+func (n *Notifier) logError(msg string, err error) {
+	if n.log != nil {
+		n.log.Warnw(msg, "error", err)
+	}
+}
+
+// sortNotifications 按创建时间倒序
+func sortNotifications(list []*Notification) {
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt > list[j].CreatedAt })
+}
+
+// NotificationModule 站内通知模块，需与 WebSocketServerModule 一起使用
+var NotificationModule = fx.Options(
+	fx.Provide(NewNotifier),
+	fx.Provide(fx.Annotate(
+		func(n *Notifier) WSMessageMiddleware { return n.handleMessage },
+		fx.ResultTags(`group:"ws_message_middlewares"`),
+	)),
+	// Server 依赖消息中间件，创建后再注入，避免循环依赖
+	fx.Invoke(func(n *Notifier, s *Server) { n.server = s }),
+)
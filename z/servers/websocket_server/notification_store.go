@@ -0,0 +1,245 @@
+package websocket_server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/redis_provider"
+)
+
+// memoryNotificationStore 内存通知存储，仅适用于单实例
+type memoryNotificationStore struct {
+	mu         sync.Mutex
+	maxPerUser int
+	items      map[string][]*Notification // guard:userID -> 按创建顺序
+}
+
+// NewMemoryNotificationStore 创建内存通知存储，maxPerUser <= 0 时不限制
+func NewMemoryNotificationStore(maxPerUser int) NotificationStore {
+	return &memoryNotificationStore{maxPerUser: maxPerUser, items: map[string][]*Notification{}}
+}
+
+func (s *memoryNotificationStore) Save(_ context.Context, n *Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := UserKey(n.Guard, n.UserID)
+	cp := *n
+	list := append(s.items[key], &cp)
+	if s.maxPerUser > 0 && len(list) > s.maxPerUser {
+		list = list[len(list)-s.maxPerUser:]
+	}
+	s.items[key] = list
+	return nil
+}
+
+func (s *memoryNotificationStore) List(_ context.Context, guard, userID string, unreadOnly bool) ([]*Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := s.items[UserKey(guard, userID)]
+	out := make([]*Notification, 0, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		n := items[i]
+		if unreadOnly && n.ReadAt > 0 {
+			continue
+		}
+		cp := *n
+		out = append(out, &cp)
+	}
+	sortNotifications(out)
+	return out, nil
+}
+
+func (s *memoryNotificationStore) MarkDelivered(_ context.Context, guard, userID string, ids []string, at time.Time) error {
+	s.update(guard, userID, ids, func(n *Notification) bool {
+		if n.DeliveredAt > 0 {
+			return false
+		}
+		n.DeliveredAt = at.UnixMilli()
+		return true
+	})
+	return nil
+}
+
+func (s *memoryNotificationStore) MarkRead(_ context.Context, guard, userID string, ids []string, at time.Time) ([]string, error) {
+	return s.update(guard, userID, ids, func(n *Notification) bool {
+		if n.ReadAt > 0 {
+			return false
+		}
+		n.ReadAt = at.UnixMilli()
+		if n.DeliveredAt == 0 {
+			n.DeliveredAt = n.ReadAt
+		}
+		return true
+	}), nil
+}
+
+func (s *memoryNotificationStore) update(guard, userID string, ids []string, fn func(n *Notification) bool) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	want := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		want[id] = struct{}{}
+	}
+	changed := make([]string, 0, len(ids))
+	for _, n := range s.items[UserKey(guard, userID)] {
+		if _, ok := want[n.ID]; ok && fn(n) {
+			changed = append(changed, n.ID)
+		}
+	}
+	return changed
+}
+
+// redisNotificationStore Redis 通知存储，每个用户一个 hash（ID -> JSON），多实例共享
+type redisNotificationStore struct {
+	redis      *redis_provider.Redis
+	ttl        time.Duration
+	maxPerUser int
+}
+
+// NewRedisNotificationStore 创建 Redis 通知存储，ttl 为用户最后一条通知后的保留时间
+// 通知按 redis.compression 配置压缩，超过阈值的通知以压缩形式保存
+func NewRedisNotificationStore(r *redis_provider.Redis, ttl time.Duration, maxPerUser int) NotificationStore {
+	return &redisNotificationStore{redis: r, ttl: ttl, maxPerUser: maxPerUser}
+}
+
+func (s *redisNotificationStore) key(guard, userID string) string {
+	return "ws:notifications:" + UserKey(guard, userID)
+}
+
+func (s *redisNotificationStore) Save(ctx context.Context, n *Notification) error {
+	b, err := s.encode(n)
+	if err != nil {
+		return err
+	}
+	key := s.key(n.Guard, n.UserID)
+	client := s.redis.UniversalClient()
+	if err := client.HSet(ctx, key, n.ID, b).Err(); err != nil {
+		return err
+	}
+	if s.ttl > 0 {
+		_ = client.Expire(ctx, key, s.ttl).Err()
+	}
+	if s.maxPerUser > 0 {
+		return s.trim(ctx, n.Guard, n.UserID)
+	}
+	return nil
+}
+
+// trim 删除超出数量上限的最早通知
+func (s *redisNotificationStore) trim(ctx context.Context, guard, userID string) error {
+	client := s.redis.UniversalClient()
+	key := s.key(guard, userID)
+	count, err := client.HLen(ctx, key).Result()
+	if err != nil || count <= int64(s.maxPerUser) {
+		return err
+	}
+	list, err := s.List(ctx, guard, userID, false)
+	if err != nil {
+		return err
+	}
+	stale := make([]string, 0, len(list)-s.maxPerUser)
+	for _, n := range list[s.maxPerUser:] {
+		stale = append(stale, n.ID)
+	}
+	return client.HDel(ctx, key, stale...).Err()
+}
+
+func (s *redisNotificationStore) List(ctx context.Context, guard, userID string, unreadOnly bool) ([]*Notification, error) {
+	values, err := s.redis.UniversalClient().HGetAll(ctx, s.key(guard, userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Notification, 0, len(values))
+	for _, raw := range values {
+		n, err := s.decode(raw)
+		if err != nil {
+			continue
+		}
+		if unreadOnly && n.ReadAt > 0 {
+			continue
+		}
+		out = append(out, n)
+	}
+	sortNotifications(out)
+	return out, nil
+}
+
+func (s *redisNotificationStore) MarkDelivered(ctx context.Context, guard, userID string, ids []string, at time.Time) error {
+	_, err := s.update(ctx, guard, userID, ids, func(n *Notification) bool {
+		if n.DeliveredAt > 0 {
+			return false
+		}
+		n.DeliveredAt = at.UnixMilli()
+		return true
+	})
+	return err
+}
+
+func (s *redisNotificationStore) MarkRead(ctx context.Context, guard, userID string, ids []string, at time.Time) ([]string, error) {
+	return s.update(ctx, guard, userID, ids, func(n *Notification) bool {
+		if n.ReadAt > 0 {
+			return false
+		}
+		n.ReadAt = at.UnixMilli()
+		if n.DeliveredAt == 0 {
+			n.DeliveredAt = n.ReadAt
+		}
+		return true
+	})
+}
+
+func (s *redisNotificationStore) update(ctx context.Context, guard, userID string, ids []string, fn func(n *Notification) bool) ([]string, error) {
+	if len(ids) == 0 {
+		return []string{}, nil
+	}
+	client := s.redis.UniversalClient()
+	key := s.key(guard, userID)
+	values, err := client.HMGet(ctx, key, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	changed := make([]string, 0, len(ids))
+	fields := make([]interface{}, 0, len(ids)*2)
+	for _, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			continue
+		}
+		n, err := s.decode(raw)
+		if err != nil || !fn(n) {
+			continue
+		}
+		b, err := s.encode(n)
+		if err != nil {
+			continue
+		}
+		changed = append(changed, n.ID)
+		fields = append(fields, n.ID, b)
+	}
+	if len(fields) == 0 {
+		return changed, nil
+	}
+	return changed, client.HSet(ctx, key, fields...).Err()
+}
+
+func (s *redisNotificationStore) encode(n *Notification) ([]byte, error) {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+	return s.redis.Codec().Encode(b)
+}
+
+func (s *redisNotificationStore) decode(raw string) (*Notification, error) {
+	b, err := s.redis.Codec().Decode([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+	var n Notification
+	if err := json.Unmarshal(b, &n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
@@ -0,0 +1,216 @@
+package websocket_server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/olahol/melody"
+)
+
+// ErrCodeRateLimited 入站消息超过连接或频道的速率限制
+const ErrCodeRateLimited = "rate_limited"
+
+// 超出速率限制时的处理方式
+const (
+	RateActionDrop       = "drop"       // 丢弃消息
+	RateActionWarn       = "warn"       // 丢弃消息并推送 ws.error 事件
+	RateActionDisconnect = "disconnect" // 断开连接
+)
+
+const rateSessionKey = "ws_rate"
+
+// maxRateChannels 每个连接记录限流状态的频道数上限
+const maxRateChannels = 256
+
+// WSChannelKeyFunc 返回入站消息所属频道，用于按频道限流，返回空字符串表示不按频道限流
+type WSChannelKeyFunc func(ms *melody.Session, raw []byte) string
+
+// DefaultChannelKey 默认以消息信封 data.channel 作为频道
+func DefaultChannelKey(ms *melody.Session, raw []byte) string {
+	var env struct {
+		Data struct {
+			Channel string `json:"channel"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(env.Data.Channel)
+}
+
+// RateLimit 入站消息速率，<= 0 表示不限制；突发容量为一秒的配额
+type RateLimit struct {
+	MessagesPerSec float64 `json:"messages_per_sec"`
+	BytesPerSec    float64 `json:"bytes_per_sec"`
+}
+
+func (r RateLimit) enabled() bool {
+	return r.MessagesPerSec > 0 || r.BytesPerSec > 0
+}
+
+// tokenBucket 令牌桶，桶满时允许超过容量的单次消耗，避免大于容量的消息永远无法通过
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, last: now}
+}
+
+// available 补充令牌并判断能否消耗 n 个令牌，nil 表示不限制
+func (b *tokenBucket) available(n float64, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	return b.tokens >= n || b.tokens >= b.rate
+}
+
+func (b *tokenBucket) take(n float64) {
+	if b != nil {
+		b.tokens -= n
+	}
+}
+
+// rateBuckets 一组消息数 / 字节数令牌桶
+type rateBuckets struct {
+	messages *tokenBucket
+	bytes    *tokenBucket
+}
+
+func newRateBuckets(limit RateLimit, now time.Time) *rateBuckets {
+	b := &rateBuckets{}
+	if limit.MessagesPerSec > 0 {
+		b.messages = newTokenBucket(limit.MessagesPerSec, now)
+	}
+	if limit.BytesPerSec > 0 {
+		b.bytes = newTokenBucket(limit.BytesPerSec, now)
+	}
+	return b
+}
+
+// allow 两个桶都有余量时才扣减，被拒绝的消息不消耗配额
+func (b *rateBuckets) allow(size int, now time.Time) bool {
+	okMessages := b.messages.available(1, now)
+	okBytes := b.bytes.available(float64(size), now)
+	if !okMessages || !okBytes {
+		return false
+	}
+	b.messages.take(1)
+	b.bytes.take(float64(size))
+	return true
+}
+
+// connRate 单个连接的限流状态
+type connRate struct {
+	mu       sync.Mutex
+	conn     *rateBuckets
+	channels map[string]*rateBuckets
+}
+
+// rateLimitConfig 入站消息限流配置
+type rateLimitConfig struct {
+	action     string
+	connection RateLimit
+	channel    RateLimit
+}
+
+// loadRateLimitConfig 读取 websocket.rate_limit 配置
+//
+//	websocket:
+//	  rate_limit:
+//	    action: warn              # drop / warn（默认，丢弃并推送 ws.error）/ disconnect
+//	    connection: { messages_per_sec: 20, bytes_per_sec: 65536 }
+//	    channel: { messages_per_sec: 5, bytes_per_sec: 16384 }   # 单个连接向每个频道发送的速率
+//
+// 频道由 WSChannelKeyFunc 从消息中提取，默认读取 data.channel
+func loadRateLimitConfig(cfg *config_provider.Config) (*rateLimitConfig, error) {
+	rc := &rateLimitConfig{
+		action: strings.ToLower(strings.TrimSpace(cfg.GetString("websocket.rate_limit.action", RateActionWarn))),
+		connection: RateLimit{
+			MessagesPerSec: cfg.GetFloat64("websocket.rate_limit.connection.messages_per_sec"),
+			BytesPerSec:    cfg.GetFloat64("websocket.rate_limit.connection.bytes_per_sec"),
+		},
+		channel: RateLimit{
+			MessagesPerSec: cfg.GetFloat64("websocket.rate_limit.channel.messages_per_sec"),
+			BytesPerSec:    cfg.GetFloat64("websocket.rate_limit.channel.bytes_per_sec"),
+		},
+	}
+	switch rc.action {
+	case "":
+		rc.action = RateActionWarn
+	case RateActionDrop, RateActionWarn, RateActionDisconnect:
+	default:
+		return nil, fmt.Errorf("invalid websocket.rate_limit.action: %s", rc.action)
+	}
+	return rc, nil
+}
+
+func (rc *rateLimitConfig) enabled() bool {
+	return rc.connection.enabled() || rc.channel.enabled()
+}
+
+// check 校验入站消息速率，超出时返回拒绝原因所在的范围（connection / channel:<name>）
+func (rc *rateLimitConfig) check(ms *melody.Session, msg []byte, channelKey WSChannelKeyFunc) (string, bool) {
+	var state *connRate
+	if v, ok := ms.Get(rateSessionKey); ok {
+		state, _ = v.(*connRate)
+	}
+	if state == nil {
+		state = &connRate{}
+		ms.Set(rateSessionKey, state)
+	}
+	channel := ""
+	if rc.channel.enabled() && channelKey != nil {
+		channel = channelKey(ms, msg)
+	}
+
+	now := time.Now()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if rc.connection.enabled() {
+		if state.conn == nil {
+			state.conn = newRateBuckets(rc.connection, now)
+		}
+		if !state.conn.allow(len(msg), now) {
+			return "connection", false
+		}
+	}
+	if channel == "" {
+		return "", true
+	}
+	buckets, ok := state.channels[channel]
+	if !ok {
+		if state.channels == nil {
+			state.channels = map[string]*rateBuckets{}
+		}
+		// 频道名由客户端控制，记录数达到上限时清空，避免内存无限增长
+		if len(state.channels) >= maxRateChannels {
+			clear(state.channels)
+		}
+		buckets = newRateBuckets(rc.channel, now)
+		state.channels[channel] = buckets
+	}
+	if !buckets.allow(len(msg), now) {
+		return "channel:" + channel, false
+	}
+	return "", true
+}
+
+// rateLimitedError 超出速率限制时推送的 ws.error 事件
+func rateLimitedError(scope string) Envelope {
+	env := NewEnvelope(EventError)
+	env.Data = ErrorMessage{
+		Code:    ErrCodeRateLimited,
+		Message: "message rate exceeds " + scope + " limit",
+	}
+	return env
+}
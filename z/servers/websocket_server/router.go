@@ -0,0 +1,97 @@
+package websocket_server
+
+import (
+	"github.com/icreateapp-com/go-zLib/z"
+)
+
+// InstanceRouter 基于一致性哈希将用户归属到实例，多实例部署时可直接将消息路由到所属节点，而非广播到所有实例
+// 需要网关按 UserKey 使用相同规则进行粘性接入
+type InstanceRouter struct {
+	self string
+	ring *z.HashRing
+}
+
+// NewInstanceRouter 创建实例路由，self 为当前实例标识，instances 为集群所有实例
+func NewInstanceRouter(self string, instances []string, replicas int) *InstanceRouter {
+	ring := z.NewHashRing(replicas, instances...)
+	ring.Add(self)
+	return &InstanceRouter{self: self, ring: ring}
+}
+
+// Self 返回当前实例标识
+func (r *InstanceRouter) Self() string {
+	return r.self
+}
+
+// Join 实例加入集群
+func (r *InstanceRouter) Join(instances ...string) {
+	r.ring.Add(instances...)
+}
+
+// Leave 实例离开集群
+func (r *InstanceRouter) Leave(instances ...string) {
+	r.ring.Remove(instances...)
+}
+
+// SetInstances 使用最新实例列表替换集群成员，当前实例始终保留
+func (r *InstanceRouter) SetInstances(instances ...string) {
+	nodes := make([]string, 0, len(instances)+1)
+	nodes = append(nodes, instances...)
+	r.ring.Set(append(nodes, r.self)...)
+}
+
+// Instances 返回集群所有实例
+func (r *InstanceRouter) Instances() []string {
+	return r.ring.Nodes()
+}
+
+// Owner 返回 key 所属实例
+func (r *InstanceRouter) Owner(key string) string {
+	return r.ring.Get(key)
+}
+
+// IsLocal 判断 key 是否归属当前实例
+func (r *InstanceRouter) IsLocal(key string) bool {
+	return r.Owner(key) == r.self
+}
+
+// UserKey 返回用户路由键，同一用户的连接应归属同一实例
+func UserKey(guard, userID string) string {
+	return guard + ":" + userID
+}
+
+// OwnerOfUser 返回用户所属实例
+func (r *InstanceRouter) OwnerOfUser(guard, userID string) string {
+	return r.Owner(UserKey(guard, userID))
+}
+
+// Route 按所属实例拆分推送目标，广播、频道和连接ID推送无法确定归属，需要发送给所有实例
+func (r *InstanceRouter) Route(target PushTarget) map[string]PushTarget {
+	out := map[string]PushTarget{}
+	if target.Broadcast || target.Channel != "" || target.ConnID != "" || len(target.ConnIDs) > 0 {
+		for _, instance := range r.Instances() {
+			out[instance] = target
+		}
+		return out
+	}
+
+	add := func(instance string, fn func(t *PushTarget)) {
+		t := out[instance]
+		t.Guard = target.Guard
+		fn(&t)
+		out[instance] = t
+	}
+
+	if target.Guard != "" && target.UserID != "" {
+		userID := target.UserID
+		add(r.OwnerOfUser(target.Guard, userID), func(t *PushTarget) { t.UserIDs = append(t.UserIDs, userID) })
+	}
+	if target.Guard != "" {
+		for _, userID := range target.UserIDs {
+			uid := userID
+			add(r.OwnerOfUser(target.Guard, uid), func(t *PushTarget) { t.UserIDs = append(t.UserIDs, uid) })
+		}
+	}
+
+	return out
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/icreateapp-com/go-zLib/z/providers/auth_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
@@ -20,6 +21,9 @@ type WSHandlerRegister func(m *melody.Melody, hub *Hub)
 
 type WSMessageMiddleware func(ms *melody.Session, raw []byte) (pass bool)
 
+// WSSubscribeAuthorizer 校验连接是否允许订阅频道，在 websocket.acl 规则之后执行；新代码建议使用 WSChannelAuthorizer
+type WSSubscribeAuthorizer func(ms *melody.Session, channel string) bool
+
 type In struct {
 	fx.In
 
@@ -29,6 +33,11 @@ type In struct {
 	Auth     *auth_provider.Auth
 	Handlers []WSHandlerRegister   `group:"ws_handlers"`
 	MsgMws   []WSMessageMiddleware `group:"ws_message_middlewares"`
+	DedupKey WSDedupKeyFunc        `optional:"true"`
+
+	SubscribeAuthorizer WSSubscribeAuthorizer `optional:"true"`
+	ChannelAuthorizer   WSChannelAuthorizer   `optional:"true"`
+	ChannelKey          WSChannelKeyFunc      `optional:"true"`
 }
 
 type Server struct {
@@ -60,7 +69,47 @@ func NewWebSocketServer(in In) (Out, error) {
 		path = "/ws"
 	}
 
+	// 消息去重：客户端重试导致的重复消息不再交给处理器
+	dedupEnabled := in.Cfg.GetBool("websocket.dedup.enabled")
+	dedupSize := in.Cfg.GetInt("websocket.dedup.size", 128)
+	if dedupSize <= 0 {
+		dedupSize = 128
+	}
+	dedupTTL := time.Duration(in.Cfg.GetInt("websocket.dedup.ttl_sec", 60)) * time.Second
+	if dedupTTL <= 0 {
+		dedupTTL = 60 * time.Second
+	}
+	dedupKey := in.DedupKey
+	if dedupKey == nil {
+		dedupKey = DefaultDedupKey
+	}
+
+	// 入站限流：按连接及连接发往的频道限制消息数与字节数
+	rateLimit, err := loadRateLimitConfig(in.Cfg)
+	if err != nil {
+		return Out{}, err
+	}
+	channelKey := in.ChannelKey
+	if channelKey == nil {
+		channelKey = DefaultChannelKey
+	}
+
+	// 频道访问控制：客户端订阅前按 websocket.acl 规则和注入的授权函数校验
+	acl, err := loadChannelACL(in.Cfg)
+	if err != nil {
+		return Out{}, err
+	}
+	acl.authorize = in.ChannelAuthorizer
+	acl.legacy = in.SubscribeAuthorizer
+
+	// 传输参数：压缩、消息大小上限、发送缓冲
+	transport, err := loadTransportConfig(in.Cfg)
+	if err != nil {
+		return Out{}, err
+	}
+
 	m := melody.New()
+	transport.apply(m)
 	hub := NewHub()
 	s := &Server{m: m, hub: hub, log: in.Log}
 
@@ -96,10 +145,11 @@ func NewWebSocketServer(in In) (Out, error) {
 			ms.Set("conn_id", meta.ConnID)
 			ms.Set("guard", meta.Guard)
 			ms.Set("user_id", meta.UserID)
+			transport.negotiate(ms, guard)
 			return
 		}
 
-		ok, _, authCtx, err := in.Auth.AuthenticateByGuard(guard, tokenHeader, tokenQuery)
+		ok, _, authCtx, err := in.Auth.AuthenticateByGuardContext(ms.Request.Context(), guard, tokenHeader, tokenQuery)
 		if !ok || err != nil || authCtx == nil {
 			_ = ms.CloseWithMsg([]byte("unauthorized"))
 			return
@@ -108,6 +158,7 @@ func NewWebSocketServer(in In) (Out, error) {
 		ms.Set("conn_id", meta.ConnID)
 		ms.Set("guard", meta.Guard)
 		ms.Set("user_id", meta.UserID)
+		transport.negotiate(ms, guard)
 		if authCtx.Session != nil {
 			// 会话型 guard 在连接上下文中保存续期所需的最小状态，
 			// 后续消息到达时可按 touch_interval 节流续期。
@@ -121,9 +172,40 @@ func NewWebSocketServer(in In) (Out, error) {
 		hub.Detach(ms)
 	})
 
+	m.HandleError(func(ms *melody.Session, err error) {
+		// 超过 hard_max_message_size 时底层连接已以 1009 关闭，无法再推送错误事件
+		if errors.Is(err, websocket.ErrReadLimit) && in.Log != nil {
+			connID, _ := ms.Get("conn_id")
+			in.Log.Warnw("websocket message exceeds hard limit, connection closed", "conn_id", connID, "limit", transport.hardMessageSize)
+		}
+	})
+
 	m.HandleMessage(func(ms *melody.Session, msg []byte) {
 		hub.Touch(ms)
 
+		// 超过连接消息上限的消息返回结构化错误并丢弃，连接保持
+		if rej, ok := checkMessageSize(ms, msg); !ok {
+			_ = s.Send(ms, rej)
+			return
+		}
+
+		// 超过速率限制的消息不交给处理器，避免单个客户端占满处理协程
+		if rateLimit.enabled() {
+			if scope, ok := rateLimit.check(ms, msg, channelKey); !ok {
+				switch rateLimit.action {
+				case RateActionWarn:
+					_ = s.Send(ms, rateLimitedError(scope))
+				case RateActionDisconnect:
+					if in.Log != nil {
+						connID, _ := ms.Get("conn_id")
+						in.Log.Warnw("websocket message rate exceeded, connection closed", "conn_id", connID, "scope", scope)
+					}
+					_ = ms.CloseWithMsg(websocket.FormatCloseMessage(websocket.ClosePolicyViolation, ErrCodeRateLimited))
+				}
+				return
+			}
+		}
+
 		// WebSocket 消息不会经过 HTTP 认证中间件，这里按 guard 的续期间隔
 		// 节流触发一次 session 续期，避免每条消息都写缓存。
 		authGuardValue, hasAuthGuard := ms.Get("auth.guard")
@@ -150,6 +232,10 @@ func NewWebSocketServer(in In) (Out, error) {
 			}
 		}
 
+		if dedupEnabled && isDuplicateMessage(ms, msg, dedupKey, dedupSize, dedupTTL) {
+			return
+		}
+
 		for _, mw := range in.MsgMws {
 			if mw == nil {
 				continue
@@ -171,13 +257,23 @@ func NewWebSocketServer(in In) (Out, error) {
 		case EventSubscribe:
 			var req SubscribeRequest
 			if err := DecodeData(env.Data, &req); err == nil {
-				hub.Subscribe(ms, req.Channels)
+				if rejected := acl.subscribe(hub, ms, req.Channels); len(rejected) > 0 {
+					rej := NewEnvelope(EventSubscribeRejected)
+					rej.Data = rejected
+					_ = s.Send(ms, rej)
+				}
 			}
 		case EventUnsubscribe:
 			var req SubscribeRequest
 			if err := DecodeData(env.Data, &req); err == nil {
 				hub.Unsubscribe(ms, req.Channels)
 			}
+		case EventSettings:
+			if settings, ok := GetConnSettings(ms); ok {
+				reply := NewEnvelope(EventSettings)
+				reply.Data = settings
+				_ = s.Send(ms, reply)
+			}
 		default:
 			// other events are handled by middlewares/handlers
 		}
@@ -202,7 +298,7 @@ func NewWebSocketServer(in In) (Out, error) {
 	}})
 
 	if in.Log != nil {
-		in.Log.Infow("provider[websocket] enabled", "mode", mode, "path", path)
+		in.Log.Infow("provider[websocket] enabled", "mode", mode, "path", path, "compression", transport.compression, "max_message_size", transport.maxMessageSize)
 	}
 
 	return Out{Server: s, Melody: m, Hub: hub, Route: route}, nil
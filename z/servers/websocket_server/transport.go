@@ -0,0 +1,172 @@
+package websocket_server
+
+import (
+	"compress/flate"
+	"fmt"
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/olahol/melody"
+)
+
+// 连接参数相关事件
+const (
+	EventSettings = "ws.settings" // 客户端发送该事件查询连接参数，服务端以同名事件返回 ConnSettings
+	EventError    = "ws.error"    // 服务端拒绝处理消息时推送的错误
+)
+
+// ErrCodeMessageTooLarge 入站消息超过连接的消息大小上限
+const ErrCodeMessageTooLarge = "message_too_large"
+
+// ConnSettings 连接协商后的参数
+type ConnSettings struct {
+	Compression    bool  `json:"compression"`      // 是否启用 permessage-deflate 压缩
+	MaxMessageSize int64 `json:"max_message_size"` // 入站消息大小上限（字节）
+	SendBuffer     int   `json:"send_buffer"`      // 出站消息缓冲条数，缓冲满时新消息被丢弃
+}
+
+// ErrorMessage ws.error 事件数据
+type ErrorMessage struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Limit   int64  `json:"limit,omitempty"`
+	Size    int64  `json:"size,omitempty"`
+}
+
+const connSettingsKey = "ws.settings"
+
+// guardTransport guard 级别的覆盖配置
+type guardTransport struct {
+	compression    *bool
+	maxMessageSize int64
+}
+
+// transportConfig 连接传输配置
+type transportConfig struct {
+	compression      bool
+	compressionLevel int
+	maxMessageSize   int64
+	hardMessageSize  int64
+	sendBuffer       int
+	guards           map[string]guardTransport
+}
+
+// loadTransportConfig 读取 websocket 传输配置
+//
+//	websocket:
+//	  compression:
+//	    enabled: true          # 是否协商 permessage-deflate，CPU 敏感的部署可关闭
+//	    level: 1               # 压缩级别 -2 ~ 9
+//	  max_message_size: 65536  # 入站消息上限，超出时返回 ws.error 并丢弃该消息，默认 512
+//	  hard_max_message_size: 1048576 # 超出时直接断开连接（1009），默认为各上限最大值的 4 倍
+//	  send_buffer: 256         # 每个连接的出站缓冲条数
+//	  guards:
+//	    admin: { max_message_size: 1048576, compression: false }
+func loadTransportConfig(cfg *config_provider.Config) (*transportConfig, error) {
+	tc := &transportConfig{
+		compression:      cfg.GetBool("websocket.compression.enabled", false),
+		compressionLevel: flate.BestSpeed,
+		maxMessageSize:   cfg.GetInt64("websocket.max_message_size", 512),
+		hardMessageSize:  cfg.GetInt64("websocket.hard_max_message_size", 0),
+		sendBuffer:       cfg.GetInt("websocket.send_buffer", 256),
+		guards:           map[string]guardTransport{},
+	}
+	if cfg.IsSet("websocket.compression.level") {
+		tc.compressionLevel = cfg.GetInt("websocket.compression.level")
+	}
+	if tc.maxMessageSize <= 0 {
+		tc.maxMessageSize = 512
+	}
+	if tc.sendBuffer <= 0 {
+		tc.sendBuffer = 256
+	}
+	if tc.compressionLevel < flate.HuffmanOnly || tc.compressionLevel > flate.BestCompression {
+		return nil, fmt.Errorf("invalid websocket.compression.level: %d", tc.compressionLevel)
+	}
+
+	largest := tc.maxMessageSize
+	for guard := range cfg.GetStringMap("websocket.guards") {
+		prefix := "websocket.guards." + guard
+		g := guardTransport{maxMessageSize: cfg.GetInt64(prefix + ".max_message_size")}
+		if _, ok := cfg.GetStringMap(prefix)["compression"]; ok {
+			enabled := cfg.GetBool(prefix + ".compression")
+			g.compression = &enabled
+		}
+		if g.maxMessageSize > largest {
+			largest = g.maxMessageSize
+		}
+		tc.guards[strings.ToLower(guard)] = g
+	}
+	if tc.hardMessageSize <= 0 {
+		tc.hardMessageSize = largest * 4
+	}
+	if tc.hardMessageSize < largest {
+		return nil, fmt.Errorf("invalid websocket.hard_max_message_size: %d is smaller than max_message_size %d", tc.hardMessageSize, largest)
+	}
+	return tc, nil
+}
+
+// apply 设置 melody 的全局参数；连接级的消息上限由 HandleMessage 按 ConnSettings 校验
+func (tc *transportConfig) apply(m *melody.Melody) {
+	m.Upgrader.EnableCompression = tc.compression
+	m.Config.MaxMessageSize = tc.hardMessageSize
+	m.Config.MessageBufferSize = tc.sendBuffer
+}
+
+// negotiate 计算连接参数并在连接上生效
+func (tc *transportConfig) negotiate(ms *melody.Session, guard string) ConnSettings {
+	settings := ConnSettings{
+		Compression:    tc.compression,
+		MaxMessageSize: tc.maxMessageSize,
+		SendBuffer:     tc.sendBuffer,
+	}
+	if g, ok := tc.guards[strings.ToLower(guard)]; ok {
+		if g.compression != nil {
+			settings.Compression = tc.compression && *g.compression
+		}
+		if g.maxMessageSize > 0 {
+			settings.MaxMessageSize = g.maxMessageSize
+		}
+	}
+	// 客户端未声明 permessage-deflate 时不会启用压缩
+	if settings.Compression && (ms.Request == nil || !strings.Contains(strings.ToLower(ms.Request.Header.Get("Sec-WebSocket-Extensions")), "permessage-deflate")) {
+		settings.Compression = false
+	}
+	if conn := ms.WebsocketConnection(); conn != nil && tc.compression {
+		conn.EnableWriteCompression(settings.Compression)
+		if settings.Compression {
+			_ = conn.SetCompressionLevel(tc.compressionLevel)
+		}
+	}
+	ms.Set(connSettingsKey, settings)
+	return settings
+}
+
+// GetConnSettings 返回连接协商后的参数
+func GetConnSettings(ms *melody.Session) (ConnSettings, bool) {
+	if ms == nil {
+		return ConnSettings{}, false
+	}
+	v, ok := ms.Get(connSettingsKey)
+	if !ok {
+		return ConnSettings{}, false
+	}
+	settings, ok := v.(ConnSettings)
+	return settings, ok
+}
+
+// checkMessageSize 校验入站消息大小，超出时返回 ws.error 事件
+func checkMessageSize(ms *melody.Session, msg []byte) (Envelope, bool) {
+	settings, ok := GetConnSettings(ms)
+	if !ok || int64(len(msg)) <= settings.MaxMessageSize {
+		return Envelope{}, true
+	}
+	env := NewEnvelope(EventError)
+	env.Data = ErrorMessage{
+		Code:    ErrCodeMessageTooLarge,
+		Message: fmt.Sprintf("message size %d exceeds limit %d", len(msg), settings.MaxMessageSize),
+		Limit:   settings.MaxMessageSize,
+		Size:    int64(len(msg)),
+	}
+	return env, false
+}
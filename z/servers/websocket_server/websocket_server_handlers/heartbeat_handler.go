@@ -9,6 +9,7 @@ import (
 	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/event_bus_provider"
 	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/supervisor_provider"
 	"github.com/icreateapp-com/go-zLib/z/servers/websocket_server"
 	"github.com/olahol/melody"
 	"go.uber.org/fx"
@@ -25,6 +26,8 @@ type HeartbeatHandlerIn struct {
 	Cfg *config_provider.Config
 	Log *logger_provider.Logger
 	Bus *event_bus_provider.EventBus `optional:"true"`
+
+	Supervisor *supervisor_provider.Supervisor `optional:"true"`
 }
 
 type HeartbeatMiddlewareOut struct {
@@ -139,10 +142,15 @@ func NewHeartbeatTimeoutScannerRegister(in HeartbeatHandlerIn) HeartbeatScannerO
 		if hub == nil {
 			return
 		}
-		go func() {
+		scan := func(ctx context.Context) error {
 			ticker := time.NewTicker(scanInterval)
 			defer ticker.Stop()
-			for range ticker.C {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
 				sessions := hub.ListSessions()
 				nowMs := time.Now().UnixMilli()
 				for s := range sessions {
@@ -159,7 +167,14 @@ func NewHeartbeatTimeoutScannerRegister(in HeartbeatHandlerIn) HeartbeatScannerO
 					}
 				}
 			}
-		}()
+		}
+
+		// 有监管器时由其负责 panic 恢复与重启
+		if in.Supervisor != nil {
+			in.Supervisor.GoFunc("ws.heartbeat_scanner", scan)
+		} else {
+			go func() { _ = scan(context.Background()) }()
+		}
 
 		if in.Log != nil {
 			in.Log.Infow("ws heartbeat timeout scanner enabled", "interval", scanInterval.String(), "timeout", timeout.String())
@@ -0,0 +1,87 @@
+package websocket_server_handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/icreateapp-com/go-zLib/z/providers/config_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/db_provider/db_middlewares"
+	"github.com/icreateapp-com/go-zLib/z/providers/event_bus_provider"
+	"github.com/icreateapp-com/go-zLib/z/providers/logger_provider"
+	"github.com/icreateapp-com/go-zLib/z/servers/websocket_server"
+	"go.uber.org/fx"
+)
+
+type ModelEventsHandlerIn struct {
+	fx.In
+
+	LC  fx.Lifecycle
+	Cfg *config_provider.Config
+	Log *logger_provider.Logger
+	Bus *event_bus_provider.EventBus `optional:"true"`
+	WS  *websocket_server.Server     `optional:"true"`
+}
+
+// ModelRoom 返回记录对应的房间名，如 ModelRoom("order", 123) 为 order:123
+func ModelRoom(room string, id interface{}) string {
+	return room + ":" + fmt.Sprint(id)
+}
+
+// NewModelEventsHandler 将模型事件推送到记录房间
+// 仅转发 websocket.model_events.rooms 中配置的表（表名 -> 房间前缀），客户端通过 ws.subscribe 订阅 order:123 即可收到 ws.model.updated 等事件
+// 事件只包含表名、动作、主键和变更的列名，不包含记录内容
+func NewModelEventsHandler(in ModelEventsHandlerIn) {
+	if in.WS == nil || in.Bus == nil {
+		return
+	}
+
+	rooms := map[string]string{}
+	for table, room := range in.Cfg.GetStringMap("websocket.model_events.rooms") {
+		name := strings.TrimSpace(fmt.Sprint(room))
+		if name == "" {
+			name = table
+		}
+		rooms[table] = name
+	}
+	if len(rooms) == 0 {
+		return
+	}
+
+	listener := func(ctx context.Context, event event_bus_provider.Event[any]) {
+		me, ok := event.Payload.(db_middlewares.ModelEvent)
+		if !ok || me.ID == "" {
+			return
+		}
+		room, ok := rooms[me.Table]
+		if !ok {
+			return
+		}
+		env := websocket_server.NewEnvelope("ws." + event.Name)
+		// 房间订阅不校验记录权限，只推送主键与变更的列名，客户端按需通过接口拉取记录
+		me.Data = nil
+		env.Data = me
+		in.WS.Push(websocket_server.PushTarget{Channel: ModelRoom(room, me.ID)}, env)
+	}
+
+	events := []string{db_middlewares.EventModelCreated, db_middlewares.EventModelUpdated, db_middlewares.EventModelDeleted}
+	ids := make([]uint64, 0, len(events))
+	for _, name := range events {
+		ids = append(ids, in.Bus.On(name, listener))
+	}
+
+	in.LC.Append(fx.Hook{OnStop: func(ctx context.Context) error {
+		for i, name := range events {
+			in.Bus.Off(name, ids[i])
+		}
+		return nil
+	}})
+
+	if in.Log != nil {
+		in.Log.Infow("ws model events handler enabled", "tables", len(rooms))
+	}
+}
+
+var ModelEventsHandlerModule = fx.Options(
+	fx.Invoke(NewModelEventsHandler),
+)
@@ -0,0 +1,290 @@
+package z
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceClientOptions 服务客户端选项
+type ServiceClientOptions struct {
+	BaseURL       string             // 服务基础地址，如 http://user-service:8080/api
+	AuthToken     string             // 访问令牌，为空时不注入；设置 Credentials 时忽略
+	Credentials   Credentials        // 凭证（StaticToken、OAuth2ClientCredentials、MTLSCredentials 等），响应 401 时自动刷新并重试一次
+	AuthHeader    string             // 令牌请求头，默认 Authorization
+	AuthScheme    string             // 令牌前缀，默认 Bearer，设置为 "-" 表示不加前缀
+	Headers       map[string]string  // 每次请求附加的请求头
+	ContentType   RequestContentType // 默认内容类型，默认 JSON
+	Timeout       time.Duration      // 单次请求超时时间
+	Retries       int                // 失败重试次数（仅网络错误和 5xx 响应），默认仅重试幂等方法；请求体为 io.Reader 或 multipart 时不重试
+	RetryInterval time.Duration      // 重试间隔，默认 200ms，按次数线性递增
+	RetryUnsafe   bool               // 同时重试 POST、PATCH 等非幂等请求，仅在接收方可去重时开启，否则可能重复执行
+	Client        *http.Client       // 自定义 client（可选）
+
+	Instances []ServiceInstance // 服务实例列表，非空时每次请求按 Filter 选择实例，忽略 BaseURL
+	Filter    InstanceFilter    // 默认实例选择条件，可通过 WithInstanceFilter 按调用覆盖
+}
+
+// ServiceClient 内部服务客户端，统一处理地址拼接、认证注入、链路追踪和重试
+type ServiceClient struct {
+	name    string
+	options ServiceClientOptions
+
+	mu        sync.RWMutex
+	instances []ServiceInstance
+}
+
+var (
+	serviceClients   = map[string]*ServiceClient{}
+	serviceClientsMu sync.RWMutex
+)
+
+// NewServiceClient 创建服务客户端
+func NewServiceClient(name string, opt ServiceClientOptions) *ServiceClient {
+	opt.BaseURL = strings.TrimRight(opt.BaseURL, "/")
+	if opt.AuthHeader == "" {
+		opt.AuthHeader = "Authorization"
+	}
+	if opt.AuthScheme == "" {
+		opt.AuthScheme = "Bearer"
+	}
+	if opt.ContentType == "" {
+		opt.ContentType = RequestContentTypeJSON
+	}
+	if opt.RetryInterval <= 0 {
+		opt.RetryInterval = 200 * time.Millisecond
+	}
+	if opt.Credentials == nil && opt.AuthToken != "" {
+		opt.Credentials = StaticToken{Token: opt.AuthToken, Header: opt.AuthHeader, Scheme: opt.AuthScheme}
+	}
+	client := &ServiceClient{name: name, options: opt}
+	client.SetInstances(opt.Instances)
+	return client
+}
+
+// RegisterServiceClient 按服务名注册客户端
+func RegisterServiceClient(name string, opt ServiceClientOptions) *ServiceClient {
+	client := NewServiceClient(name, opt)
+	serviceClientsMu.Lock()
+	serviceClients[name] = client
+	serviceClientsMu.Unlock()
+	return client
+}
+
+// GetServiceClient 获取已注册的服务客户端
+func GetServiceClient(name string) (*ServiceClient, error) {
+	serviceClientsMu.RLock()
+	defer serviceClientsMu.RUnlock()
+	client, ok := serviceClients[name]
+	if !ok {
+		return nil, fmt.Errorf("service client %s not registered", name)
+	}
+	return client, nil
+}
+
+// Name 返回服务名
+func (c *ServiceClient) Name() string {
+	return c.name
+}
+
+// SetInstances 替换服务实例列表，可由服务发现定期刷新
+func (c *ServiceClient) SetInstances(instances []ServiceInstance) {
+	list := make([]ServiceInstance, 0, len(instances))
+	for _, instance := range instances {
+		instance.BaseURL = strings.TrimRight(instance.BaseURL, "/")
+		list = append(list, instance)
+	}
+	c.mu.Lock()
+	c.instances = list
+	c.mu.Unlock()
+}
+
+// Instances 返回服务实例列表
+func (c *ServiceClient) Instances() []ServiceInstance {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]ServiceInstance(nil), c.instances...)
+}
+
+// SelectInstance 按调用上下文或默认条件选择实例，未配置实例时返回 BaseURL
+func (c *ServiceClient) SelectInstance(ctx context.Context) (ServiceInstance, error) {
+	c.mu.RLock()
+	instances := c.instances
+	c.mu.RUnlock()
+	if len(instances) == 0 {
+		return ServiceInstance{BaseURL: c.options.BaseURL}, nil
+	}
+
+	filter, ok := instanceFilterFrom(ctx)
+	if !ok {
+		filter = c.options.Filter
+	}
+	instance, err := SelectInstance(instances, filter)
+	if err != nil {
+		return instance, fmt.Errorf("service %s: %w", c.name, err)
+	}
+	return instance, nil
+}
+
+// URL 拼接服务地址
+func (c *ServiceClient) URL(path string) string {
+	return joinServiceURL(c.options.BaseURL, path)
+}
+
+func joinServiceURL(baseURL, path string) string {
+	if path == "" {
+		return baseURL
+	}
+	return baseURL + "/" + strings.TrimLeft(path, "/")
+}
+
+// Do 发起请求，result 不为 nil 时将 JSON 响应解析到 result
+func (c *ServiceClient) Do(ctx context.Context, method, path string, data interface{}, result interface{}) (resp *HttpResponse, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	headers := make(map[string]string, len(c.options.Headers)+2)
+	for k, v := range c.options.Headers {
+		headers[k] = v
+	}
+
+	// 请求已有 span 时创建客户端 span，上游服务的 span 挂在其下
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		var span trace.Span
+		ctx, span = otel.Tracer("github.com/icreateapp-com/go-zLib/service_client").Start(ctx, method+" "+c.name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("http.request.method", method),
+				attribute.String("peer.service", c.name),
+				attribute.String("url.path", path),
+			))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	// 注入链路追踪上下文
+	carrier := propagation.MapCarrier{}
+	TextMapPropagator().Inject(ctx, carrier)
+	for k, v := range carrier {
+		headers[k] = v
+	}
+
+	contentType := c.options.ContentType
+	if data == nil {
+		contentType = RequestContentTypeRaw
+		data = ""
+	}
+	replayable := replayableRequest(contentType, data)
+
+	for attempt := 0; attempt <= c.options.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return resp, ctx.Err()
+			case <-time.After(time.Duration(attempt) * c.options.RetryInterval):
+			}
+		}
+
+		// 每次尝试重新选择实例，重试时可切换到其它实例
+		instance, selectErr := c.SelectInstance(ctx)
+		if selectErr != nil {
+			return resp, selectErr
+		}
+
+		resp, err = AuthorizedRequest(c.options.Credentials, RequestOptions{
+			URL:         joinServiceURL(instance.BaseURL, path),
+			Method:      method,
+			Headers:     headers,
+			ContentType: contentType,
+			Data:        data,
+			Timeout:     c.options.Timeout,
+			Client:      c.options.Client,
+			Context:     ctx,
+		})
+		// 流式请求体已被消费，重试会发送空的或截断的请求体，直接返回首次的错误
+		if !replayable || !c.retryMethod(method) || !c.shouldRetry(ctx, err) {
+			break
+		}
+	}
+	if err != nil {
+		return resp, fmt.Errorf("service %s: %w", c.name, err)
+	}
+
+	if result != nil && len(resp.Body) > 0 {
+		if err := json.Unmarshal(resp.Body, result); err != nil {
+			return resp, fmt.Errorf("service %s: decode response failed: %w", c.name, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// shouldRetry 判断是否需要重试，按 IsRetryable 分类：网络错误、5xx、408、429 重试
+func (c *ServiceClient) shouldRetry(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	return IsRetryable(err)
+}
+
+// retryMethod 非幂等方法未开启 RetryUnsafe 时不重试，避免请求已被处理后重复执行
+// 断点续传按偏移量校验，可安全重试，不经过此判断
+func (c *ServiceClient) retryMethod(method string) bool {
+	return c.options.RetryUnsafe || isIdempotentMethod(method)
+}
+
+// isIdempotentMethod 判断是否为幂等方法（RFC 9110）
+func isIdempotentMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// Get 发起 GET 请求
+func (c *ServiceClient) Get(ctx context.Context, path string, result interface{}) error {
+	_, err := c.Do(ctx, http.MethodGet, path, nil, result)
+	return err
+}
+
+// Post 发起 POST 请求
+func (c *ServiceClient) Post(ctx context.Context, path string, data interface{}, result interface{}) error {
+	_, err := c.Do(ctx, http.MethodPost, path, data, result)
+	return err
+}
+
+// Put 发起 PUT 请求
+func (c *ServiceClient) Put(ctx context.Context, path string, data interface{}, result interface{}) error {
+	_, err := c.Do(ctx, http.MethodPut, path, data, result)
+	return err
+}
+
+// Delete 发起 DELETE 请求
+func (c *ServiceClient) Delete(ctx context.Context, path string, result interface{}) error {
+	_, err := c.Do(ctx, http.MethodDelete, path, nil, result)
+	return err
+}
+
+// ServiceDo 泛型请求方法，返回解析后的响应数据
+func ServiceDo[T any](ctx context.Context, c *ServiceClient, method, path string, data interface{}) (T, error) {
+	var result T
+	_, err := c.Do(ctx, method, path, data, &result)
+	return result, err
+}
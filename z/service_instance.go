@@ -0,0 +1,115 @@
+package z
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+)
+
+// ErrNoServiceInstance 没有符合条件的服务实例
+var ErrNoServiceInstance = errors.New("no available service instance")
+
+// ServiceInstance 服务实例
+type ServiceInstance struct {
+	BaseURL  string            `json:"base_url"` // 实例地址，如 http://10.0.0.1:8080/api
+	Weight   int               `json:"weight"`   // 权重，<= 0 按 1 处理
+	Zone     string            `json:"zone"`     // 所在可用区
+	Tags     map[string]string `json:"tags"`     // 标签，如 canary=true
+	Draining bool              `json:"draining"` // 下线中的实例不再接收新请求
+}
+
+// InstanceFilter 实例选择条件
+type InstanceFilter struct {
+	Zone            string            // 优先选择同可用区实例，同区无可用实例时回退到其它区
+	Tags            map[string]string // 实例标签必须全部匹配，未设置的标签按空字符串比较
+	ExcludeTags     map[string]string // 命中任一标签的实例被排除，如 {"canary": "true"} 排除灰度实例
+	IncludeDraining bool              // 是否允许选择下线中的实例
+}
+
+type instanceFilterKey struct{}
+
+// WithInstanceFilter 为单次调用指定实例选择条件，覆盖客户端默认条件
+func WithInstanceFilter(ctx context.Context, filter InstanceFilter) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, instanceFilterKey{}, filter)
+}
+
+// instanceFilterFrom 获取调用指定的实例选择条件
+func instanceFilterFrom(ctx context.Context) (InstanceFilter, bool) {
+	if ctx == nil {
+		return InstanceFilter{}, false
+	}
+	filter, ok := ctx.Value(instanceFilterKey{}).(InstanceFilter)
+	return filter, ok
+}
+
+// Match 判断实例是否满足标签和下线条件（不含可用区偏好）
+func (f InstanceFilter) Match(instance ServiceInstance) bool {
+	if instance.Draining && !f.IncludeDraining {
+		return false
+	}
+	for k, v := range f.Tags {
+		if instance.Tags[k] != v {
+			return false
+		}
+	}
+	for k, v := range f.ExcludeTags {
+		if value, ok := instance.Tags[k]; ok && value == v {
+			return false
+		}
+	}
+	return strings.TrimSpace(instance.BaseURL) != ""
+}
+
+// SelectInstance 按条件筛选实例，优先同可用区，再按权重随机选择
+func SelectInstance(instances []ServiceInstance, filter InstanceFilter) (ServiceInstance, error) {
+	candidates := make([]ServiceInstance, 0, len(instances))
+	for _, instance := range instances {
+		if filter.Match(instance) {
+			candidates = append(candidates, instance)
+		}
+	}
+	if len(candidates) == 0 {
+		return ServiceInstance{}, ErrNoServiceInstance
+	}
+
+	if filter.Zone != "" {
+		local := make([]ServiceInstance, 0, len(candidates))
+		for _, instance := range candidates {
+			if instance.Zone == filter.Zone {
+				local = append(local, instance)
+			}
+		}
+		if len(local) > 0 {
+			candidates = local
+		}
+	}
+
+	return weightedPick(candidates), nil
+}
+
+// weightedPick 按权重随机选择
+func weightedPick(instances []ServiceInstance) ServiceInstance {
+	total := 0
+	for _, instance := range instances {
+		total += instanceWeight(instance)
+	}
+	n := rand.Intn(total)
+	for _, instance := range instances {
+		n -= instanceWeight(instance)
+		if n < 0 {
+			return instance
+		}
+	}
+	return instances[len(instances)-1]
+}
+
+func instanceWeight(instance ServiceInstance) int {
+	if instance.Weight <= 0 {
+		return 1
+	}
+	return instance.Weight
+}
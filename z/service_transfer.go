@@ -0,0 +1,622 @@
+package z
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 分块传输请求头
+const (
+	TransferHeaderOffset    = "X-Transfer-Offset"    // 分块在文件中的起始位置
+	TransferHeaderChecksum  = "X-Transfer-Checksum"  // 请求体 SHA-256（hex）
+	TransferHeaderTimestamp = "X-Transfer-Timestamp" // Unix 秒
+	TransferHeaderSignature = "X-Transfer-Signature" // sha256=<hex>，见 SignTransfer
+)
+
+var (
+	ErrTransferOffsetMismatch = errors.New("transfer: receiver offset does not match sent data")
+	ErrTransferChecksum       = errors.New("transfer: checksum mismatch")
+)
+
+// transferIDPattern 传输 ID 同时用作文件名，限制字符集防止路径穿越
+var transferIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// TransferStatus 接收端的传输状态
+type TransferStatus struct {
+	ID       string `json:"id"`
+	Received int64  `json:"received"`         // 已接收的连续字节数，发送端从此处续传
+	Complete bool   `json:"complete"`         // 已完成合并并交给 OnComplete
+	SHA256   string `json:"sha256,omitempty"` // 完成后的整体校验和
+}
+
+// TransferOptions 发送选项
+type TransferOptions struct {
+	ID        string              // 传输 ID，相同 ID 可在中断后续传；为空时生成随机 ID（不支持跨进程续传）
+	Secret    string              // 签名密钥，与接收端一致
+	ChunkSize int                 // 分块大小，默认 4MB
+	Size      int64               // 总大小（可选），仅用于进度回调
+	Meta      map[string]string   // 附加信息，完成时传给接收端，如文件名、内容类型
+	Retries   int                 // 单个请求的重试次数，默认 3，仅重试网络错误和 5xx 等可重试响应
+	Timeout   time.Duration       // 单个请求超时，默认 60 秒
+	Progress  RequestProgressFunc // 进度回调（可选），total 未知时为 -1
+}
+
+// transferCompleteRequest 完成请求
+type transferCompleteRequest struct {
+	Size   int64             `json:"size"`
+	SHA256 string            `json:"sha256"`
+	Meta   map[string]string `json:"meta,omitempty"`
+}
+
+// SignTransfer 计算分块传输请求签名，覆盖方法、传输 ID、偏移、请求体校验和与时间戳
+func SignTransfer(secret, timestamp, method, id, offset, checksum string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{timestamp, method, id, offset, checksum}, ".")))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Transfer 将 r 分块发送到接收端 path（由 TransferReceiver.Register 注册），用于服务间传输大文件，替代超时的 JSON 调用
+// 传输开始时选定一个实例并固定使用，分块失败按 Retries 重试；以相同 ID 重新调用时从接收端已接收的位置续传，
+// 已接收部分仍需从 r 读出以计算整体校验和
+//
+//	status, err := client.Transfer(ctx, "/internal/blobs", file, z.TransferOptions{
+//		ID:     "export-" + jobID,
+//		Secret: secret,
+//		Meta:   map[string]string{"name": "export.csv"},
+//	})
+func (c *ServiceClient) Transfer(ctx context.Context, path string, r io.Reader, opt TransferOptions) (status TransferStatus, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opt.Secret == "" {
+		return status, errors.New("transfer: secret is required")
+	}
+	if opt.ID == "" {
+		opt.ID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+	if !transferIDPattern.MatchString(opt.ID) {
+		return status, fmt.Errorf("transfer: invalid id %q", opt.ID)
+	}
+	if opt.ChunkSize <= 0 {
+		opt.ChunkSize = 4 << 20
+	}
+	if opt.Retries <= 0 {
+		opt.Retries = 3
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 60 * time.Second
+	}
+
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		var span trace.Span
+		ctx, span = otel.Tracer("github.com/icreateapp-com/go-zLib/service_client").Start(ctx, "transfer "+c.name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("peer.service", c.name),
+				attribute.String("url.path", path),
+				attribute.String("transfer.id", opt.ID),
+			))
+		defer func() {
+			span.SetAttributes(attribute.Int64("transfer.size", status.Received))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	// 分块状态保存在接收实例本地，整个传输固定使用同一实例
+	instance, err := c.SelectInstance(ctx)
+	if err != nil {
+		return status, err
+	}
+	t := &transferSender{
+		client: c,
+		url:    joinServiceURL(instance.BaseURL, path) + "/" + opt.ID,
+		opt:    opt,
+	}
+	if err := t.run(ctx, r); err != nil {
+		return status, fmt.Errorf("service %s: %w", c.name, err)
+	}
+	return t.status, nil
+}
+
+// transferSender 单次传输的发送状态
+type transferSender struct {
+	client *ServiceClient
+	url    string
+	opt    TransferOptions
+	status TransferStatus
+}
+
+func (t *transferSender) run(ctx context.Context, r io.Reader) error {
+	st, err := t.request(ctx, http.MethodGet, "", "", nil)
+	if err != nil {
+		return err
+	}
+	received := st.Received
+
+	total := t.opt.Size
+	if total <= 0 {
+		total = -1
+	}
+	hash := sha256.New()
+	buf := make([]byte, t.opt.ChunkSize)
+	var offset int64
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			chunk := buf[:n]
+			hash.Write(chunk)
+			start := offset
+			offset += int64(n)
+			// 跳过接收端已有的部分
+			if offset > received {
+				if skip := received - start; skip > 0 {
+					start, chunk = received, chunk[skip:]
+				}
+				if received, err = t.sendChunk(ctx, start, chunk); err != nil {
+					return err
+				}
+			}
+			if t.opt.Progress != nil {
+				t.opt.Progress(offset, total)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	if received != offset {
+		return ErrTransferOffsetMismatch
+	}
+
+	body, err := json.Marshal(transferCompleteRequest{Size: offset, SHA256: hex.EncodeToString(hash.Sum(nil)), Meta: t.opt.Meta})
+	if err != nil {
+		return err
+	}
+	st, err = t.request(ctx, http.MethodPost, "/complete", "", body)
+	if err != nil {
+		return err
+	}
+	t.status = st
+	return nil
+}
+
+// sendChunk 发送分块，接收端返回 409 时按其实际偏移调整后继续，返回接收端最新偏移
+func (t *transferSender) sendChunk(ctx context.Context, offset int64, chunk []byte) (int64, error) {
+	for {
+		st, err := t.request(ctx, http.MethodPost, "", strconv.FormatInt(offset, 10), chunk)
+		if err == nil {
+			return st.Received, nil
+		}
+		var statusErr *HttpStatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusConflict {
+			return 0, err
+		}
+		// 上次请求已写入但响应丢失等情况，接收端偏移落在本分块内时跳过已接收部分
+		st, ok := decodeTransferStatus(statusErr.Body)
+		if !ok || st.Received <= offset || st.Received > offset+int64(len(chunk)) {
+			return 0, ErrTransferOffsetMismatch
+		}
+		chunk = chunk[st.Received-offset:]
+		offset = st.Received
+		if len(chunk) == 0 {
+			return offset, nil
+		}
+	}
+}
+
+// request 发起签名请求，可重试的错误按 Retries 重试
+func (t *transferSender) request(ctx context.Context, method, suffix, offset string, body []byte) (TransferStatus, error) {
+	c := t.client
+	sum := sha256.Sum256(body)
+	checksum := hex.EncodeToString(sum[:])
+
+	var resp *HttpResponse
+	var err error
+	for attempt := 0; attempt <= t.opt.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return TransferStatus{}, ctx.Err()
+			case <-time.After(time.Duration(attempt) * c.options.RetryInterval):
+			}
+		}
+
+		headers := make(map[string]string, len(c.options.Headers)+6)
+		for k, v := range c.options.Headers {
+			headers[k] = v
+		}
+		carrier := propagation.MapCarrier{}
+		TextMapPropagator().Inject(ctx, carrier)
+		for k, v := range carrier {
+			headers[k] = v
+		}
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers[TransferHeaderTimestamp] = timestamp
+		headers[TransferHeaderChecksum] = checksum
+		headers[TransferHeaderSignature] = SignTransfer(t.opt.Secret, timestamp, method, t.opt.ID, offset, checksum)
+		if offset != "" {
+			headers[TransferHeaderOffset] = offset
+		}
+
+		opt := RequestOptions{
+			URL:         t.url + suffix,
+			Method:      method,
+			Headers:     headers,
+			ContentType: RequestContentTypeBinary,
+			Data:        body,
+			Timeout:     t.opt.Timeout,
+			Client:      c.options.Client,
+			Context:     ctx,
+		}
+		if body == nil {
+			opt.ContentType, opt.Data = RequestContentTypeRaw, ""
+		}
+		resp, err = AuthorizedRequest(c.options.Credentials, opt)
+		if !c.shouldRetry(ctx, err) {
+			break
+		}
+	}
+	if err != nil {
+		return TransferStatus{}, err
+	}
+	st, ok := decodeTransferStatus(resp.Body)
+	if !ok {
+		return TransferStatus{}, fmt.Errorf("transfer: invalid response: %s", string(resp.Body))
+	}
+	return st, nil
+}
+
+// decodeTransferStatus 解析接收端以 Success / Failure 输出的状态
+func decodeTransferStatus(body []byte) (TransferStatus, bool) {
+	var res struct {
+		Message TransferStatus `json:"message"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil || res.Message.ID == "" {
+		return TransferStatus{}, false
+	}
+	return res.Message, true
+}
+
+// TransferFile 接收完成的文件
+type TransferFile struct {
+	ID     string
+	Path   string // 临时文件路径，OnComplete 返回后删除，需要保留时在 OnComplete 中移动
+	Size   int64
+	SHA256 string
+	Meta   map[string]string
+}
+
+// TransferReceiverOptions 接收选项
+type TransferReceiverOptions struct {
+	Secret       string        // 签名密钥，与发送端一致
+	Dir          string        // 分块暂存目录，默认 系统临时目录/zlib-transfer
+	MaxChunkSize int64         // 单个分块上限，默认 16MB
+	MaxSize      int64         // 单个文件上限，0 表示不限制
+	MaxSkew      time.Duration // 签名时间戳允许的偏差，默认 5 分钟
+	// OnComplete 文件合并完成并校验通过后调用，返回错误时保留文件，发送端重试完成请求时再次调用
+	OnComplete func(ctx context.Context, file TransferFile) error
+}
+
+// TransferReceiver 分块传输接收端，与 ServiceClient.Transfer 配对使用
+// 已接收的分块写入 Dir 下的 <id>.part，完成后写入 <id>.done 记录状态以便重复的完成请求返回成功
+type TransferReceiver struct {
+	opt TransferReceiverOptions
+
+	mu    sync.Mutex
+	locks map[string]*transferLock
+}
+
+// transferLock 按传输 ID 的锁，无人持有时从 locks 中删除
+type transferLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewTransferReceiver 创建接收端
+func NewTransferReceiver(opt TransferReceiverOptions) (*TransferReceiver, error) {
+	if opt.Secret == "" {
+		return nil, errors.New("transfer: secret is required")
+	}
+	if opt.OnComplete == nil {
+		return nil, errors.New("transfer: OnComplete is required")
+	}
+	if opt.Dir == "" {
+		opt.Dir = filepath.Join(os.TempDir(), "zlib-transfer")
+	}
+	if opt.MaxChunkSize <= 0 {
+		opt.MaxChunkSize = 16 << 20
+	}
+	if opt.MaxSkew <= 0 {
+		opt.MaxSkew = 5 * time.Minute
+	}
+	if err := os.MkdirAll(opt.Dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &TransferReceiver{opt: opt, locks: map[string]*transferLock{}}, nil
+}
+
+// Register 在 path 下注册接收路由：GET 查询状态、POST 写入分块、POST /complete 完成、DELETE 放弃
+//
+//	receiver.Register(r.Group("/internal"), "/blobs")
+func (rcv *TransferReceiver) Register(r gin.IRoutes, path string) {
+	path = strings.TrimRight(path, "/")
+	r.GET(path+"/:id", rcv.handleStatus)
+	r.POST(path+"/:id", rcv.handleChunk)
+	r.POST(path+"/:id/complete", rcv.handleComplete)
+	r.DELETE(path+"/:id", rcv.handleAbort)
+}
+
+// Cleanup 删除超过 olderThan 未更新的分块和完成记录，可通过 scheduler 定期执行
+func (rcv *TransferReceiver) Cleanup(olderThan time.Duration) (int, error) {
+	entries, err := os.ReadDir(rcv.opt.Dir)
+	if err != nil {
+		return 0, err
+	}
+	deadline := time.Now().Add(-olderThan)
+	removed := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		id := strings.TrimSuffix(strings.TrimSuffix(name, ".part"), ".done")
+		if id == name || !transferIDPattern.MatchString(id) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(deadline) {
+			continue
+		}
+		unlock := rcv.lock(id)
+		if os.Remove(filepath.Join(rcv.opt.Dir, name)) == nil {
+			removed++
+		}
+		unlock()
+	}
+	return removed, nil
+}
+
+func (rcv *TransferReceiver) handleStatus(c *gin.Context) {
+	id, ok := rcv.verify(c, nil)
+	if !ok {
+		return
+	}
+	defer rcv.lock(id)()
+	Success(c, rcv.status(id))
+}
+
+func (rcv *TransferReceiver) handleChunk(c *gin.Context) {
+	offset, err := strconv.ParseInt(c.GetHeader(TransferHeaderOffset), 10, 64)
+	if err != nil || offset < 0 {
+		Failure(c, "invalid transfer offset", StatusBadRequest, http.StatusBadRequest)
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, rcv.opt.MaxChunkSize)
+	id, ok := rcv.verify(c, nil)
+	if !ok {
+		return
+	}
+	defer rcv.lock(id)()
+
+	st := rcv.status(id)
+	if st.Complete || st.Received != offset {
+		Failure(c, st, StatusConflict, http.StatusConflict)
+		return
+	}
+
+	part := rcv.path(id, ".part")
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		Failure(c, err, StatusInternalError, http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	var src io.Reader = body
+	if rcv.opt.MaxSize > 0 {
+		src = io.LimitReader(body, rcv.opt.MaxSize-offset+1)
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), src)
+	// 写入失败或校验不通过时截断到写入前的位置，发送端从原偏移重试
+	rollback := func() { _ = f.Truncate(offset) }
+	if err != nil {
+		rollback()
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			Failure(c, "transfer chunk too large", StatusPayloadTooLarge, http.StatusRequestEntityTooLarge)
+			return
+		}
+		Failure(c, err, StatusBadRequest, http.StatusBadRequest)
+		return
+	}
+	if rcv.opt.MaxSize > 0 && offset+n > rcv.opt.MaxSize {
+		rollback()
+		Failure(c, "transfer exceeds max size", StatusPayloadTooLarge, http.StatusRequestEntityTooLarge)
+		return
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != c.GetHeader(TransferHeaderChecksum) {
+		rollback()
+		Failure(c, ErrTransferChecksum, StatusBadRequest, http.StatusBadRequest)
+		return
+	}
+	Success(c, TransferStatus{ID: id, Received: offset + n})
+}
+
+func (rcv *TransferReceiver) handleComplete(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		Failure(c, err, StatusBadRequest, http.StatusBadRequest)
+		return
+	}
+	id, ok := rcv.verify(c, body)
+	if !ok {
+		return
+	}
+	var req transferCompleteRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		Failure(c, err, StatusBadRequest, http.StatusBadRequest)
+		return
+	}
+	defer rcv.lock(id)()
+
+	// 重复的完成请求（上次响应丢失）直接返回结果
+	st := rcv.status(id)
+	if st.Complete {
+		if st.SHA256 != req.SHA256 {
+			Failure(c, st, StatusConflict, http.StatusConflict)
+			return
+		}
+		Success(c, st)
+		return
+	}
+	if st.Received != req.Size {
+		Failure(c, st, StatusConflict, http.StatusConflict)
+		return
+	}
+
+	part := rcv.path(id, ".part")
+	sum, err := fileSHA256(part)
+	if err != nil {
+		Failure(c, err, StatusInternalError, http.StatusInternalServerError)
+		return
+	}
+	if sum != req.SHA256 {
+		// 整体校验失败说明数据已损坏，丢弃后由发送端重新传输
+		_ = os.Remove(part)
+		Failure(c, ErrTransferChecksum, StatusBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	file := TransferFile{ID: id, Path: part, Size: req.Size, SHA256: sum, Meta: req.Meta}
+	if err := rcv.opt.OnComplete(c.Request.Context(), file); err != nil {
+		Failure(c, err, StatusInternalError, http.StatusInternalServerError)
+		return
+	}
+	_ = os.Remove(part)
+
+	st = TransferStatus{ID: id, Received: req.Size, Complete: true, SHA256: sum}
+	if done, err := json.Marshal(st); err == nil {
+		_ = os.WriteFile(rcv.path(id, ".done"), done, 0o600)
+	}
+	Success(c, st)
+}
+
+func (rcv *TransferReceiver) handleAbort(c *gin.Context) {
+	id, ok := rcv.verify(c, nil)
+	if !ok {
+		return
+	}
+	defer rcv.lock(id)()
+	_ = os.Remove(rcv.path(id, ".part"))
+	Success(c, TransferStatus{ID: id})
+}
+
+// verify 校验传输 ID 与签名；body 为空时校验和由请求头给出，分块内容在写入时校验
+func (rcv *TransferReceiver) verify(c *gin.Context, body []byte) (string, bool) {
+	id := c.Param("id")
+	if !transferIDPattern.MatchString(id) {
+		Failure(c, "invalid transfer id", StatusBadRequest, http.StatusBadRequest)
+		return "", false
+	}
+	checksum := c.GetHeader(TransferHeaderChecksum)
+	if body != nil || c.Request.Method != http.MethodPost {
+		sum := sha256.Sum256(body)
+		if hex.EncodeToString(sum[:]) != checksum {
+			Failure(c, ErrTransferChecksum, StatusBadRequest, http.StatusBadRequest)
+			return "", false
+		}
+	}
+	timestamp := c.GetHeader(TransferHeaderTimestamp)
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(ts, 0)).Abs() > rcv.opt.MaxSkew {
+		Failure(c, "invalid transfer timestamp", StatusUnauthorized, http.StatusUnauthorized)
+		return "", false
+	}
+	expected := SignTransfer(rcv.opt.Secret, timestamp, c.Request.Method, id, c.GetHeader(TransferHeaderOffset), checksum)
+	if !hmac.Equal([]byte(expected), []byte(c.GetHeader(TransferHeaderSignature))) {
+		Failure(c, "invalid transfer signature", StatusUnauthorized, http.StatusUnauthorized)
+		return "", false
+	}
+	return id, true
+}
+
+// status 读取传输状态，需持有 id 对应的锁
+func (rcv *TransferReceiver) status(id string) TransferStatus {
+	if done, err := os.ReadFile(rcv.path(id, ".done")); err == nil {
+		var st TransferStatus
+		if json.Unmarshal(done, &st) == nil && st.Complete {
+			return st
+		}
+	}
+	st := TransferStatus{ID: id}
+	if info, err := os.Stat(rcv.path(id, ".part")); err == nil {
+		st.Received = info.Size()
+	}
+	return st
+}
+
+func (rcv *TransferReceiver) path(id, ext string) string {
+	return filepath.Join(rcv.opt.Dir, id+ext)
+}
+
+// lock 按传输 ID 加锁，返回解锁函数
+func (rcv *TransferReceiver) lock(id string) func() {
+	rcv.mu.Lock()
+	l, ok := rcv.locks[id]
+	if !ok {
+		l = &transferLock{}
+		rcv.locks[id] = l
+	}
+	l.refs++
+	rcv.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		rcv.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(rcv.locks, id)
+		}
+		rcv.mu.Unlock()
+	}
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
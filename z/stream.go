@@ -1,16 +1,41 @@
 package z
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
 )
 
+// ErrStreamClosed 流已结束或客户端已断开
+var ErrStreamClosed = errors.New("stream closed")
+
+// StreamEvent SSE 事件
+type StreamEvent struct {
+	ID    string        // 事件 ID，客户端重连时通过 Last-Event-ID 回传
+	Event string        // 事件名称，为空时客户端按 message 处理
+	Data  string        // 事件数据，多行数据会拆分为多个 data 字段
+	Retry time.Duration // 客户端重连间隔
+}
+
 type StreamSender struct {
 	Context *gin.Context
 	flusher http.Flusher
+
+	mu            sync.Mutex
+	closed        bool
+	dirty         bool
+	flushInterval time.Duration
+	stop          chan struct{}
+	onDisconnect  []func()
+	disconnected  bool
 }
 
 // NewStreamSender SetHeaders 设置响应头
@@ -27,10 +52,199 @@ func NewStreamSender(ctx *gin.Context) *StreamSender {
 	ctx.Writer.Header().Del("Content-Length")
 	ctx.Writer.WriteHeader(http.StatusOK)
 
-	return &StreamSender{
+	s := &StreamSender{
 		Context: ctx,
 		flusher: f,
+		stop:    make(chan struct{}),
+	}
+	s.watchDisconnect()
+	return s
+}
+
+// LastEventID 返回客户端重连时携带的 Last-Event-ID
+func (e *StreamSender) LastEventID() string {
+	if e.Context == nil || e.Context.Request == nil {
+		return ""
+	}
+	return e.Context.Request.Header.Get("Last-Event-ID")
+}
+
+// OnDisconnect 注册客户端断开回调，流正常结束时不会触发
+func (e *StreamSender) OnDisconnect(fn func()) {
+	if fn == nil {
+		return
+	}
+	e.mu.Lock()
+	if e.disconnected {
+		e.mu.Unlock()
+		fn()
+		return
+	}
+	e.onDisconnect = append(e.onDisconnect, fn)
+	e.mu.Unlock()
+}
+
+// KeepAlive 按间隔发送注释行保活，避免代理因空闲断开连接
+func (e *StreamSender) KeepAlive(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				if err := e.SendComment("keep-alive"); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// SetFlushInterval 开启写入合并：消息先写入缓冲，按间隔统一刷新；d <= 0 时每条消息立即刷新
+func (e *StreamSender) SetFlushInterval(d time.Duration) {
+	e.mu.Lock()
+	start := e.flushInterval <= 0 && d > 0
+	e.flushInterval = d
+	e.mu.Unlock()
+	if !start {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				e.mu.Lock()
+				if e.dirty && !e.closed && e.flusher != nil {
+					e.flusher.Flush()
+					e.dirty = false
+				}
+				e.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// Send 发送 SSE 事件
+func (e *StreamSender) Send(event StreamEvent) error {
+	var b strings.Builder
+	if event.ID != "" {
+		b.WriteString("id: ")
+		b.WriteString(sanitizeStreamField(event.ID))
+		b.WriteByte('\n')
+	}
+	if event.Event != "" {
+		b.WriteString("event: ")
+		b.WriteString(sanitizeStreamField(event.Event))
+		b.WriteByte('\n')
+	}
+	if event.Retry > 0 {
+		b.WriteString("retry: ")
+		b.WriteString(strconv.FormatInt(event.Retry.Milliseconds(), 10))
+		b.WriteByte('\n')
+	}
+	data := strings.ReplaceAll(event.Data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	return e.write([]byte(b.String()))
+}
+
+// SendEvent 发送指定名称的事件
+func (e *StreamSender) SendEvent(event string, data string) error {
+	return e.Send(StreamEvent{Event: event, Data: data})
+}
+
+// SendJSON 将 v 序列化为 JSON 后发送
+func (e *StreamSender) SendJSON(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return e.Send(StreamEvent{Event: event, Data: string(data)})
+}
+
+// SendBinary 以 base64 编码发送二进制数据
+func (e *StreamSender) SendBinary(event string, data []byte) error {
+	return e.Send(StreamEvent{Event: event, Data: base64.StdEncoding.EncodeToString(data)})
+}
+
+// SendComment 发送注释行，客户端不会触发事件
+func (e *StreamSender) SendComment(text string) error {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		b.WriteString(": ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	return e.write([]byte(b.String()))
+}
+
+// write 写入并按合并策略刷新
+func (e *StreamSender) write(data []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed || e.disconnected {
+		return ErrStreamClosed
+	}
+	if err := e.writeData(data); err != nil {
+		return err
+	}
+	if e.flusher == nil {
+		return nil
 	}
+	if e.flushInterval > 0 {
+		e.dirty = true
+		return nil
+	}
+	e.flusher.Flush()
+	return nil
+}
+
+// watchDisconnect 监听请求上下文，客户端断开时触发回调并停止后台任务
+func (e *StreamSender) watchDisconnect() {
+	if e.Context == nil || e.Context.Request == nil {
+		return
+	}
+	done := e.Context.Request.Context().Done()
+	go func() {
+		select {
+		case <-e.stop:
+			return
+		case <-done:
+		}
+		e.mu.Lock()
+		if e.closed {
+			e.mu.Unlock()
+			return
+		}
+		e.disconnected = true
+		callbacks := e.onDisconnect
+		e.onDisconnect = nil
+		e.mu.Unlock()
+		for _, fn := range callbacks {
+			fn()
+		}
+	}()
+}
+
+// sanitizeStreamField 去除字段中的换行，避免破坏事件格式
+func sanitizeStreamField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
 }
 
 // writeData 写入数据到响应流
@@ -56,34 +270,29 @@ func (e *StreamSender) writeData(data []byte) error {
 
 // SendMessage 发送普通消息
 func (e *StreamSender) SendMessage(message string) {
-	if e.Context == nil || e.Context.Writer == nil {
-		fmt.Println("stream error: context or writer is nil in SendMessage")
-		return
-	}
-	data := []byte("event: message\ndata: " + message + "\n\n")
-	if err := e.writeData(data); err != nil {
+	if err := e.Send(StreamEvent{Event: "message", Data: message}); err != nil {
 		fmt.Printf("stream error: SendMessage failed: %v", err)
-		return
 	}
-	e.flusher.Flush()
 }
 
 // SendError 发送错误消息
 func (e *StreamSender) SendError(errMsg string) {
-	if e.Context == nil || e.Context.Writer == nil {
-		fmt.Println("stream error: context or writer is nil in SendError")
-		return
-	}
-	data := []byte("event: error\ndata: " + errMsg + "\n\n")
-	if err := e.writeData(data); err != nil {
+	if err := e.Send(StreamEvent{Event: "error", Data: errMsg}); err != nil {
 		fmt.Printf("stream error: SendError failed: %v", err)
-		return
 	}
-	e.flusher.Flush()
 }
 
 // Done 结束流式响应
 func (e *StreamSender) Done() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return
+	}
+	e.closed = true
+	close(e.stop)
+
 	if e.flusher == nil {
 		fmt.Println("stream error: flusher not initialized")
 		return
@@ -0,0 +1,109 @@
+package z
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// 常用 baggage 键
+const (
+	BaggageTenantID = "tenant_id"
+	BaggageUserID   = "user_id"
+	BaggageGuard    = "guard"
+)
+
+// IdentityBaggageKeys 身份相关的 baggage 键，仅由认证结果写入，入口处移除客户端传入的值
+var IdentityBaggageKeys = []string{BaggageTenantID, BaggageUserID, BaggageGuard}
+
+// inboundIdentityKey 保存被移除的入站身份 baggage 的 context 键
+type inboundIdentityKey struct{}
+
+// TextMapPropagator 返回全局传播器并始终包含 baggage，未启用链路追踪时也能跨服务传递
+func TextMapPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(otel.GetTextMapPropagator(), propagation.Baggage{})
+}
+
+// WithBaggage 将键值写入 context 的 baggage，值为空时删除该键，非法的键值会被忽略
+func WithBaggage(ctx context.Context, values map[string]string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	bag := baggage.FromContext(ctx)
+	for key, value := range values {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if value == "" {
+			bag = bag.DeleteMember(key)
+			continue
+		}
+		member, err := baggage.NewMemberRaw(key, value)
+		if err != nil {
+			continue
+		}
+		if next, err := bag.SetMember(member); err == nil {
+			bag = next
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// SetBaggage 写入单个 baggage 键值
+func SetBaggage(ctx context.Context, key string, value interface{}) context.Context {
+	return WithBaggage(ctx, map[string]string{key: fmt.Sprint(value)})
+}
+
+// BaggageValue 读取 baggage 中的值，不存在时返回空字符串
+func BaggageValue(ctx context.Context, key string) string {
+	if ctx == nil {
+		return ""
+	}
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// BaggageValues 返回全部 baggage 键值，便于日志与指标标签
+func BaggageValues(ctx context.Context) map[string]string {
+	out := map[string]string{}
+	if ctx == nil {
+		return out
+	}
+	for _, member := range baggage.FromContext(ctx).Members() {
+		out[member.Key()] = member.Value()
+	}
+	return out
+}
+
+// StripIdentityBaggage 移除从请求中提取的身份 baggage，避免公开路由及认证失败的请求伪造 tenant_id、user_id 并被转发到下游
+// 原值另存于 context，仅配置 trust_baggage 的 guard 认证成功后才会恢复，见 InboundIdentityBaggage
+func StripIdentityBaggage(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	bag := baggage.FromContext(ctx)
+	inbound := map[string]string{}
+	for _, key := range IdentityBaggageKeys {
+		if value := bag.Member(key).Value(); value != "" {
+			inbound[key] = value
+			bag = bag.DeleteMember(key)
+		}
+	}
+	if len(inbound) == 0 {
+		return ctx
+	}
+	return context.WithValue(baggage.ContextWithBaggage(ctx, bag), inboundIdentityKey{}, inbound)
+}
+
+// InboundIdentityBaggage 返回被 StripIdentityBaggage 移除的入站身份 baggage，值未经认证，不可直接信任
+func InboundIdentityBaggage(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	inbound, _ := ctx.Value(inboundIdentityKey{}).(map[string]string)
+	return inbound
+}
@@ -0,0 +1,74 @@
+package z
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClickHouseOptions ClickHouse HTTP 接口写入配置
+type ClickHouseOptions struct {
+	URL      string // HTTP 接口地址，如 http://127.0.0.1:8123
+	Database string // 为空时使用账号的默认库
+	Table    string
+	Username string
+	Password string
+	Client   *http.Client // 为空时使用 30 秒超时的默认客户端
+}
+
+// ClickHouseInsert 返回批量写入器的写入函数，每批记录以 JSONEachRow 格式通过 HTTP 接口插入，字段名取 json 标签
+// 不依赖 ClickHouse 驱动；5xx 及网络错误可由 BatchWriterOptions.Retries 重试
+//
+//	w := z.NewBatchWriter(z.ClickHouseInsert[Event](z.ClickHouseOptions{URL: "http://ch:8123", Database: "analytics", Table: "events"}),
+//		z.BatchWriterOptions{Name: "events", MaxSize: 5000, FlushInterval: 2 * time.Second, Retries: 3})
+func ClickHouseInsert[T any](opts ClickHouseOptions) BatchFlushFunc[T] {
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	table := "`" + strings.ReplaceAll(opts.Table, "`", "``") + "`"
+	if opts.Database != "" {
+		table = "`" + strings.ReplaceAll(opts.Database, "`", "``") + "`." + table
+	}
+	endpoint := strings.TrimRight(opts.URL, "/") + "/?query=" + url.QueryEscape("INSERT INTO "+table+" FORMAT JSONEachRow")
+
+	return func(ctx context.Context, items []T) error {
+		if opts.Table == "" {
+			return errors.New("clickhouse: table is required")
+		}
+		var body bytes.Buffer
+		enc := json.NewEncoder(&body)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return Permanent(err)
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+		if err != nil {
+			return Permanent(err)
+		}
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		if opts.Username != "" {
+			req.Header.Set("X-ClickHouse-User", opts.Username)
+			req.Header.Set("X-ClickHouse-Key", opts.Password)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			return &HttpStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: msg}
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+}
@@ -0,0 +1,358 @@
+package z
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrBatchWriterClosed 写入器已关闭
+var ErrBatchWriterClosed = errors.New("batch writer closed")
+
+// ErrBatchWriterFull 缓冲区已满，记录被丢弃
+var ErrBatchWriterFull = errors.New("batch writer buffer full")
+
+// BatchFlushFunc 将一批记录写入存储，如 db_provider.BatchInsert、ClickHouseInsert
+type BatchFlushFunc[T any] func(ctx context.Context, items []T) error
+
+// BatchOverflow 缓冲区已满时的处理策略
+type BatchOverflow string
+
+const (
+	BatchOverflowDropNewest BatchOverflow = "drop_newest" // 丢弃新记录（默认），Write 返回 ErrBatchWriterFull
+	BatchOverflowDropOldest BatchOverflow = "drop_oldest" // 丢弃缓冲区中最早的记录
+	BatchOverflowBlock      BatchOverflow = "block"       // 阻塞调用方直到有空间或 ctx 结束
+)
+
+// BatchWriterOptions 批量写入器配置
+type BatchWriterOptions struct {
+	Name          string        // 指标中的写入器名称，如 request_log
+	MaxSize       int           // 每批最大记录数，达到时立即写入，默认 500
+	FlushInterval time.Duration // 首条记录进入批次后的最长等待时间，默认 1s
+	QueueSize     int           // 缓冲的最大记录数，默认 MaxSize * 10
+	Overflow      BatchOverflow // 缓冲区已满时的策略，默认 drop_newest
+	FlushTimeout  time.Duration // 单次写入超时，默认 10s
+	Retries       int           // 可重试错误（见 IsRetryable）的重试次数，默认 0
+	RetryBackoff  time.Duration // 首次重试的等待时间，之后每次翻倍，默认 200ms
+	Clock         Clock
+
+	// OnError 重试后仍写入失败时调用，这批记录随后被丢弃；可用于记录日志或转存到本地文件
+	OnError func(err error, count int)
+}
+
+func (o BatchWriterOptions) normalize() BatchWriterOptions {
+	if o.MaxSize <= 0 {
+		o.MaxSize = 500
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = o.MaxSize * 10
+	}
+	switch o.Overflow {
+	case BatchOverflowDropNewest, BatchOverflowDropOldest, BatchOverflowBlock:
+	default:
+		o.Overflow = BatchOverflowDropNewest
+	}
+	if o.FlushTimeout <= 0 {
+		o.FlushTimeout = 10 * time.Second
+	}
+	if o.Retries < 0 {
+		o.Retries = 0
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 200 * time.Millisecond
+	}
+	o.Clock = ClockOr(o.Clock)
+	return o
+}
+
+// BatchWriterStats 批量写入器统计
+type BatchWriterStats struct {
+	Name      string    `json:"name"`
+	Queued    int       `json:"queued"`     // 缓冲中的记录数
+	QueueSize int       `json:"queue_size"` // 缓冲上限
+	Written   uint64    `json:"written"`    // 已写入的记录数
+	Failed    uint64    `json:"failed"`     // 写入失败被丢弃的记录数
+	Dropped   uint64    `json:"dropped"`    // 缓冲区已满被丢弃的记录数
+	Batches   uint64    `json:"batches"`    // 写入批次数
+	LastFlush time.Time `json:"last_flush"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// BatchWriter 异步批量写入器：记录先进入内存缓冲，数量达到 MaxSize 或等待超过 FlushInterval 时整批写入，
+// 适用于请求日志、审计记录、埋点等高频插入场景，避免逐条 INSERT 拖垮数据库
+// 应用停止时需调用 Close 写入剩余记录
+//
+//	w := z.NewBatchWriter(db_provider.BatchInsert[RequestLog](db, 0), z.BatchWriterOptions{Name: "request_log", MaxSize: 1000})
+//	db_provider.RegisterBatchWriter(lc, w)
+//	_ = w.Write(ctx, RequestLog{Path: c.FullPath(), Status: c.Writer.Status()})
+type BatchWriter[T any] struct {
+	opts    BatchWriterOptions
+	flushFn BatchFlushFunc[T]
+	metrics batchWriterMetrics
+	attrs   metric.MeasurementOption
+
+	items    chan T
+	flushReq chan chan error
+	closing  chan struct{}
+	stopped  chan struct{}
+	mu       sync.RWMutex
+	closed   bool
+
+	written   atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+	batches   atomic.Uint64
+	lastFlush atomic.Int64
+	lastError atomic.Value // string
+}
+
+// NewBatchWriter 创建批量写入器并启动后台写入协程
+func NewBatchWriter[T any](fn BatchFlushFunc[T], opts BatchWriterOptions) *BatchWriter[T] {
+	opts = opts.normalize()
+	w := &BatchWriter[T]{
+		opts:     opts,
+		flushFn:  fn,
+		metrics:  getBatchWriterMetrics(),
+		attrs:    metric.WithAttributes(attribute.String("writer", opts.Name)),
+		items:    make(chan T, opts.QueueSize),
+		flushReq: make(chan chan error),
+		closing:  make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write 将记录放入缓冲区，缓冲区已满时按 Overflow 策略处理
+func (w *BatchWriter[T]) Write(ctx context.Context, item T) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrBatchWriterClosed
+	}
+
+	select {
+	case w.items <- item:
+		return nil
+	default:
+	}
+
+	switch w.opts.Overflow {
+	case BatchOverflowBlock:
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		select {
+		case w.items <- item:
+			return nil
+		case <-ctx.Done():
+			w.drop(1)
+			return ctx.Err()
+		}
+	case BatchOverflowDropOldest:
+		select {
+		case <-w.items:
+			w.drop(1)
+		default:
+		}
+		select {
+		case w.items <- item:
+			return nil
+		default:
+		}
+	}
+	w.drop(1)
+	return ErrBatchWriterFull
+}
+
+// Flush 立即写入缓冲中的全部记录，返回最后一个失败批次的错误
+func (w *BatchWriter[T]) Flush(ctx context.Context) error {
+	done := make(chan error, 1)
+	select {
+	case w.flushReq <- done:
+	case <-w.stopped:
+		return ErrBatchWriterClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 停止接收新记录并等待缓冲中的剩余记录写入完成；ctx 结束时返回 ctx.Err()，后台协程仍会继续写完剩余记录
+func (w *BatchWriter[T]) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.closing)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats 返回写入统计
+func (w *BatchWriter[T]) Stats() BatchWriterStats {
+	stats := BatchWriterStats{
+		Name:      w.opts.Name,
+		Queued:    len(w.items),
+		QueueSize: cap(w.items),
+		Written:   w.written.Load(),
+		Failed:    w.failed.Load(),
+		Dropped:   w.dropped.Load(),
+		Batches:   w.batches.Load(),
+	}
+	if ts := w.lastFlush.Load(); ts > 0 {
+		stats.LastFlush = time.Unix(0, ts)
+	}
+	if msg, ok := w.lastError.Load().(string); ok {
+		stats.LastError = msg
+	}
+	return stats
+}
+
+// run 后台写入协程：按数量或等待时间触发写入，关闭时写完缓冲区后退出
+func (w *BatchWriter[T]) run() {
+	defer close(w.stopped)
+
+	batch := make([]T, 0, w.opts.MaxSize)
+	var timer ClockTimer
+	var timerC <-chan time.Time
+	flush := func() error {
+		if timer != nil {
+			timer.Stop()
+			timer, timerC = nil, nil
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		err := w.write(batch)
+		batch = make([]T, 0, w.opts.MaxSize)
+		return err
+	}
+	add := func(item T) error {
+		batch = append(batch, item)
+		if len(batch) == 1 {
+			timer = w.opts.Clock.NewTimer(w.opts.FlushInterval)
+			timerC = timer.C()
+		}
+		if len(batch) >= w.opts.MaxSize {
+			return flush()
+		}
+		return nil
+	}
+	// drain 取出当前缓冲的全部记录并写入
+	drain := func() error {
+		var last error
+		for {
+			select {
+			case item := <-w.items:
+				if err := add(item); err != nil {
+					last = err
+				}
+			default:
+				if err := flush(); err != nil {
+					last = err
+				}
+				return last
+			}
+		}
+	}
+
+	for {
+		select {
+		case item := <-w.items:
+			_ = add(item)
+		case <-timerC:
+			_ = flush()
+		case done := <-w.flushReq:
+			done <- drain()
+		case <-w.closing:
+			_ = drain()
+			return
+		}
+	}
+}
+
+// write 写入一批记录，可重试错误按退避重试，最终失败时调用 OnError
+func (w *BatchWriter[T]) write(items []T) error {
+	start := w.opts.Clock.Now()
+	var err error
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), w.opts.FlushTimeout)
+		err = w.flushFn(ctx, items)
+		cancel()
+		if err == nil || attempt >= w.opts.Retries || !IsRetryable(err) {
+			break
+		}
+		w.opts.Clock.Sleep(w.opts.RetryBackoff << attempt)
+	}
+
+	ctx := context.Background()
+	w.batches.Add(1)
+	w.lastFlush.Store(w.opts.Clock.Now().UnixNano())
+	w.metrics.duration.Record(ctx, float64(w.opts.Clock.Since(start))/float64(time.Millisecond), w.attrs)
+	w.metrics.size.Record(ctx, int64(len(items)), w.attrs)
+	if err == nil {
+		w.written.Add(uint64(len(items)))
+		w.metrics.written.Add(ctx, int64(len(items)), w.attrs)
+		return nil
+	}
+
+	w.failed.Add(uint64(len(items)))
+	w.metrics.failed.Add(ctx, int64(len(items)), w.attrs)
+	w.lastError.Store(err.Error())
+	if w.opts.OnError != nil {
+		w.opts.OnError(err, len(items))
+	}
+	return err
+}
+
+func (w *BatchWriter[T]) drop(n int) {
+	w.dropped.Add(uint64(n))
+	w.metrics.dropped.Add(context.Background(), int64(n), w.attrs)
+}
+
+// batchWriterMetrics OTel 指标，未配置 MeterProvider 时为空实现
+type batchWriterMetrics struct {
+	written  metric.Int64Counter
+	failed   metric.Int64Counter
+	dropped  metric.Int64Counter
+	size     metric.Int64Histogram
+	duration metric.Float64Histogram
+}
+
+var (
+	batchWriterMetricsOnce sync.Once
+	batchWriterMetricsInst batchWriterMetrics
+)
+
+func getBatchWriterMetrics() batchWriterMetrics {
+	batchWriterMetricsOnce.Do(func() {
+		meter := otel.Meter("github.com/icreateapp-com/go-zLib/batch_writer")
+		batchWriterMetricsInst.written, _ = meter.Int64Counter("batch_writer.written", metric.WithDescription("已写入的记录数"))
+		batchWriterMetricsInst.failed, _ = meter.Int64Counter("batch_writer.failed", metric.WithDescription("写入失败被丢弃的记录数"))
+		batchWriterMetricsInst.dropped, _ = meter.Int64Counter("batch_writer.dropped", metric.WithDescription("缓冲区已满被丢弃的记录数"))
+		batchWriterMetricsInst.size, _ = meter.Int64Histogram("batch_writer.batch.size", metric.WithDescription("每批记录数"))
+		batchWriterMetricsInst.duration, _ = meter.Float64Histogram("batch_writer.flush.duration", metric.WithUnit("ms"), metric.WithDescription("每批写入耗时"))
+	})
+	return batchWriterMetricsInst
+}
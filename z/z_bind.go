@@ -0,0 +1,191 @@
+package z
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/goccy/go-json"
+)
+
+// BindLimits JSON 请求体绑定限制，字段 <= 0 时使用默认值
+type BindLimits struct {
+	MaxBodySize     int64 // 请求体最大字节数，默认 4MB，分块传输同样生效
+	MaxDepth        int   // 对象 / 数组最大嵌套层数，默认 32
+	MaxArrayLength  int   // 单个数组最大元素数，默认 10000
+	MaxNumberLength int   // 单个数字最大字符数，默认 64
+}
+
+// 默认绑定限制
+const (
+	defaultBindMaxBodySize     = 4 << 20
+	defaultBindMaxDepth        = 32
+	defaultBindMaxArrayLength  = 10000
+	defaultBindMaxNumberLength = 64
+)
+
+var (
+	bindLimitsMu sync.RWMutex
+	bindLimits   BindLimits
+)
+
+// SetBindLimits 设置 BindJSON 的全局限制，通常由 http_server 按 http.bind.* 配置调用
+func SetBindLimits(limits BindLimits) {
+	bindLimitsMu.Lock()
+	defer bindLimitsMu.Unlock()
+	bindLimits = limits
+}
+
+// GetBindLimits 返回补全默认值后的全局限制
+func GetBindLimits() BindLimits {
+	bindLimitsMu.RLock()
+	limits := bindLimits
+	bindLimitsMu.RUnlock()
+	return limits.normalize()
+}
+
+func (l BindLimits) normalize() BindLimits {
+	if l.MaxBodySize <= 0 {
+		l.MaxBodySize = defaultBindMaxBodySize
+	}
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = defaultBindMaxDepth
+	}
+	if l.MaxArrayLength <= 0 {
+		l.MaxArrayLength = defaultBindMaxArrayLength
+	}
+	if l.MaxNumberLength <= 0 {
+		l.MaxNumberLength = defaultBindMaxNumberLength
+	}
+	return l
+}
+
+// BindError 请求体不符合绑定限制或不是合法 JSON，Status 为 StatusPayloadTooLarge 或 StatusBadRequest
+type BindError struct {
+	Code    Status
+	Message string
+}
+
+func (e *BindError) Error() string {
+	return e.Message
+}
+
+// Status 返回业务状态码，便于 Failure(c, err, err.Status()) 输出
+func (e *BindError) Status() Status {
+	return e.Code
+}
+
+// ErrBodyTooLarge 请求体超过 MaxBodySize
+var ErrBodyTooLarge = &BindError{Code: StatusPayloadTooLarge, Message: "request body too large"}
+
+// BindJSON 在限制内读取请求体并解析到 obj，随后执行 binding 校验
+// 超限或 JSON 非法时返回 *BindError；请求体为空返回 io.EOF；校验失败返回 validator 错误，可交给 Validator.T 翻译
+func BindJSON(c *gin.Context, obj any, limits ...BindLimits) error {
+	l := GetBindLimits()
+	if len(limits) > 0 {
+		l = limits[0].normalize()
+	}
+	if c.Request == nil || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return io.EOF
+	}
+	if c.Request.ContentLength > l.MaxBodySize {
+		return ErrBodyTooLarge
+	}
+
+	// ContentLength 为 -1（分块传输）时只能边读边限制
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, l.MaxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return ErrBodyTooLarge
+		}
+		return &BindError{Code: StatusBadRequest, Message: "failed to read request body"}
+	}
+	if len(body) == 0 {
+		return io.EOF
+	}
+	if err := CheckJSON(body, l); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, obj); err != nil {
+		return &BindError{Code: StatusBadRequest, Message: "invalid json: " + err.Error()}
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// CheckJSON 在解析前线性扫描 JSON，检查嵌套层数、数组长度和数字长度，不校验语法
+func CheckJSON(data []byte, limits BindLimits) error {
+	l := limits.normalize()
+
+	// stack 记录每层是否为数组及其元素数，-1 表示尚未遇到首个元素
+	type frame struct {
+		array bool
+		count int
+	}
+	stack := make([]frame, 0, 8)
+	inString, escaped := false, false
+	numberLen := 0
+
+	for _, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+		if isJSONNumberByte(b) {
+			numberLen++
+			if numberLen > l.MaxNumberLength {
+				return &BindError{Code: StatusBadRequest, Message: fmt.Sprintf("json number exceeds %d characters", l.MaxNumberLength)}
+			}
+		} else {
+			numberLen = 0
+		}
+		if b == ' ' || b == '\t' || b == '\n' || b == '\r' {
+			continue
+		}
+
+		// 数组内首个非空白字符（且不是 ]）即为第一个元素
+		if n := len(stack); n > 0 && stack[n-1].array && stack[n-1].count < 0 && b != ']' {
+			stack[n-1].count = 1
+		}
+
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			if len(stack) >= l.MaxDepth {
+				return &BindError{Code: StatusBadRequest, Message: fmt.Sprintf("json nesting exceeds %d levels", l.MaxDepth)}
+			}
+			stack = append(stack, frame{array: b == '[', count: -1})
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case ',':
+			if n := len(stack); n > 0 && stack[n-1].array {
+				stack[n-1].count++
+				if stack[n-1].count > l.MaxArrayLength {
+					return &BindError{Code: StatusBadRequest, Message: fmt.Sprintf("json array exceeds %d elements", l.MaxArrayLength)}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isJSONNumberByte(b byte) bool {
+	return (b >= '0' && b <= '9') || b == '-' || b == '+' || b == '.' || b == 'e' || b == 'E'
+}
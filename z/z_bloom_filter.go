@@ -0,0 +1,90 @@
+package z
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// BloomFilter 内存布隆过滤器，用于快速判断元素一定不存在
+type BloomFilter struct {
+	mu   sync.RWMutex
+	bits []uint64
+	m    uint
+	k    uint
+}
+
+// NewBloomFilter 根据预期元素数量和误判率创建布隆过滤器
+func NewBloomFilter(expected uint, fpRate float64) *BloomFilter {
+	m, k := BloomParams(expected, fpRate)
+	return &BloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// BloomParams 根据预期元素数量和误判率计算位数组大小 m 和哈希函数个数 k
+func BloomParams(expected uint, fpRate float64) (m uint, k uint) {
+	if expected == 0 {
+		expected = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	n := float64(expected)
+	m = uint(math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k = uint(math.Round(float64(m) / n * math.Ln2))
+	if m < 64 {
+		m = 64
+	}
+	if k < 1 {
+		k = 1
+	}
+	return m, k
+}
+
+// BloomLocations 计算元素在位数组中的 k 个位置（双重哈希）
+func BloomLocations(key string, m, k uint) []uint {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	sum := h.Sum64()
+	h1 := uint32(sum)
+	h2 := uint32(sum >> 32)
+
+	locations := make([]uint, k)
+	for i := uint(0); i < k; i++ {
+		locations[i] = uint(h1+uint32(i)*h2) % m
+	}
+	return locations
+}
+
+// Add 添加元素
+func (f *BloomFilter) Add(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, loc := range BloomLocations(key, f.m, f.k) {
+		f.bits[loc/64] |= 1 << (loc % 64)
+	}
+}
+
+// MightContain 判断元素是否可能存在，返回 false 时元素一定不存在
+func (f *BloomFilter) MightContain(key string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, loc := range BloomLocations(key, f.m, f.k) {
+		if f.bits[loc/64]&(1<<(loc%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Reset 清空过滤器
+func (f *BloomFilter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.bits {
+		f.bits[i] = 0
+	}
+}
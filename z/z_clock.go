@@ -0,0 +1,202 @@
+package z
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 时间来源，令牌过期、任务调度、缓存 TTL、任务重启退避等通过它取时间，测试时替换为 FakeClock
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) ClockTimer
+	Sleep(d time.Duration)
+}
+
+// ClockTimer Clock 创建的定时器
+type ClockTimer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// SystemClock 系统时钟
+var SystemClock Clock = systemClock{}
+
+// ClockOr c 为空时返回 SystemClock，供 provider 处理可选的 Clock 依赖
+func ClockOr(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (systemClock) NewTimer(d time.Duration) ClockTimer    { return systemTimer{time.NewTimer(d)} }
+
+type systemTimer struct{ t *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time        { return t.t.C }
+func (t systemTimer) Stop() bool                 { return t.t.Stop() }
+func (t systemTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+// FakeClock 可控时钟，时间只在 Advance / Set 时前进，到期的定时器随之触发
+//
+//	clock := z.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	fx.Supply(fx.Annotate(clock, fx.As(new(z.Clock))))
+//	...
+//	clock.Advance(25 * time.Hour) // 令牌过期
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	waiters chan struct{}
+}
+
+// NewFakeClock 创建从 start 开始的可控时钟，start 为零值时使用当前时间
+func NewFakeClock(start time.Time) *FakeClock {
+	if start.IsZero() {
+		start = time.Now()
+	}
+	return &FakeClock{now: start, waiters: make(chan struct{})}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Sleep 阻塞直到时钟被推进 d
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) ClockTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	c.schedule(t, d)
+	return t
+}
+
+// Advance 推进时钟并触发到期的定时器
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.setLocked(c.now.Add(d))
+	c.mu.Unlock()
+}
+
+// Set 将时钟设置为 t，早于当前时间时不触发定时器
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.setLocked(t)
+	c.mu.Unlock()
+}
+
+// Waiters 返回尚未触发的定时器数量
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil 阻塞直到至少有 n 个未触发的定时器，用于等待被测协程进入 Sleep / 定时等待后再推进时钟
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		if len(c.timers) >= n {
+			c.mu.Unlock()
+			return
+		}
+		ch := c.waiters
+		c.mu.Unlock()
+		<-ch
+	}
+}
+
+// schedule 登记定时器，需在持有 c.mu 时调用
+func (c *FakeClock) schedule(t *fakeTimer, d time.Duration) {
+	t.deadline = c.now.Add(d)
+	if d <= 0 {
+		t.fire(c.now)
+		return
+	}
+	c.timers = append(c.timers, t)
+	close(c.waiters)
+	c.waiters = make(chan struct{})
+}
+
+// remove 移除定时器，需在持有 c.mu 时调用
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, item := range c.timers {
+		if item == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (c *FakeClock) setLocked(now time.Time) {
+	if now.Before(c.now) {
+		c.now = now
+		return
+	}
+	c.now = now
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+	remaining := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(now) {
+			remaining = append(remaining, t)
+			continue
+		}
+		t.fire(t.deadline)
+	}
+	c.timers = remaining
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	ch       chan time.Time
+	deadline time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+// fire 与 time.Timer 一致，通道已有未读取的值时丢弃本次触发
+func (t *fakeTimer) fire(now time.Time) {
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.remove(t)
+	t.clock.schedule(t, d)
+	return active
+}
@@ -0,0 +1,262 @@
+package z
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// 内置压缩算法名称
+const (
+	CompressionNone   = "none"
+	CompressionGzip   = "gzip"
+	CompressionZstd   = "zstd"
+	CompressionSnappy = "snappy"
+)
+
+// DefaultCompressionMinSize 默认压缩阈值，小于该长度的值不压缩
+const DefaultCompressionMinSize = 1024
+
+// compressionMagic 压缩值头部标记，其后 1 字节为算法标识；JSON 与文本不会以 0x00 开头
+var compressionMagic = []byte{0x00, 'Z'}
+
+// ErrUnknownCompression 值头部的算法标识未注册
+var ErrUnknownCompression = errors.New("compression: unknown codec")
+
+// Compressor 压缩算法，ID 写入压缩值头部，注册后不可更改
+type Compressor interface {
+	Name() string
+	ID() byte
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	compressorMu     sync.RWMutex
+	compressorByName = map[string]Compressor{}
+	compressorByID   = map[byte]Compressor{}
+)
+
+func init() {
+	RegisterCompressor(gzipCompressor{})
+	RegisterCompressor(&zstdCompressor{})
+	RegisterCompressor(snappyCompressor{})
+}
+
+// RegisterCompressor 注册压缩算法，名称或标识重复时覆盖
+func RegisterCompressor(c Compressor) {
+	if c == nil {
+		return
+	}
+	compressorMu.Lock()
+	defer compressorMu.Unlock()
+	compressorByName[strings.ToLower(c.Name())] = c
+	compressorByID[c.ID()] = c
+}
+
+// GetCompressor 按名称获取压缩算法
+func GetCompressor(name string) (Compressor, bool) {
+	compressorMu.RLock()
+	defer compressorMu.RUnlock()
+	c, ok := compressorByName[strings.ToLower(strings.TrimSpace(name))]
+	return c, ok
+}
+
+// CompressionCodec 超过阈值的值压缩后在头部写入 3 字节标记（0x00 'Z' 算法标识），未带标记的值原样读取
+// 因此开启、关闭或切换算法前后写入的数据都可以读取；nil 表示不压缩，Decode 仍可解压带标记的值
+type CompressionCodec struct {
+	compressor Compressor
+	minSize    int
+	target     string
+}
+
+// NewCompressionCodec 创建压缩编解码器，name 为空或 none 时返回 nil（不压缩）
+// target 为指标属性，标识使用方，如 redis、job
+func NewCompressionCodec(name string, minSize int, target string) (*CompressionCodec, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == CompressionNone {
+		return nil, nil
+	}
+	c, ok := GetCompressor(name)
+	if !ok {
+		return nil, fmt.Errorf("compression: unsupported codec: %s", name)
+	}
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+	return &CompressionCodec{compressor: c, minSize: minSize, target: target}, nil
+}
+
+// Name 算法名称，nil 时为 none
+func (c *CompressionCodec) Name() string {
+	if c == nil {
+		return CompressionNone
+	}
+	return c.compressor.Name()
+}
+
+// Encode 超过阈值时压缩并写入标记；压缩后未变小时保留原值
+func (c *CompressionCodec) Encode(data []byte) ([]byte, error) {
+	if c == nil || len(data) < c.minSize {
+		return data, nil
+	}
+	compressed, err := c.compressor.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed)+len(compressionMagic)+1 >= len(data) {
+		compressionStats.record(c.compressor.Name(), c.target, len(data), len(data), false)
+		return data, nil
+	}
+	out := make([]byte, 0, len(compressionMagic)+1+len(compressed))
+	out = append(out, compressionMagic...)
+	out = append(out, c.compressor.ID())
+	out = append(out, compressed...)
+	compressionStats.record(c.compressor.Name(), c.target, len(data), len(out), true)
+	return out, nil
+}
+
+// Decode 解压带标记的值，未带标记的值原样返回
+func (c *CompressionCodec) Decode(data []byte) ([]byte, error) {
+	return Decompress(data)
+}
+
+// IsCompressed 值是否带压缩标记
+func IsCompressed(data []byte) bool {
+	return len(data) > len(compressionMagic) && bytes.HasPrefix(data, compressionMagic)
+}
+
+// Decompress 按值头部标记解压，未带标记的值原样返回
+func Decompress(data []byte) ([]byte, error) {
+	if !IsCompressed(data) {
+		return data, nil
+	}
+	id := data[len(compressionMagic)]
+	compressorMu.RLock()
+	c, ok := compressorByID[id]
+	compressorMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: id %d", ErrUnknownCompression, id)
+	}
+	return c.Decompress(data[len(compressionMagic)+1:])
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Name() string { return CompressionGzip }
+func (gzipCompressor) ID() byte     { return 1 }
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// zstdCompressor 编码器与解码器可并发复用，首次使用时创建
+type zstdCompressor struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	err     error
+}
+
+func (*zstdCompressor) Name() string { return CompressionZstd }
+func (*zstdCompressor) ID() byte     { return 2 }
+
+func (c *zstdCompressor) init() error {
+	c.once.Do(func() {
+		if c.encoder, c.err = zstd.NewWriter(nil); c.err != nil {
+			return
+		}
+		c.decoder, c.err = zstd.NewReader(nil)
+	})
+	return c.err
+}
+
+func (c *zstdCompressor) Compress(data []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCompressor) Decompress(data []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c.decoder.DecodeAll(data, nil)
+}
+
+type snappyCompressor struct{}
+
+func (snappyCompressor) Name() string { return CompressionSnappy }
+func (snappyCompressor) ID() byte     { return 3 }
+
+func (snappyCompressor) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+func (snappyCompressor) Decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
+
+// compressionStats 压缩指标：compression.bytes.in / compression.bytes.out（字节数）及 compression.ratio（压缩后 / 压缩前）
+// 属性 codec、target、compressed（是否实际压缩，压缩后未变小时为 false）
+var compressionStats = &compressionMetrics{}
+
+type compressionMetrics struct {
+	once     sync.Once
+	bytesIn  metric.Int64Counter
+	bytesOut metric.Int64Counter
+	ratio    metric.Float64Histogram
+}
+
+func (m *compressionMetrics) record(codec, target string, in, out int, compressed bool) {
+	m.once.Do(func() {
+		meter := otel.Meter("github.com/icreateapp-com/go-zLib/compression")
+		m.bytesIn, _ = meter.Int64Counter("compression.bytes.in", metric.WithUnit("By"))
+		m.bytesOut, _ = meter.Int64Counter("compression.bytes.out", metric.WithUnit("By"))
+		m.ratio, _ = meter.Float64Histogram("compression.ratio", metric.WithDescription("compressed size / original size"))
+	})
+	attrs := metric.WithAttributes(
+		attribute.String("codec", codec),
+		attribute.String("target", target),
+		attribute.Bool("compressed", compressed),
+	)
+	ctx := context.Background()
+	if m.bytesIn != nil {
+		m.bytesIn.Add(ctx, int64(in), attrs)
+	}
+	if m.bytesOut != nil {
+		m.bytesOut.Add(ctx, int64(out), attrs)
+	}
+	if m.ratio != nil && in > 0 {
+		m.ratio.Record(ctx, float64(out)/float64(in), attrs)
+	}
+}
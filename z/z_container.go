@@ -0,0 +1,199 @@
+package z
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scope 服务生命周期
+type Scope int
+
+const (
+	ScopeSingleton Scope = iota // 首次解析时创建，之后复用
+	ScopeRequest                // 每个请求作用域内创建一次，需先通过 WithRequestScope 或 RequestScope 中间件开启作用域
+)
+
+var (
+	// ErrServiceNotProvided 类型未注册
+	ErrServiceNotProvided = errors.New("service not provided")
+	// ErrNoRequestScope 解析 ScopeRequest 服务时 ctx 未开启请求作用域
+	ErrNoRequestScope = errors.New("no request scope in context")
+)
+
+// Container 轻量服务注册表，按类型注册构造函数，用于 fx 之外的业务服务，测试时可用 Provide 覆盖实现而不必修改包级单例
+// 基础设施 provider 仍由 fx 注入
+type Container struct {
+	mu      sync.RWMutex
+	entries map[reflect.Type]*containerEntry
+}
+
+type containerEntry struct {
+	scope Scope
+	ctor  func(ctx context.Context) (interface{}, error)
+
+	mu    sync.Mutex
+	built bool
+	value interface{}
+}
+
+// DefaultContainer Provide / Resolve 使用的默认容器
+var DefaultContainer = NewContainer()
+
+// NewContainer 创建容器
+func NewContainer() *Container {
+	return &Container{entries: map[reflect.Type]*containerEntry{}}
+}
+
+// Reset 清空全部注册，测试结束时调用
+func (c *Container) Reset() {
+	c.mu.Lock()
+	c.entries = map[reflect.Type]*containerEntry{}
+	c.mu.Unlock()
+}
+
+func (c *Container) set(t reflect.Type, entry *containerEntry) (restore func()) {
+	c.mu.Lock()
+	prev, had := c.entries[t]
+	c.entries[t] = entry
+	c.mu.Unlock()
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if had {
+			c.entries[t] = prev
+		} else {
+			delete(c.entries, t)
+		}
+	}
+}
+
+func (c *Container) get(t reflect.Type) (*containerEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[t]
+	return entry, ok
+}
+
+// requestScope 请求作用域内已创建的服务
+type requestScope struct {
+	mu     sync.Mutex
+	values map[*containerEntry]interface{}
+}
+
+type requestScopeKey struct{}
+
+// WithRequestScope 开启请求作用域，ScopeRequest 服务在同一作用域内只创建一次
+func WithRequestScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestScopeKey{}, &requestScope{values: map[*containerEntry]interface{}{}})
+}
+
+// RequestScope gin 中间件，为每个请求开启作用域
+func RequestScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithRequestScope(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// resolve 按作用域取得或创建实例，构造失败时不缓存，下次解析重试
+func (e *containerEntry) resolve(ctx context.Context) (interface{}, error) {
+	if e.scope == ScopeRequest {
+		scope, ok := ctx.Value(requestScopeKey{}).(*requestScope)
+		if !ok {
+			return nil, ErrNoRequestScope
+		}
+		scope.mu.Lock()
+		defer scope.mu.Unlock()
+		if v, ok := scope.values[e]; ok {
+			return v, nil
+		}
+		v, err := e.ctor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		scope.values[e] = v
+		return v, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.built {
+		return e.value, nil
+	}
+	v, err := e.ctor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.value, e.built = v, true
+	return v, nil
+}
+
+func serviceType[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// ProvideIn 在容器中注册 T 的构造函数，已注册时覆盖，返回的 restore 恢复之前的注册
+//
+//	restore := z.ProvideIn(c, func(ctx context.Context) (OrderService, error) { return &fakeOrderService{}, nil })
+//	defer restore()
+func ProvideIn[T any](c *Container, ctor func(ctx context.Context) (T, error), scope ...Scope) (restore func()) {
+	entry := &containerEntry{scope: FirstOrDefault(scope, ScopeSingleton)}
+	entry.ctor = func(ctx context.Context) (interface{}, error) { return ctor(ctx) }
+	return c.set(serviceType[T](), entry)
+}
+
+// ResolveIn 从容器解析 T，ScopeRequest 服务需要 ctx 已开启请求作用域
+func ResolveIn[T any](ctx context.Context, c *Container) (T, error) {
+	var zero T
+	t := serviceType[T]()
+	entry, ok := c.get(t)
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrServiceNotProvided, t)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	v, err := entry.resolve(ctx)
+	if err != nil {
+		return zero, fmt.Errorf("resolve %s: %w", t, err)
+	}
+	out, ok := v.(T)
+	if !ok && v != nil {
+		return zero, fmt.Errorf("resolve %s: unexpected %T", t, v)
+	}
+	return out, nil
+}
+
+// Provide 在 DefaultContainer 中注册 T 的构造函数，默认 ScopeSingleton
+func Provide[T any](ctor func(ctx context.Context) (T, error), scope ...Scope) (restore func()) {
+	return ProvideIn(DefaultContainer, ctor, scope...)
+}
+
+// ProvideValue 在 DefaultContainer 中注册 T 的现成实例，常用于测试替换
+func ProvideValue[T any](value T) (restore func()) {
+	return Provide(func(context.Context) (T, error) { return value, nil })
+}
+
+// Resolve 从 DefaultContainer 解析单例服务
+func Resolve[T any]() (T, error) {
+	return ResolveIn[T](context.Background(), DefaultContainer)
+}
+
+// ResolveCtx 从 DefaultContainer 解析服务，支持 ScopeRequest
+func ResolveCtx[T any](ctx context.Context) (T, error) {
+	return ResolveIn[T](ctx, DefaultContainer)
+}
+
+// MustResolve 解析失败时 panic，用于启动阶段
+func MustResolve[T any]() T {
+	v, err := Resolve[T]()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
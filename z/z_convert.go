@@ -0,0 +1,275 @@
+package z
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConvertOptions 严格转换选项，见 ToStructStrict
+type ConvertOptions struct {
+	DisallowUnknownFields bool // 源数据包含目标结构体未定义的字段时报错
+	RequireFields         bool // binding 或 validate 标签含 required 的字段缺失或为 null 时报错
+}
+
+// StrictConvert 启用全部检查
+var StrictConvert = ConvertOptions{DisallowUnknownFields: true, RequireFields: true}
+
+// ConvertIssue 单个字段的转换问题，Path 为 json 字段路径，如 items[0].price
+type ConvertIssue struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ConvertError 严格转换失败，包含全部字段问题
+type ConvertError struct {
+	Issues []ConvertIssue `json:"issues"`
+}
+
+func (e *ConvertError) Error() string {
+	if len(e.Issues) == 0 {
+		return "convert failed"
+	}
+	first := e.Issues[0]
+	msg := "convert: " + first.Message
+	if first.Path != "" {
+		msg = "convert: " + first.Path + " " + first.Message
+	}
+	if len(e.Issues) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Issues)-1)
+	}
+	return msg
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// ToStructStrict 与 ToStruct 相同经 JSON 转换，但不会静默丢弃未知字段或忽略类型不符：
+// 先按目标类型检查全部字段，存在问题时返回 *ConvertError 且不修改 target；未传 opts 时使用 StrictConvert
+//
+//	var req CreateOrderRequest
+//	if err := z.ToStructStrict(data, &req); err != nil {
+//		var convErr *z.ConvertError
+//		errors.As(err, &convErr) // convErr.Issues: [{items[0].price expected float64, got string}]
+//	}
+func ToStructStrict(data interface{}, target interface{}, opts ...ConvertOptions) error {
+	opt := StrictConvert
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("convert: target must be a non-nil pointer")
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var issues []ConvertIssue
+	checkConvert(raw, rv.Type().Elem(), "", opt, &issues)
+	if len(issues) > 0 {
+		return &ConvertError{Issues: issues}
+	}
+	return json.Unmarshal(raw, target)
+}
+
+// checkConvert 按目标类型递归检查 JSON 数据，问题追加到 issues
+func checkConvert(raw json.RawMessage, t reflect.Type, path string, opt ConvertOptions, issues *[]ConvertIssue) {
+	if isJSONNull(raw) {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// 自定义解析的类型（如 time.Time、WrapTime）整体交给 json 校验
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		checkConvertLeaf(raw, t, path, issues)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			*issues = append(*issues, ConvertIssue{Path: path, Message: "expected object, got " + rawJSONKind(raw)})
+			return
+		}
+		fields := convertFields(t)
+		seen := make(map[int]bool, len(fields))
+		for _, key := range sortedRawKeys(obj) {
+			value := obj[key]
+			idx := matchConvertField(fields, key)
+			if idx < 0 {
+				if opt.DisallowUnknownFields {
+					*issues = append(*issues, ConvertIssue{Path: joinConvertPath(path, key), Message: "is unknown"})
+				}
+				continue
+			}
+			seen[idx] = true
+			checkConvert(value, fields[idx].typ, joinConvertPath(path, key), opt, issues)
+			if opt.RequireFields && fields[idx].required && isJSONNull(value) {
+				*issues = append(*issues, ConvertIssue{Path: joinConvertPath(path, key), Message: "is required"})
+			}
+		}
+		if opt.RequireFields {
+			for i, f := range fields {
+				if f.required && !seen[i] {
+					*issues = append(*issues, ConvertIssue{Path: joinConvertPath(path, f.name), Message: "is required"})
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			checkConvertLeaf(raw, t, path, issues)
+			return
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			*issues = append(*issues, ConvertIssue{Path: path, Message: "expected array, got " + rawJSONKind(raw)})
+			return
+		}
+		for i, item := range items {
+			checkConvert(item, t.Elem(), path+"["+strconv.Itoa(i)+"]", opt, issues)
+		}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			checkConvertLeaf(raw, t, path, issues)
+			return
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			*issues = append(*issues, ConvertIssue{Path: path, Message: "expected object, got " + rawJSONKind(raw)})
+			return
+		}
+		for _, key := range sortedRawKeys(obj) {
+			checkConvert(obj[key], t.Elem(), joinConvertPath(path, key), opt, issues)
+		}
+	default:
+		checkConvertLeaf(raw, t, path, issues)
+	}
+}
+
+// checkConvertLeaf 尝试将值解析为目标类型
+func checkConvertLeaf(raw json.RawMessage, t reflect.Type, path string, issues *[]ConvertIssue) {
+	if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+		msg := err.Error()
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			msg = "expected " + t.String() + ", got " + typeErr.Value
+		}
+		*issues = append(*issues, ConvertIssue{Path: path, Message: msg})
+	}
+}
+
+// convertField 结构体中参与 JSON 转换的字段
+type convertField struct {
+	name     string
+	typ      reflect.Type
+	required bool
+}
+
+// convertFields 按 encoding/json 规则列出字段：跳过未导出字段及 json:"-"，展开无 json 名称的内嵌结构体
+func convertFields(t reflect.Type) []convertField {
+	var fields []convertField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, convertFields(ft)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, convertField{
+			name:     name,
+			typ:      f.Type,
+			required: tagRequired(f.Tag.Get("binding")) || tagRequired(f.Tag.Get("validate")),
+		})
+	}
+	return fields
+}
+
+// matchConvertField 按 encoding/json 规则匹配字段：优先精确匹配，其次不区分大小写
+func matchConvertField(fields []convertField, key string) int {
+	for i, f := range fields {
+		if f.name == key {
+			return i
+		}
+	}
+	for i, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func tagRequired(tag string) bool {
+	for _, rule := range strings.Split(tag, ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+func joinConvertPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// sortedRawKeys 返回排序后的键，使问题列表顺序稳定
+func sortedRawKeys(obj map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
+
+// rawJSONKind 返回 JSON 值的类型名，用于错误信息
+func rawJSONKind(raw json.RawMessage) string {
+	switch raw[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	default:
+		return "number"
+	}
+}
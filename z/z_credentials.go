@@ -0,0 +1,330 @@
+package z
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Credentials 请求凭证，ServiceClient 与配置中心客户端在每次请求前调用 Authorize 注入凭证
+// 响应 401 时调用 Refresh 刷新凭证后重试一次
+type Credentials interface {
+	Authorize(ctx context.Context, headers map[string]string) error
+	Refresh(ctx context.Context) error
+}
+
+// ClientCredentials 需要自定义 http.Client 的凭证（如 mTLS），未显式指定 Client 时使用其返回值
+type ClientCredentials interface {
+	HttpClient() *http.Client
+}
+
+// StaticToken 静态令牌
+type StaticToken struct {
+	Token  string // 令牌，为空时不注入
+	Header string // 请求头，默认 Authorization
+	Scheme string // 令牌前缀，默认 Bearer，设置为 "-" 表示不加前缀
+}
+
+func (t StaticToken) Authorize(_ context.Context, headers map[string]string) error {
+	if t.Token != "" {
+		headers[headerOr(t.Header)] = withScheme(t.Scheme, t.Token)
+	}
+	return nil
+}
+
+// Refresh 静态令牌无法刷新
+func (t StaticToken) Refresh(context.Context) error {
+	return errors.New("static token cannot be refreshed")
+}
+
+// OAuth2Options OAuth2 client credentials 授权选项
+type OAuth2Options struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Audience     string            // 部分平台要求的 audience 参数，可选
+	Params       map[string]string // 附加的表单参数
+	Header       string            // 请求头，默认 Authorization
+	ExpirySkew   time.Duration     // 提前刷新的时间，默认 30s
+	Timeout      time.Duration     // 获取令牌的超时时间，默认 10s
+	Client       *http.Client      // 访问令牌端点的 client，可配合 mTLS 使用
+}
+
+// OAuth2ClientCredentials 通过 client credentials 授权获取访问令牌，过期前自动刷新
+type OAuth2ClientCredentials struct {
+	opt OAuth2Options
+
+	mu        sync.Mutex
+	token     string
+	tokenType string
+	expiresAt time.Time
+}
+
+// NewOAuth2ClientCredentials 创建 OAuth2 client credentials 凭证
+func NewOAuth2ClientCredentials(opt OAuth2Options) *OAuth2ClientCredentials {
+	if opt.ExpirySkew <= 0 {
+		opt.ExpirySkew = 30 * time.Second
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+	return &OAuth2ClientCredentials{opt: opt}
+}
+
+func (o *OAuth2ClientCredentials) Authorize(ctx context.Context, headers map[string]string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token == "" || (!o.expiresAt.IsZero() && time.Now().Add(o.opt.ExpirySkew).After(o.expiresAt)) {
+		if err := o.fetch(ctx); err != nil {
+			return err
+		}
+	}
+	headers[headerOr(o.opt.Header)] = o.tokenType + " " + o.token
+	return nil
+}
+
+// Refresh 丢弃当前令牌并重新获取
+func (o *OAuth2ClientCredentials) Refresh(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.fetch(ctx)
+}
+
+// fetch 请求令牌端点，需在持有 o.mu 时调用
+func (o *OAuth2ClientCredentials) fetch(ctx context.Context) error {
+	form := map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     o.opt.ClientID,
+		"client_secret": o.opt.ClientSecret,
+	}
+	if len(o.opt.Scopes) > 0 {
+		form["scope"] = strings.Join(o.opt.Scopes, " ")
+	}
+	if o.opt.Audience != "" {
+		form["audience"] = o.opt.Audience
+	}
+	for k, v := range o.opt.Params {
+		form[k] = v
+	}
+
+	resp, err := RequestWithResponse(RequestOptions{
+		URL:         o.opt.TokenURL,
+		Method:      http.MethodPost,
+		Headers:     map[string]string{"Accept": "application/json"},
+		ContentType: RequestContentTypeForm,
+		Data:        form,
+		Timeout:     o.opt.Timeout,
+		Client:      o.opt.Client,
+		Context:     ctx,
+	})
+	if err != nil {
+		return fmt.Errorf("oauth2 token: %w", err)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(resp.Body, &token); err != nil {
+		return fmt.Errorf("oauth2 token: decode response failed: %w", err)
+	}
+	if token.AccessToken == "" {
+		return errors.New("oauth2 token: empty access_token")
+	}
+
+	o.token = token.AccessToken
+	o.tokenType = "Bearer"
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		o.tokenType = token.TokenType
+	}
+	o.expiresAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		o.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return nil
+}
+
+// MTLSOptions 双向 TLS 选项
+type MTLSOptions struct {
+	CertFile   string // 客户端证书
+	KeyFile    string // 客户端私钥
+	CAFile     string // 服务端 CA，为空时使用系统根证书
+	ServerName string // 校验的服务端名称，可选
+}
+
+// MTLSCredentials 双向 TLS 凭证，Refresh 时重新加载证书文件，适用于证书定期轮换的场景
+type MTLSCredentials struct {
+	opt    MTLSOptions
+	cert   atomic.Pointer[tls.Certificate]
+	client *http.Client
+}
+
+// NewMTLSCredentials 加载客户端证书并创建使用该证书的 http.Client
+func NewMTLSCredentials(opt MTLSOptions) (*MTLSCredentials, error) {
+	m := &MTLSCredentials{opt: opt}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: opt.ServerName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return m.cert.Load(), nil
+		},
+	}
+	if opt.CAFile != "" {
+		pem, err := os.ReadFile(opt.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("mtls: read ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("mtls: no certificates found in %s", opt.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	m.client = &http.Client{Transport: transport, Timeout: 30 * time.Second}
+	return m, nil
+}
+
+func (m *MTLSCredentials) load() error {
+	cert, err := tls.LoadX509KeyPair(m.opt.CertFile, m.opt.KeyFile)
+	if err != nil {
+		return fmt.Errorf("mtls: load certificate: %w", err)
+	}
+	m.cert.Store(&cert)
+	return nil
+}
+
+// Authorize mTLS 在 TLS 握手时认证，不注入请求头
+func (m *MTLSCredentials) Authorize(context.Context, map[string]string) error {
+	return nil
+}
+
+// Refresh 重新加载证书文件，之后的新连接使用新证书
+func (m *MTLSCredentials) Refresh(context.Context) error {
+	if err := m.load(); err != nil {
+		return err
+	}
+	m.client.CloseIdleConnections()
+	return nil
+}
+
+// HttpClient 返回使用客户端证书的 http.Client
+func (m *MTLSCredentials) HttpClient() *http.Client {
+	return m.client
+}
+
+// ChainCredentials 依次应用多个凭证，如 mTLS + OAuth2
+type ChainCredentials []Credentials
+
+func (c ChainCredentials) Authorize(ctx context.Context, headers map[string]string) error {
+	for _, cred := range c {
+		if err := cred.Authorize(ctx, headers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Refresh 刷新所有可刷新的凭证，全部失败时返回最后一个错误
+func (c ChainCredentials) Refresh(ctx context.Context) error {
+	var lastErr error
+	refreshed := false
+	for _, cred := range c {
+		if err := cred.Refresh(ctx); err != nil {
+			lastErr = err
+			continue
+		}
+		refreshed = true
+	}
+	if refreshed {
+		return nil
+	}
+	return lastErr
+}
+
+// HttpClient 返回第一个提供自定义 client 的凭证的 client
+func (c ChainCredentials) HttpClient() *http.Client {
+	for _, cred := range c {
+		if cc, ok := cred.(ClientCredentials); ok {
+			if client := cc.HttpClient(); client != nil {
+				return client
+			}
+		}
+	}
+	return nil
+}
+
+// AuthorizedRequest 注入凭证后发起请求，响应 401 时刷新凭证并重试一次；请求体为流式 io.Reader 或 multipart 时不重试
+func AuthorizedRequest(creds Credentials, opt RequestOptions) (*HttpResponse, error) {
+	if creds == nil {
+		return RequestWithResponse(opt)
+	}
+	ctx := opt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opt.Client == nil {
+		if cc, ok := creds.(ClientCredentials); ok {
+			opt.Client = cc.HttpClient()
+		}
+	}
+
+	base := opt.Headers
+	for attempt := 0; ; attempt++ {
+		headers := make(map[string]string, len(base)+1)
+		for k, v := range base {
+			headers[k] = v
+		}
+		if err := creds.Authorize(ctx, headers); err != nil {
+			return nil, err
+		}
+		opt.Headers = headers
+
+		resp, err := RequestWithResponse(opt)
+		var statusErr *HttpStatusError
+		if attempt > 0 || !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+		if !replayableRequest(opt.ContentType, opt.Data) {
+			return resp, err
+		}
+		if creds.Refresh(ctx) != nil {
+			return resp, err
+		}
+	}
+}
+
+func headerOr(header string) string {
+	if header == "" {
+		return "Authorization"
+	}
+	return header
+}
+
+func withScheme(scheme, token string) string {
+	switch scheme {
+	case "-":
+		return token
+	case "":
+		return "Bearer " + token
+	default:
+		return scheme + " " + token
+	}
+}
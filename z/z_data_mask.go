@@ -0,0 +1,255 @@
+package z
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+)
+
+// maskPlaceholder 全部脱敏时的占位值
+const maskPlaceholder = "******"
+
+// MaskFunc 脱敏函数
+type MaskFunc func(value string) string
+
+// MaskRule 响应字段脱敏规则
+// Path 不含 . 时匹配任意层级的同名字段，如 phone；含 . 时从 message 根开始匹配，* 匹配任意键或数组下标，如 list.*.id_card
+type MaskRule struct {
+	Path         string   `json:"path"`
+	Mask         string   `json:"mask"`          // 脱敏方式：phone / email / id_card / name / all 或 RegisterMask 注册的名称
+	Guards       []string `json:"guards"`        // 仅对这些 guard 生效，为空时对所有调用方生效
+	ExemptGuards []string `json:"exempt_guards"` // 这些 guard 不脱敏
+	Permission   string   `json:"permission"`    // 具备该权限（resource:action）时不脱敏
+}
+
+var (
+	maskMu      sync.RWMutex
+	maskRules   []MaskRule
+	maskFuncs   = map[string]MaskFunc{"phone": MaskPhone, "email": MaskEmail, "id_card": MaskIDCard, "name": MaskName, "all": MaskAll}
+	maskCanFunc func(c *gin.Context, permission string) bool
+)
+
+// SetMaskRules 设置全局脱敏规则，Success 输出前按规则处理 message
+func SetMaskRules(rules []MaskRule) {
+	maskMu.Lock()
+	defer maskMu.Unlock()
+	maskRules = append([]MaskRule(nil), rules...)
+}
+
+// RegisterMask 注册自定义脱敏方式
+func RegisterMask(name string, fn MaskFunc) {
+	if name == "" || fn == nil {
+		return
+	}
+	maskMu.Lock()
+	defer maskMu.Unlock()
+	maskFuncs[name] = fn
+}
+
+// LookupMask 按名称返回脱敏方式，包括 RegisterMask 注册的
+func LookupMask(name string) (MaskFunc, bool) {
+	maskMu.RLock()
+	defer maskMu.RUnlock()
+	fn, ok := maskFuncs[name]
+	return fn, ok
+}
+
+// SetMaskPermissionChecker 设置权限判断函数，未设置时规则中的 Permission 不生效
+func SetMaskPermissionChecker(fn func(c *gin.Context, permission string) bool) {
+	maskMu.Lock()
+	defer maskMu.Unlock()
+	maskCanFunc = fn
+}
+
+// MaskPhone 保留前 3 位和后 4 位，如 138****5678
+func MaskPhone(value string) string {
+	return maskMiddle(value, 3, 4)
+}
+
+// MaskEmail 保留用户名首字符和域名，如 a***@example.com
+func MaskEmail(value string) string {
+	at := strings.LastIndex(value, "@")
+	if at <= 0 {
+		return MaskAll(value)
+	}
+	_, size := utf8.DecodeRuneInString(value)
+	return value[:size] + "***" + value[at:]
+}
+
+// MaskIDCard 保留前 6 位和后 4 位
+func MaskIDCard(value string) string {
+	return maskMiddle(value, 6, 4)
+}
+
+// MaskName 保留首字符，如 张**
+func MaskName(value string) string {
+	n := utf8.RuneCountInString(value)
+	if n <= 1 {
+		return value
+	}
+	_, size := utf8.DecodeRuneInString(value)
+	return value[:size] + strings.Repeat("*", n-1)
+}
+
+// MaskAll 全部替换为 *
+func MaskAll(value string) string {
+	if value == "" {
+		return value
+	}
+	return maskPlaceholder
+}
+
+// maskMiddle 保留首尾字符，中间替换为 *；长度不足时全部替换
+func maskMiddle(value string, head, tail int) string {
+	runes := []rune(value)
+	if len(runes) <= head+tail {
+		return MaskAll(value)
+	}
+	return string(runes[:head]) + strings.Repeat("*", len(runes)-head-tail) + string(runes[len(runes)-tail:])
+}
+
+// activeMaskRule 已解析的规则
+type activeMaskRule struct {
+	path []string
+	deep bool
+	fn   MaskFunc
+}
+
+// activeMaskRules 返回对当前调用方生效的规则
+func activeMaskRules(c *gin.Context) []activeMaskRule {
+	maskMu.RLock()
+	rules := maskRules
+	funcs := maskFuncs
+	can := maskCanFunc
+	maskMu.RUnlock()
+	if len(rules) == 0 {
+		return nil
+	}
+
+	guard := ""
+	if c != nil {
+		if v, ok := c.Get("auth.guard"); ok {
+			guard, _ = v.(string)
+		}
+	}
+
+	active := make([]activeMaskRule, 0, len(rules))
+	for _, rule := range rules {
+		fn, ok := funcs[rule.Mask]
+		if !ok || rule.Path == "" {
+			continue
+		}
+		if len(rule.Guards) > 0 && !InStringSlice(rule.Guards, guard) {
+			continue
+		}
+		if guard != "" && InStringSlice(rule.ExemptGuards, guard) {
+			continue
+		}
+		if rule.Permission != "" && can != nil && c != nil && can(c, rule.Permission) {
+			continue
+		}
+		path := strings.Split(rule.Path, ".")
+		active = append(active, activeMaskRule{path: path, deep: len(path) == 1, fn: fn})
+	}
+	return active
+}
+
+// ApplyMask 按当前调用方生效的规则脱敏数据，无生效规则时原样返回
+// 无法转换为 JSON 时返回错误，调用方不应输出原始数据
+func ApplyMask(c *gin.Context, data interface{}) (interface{}, error) {
+	if data == nil {
+		return data, nil
+	}
+	rules := activeMaskRules(c)
+	if len(rules) == 0 {
+		return data, nil
+	}
+
+	// 转为通用结构后按路径处理，保持与 JSON 输出一致的字段名；数字保留为 json.Number，避免 int64 精度丢失
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.deep {
+			generic = maskDeep(generic, rule.path[0], rule.fn)
+		} else {
+			generic = maskPath(generic, rule.path, rule.fn)
+		}
+	}
+	return generic, nil
+}
+
+// maskDeep 处理任意层级的同名字段
+func maskDeep(value interface{}, field string, fn MaskFunc) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if k == field {
+				v[k] = maskValue(item, fn)
+				continue
+			}
+			v[k] = maskDeep(item, field, fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskDeep(item, field, fn)
+		}
+	}
+	return value
+}
+
+// maskPath 按路径处理字段
+func maskPath(value interface{}, path []string, fn MaskFunc) interface{} {
+	if len(path) == 0 {
+		return maskValue(value, fn)
+	}
+	key, rest := path[0], path[1:]
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if key == "*" || k == key {
+				v[k] = maskPath(item, rest, fn)
+			}
+		}
+	case []interface{}:
+		// 数组下标可省略，如 list.phone 与 list.*.phone 等价
+		for i, item := range v {
+			if key == "*" {
+				v[i] = maskPath(item, rest, fn)
+			} else {
+				v[i] = maskPath(item, path, fn)
+			}
+		}
+	}
+	return value
+}
+
+// maskValue 脱敏字符串值，数组逐项处理，其它类型转为字符串后处理
+func maskValue(value interface{}, fn MaskFunc) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return fn(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskValue(item, fn)
+		}
+		return v
+	case map[string]interface{}:
+		return maskPlaceholder
+	default:
+		return fn(ToString(v))
+	}
+}
@@ -0,0 +1,46 @@
+package z
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusError 携带业务状态码的错误，如 db_provider.DBError、auth_provider.AuthError、BindError
+type StatusError interface {
+	error
+	Status() Status
+}
+
+// HandlerFunc 返回结果或错误的请求处理函数，由 H 转换为 gin 路由处理函数
+type HandlerFunc func(c *gin.Context) (any, error)
+
+// H 将返回结果或错误的处理函数转换为 gin 路由处理函数，省去每个控制器方法中的 Success / Failure 样板代码
+// 成功时以 Success 输出结果；失败时经 Tracker 记录堆栈并写入当前请求的 span，错误链中存在 StatusError 时使用其状态码；
+// 处理函数已自行输出响应或中止请求时不再输出
+//
+//	r.GET("/posts/:id", z.H(func(c *gin.Context) (any, error) {
+//		return svc.Get(c.Request.Context(), c.Param("id"))
+//	}))
+func H(handler HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.IsAborted() {
+			return
+		}
+		result, err := handler(c)
+		if c.IsAborted() || c.Writer.Written() {
+			return
+		}
+		if err != nil {
+			err = Tracker.ErrorCtx(c.Request.Context(), err)
+			var statusErr StatusError
+			if errors.As(err, &statusErr) {
+				Failure(c, err, statusErr.Status())
+				return
+			}
+			Failure(c, err)
+			return
+		}
+		Success(c, result)
+	}
+}
@@ -0,0 +1,165 @@
+package z
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const defaultHashRingReplicas = 160
+
+// HashRing 一致性哈希环，支持虚拟节点与成员变更
+type HashRing struct {
+	mu       sync.RWMutex
+	replicas int
+	keys     []uint32
+	ring     map[uint32]string
+	nodes    map[string]struct{}
+}
+
+// NewHashRing 创建一致性哈希环，replicas 为每个节点的虚拟节点数，小于等于 0 时默认 160
+func NewHashRing(replicas int, nodes ...string) *HashRing {
+	if replicas <= 0 {
+		replicas = defaultHashRingReplicas
+	}
+	r := &HashRing{
+		replicas: replicas,
+		ring:     map[uint32]string{},
+		nodes:    map[string]struct{}{},
+	}
+	r.Add(nodes...)
+	return r
+}
+
+// Add 添加节点
+func (r *HashRing) Add(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.add(nodes...)
+}
+
+// add 添加节点并重建索引，调用方需持有写锁
+func (r *HashRing) add(nodes ...string) {
+	for _, node := range nodes {
+		if node == "" {
+			continue
+		}
+		if _, ok := r.nodes[node]; ok {
+			continue
+		}
+		r.nodes[node] = struct{}{}
+		for i := 0; i < r.replicas; i++ {
+			r.ring[r.hash(node+"#"+strconv.Itoa(i))] = node
+		}
+	}
+	r.rebuild()
+}
+
+// Remove 移除节点
+func (r *HashRing) Remove(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, node := range nodes {
+		if _, ok := r.nodes[node]; !ok {
+			continue
+		}
+		delete(r.nodes, node)
+		for i := 0; i < r.replicas; i++ {
+			h := r.hash(node + "#" + strconv.Itoa(i))
+			if r.ring[h] == node {
+				delete(r.ring, h)
+			}
+		}
+	}
+	r.rebuild()
+}
+
+// Set 使用给定节点列表替换当前成员
+func (r *HashRing) Set(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ring = map[uint32]string{}
+	r.nodes = map[string]struct{}{}
+	r.add(nodes...)
+}
+
+// Get 返回 key 所属节点，环为空时返回空字符串
+func (r *HashRing) Get(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.keys) == 0 {
+		return ""
+	}
+	return r.ring[r.keys[r.search(r.hash(key))]]
+}
+
+// GetN 返回 key 顺时针方向上的 n 个不同节点，用于副本或故障转移
+func (r *HashRing) GetN(key string, n int) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.keys) == 0 || n <= 0 {
+		return nil
+	}
+	if n > len(r.nodes) {
+		n = len(r.nodes)
+	}
+	out := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	for i, idx := 0, r.search(r.hash(key)); i < len(r.keys) && len(out) < n; i++ {
+		node := r.ring[r.keys[(idx+i)%len(r.keys)]]
+		if _, ok := seen[node]; ok {
+			continue
+		}
+		seen[node] = struct{}{}
+		out = append(out, node)
+	}
+	return out
+}
+
+// Nodes 返回当前所有节点
+func (r *HashRing) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, 0, len(r.nodes))
+	for node := range r.nodes {
+		out = append(out, node)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Has 判断节点是否存在
+func (r *HashRing) Has(node string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.nodes[node]
+	return ok
+}
+
+// Len 返回节点数量
+func (r *HashRing) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.nodes)
+}
+
+func (r *HashRing) hash(key string) uint32 {
+	return crc32.ChecksumIEEE([]byte(key))
+}
+
+func (r *HashRing) search(h uint32) int {
+	idx := sort.Search(len(r.keys), func(i int) bool { return r.keys[i] >= h })
+	if idx == len(r.keys) {
+		idx = 0
+	}
+	return idx
+}
+
+func (r *HashRing) rebuild() {
+	r.keys = make([]uint32, 0, len(r.ring))
+	for k := range r.ring {
+		r.keys = append(r.keys, k)
+	}
+	sort.Slice(r.keys, func(i, j int) bool { return r.keys[i] < r.keys[j] })
+}
@@ -12,10 +12,12 @@ import (
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -38,8 +40,27 @@ type MultipartField struct {
 	FileName string    // 文件名，仅在 IsFile 为 true 时生效
 	Reader   io.Reader // 读取内容
 	IsFile   bool      // 是否为文件
+	Size     int64     // 内容长度，大于 0 时用于预计算 Content-Length；未设置时尝试从 Reader 推断
 }
 
+// replayableRequest 判断请求体能否重新构造用于重试：流式 io.Reader 和 multipart 字段发送一次即被消费，重发会得到空的或截断的请求体
+func replayableRequest(contentType RequestContentType, data interface{}) bool {
+	switch contentType {
+	case RequestContentTypeMultipart:
+		return false
+	case RequestContentTypeRaw:
+		switch data.(type) {
+		case string, []byte:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// RequestProgressFunc 上传进度回调，total 未知时为 -1
+type RequestProgressFunc func(written, total int64)
+
 // RequestOptions 请求选项
 type RequestOptions struct {
 	URL         string
@@ -48,6 +69,25 @@ type RequestOptions struct {
 	ContentType RequestContentType
 	Data        interface{}
 	Timeout     time.Duration
+	OnProgress  RequestProgressFunc // 上传进度回调（可选）
+	Client      *http.Client        // 自定义 client（可选），通过 NewHttpClient 创建可在多次请求间保持 cookie
+	Context     context.Context     // 请求上下文（可选），取消或截止时间会传递到请求
+}
+
+// HttpClientOptions 自定义 client 选项
+type HttpClientOptions struct {
+	CookieJar       bool          // 是否启用 cookie jar，在多次请求间保持 cookie
+	MaxRedirects    int           // 最大重定向次数，0 表示使用默认值 10，小于 0 表示禁止重定向
+	ForbidDowngrade bool          // 是否禁止从 https 重定向到 http
+	Timeout         time.Duration // client 超时时间，0 表示由请求上下文控制
+}
+
+// HttpResponse 完整的请求响应
+type HttpResponse struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
 }
 
 var (
@@ -70,8 +110,53 @@ func getClient() *http.Client {
 	return defaultClient
 }
 
+// NewHttpClient 创建带 cookie jar 和重定向策略的 client，与默认 client 共享连接池
+func NewHttpClient(opt HttpClientOptions) (*http.Client, error) {
+	client := &http.Client{
+		Timeout:   opt.Timeout,
+		Transport: getClient().Transport,
+	}
+
+	if opt.CookieJar {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		client.Jar = jar
+	}
+
+	maxRedirects := opt.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = 10
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if maxRedirects < 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		if opt.ForbidDowngrade && len(via) > 0 && via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme == "http" {
+			return fmt.Errorf("redirect downgrade from https to http is forbidden: %s", req.URL.String())
+		}
+		return nil
+	}
+
+	return client, nil
+}
+
 // Request 发起请求
 func Request(opt RequestOptions) ([]byte, error) {
+	resp, err := RequestWithResponse(opt)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// RequestWithResponse 发起请求并返回状态码、响应头和响应体
+// 状态码大于等于 400 时同时返回响应和错误
+func RequestWithResponse(opt RequestOptions) (*HttpResponse, error) {
 	if opt.Method == "" {
 		opt.Method = http.MethodPost
 	}
@@ -80,6 +165,8 @@ func Request(opt RequestOptions) ([]byte, error) {
 	}
 	headers := make(http.Header)
 	var body io.Reader
+	var bodyCloser io.Closer
+	contentLength := int64(-1)
 
 	// 构造 body 和 headers
 	switch opt.ContentType {
@@ -108,28 +195,9 @@ func Request(opt RequestOptions) ([]byte, error) {
 		if !ok {
 			return nil, errors.New("multipart content-type requires map[string]MultipartField")
 		}
-		var b bytes.Buffer
-		writer := multipart.NewWriter(&b)
-		for key, field := range form {
-			if field.IsFile {
-				part, err := writer.CreateFormFile(key, field.FileName)
-				if err != nil {
-					return nil, err
-				}
-				_, err = io.Copy(part, field.Reader)
-				if err != nil {
-					return nil, err
-				}
-			} else {
-				err := writer.WriteField(key, readToString(field.Reader))
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
-		writer.Close()
-		body = &b
-		headers.Set("Content-Type", writer.FormDataContentType())
+		pr, ct, length := newMultipartBody(form)
+		body, bodyCloser, contentLength = pr, pr, length
+		headers.Set("Content-Type", ct)
 
 	case RequestContentTypeXML:
 		xmlBytes, err := xml.Marshal(opt.Data)
@@ -164,13 +232,42 @@ func Request(opt RequestOptions) ([]byte, error) {
 	}
 
 	// 构造请求上下文
-	ctx, cancel := context.WithTimeout(context.Background(), opt.Timeout)
+	parent := opt.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, opt.Timeout)
 	defer cancel()
 
+	// 流式 body 在请求未发出时需要关闭，以结束写入协程
+	if bodyCloser != nil {
+		defer bodyCloser.Close()
+	}
+
+	if contentLength < 0 {
+		contentLength = readerLen(body)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, opt.Method, opt.URL, body)
 	if err != nil {
 		return nil, err
 	}
+	if opt.OnProgress != nil && req.Body != nil && req.Body != http.NoBody {
+		req.Body = &progressReader{reader: req.Body, total: contentLength, onProgress: opt.OnProgress}
+		// 保留 GetBody，重定向（307/308）或 HTTP/2 重试重放请求体时同样回调进度
+		if getBody := req.GetBody; getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				rc, err := getBody()
+				if err != nil || rc == http.NoBody {
+					return rc, err
+				}
+				return &progressReader{reader: rc, total: contentLength, onProgress: opt.OnProgress}, nil
+			}
+		}
+	}
+	if contentLength >= 0 {
+		req.ContentLength = contentLength
+	}
 
 	// 合并 headers
 	for k, v := range opt.Headers {
@@ -183,7 +280,16 @@ func Request(opt RequestOptions) ([]byte, error) {
 	}
 
 	// 发起请求
-	client := getClient()
+	client := opt.Client
+	if client == nil {
+		client = getClient()
+	}
+	if client.Timeout > 0 && opt.Timeout > client.Timeout {
+		// 超时时间超过 client 时由上下文控制，避免大文件上传被提前中断
+		c := *client
+		c.Timeout = 0
+		client = &c
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -195,11 +301,18 @@ func Request(opt RequestOptions) ([]byte, error) {
 		return nil, err
 	}
 
+	result := &HttpResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Body:       respBody,
+	}
+
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("http error: %s\n%s", resp.Status, string(respBody))
+		return result, &HttpStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: respBody}
 	}
 
-	return respBody, nil
+	return result, nil
 }
 
 // RequestSSEChannel 发起 SSE 请求，返回一个只读通道供外部消费事件
@@ -234,7 +347,11 @@ func RequestSSEChannel(opt RequestOptions) (<-chan string, <-chan error, context
 	}
 
 	// 创建超时上下文
-	ctx, cancel := context.WithTimeout(context.Background(), opt.Timeout)
+	parent := opt.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, opt.Timeout)
 	req, err := http.NewRequestWithContext(ctx, opt.Method, opt.URL, body)
 	if err != nil {
 		cancel()
@@ -312,13 +429,132 @@ func GetSSEChannel(url string, headers map[string]string) (<-chan string, <-chan
 	})
 }
 
-// 读取流为字符串（用于 Multipart 普通字段）
-func readToString(r io.Reader) string {
-	if r == nil {
-		return ""
+// newMultipartBody 通过 io.Pipe 流式构造 multipart 请求体，避免将大文件整体读入内存
+// 所有字段长度均可确定时返回预计算的 Content-Length，否则返回 -1
+func newMultipartBody(form map[string]MultipartField) (io.ReadCloser, string, int64) {
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	contentLength := multipartContentLength(form, keys, boundary)
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	_ = writer.SetBoundary(boundary)
+
+	go func() {
+		for _, key := range keys {
+			field := form[key]
+			part, err := createMultipartPart(writer, key, field)
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if field.Reader != nil {
+				if _, err := io.Copy(part, field.Reader); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+			}
+		}
+		pw.CloseWithError(writer.Close())
+	}()
+
+	return pr, writer.FormDataContentType(), contentLength
+}
+
+// multipartContentLength 预计算 multipart 请求体长度，存在未知长度字段时返回 -1
+func multipartContentLength(form map[string]MultipartField, keys []string, boundary string) int64 {
+	counter := &countingWriter{}
+	writer := multipart.NewWriter(counter)
+	_ = writer.SetBoundary(boundary)
+
+	var total int64
+	for _, key := range keys {
+		field := form[key]
+		size := field.Size
+		if size <= 0 {
+			size = readerLen(field.Reader)
+		}
+		if size < 0 {
+			return -1
+		}
+		if _, err := createMultipartPart(writer, key, field); err != nil {
+			return -1
+		}
+		total += size
+	}
+	if err := writer.Close(); err != nil {
+		return -1
+	}
+
+	return total + counter.n
+}
+
+// createMultipartPart 创建 multipart 字段头
+func createMultipartPart(writer *multipart.Writer, key string, field MultipartField) (io.Writer, error) {
+	if field.IsFile {
+		return writer.CreateFormFile(key, field.FileName)
+	}
+	return writer.CreateFormField(key)
+}
+
+// readerLen 尝试推断 Reader 剩余可读长度，无法确定时返回 -1
+func readerLen(r io.Reader) int64 {
+	switch v := r.(type) {
+	case nil:
+		return 0
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
+
+// countingWriter 仅统计写入字节数
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// progressReader 在读取请求体时回调上传进度
+type progressReader struct {
+	reader     io.Reader
+	total      int64
+	written    int64
+	onProgress RequestProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.written += int64(n)
+		r.onProgress(r.written, r.total)
+	}
+	return n, err
+}
+
+func (r *progressReader) Close() error {
+	if c, ok := r.reader.(io.Closer); ok {
+		return c.Close()
 	}
-	b, _ := io.ReadAll(r)
-	return string(b)
+	return nil
 }
 
 // Get 请求
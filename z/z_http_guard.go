@@ -0,0 +1,128 @@
+package z
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// OutboundError 出站请求被 OutboundPolicy 拒绝，属于确定性错误，不应重试
+type OutboundError struct {
+	Host   string
+	Reason string
+}
+
+func (e *OutboundError) Error() string {
+	return fmt.Sprintf("outbound request to %s denied: %s", e.Host, e.Reason)
+}
+
+// RetryClass 实现 RetryClassifier
+func (e *OutboundError) RetryClass() RetryClass {
+	return RetryPermanent
+}
+
+// OutboundPolicy 出站请求限制，用于请求调用方提供的地址（如任务回调、Webhook），防止 SSRF
+// 主机名在发起请求和每次重定向时校验，IP 在 DNS 解析后、建立连接时校验，避免 DNS 重绑定绕过
+type OutboundPolicy struct {
+	AllowedHosts []string // 允许的主机名，支持 *.example.com 匹配子域名；为空时不限主机名
+	AllowPrivate bool     // 允许回环、内网、链路本地（含云元数据 169.254.169.254）等非公网地址
+}
+
+// HostAllowed 判断主机名是否在允许列表中
+func (p OutboundPolicy) HostAllowed(host string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range p.AllowedHosts {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == host {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok && strings.HasSuffix(host, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckURL 校验地址的协议（http / https）和主机名，主机为 IP 字面量时同时校验地址范围
+func (p OutboundPolicy) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return &OutboundError{Host: u.Host, Reason: "scheme must be http or https"}
+	}
+	host := u.Hostname()
+	if host == "" {
+		return &OutboundError{Host: u.Host, Reason: "missing host"}
+	}
+	if !p.HostAllowed(host) {
+		return &OutboundError{Host: host, Reason: "host not allowed"}
+	}
+	if ip := net.ParseIP(host); ip != nil && !p.AllowPrivate && !IsPublicIP(ip) {
+		return &OutboundError{Host: host, Reason: "address not allowed"}
+	}
+	return nil
+}
+
+// NewHttpClient 创建遵循该策略的 client，不使用代理；timeout 为 0 时由请求上下文控制
+func (p OutboundPolicy) NewHttpClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		// Control 在 DNS 解析后、连接前调用，address 为实际连接的 IP:端口
+		Control: func(_, address string, _ syscall.RawConn) error {
+			if p.AllowPrivate {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+				return &OutboundError{Host: host, Reason: "address not allowed"}
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after %d redirects", len(via))
+			}
+			return p.CheckURL(req.URL.String())
+		},
+	}
+}
+
+// cgnatNet 运营商级 NAT 地址段（RFC 6598），net.IP.IsPrivate 不包含
+var cgnatNet = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP 判断是否为公网地址：排除回环、内网、链路本地、组播、未指定地址及运营商级 NAT 地址
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil && (ip4[0] == 0 || cgnatNet.Contains(ip4)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,535 @@
+package z
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// HttpRecorderMode 录制回放模式
+type HttpRecorderMode string
+
+const (
+	HttpReplay HttpRecorderMode = "replay" // 仅从 fixture 回放，不访问网络，未匹配的请求返回 ErrHttpContractDrift
+	HttpRecord HttpRecorderMode = "record" // 访问真实接口并录制，Finish 时覆盖写入 fixture
+	HttpVerify HttpRecorderMode = "verify" // 访问真实接口，与 fixture 对比状态码及 JSON 结构，不一致记为契约漂移
+)
+
+// HttpRecorderModeEnv 未指定模式时读取的环境变量，默认 replay
+const HttpRecorderModeEnv = "ZLIB_HTTP_RECORDER"
+
+// ErrHttpContractDrift 请求或响应与 fixture 不一致
+var ErrHttpContractDrift = errors.New("http: contract drift")
+
+// DefaultScrubFields 默认脱敏的 JSON 字段名（不区分大小写）
+var DefaultScrubFields = []string{"password", "passwd", "secret", "client_secret", "token", "access_token", "refresh_token", "api_key"}
+
+// HttpRecorderOptions 录制回放选项
+type HttpRecorderOptions struct {
+	Mode        HttpRecorderMode  // 为空时读取环境变量 ZLIB_HTTP_RECORDER
+	Transport   http.RoundTripper // record / verify 使用的真实 transport，默认复用默认 client 的连接池
+	Scrubber    ReportScrubber    // 请求头、URL 及非 JSON body 的脱敏规则，录制前应用于请求和响应
+	ScrubFields []string          // JSON body 中需要脱敏的字段名，为空时使用 DefaultScrubFields
+	// Match 判断请求是否与录制的请求一致，默认比较 method、URL 和 body（JSON 按语义比较，multipart 忽略 body）
+	Match func(req, recorded HttpRecordedRequest) bool
+}
+
+// HttpRecordedRequest 录制的请求（已脱敏）
+type HttpRecordedRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"` // 非 UTF-8 内容为 base64
+}
+
+// HttpRecordedResponse 录制的响应（已脱敏）
+type HttpRecordedResponse struct {
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// HttpInteraction 一次请求与响应
+type HttpInteraction struct {
+	Request  HttpRecordedRequest  `json:"request"`
+	Response HttpRecordedResponse `json:"response"`
+}
+
+// HttpContractDriftError 契约漂移详情，errors.Is(err, ErrHttpContractDrift) 为 true
+type HttpContractDriftError struct {
+	Method string
+	URL    string
+	Reason string
+}
+
+func (e *HttpContractDriftError) Error() string {
+	return fmt.Sprintf("http: contract drift: %s %s: %s", e.Method, e.URL, e.Reason)
+}
+
+func (e *HttpContractDriftError) Is(target error) bool {
+	return target == ErrHttpContractDrift
+}
+
+type httpFixture struct {
+	Interactions []HttpInteraction `json:"interactions"`
+}
+
+// HttpRecorder 第三方接口录制回放，实现 http.RoundTripper，用于契约测试
+// 测试中录制一次（ZLIB_HTTP_RECORDER=record），CI 中回放 fixture 无需网络，定期以 verify 模式检查接口是否变化
+//
+//	rec, err := z.NewHttpRecorder("testdata/payment.json")
+//	defer rec.Install()()
+//	defer func() {
+//		if err := rec.Finish(); err != nil {
+//			t.Fatal(err)
+//		}
+//	}()
+type HttpRecorder struct {
+	path      string
+	opt       HttpRecorderOptions
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []HttpInteraction
+	used         []bool
+	recorded     []HttpInteraction
+	drifts       []error
+}
+
+// NewHttpRecorder 创建录制回放器，replay / verify 模式加载 path 指向的 fixture
+func NewHttpRecorder(path string, opt ...HttpRecorderOptions) (*HttpRecorder, error) {
+	r := &HttpRecorder{path: path}
+	if len(opt) > 0 {
+		r.opt = opt[0]
+	}
+	if r.opt.Mode == "" {
+		r.opt.Mode = HttpRecorderMode(strings.ToLower(strings.TrimSpace(os.Getenv(HttpRecorderModeEnv))))
+	}
+	if r.opt.Mode == "" {
+		r.opt.Mode = HttpReplay
+	}
+	if len(r.opt.ScrubFields) == 0 {
+		r.opt.ScrubFields = DefaultScrubFields
+	}
+	r.transport = r.opt.Transport
+	if r.transport == nil {
+		r.transport = getClient().Transport
+	}
+
+	switch r.opt.Mode {
+	case HttpRecord:
+		return r, nil
+	case HttpReplay, HttpVerify:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("http recorder: load fixture: %w", err)
+		}
+		var fixture httpFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("http recorder: parse fixture %s: %w", path, err)
+		}
+		r.interactions = fixture.Interactions
+		r.used = make([]bool, len(fixture.Interactions))
+		return r, nil
+	default:
+		return nil, fmt.Errorf("http recorder: invalid mode: %s", r.opt.Mode)
+	}
+}
+
+// Mode 当前模式
+func (r *HttpRecorder) Mode() HttpRecorderMode {
+	return r.opt.Mode
+}
+
+// Client 返回经过录制回放的 client，可传给 RequestOptions.Client
+func (r *HttpRecorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Install 替换默认 client 的 transport，使 Get / Post / Request 等经过录制回放，返回恢复函数
+// 仅用于测试；Install 之前通过 NewHttpClient 创建的 client 不受影响
+func (r *HttpRecorder) Install() func() {
+	client := getClient()
+	prev := client.Transport
+	client.Transport = r
+	return func() { client.Transport = prev }
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (r *HttpRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recordedReq := r.recordRequest(req, body)
+
+	if r.opt.Mode == HttpReplay {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		i := r.find(recordedReq)
+		if i < 0 {
+			drift := &HttpContractDriftError{Method: recordedReq.Method, URL: recordedReq.URL, Reason: "no matching recorded interaction"}
+			r.drifts = append(r.drifts, drift)
+			return nil, drift
+		}
+		r.used[i] = true
+		return replayResponse(req, r.interactions[i].Response)
+	}
+
+	outbound := req.Clone(req.Context())
+	if body != nil {
+		outbound.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := r.transport.RoundTrip(outbound)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	recordedResp := r.recordResponse(resp, respBody)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opt.Mode == HttpRecord {
+		r.recorded = append(r.recorded, HttpInteraction{Request: recordedReq, Response: recordedResp})
+		return resp, nil
+	}
+
+	// verify：与录制的响应对比
+	i := r.find(recordedReq)
+	if i < 0 {
+		r.drifts = append(r.drifts, &HttpContractDriftError{Method: recordedReq.Method, URL: recordedReq.URL, Reason: "no matching recorded interaction"})
+		return resp, nil
+	}
+	r.used[i] = true
+	if reason := compareResponses(r.interactions[i].Response, recordedResp); reason != "" {
+		r.drifts = append(r.drifts, &HttpContractDriftError{Method: recordedReq.Method, URL: recordedReq.URL, Reason: reason})
+	}
+	return resp, nil
+}
+
+// Finish 结束录制回放：record 模式写入 fixture；replay / verify 模式返回契约漂移及未使用的录制
+func (r *HttpRecorder) Finish() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.opt.Mode == HttpRecord {
+		data, err := json.MarshalIndent(httpFixture{Interactions: r.recorded}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(r.path, append(data, '\n'), 0o644)
+	}
+
+	errs := append([]error(nil), r.drifts...)
+	for i, used := range r.used {
+		if !used {
+			req := r.interactions[i].Request
+			errs = append(errs, &HttpContractDriftError{Method: req.Method, URL: req.URL, Reason: "recorded interaction was not requested"})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// find 查找第一条未使用且匹配的录制，调用方需持有锁
+func (r *HttpRecorder) find(req HttpRecordedRequest) int {
+	match := r.opt.Match
+	if match == nil {
+		match = matchRecordedRequest
+	}
+	for i, interaction := range r.interactions {
+		if !r.used[i] && match(req, interaction.Request) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *HttpRecorder) recordRequest(req *http.Request, body []byte) HttpRecordedRequest {
+	scrubber := r.scrubber()
+	out := HttpRecordedRequest{
+		Method: req.Method,
+		URL:    scrubber.value(req.URL.String()),
+		Header: scrubber.header(req.Header),
+	}
+	out.Body, out.BodyEncoding = r.scrubBody(req.Header.Get("Content-Type"), body)
+	return out
+}
+
+func (r *HttpRecorder) recordResponse(resp *http.Response, body []byte) HttpRecordedResponse {
+	out := HttpRecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     r.scrubber().header(resp.Header),
+	}
+	out.Body, out.BodyEncoding = r.scrubBody(resp.Header.Get("Content-Type"), body)
+	return out
+}
+
+// scrubBody JSON 按字段名脱敏，文本按 Scrubber.Patterns 脱敏，非 UTF-8 内容以 base64 保存
+func (r *HttpRecorder) scrubBody(contentType string, body []byte) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	if !utf8.Valid(body) {
+		return base64.StdEncoding.EncodeToString(body), "base64"
+	}
+	if isJSONContentType(contentType) {
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			if b, err := json.Marshal(scrubJSONFields(v, r.opt.ScrubFields)); err == nil {
+				return string(b), ""
+			}
+		}
+	}
+	return r.scrubber().value(string(body)), ""
+}
+
+func (r *HttpRecorder) scrubber() recorderScrubber {
+	return recorderScrubber{r.opt.Scrubber}
+}
+
+// recorderScrubber 复用 ReportScrubber 的请求头与正则规则
+type recorderScrubber struct {
+	ReportScrubber
+}
+
+func (s recorderScrubber) value(v string) string {
+	patterns := s.Patterns
+	if patterns == nil {
+		patterns = DefaultScrubPatterns
+	}
+	for _, p := range patterns {
+		if p.NumSubexp() > 0 {
+			v = p.ReplaceAllString(v, "${1}"+scrubbed)
+		} else {
+			v = p.ReplaceAllString(v, scrubbed)
+		}
+	}
+	return v
+}
+
+func (s recorderScrubber) header(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	headers := s.Headers
+	if len(headers) == 0 {
+		headers = DefaultScrubHeaders
+	}
+	out := make(http.Header, len(h))
+	for k, vs := range h {
+		filtered := false
+		for _, name := range headers {
+			if strings.EqualFold(k, name) {
+				filtered = true
+				break
+			}
+		}
+		for _, v := range vs {
+			if filtered {
+				out[k] = append(out[k], scrubbed)
+			} else {
+				out[k] = append(out[k], s.value(v))
+			}
+		}
+	}
+	return out
+}
+
+func scrubJSONFields(v interface{}, fields []string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			sensitive := false
+			for _, f := range fields {
+				if strings.EqualFold(k, f) {
+					sensitive = true
+					break
+				}
+			}
+			if sensitive {
+				val[k] = scrubbed
+			} else {
+				val[k] = scrubJSONFields(item, fields)
+			}
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = scrubJSONFields(val[i], fields)
+		}
+		return val
+	}
+	return v
+}
+
+// matchRecordedRequest 默认匹配规则
+func matchRecordedRequest(req, recorded HttpRecordedRequest) bool {
+	if !strings.EqualFold(req.Method, recorded.Method) || !sameURL(req.URL, recorded.URL) {
+		return false
+	}
+	contentType := req.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		// multipart 边界随机生成，不比较 body
+		return true
+	case isJSONContentType(contentType):
+		var a, b interface{}
+		if json.Unmarshal([]byte(req.Body), &a) == nil && json.Unmarshal([]byte(recorded.Body), &b) == nil {
+			return reflect.DeepEqual(a, b)
+		}
+	case mediaType == string(RequestContentTypeForm):
+		a, errA := url.ParseQuery(req.Body)
+		b, errB := url.ParseQuery(recorded.Body)
+		if errA == nil && errB == nil {
+			return reflect.DeepEqual(a, b)
+		}
+	}
+	return req.Body == recorded.Body
+}
+
+// sameURL 比较 URL，查询参数不区分顺序
+func sameURL(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	if ua.Scheme != ub.Scheme || ua.Host != ub.Host || ua.Path != ub.Path {
+		return false
+	}
+	return reflect.DeepEqual(ua.Query(), ub.Query())
+}
+
+// compareResponses 对比状态码与 JSON 结构（字段名及类型），返回不一致的原因
+func compareResponses(recorded, actual HttpRecordedResponse) string {
+	if recorded.StatusCode != actual.StatusCode {
+		return fmt.Sprintf("status code changed: %d -> %d", recorded.StatusCode, actual.StatusCode)
+	}
+	if !isJSONContentType(recorded.Header.Get("Content-Type")) {
+		return ""
+	}
+	var a, b interface{}
+	if json.Unmarshal([]byte(recorded.Body), &a) != nil {
+		return ""
+	}
+	if err := json.Unmarshal([]byte(actual.Body), &b); err != nil {
+		return "response is no longer valid JSON"
+	}
+	var diffs []string
+	compareJSONShape("$", a, b, &diffs)
+	return strings.Join(diffs, "; ")
+}
+
+func compareJSONShape(path string, recorded, actual interface{}, diffs *[]string) {
+	if recorded == nil || actual == nil {
+		// null 值无法确定类型
+		return
+	}
+	if ta, tb := jsonKind(recorded), jsonKind(actual); ta != tb {
+		*diffs = append(*diffs, fmt.Sprintf("%s: type changed: %s -> %s", path, ta, tb))
+		return
+	}
+	switch a := recorded.(type) {
+	case map[string]interface{}:
+		b := actual.(map[string]interface{})
+		keys := make([]string, 0, len(a))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v, ok := b[k]
+			if !ok {
+				*diffs = append(*diffs, fmt.Sprintf("%s.%s: field removed", path, k))
+				continue
+			}
+			compareJSONShape(path+"."+k, a[k], v, diffs)
+		}
+	case []interface{}:
+		b := actual.([]interface{})
+		if len(a) > 0 && len(b) > 0 {
+			compareJSONShape(path+"[0]", a[0], b[0], diffs)
+		}
+	}
+}
+
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+func replayResponse(req *http.Request, recorded HttpRecordedResponse) (*http.Response, error) {
+	body := []byte(recorded.Body)
+	if recorded.BodyEncoding == "base64" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(recorded.Body); err != nil {
+			return nil, err
+		}
+	}
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
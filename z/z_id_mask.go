@@ -0,0 +1,219 @@
+package z
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 错误定义
+var (
+	ErrIDMaskerNotConfigured = errors.New("id masker not configured")
+	ErrInvalidMaskedID       = errors.New("invalid masked id")
+)
+
+const idMaskAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// idMaskLength 16 字节密文的 base62 定长编码长度
+const idMaskLength = 22
+
+// IDMasker 将数字主键加密为不透明字符串，避免对外暴露自增 ID 被遍历
+// 采用 AES 单块加密：明文为 8 字节 ID + 8 字节校验，解码时校验不通过即视为非法
+type IDMasker struct {
+	block cipher.Block
+	check [8]byte
+}
+
+// NewIDMasker 使用密钥创建 IDMasker，密钥可为任意长度字符串
+func NewIDMasker(key string) (*IDMasker, error) {
+	if strings.TrimSpace(key) == "" {
+		return nil, errors.New("id mask key is empty")
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:16])
+	if err != nil {
+		return nil, err
+	}
+	m := &IDMasker{block: block}
+	copy(m.check[:], sum[16:24])
+	return m, nil
+}
+
+// Encode 加密 ID
+func (m *IDMasker) Encode(id int64) string {
+	var plain, out [16]byte
+	binary.BigEndian.PutUint64(plain[:8], uint64(id))
+	copy(plain[8:], m.check[:])
+	m.block.Encrypt(out[:], plain[:])
+
+	s := new(big.Int).SetBytes(out[:]).Text(62)
+	if len(s) < idMaskLength {
+		s = strings.Repeat("0", idMaskLength-len(s)) + s
+	}
+	return s
+}
+
+// Decode 解密 ID
+func (m *IDMasker) Decode(s string) (int64, error) {
+	if len(s) != idMaskLength || strings.Trim(s, idMaskAlphabet) != "" {
+		return 0, ErrInvalidMaskedID
+	}
+	n, ok := new(big.Int).SetString(s, 62)
+	if !ok || n.BitLen() > 128 {
+		return 0, ErrInvalidMaskedID
+	}
+	var in, plain [16]byte
+	n.FillBytes(in[:])
+	m.block.Decrypt(plain[:], in[:])
+	if subtle.ConstantTimeCompare(plain[8:], m.check[:]) != 1 {
+		return 0, ErrInvalidMaskedID
+	}
+	return int64(binary.BigEndian.Uint64(plain[:8])), nil
+}
+
+var (
+	idMaskerMu sync.RWMutex
+	idMasker   *IDMasker
+)
+
+// SetIDMasker 设置全局 IDMasker，MaskedID 的序列化与绑定依赖该实例
+func SetIDMasker(m *IDMasker) {
+	idMaskerMu.Lock()
+	defer idMaskerMu.Unlock()
+	idMasker = m
+}
+
+func getIDMasker() (*IDMasker, error) {
+	idMaskerMu.RLock()
+	defer idMaskerMu.RUnlock()
+	if idMasker == nil {
+		return nil, ErrIDMaskerNotConfigured
+	}
+	return idMasker, nil
+}
+
+// MaskID 使用全局 IDMasker 加密 ID
+func MaskID(id int64) (string, error) {
+	m, err := getIDMasker()
+	if err != nil {
+		return "", err
+	}
+	return m.Encode(id), nil
+}
+
+// UnmaskID 使用全局 IDMasker 解密 ID
+func UnmaskID(s string) (int64, error) {
+	m, err := getIDMasker()
+	if err != nil {
+		return 0, err
+	}
+	return m.Decode(s)
+}
+
+// MaskedID 数据库中存储为整数，JSON 输出与请求绑定时使用加密字符串
+type MaskedID int64
+
+// Int64 返回原始 ID
+func (id MaskedID) Int64() int64 {
+	return int64(id)
+}
+
+// String 返回加密字符串，未配置 IDMasker 时返回空字符串
+func (id MaskedID) String() string {
+	s, _ := MaskID(int64(id))
+	return s
+}
+
+// MarshalJSON 输出加密字符串
+func (id MaskedID) MarshalJSON() ([]byte, error) {
+	s, err := MaskID(int64(id))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(`"` + s + `"`), nil
+}
+
+// UnmarshalJSON 仅接受加密字符串，拒绝明文数字
+func (id *MaskedID) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return ErrInvalidMaskedID
+	}
+	return id.UnmarshalText([]byte(s[1 : len(s)-1]))
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (id MaskedID) MarshalText() ([]byte, error) {
+	s, err := MaskID(int64(id))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (id *MaskedID) UnmarshalText(text []byte) error {
+	v, err := UnmaskID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = MaskedID(v)
+	return nil
+}
+
+// UnmarshalParam 支持 gin 的 uri/form/query 绑定
+func (id *MaskedID) UnmarshalParam(param string) error {
+	return id.UnmarshalText([]byte(param))
+}
+
+// Value 实现 driver.Valuer，数据库中存储原始整数
+func (id MaskedID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+// Scan 实现 sql.Scanner
+func (id *MaskedID) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*id = 0
+	case int64:
+		*id = MaskedID(v)
+	case int32:
+		*id = MaskedID(v)
+	case uint64:
+		*id = MaskedID(v)
+	case []byte:
+		var n int64
+		if _, err := fmt.Sscan(string(v), &n); err != nil {
+			return err
+		}
+		*id = MaskedID(n)
+	case string:
+		var n int64
+		if _, err := fmt.Sscan(v, &n); err != nil {
+			return err
+		}
+		*id = MaskedID(n)
+	default:
+		return fmt.Errorf("cannot scan %T into MaskedID", value)
+	}
+	return nil
+}
+
+// ParamMaskedID 从路径参数中解码加密 ID
+func ParamMaskedID(c *gin.Context, name string) (int64, error) {
+	return UnmaskID(c.Param(name))
+}
@@ -0,0 +1,194 @@
+package z
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/currency"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+)
+
+// LocaleInfo 请求的区域设置：语言、时区、货币
+type LocaleInfo struct {
+	Locale   string         `json:"locale"`   // 语言标签，如 zh-CN、en
+	Timezone *time.Location `json:"-"`        // 时区
+	Currency string         `json:"currency"` // ISO 4217 货币代码，如 CNY、USD
+}
+
+// TimezoneName 返回时区名称
+func (l LocaleInfo) TimezoneName() string {
+	if l.Timezone == nil {
+		return ""
+	}
+	return l.Timezone.String()
+}
+
+// Language 返回主语言，如 zh-CN -> zh
+func (l LocaleInfo) Language() string {
+	lang := strings.ToLower(strings.ReplaceAll(l.Locale, "_", "-"))
+	if i := strings.Index(lang, "-"); i > 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+type localeKey struct{}
+
+var (
+	localeMu      sync.RWMutex
+	defaultLocale = LocaleInfo{Locale: "en", Timezone: time.Local, Currency: "USD"}
+	translators   = map[string]locales.Translator{"en": en.New(), "zh": zh.New()}
+)
+
+// SetDefaultLocale 设置未携带区域设置的请求及后台任务使用的默认值，空字段保持不变
+func SetDefaultLocale(l LocaleInfo) {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	if l.Locale != "" {
+		defaultLocale.Locale = l.Locale
+	}
+	if l.Timezone != nil {
+		defaultLocale.Timezone = l.Timezone
+	}
+	if l.Currency != "" {
+		defaultLocale.Currency = strings.ToUpper(l.Currency)
+	}
+}
+
+// DefaultLocale 返回默认区域设置
+func DefaultLocale() LocaleInfo {
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	return defaultLocale
+}
+
+// RegisterLocale 注册语言的格式化规则，默认内置 en、zh
+// 例如 z.RegisterLocale(ja.New())，ja 为 github.com/go-playground/locales/ja
+func RegisterLocale(tr locales.Translator) {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	translators[LocaleInfo{Locale: tr.Locale()}.Language()] = tr
+}
+
+// HasLocale 判断语言是否已注册格式化规则
+func HasLocale(locale string) bool {
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	_, ok := translators[LocaleInfo{Locale: locale}.Language()]
+	return ok
+}
+
+// WithLocale 将区域设置写入 context，空字段使用默认值
+func WithLocale(ctx context.Context, l LocaleInfo) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, localeKey{}, l)
+}
+
+// LocaleFrom 读取 context 中的区域设置，未设置的字段使用默认值
+func LocaleFrom(ctx context.Context) LocaleInfo {
+	l := DefaultLocale()
+	if ctx == nil {
+		return l
+	}
+	if v, ok := ctx.Value(localeKey{}).(LocaleInfo); ok {
+		if v.Locale != "" {
+			l.Locale = v.Locale
+		}
+		if v.Timezone != nil {
+			l.Timezone = v.Timezone
+		}
+		if v.Currency != "" {
+			l.Currency = v.Currency
+		}
+	}
+	return l
+}
+
+// LocaleTranslator 返回请求语言的格式化规则，未注册时使用默认语言，仍未注册时使用 en
+func LocaleTranslator(ctx context.Context) locales.Translator {
+	l := LocaleFrom(ctx)
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	if tr, ok := translators[l.Language()]; ok {
+		return tr
+	}
+	if tr, ok := translators[LocaleInfo{Locale: defaultLocale.Locale}.Language()]; ok {
+		return tr
+	}
+	return translators["en"]
+}
+
+// LocalTime 将时间转换到请求时区
+func LocalTime(ctx context.Context, t time.Time) time.Time {
+	if tz := LocaleFrom(ctx).Timezone; tz != nil {
+		return t.In(tz)
+	}
+	return t
+}
+
+// FormatLocalTime 按请求时区格式化时间，layout 为空时使用 2006-01-02 15:04:05
+func FormatLocalTime(ctx context.Context, t time.Time, layout string) string {
+	if layout == "" {
+		layout = time.DateTime
+	}
+	return LocalTime(ctx, t).Format(layout)
+}
+
+// FormatLocalDate 按请求语言和时区格式化日期，如 en 为 Jan 2, 2006，zh 为 2006年1月2日
+func FormatLocalDate(ctx context.Context, t time.Time) string {
+	return LocaleTranslator(ctx).FmtDateMedium(LocalTime(ctx, t))
+}
+
+// FormatNumber 按请求语言格式化数字，decimals 为保留小数位
+func FormatNumber(ctx context.Context, num float64, decimals uint64) string {
+	return LocaleTranslator(ctx).FmtNumber(num, decimals)
+}
+
+// currencyTypes 常用货币代码
+var currencyTypes = map[string]currency.Type{
+	"CNY": currency.CNY, "USD": currency.USD, "EUR": currency.EUR, "GBP": currency.GBP,
+	"JPY": currency.JPY, "HKD": currency.HKD, "TWD": currency.TWD, "KRW": currency.KRW,
+	"SGD": currency.SGD, "AUD": currency.AUD, "CAD": currency.CAD, "CHF": currency.CHF,
+	"RUB": currency.RUB, "INR": currency.INR, "THB": currency.THB, "MYR": currency.MYR,
+	"VND": currency.VND, "IDR": currency.IDR, "PHP": currency.PHP, "BRL": currency.BRL,
+}
+
+// currencyDigits 小数位不为 2 的货币
+var currencyDigits = map[string]uint64{"JPY": 0, "KRW": 0, "VND": 0, "IDR": 0}
+
+// CurrencyDigits 返回货币的小数位数
+func CurrencyDigits(code string) uint64 {
+	if d, ok := currencyDigits[strings.ToUpper(code)]; ok {
+		return d
+	}
+	return 2
+}
+
+// FormatMoney 按请求语言和货币格式化金额，如 ¥1,234.50、$1,234.50；未内置的货币以代码作为后缀
+func FormatMoney(ctx context.Context, amount float64) string {
+	return FormatMoneyIn(ctx, amount, LocaleFrom(ctx).Currency)
+}
+
+// FormatMoneyIn 按请求语言格式化指定货币的金额
+func FormatMoneyIn(ctx context.Context, amount float64, code string) string {
+	code = strings.ToUpper(code)
+	tr := LocaleTranslator(ctx)
+	digits := CurrencyDigits(code)
+	if t, ok := currencyTypes[code]; ok {
+		return tr.FmtCurrency(amount, digits, t)
+	}
+	return tr.FmtNumber(amount, digits) + " " + code
+}
+
+// FormatMinorMoney 格式化以最小单位（如分）存储的金额
+func FormatMinorMoney(ctx context.Context, minor int64) string {
+	code := LocaleFrom(ctx).Currency
+	return FormatMoneyIn(ctx, float64(minor)/math.Pow10(int(CurrencyDigits(code))), code)
+}
@@ -0,0 +1,450 @@
+package z
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// 节点代理默认值
+const (
+	DefaultNodeHealthPath  = "/readyz"
+	DefaultNodeMetricsPath = "/metrics"
+	DefaultNodeLabel       = "instance"
+	defaultNodeTimeout     = 2 * time.Second
+	maxNodeResponseSize    = 32 << 20
+)
+
+// NodeTarget 节点代理聚合的同机进程
+type NodeTarget struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`          // 基础地址，如 http://127.0.0.1:8081
+	HealthPath  string `json:"health_path"`  // 健康检查路径，默认 /readyz
+	MetricsPath string `json:"metrics_path"` // 指标路径（Prometheus 文本格式），默认 /metrics，为 - 时不采集
+	Optional    bool   `json:"optional"`     // 可选进程不可用时仅上报状态，不影响整体状态
+}
+
+// NodeDiscovery 动态发现同机进程（如从服务注册中心读取），结果与配置的进程合并，同名时以配置为准
+type NodeDiscovery interface {
+	Targets(ctx context.Context) ([]NodeTarget, error)
+}
+
+// NodeAgentOptions 节点代理选项
+type NodeAgentOptions struct {
+	Timeout   time.Duration // 单个进程的请求超时，默认 2 秒
+	Label     string        // 写入指标的进程标签名，默认 instance；进程自身的同名标签改名为 exported_{label}
+	Client    *http.Client  // 默认使用独立的 http.Client
+	Discovery NodeDiscovery // 动态发现，可为空
+}
+
+// NodeStatus 单个进程的健康状态
+type NodeStatus struct {
+	Name      string          `json:"name"`
+	URL       string          `json:"url,omitempty"`
+	Status    string          `json:"status"` // UP / DOWN
+	Optional  bool            `json:"optional,omitempty"`
+	Code      int             `json:"code,omitempty"`
+	LatencyMs int64           `json:"latency_ms"`
+	Error     string          `json:"error,omitempty"`
+	Body      json.RawMessage `json:"body,omitempty"` // 进程返回的 JSON 响应
+}
+
+// NodeHealth 聚合的健康状态
+type NodeHealth struct {
+	Status    string       `json:"status"` // 任一必需进程 DOWN 时为 DOWN
+	Timestamp int64        `json:"timestamp"`
+	Nodes     []NodeStatus `json:"nodes"`
+}
+
+// NodeAgent 节点代理：聚合同机多个进程的健康检查与指标，适用于没有 Prometheus 服务发现的虚拟机部署
+type NodeAgent struct {
+	targets   []NodeTarget
+	timeout   time.Duration
+	label     string
+	client    *http.Client
+	discovery NodeDiscovery
+}
+
+// NewNodeAgent 创建节点代理
+func NewNodeAgent(targets []NodeTarget, opt NodeAgentOptions) *NodeAgent {
+	a := &NodeAgent{
+		timeout:   opt.Timeout,
+		label:     strings.TrimSpace(opt.Label),
+		client:    opt.Client,
+		discovery: opt.Discovery,
+	}
+	if a.timeout <= 0 {
+		a.timeout = defaultNodeTimeout
+	}
+	if a.label == "" {
+		a.label = DefaultNodeLabel
+	}
+	if a.client == nil {
+		a.client = &http.Client{}
+	}
+	for _, t := range targets {
+		if t = t.normalize(); t.Name != "" && t.URL != "" {
+			a.targets = append(a.targets, t)
+		}
+	}
+	return a
+}
+
+func (t NodeTarget) normalize() NodeTarget {
+	t.Name = strings.TrimSpace(t.Name)
+	t.URL = strings.TrimRight(strings.TrimSpace(t.URL), "/")
+	if t.Name == "" {
+		t.Name = strings.TrimPrefix(strings.TrimPrefix(t.URL, "http://"), "https://")
+	}
+	if t.HealthPath == "" {
+		t.HealthPath = DefaultNodeHealthPath
+	}
+	if t.MetricsPath == "" {
+		t.MetricsPath = DefaultNodeMetricsPath
+	}
+	return t
+}
+
+// Targets 返回配置及动态发现的进程，发现失败时返回配置的进程及错误
+func (a *NodeAgent) Targets(ctx context.Context) ([]NodeTarget, error) {
+	targets := append([]NodeTarget{}, a.targets...)
+	if a.discovery == nil {
+		return targets, nil
+	}
+	discovered, err := a.discovery.Targets(ctx)
+	if err != nil {
+		return targets, fmt.Errorf("node discovery: %w", err)
+	}
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		seen[t.Name] = true
+	}
+	for _, t := range discovered {
+		if t = t.normalize(); t.Name != "" && t.URL != "" && !seen[t.Name] {
+			seen[t.Name] = true
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
+
+// Health 并发检查全部进程，任一必需进程不可用时返回 ErrNotReady
+// 动态发现失败时增加一项名为 discovery 的可选状态
+func (a *NodeAgent) Health(ctx context.Context) (NodeHealth, error) {
+	targets, discoverErr := a.Targets(ctx)
+	nodes := make([]NodeStatus, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t NodeTarget) {
+			defer wg.Done()
+			nodes[i] = a.checkHealth(ctx, t)
+		}(i, t)
+	}
+	wg.Wait()
+
+	if discoverErr != nil {
+		nodes = append(nodes, NodeStatus{Name: "discovery", Status: "DOWN", Optional: true, Error: discoverErr.Error()})
+	}
+	health := NodeHealth{Status: "UP", Timestamp: time.Now().Unix(), Nodes: nodes}
+	var failed []string
+	for _, n := range nodes {
+		if n.Status != "UP" && !n.Optional {
+			failed = append(failed, n.Name)
+		}
+	}
+	if len(failed) > 0 {
+		health.Status = "DOWN"
+		return health, fmt.Errorf("%w: %v", ErrNotReady, failed)
+	}
+	return health, nil
+}
+
+func (a *NodeAgent) checkHealth(ctx context.Context, t NodeTarget) NodeStatus {
+	status := NodeStatus{Name: t.Name, URL: t.URL, Status: "DOWN", Optional: t.Optional}
+	start := time.Now()
+	code, body, err := a.fetch(ctx, t.URL+t.HealthPath, "application/json")
+	status.LatencyMs = time.Since(start).Milliseconds()
+	status.Code = code
+	if json.Valid(body) {
+		status.Body = body
+	}
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Status = "UP"
+	return status
+}
+
+// Metrics 并发采集全部进程的指标并合并写入 w（Prometheus 文本格式）
+// 每个样本增加进程标签，同名指标合并为一组；另输出 node_agent_up 表示各进程能否采集
+func (a *NodeAgent) Metrics(ctx context.Context, w io.Writer) error {
+	targets, _ := a.Targets(ctx)
+	type result struct {
+		body []byte
+		err  error
+	}
+	results := make([]result, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		if t.MetricsPath == "-" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, t NodeTarget) {
+			defer wg.Done()
+			_, body, err := a.fetch(ctx, t.URL+t.MetricsPath, "text/plain;version=0.0.4")
+			results[i] = result{body: body, err: err}
+		}(i, t)
+	}
+	wg.Wait()
+
+	m := newMetricsMerger(a.label)
+	up := &metricFamily{name: "node_agent_up", help: "Whether the node agent scraped the process successfully.", typ: "gauge"}
+	for i, t := range targets {
+		if t.MetricsPath == "-" {
+			continue
+		}
+		value := 0
+		if results[i].err == nil && m.add(t.Name, results[i].body) == nil {
+			value = 1
+		}
+		up.samples = append(up.samples, fmt.Sprintf("node_agent_up{%s=\"%s\"} %d", a.label, escapeLabelValue(t.Name), value))
+	}
+	m.order = append(m.order, up.name)
+	m.families[up.name] = up
+	return m.write(w)
+}
+
+func (a *NodeAgent) fetch(ctx context.Context, url, accept string) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", accept)
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxNodeResponseSize))
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, body, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// metricFamily 同名指标的说明、类型及样本
+type metricFamily struct {
+	name    string
+	help    string
+	typ     string
+	samples []string
+}
+
+// metricsMerger 合并多个进程的 Prometheus 文本格式指标，同名指标必须连续输出
+type metricsMerger struct {
+	label    string
+	order    []string
+	families map[string]*metricFamily
+}
+
+func newMetricsMerger(label string) *metricsMerger {
+	return &metricsMerger{label: label, families: map[string]*metricFamily{}}
+}
+
+func (m *metricsMerger) family(name string) *metricFamily {
+	f, ok := m.families[name]
+	if !ok {
+		f = &metricFamily{name: name}
+		m.families[name] = f
+		m.order = append(m.order, name)
+	}
+	return f
+}
+
+// add 解析一个进程的指标，先完整解析再合并，格式错误时不合并该进程的任何样本
+func (m *metricsMerger) add(instance string, body []byte) error {
+	type parsed struct {
+		family, help, typ, sample string
+	}
+	var lines []parsed
+	var current string
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNodeResponseSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			fields := strings.Fields(line)
+			if len(fields) < 3 || (fields[1] != "HELP" && fields[1] != "TYPE") {
+				continue
+			}
+			current = fields[2]
+			rest := strings.TrimSpace(strings.SplitN(line, fields[2], 2)[1])
+			if fields[1] == "HELP" {
+				lines = append(lines, parsed{family: current, help: rest})
+			} else {
+				lines = append(lines, parsed{family: current, typ: rest})
+			}
+			continue
+		}
+		name := sampleName(line)
+		if name == "" {
+			return errors.New("invalid metric line: " + line)
+		}
+		sample, err := withInstanceLabel(line, name, m.label, instance)
+		if err != nil {
+			return err
+		}
+		family := current
+		if !belongsToFamily(name, family) {
+			family = name
+		}
+		lines = append(lines, parsed{family: family, sample: sample})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, l := range lines {
+		f := m.family(l.family)
+		switch {
+		case l.help != "" && f.help == "":
+			f.help = l.help
+		case l.typ != "" && f.typ == "":
+			f.typ = l.typ
+		case l.sample != "":
+			f.samples = append(f.samples, l.sample)
+		}
+	}
+	return nil
+}
+
+func (m *metricsMerger) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, name := range m.order {
+		f := m.families[name]
+		if len(f.samples) == 0 {
+			continue
+		}
+		if f.help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.name, f.help)
+		}
+		if f.typ != "" {
+			fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.typ)
+		}
+		for _, s := range f.samples {
+			fmt.Fprintln(bw, s)
+		}
+	}
+	return bw.Flush()
+}
+
+// belongsToFamily 样本是否属于指标组，如 http_duration_bucket 属于 http_duration
+func belongsToFamily(sample, family string) bool {
+	if family == "" {
+		return false
+	}
+	if sample == family {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(sample, family+"_")
+	if !ok {
+		return false
+	}
+	switch suffix {
+	case "bucket", "sum", "count", "total", "created", "info", "gsum", "gcount":
+		return true
+	}
+	return false
+}
+
+func sampleName(line string) string {
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return ""
+	}
+	return line[:end]
+}
+
+// withInstanceLabel 为样本增加进程标签，样本已有的同名标签改名为 exported_{label}
+func withInstanceLabel(line, name, label, instance string) (string, error) {
+	rest := line[len(name):]
+	pair := label + `="` + escapeLabelValue(instance) + `"`
+	if !strings.HasPrefix(rest, "{") {
+		return name + "{" + pair + "}" + rest, nil
+	}
+	end, labels, err := renameLabel(rest, label, "exported_"+label)
+	if err != nil {
+		return "", fmt.Errorf("invalid metric line: %s: %w", line, err)
+	}
+	if strings.TrimSpace(labels) == "" {
+		return name + "{" + pair + "}" + rest[end+1:], nil
+	}
+	return name + "{" + pair + "," + labels + "}" + rest[end+1:], nil
+}
+
+// renameLabel 解析 {...} 标签块，返回右括号位置及改名后的标签内容
+func renameLabel(block, from, to string) (int, string, error) {
+	var out strings.Builder
+	i := 1
+	for i < len(block) {
+		switch c := block[i]; {
+		case c == '}':
+			return i, out.String(), nil
+		case c == ',' || c == ' ' || c == '\t':
+			out.WriteByte(c)
+			i++
+			continue
+		}
+		eq := strings.IndexByte(block[i:], '=')
+		if eq < 0 {
+			return 0, "", errors.New("missing label value")
+		}
+		labelName := strings.TrimSpace(block[i : i+eq])
+		if labelName == from {
+			labelName = to
+		}
+		out.WriteString(labelName)
+		out.WriteByte('=')
+		i += eq + 1
+		if i >= len(block) || block[i] != '"' {
+			return 0, "", errors.New("label value must be quoted")
+		}
+		start := i
+		i++
+		for i < len(block) && block[i] != '"' {
+			if block[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(block) {
+			return 0, "", errors.New("unterminated label value")
+		}
+		i++
+		out.WriteString(block[start:i])
+	}
+	return 0, "", errors.New("unterminated label block")
+}
+
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
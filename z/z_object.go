@@ -152,6 +152,7 @@ func FormatTimeInMap(m map[string]interface{}) {
 }
 
 // ToStruct 函数将 interface{} 转换为指定的 struct 类型
+// 经 JSON 转换，未知字段会被丢弃；需要报告未知字段、类型不符及缺失必填字段时使用 ToStructStrict
 func ToStruct(data interface{}, target interface{}) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
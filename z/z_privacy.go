@@ -0,0 +1,237 @@
+package z
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// 数据主体请求类型
+const (
+	PrivacyActionExport = "export"
+	PrivacyActionErase  = "erase"
+)
+
+// ErrPrivacyUserRequired 未指定用户
+var ErrPrivacyUserRequired = errors.New("privacy: user id required")
+
+// PrivacySubject 一类用户数据（通常对应一个模型）的导出与匿名化
+type PrivacySubject interface {
+	// Export 返回用户的全部数据，结果需可 JSON 序列化
+	Export(ctx context.Context, userID string) (interface{}, error)
+	// Erase 匿名化用户数据，返回受影响的记录数
+	Erase(ctx context.Context, userID string) (int64, error)
+}
+
+// PrivacyAuditRecord 数据主体请求的审计记录
+type PrivacyAuditRecord struct {
+	UserID    string           `json:"user_id"`
+	Action    string           `json:"action"`             // export | erase
+	Operator  string           `json:"operator,omitempty"` // 发起人，由 WithPrivacyOperator 指定
+	Subjects  []string         `json:"subjects"`
+	Affected  map[string]int64 `json:"affected,omitempty"` // erase 时各数据类受影响的记录数
+	Error     string           `json:"error,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// PrivacyAuditor 审计记录存储，db_provider.NewDBPrivacyAuditor 提供数据库实现
+type PrivacyAuditor interface {
+	Record(ctx context.Context, record PrivacyAuditRecord) error
+}
+
+// PrivacyBundle 导出结果，Data 按数据类名称组织
+type PrivacyBundle struct {
+	UserID     string                 `json:"user_id"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// JSON 返回整个导出结果的 JSON
+func (b *PrivacyBundle) JSON() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// ZIP 返回 ZIP 包：manifest.json 记录用户与导出时间，每个数据类一个 <name>.json
+func (b *PrivacyBundle) ZIP() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, v interface{}) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("privacy: encode %s: %w", name, err)
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.ExportedAt})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	names := make([]string, 0, len(b.Data))
+	for name := range b.Data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	manifest := map[string]interface{}{"user_id": b.UserID, "exported_at": b.ExportedAt, "subjects": names}
+	if err := write("manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err := write(name+".json", b.Data[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type privacyOperatorKey struct{}
+
+// WithPrivacyOperator 指定发起数据主体请求的操作人，写入审计记录
+func WithPrivacyOperator(ctx context.Context, operator string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, privacyOperatorKey{}, operator)
+}
+
+type _privacy struct {
+	mu       sync.RWMutex
+	subjects map[string]PrivacySubject
+	auditor  PrivacyAuditor
+}
+
+// Privacy 数据主体请求（导出、匿名化），按名称注册各类用户数据
+var Privacy = &_privacy{subjects: map[string]PrivacySubject{}}
+
+// Register 注册或覆盖数据类
+func (p *_privacy) Register(name string, subject PrivacySubject) {
+	name = strings.TrimSpace(name)
+	if name == "" || subject == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subjects[name] = subject
+}
+
+// Unregister 移除数据类
+func (p *_privacy) Unregister(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.subjects, name)
+}
+
+// SetAuditor 设置审计记录存储，未设置时审计记录写入日志
+func (p *_privacy) SetAuditor(auditor PrivacyAuditor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.auditor = auditor
+}
+
+// Subjects 返回已注册的数据类名称
+func (p *_privacy) Subjects() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, 0, len(p.subjects))
+	for name := range p.subjects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *_privacy) snapshot() ([]string, map[string]PrivacySubject, PrivacyAuditor) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	subjects := make(map[string]PrivacySubject, len(p.subjects))
+	names := make([]string, 0, len(p.subjects))
+	for name, s := range p.subjects {
+		subjects[name] = s
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, subjects, p.auditor
+}
+
+// Export 汇总用户在所有已注册数据类中的数据
+func (p *_privacy) Export(ctx context.Context, userID string) (*PrivacyBundle, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrPrivacyUserRequired
+	}
+	names, subjects, auditor := p.snapshot()
+	bundle := &PrivacyBundle{UserID: userID, ExportedAt: time.Now(), Data: make(map[string]interface{}, len(names))}
+
+	var err error
+	for _, name := range names {
+		var data interface{}
+		if data, err = subjects[name].Export(ctx, userID); err != nil {
+			err = fmt.Errorf("privacy: export %s: %w", name, err)
+			break
+		}
+		bundle.Data[name] = data
+	}
+	if auditErr := p.audit(ctx, auditor, PrivacyActionExport, userID, names, nil, err); auditErr != nil && err == nil {
+		err = auditErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// Erase 依次匿名化用户在所有已注册数据类中的数据，遇到错误即停止，已完成的数据类不回滚
+// 每个数据类应在自身事务中完成，重复执行是安全的
+func (p *_privacy) Erase(ctx context.Context, userID string) (map[string]int64, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrPrivacyUserRequired
+	}
+	names, subjects, auditor := p.snapshot()
+	affected := make(map[string]int64, len(names))
+
+	var err error
+	for _, name := range names {
+		var n int64
+		if n, err = subjects[name].Erase(ctx, userID); err != nil {
+			err = fmt.Errorf("privacy: erase %s: %w", name, err)
+			break
+		}
+		affected[name] = n
+	}
+	if auditErr := p.audit(ctx, auditor, PrivacyActionErase, userID, names, affected, err); auditErr != nil && err == nil {
+		err = auditErr
+	}
+	return affected, err
+}
+
+func (p *_privacy) audit(ctx context.Context, auditor PrivacyAuditor, action, userID string, names []string, affected map[string]int64, cause error) error {
+	record := PrivacyAuditRecord{UserID: userID, Action: action, Subjects: names, Affected: affected, CreatedAt: time.Now()}
+	if ctx != nil {
+		record.Operator, _ = ctx.Value(privacyOperatorKey{}).(string)
+	}
+	if cause != nil {
+		record.Error = cause.Error()
+	}
+	if auditor == nil {
+		if Info != nil {
+			b, _ := json.Marshal(record)
+			Info.Printf("privacy audit: %s", b)
+		}
+		return nil
+	}
+	if err := auditor.Record(ctx, record); err != nil {
+		return fmt.Errorf("privacy: audit: %w", err)
+	}
+	return nil
+}
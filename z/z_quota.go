@@ -0,0 +1,394 @@
+package z
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QuotaPeriod 配额重置周期
+type QuotaPeriod string
+
+const (
+	QuotaPeriodNone  QuotaPeriod = "none"  // 不重置，如存储空间
+	QuotaPeriodHour  QuotaPeriod = "hour"  // 每小时整点重置
+	QuotaPeriodDay   QuotaPeriod = "day"   // 每日零点重置
+	QuotaPeriodWeek  QuotaPeriod = "week"  // 每周一零点重置
+	QuotaPeriodMonth QuotaPeriod = "month" // 每月 1 日零点重置
+)
+
+// ErrQuotaExceeded 配额不足，可用 errors.Is 判断
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrQuotaNotDefined 配额未定义
+var ErrQuotaNotDefined = errors.New("quota not defined")
+
+// ErrQuotaSubjectRequired 未指定配额主体
+var ErrQuotaSubjectRequired = errors.New("quota subject required")
+
+// QuotaDefinition 命名配额定义
+type QuotaDefinition struct {
+	Name   string      `json:"name"`
+	Limit  int64       `json:"limit"`  // 每周期上限，<= 0 表示不限制（仅计数）
+	Period QuotaPeriod `json:"period"` // 为空时按 none 处理
+}
+
+// QuotaUsage 配额使用情况
+type QuotaUsage struct {
+	Name      string      `json:"name"`
+	Subject   string      `json:"subject"`
+	Period    QuotaPeriod `json:"period"`
+	Used      int64       `json:"used"`
+	Limit     int64       `json:"limit"`     // <= 0 表示不限制
+	Remaining int64       `json:"remaining"` // 不限制时为 -1
+	ResetAt   *time.Time  `json:"reset_at"`  // 下次重置时间，none 周期为空
+}
+
+// QuotaExceededError 配额不足错误，Status 对应 StatusQuotaExceeded
+type QuotaExceededError struct {
+	Usage     QuotaUsage
+	Requested int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota '%s' exceeded: used %d of %d, requested %d", e.Usage.Name, e.Usage.Used, e.Usage.Limit, e.Requested)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// Status 返回业务状态码，便于 Failure(c, err, err.Status()) 输出
+func (e *QuotaExceededError) Status() Status {
+	return StatusQuotaExceeded
+}
+
+// QuotaStore 配额计数存储，Consume 必须是原子的“检查并累加”
+type QuotaStore interface {
+	// Consume 在 used+n 不超过 limit（limit <= 0 不限制）时累加并返回累加后的值；超限时不累加，返回当前值和 false
+	Consume(ctx context.Context, key string, n, limit int64, ttl time.Duration) (used int64, ok bool, err error)
+	// Get 返回当前计数，不存在时为 0
+	Get(ctx context.Context, key string) (int64, error)
+	// Reset 清空计数
+	Reset(ctx context.Context, key string) error
+}
+
+// QuotaLimitResolver 按主体覆盖配额上限（如按套餐），返回 false 时使用定义中的上限
+type QuotaLimitResolver func(ctx context.Context, name, subject string) (int64, bool)
+
+type quotaSubjectKey struct{}
+
+// WithQuotaSubject 指定后续 Consume / Usage 使用的配额主体，如用户 ID 或租户 ID
+func WithQuotaSubject(ctx context.Context, subject string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, quotaSubjectKey{}, subject)
+}
+
+// QuotaSubjectFrom 获取 ctx 中的配额主体
+func QuotaSubjectFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	subject, _ := ctx.Value(quotaSubjectKey{}).(string)
+	return subject
+}
+
+type _quota struct {
+	mu       sync.RWMutex
+	defs     map[string]QuotaDefinition
+	store    QuotaStore
+	resolver QuotaLimitResolver
+	prefix   string
+}
+
+// Quota 配额计数，默认使用进程内存储，quota_provider 启用后切换为 Redis 存储
+var Quota = &_quota{defs: map[string]QuotaDefinition{}, store: newMemQuotaStore(), prefix: "quota"}
+
+// Define 定义或覆盖命名配额
+func (q *_quota) Define(defs ...QuotaDefinition) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.defineLocked(defs)
+}
+
+// Replace 以 defs 替换全部配额定义，未包含的配额被移除；用于配置热更新
+func (q *_quota) Replace(defs ...QuotaDefinition) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.defs = make(map[string]QuotaDefinition, len(defs))
+	q.defineLocked(defs)
+}
+
+// defineLocked 写入配额定义，调用方需持有 mu
+func (q *_quota) defineLocked(defs []QuotaDefinition) {
+	for _, def := range defs {
+		name := strings.TrimSpace(def.Name)
+		if name == "" {
+			continue
+		}
+		def.Name = name
+		if def.Period == "" {
+			def.Period = QuotaPeriodNone
+		}
+		q.defs[name] = def
+	}
+}
+
+// Definitions 返回全部配额定义，按名称排序
+func (q *_quota) Definitions() []QuotaDefinition {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	out := make([]QuotaDefinition, 0, len(q.defs))
+	for _, def := range q.defs {
+		out = append(out, def)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// SetStore 设置计数存储和键前缀
+func (q *_quota) SetStore(store QuotaStore, prefix string) {
+	if store == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.store = store
+	if prefix != "" {
+		q.prefix = prefix
+	}
+}
+
+// SetLimitResolver 设置按主体覆盖上限的函数
+func (q *_quota) SetLimitResolver(fn QuotaLimitResolver) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.resolver = fn
+}
+
+// Consume 为 ctx 中的主体消耗 n 个单位，超限时返回 *QuotaExceededError 且不计数
+func (q *_quota) Consume(ctx context.Context, name string, n int64) (*QuotaUsage, error) {
+	return q.ConsumeFor(ctx, name, QuotaSubjectFrom(ctx), n)
+}
+
+// ConsumeFor 为指定主体消耗 n 个单位；n 为负数时归还（如删除文件释放存储空间）
+func (q *_quota) ConsumeFor(ctx context.Context, name, subject string, n int64) (*QuotaUsage, error) {
+	def, store, key, limit, err := q.prepare(ctx, name, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	resetAt := quotaResetAt(def.Period, now)
+	var ttl time.Duration
+	if resetAt != nil {
+		// 多保留一分钟，避免各实例时钟偏差导致提前过期
+		ttl = resetAt.Sub(now) + time.Minute
+	}
+
+	checkLimit := limit
+	if n <= 0 {
+		checkLimit = 0
+	}
+	used, ok, err := store.Consume(ctx, key+quotaPeriodSuffix(def.Period, now), n, checkLimit, ttl)
+	if err != nil {
+		return nil, err
+	}
+	usage := newQuotaUsage(def, subject, used, limit, resetAt)
+	if !ok {
+		return usage, &QuotaExceededError{Usage: *usage, Requested: n}
+	}
+	return usage, nil
+}
+
+// Usage 返回 ctx 中主体的配额使用情况
+func (q *_quota) Usage(ctx context.Context, name string) (*QuotaUsage, error) {
+	return q.UsageFor(ctx, name, QuotaSubjectFrom(ctx))
+}
+
+// UsageFor 返回指定主体的配额使用情况
+func (q *_quota) UsageFor(ctx context.Context, name, subject string) (*QuotaUsage, error) {
+	def, store, key, limit, err := q.prepare(ctx, name, subject)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	used, err := store.Get(ctx, key+quotaPeriodSuffix(def.Period, now))
+	if err != nil {
+		return nil, err
+	}
+	return newQuotaUsage(def, subject, used, limit, quotaResetAt(def.Period, now)), nil
+}
+
+// UsageAll 返回指定主体全部配额的使用情况，按名称排序
+func (q *_quota) UsageAll(ctx context.Context, subject string) ([]*QuotaUsage, error) {
+	defs := q.Definitions()
+	out := make([]*QuotaUsage, 0, len(defs))
+	for _, def := range defs {
+		usage, err := q.UsageFor(ctx, def.Name, subject)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, usage)
+	}
+	return out, nil
+}
+
+// Reset 清空指定主体当前周期的计数
+func (q *_quota) Reset(ctx context.Context, name, subject string) error {
+	def, store, key, _, err := q.prepare(ctx, name, subject)
+	if err != nil {
+		return err
+	}
+	return store.Reset(ctx, key+quotaPeriodSuffix(def.Period, time.Now()))
+}
+
+// prepare 读取定义并计算计数键（不含周期后缀）和生效上限
+func (q *_quota) prepare(ctx context.Context, name, subject string) (QuotaDefinition, QuotaStore, string, int64, error) {
+	if subject == "" {
+		return QuotaDefinition{}, nil, "", 0, ErrQuotaSubjectRequired
+	}
+	q.mu.RLock()
+	def, ok := q.defs[name]
+	store := q.store
+	resolver := q.resolver
+	prefix := q.prefix
+	q.mu.RUnlock()
+	if !ok {
+		return QuotaDefinition{}, nil, "", 0, fmt.Errorf("%w: %s", ErrQuotaNotDefined, name)
+	}
+
+	limit := def.Limit
+	if resolver != nil {
+		if v, ok := resolver(ctx, name, subject); ok {
+			limit = v
+		}
+	}
+	return def, store, prefix + ":" + name + ":" + subject, limit, nil
+}
+
+func newQuotaUsage(def QuotaDefinition, subject string, used, limit int64, resetAt *time.Time) *QuotaUsage {
+	remaining := int64(-1)
+	if limit > 0 {
+		remaining = limit - used
+		if remaining < 0 {
+			remaining = 0
+		}
+	}
+	return &QuotaUsage{Name: def.Name, Subject: subject, Period: def.Period, Used: used, Limit: limit, Remaining: remaining, ResetAt: resetAt}
+}
+
+// quotaPeriodSuffix 返回当前周期的键后缀，周期切换后使用新键，旧键随过期时间清理
+func quotaPeriodSuffix(period QuotaPeriod, now time.Time) string {
+	switch period {
+	case QuotaPeriodHour:
+		return ":" + now.Format("2006010215")
+	case QuotaPeriodDay:
+		return ":" + now.Format("20060102")
+	case QuotaPeriodWeek:
+		year, week := now.ISOWeek()
+		return fmt.Sprintf(":%dW%02d", year, week)
+	case QuotaPeriodMonth:
+		return ":" + now.Format("200601")
+	default:
+		return ""
+	}
+}
+
+// quotaResetAt 返回下一周期的开始时间
+func quotaResetAt(period QuotaPeriod, now time.Time) *time.Time {
+	var t time.Time
+	switch period {
+	case QuotaPeriodHour:
+		t = time.Date(now.Year(), now.Month(), now.Day(), now.Hour()+1, 0, 0, 0, now.Location())
+	case QuotaPeriodDay:
+		t = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	case QuotaPeriodWeek:
+		offset := (int(now.Weekday()) + 6) % 7
+		t = time.Date(now.Year(), now.Month(), now.Day()-offset+7, 0, 0, 0, 0, now.Location())
+	case QuotaPeriodMonth:
+		t = time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+	default:
+		return nil
+	}
+	return &t
+}
+
+// memQuotaSweepInterval 进程内存储清理过期计数的最小间隔
+const memQuotaSweepInterval = time.Minute
+
+// memQuotaStore 进程内计数存储，仅适用于单实例
+type memQuotaStore struct {
+	mu        sync.Mutex
+	items     map[string]*memQuotaItem
+	nextSweep time.Time
+}
+
+type memQuotaItem struct {
+	used     int64
+	expireAt time.Time
+}
+
+func newMemQuotaStore() *memQuotaStore {
+	return &memQuotaStore{items: map[string]*memQuotaItem{}}
+}
+
+func (s *memQuotaStore) Consume(_ context.Context, key string, n, limit int64, ttl time.Duration) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweepLocked(now)
+	item := s.items[key]
+	if item == nil || (!item.expireAt.IsZero() && now.After(item.expireAt)) {
+		item = &memQuotaItem{}
+		if ttl > 0 {
+			item.expireAt = now.Add(ttl)
+		}
+		s.items[key] = item
+	}
+	if limit > 0 && item.used+n > limit {
+		return item.used, false, nil
+	}
+	item.used += n
+	if item.used < 0 {
+		item.used = 0
+	}
+	return item.used, true, nil
+}
+
+func (s *memQuotaStore) Get(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item := s.items[key]
+	if item == nil || (!item.expireAt.IsZero() && time.Now().After(item.expireAt)) {
+		return 0, nil
+	}
+	return item.used, nil
+}
+
+func (s *memQuotaStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
+
+// sweepLocked 清理过期的计数，周期配额每个周期产生新键，不清理时旧周期的键会一直保留；调用方需持有 mu
+func (s *memQuotaStore) sweepLocked(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(memQuotaSweepInterval)
+	for key, item := range s.items {
+		if !item.expireAt.IsZero() && now.After(item.expireAt) {
+			delete(s.items, key)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package z
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReadyChecker 依赖就绪检查，未连接或不可用时返回错误
+type ReadyChecker interface {
+	Ready() error
+}
+
+// ReadyFunc 函数形式的 ReadyChecker
+type ReadyFunc func() error
+
+func (f ReadyFunc) Ready() error { return f() }
+
+// ReadyCheck 一项依赖的就绪检查，provider 模块通过 group:"ready_checks" 注册
+type ReadyCheck struct {
+	Name     string       // 依赖名称，如 db、redis
+	Checker  ReadyChecker // 检查实现
+	Optional bool         // 可选依赖未就绪时仅上报状态，不影响整体就绪
+}
+
+// ReadyStatus 单项检查结果
+type ReadyStatus struct {
+	Name     string `json:"name"`
+	Ready    bool   `json:"ready"`
+	Optional bool   `json:"optional,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ErrNotReady 必需依赖未就绪
+var ErrNotReady = errors.New("dependencies not ready")
+
+// Readiness 汇总多个依赖的就绪状态
+type Readiness struct {
+	checks []ReadyCheck
+}
+
+// NewReadiness 创建就绪检查集合，required 非空时仅其中列出的依赖为必需，其余按可选处理
+func NewReadiness(checks []ReadyCheck, required []string) *Readiness {
+	r := &Readiness{}
+	for _, check := range checks {
+		if check.Checker == nil || check.Name == "" {
+			continue
+		}
+		if len(required) > 0 {
+			check.Optional = !InSlice(check.Name, required)
+		}
+		r.checks = append(r.checks, check)
+	}
+	return r
+}
+
+// Check 执行全部检查，任一必需依赖未就绪时返回 ErrNotReady
+func (r *Readiness) Check() ([]ReadyStatus, error) {
+	if r == nil {
+		return nil, nil
+	}
+	statuses := make([]ReadyStatus, 0, len(r.checks))
+	var failed []string
+	for _, check := range r.checks {
+		status := ReadyStatus{Name: check.Name, Ready: true, Optional: check.Optional}
+		if err := check.Checker.Ready(); err != nil {
+			status.Ready = false
+			status.Error = err.Error()
+			if !check.Optional {
+				failed = append(failed, fmt.Sprintf("%s: %v", check.Name, err))
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	if len(failed) > 0 {
+		return statuses, fmt.Errorf("%w: %v", ErrNotReady, failed)
+	}
+	return statuses, nil
+}
+
+// Wait 按 interval 重复检查直到必需依赖全部就绪，ctx 结束时返回最后一次的错误
+func (r *Readiness) Wait(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_, err := r.Check()
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for readiness: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package z
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 错误报告类型
+const (
+	ReportKindPanic = "panic"
+	ReportKindError = "error"
+)
+
+// ErrorReport 上报到外部错误收集系统的报告
+type ErrorReport struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Kind        string            `json:"kind"`  // panic / error
+	Level       string            `json:"level"` // fatal / error
+	Message     string            `json:"message"`
+	ErrorType   string            `json:"error_type,omitempty"`
+	Frames      []TraceFrame      `json:"frames,omitempty"`
+	Stack       string            `json:"stack,omitempty"` // panic 的原始堆栈
+	TraceID     string            `json:"trace_id,omitempty"`
+	SpanID      string            `json:"span_id,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Request     *ReportRequest    `json:"request,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// ReportRequest 报告关联的请求信息
+type ReportRequest struct {
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	ClientIP string            `json:"client_ip,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// ErrorReporter 错误报告器，Report 不应阻塞调用方
+type ErrorReporter interface {
+	Report(report ErrorReport)
+}
+
+// ReportSink 报告投递目标，如 SentrySink、WebhookSink
+type ReportSink interface {
+	Send(ctx context.Context, reports []ErrorReport) error
+}
+
+type reporterHolder struct{ r ErrorReporter }
+
+var errorReporter atomic.Pointer[reporterHolder]
+
+// SetErrorReporter 设置全局错误报告器，nil 表示关闭上报
+func SetErrorReporter(r ErrorReporter) {
+	if r == nil {
+		errorReporter.Store(nil)
+		return
+	}
+	errorReporter.Store(&reporterHolder{r: r})
+}
+
+func getErrorReporter() ErrorReporter {
+	if h := errorReporter.Load(); h != nil {
+		return h.r
+	}
+	return nil
+}
+
+// ReportError 上报错误，未设置报告器时忽略；TrackedError 只上报一次并携带记录时的调用栈
+func ReportError(ctx context.Context, err error, req *http.Request) {
+	r := getErrorReporter()
+	if r == nil || err == nil {
+		return
+	}
+	report := newErrorReport(ctx, ReportKindError, req)
+	report.Message = err.Error()
+	var tracked *TrackedError
+	if errors.As(err, &tracked) {
+		if !tracked.reported.CompareAndSwap(false, true) {
+			return
+		}
+		report.ErrorType = fmt.Sprintf("%T", tracked.err)
+		report.Frames = tracked.Trace.Frames
+		if tracked.Trace.TraceID != "" {
+			report.TraceID, report.SpanID = tracked.Trace.TraceID, tracked.Trace.SpanID
+		}
+	} else {
+		report.ErrorType = fmt.Sprintf("%T", err)
+		report.Frames = callerFrames(3)
+	}
+	r.Report(report)
+}
+
+// ReportPanic 上报 panic，stack 为 debug.Stack() 的结果
+func ReportPanic(ctx context.Context, recovered interface{}, stack []byte, req *http.Request) {
+	r := getErrorReporter()
+	if r == nil {
+		return
+	}
+	report := newErrorReport(ctx, ReportKindPanic, req)
+	report.Message = fmt.Sprint(recovered)
+	report.ErrorType = fmt.Sprintf("%T", recovered)
+	report.Stack = string(stack)
+	report.Frames = parseStackFrames(report.Stack)
+	r.Report(report)
+}
+
+// parseStackFrames 解析 debug.Stack() 格式的堆栈，从 panic 位置开始记录，跳过 runtime 帧
+func parseStackFrames(stack string) []TraceFrame {
+	lines := strings.Split(stack, "\n")
+	start := 1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic(") {
+			start = i + 2
+			break
+		}
+	}
+	frames := make([]TraceFrame, 0, len(lines)/2)
+	for i := start; i+1 < len(lines); i++ {
+		fn := lines[i]
+		loc := lines[i+1]
+		if fn == "" || strings.HasPrefix(fn, "\t") || !strings.HasPrefix(loc, "\t") {
+			continue
+		}
+		i++
+		if p := strings.LastIndex(fn, "("); p > 0 {
+			fn = fn[:p]
+		}
+		if strings.HasPrefix(fn, "runtime.") || strings.HasPrefix(fn, "runtime/debug.") || strings.HasPrefix(fn, "created by ") {
+			continue
+		}
+		loc = strings.TrimPrefix(loc, "\t")
+		if p := strings.LastIndex(loc, " +0x"); p > 0 {
+			loc = loc[:p]
+		}
+		file, line := loc, 0
+		if p := strings.LastIndex(loc, ":"); p > 0 {
+			file = loc[:p]
+			line, _ = strconv.Atoi(loc[p+1:])
+		}
+		frames = append(frames, TraceFrame{Function: fn, File: file, Line: line})
+		if len(frames) >= trackerMaxFrames {
+			break
+		}
+	}
+	return frames
+}
+
+// RecoverAndReport 恢复 panic 并上报，需直接 defer 调用，onPanic 可用于记录日志
+//
+//	defer z.RecoverAndReport(ctx, func(recovered interface{}, stack []byte) {
+//		log.Errorw("worker panic", "recovered", recovered, "stack", string(stack))
+//	})
+func RecoverAndReport(ctx context.Context, onPanic func(recovered interface{}, stack []byte)) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	stack := panicStack()
+	ReportPanic(ctx, recovered, stack, nil)
+	if onPanic != nil {
+		onPanic(recovered, stack)
+	}
+}
+
+func newErrorReport(ctx context.Context, kind string, req *http.Request) ErrorReport {
+	report := ErrorReport{
+		EventID:   newEventID(),
+		Timestamp: time.Now().UTC(),
+		Kind:      kind,
+		Level:     "error",
+	}
+	if kind == ReportKindPanic {
+		report.Level = "fatal"
+	}
+	if req != nil {
+		if ctx == nil {
+			ctx = req.Context()
+		}
+		report.Request = &ReportRequest{
+			Method:   req.Method,
+			URL:      req.URL.String(),
+			ClientIP: strings.TrimSpace(strings.Split(req.Header.Get("X-Forwarded-For"), ",")[0]),
+			Headers:  make(map[string]string, len(req.Header)),
+		}
+		if report.Request.ClientIP == "" {
+			report.Request.ClientIP = req.RemoteAddr
+			if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+				report.Request.ClientIP = host
+			}
+		}
+		for k := range req.Header {
+			report.Request.Headers[k] = req.Header.Get(k)
+		}
+	}
+	if ctx != nil {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			report.TraceID, report.SpanID = sc.TraceID().String(), sc.SpanID().String()
+		}
+	}
+	return report
+}
+
+// newEventID 32 位十六进制事件 ID，与 Sentry event_id 格式一致
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// panicStack 当前协程的堆栈
+func panicStack() []byte {
+	buf := make([]byte, 64<<10)
+	return buf[:runtime.Stack(buf, false)]
+}
+
+// ReportScrubber 上报前的脱敏规则
+type ReportScrubber struct {
+	Headers  []string         // 需要过滤的请求头（不区分大小写），为空时使用 DefaultScrubHeaders
+	Patterns []*regexp.Regexp // 在消息、堆栈、URL 中匹配并替换的内容
+}
+
+// DefaultScrubHeaders 默认过滤的请求头
+var DefaultScrubHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "Proxy-Authorization"}
+
+// DefaultScrubPatterns 默认过滤 URL 与消息中的密码、令牌参数
+var DefaultScrubPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)((?:password|passwd|secret|token|access_token|api_key)=)[^&\s]+`),
+}
+
+const scrubbed = "[Filtered]"
+
+// Scrub 返回脱敏后的报告
+func (s ReportScrubber) Scrub(report ErrorReport) ErrorReport {
+	headers := s.Headers
+	if len(headers) == 0 {
+		headers = DefaultScrubHeaders
+	}
+	patterns := s.Patterns
+	if patterns == nil {
+		patterns = DefaultScrubPatterns
+	}
+	replace := func(v string) string {
+		for _, p := range patterns {
+			if p.NumSubexp() > 0 {
+				v = p.ReplaceAllString(v, "${1}"+scrubbed)
+			} else {
+				v = p.ReplaceAllString(v, scrubbed)
+			}
+		}
+		return v
+	}
+
+	report.Message = replace(report.Message)
+	report.Stack = replace(report.Stack)
+	if report.Request != nil {
+		req := *report.Request
+		req.URL = replace(req.URL)
+		req.Headers = make(map[string]string, len(report.Request.Headers))
+		for k, v := range report.Request.Headers {
+			filtered := false
+			for _, h := range headers {
+				if strings.EqualFold(k, h) {
+					filtered = true
+					break
+				}
+			}
+			if filtered {
+				req.Headers[k] = scrubbed
+			} else {
+				req.Headers[k] = replace(v)
+			}
+		}
+		report.Request = &req
+	}
+	return report
+}
+
+// BatchReporterOptions 批量报告器选项
+type BatchReporterOptions struct {
+	Release       string         // 版本号
+	Environment   string         // 环境，如 production
+	SampleRate    float64        // 普通错误的采样率 (0, 1]，默认 1；panic 始终上报
+	Scrubber      ReportScrubber // 脱敏规则
+	BatchSize     int            // 每批最多报告数，默认 20
+	FlushInterval time.Duration  // 最长攒批时间，默认 5s
+	QueueSize     int            // 队列长度，队列满时丢弃新报告，默认 1000
+	SendTimeout   time.Duration  // 单批投递超时，默认 10s
+	OnError       func(err error, reports []ErrorReport)
+}
+
+// BatchReporter 异步批量投递报告：采样、补充版本信息、脱敏后进入队列，由后台协程按批发送
+type BatchReporter struct {
+	sink    ReportSink
+	opt     BatchReporterOptions
+	server  string
+	queue   chan ErrorReport
+	flush   chan chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+// NewBatchReporter 创建批量报告器并启动后台投递协程，退出前需调用 Close
+func NewBatchReporter(sink ReportSink, opt BatchReporterOptions) *BatchReporter {
+	if opt.SampleRate <= 0 || opt.SampleRate > 1 {
+		opt.SampleRate = 1
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 20
+	}
+	if opt.FlushInterval <= 0 {
+		opt.FlushInterval = 5 * time.Second
+	}
+	if opt.QueueSize <= 0 {
+		opt.QueueSize = 1000
+	}
+	if opt.SendTimeout <= 0 {
+		opt.SendTimeout = 10 * time.Second
+	}
+	hostname, _ := os.Hostname()
+	r := &BatchReporter{
+		sink:   sink,
+		opt:    opt,
+		server: hostname,
+		queue:  make(chan ErrorReport, opt.QueueSize),
+		flush:  make(chan chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go r.loop()
+	return r
+}
+
+// Report 采样、脱敏后入队，队列满或已关闭时丢弃
+func (r *BatchReporter) Report(report ErrorReport) {
+	if report.Kind != ReportKindPanic && r.opt.SampleRate < 1 && mathrand.Float64() >= r.opt.SampleRate {
+		return
+	}
+	if report.Release == "" {
+		report.Release = r.opt.Release
+	}
+	if report.Environment == "" {
+		report.Environment = r.opt.Environment
+	}
+	if report.ServerName == "" {
+		report.ServerName = r.server
+	}
+	report = r.opt.Scrubber.Scrub(report)
+
+	select {
+	case <-r.done:
+		r.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case r.queue <- report:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped 因队列已满或已关闭被丢弃的报告数
+func (r *BatchReporter) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Flush 立即投递队列中的报告，ctx 结束时返回
+func (r *BatchReporter) Flush(ctx context.Context) {
+	ack := make(chan struct{})
+	select {
+	case r.flush <- ack:
+	case <-r.done:
+		return
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-ack:
+	case <-ctx.Done():
+	}
+}
+
+// Close 投递剩余报告并停止后台协程
+func (r *BatchReporter) Close(ctx context.Context) {
+	r.Flush(ctx)
+	r.once.Do(func() { close(r.done) })
+}
+
+func (r *BatchReporter) loop() {
+	ticker := time.NewTicker(r.opt.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]ErrorReport, 0, r.opt.BatchSize)
+	for {
+		select {
+		case report := <-r.queue:
+			batch = append(batch, report)
+			if len(batch) >= r.opt.BatchSize {
+				batch = r.send(batch)
+			}
+		case <-ticker.C:
+			batch = r.send(batch)
+		case ack := <-r.flush:
+			batch = r.drain(batch)
+			close(ack)
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// drain 发送队列中全部报告
+func (r *BatchReporter) drain(batch []ErrorReport) []ErrorReport {
+	for {
+		select {
+		case report := <-r.queue:
+			batch = append(batch, report)
+			if len(batch) >= r.opt.BatchSize {
+				batch = r.send(batch)
+			}
+		default:
+			return r.send(batch)
+		}
+	}
+}
+
+func (r *BatchReporter) send(batch []ErrorReport) []ErrorReport {
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.opt.SendTimeout)
+	defer cancel()
+	reports := append([]ErrorReport(nil), batch...)
+	if err := r.sink.Send(ctx, reports); err != nil && r.opt.OnError != nil {
+		r.opt.OnError(err, reports)
+	}
+	return batch[:0]
+}
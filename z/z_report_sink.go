@@ -0,0 +1,180 @@
+package z
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SentrySink 按 Sentry envelope 协议投递报告，兼容 Sentry 及 GlitchTip 等实现
+type SentrySink struct {
+	endpoint string
+	auth     string
+	dsn      string
+	client   *http.Client
+}
+
+// NewSentrySink 解析 DSN（https://<key>@<host>/<project_id>）创建 SentrySink，client 为空时使用默认 client
+func NewSentrySink(dsn string, client *http.Client) (*SentrySink, error) {
+	u, err := url.Parse(strings.TrimSpace(dsn))
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry dsn: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, errors.New("invalid sentry dsn: missing public key or host")
+	}
+	path := strings.TrimRight(u.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	project := path[idx+1:]
+	if project == "" {
+		return nil, errors.New("invalid sentry dsn: missing project id")
+	}
+	return &SentrySink{
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path[:idx], project),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_key=%s, sentry_client=go-zlib/1.0", u.User.Username()),
+		dsn:      dsn,
+		client:   client,
+	}, nil
+}
+
+// Send Sentry 每个 envelope 只能包含一个事件，逐个投递，返回最后一个错误
+func (s *SentrySink) Send(ctx context.Context, reports []ErrorReport) error {
+	var lastErr error
+	for _, report := range reports {
+		body, err := s.envelope(report)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		_, err = RequestWithResponse(RequestOptions{
+			URL:    s.endpoint,
+			Method: http.MethodPost,
+			Headers: map[string]string{
+				"Content-Type":  "application/x-sentry-envelope",
+				"X-Sentry-Auth": s.auth,
+			},
+			ContentType: RequestContentTypeRaw,
+			Data:        body,
+			Client:      s.client,
+			Context:     ctx,
+		})
+		if err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (s *SentrySink) envelope(report ErrorReport) ([]byte, error) {
+	event, err := json.Marshal(sentryEvent(report))
+	if err != nil {
+		return nil, err
+	}
+	header, _ := json.Marshal(map[string]string{
+		"event_id": report.EventID,
+		"dsn":      s.dsn,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339),
+	})
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.WriteString("\n{\"type\":\"event\",\"length\":")
+	buf.WriteString(strconv.Itoa(len(event)))
+	buf.WriteString("}\n")
+	buf.Write(event)
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// sentryEvent 转换为 Sentry 事件结构，调用栈按 Sentry 约定由外到内排列
+func sentryEvent(report ErrorReport) map[string]interface{} {
+	frames := make([]map[string]interface{}, 0, len(report.Frames))
+	for i := len(report.Frames) - 1; i >= 0; i-- {
+		f := report.Frames[i]
+		frames = append(frames, map[string]interface{}{
+			"function": f.Function,
+			"abs_path": f.File,
+			"lineno":   f.Line,
+			"in_app":   !strings.Contains(f.File, "/go/pkg/mod/") && !strings.HasPrefix(f.Function, "runtime."),
+		})
+	}
+	exception := map[string]interface{}{
+		"type":      report.ErrorType,
+		"value":     report.Message,
+		"mechanism": map[string]interface{}{"type": report.Kind, "handled": report.Kind != ReportKindPanic},
+	}
+	if len(frames) > 0 {
+		exception["stacktrace"] = map[string]interface{}{"frames": frames}
+	}
+
+	event := map[string]interface{}{
+		"event_id":    report.EventID,
+		"timestamp":   report.Timestamp.Format(time.RFC3339Nano),
+		"level":       report.Level,
+		"platform":    "go",
+		"release":     report.Release,
+		"environment": report.Environment,
+		"server_name": report.ServerName,
+		"exception":   map[string]interface{}{"values": []interface{}{exception}},
+	}
+	if len(report.Tags) > 0 {
+		event["tags"] = report.Tags
+	}
+	if report.Request != nil {
+		event["request"] = map[string]interface{}{
+			"method":  report.Request.Method,
+			"url":     report.Request.URL,
+			"headers": report.Request.Headers,
+			"env":     map[string]string{"REMOTE_ADDR": report.Request.ClientIP},
+		}
+	}
+	if report.TraceID != "" {
+		event["contexts"] = map[string]interface{}{
+			"trace": map[string]string{"trace_id": report.TraceID, "span_id": report.SpanID},
+		}
+	}
+	if report.Stack != "" {
+		event["extra"] = map[string]string{"stack": report.Stack}
+	}
+	return event
+}
+
+// WebhookSink 以 JSON 数组 POST 一批报告到通用 webhook
+type WebhookSink struct {
+	URL         string
+	Headers     map[string]string
+	Credentials Credentials // 可选，响应 401 时刷新后重试一次
+	Client      *http.Client
+}
+
+func (s *WebhookSink) Send(ctx context.Context, reports []ErrorReport) error {
+	_, err := AuthorizedRequest(s.Credentials, RequestOptions{
+		URL:         s.URL,
+		Method:      http.MethodPost,
+		Headers:     s.Headers,
+		ContentType: RequestContentTypeJSON,
+		Data:        reports,
+		Client:      s.Client,
+		Context:     ctx,
+	})
+	return err
+}
+
+// MultiSink 同时投递到多个目标，返回最后一个错误
+type MultiSink []ReportSink
+
+func (m MultiSink) Send(ctx context.Context, reports []ErrorReport) error {
+	var lastErr error
+	for _, sink := range m {
+		if err := sink.Send(ctx, reports); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,172 @@
+package z
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// RetryClass 错误的重试分类
+type RetryClass int
+
+const (
+	RetryUnknown   RetryClass = iota // 无法判断，由调用方按自身策略处理
+	RetryRetryable                   // 瞬时错误，重试可能成功
+	RetryPermanent                   // 确定性错误，重试无意义
+)
+
+// RetryableError 自行声明是否可重试的错误，如 HttpStatusError
+type RetryableError interface {
+	Retryable() bool
+}
+
+// RetryClassifier 自行给出重试分类的错误，可返回 RetryUnknown，如 db_provider.DBError
+type RetryClassifier interface {
+	RetryClass() RetryClass
+}
+
+// retryMark Retryable / Permanent 包装的错误
+type retryMark struct {
+	err       error
+	retryable bool
+}
+
+func (e *retryMark) Error() string   { return e.err.Error() }
+func (e *retryMark) Unwrap() error   { return e.err }
+func (e *retryMark) Retryable() bool { return e.retryable }
+
+// Retryable 将错误标记为可重试
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryMark{err: err, retryable: true}
+}
+
+// Permanent 将错误标记为不可重试，任务返回该错误时不再重试
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryMark{err: err, retryable: false}
+}
+
+// HttpStatusError 状态码 >= 400 的 HTTP 响应，RequestWithResponse 返回该错误
+type HttpStatusError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *HttpStatusError) Error() string {
+	status := e.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("http error: %s\n%s", status, string(e.Body))
+}
+
+// Retryable 5xx（501 除外）、408、429 可重试
+func (e *HttpStatusError) Retryable() bool {
+	return IsRetryableStatus(e.StatusCode)
+}
+
+// IsRetryableStatus 判断 HTTP 状态码是否可重试
+func IsRetryableStatus(code int) bool {
+	switch {
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests:
+		return true
+	case code == http.StatusNotImplemented:
+		return false
+	default:
+		return code >= 500
+	}
+}
+
+// transientMessages 无法通过类型识别时按错误信息匹配的瞬时错误
+var transientMessages = []string{
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"i/o timeout",
+	"invalid connection",
+	"bad connection",
+	"connection pool timeout", // go-redis
+	"tryagain",                // redis cluster
+	"loading redis is loading",
+	"deadlock",
+	"lock wait timeout",
+	"could not serialize access",
+}
+
+// ClassifyError 判断错误是否可重试，HTTP 重试、任务重试等统一使用该规则
+// 依次检查：RetryClassifier / RetryableError 声明 > context 取消/超时 > 网络错误 > 错误信息匹配
+func ClassifyError(err error) RetryClass {
+	if err == nil {
+		return RetryUnknown
+	}
+
+	var rc RetryClassifier
+	if errors.As(err, &rc) {
+		if class := rc.RetryClass(); class != RetryUnknown {
+			return class
+		}
+	}
+	var re RetryableError
+	if errors.As(err, &re) {
+		if re.Retryable() {
+			return RetryRetryable
+		}
+		return RetryPermanent
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return RetryPermanent
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return RetryRetryable
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ETIMEDOUT) {
+		return RetryRetryable
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsNotFound {
+			return RetryPermanent
+		}
+		return RetryRetryable
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return RetryRetryable
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return RetryRetryable
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range transientMessages {
+		if strings.Contains(msg, s) {
+			return RetryRetryable
+		}
+	}
+	return RetryUnknown
+}
+
+// IsRetryable 判断错误是否为可重试的瞬时错误：网络错误、5xx 响应、死锁/序列化冲突、Redis 超时等
+func IsRetryable(err error) bool {
+	return ClassifyError(err) == RetryRetryable
+}
+
+// IsPermanent 判断错误是否明确不可重试
+func IsPermanent(err error) bool {
+	return ClassifyError(err) == RetryPermanent
+}
@@ -0,0 +1,231 @@
+package z
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteEntry 已注册的路由
+type RouteEntry struct {
+	Method      string         `json:"method"`
+	Path        string         `json:"path"`
+	Handler     string         `json:"handler"`
+	Middlewares []string       `json:"middlewares"`    // engine 级中间件链，gin 路由表不保留分组中间件
+	Auth        *RouteAuthMeta `json:"auth,omitempty"` // 声明的认证要求，未通过 SetRouteAuth 声明时为空
+}
+
+// RouteAuthMeta 路由声明的认证要求，由 helpers.RouteAuth.Handle 注册
+type RouteAuthMeta struct {
+	Action      string   `json:"action,omitempty"`
+	Public      bool     `json:"public"`                // 声明为公开访问
+	Guards      []string `json:"guards,omitempty"`      // 任一 guard 认证通过即可
+	Permissions []string `json:"permissions,omitempty"` // 所需权限，格式 resource:action
+}
+
+var (
+	routeAuthMu sync.RWMutex
+	routeAuth   = map[string]RouteAuthMeta{}
+)
+
+// SetRouteAuth 记录路由的认证要求，Routes 导出时附加到对应路由
+func SetRouteAuth(method, path string, meta RouteAuthMeta) {
+	routeAuthMu.Lock()
+	defer routeAuthMu.Unlock()
+	routeAuth[method+" "+path] = meta
+}
+
+func getRouteAuth(method, path string) *RouteAuthMeta {
+	routeAuthMu.RLock()
+	defer routeAuthMu.RUnlock()
+	meta, ok := routeAuth[method+" "+path]
+	if !ok {
+		return nil
+	}
+	return &meta
+}
+
+// PermissionMap 按权限汇总路由（值为 "METHOD path"），供前端生成菜单及按钮级权限
+// guard 非空时仅包含该 guard 可访问的路由
+func PermissionMap(routes []RouteEntry, guard string) map[string][]string {
+	out := map[string][]string{}
+	for _, route := range routes {
+		if route.Auth == nil || route.Auth.Public {
+			continue
+		}
+		if guard != "" && !InSlice(guard, route.Auth.Guards) {
+			continue
+		}
+		for _, perm := range route.Auth.Permissions {
+			out[perm] = append(out[perm], route.Method+" "+route.Path)
+		}
+	}
+	return out
+}
+
+// 路由冲突类型
+const (
+	RouteConflictShadowed      = "shadowed"       // 请求会被另一条优先级更高的路由匹配
+	RouteConflictTrailingSlash = "trailing_slash" // 仅末尾斜杠不同，易与 RedirectTrailingSlash 混淆
+	RouteConflictReserved      = "reserved"       // 路径被内置中间件拦截，路由永远不会执行
+)
+
+// RouteConflict 路由冲突
+type RouteConflict struct {
+	Kind    string `json:"kind"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+	Other   string `json:"other"` // 冲突的另一条路由或保留路径
+	Message string `json:"message"`
+}
+
+// Routes 返回 engine 上注册的路由，按路径和方法排序
+func Routes(engine *gin.Engine) []RouteEntry {
+	if engine == nil {
+		return nil
+	}
+	middlewares := make([]string, 0, len(engine.Handlers))
+	for _, h := range engine.Handlers {
+		middlewares = append(middlewares, handlerName(h))
+	}
+
+	infos := engine.Routes()
+	routes := make([]RouteEntry, 0, len(infos))
+	for _, info := range infos {
+		routes = append(routes, RouteEntry{
+			Method:      info.Method,
+			Path:        info.Path,
+			Handler:     info.Handler,
+			Middlewares: middlewares,
+			Auth:        getRouteAuth(info.Method, info.Path),
+		})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// CheckRoutes 检测相互遮蔽、仅末尾斜杠不同以及占用保留路径的路由
+// gin 在注册时已拒绝完全相同或参数名冲突的路由，这里检测的是能注册成功但请求可能命中非预期处理器的情况
+func CheckRoutes(routes []RouteEntry, reserved ...string) []RouteConflict {
+	var conflicts []RouteConflict
+	for i, a := range routes {
+		if InSlice(a.Path, reserved) {
+			conflicts = append(conflicts, RouteConflict{
+				Kind: RouteConflictReserved, Method: a.Method, Path: a.Path, Handler: a.Handler, Other: a.Path,
+				Message: fmt.Sprintf("%s %s is served by a built-in middleware and never reaches %s", a.Method, a.Path, a.Handler),
+			})
+		}
+		for _, b := range routes[i+1:] {
+			if a.Method != b.Method {
+				continue
+			}
+			if strings.TrimSuffix(a.Path, "/") == strings.TrimSuffix(b.Path, "/") {
+				conflicts = append(conflicts, RouteConflict{
+					Kind: RouteConflictTrailingSlash, Method: a.Method, Path: a.Path, Handler: a.Handler, Other: b.Path,
+					Message: fmt.Sprintf("%s %s and %s differ only by a trailing slash", a.Method, a.Path, b.Path),
+				})
+				continue
+			}
+			winner, loser, ok := routeShadow(a, b)
+			if !ok {
+				continue
+			}
+			conflicts = append(conflicts, RouteConflict{
+				Kind: RouteConflictShadowed, Method: loser.Method, Path: loser.Path, Handler: loser.Handler, Other: winner.Path,
+				Message: fmt.Sprintf("%s %s overlaps %s; overlapping requests are handled by %s", loser.Method, loser.Path, winner.Path, winner.Handler),
+			})
+		}
+	}
+	return conflicts
+}
+
+// routeShadow 判断两条路由是否存在同时匹配的请求路径，胜出方按第一个类型不同的段的优先级（静态 > 参数 > 通配）确定
+func routeShadow(a, b RouteEntry) (winner, loser RouteEntry, ok bool) {
+	as, bs := routeSegments(a.Path), routeSegments(b.Path)
+	winner, loser = a, b
+	decided := false
+	prefer := func(aWins bool) {
+		if decided {
+			return
+		}
+		decided = true
+		if !aWins {
+			winner, loser = b, a
+		}
+	}
+	for i := 0; ; i++ {
+		if i == len(as) || i == len(bs) {
+			// 通配段可匹配空路径，如 /files/*path 匹配 /files/
+			if i < len(as) && segmentKind(as[i]) == 2 {
+				prefer(false)
+				return winner, loser, true
+			}
+			if i < len(bs) && segmentKind(bs[i]) == 2 {
+				prefer(true)
+				return winner, loser, true
+			}
+			return winner, loser, len(as) == len(bs)
+		}
+		ka, kb := segmentKind(as[i]), segmentKind(bs[i])
+		switch {
+		case ka == 2 || kb == 2:
+			// 通配段匹配剩余全部路径
+			prefer(ka <= kb)
+			return winner, loser, true
+		case ka == 0 && kb == 0:
+			if as[i] != bs[i] {
+				return winner, loser, false
+			}
+		case ka == 1 && kb == 1:
+		default:
+			// 参数段不匹配空段（如 / 与 /:id）
+			if as[i] == "" || bs[i] == "" {
+				return winner, loser, false
+			}
+			prefer(ka == 0)
+		}
+	}
+}
+
+func routeSegments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// segmentKind 0 静态段，1 参数段 :id，2 通配段 *path
+func segmentKind(seg string) int {
+	switch {
+	case strings.HasPrefix(seg, ":"):
+		return 1
+	case strings.HasPrefix(seg, "*"):
+		return 2
+	}
+	return 0
+}
+
+// handlerName 返回处理函数名，闭包返回其外层函数名
+func handlerName(h gin.HandlerFunc) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	for {
+		idx := strings.LastIndex(name, ".func")
+		if idx < 0 {
+			break
+		}
+		name = name[:idx]
+	}
+	return name
+}
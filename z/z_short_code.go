@@ -0,0 +1,121 @@
+package z
+
+import (
+	"errors"
+	"hash/fnv"
+	"math/bits"
+)
+
+// shortCodeAlphabet 去掉易混淆字符 0 1 i l o I O
+const shortCodeAlphabet = "23456789abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// ErrInvalidShortCode 短码格式错误或校验不通过
+var ErrInvalidShortCode = errors.New("invalid short code")
+
+// ShortCoder 将非负整数编码为短码并可还原，用于邀请码、短链接等场景
+// 字母表按盐打乱，首字符兼作校验；仅用于混淆，不能替代 IDMasker 防止遍历
+type ShortCoder struct {
+	alphabet  []byte
+	index     [256]int
+	minLength int
+	salt      uint64
+}
+
+// NewShortCoder 创建 ShortCoder，不同的盐生成不同的短码，minLength 为短码最小长度（含校验字符）
+//
+//	coder := z.NewShortCoder("invite", 6)
+//	code := coder.Encode(12345)    // 如 "k7Qx3T"
+//	id, err := coder.Decode(code)  // 12345
+func NewShortCoder(salt string, minLength int) *ShortCoder {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(salt))
+	seed := h.Sum64()
+
+	c := &ShortCoder{alphabet: []byte(shortCodeAlphabet), minLength: minLength, salt: seed}
+	// 按盐确定性打乱字母表（xorshift）
+	x := seed | 1
+	for i := len(c.alphabet) - 1; i > 0; i-- {
+		x ^= x << 13
+		x ^= x >> 7
+		x ^= x << 17
+		j := int(x % uint64(i+1))
+		c.alphabet[i], c.alphabet[j] = c.alphabet[j], c.alphabet[i]
+	}
+	for i := range c.index {
+		c.index[i] = -1
+	}
+	for i, ch := range c.alphabet {
+		c.index[ch] = i
+	}
+	return c
+}
+
+// lottery 由数值与盐决定的首字符位置，解码时用于校验
+func (c *ShortCoder) lottery(n uint64) int {
+	hi, lo := bits.Mul64(n^c.salt, 0x9E3779B97F4A7C15)
+	return int((hi ^ lo) % uint64(len(c.alphabet)))
+}
+
+// digit 第 pos 位数字 d 对应的字符，偏移随首字符与位置变化，使相邻数字的短码差异明显
+func (c *ShortCoder) digit(d, k, pos int) byte {
+	size := len(c.alphabet)
+	return c.alphabet[(d+k+(pos+1)*7)%size]
+}
+
+// Encode 编码
+func (c *ShortCoder) Encode(n uint64) string {
+	size := uint64(len(c.alphabet))
+	var digits []int
+	for v := n; ; v /= size {
+		digits = append(digits, int(v%size))
+		if v < size {
+			break
+		}
+	}
+	// 不足最小长度时补高位 0，数值不变
+	for len(digits)+1 < c.minLength {
+		digits = append(digits, 0)
+	}
+
+	k := c.lottery(n)
+	out := make([]byte, 0, len(digits)+1)
+	out = append(out, c.alphabet[k])
+	for pos := 0; pos < len(digits); pos++ {
+		out = append(out, c.digit(digits[len(digits)-1-pos], k, pos))
+	}
+	return string(out)
+}
+
+// Decode 解码，字符不在字母表、溢出或校验不通过时返回 ErrInvalidShortCode
+func (c *ShortCoder) Decode(code string) (uint64, error) {
+	if len(code) < 2 {
+		return 0, ErrInvalidShortCode
+	}
+	size := len(c.alphabet)
+	k := c.index[code[0]]
+	if k < 0 {
+		return 0, ErrInvalidShortCode
+	}
+	var n uint64
+	for pos := 1; pos < len(code); pos++ {
+		i := c.index[code[pos]]
+		if i < 0 {
+			return 0, ErrInvalidShortCode
+		}
+		d := ((i-k-pos*7)%size + size) % size
+		hi, lo := bits.Mul64(n, uint64(size))
+		if hi != 0 {
+			return 0, ErrInvalidShortCode
+		}
+		sum, carry := bits.Add64(lo, uint64(d), 0)
+		if carry != 0 {
+			return 0, ErrInvalidShortCode
+		}
+		n = sum
+	}
+	// 重新编码比对，同时校验首字符并拒绝多余的前导 0
+	if c.Encode(n) != code {
+		return 0, ErrInvalidShortCode
+	}
+	return n, nil
+}
@@ -0,0 +1,253 @@
+package z
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SlugOptions Slugify 选项
+type SlugOptions struct {
+	Separator string // 分隔符，默认 -
+	MaxLength int    // 最大字节数，超出时在分隔符处截断，0 表示不限制
+}
+
+// latinTranslit NFKD 分解后仍无法去掉附加符号的字母
+var latinTranslit = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "ae", 'œ': "oe", 'Œ': "oe", 'ø': "o", 'Ø': "o",
+	'đ': "d", 'Đ': "d", 'ð': "d", 'Ð': "d", 'ł': "l", 'Ł': "l", 'þ': "th", 'Þ': "th",
+	'ı': "i", 'ħ': "h", 'Ħ': "h", 'ŋ': "ng", 'Ŋ': "ng",
+}
+
+// cyrillicTranslit 俄语字母转写（小写，大写先转小写再查表）
+var cyrillicTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+// greekTranslit 希腊字母转写（小写，附加符号已由 NFKD 去掉）
+var greekTranslit = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o",
+}
+
+var (
+	pinyinMu   sync.RWMutex
+	pinyinDict = map[rune]string{}
+)
+
+// SetPinyin 注册汉字读音，读音可带声调符号或数字声调，保存时去掉声调；多音字取第一个读音
+func SetPinyin(dict map[rune]string) {
+	pinyinMu.Lock()
+	defer pinyinMu.Unlock()
+	for r, py := range dict {
+		if py = firstReading(py); py != "" {
+			pinyinDict[r] = py
+		}
+	}
+}
+
+// LoadPinyinDict 加载 pinyin-data 格式的读音表，每行形如 U+4E2D: zhōng,zhòng  # 中
+// 本库不内置汉字读音，启动时加载一次即可，未收录的汉字在 Slugify 中被忽略
+func LoadPinyinDict(r io.Reader) error {
+	dict := map[rune]string{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		code, readings, ok := strings.Cut(line, ":")
+		if !ok || !strings.HasPrefix(strings.ToUpper(code), "U+") {
+			return fmt.Errorf("pinyin dict line %d: invalid entry %q", n, line)
+		}
+		cp, err := strconv.ParseUint(strings.TrimSpace(code[2:]), 16, 32)
+		if err != nil {
+			return fmt.Errorf("pinyin dict line %d: invalid code point %q", n, code)
+		}
+		dict[rune(cp)] = readings
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	SetPinyin(dict)
+	return nil
+}
+
+func firstReading(readings string) string {
+	fields := strings.FieldsFunc(readings, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(StripTones(fields[0]))
+}
+
+// StripTones 去掉拼音声调：声调符号转为基本字母，ü 转为 v，并去掉末尾的数字声调，如 lǜ / lv4 -> lv
+func StripTones(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case 'ü', 'ǖ', 'ǘ', 'ǚ', 'ǜ':
+			b.WriteByte('v')
+			continue
+		case 'Ü', 'Ǖ', 'Ǘ', 'Ǚ', 'Ǜ':
+			b.WriteByte('V')
+			continue
+		}
+		for _, d := range norm.NFD.String(string(r)) {
+			if !unicode.Is(unicode.Mn, d) {
+				b.WriteRune(d)
+			}
+		}
+	}
+	return strings.TrimRightFunc(b.String(), unicode.IsDigit)
+}
+
+// Pinyin 将字符串中的汉字转为不带声调的拼音，每个汉字一项；连续的非汉字字母数字作为一项保留，其余字符忽略
+func Pinyin(s string) []string {
+	pinyinMu.RLock()
+	defer pinyinMu.RUnlock()
+	var out []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			out = append(out, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			flush()
+			if py, ok := pinyinDict[r]; ok {
+				out = append(out, py)
+			}
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(r)
+			continue
+		}
+		flush()
+	}
+	flush()
+	return out
+}
+
+// Slugify 生成 URL slug：Unicode 规范化并去掉附加符号，转写拉丁扩展、西里尔与希腊字母，汉字转为拼音（需先 LoadPinyinDict），
+// 其余字符作为分隔，结果为小写 ASCII
+//
+//	z.Slugify("Crème Brûlée")        // creme-brulee
+//	z.Slugify("Привет, мир")         // privet-mir
+//	z.Slugify("你好 World")           // ni-hao-world
+func Slugify(s string, opts ...SlugOptions) string {
+	var o SlugOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Separator == "" {
+		o.Separator = "-"
+	}
+
+	pinyinMu.RLock()
+	defer pinyinMu.RUnlock()
+
+	var b strings.Builder
+	pending := false
+	word := func(w string) {
+		if w == "" {
+			return
+		}
+		if pending && b.Len() > 0 {
+			b.WriteString(o.Separator)
+		}
+		pending = false
+		b.WriteString(w)
+	}
+	for _, r := range norm.NFKD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		lower := unicode.ToLower(r)
+		switch {
+		case lower < unicode.MaxASCII && (unicode.IsLetter(lower) || unicode.IsDigit(lower)):
+			word(string(lower))
+		case unicode.Is(unicode.Han, r):
+			// 每个汉字的拼音单独成词
+			if py, ok := pinyinDict[r]; ok {
+				pending = true
+				word(py)
+				pending = true
+			}
+		default:
+			if t, ok := latinTranslit[r]; ok {
+				word(t)
+			} else if t, ok := cyrillicTranslit[lower]; ok {
+				word(t)
+			} else if t, ok := greekTranslit[lower]; ok {
+				word(t)
+			} else {
+				pending = true
+			}
+		}
+	}
+
+	slug := b.String()
+	if o.MaxLength > 0 && len(slug) > o.MaxLength {
+		slug = slug[:o.MaxLength]
+		if i := strings.LastIndex(slug, o.Separator); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return strings.Trim(slug, o.Separator)
+}
+
+// UniqueSlug 在 slug 已存在时依次追加 -2、-3 … 直到 exists 返回 false，尝试 maxAttempts 次（默认 100）后追加随机后缀
+// base 为空时直接使用随机串
+func UniqueSlug(base string, exists func(slug string) (bool, error), maxAttempts ...int) (string, error) {
+	attempts := 100
+	if len(maxAttempts) > 0 && maxAttempts[0] > 0 {
+		attempts = maxAttempts[0]
+	}
+	if base == "" {
+		base = strings.ToLower(GenerateRandomString(8))
+	}
+	candidate := base
+	for i := 1; i <= attempts; i++ {
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		taken, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	for i := 0; i < 5; i++ {
+		candidate = base + "-" + strings.ToLower(GenerateRandomString(6))
+		taken, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("unique slug: no available slug for %q", base)
+}
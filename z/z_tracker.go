@@ -0,0 +1,140 @@
+package z
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// trackerMaxFrames 最多记录的调用栈帧数
+const trackerMaxFrames = 32
+
+// TraceFrame 调用栈帧
+type TraceFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String 格式化为 function\n\tfile:line，与 panic 堆栈格式一致
+func (f TraceFrame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line)
+}
+
+// TraceData 错误的追踪信息
+type TraceData struct {
+	TraceID string       `json:"trace_id,omitempty"` // OTel trace ID，未关联 span 时为空
+	SpanID  string       `json:"span_id,omitempty"`
+	Frames  []TraceFrame `json:"frames"`
+}
+
+// Stack 返回格式化后的调用栈
+func (d TraceData) Stack() string {
+	lines := make([]string, 0, len(d.Frames))
+	for _, f := range d.Frames {
+		lines = append(lines, f.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TrackedError 携带调用栈和追踪信息的错误
+type TrackedError struct {
+	err      error
+	Trace    TraceData
+	reported atomic.Bool // 已通过 ReportError 上报
+}
+
+func (e *TrackedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *TrackedError) Unwrap() error {
+	return e.err
+}
+
+type _tracker struct{}
+
+// Tracker 错误追踪
+var Tracker _tracker
+
+// Error 记录调用位置的堆栈并包装错误，已包装的错误原样返回
+func (_tracker) Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	var tracked *TrackedError
+	if errors.As(err, &tracked) {
+		return err
+	}
+	return &TrackedError{err: err, Trace: TraceData{Frames: callerFrames(3)}}
+}
+
+// ErrorCtx 同 Error，并在 ctx 存在有效 span 时将堆栈记录为 span 事件、写入 trace ID，便于在链路追踪系统中定位
+func (_tracker) ErrorCtx(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	var tracked *TrackedError
+	if !errors.As(err, &tracked) {
+		tracked = &TrackedError{err: err, Trace: TraceData{Frames: callerFrames(3)}}
+		err = tracked
+	}
+	attachSpan(ctx, tracked)
+	return err
+}
+
+// TraceOf 返回错误链中的追踪信息
+func TraceOf(err error) (TraceData, bool) {
+	var tracked *TrackedError
+	if errors.As(err, &tracked) {
+		return tracked.Trace, true
+	}
+	return TraceData{}, false
+}
+
+// attachSpan 将错误关联到 ctx 中的 span，同一错误只记录一次
+func attachSpan(ctx context.Context, tracked *TrackedError) {
+	if ctx == nil || tracked.Trace.TraceID != "" {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	spanCtx := span.SpanContext()
+	if !spanCtx.IsValid() {
+		return
+	}
+
+	tracked.Trace.TraceID = spanCtx.TraceID().String()
+	tracked.Trace.SpanID = spanCtx.SpanID().String()
+
+	span.RecordError(tracked.err, trace.WithAttributes(
+		attribute.String("exception.stacktrace", tracked.Trace.Stack()),
+	))
+	span.SetStatus(codes.Error, tracked.err.Error())
+}
+
+// callerFrames 获取调用栈，skip 为跳过的栈帧数
+func callerFrames(skip int) []TraceFrame {
+	pcs := make([]uintptr, trackerMaxFrames)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	out := make([]TraceFrame, 0, n)
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "runtime.") {
+			break
+		}
+		out = append(out, TraceFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return out
+}
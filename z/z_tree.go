@@ -0,0 +1,106 @@
+package z
+
+import "fmt"
+
+// BuildTree 将扁平数据构建为嵌套树结构
+// idKey、parentKey、childrenKey 为空时默认 id、parent_id、children；父节点不存在的节点作为根节点
+func BuildTree(rows []map[string]interface{}, idKey, parentKey, childrenKey string) []map[string]interface{} {
+	if idKey == "" {
+		idKey = "id"
+	}
+	if parentKey == "" {
+		parentKey = "parent_id"
+	}
+	if childrenKey == "" {
+		childrenKey = "children"
+	}
+
+	nodes := make(map[string]map[string]interface{}, len(rows))
+	order := make([]string, 0, len(rows))
+	for _, row := range rows {
+		id := fmt.Sprint(row[idKey])
+		node := make(map[string]interface{}, len(row)+1)
+		for k, v := range row {
+			node[k] = v
+		}
+		node[childrenKey] = []map[string]interface{}{}
+		nodes[id] = node
+		order = append(order, id)
+	}
+
+	children := make(map[string][]string, len(rows))
+	roots := make([]string, 0)
+	for _, id := range order {
+		parent := nodes[id][parentKey]
+		parentID := fmt.Sprint(parent)
+		if _, ok := nodes[parentID]; parent == nil || !ok || parentID == id {
+			roots = append(roots, id)
+			continue
+		}
+		children[parentID] = append(children[parentID], id)
+	}
+
+	var attach func(id string, visiting map[string]bool) map[string]interface{}
+	attach = func(id string, visiting map[string]bool) map[string]interface{} {
+		node := nodes[id]
+		visiting[id] = true
+		list := make([]map[string]interface{}, 0, len(children[id]))
+		for _, childID := range children[id] {
+			if visiting[childID] {
+				continue
+			}
+			list = append(list, attach(childID, visiting))
+		}
+		delete(visiting, id)
+		node[childrenKey] = list
+		return node
+	}
+
+	out := make([]map[string]interface{}, 0, len(roots))
+	for _, id := range roots {
+		out = append(out, attach(id, map[string]bool{}))
+	}
+	return out
+}
+
+// BuildTypedTree 将扁平切片构建为嵌套树结构，通过回调读取主键、父级主键并写入子节点
+func BuildTypedTree[T any, K comparable](items []T, id func(T) K, parent func(T) (K, bool), setChildren func(*T, []T)) []T {
+	index := make(map[K]int, len(items))
+	for i, item := range items {
+		index[id(item)] = i
+	}
+
+	children := make(map[K][]int, len(items))
+	roots := make([]int, 0)
+	for i, item := range items {
+		pid, ok := parent(item)
+		if _, exists := index[pid]; !ok || !exists || pid == id(item) {
+			roots = append(roots, i)
+			continue
+		}
+		children[pid] = append(children[pid], i)
+	}
+
+	var build func(i int, visiting map[K]bool) T
+	build = func(i int, visiting map[K]bool) T {
+		node := items[i]
+		key := id(node)
+		visiting[key] = true
+		list := make([]T, 0, len(children[key]))
+		for _, ci := range children[key] {
+			if visiting[id(items[ci])] {
+				continue
+			}
+			list = append(list, build(ci, visiting))
+		}
+		delete(visiting, key)
+		setChildren(&node, list)
+		return node
+	}
+
+	out := make([]T, 0, len(roots))
+	for _, i := range roots {
+		out = append(out, build(i, map[K]bool{}))
+	}
+	return out
+}
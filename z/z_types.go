@@ -212,6 +212,8 @@ func (s Status) String() string {
 		return "DATA_VALIDATION"
 	case StatusDataConflict:
 		return "DATA_CONFLICT"
+	case StatusVersionConflict:
+		return "VERSION_CONFLICT"
 
 	// 依赖系统
 	case StatusDBError: